@@ -20,6 +20,39 @@ const (
 	UserRoleSuperAdmin       UserRole = 90
 )
 
+// Permission is a granular right which can be granted through a custom role.
+// The built-in roles imply a fixed set of permissions.
+type Permission string
+
+const (
+	PermissionManageSpaces    Permission = "manage_spaces"
+	PermissionManageBookings  Permission = "manage_bookings"
+	PermissionApproveBookings Permission = "approve_bookings"
+	PermissionViewReports     Permission = "view_reports"
+	PermissionManageUsers     Permission = "manage_users"
+	PermissionManageSettings  Permission = "manage_settings"
+	PermissionViewAuditLog    Permission = "view_audit_log"
+)
+
+var AllPermissions = []Permission{
+	PermissionManageSpaces,
+	PermissionManageBookings,
+	PermissionApproveBookings,
+	PermissionViewReports,
+	PermissionManageUsers,
+	PermissionManageSettings,
+	PermissionViewAuditLog,
+}
+
+func IsValidPermission(p Permission) bool {
+	for _, e := range AllPermissions {
+		if e == p {
+			return true
+		}
+	}
+	return false
+}
+
 type User struct {
 	ID                     string
 	OrganizationID         string
@@ -143,6 +176,19 @@ type Booking struct {
 	CreatedAtUTC          *time.Time
 	LastInfoMailSentAtUTC *time.Time
 	ReminderSentAtUTC     *time.Time
+	CreatedByUserID       NullUUID
+	// ApprovalLevel is the approval level a pending booking is waiting for
+	ApprovalLevel int
+	// ApprovalRequestedAtUTC is the time the current approval level has
+	// been requested, i.e. the creation time for the first level
+	ApprovalRequestedAtUTC    *time.Time
+	ApprovalReminderSentAtUTC *time.Time
+	// ApprovalEscalationGroupID is set once the approval has been escalated
+	// to the organization's fallback group
+	ApprovalEscalationGroupID NullUUID
+	// ApprovalFirstApproverID is the user who approved the first level and
+	// may not approve the second level
+	ApprovalFirstApproverID NullUUID
 }
 
 type BookingDetails struct {
@@ -150,6 +196,8 @@ type BookingDetails struct {
 	UserEmail     string
 	UserFirstname string
 	UserLastname  string
+	// CreatedByEmail is set if the booking was created by another user, i.e. a delegate
+	CreatedByEmail string
 	Booking
 }
 
@@ -196,6 +244,8 @@ const (
 	AuthPasskeyRegistration  AuthStateType = 10
 	AuthPasskeyLogin         AuthStateType = 11
 	AuthPasskey2FA           AuthStateType = 12
+	AuthMagicLinkLogin       AuthStateType = 13
+	AuthBookingApproval      AuthStateType = 14
 )
 
 type AuthState struct {
@@ -232,6 +282,12 @@ const (
 	SettingEnforceTOTPAdminsOnly = 2
 )
 
+const (
+	SettingApprovalExpiryActionNone    = ""
+	SettingApprovalExpiryActionApprove = "approve"
+	SettingApprovalExpiryActionDecline = "decline"
+)
+
 var (
 	SettingInstallID                      SettingName = SettingName{Name: "install_id", Type: SettingTypeString}
 	SettingDatabaseVersion                SettingName = SettingName{Name: "db_version", Type: SettingTypeInt}
@@ -272,4 +328,25 @@ var (
 	SettingFeatureKioskMode               SettingName = SettingName{Name: "feature_kiosk_mode", Type: SettingTypeBool}
 	SettingHideReports                    SettingName = SettingName{Name: "hide_reports", Type: SettingTypeBool}
 	SettingHideStats                      SettingName = SettingName{Name: "hide_stats", Type: SettingTypeBool}
+	SettingAllowMagicLinkLogin            SettingName = SettingName{Name: "allow_magic_link_login", Type: SettingTypeBool}
+	SettingPasswordMinLength              SettingName = SettingName{Name: "password_min_length", Type: SettingTypeInt}
+	SettingPasswordMinCharClasses         SettingName = SettingName{Name: "password_min_char_classes", Type: SettingTypeInt}
+	SettingPasswordHistory                SettingName = SettingName{Name: "password_history", Type: SettingTypeInt}
+	SettingPasswordMaxAgeDays             SettingName = SettingName{Name: "password_max_age_days", Type: SettingTypeInt}
+	SettingPasswordCheckBreached          SettingName = SettingName{Name: "password_check_breached", Type: SettingTypeBool}
+	SettingNetworkAllowlistUsers          SettingName = SettingName{Name: "network_allowlist_users", Type: SettingTypeString}
+	SettingNetworkAllowlistSpaceAdmins    SettingName = SettingName{Name: "network_allowlist_space_admins", Type: SettingTypeString}
+	SettingNetworkAllowlistOrgAdmins      SettingName = SettingName{Name: "network_allowlist_org_admins", Type: SettingTypeString}
+	SettingNetworkAllowlistServiceAccount SettingName = SettingName{Name: "network_allowlist_service_accounts", Type: SettingTypeString}
+	SettingTrustedNetworks                SettingName = SettingName{Name: "trusted_networks", Type: SettingTypeString}
+	SettingRequire2FAUntrustedNetworks    SettingName = SettingName{Name: "require_2fa_untrusted_networks", Type: SettingTypeBool}
+	SettingSuspiciousLoginNotifications   SettingName = SettingName{Name: "suspicious_login_notifications", Type: SettingTypeBool}
+	SettingChatApprovalWebhookURL         SettingName = SettingName{Name: "chat_approval_webhook_url", Type: SettingTypeString}
+	SettingChatApprovalWebhookFormat      SettingName = SettingName{Name: "chat_approval_webhook_format", Type: SettingTypeString}
+	SettingApprovalReminderHours          SettingName = SettingName{Name: "approval_reminder_hours", Type: SettingTypeInt}
+	SettingApprovalEscalationHours        SettingName = SettingName{Name: "approval_escalation_hours", Type: SettingTypeInt}
+	SettingApprovalEscalationGroupID      SettingName = SettingName{Name: "approval_escalation_group_id", Type: SettingTypeString}
+	SettingApprovalExpiryAction           SettingName = SettingName{Name: "approval_expiry_action", Type: SettingTypeString}
+	SettingApprovalExpiryHours            SettingName = SettingName{Name: "approval_expiry_hours", Type: SettingTypeInt}
+	SettingVapidPrivateKey                SettingName = SettingName{Name: "vapid_private_key", Type: SettingTypeEncryptedString}
 )
@@ -107,10 +107,16 @@ func (a *App) InitializeDatabases() {
 	SetEmailLogCallback(func(subject, recipient, organizationID string) error {
 		return GetMailLogRepository().LogEmail(subject, recipient, organizationID)
 	})
+	// Send emails asynchronously via the mail queue
+	SetEmailQueueCallback(EnqueueEmail)
 	// Set up email footer provider: DB value takes precedence over file fallback
 	SetGlobalEmailFooterProvider(func(language string) (string, error) {
 		return GetSettingsRepository().GetGlobalStringLocalized(api.SettingEmailFooterPrefix, language)
 	})
+	// Organizations can replace built-in email templates
+	SetOrgEmailTemplateProvider(GetOrgEmailTemplate)
+	// Organizations can send emails via their own SMTP server
+	SetOrgMailTransportProvider(GetOrgMailTransport)
 }
 
 // InitializePlugins dials every plugin listed in PLUGINS_CONFIG over gRPC.
@@ -554,6 +560,18 @@ func (a *App) InitializeRouter() {
 	routers["/uc/"] = &CheckUpdateRouter{}
 	routers["/healthcheck"] = &HealthcheckRouter{}
 	routers["/kiosk/"] = &KioskRouter{}
+	routers["/.well-known/"] = &WellKnownRouter{}
+	routers["/api-token/"] = &ApiTokenRouter{}
+	routers["/audit-log/"] = &AuditLogRouter{}
+	routers["/delegation/"] = &DelegationRouter{}
+	routers["/role/"] = &RoleRouter{}
+	routers["/user-attribute/"] = &UserAttributeRouter{}
+	routers["/webhook/"] = &WebhookRouter{}
+	routers["/mail-queue/"] = &MailQueueRouter{}
+	routers["/email-template/"] = &EmailTemplateRouter{}
+	routers["/mail-settings/"] = &MailSettingsRouter{}
+	routers["/approval-link/"] = &ApprovalLinkRouter{}
+	routers["/push-subscription/"] = &PushSubscriptionRouter{}
 	builtInPrefixes := make([]string, 0, len(routers))
 	for route, r := range routers {
 		builtInPrefixes = append(builtInPrefixes, route)
@@ -701,6 +719,12 @@ func (a *App) onTimerTick() {
 	if err := GetUserRepository().EnableUsersWithExpiredBan(); err != nil {
 		log.Println(err)
 	}
+	if err := GetSigningKeyRing().Rotate(); err != nil {
+		log.Println(err)
+	}
+	if err := GetApiTokenRepository().DeleteExpired(30 * 24 * time.Hour); err != nil {
+		log.Println(err)
+	}
 	num, err := GetUserRepository().DeleteObsoleteConfluenceAnonymousUsers()
 	if err != nil {
 		log.Println(err)
@@ -729,12 +753,67 @@ func (a *App) onTimerTick() {
 		}
 	}
 
+	// purge max. 100 audit log entries after retention period (disabled if <= 0)
+	if retentionDays := GetConfig().AuditLogRetentionDays; retentionDays > 0 {
+		num, err = GetAuditLogRepository().PurgeOld(time.Duration(retentionDays)*24*time.Hour, 100)
+		if err != nil {
+			log.Println(err)
+		}
+		if num > 0 {
+			log.Printf("Purged %d old audit log entries", num)
+		}
+	}
+
+	// purge max. 100 completed webhook deliveries after retention period (disabled if <= 0)
+	if retentionDays := GetConfig().WebhookDeliveryRetentionDays; retentionDays > 0 {
+		num, err = GetWebhookRepository().PurgeOldDeliveries(time.Duration(retentionDays)*24*time.Hour, 100)
+		if err != nil {
+			log.Println(err)
+		}
+		if num > 0 {
+			log.Printf("Purged %d old webhook deliveries", num)
+		}
+	}
+
+	// send webhook deliveries which are due for a retry
+	go DeliverPendingWebhooks()
+
+	// purge max. 100 undeliverable emails after retention period (disabled if <= 0)
+	if retentionDays := GetConfig().MailQueueRetentionDays; retentionDays > 0 {
+		num, err = GetMailQueueRepository().PurgeDead(time.Duration(retentionDays)*24*time.Hour, 100)
+		if err != nil {
+			log.Println(err)
+		}
+		if num > 0 {
+			log.Printf("Purged %d undeliverable emails", num)
+		}
+	}
+
+	// send queued emails which are due for a retry
+	go ProcessMailQueue()
+
+	// purge login history used for suspicious login detection once per hour
+	if time.Now().Minute() == 0 {
+		num, err = GetLoginHistoryRepository().PurgeOld(LoginHistoryRetention)
+		if err != nil {
+			log.Println(err)
+		}
+		if num > 0 {
+			log.Printf("Purged %d old login history entries", num)
+		}
+	}
+
 	// send booking reminder emails (~24h before booking start)
 	// run every 5 minutes to not cause overlapping runs
 	if time.Now().Minute()%5 == 0 {
 		go a.sendBookingReminders()
 	}
 
+	// remind approvers of pending bookings, escalate and expire them
+	if time.Now().Minute()%5 == 0 {
+		go ProcessPendingApprovals()
+	}
+
 	for _, inst := range a.PluginInstances {
 		inst.Instance.OnTimer()
 	}
@@ -742,6 +821,14 @@ func (a *App) onTimerTick() {
 	if time.Now().Minute() == 0 {
 		go a.CheckDomainAccessibilityTimer()
 	}
+	// Send daily and weekly mail digests which are due
+	if time.Now().Minute()%15 == 0 {
+		go SendMailDigests()
+	}
+	// Send digest of suspicious logins to org admins once per hour
+	if time.Now().Minute() == 0 {
+		go SendSuspiciousLoginDigests()
+	}
 	// Update install stats once per hour
 	if time.Now().Minute() == 0 {
 		go a.UpdateInstallStats()
@@ -764,20 +851,22 @@ func (a *App) sendBookingReminders() {
 		wg.Add(1)
 		go func(b *api.BookingDetails) {
 			defer wg.Done()
-			a.sendBookingReminderEmail(b)
+			a.sendBookingReminder(b)
 		}(booking)
 	}
 	wg.Wait()
 
 	num := len(bookings)
 	if num > 0 {
-		log.Printf("Sent %d booking reminder emails", num)
+		log.Printf("Sent %d booking reminders", num)
 	}
 }
 
-func (a *App) sendBookingReminderEmail(e *api.BookingDetails) {
-	active, err := GetUserPreferencesRepository().GetBool(e.UserID, PreferenceMailReminder.Name)
-	if err != nil || !active {
+func (a *App) sendBookingReminder(e *api.BookingDetails) {
+	mailActive, _ := GetUserPreferencesRepository().GetBool(e.UserID, PreferenceMailReminder.Name)
+	chatActive, _ := GetUserPreferencesRepository().GetBool(e.UserID, PreferenceChatReminder.Name)
+	pushActive, _ := GetUserPreferencesRepository().GetBool(e.UserID, PreferencePushReminder.Name)
+	if !mailActive && !chatActive && !pushActive {
 		return
 	}
 	org, err := GetOrganizationRepository().GetOne(e.Space.Location.OrganizationID)
@@ -810,8 +899,21 @@ func (a *App) sendBookingReminderEmail(e *api.BookingDetails) {
 	if userLang, err := GetUserPreferencesRepository().Get(e.UserID, PreferenceMailLanguage.Name); err == nil && userLang != "" {
 		language = userLang
 	}
-	if err := SendEmailWithOrg(&MailAddress{Address: e.UserEmail}, GetEmailTemplatePathBookingReminder(), language, vars, org.ID); err != nil {
-		log.Println(err)
+	sent := false
+	if mailActive {
+		if err := SendEmailWithOrg(&MailAddress{Address: e.UserEmail}, GetEmailTemplatePathBookingReminder(), language, vars, org.ID); err != nil {
+			log.Println(err)
+		} else {
+			sent = true
+		}
+	}
+	if chatActive && SendUserChatNotification(e.UserID, GetEmailTemplatePathBookingReminder(), language, vars) {
+		sent = true
+	}
+	if pushActive && SendUserPushNotification(e.UserID, GetEmailTemplatePathBookingReminder(), language, vars) {
+		sent = true
+	}
+	if !sent {
 		return
 	}
 
@@ -1015,5 +1117,6 @@ func (a *App) Run() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
 	defer cancel()
 	a.PublicHttpServer.Shutdown(ctx)
+	CloseSiemExporter(5 * time.Second)
 	a.KillPlugins()
 }
@@ -8,6 +8,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"log"
+	"net"
 	"os"
 	"regexp"
 	"slices"
@@ -34,6 +35,8 @@ type Config struct {
 	PostgresURL                         string
 	JwtPrivateKey                       *rsa.PrivateKey
 	JwtPublicKey                        *rsa.PublicKey
+	JwtAdditionalPublicKeys             []*rsa.PublicKey // Verification-only keys, e.g. the previous key after a manual key change
+	JwtKeyRotationDays                  int              // Rotate database-backed JWT signing keys every n days (0 = disabled)
 	StaticUiPath                        string
 	MailService                         string
 	MailSenderAddress                   string
@@ -60,6 +63,7 @@ type Config struct {
 	LoginProtectionSlidingWindowSeconds int
 	LoginProtectionBanMinutes           int
 	AuthEventRetentionDays              int
+	AuditLogRetentionDays               int
 	CryptKey                            string
 	FilesystemBasePath                  string
 	Plugins                             []RemotePluginConfig
@@ -76,14 +80,29 @@ type Config struct {
 	DNSServer                           string // DNS server address for custom resolver
 	DisablePasswordLogin                bool   // Disable password login for all users (only allow OAuth2 and SSO)
 	CORSOrigins                         []string
+	TrustedProxies                      []*net.IPNet // Reverse proxies whose X-Forwarded-For and X-Real-IP headers are trusted
 	RateLimit                           int
 	RateLimitPeriod                     string // e.g., "1-M" for 1 minute
 	MaxSessionsPerUser                  int    // Maximum number of concurrent sessions per user
 	WebAuthnRPDisplayName               string
-	MaxPasskeysPerUser                  int  // Maximum number of passkeys a single user may register
-	DisableVersionCheck                 bool // Disable polling seatsurfing.io for latest version information
-	DisableAnonymousUsageStats          bool // Disable sending anonymous usage statistics for this installation
-	DisableInstallIDExposure            bool // Disable exposing the install ID via public API
+	MaxPasskeysPerUser                  int    // Maximum number of passkeys a single user may register
+	DisableVersionCheck                 bool   // Disable polling seatsurfing.io for latest version information
+	DisableAnonymousUsageStats          bool   // Disable sending anonymous usage statistics for this installation
+	DisableInstallIDExposure            bool   // Disable exposing the install ID via public API
+	BreachedPasswordsPath               string // Directory with k-anonymity range files of breached password SHA-1 hashes
+	SiemSyslogAddress                   string // host:port of the syslog receiver security events are exported to (empty = disabled)
+	SiemSyslogProtocol                  string // "udp", "tcp" or "tls"
+	SiemFormat                          string // "rfc5424" or "cef"
+	SiemTLSCACert                       string // Path to a PEM file with CA certificates to verify the syslog receiver
+	SiemTLSInsecureSkipVerify           bool
+	SiemQueueSize                       int    // Maximum number of security events waiting to be exported
+	WebhookAllowPrivateNetworks         bool   // Allow webhooks to target loopback, private and link-local addresses
+	WebhookDeliveryRetentionDays        int    // Delete completed webhook deliveries after n days (0 = keep forever)
+	MailQueueOrgRateLimit               int    // Max. emails sent per organization and minute (0 = unlimited)
+	MailQueueRetentionDays              int    // Delete undeliverable emails after n days (0 = keep forever)
+	OrgSMTPAllowPrivateNetworks         bool   // Allow organizations' SMTP servers on loopback, private and link-local addresses
+	VapidPrivateKey                     string // base64url encoded P-256 private key for Web Push (empty = generated and stored in the database)
+	VapidSubject                        string // Contact URI sent to push services
 }
 
 var _configInstance *Config
@@ -127,6 +146,24 @@ func (c *Config) ReadConfig() {
 		c.JwtPrivateKey = privateKey
 		c.JwtPublicKey = publicKey
 	}
+	c.JwtAdditionalPublicKeys = []*rsa.PublicKey{}
+	for _, path := range strings.Split(c.getEnv("JWT_ADDITIONAL_PUBLIC_KEYS", ""), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		additionalKey, err := c.loadPublicKey(path)
+		if err != nil {
+			log.Println("⚠️  Warning: Loading additional public key failed", path, err)
+			continue
+		}
+		c.JwtAdditionalPublicKeys = append(c.JwtAdditionalPublicKeys, additionalKey)
+	}
+	c.JwtKeyRotationDays = c.getEnvInt("JWT_KEY_ROTATION_DAYS", 0)
+	if c.JwtKeyRotationDays < 0 {
+		log.Println("⚠️  Warning: JWT_KEY_ROTATION_DAYS must not be negative. Disabling key rotation.")
+		c.JwtKeyRotationDays = 0
+	}
 
 	c.SMTPHost = c.getEnv("SMTP_HOST", "127.0.0.1")
 	c.SMTPPort = c.getEnvInt("SMTP_PORT", 25)
@@ -171,6 +208,7 @@ func (c *Config) ReadConfig() {
 	c.LoginProtectionSlidingWindowSeconds = c.getEnvInt("LOGIN_PROTECTION_SLIDING_WINDOW_SECONDS", 600)
 	c.LoginProtectionBanMinutes = c.getEnvInt("LOGIN_PROTECTION_BAN_MINUTES", 5)
 	c.AuthEventRetentionDays = c.getEnvInt("AUTH_EVENT_RETENTION_DAYS", 14)
+	c.AuditLogRetentionDays = c.getEnvInt("AUDIT_LOG_RETENTION_DAYS", 365)
 	c.CryptKey = c.getEnv("CRYPT_KEY", "")
 	if c.CryptKey == "" || len(c.CryptKey) != 32 {
 		log.Fatalln("Error: No valid CRYPT_KEY set. CRYPT_KEY needs to be set to a 32 bytes long random string.")
@@ -209,6 +247,19 @@ func (c *Config) ReadConfig() {
 	if c.Development && !slices.Contains(c.CORSOrigins, "http://localhost:3000") {
 		c.CORSOrigins = append(c.CORSOrigins, "http://localhost:3000")
 	}
+	c.TrustedProxies = []*net.IPNet{}
+	for _, cidr := range strings.Split(c.getEnv("TRUSTED_PROXIES", ""), ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Println("⚠️  Warning: Invalid network in TRUSTED_PROXIES ignored:", cidr)
+			continue
+		}
+		c.TrustedProxies = append(c.TrustedProxies, ipNet)
+	}
 	c.RateLimit = c.getEnvInt("RATE_LIMIT", 250)
 	c.RateLimitPeriod = c.getEnv("RATE_LIMIT_PERIOD", "1-M")
 	rxRateLimitPeriod := regexp.MustCompile(`^[0-9]+\-[SMHD]$`)
@@ -230,6 +281,35 @@ func (c *Config) ReadConfig() {
 	c.DisableVersionCheck = (c.getEnv("DISABLE_VERSION_CHECK", "0") == "1")
 	c.DisableAnonymousUsageStats = (c.getEnv("DISABLE_ANONYMOUS_USAGE_STATS", "0") == "1")
 	c.DisableInstallIDExposure = (c.getEnv("DISABLE_INSTALL_ID_EXPOSURE", "0") == "1")
+	c.BreachedPasswordsPath = c.getEnv("BREACHED_PASSWORDS_PATH", "")
+	c.SiemSyslogAddress = c.getEnv("SIEM_SYSLOG_ADDRESS", "")
+	c.SiemSyslogProtocol = strings.ToLower(c.getEnv("SIEM_SYSLOG_PROTOCOL", "udp"))
+	if c.SiemSyslogProtocol != "udp" && c.SiemSyslogProtocol != "tcp" && c.SiemSyslogProtocol != "tls" {
+		log.Println("⚠️  Warning: Invalid SIEM_SYSLOG_PROTOCOL set. Only 'udp', 'tcp' and 'tls' are allowed. Defaulting to 'udp'.")
+		c.SiemSyslogProtocol = "udp"
+	}
+	c.SiemFormat = strings.ToLower(c.getEnv("SIEM_FORMAT", "rfc5424"))
+	if c.SiemFormat != "rfc5424" && c.SiemFormat != "cef" {
+		log.Println("⚠️  Warning: Invalid SIEM_FORMAT set. Only 'rfc5424' and 'cef' are allowed. Defaulting to 'rfc5424'.")
+		c.SiemFormat = "rfc5424"
+	}
+	c.SiemTLSCACert = c.getEnv("SIEM_TLS_CA_CERT", "")
+	c.SiemTLSInsecureSkipVerify = (c.getEnv("SIEM_TLS_INSECURE_SKIP_VERIFY", "0") == "1")
+	if c.SiemTLSInsecureSkipVerify {
+		log.Println("⚠️  Warning: SIEM_TLS_INSECURE_SKIP_VERIFY is enabled, do *not* use this in production environments!")
+	}
+	c.SiemQueueSize = c.getEnvInt("SIEM_QUEUE_SIZE", 1000)
+	if c.SiemQueueSize < 1 {
+		log.Println("⚠️  Warning: SIEM_QUEUE_SIZE must be at least 1. Defaulting to 1000.")
+		c.SiemQueueSize = 1000
+	}
+	c.WebhookAllowPrivateNetworks = (c.getEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "0") == "1")
+	c.WebhookDeliveryRetentionDays = c.getEnvInt("WEBHOOK_DELIVERY_RETENTION_DAYS", 30)
+	c.MailQueueOrgRateLimit = c.getEnvInt("MAIL_QUEUE_ORG_RATE_LIMIT", 60)
+	c.MailQueueRetentionDays = c.getEnvInt("MAIL_QUEUE_RETENTION_DAYS", 30)
+	c.OrgSMTPAllowPrivateNetworks = (c.getEnv("ORG_SMTP_ALLOW_PRIVATE_NETWORKS", "0") == "1")
+	c.VapidPrivateKey = c.getEnv("VAPID_PRIVATE_KEY", "")
+	c.VapidSubject = c.getEnv("VAPID_SUBJECT", "mailto:"+c.MailSenderAddress)
 
 	// Check deprecated environment variables
 	if c.getEnv("ADMIN_UI_BACKEND", "") != "" {
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type ApiTokenRepository struct {
+}
+
+type ApiToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	TokenHash  string
+	Scopes     []string
+	Created    time.Time
+	Expiry     *time.Time
+	LastUsed   *time.Time
+	LastUsedIP string
+}
+
+const (
+	ApiTokenScopeBookingsRead  = "bookings:read"
+	ApiTokenScopeBookingsWrite = "bookings:write"
+	ApiTokenScopeSpacesRead    = "spaces:read"
+	ApiTokenScopeSpacesWrite   = "spaces:write"
+	ApiTokenScopeUsersRead     = "users:read"
+	ApiTokenScopeAdminUsers    = "admin:users"
+	ApiTokenScopeAdminSettings = "admin:settings"
+	ApiTokenScopeStatsRead     = "stats:read"
+)
+
+var ApiTokenScopes = []string{
+	ApiTokenScopeBookingsRead,
+	ApiTokenScopeBookingsWrite,
+	ApiTokenScopeSpacesRead,
+	ApiTokenScopeSpacesWrite,
+	ApiTokenScopeUsersRead,
+	ApiTokenScopeAdminUsers,
+	ApiTokenScopeAdminSettings,
+	ApiTokenScopeStatsRead,
+}
+
+// Prefix of personal API tokens, distinguishes them from legacy service account tokens
+const ApiTokenPrefix = "sst_"
+
+var apiTokenRepository *ApiTokenRepository
+var apiTokenRepositoryOnce sync.Once
+
+func GetApiTokenRepository() *ApiTokenRepository {
+	apiTokenRepositoryOnce.Do(func() {
+		apiTokenRepository = &ApiTokenRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS api_tokens (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"user_id uuid NOT NULL, " +
+			"name VARCHAR NOT NULL, " +
+			"token_hash VARCHAR NOT NULL, " +
+			"scopes VARCHAR NOT NULL DEFAULT '', " +
+			"created TIMESTAMP NOT NULL, " +
+			"expiry TIMESTAMP NULL, " +
+			"last_used TIMESTAMP NULL, " +
+			"last_used_ip VARCHAR NOT NULL DEFAULT '', " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_api_tokens_token_hash ON api_tokens(token_hash)"); err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id)"); err != nil {
+			panic(err)
+		}
+	})
+	return apiTokenRepository
+}
+
+func (r *ApiTokenRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *ApiTokenRepository) Create(e *ApiToken) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO api_tokens "+
+		"(user_id, name, token_hash, scopes, created, expiry) "+
+		"VALUES ($1, $2, $3, $4, $5, $6) "+
+		"RETURNING id",
+		e.UserID, e.Name, e.TokenHash, strings.Join(e.Scopes, ","), e.Created, e.Expiry).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *ApiTokenRepository) GetOne(id string) (*ApiToken, error) {
+	e := &ApiToken{}
+	var scopes string
+	err := GetDatabase().DB().QueryRow("SELECT id, user_id, name, token_hash, scopes, created, expiry, last_used, last_used_ip "+
+		"FROM api_tokens "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.UserID, &e.Name, &e.TokenHash, &scopes, &e.Created, &e.Expiry, &e.LastUsed, &e.LastUsedIP)
+	if err != nil {
+		return nil, err
+	}
+	e.Scopes = r.splitScopes(scopes)
+	return e, nil
+}
+
+// GetValidByTokenHash returns the unexpired token with the given hash.
+func (r *ApiTokenRepository) GetValidByTokenHash(tokenHash string) (*ApiToken, error) {
+	e := &ApiToken{}
+	var scopes string
+	err := GetDatabase().DB().QueryRow("SELECT id, user_id, name, token_hash, scopes, created, expiry, last_used, last_used_ip "+
+		"FROM api_tokens "+
+		"WHERE token_hash = $1 AND (expiry IS NULL OR expiry > $2)",
+		tokenHash, time.Now().UTC()).Scan(&e.ID, &e.UserID, &e.Name, &e.TokenHash, &scopes, &e.Created, &e.Expiry, &e.LastUsed, &e.LastUsedIP)
+	if err != nil {
+		return nil, err
+	}
+	e.Scopes = r.splitScopes(scopes)
+	return e, nil
+}
+
+func (r *ApiTokenRepository) GetAllByUserID(userID string) ([]*ApiToken, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, user_id, name, token_hash, scopes, created, expiry, last_used, last_used_ip "+
+		"FROM api_tokens "+
+		"WHERE user_id = $1 "+
+		"ORDER BY created DESC",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*ApiToken{}
+	for rows.Next() {
+		e := &ApiToken{}
+		var scopes string
+		err = rows.Scan(&e.ID, &e.UserID, &e.Name, &e.TokenHash, &scopes, &e.Created, &e.Expiry, &e.LastUsed, &e.LastUsedIP)
+		if err != nil {
+			return nil, err
+		}
+		e.Scopes = r.splitScopes(scopes)
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *ApiTokenRepository) GetCountByUserID(userID string) (int, error) {
+	var res int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(*) FROM api_tokens WHERE user_id = $1", userID).Scan(&res)
+	return res, err
+}
+
+func (r *ApiTokenRepository) UpdateLastUsed(id string, ip string) error {
+	_, err := GetDatabase().DB().Exec("UPDATE api_tokens SET last_used = $2, last_used_ip = $3 WHERE id = $1",
+		id, time.Now().UTC(), ip)
+	return err
+}
+
+func (r *ApiTokenRepository) Delete(e *ApiToken) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM api_tokens WHERE id = $1", e.ID)
+	return err
+}
+
+func (r *ApiTokenRepository) DeleteAllByUserID(userID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM api_tokens WHERE user_id = $1", userID)
+	return err
+}
+
+// DeleteExpired deletes tokens which expired more than the given time ago.
+// Expired tokens are kept for a while so their owners can see why an
+// integration stopped working.
+func (r *ApiTokenRepository) DeleteExpired(keep time.Duration) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM api_tokens WHERE expiry < $1", time.Now().UTC().Add(-keep))
+	return err
+}
+
+func (r *ApiTokenRepository) splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
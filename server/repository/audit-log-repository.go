@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+)
+
+type AuditLogRepository struct {
+}
+
+// AuditLogEntry records a change made by a user to an entity of an
+// organization.
+type AuditLogEntry struct {
+	ID             string
+	OrganizationID string
+	Timestamp      time.Time
+	ActorUserID    string
+	ActorEmail     string
+	Action         string
+	EntityType     string
+	EntityID       string
+	EntityName     string
+	IP             string
+	// JSON object mapping changed attributes to their old and new values
+	Changes string
+}
+
+const (
+	AuditActionCreate        = "create"
+	AuditActionUpdate        = "update"
+	AuditActionDelete        = "delete"
+	AuditActionApprove       = "approve"
+	AuditActionDecline       = "decline"
+	AuditActionResetTotp     = "reset_totp"
+	AuditActionResetPasskeys = "reset_passkeys"
+	AuditActionVerify        = "verify"
+)
+
+const (
+	AuditEntityLocation      = "location"
+	AuditEntitySpace         = "space"
+	AuditEntityGroup         = "group"
+	AuditEntityUser          = "user"
+	AuditEntitySetting       = "setting"
+	AuditEntityBooking       = "booking"
+	AuditEntityAuthProvider  = "auth_provider"
+	AuditEntityDomain        = "domain"
+	AuditEntityApiToken      = "api_token"
+	AuditEntityRole          = "role"
+	AuditEntityUserAttribute = "user_attribute"
+	AuditEntityWebhook       = "webhook"
+	AuditEntityEmailTemplate = "email_template"
+	AuditEntityMailSettings  = "mail_settings"
+)
+
+type AuditLogFilter struct {
+	OrganizationID string
+	Start          time.Time
+	End            time.Time
+	ActorLike      string
+	Action         string
+	EntityType     string
+	EntityID       string
+}
+
+var auditLogRepository *AuditLogRepository
+var auditLogRepositoryOnce sync.Once
+
+func GetAuditLogRepository() *AuditLogRepository {
+	auditLogRepositoryOnce.Do(func() {
+		auditLogRepository = &AuditLogRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS audit_log (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NOT NULL, " +
+			"timestamp TIMESTAMP NOT NULL, " +
+			"actor_user_id uuid NULL, " +
+			"actor_email VARCHAR NOT NULL DEFAULT '', " +
+			"action VARCHAR NOT NULL, " +
+			"entity_type VARCHAR NOT NULL, " +
+			"entity_id VARCHAR NOT NULL DEFAULT '', " +
+			"entity_name VARCHAR NOT NULL DEFAULT '', " +
+			"ip VARCHAR NOT NULL DEFAULT '', " +
+			"changes TEXT NOT NULL DEFAULT '{}', " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		_, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_audit_log_organization_id ON audit_log(organization_id, timestamp)")
+		if err != nil {
+			panic(err)
+		}
+	})
+	return auditLogRepository
+}
+
+func (r *AuditLogRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *AuditLogRepository) Create(e *AuditLogEntry) error {
+	var id string
+	var actorUserID interface{}
+	if e.ActorUserID != "" {
+		actorUserID = e.ActorUserID
+	}
+	if e.Changes == "" {
+		e.Changes = "{}"
+	}
+	err := GetDatabase().DB().QueryRow("INSERT INTO audit_log "+
+		"(organization_id, timestamp, actor_user_id, actor_email, action, entity_type, entity_id, entity_name, ip, changes) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) "+
+		"RETURNING id",
+		e.OrganizationID, e.Timestamp, actorUserID, e.ActorEmail, e.Action, e.EntityType, e.EntityID, e.EntityName, e.IP, e.Changes).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *AuditLogRepository) buildFilterQuery(f *AuditLogFilter) (string, []interface{}) {
+	where := "WHERE organization_id = $1 AND timestamp BETWEEN $2 AND $3"
+	args := []interface{}{f.OrganizationID, f.Start, f.End}
+	if f.ActorLike != "" {
+		args = append(args, "%"+f.ActorLike+"%")
+		where += " AND actor_email ILIKE $" + strconv.Itoa(len(args))
+	}
+	if f.Action != "" {
+		args = append(args, f.Action)
+		where += " AND action = $" + strconv.Itoa(len(args))
+	}
+	if f.EntityType != "" {
+		args = append(args, f.EntityType)
+		where += " AND entity_type = $" + strconv.Itoa(len(args))
+	}
+	if f.EntityID != "" {
+		args = append(args, f.EntityID)
+		where += " AND entity_id = $" + strconv.Itoa(len(args))
+	}
+	return where, args
+}
+
+// GetFiltered returns the matching entries, newest first. A maxResults of 0
+// returns all matching entries.
+func (r *AuditLogRepository) GetFiltered(f *AuditLogFilter, maxResults, offset int) ([]*AuditLogEntry, error) {
+	where, args := r.buildFilterQuery(f)
+	limit := ""
+	if maxResults > 0 {
+		args = append(args, maxResults, offset)
+		limit = " LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+	}
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, timestamp, actor_user_id, actor_email, "+
+		"action, entity_type, entity_id, entity_name, ip, changes "+
+		"FROM audit_log "+
+		where+" "+
+		"ORDER BY timestamp DESC"+limit, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*AuditLogEntry{}
+	for rows.Next() {
+		e := &AuditLogEntry{}
+		var actorUserID NullUUID
+		err = rows.Scan(&e.ID, &e.OrganizationID, &e.Timestamp, &actorUserID, &e.ActorEmail,
+			&e.Action, &e.EntityType, &e.EntityID, &e.EntityName, &e.IP, &e.Changes)
+		if err != nil {
+			return nil, err
+		}
+		e.ActorUserID = string(actorUserID)
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *AuditLogRepository) CountFiltered(f *AuditLogFilter) (int, error) {
+	where, args := r.buildFilterQuery(f)
+	var count int
+	if err := GetDatabase().DB().QueryRow("SELECT COUNT(id) FROM audit_log "+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *AuditLogRepository) DeleteAll(organizationID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM audit_log WHERE organization_id = $1", organizationID)
+	return err
+}
+
+func (r *AuditLogRepository) PurgeOld(maxAge time.Duration, batchSize int) (int, error) {
+	limit := time.Now().Add(-maxAge)
+	result, err := GetDatabase().DB().Exec("DELETE FROM audit_log WHERE id IN ("+
+		"SELECT id FROM audit_log WHERE timestamp < $1 ORDER BY timestamp ASC LIMIT $2)",
+		limit, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	num, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(num), nil
+}
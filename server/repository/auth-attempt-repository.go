@@ -34,6 +34,13 @@ const (
 	AuthMethodPasskey2FA = "passkey_2fa"
 	AuthMethodOAuth      = "oauth"
 	AuthMethodConfluence = "confluence"
+
+	AuthMethodClientCredentials = "client_credentials"
+	AuthMethodRefreshToken      = "refresh_token"
+	AuthMethodMagicLink         = "magic_link"
+	AuthMethodAccessToken       = "access_token"
+	AuthMethodApiToken          = "api_token"
+	AuthMethodServiceAccount    = "service_account"
 )
 
 const (
@@ -63,6 +70,10 @@ const (
 	AuthErrorOrgMismatch           = "org_mismatch"
 	AuthErrorInternal              = "internal_error"
 	AuthErrorConfluenceJwtInvalid  = "confluence_jwt_invalid"
+	AuthErrorNoServiceAccount      = "no_service_account"
+	AuthErrorRefreshTokenReuse     = "refresh_token_reuse"
+	AuthErrorNetworkNotAllowed     = "network_not_allowed"
+	AuthErrorSecondFactorRequired  = "second_factor_required"
 )
 
 const maxAuthErrorDetailLength = 2000
@@ -65,6 +65,30 @@ func (r *AuthStateStore) Delete(e *AuthState) error {
 	return err
 }
 
+// Consume deletes the auth state and reports whether it still existed, so
+// single-use states cannot be redeemed twice by concurrent requests.
+func (r *AuthStateStore) Consume(e *AuthState) (bool, error) {
+	res, err := GetDatabase().DB().Exec("DELETE FROM auth_states WHERE id = $1", e.ID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// Restore re-creates a consumed auth state with its original ID, e.g. if the
+// action it authorized failed.
+func (r *AuthStateStore) Restore(e *AuthState) error {
+	_, err := GetDatabase().DB().Exec("INSERT INTO auth_states "+
+		"(id, auth_provider_id, expiry, auth_state_type, payload) "+
+		"VALUES ($1, $2, $3, $4, $5)",
+		e.ID, e.AuthProviderID, e.Expiry, e.AuthStateType, e.Payload)
+	return err
+}
+
 // GetOneActive returns the auth state with the given ID if it has not expired yet.
 // Expiry is compared in SQL as timestamps round-trip as wall-clock time.
 func (r *AuthStateStore) GetOneActive(id string) (*AuthState, error) {
@@ -110,6 +134,15 @@ func (r *AuthStateStore) GetActiveByPayloadAndType(payload string, authStateType
 	return result, nil
 }
 
+// DeleteByPayloadPrefix deletes all auth states of the given type whose
+// payload starts with prefix.
+func (r *AuthStateStore) DeleteByPayloadPrefix(authStateType AuthStateType, prefix string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM auth_states "+
+		"WHERE auth_state_type = $1 AND LEFT(payload, LENGTH($2)) = $2",
+		authStateType, prefix)
+	return err
+}
+
 func (r *AuthStateStore) DeleteExpired() error {
 	now := time.Now()
 	_, err := GetDatabase().DB().Exec("DELETE FROM auth_states WHERE expiry < $1", now)
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type BookingCancellationRepository struct {
+}
+
+// BookingCancellation records a cancelled booking for reporting. Names are
+// copied at the time of cancellation, as the booking is deleted and spaces
+// or locations may be deleted later on.
+type BookingCancellation struct {
+	ID                string
+	OrganizationID    string
+	BookingID         string
+	UserID            string
+	UserEmail         string
+	SpaceID           string
+	SpaceName         string
+	LocationID        string
+	LocationName      string
+	Enter             time.Time
+	Leave             time.Time
+	Reason            string
+	CancelledByUserID string
+	CancelledByEmail  string
+	// Bulk is true if the booking has been cancelled together with others
+	Bulk    bool
+	Created time.Time
+}
+
+var bookingCancellationRepository *BookingCancellationRepository
+var bookingCancellationRepositoryOnce sync.Once
+
+func GetBookingCancellationRepository() *BookingCancellationRepository {
+	bookingCancellationRepositoryOnce.Do(func() {
+		bookingCancellationRepository = &BookingCancellationRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS booking_cancellations (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NOT NULL, " +
+			"booking_id uuid NOT NULL, " +
+			"user_id uuid NOT NULL, " +
+			"user_email VARCHAR NOT NULL DEFAULT '', " +
+			"space_id uuid NOT NULL, " +
+			"space_name VARCHAR NOT NULL DEFAULT '', " +
+			"location_id uuid NOT NULL, " +
+			"location_name VARCHAR NOT NULL DEFAULT '', " +
+			"enter_time TIMESTAMP NOT NULL, " +
+			"leave_time TIMESTAMP NOT NULL, " +
+			"reason VARCHAR NOT NULL DEFAULT '', " +
+			"cancelled_by_user_id uuid NOT NULL, " +
+			"cancelled_by_email VARCHAR NOT NULL DEFAULT '', " +
+			"bulk boolean NOT NULL DEFAULT FALSE, " +
+			"created TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_booking_cancellations_org ON booking_cancellations(organization_id, created)"); err != nil {
+			panic(err)
+		}
+	})
+	return bookingCancellationRepository
+}
+
+func (r *BookingCancellationRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *BookingCancellationRepository) Create(e *BookingCancellation) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO booking_cancellations "+
+		"(organization_id, booking_id, user_id, user_email, space_id, space_name, location_id, location_name, enter_time, leave_time, reason, cancelled_by_user_id, cancelled_by_email, bulk, created) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) "+
+		"RETURNING id",
+		e.OrganizationID, e.BookingID, e.UserID, e.UserEmail, e.SpaceID, e.SpaceName, e.LocationID, e.LocationName, e.Enter, e.Leave, e.Reason, e.CancelledByUserID, e.CancelledByEmail, e.Bulk, e.Created).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// GetAllByOrg returns the organization's cancellations within the given time
+// range, newest first. If locationID is set, only cancellations of bookings
+// in this location are returned.
+func (r *BookingCancellationRepository) GetAllByOrg(organizationID string, start, end time.Time, locationID string) ([]*BookingCancellation, error) {
+	query := "SELECT id, organization_id, booking_id, user_id, user_email, space_id, space_name, location_id, location_name, enter_time, leave_time, reason, cancelled_by_user_id, cancelled_by_email, bulk, created " +
+		"FROM booking_cancellations " +
+		"WHERE organization_id = $1 AND created >= $2 AND created <= $3"
+	args := []any{organizationID, start, end}
+	if locationID != "" {
+		query += fmt.Sprintf(" AND location_id = $%d", len(args)+1)
+		args = append(args, locationID)
+	}
+	query += " ORDER BY created DESC"
+	rows, err := GetDatabase().DB().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*BookingCancellation{}
+	for rows.Next() {
+		e := &BookingCancellation{}
+		if err = rows.Scan(&e.ID, &e.OrganizationID, &e.BookingID, &e.UserID, &e.UserEmail, &e.SpaceID, &e.SpaceName, &e.LocationID, &e.LocationName, &e.Enter, &e.Leave, &e.Reason, &e.CancelledByUserID, &e.CancelledByEmail, &e.Bulk, &e.Created); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// DeleteAll removes all cancellations of the organization.
+func (r *BookingCancellationRepository) DeleteAll(organizationID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM booking_cancellations WHERE organization_id = $1", organizationID)
+	return err
+}
@@ -22,6 +22,13 @@ type BookingPresenceItem struct {
 	Presence map[string]int
 }
 
+type BookingCountByAttributeValue struct {
+	Value       string
+	NumUsers    int
+	NumBookers  int
+	NumBookings int
+}
+
 // DateRange is an inclusive [Enter, Leave] window used by the stats queries.
 type DateRange struct {
 	Enter time.Time
@@ -115,6 +122,27 @@ func (r *BookingStore) RunSchemaUpgrade(curVersion, targetVersion int) {
 			panic(err)
 		}
 	}
+	if curVersion < 54 {
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE bookings " +
+			"ADD COLUMN IF NOT EXISTS created_by_user_id uuid NULL"); err != nil {
+			panic(err)
+		}
+	}
+	if curVersion < 59 {
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE bookings " +
+			"ADD COLUMN IF NOT EXISTS approval_level INTEGER NOT NULL DEFAULT 1, " +
+			"ADD COLUMN IF NOT EXISTS approval_requested_at_utc TIMESTAMP NULL DEFAULT NULL, " +
+			"ADD COLUMN IF NOT EXISTS approval_reminder_sent_at_utc TIMESTAMP NULL DEFAULT NULL, " +
+			"ADD COLUMN IF NOT EXISTS approval_escalation_group_id uuid NULL"); err != nil {
+			panic(err)
+		}
+	}
+	if curVersion < 60 {
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE bookings " +
+			"ADD COLUMN IF NOT EXISTS approval_first_approver_id uuid NULL"); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func (r *BookingStore) PurgeOldBookings(batchSize int) (int, error) {
@@ -175,10 +203,10 @@ func (r *BookingStore) PurgeOldBookings(batchSize int) (int, error) {
 func (r *BookingStore) Create(e *Booking) error {
 	var id string
 	err := GetDatabase().DB().QueryRow("INSERT INTO bookings "+
-		"(user_id, space_id, enter_time, leave_time, caldav_id, approved, subject, recurring_id, created_at_utc) "+
-		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) "+
+		"(user_id, space_id, enter_time, leave_time, caldav_id, approved, subject, recurring_id, created_at_utc, created_by_user_id) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) "+
 		"RETURNING id",
-		e.UserID, e.SpaceID, e.Enter, e.Leave, e.CalDavID, e.Approved, e.Subject, CheckNullUUID(e.RecurringID), time.Now().UTC()).Scan(&id)
+		e.UserID, e.SpaceID, e.Enter, e.Leave, e.CalDavID, e.Approved, e.Subject, CheckNullUUID(e.RecurringID), time.Now().UTC(), CheckNullUUID(e.CreatedByUserID)).Scan(&id)
 	if err != nil {
 		return err
 	}
@@ -189,15 +217,17 @@ func (r *BookingStore) Create(e *Booking) error {
 func (r *BookingStore) GetOne(id string) (*BookingDetails, error) {
 	e := &BookingDetails{}
 	err := GetDatabase().DB().QueryRow("SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, "+
+		"bookings.approval_level, COALESCE(bookings.approval_requested_at_utc, bookings.created_at_utc), bookings.approval_reminder_sent_at_utc, bookings.approval_escalation_group_id, bookings.approval_first_approver_id, "+
 		"spaces.id, spaces.location_id, spaces.name, "+
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, "+
-		"users.email, users.firstname, users.lastname "+
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') "+
 		"FROM bookings "+
 		"INNER JOIN spaces ON bookings.space_id = spaces.id "+
 		"INNER JOIN locations ON spaces.location_id = locations.id "+
 		"INNER JOIN users ON bookings.user_id = users.id "+
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id "+
 		"WHERE bookings.id = $1",
-		id).Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		id).Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.ApprovalLevel, &e.ApprovalRequestedAtUTC, &e.ApprovalReminderSentAtUTC, &e.ApprovalEscalationGroupID, &e.ApprovalFirstApproverID, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 	if err != nil {
 		return nil, err
 	}
@@ -258,14 +288,15 @@ func (r *BookingStore) GetFirstUpcomingOrCurrentBookingByUserID(userID string) (
 	err := GetDatabase().DB().QueryRow("SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, "+
 		"spaces.id, spaces.location_id, spaces.name, "+
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, "+
-		"users.email, users.firstname, users.lastname "+
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') "+
 		"FROM bookings "+
 		"INNER JOIN spaces ON bookings.space_id = spaces.id "+
 		"INNER JOIN locations ON spaces.location_id = locations.id "+
 		"INNER JOIN users ON bookings.user_id = users.id "+
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id "+
 		"WHERE bookings.user_id = $1 AND bookings.leave_time > $2 "+
 		"ORDER BY bookings.enter_time ASC LIMIT 1",
-		userID, time.Now()).Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		userID, time.Now()).Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 	if err != nil {
 		return nil, err
 	}
@@ -277,11 +308,12 @@ func (r *BookingStore) GetAllByOrg(organizationID string, startTime, endTime tim
 	query := "SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, " +
 		"spaces.id, spaces.location_id, spaces.name, " +
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, " +
-		"users.email, users.firstname, users.lastname " +
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') " +
 		"FROM bookings " +
 		"INNER JOIN spaces ON bookings.space_id = spaces.id " +
 		"INNER JOIN locations ON spaces.location_id = locations.id " +
 		"INNER JOIN users ON bookings.user_id = users.id " +
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id " +
 		"WHERE locations.organization_id = $1 AND enter_time >= $2 AND leave_time <= $3"
 	args := []any{organizationID, startTime, endTime}
 	if userEmail != "" {
@@ -300,7 +332,52 @@ func (r *BookingStore) GetAllByOrg(organizationID string, startTime, endTime tim
 	defer rows.Close()
 	for rows.Next() {
 		e := &BookingDetails{}
-		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// GetAllOverlappingByOrg returns the organization's bookings which overlap the
+// given time range and have not ended yet, optionally restricted to a
+// location, space and user.
+func (r *BookingStore) GetAllOverlappingByOrg(organizationID string, startTime, endTime time.Time, locationID, spaceID, userID string) ([]*BookingDetails, error) {
+	var result []*BookingDetails
+	query := "SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, " +
+		"spaces.id, spaces.location_id, spaces.name, " +
+		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, " +
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') " +
+		"FROM bookings " +
+		"INNER JOIN spaces ON bookings.space_id = spaces.id " +
+		"INNER JOIN locations ON spaces.location_id = locations.id " +
+		"INNER JOIN users ON bookings.user_id = users.id " +
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id " +
+		"WHERE locations.organization_id = $1 AND enter_time < $3 AND leave_time > $2 AND leave_time >= NOW()"
+	args := []any{organizationID, startTime, endTime}
+	if locationID != "" {
+		query += fmt.Sprintf(" AND locations.id = $%d", len(args)+1)
+		args = append(args, locationID)
+	}
+	if spaceID != "" {
+		query += fmt.Sprintf(" AND spaces.id = $%d", len(args)+1)
+		args = append(args, spaceID)
+	}
+	if userID != "" {
+		query += fmt.Sprintf(" AND users.id = $%d", len(args)+1)
+		args = append(args, userID)
+	}
+	query += " ORDER BY enter_time"
+	rows, err := GetDatabase().DB().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &BookingDetails{}
+		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 		if err != nil {
 			return nil, err
 		}
@@ -314,11 +391,12 @@ func (r *BookingStore) GetAllCurrentByOrg(organizationID string, userEmail strin
 	query := "SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, " +
 		"spaces.id, spaces.location_id, spaces.name, " +
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, " +
-		"users.email, users.firstname, users.lastname " +
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') " +
 		"FROM bookings " +
 		"INNER JOIN spaces ON bookings.space_id = spaces.id " +
 		"INNER JOIN locations ON spaces.location_id = locations.id " +
 		"INNER JOIN users ON bookings.user_id = users.id " +
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id " +
 		"CROSS JOIN LATERAL (SELECT COALESCE(NULLIF(locations.tz, ''), NULLIF((SELECT value FROM settings WHERE organization_id = $1 AND name = 'default_timezone'), ''), 'UTC') AS tz) AS effective_tz " +
 		"WHERE locations.organization_id = $1 " +
 		"AND enter_time <= (NOW() AT TIME ZONE effective_tz.tz) " +
@@ -341,7 +419,7 @@ func (r *BookingStore) GetAllCurrentByOrg(organizationID string, userEmail strin
 	defer rows.Close()
 	for rows.Next() {
 		e := &BookingDetails{}
-		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 		if err != nil {
 			return nil, err
 		}
@@ -355,11 +433,12 @@ func (r *BookingStore) GetAllByUser(userID string, startTime time.Time) ([]*Book
 	rows, err := GetDatabase().DB().Query("SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, "+
 		"spaces.id, spaces.location_id, spaces.name, "+
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, "+
-		"users.email, users.firstname, users.lastname "+
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') "+
 		"FROM bookings "+
 		"INNER JOIN spaces ON bookings.space_id = spaces.id "+
 		"INNER JOIN locations ON spaces.location_id = locations.id "+
 		"INNER JOIN users ON bookings.user_id = users.id "+
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id "+
 		"WHERE user_id = $1 AND leave_time >= $2 "+
 		"ORDER BY enter_time", userID, startTime)
 	if err != nil {
@@ -368,7 +447,7 @@ func (r *BookingStore) GetAllByUser(userID string, startTime time.Time) ([]*Book
 	defer rows.Close()
 	for rows.Next() {
 		e := &BookingDetails{}
-		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 		if err != nil {
 			return nil, err
 		}
@@ -382,11 +461,12 @@ func (r *BookingStore) GetAllByRecurringID(recurringID string) ([]*BookingDetail
 	rows, err := GetDatabase().DB().Query("SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, "+
 		"spaces.id, spaces.location_id, spaces.name, "+
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, "+
-		"users.email, users.firstname, users.lastname "+
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') "+
 		"FROM bookings "+
 		"INNER JOIN spaces ON bookings.space_id = spaces.id "+
 		"INNER JOIN locations ON spaces.location_id = locations.id "+
 		"INNER JOIN users ON bookings.user_id = users.id "+
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id "+
 		"WHERE recurring_id = $1 "+
 		"ORDER BY enter_time", recurringID)
 	if err != nil {
@@ -395,7 +475,7 @@ func (r *BookingStore) GetAllByRecurringID(recurringID string) ([]*BookingDetail
 	defer rows.Close()
 	for rows.Next() {
 		e := &BookingDetails{}
-		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 		if err != nil {
 			return nil, err
 		}
@@ -430,16 +510,51 @@ func (r *BookingStore) SetReminderSent(id string, t *time.Time) error {
 	return err
 }
 
+// SetApprovalLevel moves a pending booking from the previous to the given
+// approval level and records the user who approved the previous level. The
+// reminder and escalation state is reset for the new level. Returns false if
+// the booking has been approved, declined or moved to another level in the
+// meantime.
+func (r *BookingStore) SetApprovalLevel(id string, level int, approverID string) (bool, error) {
+	res, err := GetDatabase().DB().Exec("UPDATE bookings SET "+
+		"approval_level = $1, "+
+		"approval_requested_at_utc = $2, "+
+		"approval_reminder_sent_at_utc = NULL, "+
+		"approval_escalation_group_id = NULL, "+
+		"approval_first_approver_id = $3 "+
+		"WHERE id = $4 AND approved = false AND approval_level = $5",
+		level, time.Now().UTC(), CheckNullUUID(NullUUID(approverID)), id, level-1)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (r *BookingStore) SetApprovalReminderSent(id string, t *time.Time) error {
+	_, err := GetDatabase().DB().Exec("UPDATE bookings SET approval_reminder_sent_at_utc = $1 WHERE id = $2", t, id)
+	return err
+}
+
+func (r *BookingStore) SetApprovalEscalated(id string, groupID string) error {
+	_, err := GetDatabase().DB().Exec("UPDATE bookings SET approval_escalation_group_id = $1 WHERE id = $2", groupID, id)
+	return err
+}
+
 func (r *BookingStore) GetBookingsDueForReminder(batchSize int) ([]*BookingDetails, error) {
 	var result []*BookingDetails
 	rows, err := GetDatabase().DB().Query("SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, bookings.created_at_utc, bookings.reminder_sent_at_utc, "+
 		"spaces.id, spaces.location_id, spaces.name, "+
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, "+
-		"users.email, users.firstname, users.lastname "+
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') "+
 		"FROM bookings "+
 		"INNER JOIN spaces ON bookings.space_id = spaces.id "+
 		"INNER JOIN locations ON spaces.location_id = locations.id "+
 		"INNER JOIN users ON bookings.user_id = users.id "+
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id "+
 		"WHERE bookings.reminder_sent_at_utc IS NULL "+
 		"AND bookings.approved = true "+
 		"AND bookings.enter_time > (NOW() AT TIME ZONE 'UTC') + INTERVAL '20 hours' "+
@@ -454,7 +569,7 @@ func (r *BookingStore) GetBookingsDueForReminder(batchSize int) ([]*BookingDetai
 	defer rows.Close()
 	for rows.Next() {
 		e := &BookingDetails{}
-		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.CreatedAtUTC, &e.ReminderSentAtUTC, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 		if err != nil {
 			return nil, err
 		}
@@ -464,9 +579,24 @@ func (r *BookingStore) GetBookingsDueForReminder(batchSize int) ([]*BookingDetai
 }
 
 func (r *BookingStore) Delete(e *BookingDetails) error {
-	_, err := GetDatabase().DB().Exec("DELETE FROM bookings WHERE id = $1", e.ID)
+	_, err := r.delete(e, "")
+	return err
+}
+
+// DeletePending deletes the booking if it is still pending approval. Returns
+// false if it has been approved or deleted in the meantime.
+func (r *BookingStore) DeletePending(e *BookingDetails) (bool, error) {
+	return r.delete(e, " AND approved = false")
+}
+
+func (r *BookingStore) delete(e *BookingDetails, condition string) (bool, error) {
+	res, err := GetDatabase().DB().Exec("DELETE FROM bookings WHERE id = $1"+condition, e.ID)
 	if err != nil {
-		return err
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
 	}
 
 	// also delete the recurring booking if the last booking (of the series) was deleted
@@ -475,10 +605,25 @@ func (r *BookingStore) Delete(e *BookingDetails) error {
 			"WHERE id = $1 "+
 			"AND (SELECT COUNT(*) FROM bookings WHERE recurring_id = $1) = 0", e.RecurringID)
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
-	return nil
+	return rows > 0, nil
+}
+
+// SetApproved approves the booking if it is still pending approval. Returns
+// false if it has been approved or deleted in the meantime.
+func (r *BookingStore) SetApproved(id string) (bool, error) {
+	res, err := GetDatabase().DB().Exec("UPDATE bookings SET approved = true, reminder_sent_at_utc = NULL "+
+		"WHERE id = $1 AND approved = false", id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
 }
 
 func (r *BookingStore) GetCountAll() (int, error) {
@@ -490,22 +635,28 @@ func (r *BookingStore) GetCountAll() (int, error) {
 
 // GetCountsSummary returns the booking counts needed by the stats summary in a
 // single pass over the organization's bookings, instead of one query each.
-func (r *BookingStore) GetCountsSummary(organizationID string, today, yesterday, thisWeek DateRange) (BookingCounts, error) {
+// If location is not nil, only bookings in that location are counted.
+func (r *BookingStore) GetCountsSummary(organizationID string, location *Location, today, yesterday, thisWeek DateRange) (BookingCounts, error) {
 	var res BookingCounts
-	err := GetDatabase().DB().QueryRow("SELECT COUNT(bookings.id), "+
-		"COUNT(bookings.id) FILTER (WHERE enter_time <= (NOW() AT TIME ZONE effective_tz.tz) AND leave_time >= (NOW() AT TIME ZONE effective_tz.tz)), "+
-		"COUNT(bookings.id) FILTER (WHERE enter_time <= $3 AND leave_time >= $2), "+
-		"COUNT(bookings.id) FILTER (WHERE enter_time <= $5 AND leave_time >= $4), "+
-		"COUNT(bookings.id) FILTER (WHERE enter_time <= $7 AND leave_time >= $6) "+
-		"FROM bookings "+
-		"INNER JOIN spaces ON spaces.id = bookings.space_id "+
-		"INNER JOIN locations ON locations.id = spaces.location_id "+
-		"CROSS JOIN LATERAL (SELECT COALESCE(NULLIF(locations.tz, ''), NULLIF((SELECT value FROM settings WHERE organization_id = $1 AND name = 'default_timezone'), ''), 'UTC') AS tz) AS effective_tz "+
-		"WHERE locations.organization_id = $1",
-		organizationID,
+	query := "SELECT COUNT(bookings.id), " +
+		"COUNT(bookings.id) FILTER (WHERE enter_time <= (NOW() AT TIME ZONE effective_tz.tz) AND leave_time >= (NOW() AT TIME ZONE effective_tz.tz)), " +
+		"COUNT(bookings.id) FILTER (WHERE enter_time <= $3 AND leave_time >= $2), " +
+		"COUNT(bookings.id) FILTER (WHERE enter_time <= $5 AND leave_time >= $4), " +
+		"COUNT(bookings.id) FILTER (WHERE enter_time <= $7 AND leave_time >= $6) " +
+		"FROM bookings " +
+		"INNER JOIN spaces ON spaces.id = bookings.space_id " +
+		"INNER JOIN locations ON locations.id = spaces.location_id " +
+		"CROSS JOIN LATERAL (SELECT COALESCE(NULLIF(locations.tz, ''), NULLIF((SELECT value FROM settings WHERE organization_id = $1 AND name = 'default_timezone'), ''), 'UTC') AS tz) AS effective_tz " +
+		"WHERE locations.organization_id = $1"
+	args := []any{organizationID,
 		today.Enter, today.Leave,
 		yesterday.Enter, yesterday.Leave,
-		thisWeek.Enter, thisWeek.Leave).
+		thisWeek.Enter, thisWeek.Leave}
+	if location != nil {
+		query += fmt.Sprintf(" AND spaces.location_id = $%d", len(args)+1)
+		args = append(args, location.ID)
+	}
+	err := GetDatabase().DB().QueryRow(query, args...).
 		Scan(&res.Total, &res.Current, &res.Today, &res.Yesterday, &res.ThisWeek)
 	return res, err
 }
@@ -751,9 +902,11 @@ func (r *BookingStore) GetConcurrent(location *Location, enter time.Time, leave
 	return max, nil
 }
 
-func (r *BookingStore) GetPresenceReport(organizationID string, location *Location, start time.Time, end time.Time, maxResults, offset int) ([]*BookingPresenceItem, error) {
+// GetPresenceReport returns the number of bookings per user and day. If userIDs
+// is not nil, the report is limited to these users.
+func (r *BookingStore) GetPresenceReport(organizationID string, location *Location, userIDs []string, start time.Time, end time.Time, maxResults, offset int) ([]*BookingPresenceItem, error) {
 	// Build list of users to include in report
-	users, err := GetUserRepository().GetAll(organizationID, maxResults, offset)
+	users, err := r.getPresenceReportUsers(organizationID, userIDs, maxResults, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -843,21 +996,117 @@ func (r *BookingStore) GetPresenceReport(organizationID string, location *Locati
 	return res, nil
 }
 
+func (r *BookingStore) getPresenceReportUsers(organizationID string, userIDs []string, maxResults, offset int) ([]*User, error) {
+	if userIDs == nil {
+		return GetUserRepository().GetAll(organizationID, maxResults, offset)
+	}
+	list, err := GetUserRepository().GetAllByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	users := []*User{}
+	for _, user := range list {
+		if user.OrganizationID == organizationID {
+			users = append(users, user)
+		}
+	}
+	if offset >= len(users) {
+		return []*User{}, nil
+	}
+	users = users[offset:]
+	if len(users) > maxResults {
+		users = users[:maxResults]
+	}
+	return users, nil
+}
+
+// GetCountByUserAttribute returns the number of users and bookings grouped by
+// the users' values of a user attribute. Users without a value are grouped
+// under the empty string.
+func (r *BookingStore) GetCountByUserAttribute(organizationID, attributeID string, location *Location, enter *time.Time, leave *time.Time) ([]*BookingCountByAttributeValue, error) {
+	args := []any{organizationID, attributeID}
+	conditions := ""
+	if enter != nil && leave != nil {
+		conditions += fmt.Sprintf(" AND b.enter_time >= $%d AND b.enter_time <= $%d", len(args)+1, len(args)+2)
+		args = append(args, *enter, *leave)
+	}
+	if location != nil {
+		conditions += fmt.Sprintf(" AND b.space_id IN (SELECT id FROM spaces WHERE location_id = $%d)", len(args)+1)
+		args = append(args, location.ID)
+	}
+	query := "SELECT COALESCE(v.value, '') AS attribute_value, COUNT(DISTINCT u.id), COUNT(DISTINCT b.user_id), COUNT(b.id) " +
+		"FROM users u " +
+		"LEFT JOIN user_attribute_values v ON v.user_id = u.id AND v.attribute_id = $2 " +
+		"LEFT JOIN bookings b ON b.user_id = u.id" + conditions + " " +
+		"WHERE u.organization_id = $1 " +
+		"GROUP BY attribute_value " +
+		"ORDER BY attribute_value"
+	rows, err := GetDatabase().DB().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []*BookingCountByAttributeValue{}
+	for rows.Next() {
+		e := &BookingCountByAttributeValue{}
+		if err := rows.Scan(&e.Value, &e.NumUsers, &e.NumBookers, &e.NumBookings); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+// bookingsRequiringApprovalCondition matches the pending bookings the user
+// may approve: bookings waiting for the approval level of one of the user's
+// groups, and bookings escalated to one of the user's groups. Users who
+// approved the first level may not approve the second level.
+const bookingsRequiringApprovalCondition = "bookings.approved = false AND " +
+	"bookings.leave_time >= NOW() - INTERVAL '24 hours' AND " +
+	"bookings.approval_first_approver_id IS DISTINCT FROM $1 AND (" +
+	"bookings.space_id IN (SELECT space_id FROM spaces_approvers WHERE level = bookings.approval_level AND group_id IN (" +
+	"SELECT group_id FROM users_groups WHERE user_id = $1" +
+	")) OR " +
+	"bookings.approval_escalation_group_id IN (SELECT group_id FROM users_groups WHERE user_id = $1)" +
+	")"
+
 func (r *BookingStore) GetBookingsRequiringApproval(approverUserID string) ([]*BookingDetails, error) {
+	return r.getPendingApprovals(bookingsRequiringApprovalCondition, approverUserID)
+}
+
+func (r *BookingStore) GetBookingsCountRequiringApproval(approverUserID string) (int, error) {
+	var count int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(1) "+
+		"FROM bookings "+
+		"WHERE "+bookingsRequiringApprovalCondition, approverUserID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetPendingApprovalsByOrg returns all bookings of the organization which are
+// still waiting for approval and have not ended yet in the location's time
+// zone.
+func (r *BookingStore) GetPendingApprovalsByOrg(organizationID string) ([]*BookingDetails, error) {
+	return r.getPendingApprovals("bookings.approved = false AND "+
+		"bookings.leave_time >= (NOW() AT TIME ZONE COALESCE(NULLIF(locations.tz, ''), NULLIF((SELECT value FROM settings WHERE organization_id = $1 AND name = 'default_timezone'), ''), 'UTC')) AND "+
+		"locations.organization_id = $1", organizationID)
+}
+
+func (r *BookingStore) getPendingApprovals(condition string, args ...any) ([]*BookingDetails, error) {
 	rows, err := GetDatabase().DB().Query("SELECT bookings.id, bookings.user_id, bookings.space_id, bookings.enter_time, bookings.leave_time, bookings.caldav_id, bookings.approved, bookings.subject, bookings.recurring_id, "+
+		"bookings.approval_level, COALESCE(bookings.approval_requested_at_utc, bookings.created_at_utc), bookings.approval_reminder_sent_at_utc, bookings.approval_escalation_group_id, bookings.approval_first_approver_id, "+
 		"spaces.id, spaces.location_id, spaces.name, "+
 		"locations.id, locations.organization_id, locations.name, locations.description, locations.tz, "+
-		"users.email, users.firstname, users.lastname "+
+		"users.email, users.firstname, users.lastname, bookings.created_by_user_id, COALESCE(creators.email, '') "+
 		"FROM bookings "+
 		"INNER JOIN spaces ON bookings.space_id = spaces.id "+
 		"INNER JOIN locations ON spaces.location_id = locations.id "+
 		"INNER JOIN users ON bookings.user_id = users.id "+
-		"WHERE bookings.approved = false AND "+
-		"bookings.leave_time >= NOW() - INTERVAL '24 hours' AND "+
-		"bookings.space_id IN (SELECT space_id FROM spaces_approvers WHERE group_id IN ("+
-		"SELECT group_id FROM users_groups WHERE user_id = $1"+
-		")) "+
-		"ORDER BY bookings.enter_time ASC", approverUserID)
+		"LEFT JOIN users creators ON bookings.created_by_user_id = creators.id "+
+		"WHERE "+condition+" "+
+		"ORDER BY bookings.enter_time ASC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -865,7 +1114,7 @@ func (r *BookingStore) GetBookingsRequiringApproval(approverUserID string) ([]*B
 	var result []*BookingDetails
 	for rows.Next() {
 		e := &BookingDetails{}
-		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname)
+		err = rows.Scan(&e.ID, &e.UserID, &e.SpaceID, &e.Enter, &e.Leave, &e.CalDavID, &e.Approved, &e.Subject, &e.RecurringID, &e.ApprovalLevel, &e.ApprovalRequestedAtUTC, &e.ApprovalReminderSentAtUTC, &e.ApprovalEscalationGroupID, &e.ApprovalFirstApproverID, &e.Space.ID, &e.Space.LocationID, &e.Space.Name, &e.Space.Location.ID, &e.Space.Location.OrganizationID, &e.Space.Location.Name, &e.Space.Location.Description, &e.Space.Location.Timezone, &e.UserEmail, &e.UserFirstname, &e.UserLastname, &e.CreatedByUserID, &e.CreatedByEmail)
 		if err != nil {
 			return nil, err
 		}
@@ -873,18 +1122,3 @@ func (r *BookingStore) GetBookingsRequiringApproval(approverUserID string) ([]*B
 	}
 	return result, nil
 }
-
-func (r *BookingStore) GetBookingsCountRequiringApproval(approverUserID string) (int, error) {
-	var count int
-	err := GetDatabase().DB().QueryRow("SELECT COUNT(1) "+
-		"FROM bookings "+
-		"WHERE approved = false AND "+
-		"leave_time >= NOW() - INTERVAL '24 hours' AND "+
-		"space_id IN (SELECT space_id FROM spaces_approvers WHERE group_id IN ("+
-		"SELECT group_id FROM users_groups WHERE user_id = $1"+
-		"))", approverUserID).Scan(&count)
-	if err != nil {
-		return 0, err
-	}
-	return count, nil
-}
@@ -10,7 +10,7 @@ import (
 )
 
 func RunDBSchemaUpdates() {
-	targetVersion := 52
+	targetVersion := 60
 	curVersion, err := GetSettingsRepository().GetGlobalInt(SettingDatabaseVersion.Name)
 	log.Printf("Initializing database with schema version %d (current: %d) …\n", targetVersion, curVersion)
 	if err != nil {
@@ -37,6 +37,21 @@ func RunDBSchemaUpdates() {
 		GetSessionRepository(),
 		GetPasskeyRepository(),
 		GetLocationFloorPlanRepository(),
+		GetSigningKeyRepository(),
+		GetApiTokenRepository(),
+		GetPasswordHistoryRepository(),
+		GetLoginHistoryRepository(),
+		GetAuditLogRepository(),
+		GetDelegationRepository(),
+		GetRoleRepository(),
+		GetUserAttributeRepository(),
+		GetWebhookRepository(),
+		GetMailQueueRepository(),
+		GetEmailTemplateRepository(),
+		GetMailSettingsRepository(),
+		GetMailDigestRepository(),
+		GetBookingCancellationRepository(),
+		GetPushSubscriptionRepository(),
 	}
 	for _, repository := range repositories {
 		repository.RunSchemaUpgrade(curVersion, targetVersion)
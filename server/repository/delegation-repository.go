@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+)
+
+type DelegationRepository struct {
+}
+
+// Delegation grants a user (the delegate) or all members of a group the
+// right to create, modify and cancel bookings on behalf of another user.
+type Delegation struct {
+	ID              string
+	OrganizationID  string
+	UserID          string
+	DelegateUserID  string
+	DelegateGroupID string
+	Created         time.Time
+	Expiry          *time.Time
+}
+
+type DelegationDetails struct {
+	Delegation
+	UserEmail         string
+	DelegateEmail     string
+	DelegateGroupName string
+}
+
+var delegationRepository *DelegationRepository
+var delegationRepositoryOnce sync.Once
+
+func GetDelegationRepository() *DelegationRepository {
+	delegationRepositoryOnce.Do(func() {
+		delegationRepository = &DelegationRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS delegations (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NOT NULL, " +
+			"user_id uuid NOT NULL, " +
+			"delegate_user_id uuid NULL, " +
+			"delegate_group_id uuid NULL, " +
+			"created TIMESTAMP NOT NULL, " +
+			"expiry TIMESTAMP NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_delegations_user_id ON delegations(user_id)"); err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_delegations_delegate_user_id ON delegations(delegate_user_id)"); err != nil {
+			panic(err)
+		}
+	})
+	return delegationRepository
+}
+
+func (r *DelegationRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *DelegationRepository) Create(e *Delegation) error {
+	var id string
+	e.Created = time.Now().UTC()
+	err := GetDatabase().DB().QueryRow("INSERT INTO delegations "+
+		"(organization_id, user_id, delegate_user_id, delegate_group_id, created, expiry) "+
+		"VALUES ($1, $2, $3, $4, $5, $6) "+
+		"RETURNING id",
+		e.OrganizationID, e.UserID, CheckNullUUID(NullUUID(e.DelegateUserID)), CheckNullUUID(NullUUID(e.DelegateGroupID)), e.Created, e.Expiry).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *DelegationRepository) GetOne(id string) (*Delegation, error) {
+	e := &Delegation{}
+	var delegateUserID, delegateGroupID NullUUID
+	err := GetDatabase().DB().QueryRow("SELECT id, organization_id, user_id, delegate_user_id, delegate_group_id, created, expiry "+
+		"FROM delegations "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.OrganizationID, &e.UserID, &delegateUserID, &delegateGroupID, &e.Created, &e.Expiry)
+	if err != nil {
+		return nil, err
+	}
+	e.DelegateUserID = string(delegateUserID)
+	e.DelegateGroupID = string(delegateGroupID)
+	return e, nil
+}
+
+// GetAllByUser returns all delegations granted by the specified user,
+// including expired ones.
+func (r *DelegationRepository) GetAllByUser(userID string) ([]*DelegationDetails, error) {
+	rows, err := GetDatabase().DB().Query("SELECT delegations.id, delegations.organization_id, delegations.user_id, "+
+		"delegations.delegate_user_id, delegations.delegate_group_id, delegations.created, delegations.expiry, "+
+		"users.email, COALESCE(delegates.email, ''), COALESCE(groups.name, '') "+
+		"FROM delegations "+
+		"INNER JOIN users ON delegations.user_id = users.id "+
+		"LEFT JOIN users delegates ON delegations.delegate_user_id = delegates.id "+
+		"LEFT JOIN groups ON delegations.delegate_group_id = groups.id "+
+		"WHERE delegations.user_id = $1 "+
+		"ORDER BY delegations.created", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []*DelegationDetails
+	for rows.Next() {
+		e := &DelegationDetails{}
+		var delegateUserID, delegateGroupID NullUUID
+		err = rows.Scan(&e.ID, &e.OrganizationID, &e.UserID, &delegateUserID, &delegateGroupID, &e.Created, &e.Expiry,
+			&e.UserEmail, &e.DelegateEmail, &e.DelegateGroupName)
+		if err != nil {
+			return nil, err
+		}
+		e.DelegateUserID = string(delegateUserID)
+		e.DelegateGroupID = string(delegateGroupID)
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// GetPrincipals returns the users who have delegated their bookings to the
+// specified user, either directly or through one of the user's groups.
+// Expired delegations are ignored.
+func (r *DelegationRepository) GetPrincipals(delegateUserID string) ([]*User, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, email, firstname, lastname "+
+		"FROM users "+
+		"WHERE id IN ("+
+		"SELECT user_id FROM delegations "+
+		"WHERE (expiry IS NULL OR expiry > $2) AND "+
+		"(delegate_user_id = $1 OR delegate_group_id IN (SELECT group_id FROM users_groups WHERE user_id = $1))"+
+		") AND id != $1 "+
+		"ORDER BY email", delegateUserID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []*User
+	for rows.Next() {
+		e := &User{}
+		err = rows.Scan(&e.ID, &e.Email, &e.Firstname, &e.Lastname)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// IsDelegate returns true if the delegate user may manage the bookings of
+// the specified user.
+func (r *DelegationRepository) IsDelegate(userID, delegateUserID string) (bool, error) {
+	var res int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(id) "+
+		"FROM delegations "+
+		"WHERE user_id = $1 AND (expiry IS NULL OR expiry > $3) AND "+
+		"(delegate_user_id = $2 OR delegate_group_id IN (SELECT group_id FROM users_groups WHERE user_id = $2))",
+		userID, delegateUserID, time.Now().UTC()).Scan(&res)
+	if err != nil {
+		return false, err
+	}
+	return res > 0, nil
+}
+
+func (r *DelegationRepository) Delete(e *Delegation) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM delegations WHERE id = $1", e.ID)
+	return err
+}
+
+func (r *DelegationRepository) DeleteAll(organizationID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM delegations WHERE organization_id = $1", organizationID)
+	return err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+type EmailTemplateRepository struct {
+}
+
+// EmailTemplate is an organization's replacement for a built-in email
+// template in one language.
+type EmailTemplate struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	Language       string
+	Subject        string
+	Headline       string
+	BodyHTML       string
+	BodyText       string
+	Updated        time.Time
+}
+
+var emailTemplateRepository *EmailTemplateRepository
+var emailTemplateRepositoryOnce sync.Once
+
+func GetEmailTemplateRepository() *EmailTemplateRepository {
+	emailTemplateRepositoryOnce.Do(func() {
+		emailTemplateRepository = &EmailTemplateRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS email_templates (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NOT NULL, " +
+			"name VARCHAR NOT NULL, " +
+			"language VARCHAR NOT NULL, " +
+			"subject VARCHAR NOT NULL, " +
+			"headline VARCHAR NOT NULL DEFAULT '', " +
+			"body_html TEXT NOT NULL, " +
+			"body_text TEXT NOT NULL DEFAULT '', " +
+			"updated TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		_, err = GetDatabase().DB().Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_email_templates_organization_name_language ON email_templates(organization_id, name, language)")
+		if err != nil {
+			panic(err)
+		}
+	})
+	return emailTemplateRepository
+}
+
+func (r *EmailTemplateRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// No updates yet
+}
+
+// Save creates the template or replaces the existing one with the same
+// organization, name and language.
+func (r *EmailTemplateRepository) Save(e *EmailTemplate) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO email_templates "+
+		"(organization_id, name, language, subject, headline, body_html, body_text, updated) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) "+
+		"ON CONFLICT (organization_id, name, language) DO UPDATE SET "+
+		"subject = EXCLUDED.subject, headline = EXCLUDED.headline, body_html = EXCLUDED.body_html, body_text = EXCLUDED.body_text, updated = EXCLUDED.updated "+
+		"RETURNING id",
+		e.OrganizationID, e.Name, e.Language, e.Subject, e.Headline, e.BodyHTML, e.BodyText, e.Updated).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *EmailTemplateRepository) GetOne(organizationID, name, language string) (*EmailTemplate, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, name, language, subject, headline, body_html, body_text, updated "+
+		"FROM email_templates "+
+		"WHERE organization_id = $1 AND name = $2 AND language = $3",
+		organizationID, name, language)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list, err := r.scanTemplates(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return list[0], nil
+}
+
+func (r *EmailTemplateRepository) GetAll(organizationID string) ([]*EmailTemplate, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, name, language, subject, headline, body_html, body_text, updated "+
+		"FROM email_templates "+
+		"WHERE organization_id = $1 "+
+		"ORDER BY name, language",
+		organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanTemplates(rows)
+}
+
+func (r *EmailTemplateRepository) Delete(e *EmailTemplate) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM email_templates WHERE id = $1", e.ID)
+	return err
+}
+
+func (r *EmailTemplateRepository) DeleteAll(organizationID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM email_templates WHERE organization_id = $1", organizationID)
+	return err
+}
+
+func (r *EmailTemplateRepository) scanTemplates(rows *sql.Rows) ([]*EmailTemplate, error) {
+	result := []*EmailTemplate{}
+	for rows.Next() {
+		e := &EmailTemplate{}
+		err := rows.Scan(&e.ID, &e.OrganizationID, &e.Name, &e.Language, &e.Subject, &e.Headline, &e.BodyHTML, &e.BodyText, &e.Updated)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
@@ -194,6 +194,18 @@ func (r *GroupStore) Delete(e *Group) error {
 		"group_id = $1", e.ID); err != nil {
 		return err
 	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM delegations WHERE "+
+		"delegate_group_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM roles_assignments WHERE "+
+		"group_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_admins WHERE "+
+		"group_id = $1", e.ID); err != nil {
+		return err
+	}
 	_, err := GetDatabase().DB().Exec("DELETE FROM groups WHERE id = $1", e.ID)
 	return err
 }
@@ -253,6 +265,30 @@ func (r *GroupStore) GetUserCountMap(organizationID string) (map[string]int, err
 	return res, nil
 }
 
+// GetGroupNamesByUser returns the names of the groups each user of the
+// organization is a member of, keyed by user ID.
+func (r *GroupStore) GetGroupNamesByUser(organizationID string) (map[string][]string, error) {
+	res := make(map[string][]string)
+	rows, err := GetDatabase().DB().Query("SELECT users_groups.user_id, groups.name "+
+		"FROM users_groups "+
+		"INNER JOIN groups ON groups.id = users_groups.group_id "+
+		"WHERE groups.organization_id = $1 "+
+		"ORDER BY groups.name",
+		organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID, name string
+		if err = rows.Scan(&userID, &name); err != nil {
+			return nil, err
+		}
+		res[userID] = append(res[userID], name)
+	}
+	return res, nil
+}
+
 func (r *GroupStore) AddMembers(e *Group, userIDs []string) error {
 	sqlStr := "INSERT INTO users_groups (group_id, user_id) VALUES "
 	vals := []interface{}{}
@@ -27,6 +27,29 @@ type LocationGroup struct {
 	GroupID    string
 }
 
+// LocationAttributeRule allows users whose user attribute matches the value
+// (case-insensitive) to book spaces in the location.
+type LocationAttributeRule struct {
+	LocationID  string
+	AttributeID string
+	Value       string
+}
+
+// LocationAdmin grants a user or all members of a group the space admin
+// permissions for a single location.
+type LocationAdmin struct {
+	ID         string
+	LocationID string
+	UserID     string
+	GroupID    string
+}
+
+type LocationAdminDetails struct {
+	LocationAdmin
+	UserEmail string
+	GroupName string
+}
+
 var locationRepository *LocationStore
 var locationRepositoryOnce sync.Once
 
@@ -104,6 +127,31 @@ func (r *LocationStore) RunSchemaUpgrade(curVersion, targetVersion int) {
 			panic(err)
 		}
 	}
+	if curVersion < 55 {
+		if _, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS locations_admins (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"location_id uuid NOT NULL, " +
+			"user_id uuid NULL, " +
+			"group_id uuid NULL, " +
+			"PRIMARY KEY (id))"); err != nil {
+			panic(err)
+		}
+		if _, err := GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_locations_admins_location_id ON locations_admins(location_id)"); err != nil {
+			panic(err)
+		}
+		if _, err := GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_locations_admins_user_id ON locations_admins(user_id)"); err != nil {
+			panic(err)
+		}
+	}
+	if curVersion < 56 {
+		if _, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS locations_allowed_attributes (" +
+			"location_id uuid NOT NULL, " +
+			"attribute_id uuid NOT NULL, " +
+			"value VARCHAR NOT NULL, " +
+			"PRIMARY KEY (location_id, attribute_id, value))"); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func (r *LocationStore) Create(e *Location) error {
@@ -209,6 +257,15 @@ func (r *LocationStore) Delete(e *Location) error {
 	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_allowed_bookers WHERE location_id = $1", e.ID); err != nil {
 		return err
 	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM roles_assignments WHERE location_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_admins WHERE location_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_allowed_attributes WHERE location_id = $1", e.ID); err != nil {
+		return err
+	}
 
 	_, err := GetDatabase().DB().Exec("DELETE FROM locations WHERE id = $1", e.ID)
 	return err
@@ -237,6 +294,12 @@ func (r *LocationStore) DeleteAll(organizationID string) error {
 	if _, err := GetDatabase().DB().Exec("DELETE FROM spaces WHERE spaces.location_id IN (SELECT locations.id FROM locations WHERE locations.organization_id = $1)", organizationID); err != nil {
 		return err
 	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_admins WHERE locations_admins.location_id IN (SELECT locations.id FROM locations WHERE locations.organization_id = $1)", organizationID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_allowed_attributes WHERE locations_allowed_attributes.location_id IN (SELECT locations.id FROM locations WHERE locations.organization_id = $1)", organizationID); err != nil {
+		return err
+	}
 	_, err := GetDatabase().DB().Exec("DELETE FROM locations WHERE organization_id = $1", organizationID)
 	return err
 }
@@ -358,3 +421,139 @@ func (r *LocationStore) GetAllAllowedBookersForLocationList(locationIDs []string
 func (r *LocationStore) GetAllAllowedBookersForLocation(locationID string) ([]*LocationGroup, error) {
 	return r.GetAllAllowedBookersForLocationList([]string{locationID})
 }
+
+func (r *LocationStore) ReplaceAllowedAttributes(location *Location, rules []*LocationAttributeRule) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_allowed_attributes WHERE location_id = $1", location.ID); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if _, err := GetDatabase().DB().Exec("INSERT INTO locations_allowed_attributes (location_id, attribute_id, value) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+			location.ID, rule.AttributeID, rule.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *LocationStore) GetAllowedAttributesForLocationList(locationIDs []string) ([]*LocationAttributeRule, error) {
+	var result []*LocationAttributeRule
+	rows, err := GetDatabase().DB().Query("SELECT location_id, attribute_id, value "+
+		"FROM locations_allowed_attributes "+
+		"WHERE location_id = ANY($1::uuid[]) "+
+		"ORDER BY value",
+		pq.StringArray(locationIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &LocationAttributeRule{}
+		err = rows.Scan(&e.LocationID, &e.AttributeID, &e.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *LocationStore) GetAllowedAttributesForLocation(locationID string) ([]*LocationAttributeRule, error) {
+	return r.GetAllowedAttributesForLocationList([]string{locationID})
+}
+
+func (r *LocationStore) AddAdmin(e *LocationAdmin) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO locations_admins "+
+		"(location_id, user_id, group_id) "+
+		"VALUES ($1, $2, $3) "+
+		"RETURNING id",
+		e.LocationID, CheckNullUUID(NullUUID(e.UserID)), CheckNullUUID(NullUUID(e.GroupID))).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *LocationStore) GetAdmin(id string) (*LocationAdmin, error) {
+	e := &LocationAdmin{}
+	var userID, groupID NullUUID
+	err := GetDatabase().DB().QueryRow("SELECT id, location_id, user_id, group_id "+
+		"FROM locations_admins "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.LocationID, &userID, &groupID)
+	if err != nil {
+		return nil, err
+	}
+	e.UserID = string(userID)
+	e.GroupID = string(groupID)
+	return e, nil
+}
+
+func (r *LocationStore) GetAdmins(locationID string) ([]*LocationAdminDetails, error) {
+	var result []*LocationAdminDetails
+	rows, err := GetDatabase().DB().Query("SELECT locations_admins.id, locations_admins.location_id, "+
+		"locations_admins.user_id, locations_admins.group_id, "+
+		"COALESCE(users.email, ''), COALESCE(groups.name, '') "+
+		"FROM locations_admins "+
+		"LEFT JOIN users ON locations_admins.user_id = users.id "+
+		"LEFT JOIN groups ON locations_admins.group_id = groups.id "+
+		"WHERE locations_admins.location_id = $1 "+
+		"ORDER BY users.email, groups.name",
+		locationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &LocationAdminDetails{}
+		var userID, groupID NullUUID
+		err = rows.Scan(&e.ID, &e.LocationID, &userID, &groupID, &e.UserEmail, &e.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		e.UserID = string(userID)
+		e.GroupID = string(groupID)
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *LocationStore) RemoveAdmin(e *LocationAdmin) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM locations_admins WHERE id = $1", e.ID)
+	return err
+}
+
+// IsGroupAdmin returns true if the group has been assigned as admin of at
+// least one location.
+func (r *LocationStore) IsGroupAdmin(groupID string) (bool, error) {
+	var res int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(id) FROM locations_admins WHERE group_id = $1", groupID).Scan(&res)
+	if err != nil {
+		return false, err
+	}
+	return res > 0, nil
+}
+
+// GetAdminLocationIDs returns the IDs of all locations the specified user
+// administers, either directly or through one of the user's groups.
+func (r *LocationStore) GetAdminLocationIDs(userID string) ([]string, error) {
+	result := []string{}
+	rows, err := GetDatabase().DB().Query("SELECT DISTINCT location_id "+
+		"FROM locations_admins "+
+		"WHERE user_id = $1 OR "+
+		"group_id IN (SELECT group_id FROM users_groups WHERE user_id = $1)",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var locationID string
+		if err = rows.Scan(&locationID); err != nil {
+			return nil, err
+		}
+		result = append(result, locationID)
+	}
+	return result, nil
+}
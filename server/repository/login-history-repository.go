@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+type LoginHistoryRepository struct {
+}
+
+// LoginHistoryEntry is a successful interactive login of a user. Entries are
+// used as the baseline for detecting suspicious logins.
+type LoginHistoryEntry struct {
+	ID             string
+	UserID         string
+	OrganizationID string
+	Device         string
+	IP             string
+	Network        string
+	Created        time.Time
+	// Comma-separated suspicious login reasons, empty if the login was inconspicuous
+	Reasons      string
+	DigestSent   bool
+	UserEmail    string
+	UserFullName string
+}
+
+var loginHistoryRepository *LoginHistoryRepository
+var loginHistoryRepositoryOnce sync.Once
+
+func GetLoginHistoryRepository() *LoginHistoryRepository {
+	loginHistoryRepositoryOnce.Do(func() {
+		loginHistoryRepository = &LoginHistoryRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS login_history (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"user_id uuid NOT NULL, " +
+			"organization_id uuid NOT NULL, " +
+			"device VARCHAR NOT NULL DEFAULT '', " +
+			"ip VARCHAR NOT NULL DEFAULT '', " +
+			"network VARCHAR NOT NULL DEFAULT '', " +
+			"created TIMESTAMP NOT NULL, " +
+			"reasons VARCHAR NOT NULL DEFAULT '', " +
+			"digest_sent boolean NOT NULL DEFAULT FALSE, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_login_history_user_id ON login_history(user_id, created)"); err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_login_history_digest ON login_history(organization_id) WHERE reasons != '' AND digest_sent IS FALSE"); err != nil {
+			panic(err)
+		}
+	})
+	return loginHistoryRepository
+}
+
+func (r *LoginHistoryRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *LoginHistoryRepository) Create(e *LoginHistoryEntry) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO login_history "+
+		"(user_id, organization_id, device, ip, network, created, reasons, digest_sent) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) "+
+		"RETURNING id",
+		e.UserID, e.OrganizationID, e.Device, e.IP, e.Network, e.Created, e.Reasons, e.DigestSent).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// GetByUserIDSince returns the user's logins since the given time, newest first.
+func (r *LoginHistoryRepository) GetByUserIDSince(userID string, since time.Time) ([]*LoginHistoryEntry, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, user_id, organization_id, device, ip, network, created, reasons, digest_sent "+
+		"FROM login_history "+
+		"WHERE user_id = $1 AND created >= $2 "+
+		"ORDER BY created DESC",
+		userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*LoginHistoryEntry{}
+	for rows.Next() {
+		e := &LoginHistoryEntry{}
+		if err = rows.Scan(&e.ID, &e.UserID, &e.OrganizationID, &e.Device, &e.IP, &e.Network, &e.Created, &e.Reasons, &e.DigestSent); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// GetOrgIDsWithPendingDigest returns the IDs of organizations with suspicious
+// logins not yet reported to the org admins.
+func (r *LoginHistoryRepository) GetOrgIDsWithPendingDigest() ([]string, error) {
+	rows, err := GetDatabase().DB().Query("SELECT DISTINCT organization_id " +
+		"FROM login_history " +
+		"WHERE reasons != '' AND digest_sent IS FALSE")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// GetPendingDigest returns the organization's suspicious logins not yet
+// reported to the org admins, oldest first.
+func (r *LoginHistoryRepository) GetPendingDigest(organizationID string) ([]*LoginHistoryEntry, error) {
+	rows, err := GetDatabase().DB().Query("SELECT login_history.id, login_history.user_id, login_history.organization_id, "+
+		"login_history.device, login_history.ip, login_history.network, login_history.created, login_history.reasons, login_history.digest_sent, "+
+		"users.email, CONCAT_WS(' ', users.firstname, users.lastname) "+
+		"FROM login_history "+
+		"INNER JOIN users ON users.id = login_history.user_id "+
+		"WHERE login_history.organization_id = $1 AND login_history.reasons != '' AND login_history.digest_sent IS FALSE "+
+		"ORDER BY login_history.created",
+		organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*LoginHistoryEntry{}
+	for rows.Next() {
+		e := &LoginHistoryEntry{}
+		if err = rows.Scan(&e.ID, &e.UserID, &e.OrganizationID, &e.Device, &e.IP, &e.Network, &e.Created, &e.Reasons, &e.DigestSent, &e.UserEmail, &e.UserFullName); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *LoginHistoryRepository) SetDigestSent(organizationID string, until time.Time) error {
+	_, err := GetDatabase().DB().Exec("UPDATE login_history SET digest_sent = TRUE "+
+		"WHERE organization_id = $1 AND reasons != '' AND digest_sent IS FALSE AND created <= $2",
+		organizationID, until)
+	return err
+}
+
+// PurgeOld deletes logins older than maxAge.
+func (r *LoginHistoryRepository) PurgeOld(maxAge time.Duration) (int, error) {
+	res, err := GetDatabase().DB().Exec("DELETE FROM login_history WHERE created < $1", time.Now().Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+	num, err := res.RowsAffected()
+	return int(num), err
+}
+
+func (r *LoginHistoryRepository) DeleteAllByUserID(userID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM login_history WHERE user_id = $1", userID)
+	return err
+}
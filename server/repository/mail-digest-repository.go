@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type MailDigestRepository struct {
+}
+
+// MailDigestRecipient is a user who receives booking information as a daily
+// or weekly digest instead of one mail per event.
+type MailDigestRecipient struct {
+	UserID         string
+	OrganizationID string
+	// PreferenceMailNotificationsDailyDigest or PreferenceMailNotificationsWeeklyDigest
+	Mode     int
+	LastSent *time.Time
+}
+
+var mailDigestRepository *MailDigestRepository
+var mailDigestRepositoryOnce sync.Once
+
+func GetMailDigestRepository() *MailDigestRepository {
+	mailDigestRepositoryOnce.Do(func() {
+		mailDigestRepository = &MailDigestRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS users_mail_digests (" +
+			"user_id uuid NOT NULL, " +
+			"last_sent TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (user_id))")
+		if err != nil {
+			panic(err)
+		}
+	})
+	return mailDigestRepository
+}
+
+func (r *MailDigestRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// No updates yet
+}
+
+// GetRecipients returns all enabled users who have chosen a digest mode.
+func (r *MailDigestRepository) GetRecipients() ([]*MailDigestRecipient, error) {
+	var result []*MailDigestRecipient
+	rows, err := GetDatabase().DB().Query("SELECT users.id, users.organization_id, users_preferences.value, users_mail_digests.last_sent "+
+		"FROM users "+
+		"INNER JOIN users_preferences ON users_preferences.user_id = users.id AND users_preferences.name = $1 "+
+		"LEFT JOIN users_mail_digests ON users_mail_digests.user_id = users.id "+
+		"WHERE users.disabled = FALSE AND users_preferences.value IN ($2, $3) "+
+		"ORDER BY users.id",
+		PreferenceMailNotifications.Name,
+		strconv.Itoa(PreferenceMailNotificationsDailyDigest),
+		strconv.Itoa(PreferenceMailNotificationsWeeklyDigest))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &MailDigestRecipient{}
+		var mode string
+		if err := rows.Scan(&e.UserID, &e.OrganizationID, &mode, &e.LastSent); err != nil {
+			return nil, err
+		}
+		e.Mode, _ = strconv.Atoi(mode)
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *MailDigestRepository) SetSent(userID string, t time.Time) error {
+	_, err := GetDatabase().DB().Exec("INSERT INTO users_mail_digests (user_id, last_sent) "+
+		"VALUES ($1, $2) "+
+		"ON CONFLICT (user_id) DO UPDATE SET last_sent = $2",
+		userID, t)
+	return err
+}
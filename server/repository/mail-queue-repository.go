@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type MailQueueRepository struct {
+}
+
+type QueuedMailStatus int
+
+const (
+	QueuedMailStatusPending QueuedMailStatus = 0
+	// Dead messages are not retried automatically anymore
+	QueuedMailStatusDead QueuedMailStatus = 1
+)
+
+type QueuedMail struct {
+	ID             string
+	OrganizationID string
+	Recipient      string
+	RecipientName  string
+	Subject        string
+	Body           string
+	BodyText       string
+	Attachments    []*MailAttachment
+	Status         QueuedMailStatus
+	Attempts       int
+	NextAttempt    *time.Time
+	LastAttempt    *time.Time
+	Error          string
+	Created        time.Time
+}
+
+var mailQueueRepository *MailQueueRepository
+var mailQueueRepositoryOnce sync.Once
+
+const mailQueueColumns = "id, COALESCE(organization_id::text, ''), recipient, recipient_name, subject, body, body_text, attachments, status, attempts, next_attempt, last_attempt, error, created"
+
+func GetMailQueueRepository() *MailQueueRepository {
+	mailQueueRepositoryOnce.Do(func() {
+		mailQueueRepository = &MailQueueRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS mail_queue (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NULL, " +
+			"recipient VARCHAR NOT NULL, " +
+			"recipient_name VARCHAR NOT NULL DEFAULT '', " +
+			"subject VARCHAR NOT NULL, " +
+			"body TEXT NOT NULL, " +
+			"body_text TEXT NOT NULL DEFAULT '', " +
+			"attachments TEXT NOT NULL DEFAULT '[]', " +
+			"status INTEGER NOT NULL DEFAULT 0, " +
+			"attempts INTEGER NOT NULL DEFAULT 0, " +
+			"next_attempt TIMESTAMP NULL, " +
+			"last_attempt TIMESTAMP NULL, " +
+			"error VARCHAR NOT NULL DEFAULT '', " +
+			"created TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		_, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_mail_queue_organization_id ON mail_queue(organization_id, status)")
+		if err != nil {
+			panic(err)
+		}
+		_, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_mail_queue_next_attempt ON mail_queue(next_attempt) WHERE status = 0")
+		if err != nil {
+			panic(err)
+		}
+	})
+	return mailQueueRepository
+}
+
+func (r *MailQueueRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	if curVersion < 58 {
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE mail_queue " +
+			"ADD COLUMN IF NOT EXISTS body_text TEXT NOT NULL DEFAULT ''"); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (r *MailQueueRepository) Create(e *QueuedMail) error {
+	attachments, err := json.Marshal(e.Attachments)
+	if err != nil {
+		return err
+	}
+	var id string
+	err = GetDatabase().DB().QueryRow("INSERT INTO mail_queue "+
+		"(organization_id, recipient, recipient_name, subject, body, body_text, attachments, status, attempts, next_attempt, created) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) "+
+		"RETURNING id",
+		r.nullOrganizationID(e.OrganizationID), e.Recipient, e.RecipientName, e.Subject, e.Body, e.BodyText, string(attachments), e.Status, e.Attempts, e.NextAttempt, e.Created).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *MailQueueRepository) GetOne(id string) (*QueuedMail, error) {
+	rows, err := GetDatabase().DB().Query("SELECT "+mailQueueColumns+" FROM mail_queue WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list, err := r.scanMails(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return list[0], nil
+}
+
+// GetDead returns the dead messages of an organization, most recent first.
+func (r *MailQueueRepository) GetDead(organizationID string, maxResults, offset int) ([]*QueuedMail, error) {
+	rows, err := GetDatabase().DB().Query("SELECT "+mailQueueColumns+" FROM mail_queue "+
+		"WHERE organization_id = $1 AND status = $2 "+
+		"ORDER BY last_attempt DESC "+
+		"LIMIT $3 OFFSET $4",
+		organizationID, QueuedMailStatusDead, maxResults, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanMails(rows)
+}
+
+func (r *MailQueueRepository) GetCount(organizationID string, status QueuedMailStatus) (int, error) {
+	var res int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(*) FROM mail_queue "+
+		"WHERE organization_id = $1 AND status = $2",
+		organizationID, status).Scan(&res)
+	return res, err
+}
+
+func (r *MailQueueRepository) Update(e *QueuedMail) error {
+	_, err := GetDatabase().DB().Exec("UPDATE mail_queue SET "+
+		"status = $1, attempts = $2, next_attempt = $3, last_attempt = $4, error = $5 "+
+		"WHERE id = $6",
+		e.Status, e.Attempts, e.NextAttempt, e.LastAttempt, e.Error, e.ID)
+	return err
+}
+
+func (r *MailQueueRepository) Delete(e *QueuedMail) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM mail_queue WHERE id = $1", e.ID)
+	return err
+}
+
+// Retry puts a dead message back into the queue with a fresh attempt counter.
+func (r *MailQueueRepository) Retry(e *QueuedMail) error {
+	now := time.Now().UTC()
+	e.Status = QueuedMailStatusPending
+	e.Attempts = 0
+	e.NextAttempt = &now
+	e.Error = ""
+	return r.Update(e)
+}
+
+// ClaimDue returns up to maxResults pending messages which are due and
+// postpones them by lease so that concurrent workers skip them.
+func (r *MailQueueRepository) ClaimDue(maxResults int, lease time.Duration) ([]*QueuedMail, error) {
+	now := time.Now().UTC()
+	rows, err := GetDatabase().DB().Query("UPDATE mail_queue SET next_attempt = $1 "+
+		"WHERE id IN ("+
+		"SELECT id FROM mail_queue "+
+		"WHERE status = $2 AND next_attempt <= $3 "+
+		"ORDER BY next_attempt "+
+		"LIMIT $4 "+
+		"FOR UPDATE SKIP LOCKED) "+
+		"RETURNING "+mailQueueColumns,
+		now.Add(lease), QueuedMailStatusPending, now, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanMails(rows)
+}
+
+// Postpone reschedules a claimed message without counting an attempt.
+func (r *MailQueueRepository) Postpone(e *QueuedMail, next time.Time) error {
+	e.NextAttempt = &next
+	_, err := GetDatabase().DB().Exec("UPDATE mail_queue SET next_attempt = $1 WHERE id = $2", next, e.ID)
+	return err
+}
+
+// RemoveOrganization deletes the dead messages of an organization and
+// detaches its pending messages, so that e.g. deletion confirmations are
+// still delivered.
+func (r *MailQueueRepository) RemoveOrganization(organizationID string) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM mail_queue "+
+		"WHERE organization_id = $1 AND status = $2",
+		organizationID, QueuedMailStatusDead); err != nil {
+		return err
+	}
+	_, err := GetDatabase().DB().Exec("UPDATE mail_queue SET organization_id = NULL "+
+		"WHERE organization_id = $1", organizationID)
+	return err
+}
+
+func (r *MailQueueRepository) PurgeDead(maxAge time.Duration, batchSize int) (int, error) {
+	limit := time.Now().Add(-maxAge)
+	result, err := GetDatabase().DB().Exec("DELETE FROM mail_queue WHERE id IN ("+
+		"SELECT id FROM mail_queue WHERE status = $1 AND last_attempt < $2 ORDER BY last_attempt ASC LIMIT $3)",
+		QueuedMailStatusDead, limit, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	num, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(num), nil
+}
+
+func (r *MailQueueRepository) scanMails(rows *sql.Rows) ([]*QueuedMail, error) {
+	result := []*QueuedMail{}
+	for rows.Next() {
+		e := &QueuedMail{}
+		var attachments string
+		err := rows.Scan(&e.ID, &e.OrganizationID, &e.Recipient, &e.RecipientName, &e.Subject, &e.Body, &e.BodyText, &attachments, &e.Status, &e.Attempts, &e.NextAttempt, &e.LastAttempt, &e.Error, &e.Created)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(attachments), &e.Attachments); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *MailQueueRepository) nullOrganizationID(organizationID string) interface{} {
+	if organizationID == "" {
+		return nil
+	}
+	return organizationID
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+type MailSettingsRepository struct {
+}
+
+// MailSettings configure how an organization's mails are sent. If SMTPHost
+// is empty, the global mail service is used with the organization's sender
+// name and reply-to address.
+type MailSettings struct {
+	OrganizationID         string
+	SMTPHost               string
+	SMTPPort               int
+	SMTPTLSMode            string
+	SMTPInsecureSkipVerify bool
+	SMTPAuth               bool
+	SMTPAuthMethod         string
+	SMTPAuthUser           string
+	// Encrypted with EncryptString
+	SMTPAuthPassEncrypted string
+	SenderAddress         string
+	SenderName            string
+	ReplyTo               string
+	Updated               time.Time
+}
+
+var mailSettingsRepository *MailSettingsRepository
+var mailSettingsRepositoryOnce sync.Once
+
+func GetMailSettingsRepository() *MailSettingsRepository {
+	mailSettingsRepositoryOnce.Do(func() {
+		mailSettingsRepository = &MailSettingsRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS organizations_mail_settings (" +
+			"organization_id uuid NOT NULL, " +
+			"smtp_host VARCHAR NOT NULL DEFAULT '', " +
+			"smtp_port INTEGER NOT NULL DEFAULT 0, " +
+			"smtp_tls_mode VARCHAR NOT NULL DEFAULT '', " +
+			"smtp_insecure_skip_verify boolean NOT NULL DEFAULT FALSE, " +
+			"smtp_auth boolean NOT NULL DEFAULT FALSE, " +
+			"smtp_auth_method VARCHAR NOT NULL DEFAULT '', " +
+			"smtp_auth_user VARCHAR NOT NULL DEFAULT '', " +
+			"smtp_auth_pass VARCHAR NOT NULL DEFAULT '', " +
+			"sender_address VARCHAR NOT NULL DEFAULT '', " +
+			"sender_name VARCHAR NOT NULL DEFAULT '', " +
+			"reply_to VARCHAR NOT NULL DEFAULT '', " +
+			"updated TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (organization_id))")
+		if err != nil {
+			panic(err)
+		}
+	})
+	return mailSettingsRepository
+}
+
+func (r *MailSettingsRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// No updates yet
+}
+
+func (r *MailSettingsRepository) Get(organizationID string) (*MailSettings, error) {
+	e := &MailSettings{}
+	err := GetDatabase().DB().QueryRow("SELECT organization_id, smtp_host, smtp_port, smtp_tls_mode, smtp_insecure_skip_verify, "+
+		"smtp_auth, smtp_auth_method, smtp_auth_user, smtp_auth_pass, sender_address, sender_name, reply_to, updated "+
+		"FROM organizations_mail_settings "+
+		"WHERE organization_id = $1",
+		organizationID).Scan(&e.OrganizationID, &e.SMTPHost, &e.SMTPPort, &e.SMTPTLSMode, &e.SMTPInsecureSkipVerify,
+		&e.SMTPAuth, &e.SMTPAuthMethod, &e.SMTPAuthUser, &e.SMTPAuthPassEncrypted, &e.SenderAddress, &e.SenderName, &e.ReplyTo, &e.Updated)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *MailSettingsRepository) Save(e *MailSettings) error {
+	_, err := GetDatabase().DB().Exec("INSERT INTO organizations_mail_settings "+
+		"(organization_id, smtp_host, smtp_port, smtp_tls_mode, smtp_insecure_skip_verify, "+
+		"smtp_auth, smtp_auth_method, smtp_auth_user, smtp_auth_pass, sender_address, sender_name, reply_to, updated) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) "+
+		"ON CONFLICT (organization_id) DO UPDATE SET "+
+		"smtp_host = EXCLUDED.smtp_host, smtp_port = EXCLUDED.smtp_port, smtp_tls_mode = EXCLUDED.smtp_tls_mode, "+
+		"smtp_insecure_skip_verify = EXCLUDED.smtp_insecure_skip_verify, smtp_auth = EXCLUDED.smtp_auth, "+
+		"smtp_auth_method = EXCLUDED.smtp_auth_method, smtp_auth_user = EXCLUDED.smtp_auth_user, "+
+		"smtp_auth_pass = EXCLUDED.smtp_auth_pass, sender_address = EXCLUDED.sender_address, "+
+		"sender_name = EXCLUDED.sender_name, reply_to = EXCLUDED.reply_to, updated = EXCLUDED.updated",
+		e.OrganizationID, e.SMTPHost, e.SMTPPort, e.SMTPTLSMode, e.SMTPInsecureSkipVerify,
+		e.SMTPAuth, e.SMTPAuthMethod, e.SMTPAuthUser, e.SMTPAuthPassEncrypted, e.SenderAddress, e.SenderName, e.ReplyTo, e.Updated)
+	return err
+}
+
+func (r *MailSettingsRepository) Delete(organizationID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM organizations_mail_settings WHERE organization_id = $1", organizationID)
+	return err
+}
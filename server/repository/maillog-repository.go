@@ -8,12 +8,22 @@ import (
 type MailLogRepository struct {
 }
 
+type MailLogStatus int
+
+const (
+	MailLogStatusSent MailLogStatus = 0
+	// The message could not be delivered and was moved to the dead letter queue
+	MailLogStatusFailed MailLogStatus = 1
+)
+
 type MailLog struct {
 	ID             string
 	Timestamp      time.Time
 	Subject        string
 	Recipient      string
 	OrganizationID string
+	Status         MailLogStatus
+	Error          string
 }
 
 type MailLogBySubject struct {
@@ -59,7 +69,13 @@ func GetMailLogRepository() *MailLogRepository {
 }
 
 func (r *MailLogRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
-	// No updates yet
+	if curVersion < 57 {
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE mail_logs " +
+			"ADD COLUMN IF NOT EXISTS status INTEGER NOT NULL DEFAULT 0, " +
+			"ADD COLUMN IF NOT EXISTS error VARCHAR NOT NULL DEFAULT ''"); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func (r *MailLogRepository) Create(e *MailLog) error {
@@ -71,10 +87,10 @@ func (r *MailLogRepository) Create(e *MailLog) error {
 		orgID = e.OrganizationID
 	}
 	err := GetDatabase().DB().QueryRow("INSERT INTO mail_logs "+
-		"(timestamp, subject, recipient, organization_id) "+
-		"VALUES ($1, $2, $3, $4) "+
+		"(timestamp, subject, recipient, organization_id, status, error) "+
+		"VALUES ($1, $2, $3, $4, $5, $6) "+
 		"RETURNING id",
-		e.Timestamp, e.Subject, e.Recipient, orgID).Scan(&id)
+		e.Timestamp, e.Subject, e.Recipient, orgID, e.Status, e.Error).Scan(&id)
 	if err != nil {
 		return err
 	}
@@ -92,14 +108,41 @@ func (r *MailLogRepository) LogEmail(subject, recipient, organizationID string)
 	return r.Create(e)
 }
 
+// LogFailedEmail records a message which was given up after failed delivery
+// attempts.
+func (r *MailLogRepository) LogFailedEmail(subject, recipient, organizationID, errorMessage string) error {
+	e := &MailLog{
+		Timestamp:      time.Now(),
+		Subject:        subject,
+		Recipient:      recipient,
+		OrganizationID: organizationID,
+		Status:         MailLogStatusFailed,
+		Error:          errorMessage,
+	}
+	return r.Create(e)
+}
+
+// GetCountByOrganizationSince returns the number of messages sent for an
+// organization since the given time.
+func (r *MailLogRepository) GetCountByOrganizationSince(organizationID string, since time.Time) (int, error) {
+	var count int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(id) FROM mail_logs "+
+		"WHERE organization_id = $1 AND timestamp >= $2 AND status = $3",
+		organizationID, since, MailLogStatusSent).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *MailLogRepository) GetCountByDate(date time.Time) (int, error) {
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
 	var count int
 	err := GetDatabase().DB().QueryRow("SELECT COUNT(id) FROM mail_logs "+
-		"WHERE timestamp >= $1 AND timestamp < $2",
-		startOfDay, endOfDay).Scan(&count)
+		"WHERE timestamp >= $1 AND timestamp < $2 AND status = $3",
+		startOfDay, endOfDay, MailLogStatusSent).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -111,10 +154,10 @@ func (r *MailLogRepository) GetCountBySubjectAndDate(date time.Time) ([]*MailLog
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
 	rows, err := GetDatabase().DB().Query("SELECT subject, COUNT(id) as count FROM mail_logs "+
-		"WHERE timestamp >= $1 AND timestamp < $2 "+
+		"WHERE timestamp >= $1 AND timestamp < $2 AND status = $3 "+
 		"GROUP BY subject "+
 		"ORDER BY count DESC, subject ASC",
-		startOfDay, endOfDay)
+		startOfDay, endOfDay, MailLogStatusSent)
 	if err != nil {
 		return nil, err
 	}
@@ -136,10 +179,10 @@ func (r *MailLogRepository) GetCountByOrganizationAndDate(date time.Time) ([]*Ma
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
 	rows, err := GetDatabase().DB().Query("SELECT organization_id, COUNT(id) as count FROM mail_logs "+
-		"WHERE timestamp >= $1 AND timestamp < $2 AND organization_id IS NOT NULL "+
+		"WHERE timestamp >= $1 AND timestamp < $2 AND organization_id IS NOT NULL AND status = $3 "+
 		"GROUP BY organization_id "+
 		"ORDER BY count DESC, organization_id ASC",
-		startOfDay, endOfDay)
+		startOfDay, endOfDay, MailLogStatusSent)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +200,7 @@ func (r *MailLogRepository) GetCountByOrganizationAndDate(date time.Time) ([]*Ma
 }
 
 func (r *MailLogRepository) AnonymizeAll(organizationID string) error {
-	if _, err := GetDatabase().DB().Exec("UPDATE mail_logs SET recipient = '' "+
+	if _, err := GetDatabase().DB().Exec("UPDATE mail_logs SET recipient = '', error = '' "+
 		"WHERE organization_id = $1", organizationID); err != nil {
 		return err
 	}
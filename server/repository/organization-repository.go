@@ -305,6 +305,9 @@ func (r *OrganizationStore) Delete(e *Organization) error {
 	if err := GetLocationRepository().DeleteAll(e.ID); err != nil {
 		return err
 	}
+	if err := GetBookingCancellationRepository().DeleteAll(e.ID); err != nil {
+		return err
+	}
 	if err := GetSettingsRepository().DeleteAll(e.ID); err != nil {
 		return err
 	}
@@ -319,10 +322,42 @@ func (r *OrganizationStore) Delete(e *Organization) error {
 	if err := GetMailLogRepository().AnonymizeAll(e.ID); err != nil {
 		return err
 	}
+	// Delete failed and detach pending queued mails
+	if err := GetMailQueueRepository().RemoveOrganization(e.ID); err != nil {
+		return err
+	}
 	// Delete auth events
 	if err := GetAuthAttemptRepository().DeleteAll(e.ID); err != nil {
 		return err
 	}
+	// Delete delegations
+	if err := GetDelegationRepository().DeleteAll(e.ID); err != nil {
+		return err
+	}
+	// Delete custom roles
+	if err := GetRoleRepository().DeleteAll(e.ID); err != nil {
+		return err
+	}
+	// Delete user attributes and their values
+	if err := GetUserAttributeRepository().DeleteAll(e.ID); err != nil {
+		return err
+	}
+	// Delete webhooks and their deliveries
+	if err := GetWebhookRepository().DeleteAll(e.ID); err != nil {
+		return err
+	}
+	// Delete mail transport settings
+	if err := GetMailSettingsRepository().Delete(e.ID); err != nil {
+		return err
+	}
+	// Delete custom email templates
+	if err := GetEmailTemplateRepository().DeleteAll(e.ID); err != nil {
+		return err
+	}
+	// Delete audit log
+	if err := GetAuditLogRepository().DeleteAll(e.ID); err != nil {
+		return err
+	}
 	_, err := GetDatabase().DB().Exec("DELETE FROM organizations_domains WHERE organization_id = $1", e.ID)
 	if err != nil {
 		return err
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+type PasswordHistoryRepository struct {
+}
+
+type PasswordHistoryEntry struct {
+	ID             string
+	UserID         string
+	HashedPassword string
+	Created        time.Time
+}
+
+// Maximum number of previous passwords kept per user
+const MaxPasswordHistoryEntries = 24
+
+var passwordHistoryRepository *PasswordHistoryRepository
+var passwordHistoryRepositoryOnce sync.Once
+
+func GetPasswordHistoryRepository() *PasswordHistoryRepository {
+	passwordHistoryRepositoryOnce.Do(func() {
+		passwordHistoryRepository = &PasswordHistoryRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS password_history (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"user_id uuid NOT NULL, " +
+			"hashed_password VARCHAR NOT NULL, " +
+			"created TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id)"); err != nil {
+			panic(err)
+		}
+	})
+	return passwordHistoryRepository
+}
+
+func (r *PasswordHistoryRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+// Create adds a password hash to the user's history and removes entries
+// exceeding MaxPasswordHistoryEntries.
+func (r *PasswordHistoryRepository) Create(e *PasswordHistoryEntry) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO password_history "+
+		"(user_id, hashed_password, created) "+
+		"VALUES ($1, $2, $3) "+
+		"RETURNING id",
+		e.UserID, e.HashedPassword, e.Created).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	_, err = GetDatabase().DB().Exec("DELETE FROM password_history "+
+		"WHERE user_id = $1 AND id NOT IN ("+
+		"SELECT id FROM password_history WHERE user_id = $1 ORDER BY created DESC LIMIT $2)",
+		e.UserID, MaxPasswordHistoryEntries)
+	return err
+}
+
+// GetRecentByUserID returns the user's most recent password hashes, newest first.
+func (r *PasswordHistoryRepository) GetRecentByUserID(userID string, limit int) ([]*PasswordHistoryEntry, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, user_id, hashed_password, created "+
+		"FROM password_history "+
+		"WHERE user_id = $1 "+
+		"ORDER BY created DESC "+
+		"LIMIT $2",
+		userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*PasswordHistoryEntry{}
+	for rows.Next() {
+		e := &PasswordHistoryEntry{}
+		if err = rows.Scan(&e.ID, &e.UserID, &e.HashedPassword, &e.Created); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// GetLastChanged returns when the user's password was last set or nil if unknown.
+func (r *PasswordHistoryRepository) GetLastChanged(userID string) (*time.Time, error) {
+	var res *time.Time
+	err := GetDatabase().DB().QueryRow("SELECT MAX(created) FROM password_history WHERE user_id = $1", userID).Scan(&res)
+	return res, err
+}
+
+func (r *PasswordHistoryRepository) DeleteAllByUserID(userID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM password_history WHERE user_id = $1", userID)
+	return err
+}
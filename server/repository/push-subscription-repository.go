@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+type PushSubscriptionRepository struct {
+}
+
+// PushSubscription is a browser's Web Push subscription. Each device or
+// browser profile a user enables push notifications on has its own
+// subscription.
+type PushSubscription struct {
+	ID        string
+	UserID    string
+	Endpoint  string
+	P256dh    string
+	Auth      string
+	UserAgent string
+	Created   time.Time
+}
+
+var pushSubscriptionRepository *PushSubscriptionRepository
+var pushSubscriptionRepositoryOnce sync.Once
+
+func GetPushSubscriptionRepository() *PushSubscriptionRepository {
+	pushSubscriptionRepositoryOnce.Do(func() {
+		pushSubscriptionRepository = &PushSubscriptionRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS push_subscriptions (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"user_id uuid NOT NULL, " +
+			"endpoint VARCHAR NOT NULL, " +
+			"p256dh VARCHAR NOT NULL, " +
+			"auth VARCHAR NOT NULL, " +
+			"user_agent VARCHAR NOT NULL DEFAULT '', " +
+			"created TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user_id ON push_subscriptions(user_id)"); err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_push_subscriptions_endpoint ON push_subscriptions(endpoint)"); err != nil {
+			panic(err)
+		}
+	})
+	return pushSubscriptionRepository
+}
+
+func (r *PushSubscriptionRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+// Create stores the subscription. Endpoints are unique, so subscribing again
+// from the same browser replaces the keys and assigns the subscription to the
+// current user.
+func (r *PushSubscriptionRepository) Create(e *PushSubscription) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO push_subscriptions "+
+		"(user_id, endpoint, p256dh, auth, user_agent, created) "+
+		"VALUES ($1, $2, $3, $4, $5, $6) "+
+		"ON CONFLICT (endpoint) DO UPDATE SET user_id = $1, p256dh = $3, auth = $4, user_agent = $5, created = $6 "+
+		"RETURNING id",
+		e.UserID, e.Endpoint, e.P256dh, e.Auth, e.UserAgent, e.Created).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *PushSubscriptionRepository) GetOne(id string) (*PushSubscription, error) {
+	e := &PushSubscription{}
+	err := GetDatabase().DB().QueryRow("SELECT id, user_id, endpoint, p256dh, auth, user_agent, created "+
+		"FROM push_subscriptions "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.UserID, &e.Endpoint, &e.P256dh, &e.Auth, &e.UserAgent, &e.Created)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *PushSubscriptionRepository) GetAllByUserID(userID string) ([]*PushSubscription, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, user_id, endpoint, p256dh, auth, user_agent, created "+
+		"FROM push_subscriptions "+
+		"WHERE user_id = $1 "+
+		"ORDER BY created", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*PushSubscription{}
+	for rows.Next() {
+		e := &PushSubscription{}
+		if err = rows.Scan(&e.ID, &e.UserID, &e.Endpoint, &e.P256dh, &e.Auth, &e.UserAgent, &e.Created); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *PushSubscriptionRepository) Delete(e *PushSubscription) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM push_subscriptions WHERE id = $1", e.ID)
+	return err
+}
+
+// DeleteByEndpoint removes the user's subscription of a browser, e.g. after
+// the user disabled push notifications.
+func (r *PushSubscriptionRepository) DeleteByEndpoint(userID, endpoint string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2", userID, endpoint)
+	return err
+}
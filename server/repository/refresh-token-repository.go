@@ -4,6 +4,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	. "github.com/seatsurfing/seatsurfing/server/api"
 )
 
@@ -14,8 +16,10 @@ type RefreshToken struct {
 	ID        string
 	UserID    string
 	SessionID string
+	FamilyID  string
 	Created   time.Time
 	Expiry    time.Time
+	Rotated   *time.Time
 }
 
 var refreshTokenRepository *RefreshTokenRepository
@@ -44,15 +48,37 @@ func (r *RefreshTokenRepository) RunSchemaUpgrade(curVersion, targetVersion int)
 			panic(err)
 		}
 	}
+	if curVersion < 53 {
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE refresh_tokens " +
+			"ADD COLUMN IF NOT EXISTS family_id uuid NULL, " +
+			"ADD COLUMN IF NOT EXISTS rotated TIMESTAMP NULL"); err != nil {
+			panic(err)
+		}
+		// existing tokens become the root of their own family
+		if _, err := GetDatabase().DB().Exec("UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL"); err != nil {
+			panic(err)
+		}
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL"); err != nil {
+			panic(err)
+		}
+		if _, err := GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id)"); err != nil {
+			panic(err)
+		}
+	}
 }
 
+// Create stores a new refresh token. If no family ID is set, the token starts
+// a new token family.
 func (r *RefreshTokenRepository) Create(e *RefreshToken) error {
+	if e.FamilyID == "" {
+		e.FamilyID = uuid.New().String()
+	}
 	var id string
 	err := GetDatabase().DB().QueryRow("INSERT INTO refresh_tokens "+
-		"(user_id, session_id, created, expiry) "+
-		"VALUES ($1, $2, $3, $4) "+
+		"(user_id, session_id, family_id, created, expiry) "+
+		"VALUES ($1, $2, $3, $4, $5) "+
 		"RETURNING id",
-		e.UserID, e.SessionID, e.Created, e.Expiry).Scan(&id)
+		e.UserID, e.SessionID, e.FamilyID, e.Created, e.Expiry).Scan(&id)
 	if err != nil {
 		return err
 	}
@@ -62,21 +88,34 @@ func (r *RefreshTokenRepository) Create(e *RefreshToken) error {
 
 func (r *RefreshTokenRepository) GetOne(id string) (*RefreshToken, error) {
 	e := &RefreshToken{}
-	err := GetDatabase().DB().QueryRow("SELECT id, user_id, session_id, created, expiry "+
+	err := GetDatabase().DB().QueryRow("SELECT id, user_id, session_id, family_id, created, expiry, rotated "+
 		"FROM refresh_tokens "+
 		"WHERE id = $1",
-		id).Scan(&e.ID, &e.UserID, &e.SessionID, &e.Created, &e.Expiry)
+		id).Scan(&e.ID, &e.UserID, &e.SessionID, &e.FamilyID, &e.Created, &e.Expiry, &e.Rotated)
 	if err != nil {
 		return nil, err
 	}
 	return e, nil
 }
 
-func (r *RefreshTokenRepository) Delete(e *RefreshToken) error {
-	// Hint: Do not delete session here, because it might be used by other refresh tokens.
-	// Instead, delete the session when deleting expired tokens or when deleting all tokens of a user.
-	// This function is only used when refreshing a token, so the session should not be deleted here.
-	_, err := GetDatabase().DB().Exec("DELETE FROM refresh_tokens WHERE id = $1", e.ID)
+// MarkRotated marks the token as used for a refresh. Rotated tokens are kept
+// until they expire to detect reuse. Returns false if the token has already
+// been rotated, i.e. if it has been reused.
+func (r *RefreshTokenRepository) MarkRotated(e *RefreshToken) (bool, error) {
+	res, err := GetDatabase().DB().Exec("UPDATE refresh_tokens SET rotated = $2 WHERE id = $1 AND rotated IS NULL",
+		e.ID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return num == 1, nil
+}
+
+func (r *RefreshTokenRepository) DeleteFamily(familyID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM refresh_tokens WHERE family_id = $1", familyID)
 	return err
 }
 
@@ -96,6 +135,6 @@ func (r *RefreshTokenRepository) DeleteOfUser(u *User) error {
 
 func (r *RefreshTokenRepository) GetCountBySession(sessionID string) (int, error) {
 	var count int
-	err := GetDatabase().DB().QueryRow("SELECT COUNT(*) FROM refresh_tokens WHERE session_id = $1 AND expiry > NOW()", sessionID).Scan(&count)
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(*) FROM refresh_tokens WHERE session_id = $1 AND expiry > NOW() AND rotated IS NULL", sessionID).Scan(&count)
 	return count, err
 }
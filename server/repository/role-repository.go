@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/lib/pq"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+)
+
+type RoleRepository struct {
+}
+
+// Role is an organization-defined set of permissions which can be assigned
+// to users or groups, optionally limited to a single location.
+type Role struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	Permissions    []Permission
+}
+
+type RoleAssignment struct {
+	ID         string
+	RoleID     string
+	UserID     string
+	GroupID    string
+	LocationID string
+}
+
+type RoleAssignmentDetails struct {
+	RoleAssignment
+	UserEmail    string
+	GroupName    string
+	LocationName string
+}
+
+// PermissionGrant is a single permission a user holds through a role
+// assignment. An empty LocationID means the grant applies organization-wide.
+type PermissionGrant struct {
+	Permission Permission
+	LocationID string
+}
+
+var roleRepository *RoleRepository
+var roleRepositoryOnce sync.Once
+
+func GetRoleRepository() *RoleRepository {
+	roleRepositoryOnce.Do(func() {
+		roleRepository = &RoleRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS roles (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NOT NULL, " +
+			"name VARCHAR NOT NULL, " +
+			"permissions VARCHAR[] NOT NULL DEFAULT '{}', " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		_, err = GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS roles_assignments (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"role_id uuid NOT NULL, " +
+			"user_id uuid NULL, " +
+			"group_id uuid NULL, " +
+			"location_id uuid NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_roles_assignments_user_id ON roles_assignments(user_id)"); err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_roles_assignments_group_id ON roles_assignments(group_id)"); err != nil {
+			panic(err)
+		}
+	})
+	return roleRepository
+}
+
+func (r *RoleRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *RoleRepository) Create(e *Role) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO roles "+
+		"(organization_id, name, permissions) "+
+		"VALUES ($1, $2, $3) "+
+		"RETURNING id",
+		e.OrganizationID, e.Name, pq.Array(r.permissionsToStrings(e.Permissions))).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *RoleRepository) GetOne(id string) (*Role, error) {
+	e := &Role{}
+	var permissions pq.StringArray
+	err := GetDatabase().DB().QueryRow("SELECT id, organization_id, name, permissions "+
+		"FROM roles "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.OrganizationID, &e.Name, &permissions)
+	if err != nil {
+		return nil, err
+	}
+	e.Permissions = r.stringsToPermissions(permissions)
+	return e, nil
+}
+
+func (r *RoleRepository) GetAll(organizationID string) ([]*Role, error) {
+	var result []*Role
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, name, permissions "+
+		"FROM roles "+
+		"WHERE organization_id = $1 "+
+		"ORDER BY name",
+		organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &Role{}
+		var permissions pq.StringArray
+		err = rows.Scan(&e.ID, &e.OrganizationID, &e.Name, &permissions)
+		if err != nil {
+			return nil, err
+		}
+		e.Permissions = r.stringsToPermissions(permissions)
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *RoleRepository) Update(e *Role) error {
+	_, err := GetDatabase().DB().Exec("UPDATE roles SET "+
+		"name = $1, "+
+		"permissions = $2 "+
+		"WHERE id = $3",
+		e.Name, pq.Array(r.permissionsToStrings(e.Permissions)), e.ID)
+	return err
+}
+
+func (r *RoleRepository) Delete(e *Role) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM roles_assignments WHERE role_id = $1", e.ID); err != nil {
+		return err
+	}
+	_, err := GetDatabase().DB().Exec("DELETE FROM roles WHERE id = $1", e.ID)
+	return err
+}
+
+func (r *RoleRepository) DeleteAll(organizationID string) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM roles_assignments WHERE "+
+		"role_id IN (SELECT id FROM roles WHERE organization_id = $1)", organizationID); err != nil {
+		return err
+	}
+	_, err := GetDatabase().DB().Exec("DELETE FROM roles WHERE organization_id = $1", organizationID)
+	return err
+}
+
+func (r *RoleRepository) CreateAssignment(e *RoleAssignment) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO roles_assignments "+
+		"(role_id, user_id, group_id, location_id) "+
+		"VALUES ($1, $2, $3, $4) "+
+		"RETURNING id",
+		e.RoleID, CheckNullUUID(NullUUID(e.UserID)), CheckNullUUID(NullUUID(e.GroupID)), CheckNullUUID(NullUUID(e.LocationID))).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *RoleRepository) GetAssignment(id string) (*RoleAssignment, error) {
+	e := &RoleAssignment{}
+	var userID, groupID, locationID NullUUID
+	err := GetDatabase().DB().QueryRow("SELECT id, role_id, user_id, group_id, location_id "+
+		"FROM roles_assignments "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.RoleID, &userID, &groupID, &locationID)
+	if err != nil {
+		return nil, err
+	}
+	e.UserID = string(userID)
+	e.GroupID = string(groupID)
+	e.LocationID = string(locationID)
+	return e, nil
+}
+
+func (r *RoleRepository) GetAssignments(roleID string) ([]*RoleAssignmentDetails, error) {
+	var result []*RoleAssignmentDetails
+	rows, err := GetDatabase().DB().Query("SELECT roles_assignments.id, roles_assignments.role_id, "+
+		"roles_assignments.user_id, roles_assignments.group_id, roles_assignments.location_id, "+
+		"COALESCE(users.email, ''), COALESCE(groups.name, ''), COALESCE(locations.name, '') "+
+		"FROM roles_assignments "+
+		"LEFT JOIN users ON roles_assignments.user_id = users.id "+
+		"LEFT JOIN groups ON roles_assignments.group_id = groups.id "+
+		"LEFT JOIN locations ON roles_assignments.location_id = locations.id "+
+		"WHERE roles_assignments.role_id = $1 "+
+		"ORDER BY users.email, groups.name, locations.name",
+		roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &RoleAssignmentDetails{}
+		var userID, groupID, locationID NullUUID
+		err = rows.Scan(&e.ID, &e.RoleID, &userID, &groupID, &locationID, &e.UserEmail, &e.GroupName, &e.LocationName)
+		if err != nil {
+			return nil, err
+		}
+		e.UserID = string(userID)
+		e.GroupID = string(groupID)
+		e.LocationID = string(locationID)
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *RoleRepository) DeleteAssignment(e *RoleAssignment) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM roles_assignments WHERE id = $1", e.ID)
+	return err
+}
+
+// IsGroupAssigned returns true if the group has been assigned at least one
+// custom role.
+func (r *RoleRepository) IsGroupAssigned(groupID string) (bool, error) {
+	var res int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(id) FROM roles_assignments WHERE group_id = $1", groupID).Scan(&res)
+	if err != nil {
+		return false, err
+	}
+	return res > 0, nil
+}
+
+// IsUserAssigned returns true if the user has been assigned at least one
+// custom role, either directly or through one of the user's groups.
+func (r *RoleRepository) IsUserAssigned(userID string) (bool, error) {
+	var res int
+	err := GetDatabase().DB().QueryRow("SELECT COUNT(id) FROM roles_assignments "+
+		"WHERE user_id = $1 OR "+
+		"group_id IN (SELECT group_id FROM users_groups WHERE user_id = $1)",
+		userID).Scan(&res)
+	if err != nil {
+		return false, err
+	}
+	return res > 0, nil
+}
+
+// GetPermissionGrants returns all permissions the specified user holds
+// through custom roles, either directly or through one of the user's groups.
+func (r *RoleRepository) GetPermissionGrants(userID string) ([]*PermissionGrant, error) {
+	var result []*PermissionGrant
+	rows, err := GetDatabase().DB().Query("SELECT roles.permissions, roles_assignments.location_id "+
+		"FROM roles_assignments "+
+		"INNER JOIN roles ON roles_assignments.role_id = roles.id "+
+		"WHERE roles_assignments.user_id = $1 OR "+
+		"roles_assignments.group_id IN (SELECT group_id FROM users_groups WHERE user_id = $1)",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var permissions pq.StringArray
+		var locationID NullUUID
+		if err = rows.Scan(&permissions, &locationID); err != nil {
+			return nil, err
+		}
+		for _, p := range r.stringsToPermissions(permissions) {
+			result = append(result, &PermissionGrant{
+				Permission: p,
+				LocationID: string(locationID),
+			})
+		}
+	}
+	return result, nil
+}
+
+func (r *RoleRepository) permissionsToStrings(permissions []Permission) []string {
+	res := []string{}
+	for _, p := range permissions {
+		res = append(res, string(p))
+	}
+	return res
+}
+
+func (r *RoleRepository) stringsToPermissions(permissions []string) []Permission {
+	res := []Permission{}
+	for _, p := range permissions {
+		res = append(res, Permission(p))
+	}
+	return res
+}
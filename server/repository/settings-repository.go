@@ -4,6 +4,8 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/lib/pq"
+
 	. "github.com/seatsurfing/seatsurfing/server/api"
 )
 
@@ -210,6 +212,28 @@ func (r *SettingsStore) GetOrgIDsByValue(name string, value string) ([]string, e
 	return result, nil
 }
 
+// GetOrgIDsWithAnySet returns the organizations which have at least one of
+// the settings set to a value other than empty or "0".
+func (r *SettingsStore) GetOrgIDsWithAnySet(names []string) ([]string, error) {
+	var result []string
+	rows, err := GetDatabase().DB().Query("SELECT DISTINCT organization_id FROM settings "+
+		"WHERE name = ANY($1) AND value != '' AND value != '0' "+
+		"ORDER BY organization_id", pq.StringArray(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var orgID string
+		err = rows.Scan(&orgID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, orgID)
+	}
+	return result, nil
+}
+
 func (r *SettingsStore) InitDefaultSettingsForOrg(organizationID string) error {
 	_, err := GetDatabase().DB().Exec("INSERT INTO settings (organization_id, name, value) "+
 		"VALUES "+
@@ -246,7 +270,20 @@ func (r *SettingsStore) InitDefaultSettingsForOrg(organizationID string) error {
 		"($1, '"+SettingEnforceTOTP.Name+"', '0'), "+
 		"($1, '"+SettingKioskModeEnabled.Name+"', '0'), "+
 		"($1, '"+SettingHideReports.Name+"', '0'), "+
-		"($1, '"+SettingHideStats.Name+"', '0') "+
+		"($1, '"+SettingHideStats.Name+"', '0'), "+
+		"($1, '"+SettingAllowMagicLinkLogin.Name+"', '0'), "+
+		"($1, '"+SettingPasswordMinLength.Name+"', '8'), "+
+		"($1, '"+SettingPasswordMinCharClasses.Name+"', '4'), "+
+		"($1, '"+SettingPasswordHistory.Name+"', '0'), "+
+		"($1, '"+SettingPasswordMaxAgeDays.Name+"', '0'), "+
+		"($1, '"+SettingPasswordCheckBreached.Name+"', '0'), "+
+		"($1, '"+SettingNetworkAllowlistUsers.Name+"', ''), "+
+		"($1, '"+SettingNetworkAllowlistSpaceAdmins.Name+"', ''), "+
+		"($1, '"+SettingNetworkAllowlistOrgAdmins.Name+"', ''), "+
+		"($1, '"+SettingNetworkAllowlistServiceAccount.Name+"', ''), "+
+		"($1, '"+SettingTrustedNetworks.Name+"', ''), "+
+		"($1, '"+SettingRequire2FAUntrustedNetworks.Name+"', '0'), "+
+		"($1, '"+SettingSuspiciousLoginNotifications.Name+"', '1') "+
 		"ON CONFLICT (organization_id, name) DO NOTHING",
 		organizationID)
 	return err
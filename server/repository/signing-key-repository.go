@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+type SigningKeyRepository struct {
+}
+
+// SigningKey is a database-backed RSA key pair used for signing access tokens.
+// Keys are published (and accepted for verification) as soon as they are
+// created, but only used for signing once Activates has passed. Superseded keys
+// stay valid for verification until Expires, so tokens signed with them remain
+// usable until they expire themselves.
+type SigningKey struct {
+	ID         string
+	PrivateKey string // PEM encoded, encrypted at rest
+	PublicKey  string // PEM encoded
+	Created    time.Time
+	Activates  time.Time
+	Expires    *time.Time
+}
+
+var signingKeyRepository *SigningKeyRepository
+var signingKeyRepositoryOnce sync.Once
+
+func GetSigningKeyRepository() *SigningKeyRepository {
+	signingKeyRepositoryOnce.Do(func() {
+		signingKeyRepository = &SigningKeyRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS signing_keys (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"private_key VARCHAR NOT NULL, " +
+			"public_key VARCHAR NOT NULL, " +
+			"created TIMESTAMP NOT NULL, " +
+			"activates TIMESTAMP NOT NULL, " +
+			"expires TIMESTAMP NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+	})
+	return signingKeyRepository
+}
+
+func (r *SigningKeyRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *SigningKeyRepository) Create(e *SigningKey) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO signing_keys "+
+		"(private_key, public_key, created, activates, expires) "+
+		"VALUES ($1, $2, $3, $4, $5) "+
+		"RETURNING id",
+		e.PrivateKey, e.PublicKey, e.Created, e.Activates, e.Expires).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// GetAllValid returns all keys which have not expired yet, including keys
+// which are not yet active for signing. The newest key is returned first.
+func (r *SigningKeyRepository) GetAllValid() ([]*SigningKey, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, private_key, public_key, created, activates, expires " +
+		"FROM signing_keys " +
+		"WHERE expires IS NULL OR expires > NOW() " +
+		"ORDER BY activates DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []*SigningKey
+	for rows.Next() {
+		e := &SigningKey{}
+		err = rows.Scan(&e.ID, &e.PrivateKey, &e.PublicKey, &e.Created, &e.Activates, &e.Expires)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// GetLatestActivation returns the activation time of the newest key, or nil if
+// there is no unexpired key at all.
+func (r *SigningKeyRepository) GetLatestActivation() (*time.Time, error) {
+	var res *time.Time
+	err := GetDatabase().DB().QueryRow("SELECT MAX(activates) FROM signing_keys " +
+		"WHERE expires IS NULL OR expires > NOW()").Scan(&res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExpireSuperseded sets the expiry of all keys which have been replaced by a
+// newer, already active key. The expiry is set to the activation of the
+// replacing key plus the given grace period.
+func (r *SigningKeyRepository) ExpireSuperseded(grace time.Duration) error {
+	_, err := GetDatabase().DB().Exec("UPDATE signing_keys k SET expires = n.activates + $1 * INTERVAL '1 second' "+
+		"FROM ("+
+		"SELECT k2.id, MIN(n2.activates) AS activates FROM signing_keys k2 "+
+		"INNER JOIN signing_keys n2 ON n2.activates > k2.activates AND n2.activates <= NOW() "+
+		"WHERE k2.expires IS NULL "+
+		"GROUP BY k2.id"+
+		") n "+
+		"WHERE k.id = n.id",
+		int(grace.Seconds()))
+	return err
+}
+
+// ExpireAll sets the expiry of all keys without expiry to now plus the given
+// grace period. Used when key rotation has been disabled.
+func (r *SigningKeyRepository) ExpireAll(grace time.Duration) error {
+	_, err := GetDatabase().DB().Exec("UPDATE signing_keys SET expires = NOW() + $1 * INTERVAL '1 second' "+
+		"WHERE expires IS NULL",
+		int(grace.Seconds()))
+	return err
+}
+
+func (r *SigningKeyRepository) DeleteExpired() error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM signing_keys WHERE expires < NOW()")
+	return err
+}
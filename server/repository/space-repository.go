@@ -37,8 +37,16 @@ type SpaceAvailability struct {
 type SpaceGroup struct {
 	SpaceID string
 	GroupID string
+	Level   int
 }
 
+// Approval levels of approver groups. If a space has second level approver
+// groups, bookings approved by the first level need another approval.
+const (
+	ApprovalLevelFirst  int = 1
+	ApprovalLevelSecond int = 2
+)
+
 var spaceRepository *SpaceStore
 var spaceRepositoryOnce sync.Once
 
@@ -111,6 +119,12 @@ func (r *SpaceStore) RunSchemaUpgrade(curVersion, targetVersion int) {
 			panic(err)
 		}
 	}
+	if curVersion < 59 {
+		if _, err := GetDatabase().DB().Exec("ALTER TABLE spaces_approvers " +
+			"ADD COLUMN IF NOT EXISTS level INTEGER NOT NULL DEFAULT 1"); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func (r *SpaceStore) Create(e *Space) error {
@@ -369,13 +383,18 @@ func (r *SpaceStore) GetBookingUserIDMap(organizationID string, enter, leave tim
 	return res, nil
 }
 
+// GetApproverGroupIDs returns the first level approver groups of the space.
 func (r *SpaceStore) GetApproverGroupIDs(spaceID string) ([]string, error) {
+	return r.GetApproverGroupIDsForLevel(spaceID, ApprovalLevelFirst)
+}
+
+func (r *SpaceStore) GetApproverGroupIDsForLevel(spaceID string, level int) ([]string, error) {
 	var result []string
 	rows, err := GetDatabase().DB().Query("SELECT group_id "+
 		"FROM spaces_approvers "+
-		"WHERE space_id = $1 "+
+		"WHERE space_id = $1 AND level = $2 "+
 		"ORDER BY group_id",
-		spaceID)
+		spaceID, level)
 	if err != nil {
 		return nil, err
 	}
@@ -391,17 +410,22 @@ func (r *SpaceStore) GetApproverGroupIDs(spaceID string) ([]string, error) {
 	return result, nil
 }
 
+// AddApprovers adds first level approver groups to the space.
 func (r *SpaceStore) AddApprovers(e *Space, groupIDs []string) error {
+	return r.AddApproversForLevel(e, groupIDs, ApprovalLevelFirst)
+}
+
+func (r *SpaceStore) AddApproversForLevel(e *Space, groupIDs []string, level int) error {
 	if len(groupIDs) == 0 {
 		return nil
 	}
-	sqlStr := "INSERT INTO spaces_approvers (space_id, group_id) VALUES "
+	sqlStr := "INSERT INTO spaces_approvers (space_id, group_id, level) VALUES "
 	vals := []interface{}{}
 	i := 1
 	for _, groupID := range groupIDs {
-		sqlStr += fmt.Sprintf("($%d, $%d),", i, i+1)
-		i += 2
-		vals = append(vals, e.ID, groupID)
+		sqlStr += fmt.Sprintf("($%d, $%d, $%d),", i, i+1, i+2)
+		i += 3
+		vals = append(vals, e.ID, groupID, level)
 	}
 	sqlStr = strings.TrimSuffix(sqlStr, ",")
 	_, err := GetDatabase().DB().Exec(sqlStr, vals...)
@@ -418,7 +442,7 @@ func (r *SpaceStore) RemoveApprovers(e *Space, groupIDs []string) error {
 
 func (r *SpaceStore) GetAllApproversForSpaceList(spaceIDs []string) ([]*SpaceGroup, error) {
 	var result []*SpaceGroup
-	rows, err := GetDatabase().DB().Query("SELECT space_id, group_id "+
+	rows, err := GetDatabase().DB().Query("SELECT space_id, group_id, level "+
 		"FROM spaces_approvers "+
 		"WHERE space_id = ANY($1::uuid[])",
 		pq.StringArray(spaceIDs))
@@ -428,7 +452,7 @@ func (r *SpaceStore) GetAllApproversForSpaceList(spaceIDs []string) ([]*SpaceGro
 	defer rows.Close()
 	for rows.Next() {
 		e := &SpaceGroup{}
-		err = rows.Scan(&e.SpaceID, &e.GroupID)
+		err = rows.Scan(&e.SpaceID, &e.GroupID, &e.Level)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func TestAuditLogRepositoryPurgeOld(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+
+	for _, age := range []time.Duration{1 * time.Hour, 40 * 24 * time.Hour, 50 * 24 * time.Hour} {
+		err := GetAuditLogRepository().Create(&AuditLogEntry{
+			OrganizationID: org.ID,
+			Timestamp:      time.Now().Add(-age),
+			Action:         AuditActionUpdate,
+			EntityType:     AuditEntitySetting,
+		})
+		CheckTestIsNil(t, err)
+	}
+	filter := &AuditLogFilter{
+		OrganizationID: org.ID,
+		Start:          time.Now().Add(-365 * 24 * time.Hour),
+		End:            time.Now(),
+	}
+	count, _ := GetAuditLogRepository().CountFiltered(filter)
+	CheckTestInt(t, 3, count)
+
+	num, err := GetAuditLogRepository().PurgeOld(30*24*time.Hour, 100)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 2, num)
+	count, _ = GetAuditLogRepository().CountFiltered(filter)
+	CheckTestInt(t, 1, count)
+
+	GetAuditLogRepository().DeleteAll(org.ID)
+	count, _ = GetAuditLogRepository().CountFiltered(filter)
+	CheckTestInt(t, 0, count)
+}
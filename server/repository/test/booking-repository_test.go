@@ -55,7 +55,7 @@ func TestBookingRepositoryPresenceReport(t *testing.T) {
 	GetBookingRepository().Create(b2_1)
 
 	end := tomorrow.Add(24 * 7 * time.Hour)
-	res, err := GetBookingRepository().GetPresenceReport(org.ID, nil, tomorrow, end, 99999, 0)
+	res, err := GetBookingRepository().GetPresenceReport(org.ID, nil, nil, tomorrow, end, 99999, 0)
 
 	CheckTestBool(t, true, err == nil)
 	CheckTestInt(t, 3, len(res))
@@ -130,7 +130,7 @@ func TestBookingRepositoryPresenceReportFilterByLocation(t *testing.T) {
 	GetBookingRepository().Create(b2_2)
 
 	// get presence report for location1 for yesterday
-	res, err := GetBookingRepository().GetPresenceReport(org.ID, location1, yesterday, yesterday.Add(8*time.Hour), 99999, 0)
+	res, err := GetBookingRepository().GetPresenceReport(org.ID, location1, nil, yesterday, yesterday.Add(8*time.Hour), 99999, 0)
 	CheckTestBool(t, true, err == nil)
 
 	for _, item := range res {
@@ -573,7 +573,7 @@ func TestBookingRepositoryCurrentWithLocationTimezone(t *testing.T) {
 	CheckTestString(t, currentBooking.ID, currentBookings[0].ID)
 
 	now := DateRange{Enter: time.Now().UTC(), Leave: time.Now().UTC()}
-	counts, err := GetBookingRepository().GetCountsSummary(org.ID, now, now, now)
+	counts, err := GetBookingRepository().GetCountsSummary(org.ID, nil, now, now, now)
 	CheckTestBool(t, true, err == nil)
 	CheckTestInt(t, 1, counts.Current)
 }
@@ -622,6 +622,96 @@ func TestGetBookingsDueForReminderExcludesNotApproved(t *testing.T) {
 	CheckTestInt(t, 0, len(results))
 }
 
+func TestBookingRepositoryCompletePendingOnce(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	_, space := CreateTestLocationAndSpace(org)
+
+	enter := time.Now().UTC().Add(48 * time.Hour)
+	b := &Booking{
+		UserID:  user.ID,
+		SpaceID: space.ID,
+		Enter:   enter,
+		Leave:   enter.Add(8 * time.Hour),
+	}
+	GetBookingRepository().Create(b)
+	e, err := GetBookingRepository().GetOne(b.ID)
+	CheckTestIsNil(t, err)
+
+	// Only the first of concurrent approvals and declines changes the booking
+	changed, err := GetBookingRepository().SetApproved(b.ID)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, changed)
+	changed, err = GetBookingRepository().SetApproved(b.ID)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, changed)
+	changed, err = GetBookingRepository().DeletePending(e)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, changed)
+
+	e, err = GetBookingRepository().GetOne(b.ID)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, e.Approved)
+
+	// Pending bookings can be declined once
+	b2 := &Booking{
+		UserID:  user.ID,
+		SpaceID: space.ID,
+		Enter:   enter.Add(24 * time.Hour),
+		Leave:   enter.Add(32 * time.Hour),
+	}
+	GetBookingRepository().Create(b2)
+	e2, _ := GetBookingRepository().GetOne(b2.ID)
+	changed, err = GetBookingRepository().DeletePending(e2)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, changed)
+	changed, err = GetBookingRepository().SetApproved(b2.ID)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, changed)
+}
+
+func TestBookingRepositorySetApprovalLevelOnce(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	_, space := CreateTestLocationAndSpace(org)
+
+	enter := time.Now().UTC().Add(48 * time.Hour)
+	b := &Booking{
+		UserID:  user.ID,
+		SpaceID: space.ID,
+		Enter:   enter,
+		Leave:   enter.Add(8 * time.Hour),
+	}
+	GetBookingRepository().Create(b)
+
+	// Only the first of concurrent first level approvals moves the booking
+	changed, err := GetBookingRepository().SetApprovalLevel(b.ID, ApprovalLevelSecond, "")
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, changed)
+	changed, err = GetBookingRepository().SetApprovalLevel(b.ID, ApprovalLevelSecond, "")
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, changed)
+
+	e, err := GetBookingRepository().GetOne(b.ID)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, ApprovalLevelSecond, e.ApprovalLevel)
+
+	// Approved bookings are not moved
+	b2 := &Booking{
+		UserID:  user.ID,
+		SpaceID: space.ID,
+		Enter:   enter.Add(24 * time.Hour),
+		Leave:   enter.Add(32 * time.Hour),
+	}
+	GetBookingRepository().Create(b2)
+	GetBookingRepository().SetApproved(b2.ID)
+	changed, err = GetBookingRepository().SetApprovalLevel(b2.ID, ApprovalLevelSecond, "")
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, changed)
+}
+
 func TestGetBookingsDueForReminderExcludesAlreadySent(t *testing.T) {
 	ClearTestDB()
 	org := CreateTestOrg("test.com")
@@ -782,7 +872,7 @@ func TestBookingRepositoryCurrentWithDefaultTimezone(t *testing.T) {
 	CheckTestString(t, currentBooking.ID, currentBookings[0].ID)
 
 	now := DateRange{Enter: time.Now().UTC(), Leave: time.Now().UTC()}
-	counts, err := GetBookingRepository().GetCountsSummary(org.ID, now, now, now)
+	counts, err := GetBookingRepository().GetCountsSummary(org.ID, nil, now, now, now)
 	CheckTestBool(t, true, err == nil)
 	CheckTestInt(t, 1, counts.Current)
 }
@@ -823,7 +913,7 @@ func TestBookingRepositoryGetCountsSummary(t *testing.T) {
 	// Currently running right now.
 	book(time.Now().UTC().Add(-1*time.Hour), time.Now().UTC().Add(1*time.Hour))
 
-	counts, err := GetBookingRepository().GetCountsSummary(org.ID, today, yesterday, thisWeek)
+	counts, err := GetBookingRepository().GetCountsSummary(org.ID, nil, today, yesterday, thisWeek)
 	CheckTestIsNil(t, err)
 	CheckTestInt(t, 6, counts.Total)
 	CheckTestInt(t, 2, counts.Today)     // 09-01 booking + the one spanning midnight
@@ -849,7 +939,7 @@ func TestBookingRepositoryGetCountsSummaryOrgIsolation(t *testing.T) {
 	GetBookingRepository().Create(&Booking{UserID: userB.ID, SpaceID: spaceB.ID, Enter: enter, Leave: leave})
 
 	today := DateRange{Enter: time.Date(2030, 9, 1, 0, 0, 0, 0, time.UTC), Leave: time.Date(2030, 9, 1, 23, 0, 0, 0, time.UTC)}
-	counts, err := GetBookingRepository().GetCountsSummary(orgA.ID, today, today, today)
+	counts, err := GetBookingRepository().GetCountsSummary(orgA.ID, nil, today, today, today)
 	CheckTestIsNil(t, err)
 	CheckTestInt(t, 1, counts.Total)
 	CheckTestInt(t, 1, counts.Today)
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lib/pq"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+)
+
+type UserAttributeRepository struct {
+}
+
+// UserAttributeTypeManager is the type of user attributes holding the email
+// address of the user's manager. Managers can view the presence of the users
+// reporting to them.
+const UserAttributeTypeManager SettingType = 10
+
+// UserAttribute is an organization-defined profile field of users, such as
+// department or cost center. If ClaimName is set, the value is synchronized
+// from the identity provider's user info claim of that name on every login.
+type UserAttribute struct {
+	ID             string
+	OrganizationID string
+	Label          string
+	Type           SettingType
+	ClaimName      string
+}
+
+type UserAttributeValue struct {
+	AttributeID string
+	UserID      string
+	Value       string
+}
+
+var userAttributeRepository *UserAttributeRepository
+var userAttributeRepositoryOnce sync.Once
+
+func GetUserAttributeRepository() *UserAttributeRepository {
+	userAttributeRepositoryOnce.Do(func() {
+		userAttributeRepository = &UserAttributeRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS user_attributes (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NOT NULL, " +
+			"label VARCHAR NOT NULL, " +
+			"type INTEGER DEFAULT " + strconv.Itoa(int(SettingTypeString)) + ", " +
+			"claim_name VARCHAR NOT NULL DEFAULT '', " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		_, err = GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS user_attribute_values (" +
+			"attribute_id uuid NOT NULL, " +
+			"user_id uuid NOT NULL, " +
+			"value VARCHAR NOT NULL DEFAULT '', " +
+			"PRIMARY KEY (attribute_id, user_id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_user_attribute_values_user_id ON user_attribute_values(user_id)"); err != nil {
+			panic(err)
+		}
+	})
+	return userAttributeRepository
+}
+
+func (r *UserAttributeRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// nothing yet
+}
+
+func (r *UserAttributeRepository) Create(e *UserAttribute) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO user_attributes "+
+		"(organization_id, label, type, claim_name) "+
+		"VALUES ($1, $2, $3, $4) "+
+		"RETURNING id",
+		e.OrganizationID, e.Label, e.Type, e.ClaimName).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *UserAttributeRepository) GetOne(id string) (*UserAttribute, error) {
+	e := &UserAttribute{}
+	err := GetDatabase().DB().QueryRow("SELECT id, organization_id, label, type, claim_name "+
+		"FROM user_attributes "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.OrganizationID, &e.Label, &e.Type, &e.ClaimName)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *UserAttributeRepository) GetAll(organizationID string) ([]*UserAttribute, error) {
+	var result []*UserAttribute
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, label, type, claim_name "+
+		"FROM user_attributes "+
+		"WHERE organization_id = $1 "+
+		"ORDER BY label", organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &UserAttribute{}
+		err = rows.Scan(&e.ID, &e.OrganizationID, &e.Label, &e.Type, &e.ClaimName)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// GetManagerAttribute returns the organization's manager attribute or nil if
+// the organization does not define one.
+func (r *UserAttributeRepository) GetManagerAttribute(organizationID string) (*UserAttribute, error) {
+	list, err := r.GetAll(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range list {
+		if e.Type == UserAttributeTypeManager {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *UserAttributeRepository) Update(e *UserAttribute) error {
+	_, err := GetDatabase().DB().Exec("UPDATE user_attributes SET "+
+		"label = $1, "+
+		"type = $2, "+
+		"claim_name = $3 "+
+		"WHERE id = $4",
+		e.Label, e.Type, e.ClaimName, e.ID)
+	return err
+}
+
+func (r *UserAttributeRepository) Delete(e *UserAttribute) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM user_attribute_values WHERE attribute_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_allowed_attributes WHERE attribute_id = $1", e.ID); err != nil {
+		return err
+	}
+	_, err := GetDatabase().DB().Exec("DELETE FROM user_attributes WHERE id = $1", e.ID)
+	return err
+}
+
+func (r *UserAttributeRepository) DeleteAll(organizationID string) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM user_attribute_values WHERE "+
+		"attribute_id IN (SELECT id FROM user_attributes WHERE organization_id = $1)", organizationID); err != nil {
+		return err
+	}
+	_, err := GetDatabase().DB().Exec("DELETE FROM user_attributes WHERE organization_id = $1", organizationID)
+	return err
+}
+
+func (r *UserAttributeRepository) SetValue(attributeID, userID, value string) error {
+	_, err := GetDatabase().DB().Exec("INSERT INTO user_attribute_values (attribute_id, user_id, value) "+
+		"VALUES ($1, $2, $3) "+
+		"ON CONFLICT (attribute_id, user_id) DO UPDATE SET value = $3",
+		attributeID, userID, value)
+	return err
+}
+
+func (r *UserAttributeRepository) DeleteValue(attributeID, userID string) error {
+	_, err := GetDatabase().DB().Exec("DELETE FROM user_attribute_values WHERE attribute_id = $1 AND user_id = $2",
+		attributeID, userID)
+	return err
+}
+
+func (r *UserAttributeRepository) GetValuesForUser(userID string) ([]*UserAttributeValue, error) {
+	return r.GetValuesForUserList([]string{userID})
+}
+
+func (r *UserAttributeRepository) GetValuesForUserList(userIDs []string) ([]*UserAttributeValue, error) {
+	var result []*UserAttributeValue
+	rows, err := GetDatabase().DB().Query("SELECT attribute_id, user_id, value "+
+		"FROM user_attribute_values "+
+		"WHERE user_id = ANY($1::uuid[])",
+		pq.StringArray(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &UserAttributeValue{}
+		err = rows.Scan(&e.AttributeID, &e.UserID, &e.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// GetUserIDsByValue returns the IDs of all users whose value of the attribute
+// equals the specified value, ignoring case.
+func (r *UserAttributeRepository) GetUserIDsByValue(attributeID, value string) ([]string, error) {
+	result := []string{}
+	rows, err := GetDatabase().DB().Query("SELECT user_id "+
+		"FROM user_attribute_values "+
+		"WHERE attribute_id = $1 AND LOWER(value) = $2",
+		attributeID, strings.ToLower(strings.TrimSpace(value)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID string
+		if err = rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		result = append(result, userID)
+	}
+	return result, nil
+}
+
+// GetReportIDs returns the IDs of the users reporting to the specified manager
+// according to the organization's manager attribute.
+func (r *UserAttributeRepository) GetReportIDs(manager *User) ([]string, error) {
+	attribute, err := r.GetManagerAttribute(manager.OrganizationID)
+	if err != nil || attribute == nil {
+		return []string{}, err
+	}
+	return r.GetUserIDsByValue(attribute.ID, manager.Email)
+}
@@ -37,13 +37,20 @@ var (
 	PreferenceCalDAVUser            PreferenceName = PreferenceName{Name: "caldav_user", Type: SettingTypeString}
 	PreferenceCalDAVPass            PreferenceName = PreferenceName{Name: "caldav_pass", Type: SettingTypeEncryptedString}
 	PreferenceCalDAVPath            PreferenceName = PreferenceName{Name: "caldav_path", Type: SettingTypeString}
-	PreferenceMailNotifications     PreferenceName = PreferenceName{Name: "mail_notifications", Type: SettingTypeBool}
+	PreferenceMailNotifications     PreferenceName = PreferenceName{Name: "mail_notifications", Type: SettingTypeInt}
 	PreferenceMailReminder          PreferenceName = PreferenceName{Name: "mail_reminder", Type: SettingTypeBool}
 	PreferenceMailLanguage          PreferenceName = PreferenceName{Name: "mail_language", Type: SettingTypeString}
 	PreferenceDateFormat            PreferenceName = PreferenceName{Name: "date_format", Type: SettingTypeString}
 	PreferenceApprovalNotifications PreferenceName = PreferenceName{Name: "approval_notifications", Type: SettingTypeBool}
 	Preference24HourTime            PreferenceName = PreferenceName{Name: "use_24_hour_time", Type: SettingTypeBool}
 	PreferenceWeekStartDay          PreferenceName = PreferenceName{Name: "week_start_day", Type: SettingTypeInt}
+	PreferenceChatWebhookURL        PreferenceName = PreferenceName{Name: "chat_webhook_url", Type: SettingTypeEncryptedString}
+	PreferenceChatWebhookFormat     PreferenceName = PreferenceName{Name: "chat_webhook_format", Type: SettingTypeString}
+	PreferenceChatNotifications     PreferenceName = PreferenceName{Name: "chat_notifications", Type: SettingTypeBool}
+	PreferenceChatReminder          PreferenceName = PreferenceName{Name: "chat_reminder", Type: SettingTypeBool}
+	PreferencePushNotifications     PreferenceName = PreferenceName{Name: "push_notifications", Type: SettingTypeBool}
+	PreferencePushReminder          PreferenceName = PreferenceName{Name: "push_reminder", Type: SettingTypeBool}
+	PreferencePushApprovalRequests  PreferenceName = PreferenceName{Name: "push_approval_requests", Type: SettingTypeBool}
 )
 
 var (
@@ -52,6 +59,13 @@ var (
 	PreferenceEnterTimeNextWorkday int = 3
 )
 
+var (
+	PreferenceMailNotificationsOff          int = 0
+	PreferenceMailNotificationsImmediate    int = 1
+	PreferenceMailNotificationsDailyDigest  int = 2
+	PreferenceMailNotificationsWeeklyDigest int = 3
+)
+
 var userPreferencesRepository *UserPreferencesRepository
 var userPreferencesRepositoryOnce sync.Once
 
@@ -304,6 +304,28 @@ func (r *UserStore) GetAll(organizationID string, maxResults int, offset int) ([
 	return result, nil
 }
 
+// GetOrgAdmins returns the enabled org admins of the organization.
+func (r *UserStore) GetOrgAdmins(organizationID string) ([]*User, error) {
+	var result []*User
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, email, role, password, auth_provider_id, atlassian_id, disabled, ban_expiry, firstname, lastname, last_activity_at_utc, totp_secret, password_pending, password_update_required, api_token "+
+		"FROM users "+
+		"WHERE organization_id = $1 AND role IN ($2, $3) AND disabled IS FALSE "+
+		"ORDER BY email", organizationID, UserRoleOrgAdmin, UserRoleSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &User{}
+		err = rows.Scan(&e.ID, &e.OrganizationID, &e.Email, &e.Role, &e.HashedPassword, &e.AuthProviderID, &e.AtlassianID, &e.Disabled, &e.BanExpiry, &e.Firstname, &e.Lastname, &e.LastActivityAtUTC, &e.TotpSecret, &e.PasswordPending, &e.PasswordUpdateRequired, &e.ApiToken)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
 func (r *UserStore) GetAllByIDs(userIDs []string) ([]*User, error) {
 	var result []*User
 	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, email, role, password, auth_provider_id, atlassian_id, disabled, ban_expiry, firstname, lastname, last_activity_at_utc, totp_secret, password_pending, password_update_required, api_token "+
@@ -422,10 +444,50 @@ func (r *UserStore) Delete(e *User) error {
 		"user_id = $1", e.ID); err != nil {
 		return err
 	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM users_mail_digests WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
 	if _, err := GetDatabase().DB().Exec("DELETE FROM buddies WHERE "+
 		"owner_id = $1 OR buddy_id = $1", e.ID); err != nil {
 		return err
 	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM api_tokens WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM password_history WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM login_history WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM booking_cancellations WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM push_subscriptions WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM delegations WHERE "+
+		"user_id = $1 OR delegate_user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM roles_assignments WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM locations_admins WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM user_attribute_values WHERE "+
+		"user_id = $1", e.ID); err != nil {
+		return err
+	}
 	_, err := GetDatabase().DB().Exec("DELETE FROM users WHERE id = $1", e.ID)
 	return err
 }
@@ -440,10 +502,30 @@ func (r *UserStore) DeleteAll(organizationID string) error {
 		"user_id IN (SELECT id FROM users WHERE organization_id = $1)", organizationID); err != nil {
 		return err
 	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM users_mail_digests WHERE "+
+		"user_id IN (SELECT id FROM users WHERE organization_id = $1)", organizationID); err != nil {
+		return err
+	}
 	if _, err := GetDatabase().DB().Exec("DELETE FROM users_groups WHERE "+
 		"user_id IN (SELECT id FROM users WHERE organization_id = $1)", organizationID); err != nil {
 		return err
 	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM api_tokens WHERE "+
+		"user_id IN (SELECT id FROM users WHERE organization_id = $1)", organizationID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM password_history WHERE "+
+		"user_id IN (SELECT id FROM users WHERE organization_id = $1)", organizationID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM login_history WHERE "+
+		"organization_id = $1", organizationID); err != nil {
+		return err
+	}
+	if _, err := GetDatabase().DB().Exec("DELETE FROM push_subscriptions WHERE "+
+		"user_id IN (SELECT id FROM users WHERE organization_id = $1)", organizationID); err != nil {
+		return err
+	}
 	// Also delete refresh tokens
 	if _, err := GetDatabase().DB().Exec("DELETE FROM refresh_tokens WHERE "+
 		"user_id IN (SELECT id FROM users WHERE organization_id = $1)", organizationID); err != nil {
@@ -507,12 +589,18 @@ func (r *UserStore) EnableUsersWithExpiredBan() error {
 }
 
 func (r *UserStore) CanCreateUser(org *Organization) bool {
+	return r.CanCreateUsers(org, 1)
+}
+
+// CanCreateUsers returns whether num users can be added to the organization
+// without exceeding its user limit.
+func (r *UserStore) CanCreateUsers(org *Organization, num int) bool {
 	noUserLimit, _ := GetSettingsRepository().GetBool(org.ID, SettingFeatureNoUserLimit.Name)
 	if noUserLimit {
 		return true
 	}
 	curUsers, _ := GetUserRepository().GetCount(org.ID)
-	return curUsers < DefaultUserLimit
+	return curUsers+num <= DefaultUserLimit
 }
 
 func (r *UserStore) IsSpaceAdmin(user *User) bool {
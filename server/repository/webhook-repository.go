@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+}
+
+// Webhook is an organization-configured HTTP endpoint which receives signed
+// event notifications.
+type Webhook struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	URL            string
+	// HMAC-SHA256 signing secret, encrypted with EncryptString
+	SecretEncrypted     string
+	Events              []string
+	Enabled             bool
+	ConsecutiveFailures int
+	DisabledAt          *time.Time
+	Created             time.Time
+}
+
+// WebhookDelivery is a single event to be delivered to a webhook. Deliveries
+// are persisted before sending (outbox) and retried until they succeed or
+// the maximum number of attempts is reached.
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	OrganizationID string
+	Event          string
+	Payload        string
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttempt    *time.Time
+	LastAttempt    *time.Time
+	ResponseCode   int
+	Error          string
+	Created        time.Time
+}
+
+type WebhookDeliveryStatus int
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = 0
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = 1
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = 2
+)
+
+const (
+	WebhookEventBookingCreated  = "booking.created"
+	WebhookEventBookingUpdated  = "booking.updated"
+	WebhookEventBookingDeleted  = "booking.deleted"
+	WebhookEventBookingApproved = "booking.approved"
+	WebhookEventBookingDeclined = "booking.declined"
+	WebhookEventUserCreated     = "user.created"
+	WebhookEventUserUpdated     = "user.updated"
+	WebhookEventUserDeleted     = "user.deleted"
+	WebhookEventSpaceCreated    = "space.created"
+	WebhookEventSpaceUpdated    = "space.updated"
+	WebhookEventSpaceDeleted    = "space.deleted"
+	// Sent on request of an admin only, webhooks cannot subscribe to it
+	WebhookEventTest = "webhook.test"
+)
+
+var WebhookEvents = []string{
+	WebhookEventBookingCreated,
+	WebhookEventBookingUpdated,
+	WebhookEventBookingDeleted,
+	WebhookEventBookingApproved,
+	WebhookEventBookingDeclined,
+	WebhookEventUserCreated,
+	WebhookEventUserUpdated,
+	WebhookEventUserDeleted,
+	WebhookEventSpaceCreated,
+	WebhookEventSpaceUpdated,
+	WebhookEventSpaceDeleted,
+}
+
+var webhookRepository *WebhookRepository
+var webhookRepositoryOnce sync.Once
+
+func GetWebhookRepository() *WebhookRepository {
+	webhookRepositoryOnce.Do(func() {
+		webhookRepository = &WebhookRepository{}
+		_, err := GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS webhooks (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"organization_id uuid NOT NULL, " +
+			"name VARCHAR NOT NULL, " +
+			"url VARCHAR NOT NULL, " +
+			"secret VARCHAR NOT NULL, " +
+			"events VARCHAR[] NOT NULL DEFAULT '{}', " +
+			"enabled boolean NOT NULL DEFAULT TRUE, " +
+			"consecutive_failures INTEGER NOT NULL DEFAULT 0, " +
+			"disabled_at TIMESTAMP NULL, " +
+			"created TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_webhooks_organization_id ON webhooks(organization_id)"); err != nil {
+			panic(err)
+		}
+		_, err = GetDatabase().DB().Exec("CREATE TABLE IF NOT EXISTS webhook_deliveries (" +
+			"id uuid DEFAULT uuid_generate_v4(), " +
+			"webhook_id uuid NOT NULL, " +
+			"organization_id uuid NOT NULL, " +
+			"event VARCHAR NOT NULL, " +
+			"payload TEXT NOT NULL, " +
+			"status INTEGER NOT NULL DEFAULT 0, " +
+			"attempts INTEGER NOT NULL DEFAULT 0, " +
+			"next_attempt TIMESTAMP NULL, " +
+			"last_attempt TIMESTAMP NULL, " +
+			"response_code INTEGER NOT NULL DEFAULT 0, " +
+			"error VARCHAR NOT NULL DEFAULT '', " +
+			"created TIMESTAMP NOT NULL, " +
+			"PRIMARY KEY (id))")
+		if err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created)"); err != nil {
+			panic(err)
+		}
+		if _, err = GetDatabase().DB().Exec("CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt ON webhook_deliveries(next_attempt) WHERE status = 0"); err != nil {
+			panic(err)
+		}
+	})
+	return webhookRepository
+}
+
+func (r *WebhookRepository) RunSchemaUpgrade(curVersion, targetVersion int) {
+	// no schema changes yet
+}
+
+func (r *WebhookRepository) Create(e *Webhook) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO webhooks "+
+		"(organization_id, name, url, secret, events, enabled, created) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7) "+
+		"RETURNING id",
+		e.OrganizationID, e.Name, e.URL, e.SecretEncrypted, pq.Array(e.Events), e.Enabled, e.Created).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *WebhookRepository) GetOne(id string) (*Webhook, error) {
+	e := &Webhook{}
+	var events pq.StringArray
+	err := GetDatabase().DB().QueryRow("SELECT id, organization_id, name, url, secret, events, enabled, consecutive_failures, disabled_at, created "+
+		"FROM webhooks "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.OrganizationID, &e.Name, &e.URL, &e.SecretEncrypted, &events, &e.Enabled, &e.ConsecutiveFailures, &e.DisabledAt, &e.Created)
+	if err != nil {
+		return nil, err
+	}
+	e.Events = events
+	return e, nil
+}
+
+func (r *WebhookRepository) GetAll(organizationID string) ([]*Webhook, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, name, url, secret, events, enabled, consecutive_failures, disabled_at, created "+
+		"FROM webhooks "+
+		"WHERE organization_id = $1 "+
+		"ORDER BY name",
+		organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanWebhooks(rows)
+}
+
+// GetEnabledForEvent returns the enabled webhooks of the organization which
+// subscribed to the given event.
+func (r *WebhookRepository) GetEnabledForEvent(organizationID, event string) ([]*Webhook, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, organization_id, name, url, secret, events, enabled, consecutive_failures, disabled_at, created "+
+		"FROM webhooks "+
+		"WHERE organization_id = $1 AND enabled = TRUE AND $2 = ANY(events)",
+		organizationID, event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanWebhooks(rows)
+}
+
+func (r *WebhookRepository) scanWebhooks(rows *sql.Rows) ([]*Webhook, error) {
+	result := []*Webhook{}
+	for rows.Next() {
+		e := &Webhook{}
+		var events pq.StringArray
+		err := rows.Scan(&e.ID, &e.OrganizationID, &e.Name, &e.URL, &e.SecretEncrypted, &events, &e.Enabled, &e.ConsecutiveFailures, &e.DisabledAt, &e.Created)
+		if err != nil {
+			return nil, err
+		}
+		e.Events = events
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// Update saves the webhook's configuration. Saving an enabled webhook resets
+// its failure counter.
+func (r *WebhookRepository) Update(e *Webhook) error {
+	if e.Enabled {
+		e.ConsecutiveFailures = 0
+		e.DisabledAt = nil
+	}
+	_, err := GetDatabase().DB().Exec("UPDATE webhooks SET "+
+		"name = $1, url = $2, secret = $3, events = $4, enabled = $5, consecutive_failures = $6, disabled_at = $7 "+
+		"WHERE id = $8",
+		e.Name, e.URL, e.SecretEncrypted, pq.Array(e.Events), e.Enabled, e.ConsecutiveFailures, e.DisabledAt, e.ID)
+	return err
+}
+
+func (r *WebhookRepository) Delete(e *Webhook) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM webhook_deliveries WHERE webhook_id = $1", e.ID); err != nil {
+		return err
+	}
+	_, err := GetDatabase().DB().Exec("DELETE FROM webhooks WHERE id = $1", e.ID)
+	return err
+}
+
+func (r *WebhookRepository) DeleteAll(organizationID string) error {
+	if _, err := GetDatabase().DB().Exec("DELETE FROM webhook_deliveries WHERE organization_id = $1", organizationID); err != nil {
+		return err
+	}
+	_, err := GetDatabase().DB().Exec("DELETE FROM webhooks WHERE organization_id = $1", organizationID)
+	return err
+}
+
+func (r *WebhookRepository) ResetFailures(webhookID string) error {
+	_, err := GetDatabase().DB().Exec("UPDATE webhooks SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures > 0", webhookID)
+	return err
+}
+
+// RecordFailure increments the webhook's failure counter and disables the
+// webhook once maxFailures consecutive attempts failed. Returns true if the
+// webhook has been disabled by this call.
+func (r *WebhookRepository) RecordFailure(webhookID string, maxFailures int) (bool, error) {
+	var disabled bool
+	err := GetDatabase().DB().QueryRow("UPDATE webhooks SET "+
+		"consecutive_failures = consecutive_failures + 1, "+
+		"enabled = (enabled AND consecutive_failures + 1 < $2), "+
+		"disabled_at = CASE WHEN enabled AND consecutive_failures + 1 >= $2 THEN $3 ELSE disabled_at END "+
+		"WHERE id = $1 "+
+		"RETURNING COALESCE(disabled_at = $3, FALSE)",
+		webhookID, maxFailures, time.Now().UTC()).Scan(&disabled)
+	if err != nil {
+		return false, err
+	}
+	return disabled, nil
+}
+
+func (r *WebhookRepository) CreateDelivery(e *WebhookDelivery) error {
+	var id string
+	err := GetDatabase().DB().QueryRow("INSERT INTO webhook_deliveries "+
+		"(webhook_id, organization_id, event, payload, status, attempts, next_attempt, created) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) "+
+		"RETURNING id",
+		e.WebhookID, e.OrganizationID, e.Event, e.Payload, e.Status, e.Attempts, e.NextAttempt, e.Created).Scan(&id)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+func (r *WebhookRepository) UpdateDelivery(e *WebhookDelivery) error {
+	_, err := GetDatabase().DB().Exec("UPDATE webhook_deliveries SET "+
+		"status = $1, attempts = $2, next_attempt = $3, last_attempt = $4, response_code = $5, error = $6 "+
+		"WHERE id = $7",
+		e.Status, e.Attempts, e.NextAttempt, e.LastAttempt, e.ResponseCode, e.Error, e.ID)
+	return err
+}
+
+func (r *WebhookRepository) GetDelivery(id string) (*WebhookDelivery, error) {
+	e := &WebhookDelivery{}
+	err := GetDatabase().DB().QueryRow("SELECT id, webhook_id, organization_id, event, payload, status, attempts, next_attempt, last_attempt, response_code, error, created "+
+		"FROM webhook_deliveries "+
+		"WHERE id = $1",
+		id).Scan(&e.ID, &e.WebhookID, &e.OrganizationID, &e.Event, &e.Payload, &e.Status, &e.Attempts, &e.NextAttempt, &e.LastAttempt, &e.ResponseCode, &e.Error, &e.Created)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetDeliveries returns the most recent deliveries of a webhook, newest first.
+func (r *WebhookRepository) GetDeliveries(webhookID string, maxResults int) ([]*WebhookDelivery, error) {
+	rows, err := GetDatabase().DB().Query("SELECT id, webhook_id, organization_id, event, payload, status, attempts, next_attempt, last_attempt, response_code, error, created "+
+		"FROM webhook_deliveries "+
+		"WHERE webhook_id = $1 "+
+		"ORDER BY created DESC "+
+		"LIMIT $2",
+		webhookID, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanDeliveries(rows)
+}
+
+// ClaimDueDeliveries returns up to maxResults pending deliveries which are
+// due for sending. The returned deliveries are leased for the given duration
+// so that concurrent workers (i.e. other instances) do not pick them up, too.
+func (r *WebhookRepository) ClaimDueDeliveries(maxResults int, lease time.Duration) ([]*WebhookDelivery, error) {
+	now := time.Now().UTC()
+	rows, err := GetDatabase().DB().Query("UPDATE webhook_deliveries SET next_attempt = $1 "+
+		"WHERE id IN ("+
+		"SELECT id FROM webhook_deliveries "+
+		"WHERE status = $2 AND next_attempt <= $3 "+
+		"ORDER BY next_attempt "+
+		"LIMIT $4 "+
+		"FOR UPDATE SKIP LOCKED) "+
+		"RETURNING id, webhook_id, organization_id, event, payload, status, attempts, next_attempt, last_attempt, response_code, error, created",
+		now.Add(lease), WebhookDeliveryStatusPending, now, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanDeliveries(rows)
+}
+
+// FailPendingDeliveries marks all pending deliveries of a webhook as failed,
+// i.e. after the webhook has been disabled.
+func (r *WebhookRepository) FailPendingDeliveries(webhookID, reason string) error {
+	_, err := GetDatabase().DB().Exec("UPDATE webhook_deliveries SET "+
+		"status = $1, next_attempt = NULL, error = $2 "+
+		"WHERE webhook_id = $3 AND status = $4",
+		WebhookDeliveryStatusFailed, reason, webhookID, WebhookDeliveryStatusPending)
+	return err
+}
+
+func (r *WebhookRepository) scanDeliveries(rows *sql.Rows) ([]*WebhookDelivery, error) {
+	result := []*WebhookDelivery{}
+	for rows.Next() {
+		e := &WebhookDelivery{}
+		err := rows.Scan(&e.ID, &e.WebhookID, &e.OrganizationID, &e.Event, &e.Payload, &e.Status, &e.Attempts, &e.NextAttempt, &e.LastAttempt, &e.ResponseCode, &e.Error, &e.Created)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (r *WebhookRepository) PurgeOldDeliveries(maxAge time.Duration, batchSize int) (int, error) {
+	limit := time.Now().Add(-maxAge)
+	result, err := GetDatabase().DB().Exec("DELETE FROM webhook_deliveries WHERE id IN ("+
+		"SELECT id FROM webhook_deliveries WHERE status != $1 AND created < $2 ORDER BY created ASC LIMIT $3)",
+		WebhookDeliveryStatusPending, limit, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	num, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(num), nil
+}
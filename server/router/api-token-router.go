@@ -0,0 +1,314 @@
+package router
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+// Maximum number of API tokens a single user can own
+const maxApiTokensPerUser = 25
+
+type ApiTokenRouter struct {
+}
+
+type CreateApiTokenRequest struct {
+	Name   string     `json:"name" validate:"required,max=255"`
+	Scopes []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	Expiry *time.Time `json:"expiry"`
+	UserID string     `json:"userId" validate:"omitempty,uuid"`
+}
+
+type GetApiTokenResponse struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Created    time.Time  `json:"created"`
+	Expiry     *time.Time `json:"expiry"`
+	LastUsed   *time.Time `json:"lastUsed"`
+	LastUsedIP string     `json:"lastUsedIp"`
+}
+
+type CreateApiTokenResponse struct {
+	GetApiTokenResponse
+	Token string `json:"token"`
+}
+
+// apiTokenScopeRule maps a path prefix or pattern to the scopes required for
+// reading (GET) and writing (any other method). An empty scope denies access.
+type apiTokenScopeRule struct {
+	pathPrefix  string
+	pathPattern string // Matched using path.Match if set instead of pathPrefix
+	readScope   string
+	writeScope  string
+}
+
+func (rule *apiTokenScopeRule) matches(p string) bool {
+	if rule.pathPattern != "" {
+		ok, _ := path.Match(rule.pathPattern, p)
+		return ok
+	}
+	return strings.HasPrefix(p, rule.pathPrefix)
+}
+
+// Rules are evaluated in order, the first matching prefix wins. Paths without
+// a matching rule cannot be accessed using API tokens.
+var apiTokenScopeRules = []*apiTokenScopeRule{
+	{pathPrefix: "/booking/report/", readScope: ApiTokenScopeStatsRead},
+	{pathPrefix: "/booking/", readScope: ApiTokenScopeBookingsRead, writeScope: ApiTokenScopeBookingsWrite},
+	{pathPrefix: "/recurring-booking/", readScope: ApiTokenScopeBookingsRead, writeScope: ApiTokenScopeBookingsWrite},
+	{pathPrefix: "/location/", readScope: ApiTokenScopeSpacesRead, writeScope: ApiTokenScopeSpacesWrite},
+	{pathPrefix: "/space-attribute/", readScope: ApiTokenScopeSpacesRead, writeScope: ApiTokenScopeSpacesWrite},
+	{pathPrefix: "/search/", readScope: ApiTokenScopeSpacesRead},
+	{pathPrefix: "/user/passkey/"},
+	{pathPrefix: "/user/totp/"},
+	{pathPrefix: "/user/merge/"},
+	{pathPattern: "/user/*/password/"},
+	{pathPrefix: "/user/", readScope: ApiTokenScopeUsersRead, writeScope: ApiTokenScopeAdminUsers},
+	{pathPrefix: "/group/", readScope: ApiTokenScopeUsersRead, writeScope: ApiTokenScopeAdminUsers},
+	{pathPrefix: "/setting/", readScope: ApiTokenScopeAdminSettings, writeScope: ApiTokenScopeAdminSettings},
+	{pathPrefix: "/stats/", readScope: ApiTokenScopeStatsRead},
+	{pathPrefix: "/webhook/", readScope: ApiTokenScopeAdminSettings, writeScope: ApiTokenScopeAdminSettings},
+	{pathPrefix: "/mail-queue/", readScope: ApiTokenScopeAdminSettings, writeScope: ApiTokenScopeAdminSettings},
+	{pathPrefix: "/email-template/", readScope: ApiTokenScopeAdminSettings, writeScope: ApiTokenScopeAdminSettings},
+	{pathPrefix: "/mail-settings/", readScope: ApiTokenScopeAdminSettings, writeScope: ApiTokenScopeAdminSettings},
+}
+
+// GetApiTokenRequiredScope returns the scope an API token needs to perform the
+// request or an empty string if the request is not allowed for API tokens.
+func GetApiTokenRequiredScope(r *http.Request) string {
+	p := getApiTokenRequestPath(r)
+	// Any token may retrieve the user it belongs to
+	if p == "/user/me/" && r.Method == "GET" {
+		return "*"
+	}
+	if strings.Contains(p, "/api-token/") {
+		return ""
+	}
+	for _, rule := range apiTokenScopeRules {
+		if rule.matches(p) {
+			if r.Method == "GET" || r.Method == "HEAD" {
+				return rule.readScope
+			}
+			return rule.writeScope
+		}
+	}
+	return ""
+}
+
+// getApiTokenRequestPath returns the request's path with a trailing slash.
+func getApiTokenRequestPath(r *http.Request) string {
+	p := r.URL.Path
+	if !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return p
+}
+
+// HasApiTokenScope checks if the token grants the scope required for the request.
+func HasApiTokenScope(token *ApiToken, r *http.Request) bool {
+	scope := GetApiTokenRequiredScope(r)
+	if scope == "" {
+		return false
+	}
+	// Tokens must not modify the account they belong to, e.g. change its
+	// password or email address, so a leaked token cannot take it over
+	if r.Method != "GET" && r.Method != "HEAD" {
+		p := getApiTokenRequestPath(r)
+		if strings.HasPrefix(p, "/user/me/") || strings.HasPrefix(p, "/user/"+token.UserID+"/") {
+			return false
+		}
+	}
+	if scope == "*" {
+		return true
+	}
+	return slices.Contains(token.Scopes, scope)
+}
+
+func (router *ApiTokenRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/scopes", router.getScopes).Methods("GET")
+	s.HandleFunc("/user/{userId}", router.getAllByUser).Methods("GET")
+	s.HandleFunc("/{id}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.create).Methods("POST")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+func (router *ApiTokenRouter) getScopes(w http.ResponseWriter, r *http.Request) {
+	SendJSON(w, ApiTokenScopes)
+}
+
+func (router *ApiTokenRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	router.sendList(w, GetRequestUserID(r))
+}
+
+func (router *ApiTokenRouter) getAllByUser(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	vars := mux.Vars(r)
+	e, err := GetUserRepository().GetOne(vars["userId"])
+	if err != nil || e == nil {
+		SendNotFound(w)
+		return
+	}
+	if e.ID != user.ID && !CanAdminOrg(user, e.OrganizationID) {
+		SendForbidden(w)
+		return
+	}
+	router.sendList(w, e.ID)
+}
+
+func (router *ApiTokenRouter) sendList(w http.ResponseWriter, userID string) {
+	list, err := GetApiTokenRepository().GetAllByUserID(userID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetApiTokenResponse{}
+	for _, e := range list {
+		res = append(res, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+func (router *ApiTokenRouter) create(w http.ResponseWriter, r *http.Request) {
+	var m CreateApiTokenRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	user := GetRequestUser(r)
+	owner := user
+	if m.UserID != "" && m.UserID != user.ID {
+		// Org admins can issue tokens for the service accounts of their organization
+		e, err := GetUserRepository().GetOne(m.UserID)
+		if err != nil || e == nil {
+			SendNotFound(w)
+			return
+		}
+		if !CanAdminOrg(user, e.OrganizationID) {
+			SendForbidden(w)
+			return
+		}
+		if !isServiceAccountRole(int(e.Role)) {
+			SendBadRequest(w)
+			return
+		}
+		owner = e
+	}
+	for _, scope := range m.Scopes {
+		if !slices.Contains(ApiTokenScopes, scope) {
+			SendBadRequest(w)
+			return
+		}
+	}
+	if m.Expiry != nil && !m.Expiry.After(time.Now()) {
+		SendBadRequest(w)
+		return
+	}
+	count, err := GetApiTokenRepository().GetCountByUserID(owner.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	if count >= maxApiTokensPerUser {
+		SendPaymentRequired(w)
+		return
+	}
+	rawBytes := make([]byte, 32)
+	if _, err := rand.Read(rawBytes); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	rawToken := ApiTokenPrefix + hex.EncodeToString(rawBytes)
+	e := &ApiToken{
+		UserID:    owner.ID,
+		Name:      m.Name,
+		TokenHash: GetApiTokenHash(rawToken),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(m.Scopes))),
+		Created:   time.Now().UTC(),
+	}
+	if m.Expiry != nil {
+		expiry := m.Expiry.UTC()
+		e.Expiry = &expiry
+	}
+	if err := GetApiTokenRepository().Create(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionCreate,
+		EntityType:     AuditEntityApiToken,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: owner.OrganizationID,
+	}, nil, router.copyToRestModel(e))
+	res := &CreateApiTokenResponse{
+		GetApiTokenResponse: *router.copyToRestModel(e),
+		Token:               rawToken,
+	}
+	w.Header().Set("X-Object-ID", e.ID)
+	w.WriteHeader(http.StatusCreated)
+	SendJSON(w, res)
+}
+
+func (router *ApiTokenRouter) delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	e, err := GetApiTokenRepository().GetOne(vars["id"])
+	if err != nil || e == nil {
+		SendNotFound(w)
+		return
+	}
+	user := GetRequestUser(r)
+	if e.UserID != user.ID {
+		owner, err := GetUserRepository().GetOne(e.UserID)
+		if err != nil || owner == nil || !CanAdminOrg(user, owner.OrganizationID) {
+			SendForbidden(w)
+			return
+		}
+	}
+	if err := GetApiTokenRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionDelete,
+		EntityType: AuditEntityApiToken,
+		EntityID:   e.ID,
+		EntityName: e.Name,
+	}, router.copyToRestModel(e), nil)
+	SendUpdated(w)
+}
+
+func (router *ApiTokenRouter) copyToRestModel(e *ApiToken) *GetApiTokenResponse {
+	return &GetApiTokenResponse{
+		ID:         e.ID,
+		UserID:     e.UserID,
+		Name:       e.Name,
+		Scopes:     e.Scopes,
+		Created:    e.Created,
+		Expiry:     e.Expiry,
+		LastUsed:   e.LastUsed,
+		LastUsedIP: e.LastUsedIP,
+	}
+}
+
+// GetApiTokenHash returns the hash under which an API token is stored.
+func GetApiTokenHash(rawToken string) string {
+	hash := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,147 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const approvalLinkExpiry = 7 * 24 * time.Hour
+
+// ApprovalLinkRouter handles the links in approval request emails which allow
+// approvers to approve or decline a booking without logging in.
+type ApprovalLinkRouter struct {
+}
+
+// ApproveBookingByLinkRequest requires the decision to be given explicitly, so
+// that an incomplete request doesn't decline and delete the booking.
+type ApproveBookingByLinkRequest struct {
+	Approved *bool `json:"approved" validate:"required"`
+}
+
+type GetApprovalLinkResponse struct {
+	Booking       *GetBookingResponse `json:"booking"`
+	ApproverEmail string              `json:"approverEmail"`
+}
+
+func (router *ApprovalLinkRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/{token}", router.getOne).Methods("GET")
+	s.HandleFunc("/{token}", router.approve).Methods("POST")
+}
+
+// createApprovalLink returns the token of a new single-use link which allows
+// the approver to approve or decline the booking.
+func createApprovalLink(bookingID, approverID string) (string, error) {
+	authState := &AuthState{
+		AuthProviderID: GetSettingsRepository().GetNullUUID(),
+		Expiry:         time.Now().Add(approvalLinkExpiry),
+		AuthStateType:  AuthBookingApproval,
+		Payload:        bookingID + ":" + approverID,
+	}
+	if err := GetAuthStateRepository().Create(authState); err != nil {
+		return "", err
+	}
+	return GetActionLinkToken(authState.ID), nil
+}
+
+// invalidateBookingApprovalLinks deletes the approval links of all approvers
+// once the booking has been approved or declined.
+func invalidateBookingApprovalLinks(bookingID string) {
+	if err := GetAuthStateRepository().DeleteByPayloadPrefix(AuthBookingApproval, bookingID+":"); err != nil {
+		log.Println(err)
+	}
+}
+
+func (router *ApprovalLinkRouter) getOne(w http.ResponseWriter, r *http.Request) {
+	_, e, approver := router.getLinkState(w, r)
+	if e == nil {
+		return
+	}
+	SendJSON(w, &GetApprovalLinkResponse{
+		Booking:       (&BookingRouter{}).copyToRestModel(e),
+		ApproverEmail: approver.Email,
+	})
+}
+
+func (router *ApprovalLinkRouter) approve(w http.ResponseWriter, r *http.Request) {
+	var m ApproveBookingByLinkRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	authState, e, approver := router.getLinkState(w, r)
+	if e == nil {
+		return
+	}
+	consumed, err := GetAuthStateRepository().Consume(authState)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	if !consumed {
+		SendGone(w)
+		return
+	}
+	// Record the action as performed by the approver the link was sent to
+	r = SetRequestUserID(r, approver.ID)
+	if err := (&BookingRouter{}).setBookingApproval(r, e, *m.Approved); err == errBookingApprovalCompleted {
+		SendGone(w)
+		return
+	} else if err != nil {
+		log.Println(err)
+		// Allow the approver to try again
+		if err := GetAuthStateRepository().Restore(authState); err != nil {
+			log.Println(err)
+		}
+		SendInternalServerError(w)
+		return
+	}
+	SendUpdated(w)
+}
+
+// getLinkState returns the auth state, booking and approver of a valid link.
+// Otherwise, an error response is sent and nil is returned. Links for
+// bookings which have been processed in the meantime are answered with 410.
+func (router *ApprovalLinkRouter) getLinkState(w http.ResponseWriter, r *http.Request) (*AuthState, *BookingDetails, *User) {
+	id, ok := ParseActionLinkToken(mux.Vars(r)["token"])
+	if !ok {
+		SendNotFound(w)
+		return nil, nil, nil
+	}
+	authState, err := GetAuthStateRepository().GetOneActive(id)
+	if err != nil || authState.AuthStateType != AuthBookingApproval {
+		SendNotFound(w)
+		return nil, nil, nil
+	}
+	bookingID, approverID, found := strings.Cut(authState.Payload, ":")
+	if !found {
+		SendNotFound(w)
+		return nil, nil, nil
+	}
+	approver, err := GetUserRepository().GetOne(approverID)
+	if err != nil || approver.Disabled {
+		SendNotFound(w)
+		return nil, nil, nil
+	}
+	e, err := GetBookingRepository().GetOne(bookingID)
+	if err != nil || e.Approved || e.Leave.Before(time.Now().Add(-24*time.Hour)) {
+		GetAuthStateRepository().Delete(authState)
+		SendGone(w)
+		return nil, nil, nil
+	}
+	if e.Space.Location.OrganizationID != approver.OrganizationID ||
+		!HasLocationPermission(approver, e.Space.Location.OrganizationID, PermissionApproveBookings, e.Space.LocationID) ||
+		!(&BookingRouter{}).isValidApproverForBooking(approver.ID, &e.Booking) {
+		SendForbidden(w)
+		return nil, nil, nil
+	}
+	return authState, e, approver
+}
@@ -0,0 +1,135 @@
+package router
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+var approvalSLAMu sync.Mutex
+
+// approvalSLA holds an organization's settings for pending approvals.
+type approvalSLA struct {
+	ReminderHours     int
+	EscalationHours   int
+	EscalationGroupID string
+	ExpiryAction      string
+	ExpiryHours       int
+}
+
+// ProcessPendingApprovals reminds approvers of pending bookings, escalates
+// them to the fallback group and applies the expiry action as configured.
+func ProcessPendingApprovals() {
+	ProcessPendingApprovalsAt(time.Now().UTC())
+}
+
+// ProcessPendingApprovalsAt processes the pending bookings of all
+// organizations which have configured approval reminders, escalation or
+// expiry, as of the given time.
+func ProcessPendingApprovalsAt(now time.Time) {
+	approvalSLAMu.Lock()
+	defer approvalSLAMu.Unlock()
+
+	orgIDs, err := GetSettingsRepository().GetOrgIDsWithAnySet([]string{
+		SettingApprovalReminderHours.Name,
+		SettingApprovalEscalationHours.Name,
+		SettingApprovalExpiryAction.Name,
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	num := 0
+	for _, orgID := range orgIDs {
+		sla := getApprovalSLA(orgID)
+		list, err := GetBookingRepository().GetPendingApprovalsByOrg(orgID)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		for _, e := range list {
+			if processPendingApproval(e, sla, now) {
+				num++
+			}
+		}
+	}
+	if num > 0 {
+		log.Printf("Processed %d pending approvals", num)
+	}
+}
+
+func getApprovalSLA(organizationID string) *approvalSLA {
+	sla := &approvalSLA{}
+	sla.ReminderHours, _ = GetSettingsRepository().GetInt(organizationID, SettingApprovalReminderHours.Name)
+	sla.EscalationHours, _ = GetSettingsRepository().GetInt(organizationID, SettingApprovalEscalationHours.Name)
+	sla.ExpiryAction, _ = GetSettingsRepository().Get(organizationID, SettingApprovalExpiryAction.Name)
+	sla.ExpiryHours, _ = GetSettingsRepository().GetInt(organizationID, SettingApprovalExpiryHours.Name)
+	groupID, _ := GetSettingsRepository().Get(organizationID, SettingApprovalEscalationGroupID.Name)
+	if groupID != "" {
+		// Only escalate to groups of the organization
+		if group, err := GetGroupRepository().GetOne(groupID); err == nil && group.OrganizationID == organizationID {
+			sla.EscalationGroupID = group.ID
+		}
+	}
+	return sla
+}
+
+// processPendingApproval applies the first due step to the pending booking:
+// the expiry action once the start time approaches, the escalation and the
+// reminder. Returns true if a step has been applied.
+func processPendingApproval(e *BookingDetails, sla *approvalSLA, now time.Time) bool {
+	router := &BookingRouter{}
+	if sla.ExpiryAction == SettingApprovalExpiryActionApprove || sla.ExpiryAction == SettingApprovalExpiryActionDecline {
+		// Booking times are stored in location time
+		enter, err := GetLocationRepository().AttachTimezoneInformation(e.Enter, &e.Space.Location)
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+		if !now.Before(enter.Add(-time.Duration(sla.ExpiryHours) * time.Hour)) {
+			if err := router.completeBookingApproval(e, sla.ExpiryAction == SettingApprovalExpiryActionApprove); err == errBookingApprovalCompleted {
+				return false
+			} else if err != nil {
+				log.Println(err)
+				return false
+			}
+			return true
+		}
+	}
+	if e.ApprovalRequestedAtUTC == nil {
+		return false
+	}
+	pending := now.Sub(*e.ApprovalRequestedAtUTC)
+	pendingHours := int(pending.Hours())
+	if sla.EscalationHours > 0 && sla.EscalationGroupID != "" && e.ApprovalEscalationGroupID == "" &&
+		pending >= time.Duration(sla.EscalationHours)*time.Hour {
+		if err := GetBookingRepository().SetApprovalEscalated(e.ID, sla.EscalationGroupID); err != nil {
+			log.Println(err)
+			return false
+		}
+		e.ApprovalEscalationGroupID = NullUUID(sla.EscalationGroupID)
+		router.sendApprovalNotifications(&e.Booking, []string{sla.EscalationGroupID}, approvalNotificationEscalation, pendingHours)
+		return true
+	}
+	if sla.ReminderHours > 0 && e.ApprovalReminderSentAtUTC == nil &&
+		pending >= time.Duration(sla.ReminderHours)*time.Hour {
+		if err := GetBookingRepository().SetApprovalReminderSent(e.ID, &now); err != nil {
+			log.Println(err)
+			return false
+		}
+		approverGroupIDs, err := GetSpaceRepository().GetApproverGroupIDsForLevel(e.SpaceID, getBookingApprovalLevel(&e.Booking))
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+		if e.ApprovalEscalationGroupID != "" {
+			approverGroupIDs = append(approverGroupIDs, string(e.ApprovalEscalationGroupID))
+		}
+		router.sendApprovalNotifications(&e.Booking, approverGroupIDs, approvalNotificationReminder, pendingHours)
+		return true
+	}
+	return false
+}
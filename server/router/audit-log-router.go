@@ -0,0 +1,309 @@
+package router
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type AuditLogRouter struct {
+}
+
+type AuditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type GetAuditLogResponse struct {
+	ID          string          `json:"id"`
+	Timestamp   time.Time       `json:"timestamp"`
+	ActorUserID string          `json:"actorUserId"`
+	ActorEmail  string          `json:"actorEmail"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entityType"`
+	EntityID    string          `json:"entityId"`
+	EntityName  string          `json:"entityName"`
+	IP          string          `json:"ip"`
+	Changes     json.RawMessage `json:"changes"`
+}
+
+type GetAuditLogListResponse struct {
+	Total int                    `json:"total"`
+	Items []*GetAuditLogResponse `json:"items"`
+}
+
+const (
+	auditLogDefaultLimit = 50
+	auditLogMaxLimit     = 100
+	auditLogRedacted     = "[redacted]"
+)
+
+// Attributes containing one of these strings are never written to the audit log
+var auditLogSensitiveAttributes = []string{"password", "secret", "token", "hash"}
+
+func (router *AuditLogRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/export", router.export).Methods("GET")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+func (router *AuditLogRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionViewAuditLog) {
+		SendForbidden(w)
+		return
+	}
+	filter, err := router.getFilter(r, user)
+	if err != nil {
+		SendBadRequest(w)
+		return
+	}
+	limit := auditLogDefaultLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 {
+			SendBadRequest(w)
+			return
+		}
+		limit = min(parsed, auditLogMaxLimit)
+	}
+	offset := 0
+	if param := r.URL.Query().Get("offset"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 0 {
+			SendBadRequest(w)
+			return
+		}
+		offset = parsed
+	}
+	total, err := GetAuditLogRepository().CountFiltered(filter)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	list, err := GetAuditLogRepository().GetFiltered(filter, limit, offset)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := &GetAuditLogListResponse{
+		Total: total,
+		Items: []*GetAuditLogResponse{},
+	}
+	for _, e := range list {
+		res.Items = append(res.Items, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+func (router *AuditLogRouter) export(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionViewAuditLog) {
+		SendForbidden(w)
+		return
+	}
+	filter, err := router.getFilter(r, user)
+	if err != nil {
+		SendBadRequest(w)
+		return
+	}
+	list, err := GetAuditLogRepository().GetFiltered(filter, 0, 0)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"audit-log.csv\"")
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"timestamp", "actorUserId", "actorEmail", "action", "entityType", "entityId", "entityName", "ip", "changes"})
+	for _, e := range list {
+		writer.Write([]string{
+			e.Timestamp.UTC().Format(time.RFC3339),
+			e.ActorUserID,
+			escapeCSVFormula(e.ActorEmail),
+			e.Action,
+			e.EntityType,
+			escapeCSVFormula(e.EntityID),
+			escapeCSVFormula(e.EntityName),
+			e.IP,
+			e.Changes,
+		})
+	}
+	writer.Flush()
+}
+
+func (router *AuditLogRouter) getFilter(r *http.Request, user *User) (*AuditLogFilter, error) {
+	end := time.Now()
+	if param := r.URL.Query().Get("end"); param != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, param)
+		if err != nil {
+			return nil, err
+		}
+		end = parsed
+	}
+	start := end.Add(-7 * 24 * time.Hour)
+	if param := r.URL.Query().Get("start"); param != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, param)
+		if err != nil {
+			return nil, err
+		}
+		start = parsed
+	}
+	return &AuditLogFilter{
+		OrganizationID: user.OrganizationID,
+		Start:          start,
+		End:            end,
+		ActorLike:      r.URL.Query().Get("actor"),
+		Action:         r.URL.Query().Get("action"),
+		EntityType:     r.URL.Query().Get("entityType"),
+		EntityID:       r.URL.Query().Get("entityId"),
+	}, nil
+}
+
+func (router *AuditLogRouter) copyToRestModel(e *AuditLogEntry) *GetAuditLogResponse {
+	return &GetAuditLogResponse{
+		ID:          e.ID,
+		Timestamp:   e.Timestamp,
+		ActorUserID: e.ActorUserID,
+		ActorEmail:  e.ActorEmail,
+		Action:      e.Action,
+		EntityType:  e.EntityType,
+		EntityID:    e.EntityID,
+		EntityName:  e.EntityName,
+		IP:          e.IP,
+		Changes:     json.RawMessage(e.Changes),
+	}
+}
+
+// escapeCSVFormula prevents spreadsheet applications from interpreting
+// user-controlled values as formulas.
+func escapeCSVFormula(s string) string {
+	if s != "" && strings.ContainsAny(s[:1], "=+-@\t\r") {
+		return "'" + s
+	}
+	return s
+}
+
+// GetAuditChanges compares the JSON representations of an entity before and
+// after a change and returns the changed attributes. before is nil for
+// created and after is nil for deleted entities. Values of sensitive
+// attributes are redacted.
+func GetAuditChanges(before, after any) map[string]*AuditChange {
+	oldValues, newValues := toAuditValues(before), toAuditValues(after)
+	keys := []string{}
+	for k := range oldValues {
+		keys = append(keys, k)
+	}
+	for k := range newValues {
+		if _, ok := oldValues[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	res := map[string]*AuditChange{}
+	for _, k := range keys {
+		oldValue, newValue := oldValues[k], newValues[k]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if isSensitiveAuditAttribute(k) {
+			oldValue, newValue = redactAuditValue(oldValue), redactAuditValue(newValue)
+		}
+		res[k] = &AuditChange{Old: oldValue, New: newValue}
+	}
+	return res
+}
+
+func toAuditValues(v any) map[string]any {
+	res := map[string]any{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return res
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return res
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		var value any
+		json.Unmarshal(data, &value)
+		return map[string]any{"value": value}
+	}
+	return res
+}
+
+func isSensitiveAuditAttribute(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range auditLogSensitiveAttributes {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactAuditValue(v any) any {
+	if v == nil || v == "" {
+		return v
+	}
+	return auditLogRedacted
+}
+
+// recordAuditLog adds a change made by the requesting user to the audit log
+// and forwards it to the SIEM exporter. OrganizationID defaults to the
+// requesting user's organization.
+func recordAuditLog(r *http.Request, e *AuditLogEntry, before, after any) {
+	user := GetRequestUser(r)
+	if user == nil {
+		return
+	}
+	var ar AuthRouter
+	e.Timestamp = time.Now().UTC()
+	e.ActorUserID = user.ID
+	e.ActorEmail = user.Email
+	e.IP = GetClientIP(r)
+	if e.OrganizationID == "" {
+		e.OrganizationID = user.OrganizationID
+	}
+	changes, err := json.Marshal(GetAuditChanges(before, after))
+	if err != nil {
+		log.Println("Error computing audit log changes: " + err.Error())
+		changes = []byte("{}")
+	}
+	e.Changes = string(changes)
+	if err := GetAuditLogRepository().Create(e); err != nil {
+		log.Println("Error recording audit log entry: " + err.Error())
+	}
+	target := e.EntityName
+	if target == "" {
+		target = e.EntityID
+	}
+	ExportSecurityEvent(&SecurityEvent{
+		Timestamp:      e.Timestamp,
+		Category:       SecurityEventCategoryAdmin,
+		Action:         e.EntityType + "_" + e.Action,
+		Successful:     true,
+		OrganizationID: e.OrganizationID,
+		UserID:         user.ID,
+		Email:          user.Email,
+		SourceIP:       e.IP,
+		Device:         ar.GetDeviceInfo(r),
+		Target:         target,
+		Detail:         e.Changes,
+	})
+}
@@ -85,7 +85,7 @@ func (router *AuthProviderRouter) getOne(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !HasPermission(user, e.OrganizationID, PermissionManageSettings) {
 		SendForbidden(w)
 		return
 	}
@@ -95,7 +95,7 @@ func (router *AuthProviderRouter) getOne(w http.ResponseWriter, r *http.Request)
 
 func (router *AuthProviderRouter) getAll(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
 		SendForbidden(w)
 		return
 	}
@@ -153,7 +153,7 @@ func (router *AuthProviderRouter) update(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !HasPermission(user, e.OrganizationID, PermissionManageSettings) {
 		SendForbidden(w)
 		return
 	}
@@ -180,11 +180,19 @@ func (router *AuthProviderRouter) update(w http.ResponseWriter, r *http.Request)
 	eNew.ID = e.ID
 	eNew.OrganizationID = e.OrganizationID
 	eNew.ReadOnly = e.ReadOnly
+	before := router.getAuditModel(e)
 	if err := GetAuthProviderRepository().Update(eNew); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityAuthProvider,
+		EntityID:       e.ID,
+		EntityName:     eNew.Name,
+		OrganizationID: e.OrganizationID,
+	}, before, router.getAuditModel(eNew))
 	SendUpdated(w)
 }
 
@@ -197,7 +205,7 @@ func (router *AuthProviderRouter) delete(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !HasPermission(user, e.OrganizationID, PermissionManageSettings) {
 		SendForbidden(w)
 		return
 	}
@@ -223,6 +231,13 @@ func (router *AuthProviderRouter) delete(w http.ResponseWriter, r *http.Request)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntityAuthProvider,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, router.getAuditModel(e), nil)
 	SendUpdated(w)
 }
 
@@ -239,7 +254,7 @@ func (router *AuthProviderRouter) create(w http.ResponseWriter, r *http.Request)
 	}
 
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
 		SendForbidden(w)
 		return
 	}
@@ -272,6 +287,12 @@ func (router *AuthProviderRouter) create(w http.ResponseWriter, r *http.Request)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityAuthProvider,
+		EntityID:   e.ID,
+		EntityName: e.Name,
+	}, nil, router.getAuditModel(e))
 	SendCreated(w, e.ID)
 }
 
@@ -294,6 +315,16 @@ func (router *AuthProviderRouter) copyFromRestModel(m *CreateAuthProviderRequest
 	return e
 }
 
+// getAuditModel includes the encrypted client secret so that the audit log
+// records that it has been changed. Its value is redacted by the audit log.
+func (router *AuthProviderRouter) getAuditModel(e *AuthProvider) *GetAuthProviderResponse {
+	clientSecret := e.ClientSecret
+	m := router.copyToRestModel(e)
+	e.ClientSecret = clientSecret
+	m.ClientSecret = clientSecret
+	return m
+}
+
 func (router *AuthProviderRouter) copyToRestModel(e *AuthProvider) *GetAuthProviderResponse {
 	m := &GetAuthProviderResponse{}
 	m.ID = e.ID
@@ -106,6 +106,8 @@ type IdPUserInfo struct {
 	Email     string
 	Firstname string
 	Lastname  string
+	// All claims returned by the user info endpoint
+	Claims map[string]interface{}
 }
 
 type InitPasswordResetRequest struct {
@@ -122,6 +124,7 @@ type AuthPreflightResponse struct {
 	AuthProviders        []*GetAuthProviderPublicResponse `json:"authProviders"`
 	RequirePassword      bool                             `json:"requirePassword"`
 	DisablePasswordLogin bool                             `json:"disablePasswordLogin"`
+	MagicLinkLogin       bool                             `json:"magicLinkLogin"`
 	Domain               string                           `json:"domain"`
 }
 
@@ -169,9 +172,12 @@ func (router *AuthRouter) SetupRoutes(s *mux.Router) {
 	s.HandleFunc("/logout/{where}", router.logout).Methods("GET")
 	s.HandleFunc("/initpwreset", router.initPasswordReset).Methods("POST")
 	s.HandleFunc("/pwreset/{id}", router.completePasswordReset).Methods("POST")
+	s.HandleFunc("/magic-link", router.initMagicLinkLogin).Methods("POST")
+	s.HandleFunc("/magic-link/{id}", router.completeMagicLinkLogin).Methods("POST")
 	s.HandleFunc("/setpw/{id}", router.validateUserInvitation).Methods("GET")
 	s.HandleFunc("/setpw/{id}", router.completeUserInvitation).Methods("POST")
 	s.HandleFunc("/refresh", router.refreshAccessToken).Methods("POST")
+	s.HandleFunc("/token", router.issueOAuthToken).Methods("POST")
 	s.HandleFunc("/singleorg", router.singleOrg).Methods("GET")
 	s.HandleFunc("/org/{domain}", router.getOrgDetails).Methods("GET")
 }
@@ -272,6 +278,24 @@ func (router *AuthRouter) refreshAccessToken(w http.ResponseWriter, r *http.Requ
 		SendNotFound(w)
 		return
 	}
+	// Each refresh token can be used once. A second use indicates that the
+	// token has been stolen, so the whole token family and its session are revoked.
+	if refreshToken.Rotated != nil {
+		router.revokeRefreshTokenFamily(r, user, session, refreshToken)
+		SendUnauthorized(w)
+		return
+	}
+	rotated, err := GetRefreshTokenRepository().MarkRotated(refreshToken)
+	if err != nil {
+		log.Println("Error rotating refresh token: " + err.Error())
+		SendInternalServerError(w)
+		return
+	}
+	if !rotated {
+		router.revokeRefreshTokenFamily(r, user, session, refreshToken)
+		SendUnauthorized(w)
+		return
+	}
 	now := time.Now().UTC()
 	user.LastActivityAtUTC = &now
 	GetUserRepository().Update(user)
@@ -281,17 +305,25 @@ func (router *AuthRouter) refreshAccessToken(w http.ResponseWriter, r *http.Requ
 	}
 	claims := router.CreateClaims(user, session)
 	accessToken := router.CreateAccessToken(claims)
-	newRefreshToken := router.createRefreshToken(claims)
+	newRefreshToken := router.createRefreshTokenInFamily(claims, refreshToken.FamilyID)
 	res := &JWTResponse{
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
 	}
-	if err := GetRefreshTokenRepository().Delete(refreshToken); err != nil {
-		log.Println("Error deleting old refresh token: " + err.Error())
-	}
 	SendJSON(w, res)
 }
 
+func (router *AuthRouter) revokeRefreshTokenFamily(r *http.Request, user *User, session *Session, refreshToken *RefreshToken) {
+	log.Printf("Refresh token reuse detected for user %s, revoking session %s\n", user.ID, session.ID)
+	if err := GetRefreshTokenRepository().DeleteFamily(refreshToken.FamilyID); err != nil {
+		log.Println("Error revoking refresh token family: " + err.Error())
+	}
+	if err := GetSessionRepository().Delete(session); err != nil {
+		log.Println("Error revoking session: " + err.Error())
+	}
+	recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodRefreshToken, ErrorCode: AuthErrorRefreshTokenReuse})
+}
+
 func (router *AuthRouter) initPasswordReset(w http.ResponseWriter, r *http.Request) {
 	if GetConfig().DisablePasswordLogin {
 		SendNotFound(w)
@@ -350,11 +382,6 @@ func (router *AuthRouter) completePasswordReset(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if !ValidatePassword(m.Password) {
-		SendBadRequest(w)
-		return
-	}
-
 	vars := mux.Vars(r)
 	authState, err := GetAuthStateRepository().GetOne(vars["id"])
 	if err != nil {
@@ -382,7 +409,12 @@ func (router *AuthRouter) completePasswordReset(w http.ResponseWriter, r *http.R
 		SendNotFound(w)
 		return
 	}
-	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(m.Password))
+	if code := checkPasswordPolicy(user, m.Password); code != 0 {
+		SendBadRequestCode(w, code)
+		return
+	}
+	setUserPassword(user, m.Password)
+	user.PasswordUpdateRequired = false
 	GetUserRepository().Update(user)
 	GetAuthStateRepository().Delete(authState)
 	GetSessionRepository().DeleteOfUser(user)
@@ -437,11 +469,6 @@ func (router *AuthRouter) completeUserInvitation(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if !ValidatePassword(m.Password) {
-		SendBadRequest(w)
-		return
-	}
-
 	vars := mux.Vars(r)
 	authState, err := GetAuthStateRepository().GetOne(vars["id"])
 	if err != nil {
@@ -470,7 +497,11 @@ func (router *AuthRouter) completeUserInvitation(w http.ResponseWriter, r *http.
 		SendNotFound(w)
 		return
 	}
-	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(m.Password))
+	if code := checkPasswordPolicy(user, m.Password); code != 0 {
+		SendBadRequestCode(w, code)
+		return
+	}
+	setUserPassword(user, m.Password)
 	user.PasswordPending = false
 	user.PasswordUpdateRequired = false
 	user.AuthProviderID = NullUUID("")
@@ -567,6 +598,16 @@ func (router *AuthRouter) loginPassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !checkNetworkPolicy(w, r, user, AuthMethodPassword) {
+		return
+	}
+
+	// check if password has exceeded the maximum age of the org's password policy
+	if !user.PasswordUpdateRequired && isPasswordExpired(user) {
+		user.PasswordUpdateRequired = true
+		GetUserRepository().Update(user)
+	}
+
 	// check if password update is required
 	if user.PasswordUpdateRequired {
 		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodPassword, ErrorCode: AuthErrorPasswordUpdateReq})
@@ -594,36 +635,52 @@ func (router *AuthRouter) loginPassword(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		// Check for replay attack
-		if totpCache.isCodeUsed(user.ID, m.Code) {
-			recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodTOTP, ErrorCode: AuthErrorTotpReplay, BanCheck: true})
-			SendBadRequest(w)
+		if !router.verifyTotpCode(w, r, user, m.Code) {
 			return
 		}
+	}
+
+	// Logins from untrusted networks require a second factor
+	if method == AuthMethodPassword && isSecondFactorRequiredForNetwork(user, GetClientIP(r)) {
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodPassword, ErrorCode: AuthErrorSecondFactorRequired, ErrorDetail: "client IP " + GetClientIP(r)})
+		SendForbiddenCode(w, ResponseCodeSecondFactorRequired)
+		return
+	}
+
+	router.createAndSendJWT(w, r, user, method, "", "", "")
+}
 
-		totpSecret, err := DecryptString(string(user.TotpSecret))
+// verifyTotpCode validates a TOTP code of the user, including replay
+// protection. On failure, the error response has already been sent.
+func (router *AuthRouter) verifyTotpCode(w http.ResponseWriter, r *http.Request, user *User, code string) bool {
+	// Check for replay attack
+	if totpCache.isCodeUsed(user.ID, code) {
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodTOTP, ErrorCode: AuthErrorTotpReplay, BanCheck: true})
+		SendBadRequest(w)
+		return false
+	}
+
+	totpSecret, err := DecryptString(string(user.TotpSecret))
+	if err != nil {
+		log.Println("Error decrypting TOTP secret for user " + user.ID + ": " + err.Error())
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodTOTP, ErrorCode: AuthErrorInternal, ErrorDetail: "failed to decrypt TOTP secret: " + err.Error()})
+		SendInternalServerError(w)
+		return false
+	}
+	valid, err := totp.ValidateCustom(code, totpSecret, time.Now(), *TotpOptions)
+	if err != nil || !valid {
+		detail := ""
 		if err != nil {
-			log.Println("Error decrypting TOTP secret for user " + user.ID + ": " + err.Error())
-			recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodTOTP, ErrorCode: AuthErrorInternal, ErrorDetail: "failed to decrypt TOTP secret: " + err.Error()})
-			SendInternalServerError(w)
-			return
-		}
-		valid, err := totp.ValidateCustom(m.Code, totpSecret, time.Now(), *TotpOptions)
-		if err != nil || !valid {
-			detail := ""
-			if err != nil {
-				detail = err.Error()
-			}
-			recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodTOTP, ErrorCode: AuthErrorTotpInvalid, ErrorDetail: detail, BanCheck: true})
-			SendBadRequest(w)
-			return
+			detail = err.Error()
 		}
-
-		// Mark code as used to prevent replay
-		totpCache.markCodeAsUsed(user.ID, m.Code)
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodTOTP, ErrorCode: AuthErrorTotpInvalid, ErrorDetail: detail, BanCheck: true})
+		SendBadRequest(w)
+		return false
 	}
 
-	router.createAndSendJWT(w, r, user, method, "", "", "")
+	// Mark code as used to prevent replay
+	totpCache.markCodeAsUsed(user.ID, code)
+	return true
 }
 
 func (router *AuthRouter) updatePassword(w http.ResponseWriter, r *http.Request) {
@@ -642,11 +699,6 @@ func (router *AuthRouter) updatePassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if !ValidatePassword(m.Password) {
-		SendBadRequest(w)
-		return
-	}
-
 	user, err := GetUserRepository().GetByEmail(m.OrganizationID, m.Email)
 	if err == sql.ErrNoRows {
 		SendBadRequest(w)
@@ -668,7 +720,12 @@ func (router *AuthRouter) updatePassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(m.NewPassword))
+	if code := checkPasswordPolicy(user, m.NewPassword); code != 0 {
+		SendBadRequestCode(w, code)
+		return
+	}
+
+	setUserPassword(user, m.NewPassword)
 	user.PasswordUpdateRequired = false
 	GetUserRepository().Update(user)
 
@@ -867,6 +924,8 @@ func (router *AuthRouter) verify(w http.ResponseWriter, r *http.Request) {
 			}
 			if err := GetUserRepository().Create(user); err != nil {
 				recordAuthEvent(r, &AuthEvent{OrganizationID: provider.OrganizationID, Email: payload.UserID, AuthProviderID: provider.ID, Method: AuthMethodOAuth, ErrorCode: AuthErrorUserCreateFailed, ErrorDetail: err.Error()})
+			} else {
+				dispatchUserWebhook(WebhookEventUserCreated, user)
 			}
 		} else {
 			if user.OrganizationID != provider.OrganizationID {
@@ -1041,6 +1100,8 @@ func (router *AuthRouter) callback(w http.ResponseWriter, r *http.Request) {
 		}
 		if err := GetUserRepository().Create(user); err != nil {
 			recordAuthEvent(r, &AuthEvent{OrganizationID: provider.OrganizationID, Email: userInfo.Email, AuthProviderID: provider.ID, Method: AuthMethodOAuth, ErrorCode: AuthErrorUserCreateFailed, ErrorDetail: err.Error()})
+		} else {
+			dispatchUserWebhook(WebhookEventUserCreated, user)
 		}
 	}
 	needUserUpdate := false
@@ -1061,6 +1122,12 @@ func (router *AuthRouter) callback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if clientIP := GetClientIP(r); !isNetworkAllowedForUser(user, clientIP) {
+		recordAuthEvent(r, &AuthEvent{User: user, AuthProviderID: provider.ID, Method: AuthMethodOAuth, ErrorCode: AuthErrorNetworkNotAllowed, ErrorDetail: "client IP " + clientIP})
+		SendTemporaryRedirect(w, router.getRedirectFailedUrl(payload.LoginType, provider, "network"))
+		return
+	}
+
 	if userInfo.Firstname != "" && user.Firstname != userInfo.Firstname {
 		user.Firstname = userInfo.Firstname
 		needUserUpdate = true
@@ -1072,6 +1139,7 @@ func (router *AuthRouter) callback(w http.ResponseWriter, r *http.Request) {
 	if needUserUpdate {
 		GetUserRepository().Update(user)
 	}
+	router.syncUserAttributesFromClaims(user, userInfo.Claims)
 	payloadNew := &AuthStateLoginPayload{
 		UserID:    userInfo.Email,
 		LoginType: payload.LoginType,
@@ -1215,9 +1283,34 @@ func ExtractUserInfoFields(result map[string]interface{}, emailField, firstnameF
 		Email:     strings.TrimSpace(emailVal),
 		Firstname: firstname,
 		Lastname:  lastname,
+		Claims:    result,
 	}, nil
 }
 
+// syncUserAttributesFromClaims sets the values of all user attributes with a
+// claim name from the identity provider's claims. Attributes missing from the
+// claims are left unchanged.
+func (router *AuthRouter) syncUserAttributesFromClaims(user *User, claims map[string]interface{}) {
+	attributes, err := GetUserAttributeRepository().GetAll(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, attribute := range attributes {
+		if attribute.ClaimName == "" {
+			continue
+		}
+		claim, ok := claims[attribute.ClaimName]
+		if !ok || claim == nil {
+			continue
+		}
+		value := normalizeUserAttributeValue(attribute, fmt.Sprint(claim))
+		if err := GetUserAttributeRepository().SetValue(attribute.ID, user.ID, value); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
 func (router *AuthRouter) SendPasswordResetEmail(user *User, ID string, org *Organization) error {
 	domain, err := GetOrganizationRepository().GetPrimaryDomain(org)
 	if err != nil {
@@ -1306,13 +1399,15 @@ func (router *AuthRouter) CreateAccessToken(claims *Claims, options ...CreateAcc
 		claims.RegisteredClaims.IssuedAt = jwt.NewNumericDate(time.Now())
 	}
 	if !slices.Contains(options, WithoutExpiry) {
-		claims.RegisteredClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(15 * time.Minute))
+		claims.RegisteredClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(AccessTokenLifetime))
 	}
 	if !slices.Contains(options, WithoutNotBefore) {
 		claims.RegisteredClaims.NotBefore = jwt.NewNumericDate(time.Now())
 	}
+	kid, signingKey := GetSigningKeyRing().GetSigningKey()
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodRS512, claims)
-	jwtString, err := accessToken.SignedString(GetConfig().JwtPrivateKey)
+	accessToken.Header["kid"] = kid
+	jwtString, err := accessToken.SignedString(signingKey)
 	if err != nil {
 		log.Println(err)
 		return ""
@@ -1321,11 +1416,16 @@ func (router *AuthRouter) CreateAccessToken(claims *Claims, options ...CreateAcc
 }
 
 func (router *AuthRouter) createRefreshToken(claims *Claims) string {
+	return router.createRefreshTokenInFamily(claims, "")
+}
+
+func (router *AuthRouter) createRefreshTokenInFamily(claims *Claims, familyID string) string {
 	var expiry time.Time
 	expiry = time.Now().Add(60 * 24 * 28 * time.Minute)
 	refreshToken := &RefreshToken{
 		UserID:    claims.UserID,
 		SessionID: claims.SessionID,
+		FamilyID:  familyID,
 		Expiry:    expiry,
 		Created:   time.Now(),
 	}
@@ -1347,6 +1447,7 @@ func (router *AuthRouter) getPreflightResponseForOrg(org *Organization) *AuthPre
 		},
 		RequirePassword:      false,
 		DisablePasswordLogin: GetConfig().DisablePasswordLogin,
+		MagicLinkLogin:       router.isMagicLinkLoginEnabled(org.ID),
 		AuthProviders:        []*GetAuthProviderPublicResponse{},
 	}
 	domain, err := GetOrganizationRepository().GetPrimaryDomain(org)
@@ -1381,10 +1482,12 @@ func recordAuthEvent(r *http.Request, e *AuthEvent) {
 	if err := GetAuthAttemptRepository().RecordAuthEvent(e); err != nil {
 		log.Println("Error recording auth event: " + err.Error())
 	}
+	exportAuthEvent(r, e)
 }
 
 func (router *AuthRouter) createAndSendJWT(w http.ResponseWriter, r *http.Request, user *User, authMethod string, authProviderID string, logoutURL string, profilePageURL string) {
 	recordAuthEvent(r, &AuthEvent{User: user, Successful: true, Method: authMethod, AuthProviderID: authProviderID, BanCheck: true})
+	recordLogin(r, user)
 	now := time.Now().UTC()
 	user.LastActivityAtUTC = &now
 	GetUserRepository().Update(user)
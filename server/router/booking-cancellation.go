@@ -0,0 +1,240 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const maxCancellationReasonLength = 500
+
+type BulkCancelBookingsRequest struct {
+	Start      time.Time `json:"start" validate:"required"`
+	End        time.Time `json:"end" validate:"required"`
+	LocationID string    `json:"locationId" validate:"omitempty,uuid"`
+	SpaceID    string    `json:"spaceId" validate:"omitempty,uuid"`
+	UserID     string    `json:"userId" validate:"omitempty,uuid"`
+	Reason     string    `json:"reason" validate:"required,max=500"`
+}
+
+type BulkCancelBookingsResponse struct {
+	Count  int `json:"count"`
+	Failed int `json:"failed"`
+}
+
+type GetBookingCancellationResponse struct {
+	ID                string    `json:"id"`
+	BookingID         string    `json:"bookingId"`
+	UserID            string    `json:"userId"`
+	UserEmail         string    `json:"userEmail"`
+	SpaceID           string    `json:"spaceId"`
+	SpaceName         string    `json:"spaceName"`
+	LocationID        string    `json:"locationId"`
+	LocationName      string    `json:"locationName"`
+	Enter             time.Time `json:"enter"`
+	Leave             time.Time `json:"leave"`
+	Reason            string    `json:"reason"`
+	CancelledByUserID string    `json:"cancelledByUserId"`
+	CancelledByEmail  string    `json:"cancelledByEmail"`
+	Bulk              bool      `json:"bulk"`
+	Created           time.Time `json:"created"`
+}
+
+// getCancellationReason returns the optional reason passed when deleting a
+// booking. Returns false if the reason is too long.
+func getCancellationReason(r *http.Request) (string, bool) {
+	reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+	return reason, len(reason) <= maxCancellationReasonLength
+}
+
+// getCancellationReasonVars returns the template variables describing why a
+// booking has been cancelled.
+func getCancellationReasonVars(reason string) map[string]string {
+	return map[string]string{
+		"reason":    reason,
+		"hasReason": boolToMailVar(reason != ""),
+	}
+}
+
+// recordBookingCancellation stores the cancelled booking for reporting.
+func recordBookingCancellation(e *BookingDetails, reason string, cancelledBy *User, bulk bool) {
+	c := &BookingCancellation{
+		OrganizationID:    e.Space.Location.OrganizationID,
+		BookingID:         e.ID,
+		UserID:            e.UserID,
+		UserEmail:         e.UserEmail,
+		SpaceID:           e.SpaceID,
+		SpaceName:         e.Space.Name,
+		LocationID:        e.Space.LocationID,
+		LocationName:      e.Space.Location.Name,
+		Enter:             e.Enter,
+		Leave:             e.Leave,
+		Reason:            reason,
+		CancelledByUserID: cancelledBy.ID,
+		CancelledByEmail:  cancelledBy.Email,
+		Bulk:              bulk,
+		Created:           time.Now().UTC(),
+	}
+	if err := GetBookingCancellationRepository().Create(c); err != nil {
+		log.Println(err)
+	}
+}
+
+// bulkCancel cancels all bookings matching the filter with a single reason,
+// e.g. due to an emergency closure, and notifies the affected users. Bookings
+// which can't be deleted are skipped and reported as failed, so the caller can
+// retry them.
+func (router *BookingRouter) bulkCancel(w http.ResponseWriter, r *http.Request) {
+	var m BulkCancelBookingsRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	m.Reason = strings.TrimSpace(m.Reason)
+	if m.Reason == "" || !m.End.After(m.Start) {
+		SendBadRequest(w)
+		return
+	}
+	requestUser := GetRequestUser(r)
+	organizationID := requestUser.OrganizationID
+	if m.SpaceID != "" {
+		space, err := GetSpaceRepository().GetOne(m.SpaceID)
+		if err != nil {
+			SendBadRequest(w)
+			return
+		}
+		if m.LocationID != "" && m.LocationID != space.LocationID {
+			SendBadRequest(w)
+			return
+		}
+		m.LocationID = space.LocationID
+	}
+	if m.LocationID != "" {
+		location, err := GetLocationRepository().GetOne(m.LocationID)
+		if err != nil || location.OrganizationID != organizationID {
+			SendBadRequest(w)
+			return
+		}
+		if !HasLocationPermission(requestUser, organizationID, PermissionManageBookings, location.ID) {
+			SendForbidden(w)
+			return
+		}
+	} else if !HasPermission(requestUser, organizationID, PermissionManageBookings) {
+		// Users limited to specific locations must select one of them
+		SendForbidden(w)
+		return
+	}
+	if m.UserID != "" {
+		user, err := GetUserRepository().GetOne(m.UserID)
+		if err != nil || user.OrganizationID != organizationID {
+			SendBadRequest(w)
+			return
+		}
+	}
+	list, err := GetBookingRepository().GetAllOverlappingByOrg(organizationID, m.Start, m.End, m.LocationID, m.SpaceID, m.UserID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := &BulkCancelBookingsResponse{}
+	for _, e := range list {
+		if err := GetBookingRepository().Delete(e); err != nil {
+			log.Println(err)
+			res.Failed++
+			continue
+		}
+		res.Count++
+		recordBookingCancellation(e, m.Reason, requestUser, true)
+		recordAuditLog(r, &AuditLogEntry{
+			EntityType:     AuditEntityBooking,
+			Action:         AuditActionDelete,
+			EntityID:       e.ID,
+			EntityName:     e.Space.Name + " (" + e.UserEmail + ")",
+			OrganizationID: organizationID,
+		}, map[string]any{
+			"userEmail": e.UserEmail,
+			"spaceId":   e.SpaceID,
+			"enter":     e.Enter,
+			"leave":     e.Leave,
+		}, map[string]any{
+			"cancellationReason": m.Reason,
+		})
+		go func(e *BookingDetails) {
+			router.onBookingDeleted(&e.Booking, true, m.Reason)
+			router.sendDelegateMailNotificationWithVars(&e.Booking, BookingMailNotificationDeleted, string(e.CreatedByUserID), getCancellationReasonVars(m.Reason))
+			dispatchBookingWebhook(WebhookEventBookingDeleted, &e.Booking)
+		}(e)
+	}
+	SendJSON(w, res)
+}
+
+func (router *BookingRouter) getCancellationReport(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionViewReports) {
+		SendForbidden(w)
+		return
+	}
+	hideReports, _ := GetSettingsRepository().GetBool(user.OrganizationID, SettingHideReports.Name)
+	if hideReports {
+		SendNotFound(w)
+		return
+	}
+	start, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("start"))
+	if err != nil {
+		SendBadRequest(w)
+		return
+	}
+	end, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("end"))
+	if err != nil {
+		SendBadRequest(w)
+		return
+	}
+	locationID := r.URL.Query().Get("locationId")
+	if locationID != "" {
+		if !ValidateGUID(locationID) {
+			SendBadRequest(w)
+			return
+		}
+		if !HasLocationPermission(user, user.OrganizationID, PermissionViewReports, locationID) {
+			SendForbidden(w)
+			return
+		}
+	} else if !HasPermission(user, user.OrganizationID, PermissionViewReports) {
+		// Users limited to specific locations must select one of them
+		SendForbidden(w)
+		return
+	}
+	list, err := GetBookingCancellationRepository().GetAllByOrg(user.OrganizationID, start, end, locationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetBookingCancellationResponse{}
+	for _, e := range list {
+		res = append(res, &GetBookingCancellationResponse{
+			ID:                e.ID,
+			BookingID:         e.BookingID,
+			UserID:            e.UserID,
+			UserEmail:         e.UserEmail,
+			SpaceID:           e.SpaceID,
+			SpaceName:         e.SpaceName,
+			LocationID:        e.LocationID,
+			LocationName:      e.LocationName,
+			Enter:             e.Enter,
+			Leave:             e.Leave,
+			Reason:            e.Reason,
+			CancelledByUserID: e.CancelledByUserID,
+			CancelledByEmail:  e.CancelledByEmail,
+			Bulk:              e.Bulk,
+			Created:           e.Created,
+		})
+	}
+	SendJSON(w, res)
+}
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"math"
 	"net/http"
 	"net/url"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -59,6 +62,10 @@ type GetBookingResponse struct {
 	Approved      bool             `json:"approved"`
 	Space         GetSpaceResponse `json:"space"`
 	RecurringID   string           `json:"recurringId"`
+	// CreatedByUserID and CreatedByEmail identify who actually created the
+	// booking, which differs from the booking's user if a delegate booked.
+	CreatedByUserID string `json:"createdByUserId"`
+	CreatedByEmail  string `json:"createdByEmail"`
 	CreateBookingRequest
 }
 
@@ -72,6 +79,8 @@ type GetPresenceReportResult struct {
 	Users     []GetUserInfoSmall `json:"users"`
 	Dates     []string           `json:"dates"`
 	Presences [][]int            `json:"presences"`
+	// Values of the requested user attribute, in the order of users
+	AttributeValues []string `json:"attributeValues,omitempty"`
 }
 
 type GetPendingApprovalsCountResponse struct {
@@ -93,8 +102,11 @@ func (router *BookingRouter) SetupRoutes(s *mux.Router) {
 	s.HandleFunc("/pendingapprovals/count", router.getPendingApprovalsCount).Methods("GET")
 	s.HandleFunc("/pendingapprovals/", router.getPendingApprovals).Methods("GET")
 	s.HandleFunc("/report/presence/", router.getPresenceReport).Methods("GET")
+	s.HandleFunc("/report/cancellations/", router.getCancellationReport).Methods("GET")
+	s.HandleFunc("/cancel/", router.bulkCancel).Methods("POST")
 	s.HandleFunc("/filter/", router.getFiltered).Methods("GET")
 	s.HandleFunc("/current/", router.getCurrent).Methods("GET")
+	s.HandleFunc("/delegated/", router.getDelegated).Methods("GET")
 	s.HandleFunc("/precheck/", router.preBookingCreateCheck).Methods("POST")
 	s.HandleFunc("/{id}/approve", router.approveBooking).Methods("POST")
 	s.HandleFunc("/{id}/ical", router.getIcal).Methods("GET")
@@ -107,10 +119,6 @@ func (router *BookingRouter) SetupRoutes(s *mux.Router) {
 
 func (router *BookingRouter) approveBooking(w http.ResponseWriter, r *http.Request) {
 	requestUser := GetRequestUser(r)
-	if !CanSpaceAdminOrg(requestUser, requestUser.OrganizationID) {
-		SendForbidden(w)
-		return
-	}
 	vars := mux.Vars(r)
 	e, err := GetBookingRepository().GetOne(vars["id"])
 	if err != nil {
@@ -118,8 +126,11 @@ func (router *BookingRouter) approveBooking(w http.ResponseWriter, r *http.Reque
 		SendNotFound(w)
 		return
 	}
-
-	if !router.isValidApproverForSpace(requestUser.ID, e.SpaceID) {
+	if !HasLocationPermission(requestUser, e.Space.Location.OrganizationID, PermissionApproveBookings, e.Space.LocationID) {
+		SendForbidden(w)
+		return
+	}
+	if !router.isValidApproverForBooking(requestUser.ID, &e.Booking) {
 		SendForbidden(w)
 		return
 	}
@@ -138,33 +149,139 @@ func (router *BookingRouter) approveBooking(w http.ResponseWriter, r *http.Reque
 		SendUpdated(w)
 		return
 	}
-	if !m.Approved {
-		if err := GetBookingRepository().Delete(e); err != nil {
-			log.Println(err)
-			SendInternalServerError(w)
-			return
-		}
-		go router.onBookingDeclinedOrApproved(&e.Booking)
+	if err := router.setBookingApproval(r, e, m.Approved); err == errBookingApprovalCompleted {
 		SendUpdated(w)
 		return
-	}
-	e.Approved = true
-	if err := GetBookingRepository().Update(&e.Booking); err != nil {
+	} else if err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
-	go router.onBookingDeclinedOrApproved(&e.Booking)
 	SendUpdated(w)
 }
 
+// setBookingApproval approves or declines a booking on behalf of the request
+// user. Approval links sent to other approvers become invalid. If the space
+// has second level approvers, a first level approval requests their approval.
+func (router *BookingRouter) setBookingApproval(r *http.Request, e *BookingDetails, approved bool) error {
+	auditEntry := &AuditLogEntry{
+		EntityType:     AuditEntityBooking,
+		EntityID:       e.ID,
+		EntityName:     e.Space.Name + " (" + e.UserEmail + ")",
+		OrganizationID: e.Space.Location.OrganizationID,
+	}
+	before := router.getApprovalAuditModel(e)
+	if approved && router.requiresNextApprovalLevel(&e.Booking) {
+		approverID := GetRequestUserID(r)
+		changed, err := GetBookingRepository().SetApprovalLevel(e.ID, ApprovalLevelSecond, approverID)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return errBookingApprovalCompleted
+		}
+		e.ApprovalLevel = ApprovalLevelSecond
+		e.ApprovalFirstApproverID = NullUUID(approverID)
+		auditEntry.Action = AuditActionApprove
+		recordAuditLog(r, auditEntry, before, router.getApprovalAuditModel(e))
+		invalidateBookingApprovalLinks(e.ID)
+		go func() {
+			router.sendApprovalRequestNotifications(&e.Booking)
+			router.sendApprovalRequestChatNotification(&e.Booking)
+		}()
+		return nil
+	}
+	if err := router.completeBookingApproval(e, approved); err != nil {
+		return err
+	}
+	if approved {
+		auditEntry.Action = AuditActionApprove
+		recordAuditLog(r, auditEntry, before, router.getApprovalAuditModel(e))
+	} else {
+		auditEntry.Action = AuditActionDecline
+		recordAuditLog(r, auditEntry, before, nil)
+	}
+	return nil
+}
+
+// errBookingApprovalCompleted is returned if a booking has been approved,
+// declined or moved to the next approval level concurrently, e.g. using an
+// approval link.
+var errBookingApprovalCompleted = errors.New("booking has already been approved or declined")
+
+// completeBookingApproval finally approves or declines a pending booking and
+// informs the user. The booking is only changed if it is still pending, so
+// the user is informed once if approvers act at the same time.
+func (router *BookingRouter) completeBookingApproval(e *BookingDetails, approved bool) error {
+	var changed bool
+	var err error
+	if !approved {
+		changed, err = GetBookingRepository().DeletePending(e)
+	} else {
+		changed, err = GetBookingRepository().SetApproved(e.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return errBookingApprovalCompleted
+	}
+	e.Approved = approved
+	invalidateBookingApprovalLinks(e.ID)
+	go router.onBookingDeclinedOrApproved(&e.Booking)
+	return nil
+}
+
+// requiresNextApprovalLevel returns true if a first level approval of the
+// booking must be followed by a second level approval.
+func (router *BookingRouter) requiresNextApprovalLevel(e *Booking) bool {
+	if getBookingApprovalLevel(e) != ApprovalLevelFirst {
+		return false
+	}
+	approverGroups, err := GetSpaceRepository().GetApproverGroupIDsForLevel(e.SpaceID, ApprovalLevelSecond)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return len(approverGroups) > 0
+}
+
+// getBookingApprovalLevel returns the approval level the booking is waiting
+// for. Bookings which have not been loaded from the database yet wait for the
+// first level.
+func getBookingApprovalLevel(e *Booking) int {
+	if e.ApprovalLevel < ApprovalLevelFirst {
+		return ApprovalLevelFirst
+	}
+	return e.ApprovalLevel
+}
+
+func (router *BookingRouter) getApprovalAuditModel(e *BookingDetails) map[string]any {
+	return map[string]any{
+		"userEmail":     e.UserEmail,
+		"spaceId":       e.SpaceID,
+		"enter":         e.Enter,
+		"leave":         e.Leave,
+		"approved":      e.Approved,
+		"approvalLevel": getBookingApprovalLevel(&e.Booking),
+	}
+}
+
 func (router *BookingRouter) getPendingApprovalsCount(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionApproveBookings) {
 		SendForbidden(w)
 		return
 	}
-	count, err := GetBookingRepository().GetBookingsCountRequiringApproval(user.ID)
+	var count int
+	var err error
+	if all, _ := GetPermittedLocationIDs(user, user.OrganizationID, PermissionApproveBookings); all {
+		count, err = GetBookingRepository().GetBookingsCountRequiringApproval(user.ID)
+	} else {
+		var list []*BookingDetails
+		list, err = router.getApprovableBookings(user)
+		count = len(list)
+	}
 	if err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
@@ -176,13 +293,33 @@ func (router *BookingRouter) getPendingApprovalsCount(w http.ResponseWriter, r *
 	SendJSON(w, res)
 }
 
+// getApprovableBookings returns the bookings awaiting approval by the user,
+// limited to the locations the user may approve bookings for.
+func (router *BookingRouter) getApprovableBookings(user *User) ([]*BookingDetails, error) {
+	list, err := GetBookingRepository().GetBookingsRequiringApproval(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	all, locationIDs := GetPermittedLocationIDs(user, user.OrganizationID, PermissionApproveBookings)
+	if all {
+		return list, nil
+	}
+	res := []*BookingDetails{}
+	for _, e := range list {
+		if slices.Contains(locationIDs, e.Space.LocationID) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
 func (router *BookingRouter) getPendingApprovals(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionApproveBookings) {
 		SendForbidden(w)
 		return
 	}
-	list, err := GetBookingRepository().GetBookingsRequiringApproval(user.ID)
+	list, err := router.getApprovableBookings(user)
 	if err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
@@ -198,7 +335,7 @@ func (router *BookingRouter) getPendingApprovals(w http.ResponseWriter, r *http.
 
 func (router *BookingRouter) validateBookingFilters(w http.ResponseWriter, r *http.Request) (*User, string, string, bool) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageBookings) {
 		SendForbidden(w)
 		return nil, "", "", false
 	}
@@ -300,7 +437,7 @@ func (router *BookingRouter) getIcal(w http.ResponseWriter, r *http.Request) {
 		SendForbidden(w)
 		return
 	}
-	if e.UserID != GetRequestUserID(r) && !CanSpaceAdminOrg(requestUser, location.OrganizationID) {
+	if e.UserID != GetRequestUserID(r) && !HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) && !isDelegateOf(requestUser, e.UserID) {
 		SendForbidden(w)
 		return
 	}
@@ -352,7 +489,7 @@ func (router *BookingRouter) getOne(w http.ResponseWriter, r *http.Request) {
 		SendForbidden(w)
 		return
 	}
-	if e.UserID != GetRequestUserID(r) && !CanSpaceAdminOrg(requestUser, location.OrganizationID) {
+	if e.UserID != GetRequestUserID(r) && !HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) && !isDelegateOf(requestUser, e.UserID) {
 		SendForbidden(w)
 		return
 	}
@@ -361,34 +498,65 @@ func (router *BookingRouter) getOne(w http.ResponseWriter, r *http.Request) {
 }
 
 func (router *BookingRouter) getAll(w http.ResponseWriter, r *http.Request) {
-	startTime := time.Now().UTC().Add(time.Hour * -12)
-	list, err := GetBookingRepository().GetAllByUser(GetRequestUserID(r), startTime)
+	res, err := router.getUpcoming(GetRequestUser(r), []string{GetRequestUserID(r)})
 	if err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
-	user := GetRequestUser(r)
+	SendJSON(w, res)
+}
+
+// getDelegated returns the upcoming bookings of all users who have delegated
+// their bookings to the request user.
+func (router *BookingRouter) getDelegated(w http.ResponseWriter, r *http.Request) {
+	principals, err := GetDelegationRepository().GetPrincipals(GetRequestUserID(r))
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	userIDs := []string{}
+	for _, principal := range principals {
+		userIDs = append(userIDs, principal.ID)
+	}
+	res, err := router.getUpcoming(GetRequestUser(r), userIDs)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendJSON(w, res)
+}
+
+func (router *BookingRouter) getUpcoming(user *User, userIDs []string) ([]*GetBookingResponse, error) {
+	startTime := time.Now().UTC().Add(time.Hour * -12)
 	defaultTz, err := GetSettingsRepository().Get(user.OrganizationID, SettingDefaultTimezone.Name)
 	if err != nil {
 		defaultTz = "UTC"
 	}
 	nowAtOrg, _ := GetUTCNowInTimezone(defaultTz)
 	res := []*GetBookingResponse{}
-	for _, e := range list {
-		var nowAtLocation time.Time
-		if e.Space.Location.Timezone == "" {
-			nowAtLocation = nowAtOrg
-		} else {
-			nowAtLocation, _ = GetUTCNowInTimezone(e.Space.Location.Timezone)
+	for _, userID := range userIDs {
+		list, err := GetBookingRepository().GetAllByUser(userID, startTime)
+		if err != nil {
+			return nil, err
 		}
-		includeEntity := e.Leave.After(nowAtLocation)
-		if includeEntity {
-			m := router.copyToRestModel(e)
-			res = append(res, m)
+		for _, e := range list {
+			var nowAtLocation time.Time
+			if e.Space.Location.Timezone == "" {
+				nowAtLocation = nowAtOrg
+			} else {
+				nowAtLocation, _ = GetUTCNowInTimezone(e.Space.Location.Timezone)
+			}
+			includeEntity := e.Leave.After(nowAtLocation)
+			if includeEntity {
+				m := router.copyToRestModel(e)
+				res = append(res, m)
+			}
 		}
 	}
-	SendJSON(w, res)
+	return res, nil
 }
 
 func (router *BookingRouter) update(w http.ResponseWriter, r *http.Request) {
@@ -415,7 +583,9 @@ func (router *BookingRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	requestUser := GetRequestUser(r)
-	if e.UserID != requestUser.ID && !CanSpaceAdminOrg(requestUser, location.OrganizationID) {
+	isSpaceAdmin := HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) &&
+		HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, e.Space.LocationID)
+	if e.UserID != requestUser.ID && !isSpaceAdmin && !isDelegateOf(requestUser, e.UserID) {
 		SendForbidden(w)
 		return
 	}
@@ -428,19 +598,33 @@ func (router *BookingRouter) update(w http.ResponseWriter, r *http.Request) {
 	eNew.CalDavID = e.CalDavID
 	eNew.UserID = e.UserID
 	eNew.Approved = e.Approved
-	if m.UserEmail != "" {
-		if !CanSpaceAdminOrg(requestUser, location.OrganizationID) {
-			SendForbidden(w)
-			return
-		}
+	if m.UserEmail != "" && m.UserEmail != e.UserEmail {
 		if m.UserEmail == requestUser.Email {
+			if !isSpaceAdmin {
+				SendForbidden(w)
+				return
+			}
 			eNew.UserID = requestUser.ID
-		} else {
-			eNew.UserID, err = router.bookForUser(requestUser, m.UserEmail, w)
+		} else if isSpaceAdmin {
+			eNew.UserID, err = router.bookForUser(requestUser, m.UserEmail, location, w)
 			if err != nil {
 				SendInternalServerError(w)
 				return
 			}
+		} else if principal := getDelegationPrincipal(requestUser, m.UserEmail); principal != nil {
+			eNew.UserID = principal.ID
+		} else {
+			SendForbidden(w)
+			return
+		}
+	}
+	// Delegates book within the limits of the user they book for
+	bookingUser := requestUser
+	if eNew.UserID != requestUser.ID && !isSpaceAdmin {
+		bookingUser, err = GetUserRepository().GetOne(eNew.UserID)
+		if err != nil {
+			SendInternalServerError(w)
+			return
 		}
 	}
 	bookingReq := &CreateBookingRequest{
@@ -452,7 +636,7 @@ func (router *BookingRouter) update(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	if valid, code := router.checkBookingCreateUpdate(bookingReq, location, requestUser, eNew.ID, 0); !valid {
+	if valid, code := router.checkBookingCreateUpdate(bookingReq, location, bookingUser, eNew.ID, 0); !valid {
 		SendBadRequestCode(w, code)
 		return
 	}
@@ -472,6 +656,7 @@ func (router *BookingRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	go router.onBookingUpdated(eNew)
+	go router.sendDelegateMailNotification(eNew, BookingMailNotificationUpdated, requestUser.ID)
 	SendUpdated(w)
 }
 
@@ -496,11 +681,16 @@ func (router *BookingRouter) delete(w http.ResponseWriter, r *http.Request) {
 		SendForbidden(w)
 		return
 	}
-	if (e.UserID != GetRequestUserID(r)) && !CanSpaceAdminOrg(GetRequestUser(r), location.OrganizationID) {
+	requestUser := GetRequestUser(r)
+	if (e.UserID != requestUser.ID) && !HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) && !isDelegateOf(requestUser, e.UserID) {
 		SendForbidden(w)
 		return
 	}
-	requestUser := GetRequestUser(r)
+	reason, ok := getCancellationReason(r)
+	if !ok {
+		SendBadRequest(w)
+		return
+	}
 
 	// leave must not be in past
 	now := time.Now().UTC()
@@ -512,11 +702,14 @@ func (router *BookingRouter) delete(w http.ResponseWriter, r *http.Request) {
 
 	// Check for the date, if the booking request is too close with SettingsMaxHoursBeforeDelete and the deletion can not be performed
 	if router.IsValidBookingHoursBeforeDelete(e, requestUser, location.OrganizationID) {
-		go router.onBookingDeleted(&e.Booking, true)
+		go router.onBookingDeleted(&e.Booking, true, reason)
+		go router.sendDelegateMailNotificationWithVars(&e.Booking, BookingMailNotificationDeleted, requestUser.ID, getCancellationReasonVars(reason))
+		go dispatchBookingWebhook(WebhookEventBookingDeleted, &e.Booking)
 		if err := GetBookingRepository().Delete(e); err != nil {
 			SendInternalServerError(w)
 			return
 		}
+		recordBookingCancellation(e, reason, requestUser, false)
 		SendUpdated(w)
 		return
 	}
@@ -615,7 +808,7 @@ func (router *BookingRouter) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// test if location and space is enabled or user is space admin
-	if (!location.Enabled || !space.Enabled) && !CanSpaceAdminOrg(requestUser, location.OrganizationID) {
+	if (!location.Enabled || !space.Enabled) && !HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) {
 		SendBadRequest(w)
 		return
 	}
@@ -626,16 +819,23 @@ func (router *BookingRouter) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	e.UserID = GetRequestUserID(r)
+	e.CreatedByUserID = NullUUID(requestUser.ID)
+	bookingUser := requestUser
 	if m.UserEmail != "" && m.UserEmail != requestUser.Email {
-		if !CanSpaceAdminOrg(requestUser, location.OrganizationID) {
+		if HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) {
+			e.UserID, err = router.bookForUser(requestUser, m.UserEmail, location, w)
+			if err != nil {
+				SendInternalServerError(w)
+				return
+			}
+		} else if principal := getDelegationPrincipal(requestUser, m.UserEmail); principal != nil {
+			// Delegates book within the limits of the user they book for
+			e.UserID = principal.ID
+			bookingUser = principal
+		} else {
 			SendForbidden(w)
 			return
 		}
-		e.UserID, err = router.bookForUser(requestUser, m.UserEmail, w)
-		if err != nil {
-			SendInternalServerError(w)
-			return
-		}
 	}
 	bookingReq := &CreateBookingRequest{
 		SpaceID: m.SpaceID,
@@ -645,7 +845,7 @@ func (router *BookingRouter) create(w http.ResponseWriter, r *http.Request) {
 			Leave: e.Leave,
 		},
 	}
-	valid, code := router.checkBookingCreateUpdate(bookingReq, location, requestUser, "", 0)
+	valid, code := router.checkBookingCreateUpdate(bookingReq, location, bookingUser, "", 0)
 	if !valid {
 		SendBadRequestCode(w, code)
 		return
@@ -668,11 +868,12 @@ func (router *BookingRouter) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	go router.onBookingCreated(e)
+	go router.sendDelegateMailNotification(e, BookingMailNotificationCreated, requestUser.ID)
 	SendCreated(w, e.ID)
 }
 
-func (router *BookingRouter) bookForUser(requestUser *User, userEmail string, w http.ResponseWriter) (string, error) {
-	if !CanSpaceAdminOrg(requestUser, requestUser.OrganizationID) {
+func (router *BookingRouter) bookForUser(requestUser *User, userEmail string, location *Location, w http.ResponseWriter) (string, error) {
+	if !HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) {
 		SendForbidden(w)
 		return "", errors.New("Forbidden")
 	}
@@ -706,6 +907,7 @@ func (router *BookingRouter) bookForUser(requestUser *User, userEmail string, w
 			SendInternalServerError(w)
 			return "", errors.New("InternalServerError")
 		}
+		dispatchUserWebhook(WebhookEventUserCreated, user)
 		bookForUser, err = GetUserRepository().GetByEmail(org.ID, userEmail)
 		if err != nil {
 			SendInternalServerError(w)
@@ -722,9 +924,18 @@ func (router *BookingRouter) bookForUser(requestUser *User, userEmail string, w
 
 func (router *BookingRouter) getPresenceReport(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
-		SendForbidden(w)
-		return
+	// Managers without report permissions may view the presence of their reports
+	var userIDs []string = nil
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionViewReports) {
+		reportIDs, err := GetUserAttributeRepository().GetReportIDs(user)
+		if err != nil {
+			log.Println(err)
+		}
+		if len(reportIDs) == 0 {
+			SendForbidden(w)
+			return
+		}
+		userIDs = reportIDs
 	}
 	hideReports, _ := GetSettingsRepository().GetBool(user.OrganizationID, SettingHideReports.Name)
 	if hideReports {
@@ -762,12 +973,42 @@ func (router *BookingRouter) getPresenceReport(w http.ResponseWriter, r *http.Re
 			SendBadRequest(w)
 			return
 		}
-		if !GetUserRepository().IsSuperAdmin(user) && location.OrganizationID != user.OrganizationID {
+		if userIDs == nil && !HasLocationPermission(user, location.OrganizationID, PermissionViewReports, location.ID) {
 			SendForbidden(w)
 			return
 		}
+	} else if userIDs == nil && !HasPermission(user, user.OrganizationID, PermissionViewReports) {
+		// Users limited to specific locations must select one of them
+		SendForbidden(w)
+		return
+	}
+	var attribute *UserAttribute = nil
+	if attributeID := r.URL.Query().Get("attributeId"); attributeID != "" {
+		if !ValidateGUID(attributeID) {
+			SendBadRequest(w)
+			return
+		}
+		attribute, _ = GetUserAttributeRepository().GetOne(attributeID)
+		if attribute == nil || attribute.OrganizationID != user.OrganizationID {
+			SendBadRequest(w)
+			return
+		}
+		if r.URL.Query().Has("attributeValue") {
+			matchingIDs, err := GetUserAttributeRepository().GetUserIDsByValue(attribute.ID, r.URL.Query().Get("attributeValue"))
+			if err != nil {
+				log.Println(err)
+				SendInternalServerError(w)
+				return
+			}
+			if userIDs != nil {
+				matchingIDs = slices.DeleteFunc(matchingIDs, func(id string) bool {
+					return !slices.Contains(userIDs, id)
+				})
+			}
+			userIDs = matchingIDs
+		}
 	}
-	items, err := GetBookingRepository().GetPresenceReport(user.OrganizationID, location, start, end, 1000, 0)
+	items, err := GetBookingRepository().GetPresenceReport(user.OrganizationID, location, userIDs, start, end, 1000, 0)
 	if err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
@@ -783,12 +1024,17 @@ func (router *BookingRouter) getPresenceReport(w http.ResponseWriter, r *http.Re
 		Dates:     make([]string, numDates),
 		Presences: make([][]int, numUsers),
 	}
-	i := 0
-	for date := range items[0].Presence {
-		res.Dates[i] = date
-		i++
+	if numUsers > 0 {
+		i := 0
+		for date := range items[0].Presence {
+			res.Dates[i] = date
+			i++
+		}
 	}
 	sort.Strings(res.Dates)
+	if attribute != nil {
+		res.AttributeValues = router.getPresenceReportAttributeValues(attribute, items)
+	}
 	for i, item := range items {
 		res.Users[i] = GetUserInfoSmall{
 			UserID:    item.User.ID,
@@ -804,9 +1050,32 @@ func (router *BookingRouter) getPresenceReport(w http.ResponseWriter, r *http.Re
 	SendJSON(w, res)
 }
 
+// getPresenceReportAttributeValues returns the users' values of the attribute
+// in the order of the report items.
+func (router *BookingRouter) getPresenceReportAttributeValues(attribute *UserAttribute, items []*BookingPresenceItem) []string {
+	res := make([]string, len(items))
+	userIDs := make([]string, len(items))
+	for i, item := range items {
+		userIDs[i] = item.User.ID
+	}
+	values, err := GetUserAttributeRepository().GetValuesForUserList(userIDs)
+	if err != nil {
+		log.Println(err)
+		return res
+	}
+	for i, item := range items {
+		for _, value := range values {
+			if value.AttributeID == attribute.ID && value.UserID == item.User.ID {
+				res[i] = value.Value
+			}
+		}
+	}
+	return res
+}
+
 func (router *BookingRouter) IsValidBookingDuration(m *BookingRequest, orgID string, user *User) bool {
 	noAdminRestrictions, _ := GetSettingsRepository().GetBool(orgID, SettingNoAdminRestrictions.Name)
-	if noAdminRestrictions && CanSpaceAdminOrg(user, orgID) {
+	if noAdminRestrictions && HasPermission(user, orgID, PermissionManageBookings) {
 		return true
 	}
 	dailyBasisBooking, _ := GetSettingsRepository().GetBool(orgID, SettingDailyBasisBooking.Name)
@@ -857,7 +1126,7 @@ func (router *BookingRouter) IsValidBookingAdvance(m *BookingRequest, orgID stri
 		return false, ResponseCodeBookingInPast
 	}
 	advanceDays := math.Floor(m.Enter.Sub(now).Hours() / 24)
-	if advanceDays >= 0 && noAdminRestrictions && CanSpaceAdminOrg(user, orgID) {
+	if advanceDays >= 0 && noAdminRestrictions && HasPermission(user, orgID, PermissionManageBookings) {
 		return true, 0
 	}
 
@@ -872,7 +1141,7 @@ func (router *BookingRouter) IsValidBookingAdvance(m *BookingRequest, orgID stri
 
 func (router *BookingRouter) IsValidMaxUpcomingBookings(orgID string, user *User, upcomingBookingsMarkup int) bool {
 	noAdminRestrictions, _ := GetSettingsRepository().GetBool(orgID, SettingNoAdminRestrictions.Name)
-	if noAdminRestrictions && CanSpaceAdminOrg(user, orgID) {
+	if noAdminRestrictions && HasPermission(user, orgID, PermissionManageBookings) {
 		return true
 	}
 	maxUpcoming, _ := GetSettingsRepository().GetInt(orgID, SettingMaxBookingsPerUser.Name)
@@ -882,7 +1151,7 @@ func (router *BookingRouter) IsValidMaxUpcomingBookings(orgID string, user *User
 
 func (router *BookingRouter) isValidMaxConcurrentBookingsForUser(orgID string, user *User, m *BookingRequest, bookingID string) bool {
 	noAdminRestrictions, _ := GetSettingsRepository().GetBool(orgID, SettingNoAdminRestrictions.Name)
-	if noAdminRestrictions && CanSpaceAdminOrg(user, orgID) {
+	if noAdminRestrictions && HasPermission(user, orgID, PermissionManageBookings) {
 		return true
 	}
 	maxConcurrent, _ := GetSettingsRepository().GetInt(orgID, SettingMaxConcurrentBookingsPerUser.Name)
@@ -939,7 +1208,8 @@ func (router *BookingRouter) isValidBookingRequest(m *CreateBookingRequest, loca
 		}
 	}
 	allowedLocationBookers, _ := GetLocationRepository().GetAllAllowedBookersForLocation(location.ID)
-	if len(allowedLocationBookers) > 0 {
+	allowedLocationAttributes, _ := GetLocationRepository().GetAllowedAttributesForLocation(location.ID)
+	if len(allowedLocationBookers) > 0 || len(allowedLocationAttributes) > 0 {
 		allowed := false
 		for _, allowedBooker := range allowedLocationBookers {
 			for _, group := range groupMemberships {
@@ -949,6 +1219,10 @@ func (router *BookingRouter) isValidBookingRequest(m *CreateBookingRequest, loca
 				}
 			}
 		}
+		if !allowed && len(allowedLocationAttributes) > 0 {
+			userAttributeValues, _ := GetUserAttributeRepository().GetValuesForUser(user.ID)
+			allowed = matchesLocationAttributeRules(allowedLocationAttributes, userAttributeValues)
+		}
 		if !allowed {
 			return false, ResponseCodeBookingNotAllowedBooker
 		}
@@ -979,7 +1253,7 @@ func (router *BookingRouter) IsValidBookingHoursBeforeDelete(e *BookingDetails,
 		log.Println(err)
 		return false
 	}
-	if noAdminRestrictions && CanSpaceAdminOrg(user, organizationID) {
+	if noAdminRestrictions && HasPermission(user, organizationID, PermissionManageBookings) {
 		return true
 	}
 
@@ -1020,7 +1294,7 @@ func (router *BookingRouter) IsValidBookingHoursBeforeDelete(e *BookingDetails,
 
 func (router *BookingRouter) isValidMinHoursBooking(e *BookingRequest, organizationID string, user *User) bool {
 	noAdminRestrictions, _ := GetSettingsRepository().GetBool(organizationID, SettingNoAdminRestrictions.Name)
-	if noAdminRestrictions && CanSpaceAdminOrg(user, organizationID) {
+	if noAdminRestrictions && HasPermission(user, organizationID, PermissionManageBookings) {
 		return true
 	}
 	min_hours, err := GetSettingsRepository().GetInt(organizationID, SettingMinBookingDurationHours.Name)
@@ -1154,25 +1428,34 @@ func (router *BookingRouter) updateCalDavEvent(e *Booking) {
 	GetBookingRepository().Update(e)
 }
 
-func (router *BookingRouter) isValidApproverForSpace(userID, spaceID string) bool {
-	approverGroups, err := GetSpaceRepository().GetApproverGroupIDs(spaceID)
+// isValidApproverForBooking returns true if the user is member of an approver
+// group of the approval level the booking is waiting for, or of the group the
+// approval has been escalated to. The user who approved the first level may
+// not approve the second level as well.
+func (router *BookingRouter) isValidApproverForBooking(userID string, e *Booking) bool {
+	level := getBookingApprovalLevel(e)
+	if level == ApprovalLevelSecond && string(e.ApprovalFirstApproverID) == userID {
+		return false
+	}
+	approverGroups, err := GetSpaceRepository().GetApproverGroupIDsForLevel(e.SpaceID, level)
 	if err != nil {
 		log.Println(err)
 		return false
 	}
-	if len(approverGroups) == 0 {
+	if len(approverGroups) == 0 && level == ApprovalLevelFirst {
 		return true
 	}
+	if e.ApprovalEscalationGroupID != "" {
+		approverGroups = append(approverGroups, string(e.ApprovalEscalationGroupID))
+	}
 	userGroups, err := GetGroupRepository().GetAllWhereUserIsMember(userID)
 	if err != nil {
 		log.Println(err)
 		return false
 	}
 	for _, group := range userGroups {
-		for _, approverGroup := range approverGroups {
-			if group.ID == approverGroup {
-				return true
-			}
+		if slices.Contains(approverGroups, group.ID) {
+			return true
 		}
 	}
 	return false
@@ -1188,6 +1471,12 @@ func (router *BookingRouter) getSpaceRequiresApproval(orgID string, e *Space) bo
 }
 
 func (router *BookingRouter) sendMailNotification(e *Booking, notification BookingMailNotification) {
+	router.sendMailNotificationWithVars(e, notification, nil)
+}
+
+// sendMailNotificationWithVars notifies the booking's user, passing additional
+// template variables such as the cancellation reason.
+func (router *BookingRouter) sendMailNotificationWithVars(e *Booking, notification BookingMailNotification, extraVars map[string]string) {
 	active, err := GetUserPreferencesRepository().GetBool(e.UserID, PreferenceMailNotifications.Name)
 	if err != nil || !active {
 		return
@@ -1202,16 +1491,12 @@ func (router *BookingRouter) sendMailNotification(e *Booking, notification Booki
 		log.Println(err)
 		return
 	}
-	space, err := GetSpaceRepository().GetOne(e.SpaceID)
-	if err != nil {
-		log.Println(err)
-		return
-	}
-	location, err := GetLocationRepository().GetOne(space.LocationID)
+	vars, err := router.getBookingNotificationVars(e, user, org)
 	if err != nil {
 		log.Println(err)
 		return
 	}
+	maps.Copy(vars, extraVars)
 	attachments := []*MailAttachment{}
 	if notification == BookingMailNotificationCreated || notification == BookingMailNotificationUpdated || notification == BookingMailNotificationApproved {
 		calDavEvent, err := router.getCalDavEventFromBooking(e)
@@ -1233,43 +1518,142 @@ func (router *BookingRouter) sendMailNotification(e *Booking, notification Booki
 		})
 	}
 
+	template := router.getBookingNotificationTemplate(notification)
+	if err := SendEmailWithAttachmentsAndOrg(&MailAddress{Address: user.Email}, template, getUserMailLanguage(user, org), vars, attachments, org.ID); err != nil {
+		log.Println(err)
+		return
+	}
+	now := time.Now().UTC()
+	if err := GetBookingRepository().UpdateLastInfoMailSentAt(e.ID, &now); err != nil {
+		log.Println(err)
+	}
+}
+
+// getBookingNotificationVars returns the template variables describing a
+// booking to its user.
+func (router *BookingRouter) getBookingNotificationVars(e *Booking, user *User, org *Organization) (map[string]string, error) {
+	space, err := GetSpaceRepository().GetOne(e.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := GetLocationRepository().GetOne(space.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := GetOrganizationRepository().GetPrimaryDomain(org)
+	if err != nil {
+		return nil, err
+	}
 	subject := e.Subject
 	if subject == "" {
 		subject = "—"
 	}
+	return map[string]string{
+		"orgDomain":     FormatURL(domain.DomainName) + "/",
+		"recipientName": user.GetSafeRecipientName(),
+		"date":          e.Enter.Format("2006-01-02 15:04") + " - " + e.Leave.Format("2006-01-02 15:04"),
+		"areaName":      location.Name,
+		"spaceName":     space.Name,
+		"subject":       subject,
+	}, nil
+}
+
+func (router *BookingRouter) getBookingNotificationTemplate(notification BookingMailNotification) string {
+	switch notification {
+	case BookingMailNotificationUpdated:
+		return GetEmailTemplatePathBookingUpdated()
+	case BookingMailNotificationDeclined:
+		return GetEmailTemplatePathBookingDeclined()
+	case BookingMailNotificationApproved:
+		return GetEmailTemplatePathBookingApproved()
+	case BookingMailNotificationDeleted:
+		return GetEmailTemplatePathBookingDeleted()
+	}
+	return GetEmailTemplatePathBookingCreated()
+}
+
+// sendDelegateMailNotification informs a delegate about a booking they manage
+// on behalf of its user. Nothing is sent if the specified user is not an
+// active delegate of the booking's user.
+func (router *BookingRouter) sendDelegateMailNotification(e *Booking, notification BookingMailNotification, delegateID string) {
+	router.sendDelegateMailNotificationWithVars(e, notification, delegateID, nil)
+}
+
+func (router *BookingRouter) sendDelegateMailNotificationWithVars(e *Booking, notification BookingMailNotification, delegateID string, extraVars map[string]string) {
+	if delegateID == "" || delegateID == e.UserID {
+		return
+	}
+	if ok, err := GetDelegationRepository().IsDelegate(e.UserID, delegateID); err != nil || !ok {
+		return
+	}
+	active, err := GetUserPreferencesRepository().GetBool(delegateID, PreferenceMailNotifications.Name)
+	if err != nil || !active {
+		return
+	}
+	delegate, err := GetUserRepository().GetOne(delegateID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	principal, err := GetUserRepository().GetOne(e.UserID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(delegate.OrganizationID)
+	if err != nil || org == nil {
+		log.Println(err)
+		return
+	}
+	space, err := GetSpaceRepository().GetOne(e.SpaceID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	location, err := GetLocationRepository().GetOne(space.LocationID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
 	domain, err := GetOrganizationRepository().GetPrimaryDomain(org)
 	if err != nil {
 		log.Println(err)
 		return
 	}
+	subject := e.Subject
+	if subject == "" {
+		subject = "—"
+	}
 	vars := map[string]string{
 		"orgDomain":     FormatURL(domain.DomainName) + "/",
-		"recipientName": user.GetSafeRecipientName(),
+		"recipientName": delegate.GetSafeRecipientName(),
+		"principalName": principal.GetSafeRecipientName(),
 		"date":          e.Enter.Format("2006-01-02 15:04") + " - " + e.Leave.Format("2006-01-02 15:04"),
 		"areaName":      location.Name,
 		"spaceName":     space.Name,
 		"subject":       subject,
-	}
-	template := GetEmailTemplatePathBookingCreated()
-	if notification == BookingMailNotificationUpdated {
-		template = GetEmailTemplatePathBookingUpdated()
-	} else if notification == BookingMailNotificationDeclined {
-		template = GetEmailTemplatePathBookingDeclined()
-	} else if notification == BookingMailNotificationApproved {
-		template = GetEmailTemplatePathBookingApproved()
-	} else if notification == BookingMailNotificationDeleted {
-		template = GetEmailTemplatePathBookingDeleted()
-	}
-	language := org.Language
-	if userLang, err := GetUserPreferencesRepository().Get(e.UserID, PreferenceMailLanguage.Name); err == nil && userLang != "" {
-		language = userLang
-	}
-	if err := SendEmailWithAttachmentsAndOrg(&MailAddress{Address: user.Email}, template, language, vars, attachments, org.ID); err != nil {
-		log.Println(err)
-		return
-	}
-	now := time.Now().UTC()
-	if err := GetBookingRepository().UpdateLastInfoMailSentAt(e.ID, &now); err != nil {
+		"created":       "0",
+		"updated":       "0",
+		"deleted":       "0",
+		"approved":      "0",
+		"declined":      "0",
+		"reason":        "",
+		"hasReason":     "0",
+	}
+	maps.Copy(vars, extraVars)
+	switch notification {
+	case BookingMailNotificationCreated:
+		vars["created"] = "1"
+	case BookingMailNotificationUpdated:
+		vars["updated"] = "1"
+	case BookingMailNotificationDeleted:
+		vars["deleted"] = "1"
+	case BookingMailNotificationApproved:
+		vars["approved"] = "1"
+	case BookingMailNotificationDeclined:
+		vars["declined"] = "1"
+	}
+	if err := SendEmailWithOrg(&MailAddress{Address: delegate.Email}, GetEmailTemplatePathBookingDelegate(), getUserMailLanguage(delegate, org), vars, org.ID); err != nil {
 		log.Println(err)
 	}
 }
@@ -1279,49 +1663,78 @@ func (router *BookingRouter) onBookingUpdated(e *Booking) {
 	for _, plg := range GetPlugins() {
 		plg.OnBookingUpdated(e.ID)
 	}
+	dispatchBookingWebhook(WebhookEventBookingUpdated, e)
 	router.sendMailNotification(e, BookingMailNotificationUpdated)
+	router.sendChatNotification(e, BookingMailNotificationUpdated)
 }
 
 func (router *BookingRouter) onBookingDeclinedOrApproved(e *Booking) {
 	if !e.Approved {
-		router.onBookingDeleted(e, false)
+		router.onBookingDeleted(e, false, "")
+		dispatchBookingWebhook(WebhookEventBookingDeclined, e)
 		router.sendMailNotification(e, BookingMailNotificationDeclined)
+		router.sendChatNotification(e, BookingMailNotificationDeclined)
+		router.sendPushNotification(e, BookingMailNotificationDeclined)
+		router.sendDelegateMailNotification(e, BookingMailNotificationDeclined, string(e.CreatedByUserID))
 	} else {
 		router.createCalDavEvent(e)
 		for _, plg := range GetPlugins() {
 			plg.OnBookingCreated(e.ID)
 		}
+		dispatchBookingWebhook(WebhookEventBookingApproved, e)
 		router.sendMailNotification(e, BookingMailNotificationApproved)
+		router.sendChatNotification(e, BookingMailNotificationApproved)
+		router.sendPushNotification(e, BookingMailNotificationApproved)
+		router.sendDelegateMailNotification(e, BookingMailNotificationApproved, string(e.CreatedByUserID))
 	}
 }
 
 func (router *BookingRouter) onBookingCreated(e *Booking) {
+	dispatchBookingWebhook(WebhookEventBookingCreated, e)
 	if e.Approved {
 		router.createCalDavEvent(e)
 		for _, plg := range GetPlugins() {
 			plg.OnBookingCreated(e.ID)
 		}
 		router.sendMailNotification(e, BookingMailNotificationCreated)
+		router.sendChatNotification(e, BookingMailNotificationCreated)
 	} else {
 		// Booking requires approval - notify approvers
 		router.sendApprovalRequestNotifications(e)
+		router.sendApprovalRequestChatNotification(e)
 	}
 }
 
-func (router *BookingRouter) sendApprovalRequestNotifications(e *Booking) {
-	// Get the space to find approver groups
-	space, err := GetSpaceRepository().GetOne(e.SpaceID)
-	if err != nil {
-		log.Println("Error getting space:", err)
-		return
-	}
+type approvalNotification int
+
+const (
+	approvalNotificationRequest approvalNotification = iota
+	approvalNotificationReminder
+	approvalNotificationEscalation
+)
 
+// sendApprovalRequestNotifications asks the approvers of the approval level
+// the booking is waiting for to approve it.
+func (router *BookingRouter) sendApprovalRequestNotifications(e *Booking) {
 	// Get approver group IDs for this space
-	approverGroupIDs, err := GetSpaceRepository().GetApproverGroupIDs(e.SpaceID)
+	approverGroupIDs, err := GetSpaceRepository().GetApproverGroupIDsForLevel(e.SpaceID, getBookingApprovalLevel(e))
 	if err != nil || len(approverGroupIDs) == 0 {
 		log.Println("Error getting approver groups or no approvers:", err)
 		return
 	}
+	router.sendApprovalNotifications(e, approverGroupIDs, approvalNotificationRequest, 0)
+}
+
+// sendApprovalNotifications sends an approval request, a reminder or an
+// escalation email to the members of the given groups. pendingHours is the
+// time the booking has been waiting for approval.
+func (router *BookingRouter) sendApprovalNotifications(e *Booking, approverGroupIDs []string, notification approvalNotification, pendingHours int) {
+	// Get the space for the notification
+	space, err := GetSpaceRepository().GetOne(e.SpaceID)
+	if err != nil {
+		log.Println("Error getting space:", err)
+		return
+	}
 
 	// Get location for timezone information
 	location, err := GetLocationRepository().GetOne(space.LocationID)
@@ -1346,6 +1759,7 @@ func (router *BookingRouter) sendApprovalRequestNotifications(e *Booking) {
 
 	// Collect all unique approver user IDs who have the preference enabled
 	approverUserIDs := make(map[string]bool)
+	pushUserIDs := make(map[string]bool)
 	for _, groupID := range approverGroupIDs {
 		group, err := GetGroupRepository().GetOne(groupID)
 		if err != nil {
@@ -1360,11 +1774,19 @@ func (router *BookingRouter) sendApprovalRequestNotifications(e *Booking) {
 		}
 
 		for _, userID := range memberIDs {
-			// Check if user has approval notifications enabled
+			if userID == string(e.ApprovalFirstApproverID) {
+				continue
+			}
+			// Check if user has approval notifications enabled, users with
+			// digest mode get pending approvals with their next digest
 			notificationsEnabled, err := GetUserPreferencesRepository().GetBool(userID, PreferenceApprovalNotifications.Name)
-			if err == nil && notificationsEnabled {
+			if err == nil && notificationsEnabled && !isMailDigestEnabled(userID) {
 				approverUserIDs[userID] = true
 			}
+			// Push notifications are sent immediately regardless of digest mode
+			if pushEnabled, _ := GetUserPreferencesRepository().GetBool(userID, PreferencePushApprovalRequests.Name); pushEnabled {
+				pushUserIDs[userID] = true
+			}
 		}
 	}
 
@@ -1378,8 +1800,11 @@ func (router *BookingRouter) sendApprovalRequestNotifications(e *Booking) {
 		return
 	}
 
-	// Send email to each approver
-	for userID := range approverUserIDs {
+	recipientUserIDs := maps.Clone(approverUserIDs)
+	maps.Copy(recipientUserIDs, pushUserIDs)
+
+	// Send email and push notifications to each approver
+	for userID := range recipientUserIDs {
 		approver, err := GetUserRepository().GetOne(userID)
 		if err != nil {
 			log.Println("Error getting approver user:", err)
@@ -1394,20 +1819,34 @@ func (router *BookingRouter) sendApprovalRequestNotifications(e *Booking) {
 			"areaName":      location.Name,
 			"spaceName":     space.Name,
 			"subject":       subject,
+			"pendingHours":  strconv.Itoa(pendingHours),
+			"secondLevel":   boolToMailVar(getBookingApprovalLevel(e) == ApprovalLevelSecond),
+			"reminder":      boolToMailVar(notification == approvalNotificationReminder),
+			"escalated":     boolToMailVar(notification == approvalNotificationEscalation),
 		}
+		actionToken, err := createApprovalLink(e.ID, approver.ID)
+		if err != nil {
+			log.Println("Error creating approval link:", err)
+		}
+		vars["actionToken"] = actionToken
 
 		approverLang := org.Language
 		if userLang, err := GetUserPreferencesRepository().Get(approver.ID, PreferenceMailLanguage.Name); err == nil && userLang != "" {
 			approverLang = userLang
 		}
 		template := GetEmailTemplatePathBookingApprovalRequest()
-		if err := SendEmailWithOrg(&MailAddress{Address: approver.Email}, template, approverLang, vars, org.ID); err != nil {
-			log.Println("Error sending approval notification email:", err)
+		if approverUserIDs[userID] {
+			if err := SendEmailWithOrg(&MailAddress{Address: approver.Email}, template, approverLang, vars, org.ID); err != nil {
+				log.Println("Error sending approval notification email:", err)
+			}
+		}
+		if pushUserIDs[userID] {
+			SendUserPushNotification(userID, template, approverLang, vars)
 		}
 	}
 }
 
-func (router *BookingRouter) onBookingDeleted(e *Booking, sendNotification bool) {
+func (router *BookingRouter) onBookingDeleted(e *Booking, sendNotification bool, reason string) {
 	for _, plg := range GetPlugins() {
 		plg.OnBookingDeleted(e.ID)
 	}
@@ -1422,7 +1861,8 @@ func (router *BookingRouter) onBookingDeleted(e *Booking, sendNotification bool)
 	}
 
 	if sendNotification {
-		router.sendMailNotification(e, BookingMailNotificationDeleted)
+		router.sendMailNotificationWithVars(e, BookingMailNotificationDeleted, getCancellationReasonVars(reason))
+		router.sendChatNotificationWithVars(e, BookingMailNotificationDeleted, getCancellationReasonVars(reason))
 	}
 }
 
@@ -1458,6 +1898,8 @@ func (router *BookingRouter) copyToRestModel(e *BookingDetails) *GetBookingRespo
 	m.Leave, _ = GetLocationRepository().AttachTimezoneInformation(e.Leave, &e.Space.Location)
 	m.Space.ID = e.Space.ID
 	m.RecurringID = string(e.RecurringID)
+	m.CreatedByUserID = string(e.CreatedByUserID)
+	m.CreatedByEmail = e.CreatedByEmail
 	m.Approved = e.Approved
 	m.Space.LocationID = e.Space.LocationID
 	m.Space.Name = e.Space.Name
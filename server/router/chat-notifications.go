@@ -0,0 +1,132 @@
+package router
+
+import (
+	"log"
+	"maps"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+// getUserChatChannel returns the incoming webhook a user has configured for
+// their own notifications or nil if there is none.
+func getUserChatChannel(userID string) NotificationChannel {
+	value, err := GetUserPreferencesRepository().Get(userID, PreferenceChatWebhookURL.Name)
+	if err != nil || value == "" {
+		return nil
+	}
+	url, err := DecryptString(value)
+	if err != nil || url == "" {
+		return nil
+	}
+	format, _ := GetUserPreferencesRepository().Get(userID, PreferenceChatWebhookFormat.Name)
+	return newChatWebhookChannel(url, format)
+}
+
+// getOrgApprovalChatChannel returns the incoming webhook an organization has
+// configured for approval requests or nil if there is none.
+func getOrgApprovalChatChannel(organizationID string) NotificationChannel {
+	url, err := GetSettingsRepository().Get(organizationID, SettingChatApprovalWebhookURL.Name)
+	if err != nil || url == "" {
+		return nil
+	}
+	format, _ := GetSettingsRepository().Get(organizationID, SettingChatApprovalWebhookFormat.Name)
+	return newChatWebhookChannel(url, format)
+}
+
+func newChatWebhookChannel(url, format string) NotificationChannel {
+	if format == "" {
+		format = ChatFormatSlack
+	}
+	return &ChatWebhookChannel{URL: url, Format: format}
+}
+
+// SendUserChatNotification posts a message rendered from an email template to
+// the user's chat channel. It returns false if the user has no channel or the
+// message could not be delivered.
+func SendUserChatNotification(userID, templateFile, language string, vars map[string]string) bool {
+	channel := getUserChatChannel(userID)
+	if channel == nil {
+		return false
+	}
+	m, err := RenderChatMessage(templateFile, language, vars)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	if err := channel.Send(m); err != nil {
+		log.Println("Error sending chat notification to user " + userID + ": " + err.Error())
+		return false
+	}
+	return true
+}
+
+// sendChatNotification informs the booking's user via chat about the same
+// events which trigger sendMailNotification.
+func (router *BookingRouter) sendChatNotification(e *Booking, notification BookingMailNotification) {
+	router.sendChatNotificationWithVars(e, notification, nil)
+}
+
+// sendChatNotificationWithVars posts to the user's chat webhook, passing
+// additional template variables such as the cancellation reason.
+func (router *BookingRouter) sendChatNotificationWithVars(e *Booking, notification BookingMailNotification, extraVars map[string]string) {
+	active, err := GetUserPreferencesRepository().GetBool(e.UserID, PreferenceChatNotifications.Name)
+	if err != nil || !active {
+		return
+	}
+	user, err := GetUserRepository().GetOne(e.UserID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	vars, err := router.getBookingNotificationVars(e, user, org)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	maps.Copy(vars, extraVars)
+	SendUserChatNotification(user.ID, router.getBookingNotificationTemplate(notification), getUserMailLanguage(user, org), vars)
+}
+
+// sendApprovalRequestChatNotification posts a booking which requires approval
+// to the organization's approval channel.
+func (router *BookingRouter) sendApprovalRequestChatNotification(e *Booking) {
+	user, err := GetUserRepository().GetOne(e.UserID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	channel := getOrgApprovalChatChannel(user.OrganizationID)
+	if channel == nil {
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	vars, err := router.getBookingNotificationVars(e, user, org)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	vars["userEmail"] = user.Email
+	vars["pendingHours"] = "0"
+	vars["secondLevel"] = boolToMailVar(getBookingApprovalLevel(e) == ApprovalLevelSecond)
+	vars["reminder"] = "0"
+	vars["escalated"] = "0"
+	m, err := RenderChatMessage(GetEmailTemplatePathBookingApprovalRequest(), org.Language, vars)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := channel.Send(m); err != nil {
+		log.Println("Error sending approval chat notification: " + err.Error())
+	}
+}
@@ -0,0 +1,199 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+type DelegationRouter struct {
+}
+
+type CreateDelegationRequest struct {
+	DelegateEmail   string     `json:"delegateEmail" validate:"omitempty,email"`
+	DelegateGroupID string     `json:"delegateGroupId" validate:"omitempty,uuid"`
+	Expiry          *time.Time `json:"expiry"`
+}
+
+type GetDelegationResponse struct {
+	ID                string     `json:"id"`
+	UserID            string     `json:"userId"`
+	UserEmail         string     `json:"userEmail"`
+	DelegateUserID    string     `json:"delegateUserId"`
+	DelegateEmail     string     `json:"delegateEmail"`
+	DelegateGroupID   string     `json:"delegateGroupId"`
+	DelegateGroupName string     `json:"delegateGroupName"`
+	Created           time.Time  `json:"created"`
+	Expiry            *time.Time `json:"expiry"`
+	Active            bool       `json:"active"`
+}
+
+func (router *DelegationRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/principals", router.getPrincipals).Methods("GET")
+	s.HandleFunc("/{id}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.create).Methods("POST")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+func (router *DelegationRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	list, err := GetDelegationRepository().GetAllByUser(GetRequestUserID(r))
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetDelegationResponse{}
+	for _, e := range list {
+		res = append(res, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+func (router *DelegationRouter) getPrincipals(w http.ResponseWriter, r *http.Request) {
+	list, err := GetDelegationRepository().GetPrincipals(GetRequestUserID(r))
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetUserInfoSmall{}
+	for _, e := range list {
+		res = append(res, &GetUserInfoSmall{
+			UserID:    e.ID,
+			Email:     e.Email,
+			Firstname: e.Firstname,
+			Lastname:  e.Lastname,
+		})
+	}
+	SendJSON(w, res)
+}
+
+func (router *DelegationRouter) create(w http.ResponseWriter, r *http.Request) {
+	var m CreateDelegationRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	m.DelegateEmail = strings.TrimSpace(m.DelegateEmail)
+	if (m.DelegateEmail == "") == (m.DelegateGroupID == "") {
+		SendBadRequest(w)
+		return
+	}
+	if m.Expiry != nil && m.Expiry.Before(time.Now()) {
+		SendBadRequest(w)
+		return
+	}
+	requestUser := GetRequestUser(r)
+	e := &Delegation{
+		OrganizationID: requestUser.OrganizationID,
+		UserID:         requestUser.ID,
+	}
+	if m.Expiry != nil {
+		expiry := m.Expiry.UTC()
+		e.Expiry = &expiry
+	}
+	if m.DelegateEmail != "" {
+		delegate, err := GetUserRepository().GetByEmail(requestUser.OrganizationID, m.DelegateEmail)
+		if err != nil || delegate == nil {
+			SendNotFound(w)
+			return
+		}
+		if delegate.ID == requestUser.ID {
+			SendBadRequest(w)
+			return
+		}
+		e.DelegateUserID = delegate.ID
+	} else {
+		if ok, err := GetGroupRepository().GroupsExistAndBelongToOrg(requestUser.OrganizationID, []string{m.DelegateGroupID}); err != nil || !ok {
+			SendNotFound(w)
+			return
+		}
+		e.DelegateGroupID = m.DelegateGroupID
+	}
+	existing, err := GetDelegationRepository().GetAllByUser(requestUser.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	for _, d := range existing {
+		if d.DelegateUserID == e.DelegateUserID && d.DelegateGroupID == e.DelegateGroupID {
+			SendAlreadyExists(w)
+			return
+		}
+	}
+	if err := GetDelegationRepository().Create(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendCreated(w, e.ID)
+}
+
+func (router *DelegationRouter) delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	e, err := GetDelegationRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	requestUser := GetRequestUser(r)
+	if e.UserID != requestUser.ID && !CanAdminOrg(requestUser, e.OrganizationID) {
+		SendForbidden(w)
+		return
+	}
+	if err := GetDelegationRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendUpdated(w)
+}
+
+func (router *DelegationRouter) copyToRestModel(e *DelegationDetails) *GetDelegationResponse {
+	m := &GetDelegationResponse{}
+	m.ID = e.ID
+	m.UserID = e.UserID
+	m.UserEmail = e.UserEmail
+	m.DelegateUserID = e.DelegateUserID
+	m.DelegateEmail = e.DelegateEmail
+	m.DelegateGroupID = e.DelegateGroupID
+	m.DelegateGroupName = e.DelegateGroupName
+	m.Created = e.Created
+	m.Expiry = e.Expiry
+	m.Active = e.Expiry == nil || e.Expiry.After(time.Now())
+	return m
+}
+
+// getDelegationPrincipal returns the user with the specified email address if
+// that user has delegated their bookings to the request user, or nil otherwise.
+func getDelegationPrincipal(requestUser *User, email string) *User {
+	principal, err := GetUserRepository().GetByEmail(requestUser.OrganizationID, email)
+	if err != nil || principal == nil {
+		return nil
+	}
+	if !isDelegateOf(requestUser, principal.ID) {
+		return nil
+	}
+	return principal
+}
+
+// isDelegateOf returns true if the request user may manage the bookings of
+// the specified user on their behalf.
+func isDelegateOf(requestUser *User, userID string) bool {
+	if requestUser.ID == userID {
+		return false
+	}
+	res, err := GetDelegationRepository().IsDelegate(userID, requestUser.ID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return res
+}
@@ -0,0 +1,307 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type EmailTemplateRouter struct {
+}
+
+type GetEmailTemplateDefinitionResponse struct {
+	Name              string   `json:"name"`
+	Variables         []string `json:"variables"`
+	Conditions        []string `json:"conditions"`
+	RequiredVariables []string `json:"requiredVariables"`
+	// Languages the organization has customized the template for
+	CustomLanguages []string `json:"customLanguages"`
+}
+
+type SetEmailTemplateRequest struct {
+	Subject  string `json:"subject" validate:"required,max=256"`
+	Headline string `json:"headline" validate:"max=256"`
+	BodyHTML string `json:"bodyHtml" validate:"required,max=65536"`
+	BodyText string `json:"bodyText" validate:"max=65536"`
+}
+
+type GetEmailTemplateResponse struct {
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	// False if the built-in template is returned
+	Custom  bool       `json:"custom"`
+	Updated *time.Time `json:"updated"`
+	SetEmailTemplateRequest
+}
+
+type GetEmailTemplatePreviewResponse struct {
+	Subject  string `json:"subject"`
+	BodyHTML string `json:"bodyHtml"`
+	BodyText string `json:"bodyText"`
+}
+
+func (router *EmailTemplateRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/{name}/{language}/preview", router.preview).Methods("POST")
+	s.HandleFunc("/{name}/{language}", router.getOne).Methods("GET")
+	s.HandleFunc("/{name}/{language}", router.update).Methods("PUT")
+	s.HandleFunc("/{name}/{language}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+// GetOrgEmailTemplate returns an organization's custom email template. It is
+// used by SendEmail to replace built-in templates.
+func GetOrgEmailTemplate(organizationID, name, language string) (*CustomEmailTemplate, error) {
+	e, err := GetEmailTemplateRepository().GetOne(organizationID, name, language)
+	if err != nil {
+		return nil, err
+	}
+	return &CustomEmailTemplate{
+		Subject:  e.Subject,
+		Headline: e.Headline,
+		BodyHTML: e.BodyHTML,
+		BodyText: e.BodyText,
+	}, nil
+}
+
+func (router *EmailTemplateRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	list, err := GetEmailTemplateRepository().GetAll(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetEmailTemplateDefinitionResponse{}
+	for _, def := range EmailTemplateDefinitions {
+		item := &GetEmailTemplateDefinitionResponse{
+			Name:              def.Name,
+			Variables:         def.Variables,
+			Conditions:        def.Conditions,
+			RequiredVariables: def.RequiredVariables,
+			CustomLanguages:   []string{},
+		}
+		if item.Conditions == nil {
+			item.Conditions = []string{}
+		}
+		if item.RequiredVariables == nil {
+			item.RequiredVariables = []string{}
+		}
+		for _, e := range list {
+			if e.Name == def.Name {
+				item.CustomLanguages = append(item.CustomLanguages, e.Language)
+			}
+		}
+		res = append(res, item)
+	}
+	SendJSON(w, res)
+}
+
+// getOne returns the organization's custom template or, if there is none,
+// the built-in template as a starting point for customization.
+func (router *EmailTemplateRouter) getOne(w http.ResponseWriter, r *http.Request) {
+	def, language := router.getDefinitionForAdmin(w, r)
+	if def == nil {
+		return
+	}
+	user := GetRequestUser(r)
+	e, err := GetEmailTemplateRepository().GetOne(user.OrganizationID, def.Name, language)
+	if err == nil {
+		SendJSON(w, router.copyToRestModel(e))
+		return
+	}
+	builtin, err := GetBuiltinEmailTemplate(def, language)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendJSON(w, &GetEmailTemplateResponse{
+		Name:     def.Name,
+		Language: language,
+		Custom:   false,
+		SetEmailTemplateRequest: SetEmailTemplateRequest{
+			Subject:  builtin.Subject,
+			Headline: builtin.Headline,
+			BodyHTML: builtin.BodyHTML,
+			BodyText: builtin.BodyText,
+		},
+	})
+}
+
+func (router *EmailTemplateRouter) update(w http.ResponseWriter, r *http.Request) {
+	def, language := router.getDefinitionForAdmin(w, r)
+	if def == nil {
+		return
+	}
+	var m SetEmailTemplateRequest
+	if UnmarshalValidateBody(r, &m) != nil || ValidateEmailSubject(m.Subject) != nil {
+		SendBadRequest(w)
+		return
+	}
+	if !router.validateTemplate(w, def, &m) {
+		return
+	}
+	user := GetRequestUser(r)
+	var before *GetEmailTemplateResponse
+	if old, err := GetEmailTemplateRepository().GetOne(user.OrganizationID, def.Name, language); err == nil {
+		before = router.copyToRestModel(old)
+	}
+	e := &EmailTemplate{
+		OrganizationID: user.OrganizationID,
+		Name:           def.Name,
+		Language:       language,
+		Subject:        m.Subject,
+		Headline:       m.Headline,
+		BodyHTML:       m.BodyHTML,
+		BodyText:       m.BodyText,
+		Updated:        time.Now().UTC(),
+	}
+	if err := GetEmailTemplateRepository().Save(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	action := AuditActionUpdate
+	if before == nil {
+		action = AuditActionCreate
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     action,
+		EntityType: AuditEntityEmailTemplate,
+		EntityID:   e.ID,
+		EntityName: def.Name + " (" + language + ")",
+	}, before, router.copyToRestModel(e))
+	SendUpdated(w)
+}
+
+// delete removes the custom template so that the built-in one is used again.
+func (router *EmailTemplateRouter) delete(w http.ResponseWriter, r *http.Request) {
+	def, language := router.getDefinitionForAdmin(w, r)
+	if def == nil {
+		return
+	}
+	user := GetRequestUser(r)
+	e, err := GetEmailTemplateRepository().GetOne(user.OrganizationID, def.Name, language)
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	if err := GetEmailTemplateRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionDelete,
+		EntityType: AuditEntityEmailTemplate,
+		EntityID:   e.ID,
+		EntityName: def.Name + " (" + language + ")",
+	}, router.copyToRestModel(e), nil)
+	SendUpdated(w)
+}
+
+// preview renders the submitted template with sample values for all of its
+// variables.
+func (router *EmailTemplateRouter) preview(w http.ResponseWriter, r *http.Request) {
+	def, language := router.getDefinitionForAdmin(w, r)
+	if def == nil {
+		return
+	}
+	var m SetEmailTemplateRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	if !router.validateTemplate(w, def, &m) {
+		return
+	}
+	subject, body, bodyText, err := RenderCustomEmailTemplate(router.copyFromRestModel(&m), def.GetSampleVars())
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	footer, footerText, err := GetEmailFooter(language)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	if bodyText != "" && footerText != "" {
+		bodyText += "\n\n-- \n" + footerText
+	}
+	SendJSON(w, &GetEmailTemplatePreviewResponse{
+		Subject:  subject,
+		BodyHTML: strings.ReplaceAll(body, "{{footer}}", footer),
+		BodyText: bodyText,
+	})
+}
+
+func (router *EmailTemplateRouter) validateTemplate(w http.ResponseWriter, def *EmailTemplateDefinition, m *SetEmailTemplateRequest) bool {
+	err := def.Validate(router.copyFromRestModel(m))
+	if errors.Is(err, ErrEmailTemplateMissingVariable) {
+		SendBadRequestCode(w, ResponseCodeEmailTemplateMissingVariable)
+		return false
+	}
+	if errors.Is(err, ErrEmailTemplateUnknownVariable) {
+		SendBadRequestCode(w, ResponseCodeEmailTemplateUnknownVariable)
+		return false
+	}
+	return true
+}
+
+func (router *EmailTemplateRouter) getDefinitionForAdmin(w http.ResponseWriter, r *http.Request) (*EmailTemplateDefinition, string) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return nil, ""
+	}
+	vars := mux.Vars(r)
+	def := GetEmailTemplateDefinition(vars["name"])
+	if def == nil {
+		SendNotFound(w)
+		return nil, ""
+	}
+	if !GetConfig().IsValidLanguageCode(vars["language"]) {
+		SendBadRequest(w)
+		return nil, ""
+	}
+	return def, vars["language"]
+}
+
+func (router *EmailTemplateRouter) copyFromRestModel(m *SetEmailTemplateRequest) *CustomEmailTemplate {
+	return &CustomEmailTemplate{
+		Subject:  m.Subject,
+		Headline: m.Headline,
+		BodyHTML: m.BodyHTML,
+		BodyText: m.BodyText,
+	}
+}
+
+func (router *EmailTemplateRouter) copyToRestModel(e *EmailTemplate) *GetEmailTemplateResponse {
+	return &GetEmailTemplateResponse{
+		Name:     e.Name,
+		Language: e.Language,
+		Custom:   true,
+		Updated:  &e.Updated,
+		SetEmailTemplateRequest: SetEmailTemplateRequest{
+			Subject:  e.Subject,
+			Headline: e.Headline,
+			BodyHTML: e.BodyHTML,
+			BodyText: e.BodyText,
+		},
+	}
+}
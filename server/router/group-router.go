@@ -45,7 +45,7 @@ func (router *GroupRouter) getOne(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasAnyPermission(user, e.OrganizationID, PermissionManageUsers, PermissionManageSpaces) {
 		SendForbidden(w)
 		return
 	}
@@ -55,7 +55,7 @@ func (router *GroupRouter) getOne(w http.ResponseWriter, r *http.Request) {
 
 func (router *GroupRouter) getAll(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasAnyPermission(user, user.OrganizationID, PermissionManageUsers, PermissionManageSpaces) {
 		SendForbidden(w)
 		return
 	}
@@ -93,7 +93,7 @@ func (router *GroupRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !HasPermission(user, e.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -112,6 +112,13 @@ func (router *GroupRouter) update(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityGroup,
+		EntityID:       e.ID,
+		EntityName:     eNew.Name,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), router.copyToRestModel(eNew))
 	SendUpdated(w)
 }
 
@@ -123,7 +130,7 @@ func (router *GroupRouter) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !router.canManageMembers(user, e) {
 		SendForbidden(w)
 		return
 	}
@@ -132,12 +139,19 @@ func (router *GroupRouter) delete(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntityGroup,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), nil)
 	SendUpdated(w)
 }
 
 func (router *GroupRouter) create(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -165,6 +179,12 @@ func (router *GroupRouter) create(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityGroup,
+		EntityID:   e.ID,
+		EntityName: e.Name,
+	}, nil, router.copyToRestModel(e))
 	SendCreated(w, e.ID)
 }
 
@@ -176,7 +196,7 @@ func (router *GroupRouter) addMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !router.canManageMembers(user, e) {
 		SendForbidden(w)
 		return
 	}
@@ -210,6 +230,13 @@ func (router *GroupRouter) addMembers(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityGroup,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, nil, map[string][]string{"addedMembers": members})
 	SendUpdated(w)
 }
 
@@ -221,7 +248,7 @@ func (router *GroupRouter) removeMembers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !router.canManageMembers(user, e) {
 		SendForbidden(w)
 		return
 	}
@@ -245,6 +272,13 @@ func (router *GroupRouter) removeMembers(w http.ResponseWriter, r *http.Request)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityGroup,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, nil, map[string][]string{"removedMembers": members})
 	SendUpdated(w)
 }
 
@@ -256,7 +290,7 @@ func (router *GroupRouter) getMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !HasPermission(user, e.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -291,6 +325,33 @@ func (router *GroupRouter) getMembers(w http.ResponseWriter, r *http.Request) {
 	SendJSON(w, res)
 }
 
+// canManageMembers returns true if the user may change the group's members.
+// Groups holding custom roles or administering locations can only be changed
+// by org admins, so users managing users cannot grant themselves additional
+// permissions.
+func (router *GroupRouter) canManageMembers(user *User, e *Group) bool {
+	if CanAdminOrg(user, e.OrganizationID) {
+		return true
+	}
+	if !HasPermission(user, e.OrganizationID, PermissionManageUsers) {
+		return false
+	}
+	assigned, err := GetRoleRepository().IsGroupAssigned(e.ID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	if assigned {
+		return false
+	}
+	locationAdmin, err := GetLocationRepository().IsGroupAdmin(e.ID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return !locationAdmin
+}
+
 func (router *GroupRouter) copyFromRestModel(m *CreateGroupRequest) *Group {
 	e := &Group{}
 	e.Name = m.Name
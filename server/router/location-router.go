@@ -39,6 +39,9 @@ type CreateLocationRequest struct {
 	MapType               string   `json:"mapType" validate:"omitempty,oneof=designed"`
 	AllowedBookerGroupIDs []string `json:"allowedBookerGroupIds" validate:"dive,uuid"`
 	BookableDays          []int    `json:"bookableDays" validate:"dive,min=0,max=6"`
+	// Users matching any of these attribute values may book in addition to
+	// the members of the allowed booker groups.
+	AllowedBookerAttributes []LocationAttributeRuleRequest `json:"allowedBookerAttributes" validate:"dive"`
 }
 
 type GetLocationResponse struct {
@@ -50,6 +53,19 @@ type GetLocationResponse struct {
 	CreateLocationRequest
 }
 
+type CreateLocationAdminRequest struct {
+	UserEmail string `json:"userEmail" validate:"omitempty,email"`
+	GroupID   string `json:"groupId" validate:"omitempty,uuid"`
+}
+
+type GetLocationAdminResponse struct {
+	ID        string `json:"id"`
+	UserID    string `json:"userId"`
+	UserEmail string `json:"userEmail"`
+	GroupID   string `json:"groupId"`
+	GroupName string `json:"groupName"`
+}
+
 type GetFloorPlanDesignResponse struct {
 	DesignData string `json:"designData"`
 }
@@ -93,6 +109,9 @@ func (router *LocationRouter) SetupRoutes(s *mux.Router) {
 	s.HandleFunc("/{id}/attribute", router.getAttributes).Methods("GET")
 	s.HandleFunc("/{id}/attribute/{attributeId}", router.setAttribute).Methods("POST")
 	s.HandleFunc("/{id}/attribute/{attributeId}", router.deleteAttribute).Methods("DELETE")
+	s.HandleFunc("/{id}/admin/{adminId}", router.removeAdmin).Methods("DELETE")
+	s.HandleFunc("/{id}/admin", router.getAdmins).Methods("GET")
+	s.HandleFunc("/{id}/admin", router.addAdmin).Methods("POST")
 	s.HandleFunc("/{id}/map", router.getMap).Methods("GET")
 	s.HandleFunc("/{id}/map", router.setMap).Methods("POST")
 	s.HandleFunc("/{id}/floorplan-design", router.getFloorPlanDesign).Methods("GET")
@@ -138,7 +157,7 @@ func (router *LocationRouter) setAttribute(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasLocationPermission(user, e.OrganizationID, PermissionManageSpaces, e.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -174,7 +193,7 @@ func (router *LocationRouter) deleteAttribute(w http.ResponseWriter, r *http.Req
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasLocationPermission(user, e.OrganizationID, PermissionManageSpaces, e.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -197,7 +216,8 @@ func (router *LocationRouter) getOne(w http.ResponseWriter, r *http.Request) {
 	}
 
 	allowedBookers, err := GetLocationRepository().GetAllAllowedBookersForLocation(e.ID)
-	res := router.copyToRestModel(e, allowedBookers)
+	allowedAttributes, err := GetLocationRepository().GetAllowedAttributesForLocation(e.ID)
+	res := router.copyToRestModel(e, allowedBookers, allowedAttributes)
 	SendJSON(w, res)
 }
 
@@ -210,11 +230,30 @@ func (router *LocationRouter) getAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Optionally restrict the list to the locations the user holds a permission for
+	if permission := r.URL.Query().Get("permission"); permission != "" {
+		if !IsValidPermission(Permission(permission)) {
+			SendBadRequest(w)
+			return
+		}
+		all, permittedIDs := GetPermittedLocationIDs(user, user.OrganizationID, Permission(permission))
+		if !all {
+			filtered := []*Location{}
+			for _, e := range list {
+				if slices.Contains(permittedIDs, e.ID) {
+					filtered = append(filtered, e)
+				}
+			}
+			list = filtered
+		}
+	}
+
 	locationIDs := []string{}
 	for _, e := range list {
 		locationIDs = append(locationIDs, e.ID)
 	}
 	allowedBookers, err := GetLocationRepository().GetAllAllowedBookersForLocationList(locationIDs)
+	allowedAttributes, err := GetLocationRepository().GetAllowedAttributesForLocationList(locationIDs)
 
 	res := []*GetLocationResponse{}
 	for _, e := range list {
@@ -224,7 +263,7 @@ func (router *LocationRouter) getAll(w http.ResponseWriter, r *http.Request) {
 				filteredLocationGroup = append(filteredLocationGroup, ab)
 			}
 		}
-		m := router.copyToRestModel(e, filteredLocationGroup)
+		m := router.copyToRestModel(e, filteredLocationGroup, allowedAttributes)
 		res = append(res, m)
 	}
 	SendJSON(w, res)
@@ -359,6 +398,7 @@ func (router *LocationRouter) search(w http.ResponseWriter, r *http.Request) {
 		locationIDs = append(locationIDs, e.ID)
 	}
 	allowedBookers, err := GetLocationRepository().GetAllAllowedBookersForLocationList(locationIDs)
+	allowedAttributes, err := GetLocationRepository().GetAllowedAttributesForLocationList(locationIDs)
 
 	for _, e := range list {
 		if MatchesSearchAttributes(e.ID, &m.Attributes, attributeValues) {
@@ -368,7 +408,7 @@ func (router *LocationRouter) search(w http.ResponseWriter, r *http.Request) {
 					filteredLocationGroup = append(filteredLocationGroup, ab)
 				}
 			}
-			m := router.copyToRestModel(e, filteredLocationGroup)
+			m := router.copyToRestModel(e, filteredLocationGroup, allowedAttributes)
 			res = append(res, m)
 		}
 	}
@@ -388,7 +428,7 @@ func (router *LocationRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasLocationPermission(user, e.OrganizationID, PermissionManageSpaces, e.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -404,6 +444,11 @@ func (router *LocationRouter) update(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if !router.isValidAllowedAttributes(&m, e.OrganizationID) {
+		SendBadRequest(w)
+		return
+	}
+	before := router.getAuditModel(e.ID)
 	previousMapType := e.MapType
 	eNew := router.copyFromRestModel(&m)
 	eNew.ID = e.ID
@@ -430,6 +475,18 @@ func (router *LocationRouter) update(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	if err := GetLocationRepository().ReplaceAllowedAttributes(eNew, router.getAllowedAttributeRules(&m)); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityLocation,
+		EntityID:       e.ID,
+		EntityName:     eNew.Name,
+		OrganizationID: e.OrganizationID,
+	}, before, router.getAuditModel(e.ID))
 
 	SendUpdated(w)
 }
@@ -442,15 +499,23 @@ func (router *LocationRouter) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasLocationPermission(user, e.OrganizationID, PermissionManageSpaces, e.ID) {
 		SendForbidden(w)
 		return
 	}
+	before := router.getAuditModel(e.ID)
 	if err := GetLocationRepository().Delete(e); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntityLocation,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, before, nil)
 	SendUpdated(w)
 }
 
@@ -463,7 +528,7 @@ func (router *LocationRouter) create(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
 	e := router.copyFromRestModel(&m)
 	e.OrganizationID = user.OrganizationID
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasPermission(user, e.OrganizationID, PermissionManageSpaces) {
 		SendForbidden(w)
 		return
 	}
@@ -479,6 +544,10 @@ func (router *LocationRouter) create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if !router.isValidAllowedAttributes(&m, e.OrganizationID) {
+		SendBadRequest(w)
+		return
+	}
 	if err := GetLocationRepository().Create(e); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
@@ -491,6 +560,17 @@ func (router *LocationRouter) create(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	if err := GetLocationRepository().ReplaceAllowedAttributes(e, router.getAllowedAttributeRules(&m)); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityLocation,
+		EntityID:   e.ID,
+		EntityName: e.Name,
+	}, nil, router.getAuditModel(e.ID))
 
 	SendCreated(w, e.ID)
 }
@@ -587,7 +667,7 @@ func (router *LocationRouter) setFloorPlanDesign(w http.ResponseWriter, r *http.
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasLocationPermission(user, e.OrganizationID, PermissionManageSpaces, e.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -639,7 +719,7 @@ func (router *LocationRouter) setMap(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, e.OrganizationID) {
+	if !HasLocationPermission(user, e.OrganizationID, PermissionManageSpaces, e.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -734,6 +814,131 @@ func weekdaysFromString(csv string) []int {
 	return days
 }
 
+func (router *LocationRouter) getAdmins(w http.ResponseWriter, r *http.Request) {
+	e := router.getLocationForOrgAdmin(w, r)
+	if e == nil {
+		return
+	}
+	list, err := GetLocationRepository().GetAdmins(e.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetLocationAdminResponse{}
+	for _, a := range list {
+		res = append(res, &GetLocationAdminResponse{
+			ID:        a.ID,
+			UserID:    a.UserID,
+			UserEmail: a.UserEmail,
+			GroupID:   a.GroupID,
+			GroupName: a.GroupName,
+		})
+	}
+	SendJSON(w, res)
+}
+
+func (router *LocationRouter) addAdmin(w http.ResponseWriter, r *http.Request) {
+	var m CreateLocationAdminRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	m.UserEmail = strings.TrimSpace(m.UserEmail)
+	if (m.UserEmail == "") == (m.GroupID == "") {
+		SendBadRequest(w)
+		return
+	}
+	e := router.getLocationForOrgAdmin(w, r)
+	if e == nil {
+		return
+	}
+	a := &LocationAdmin{
+		LocationID: e.ID,
+		GroupID:    m.GroupID,
+	}
+	if m.UserEmail != "" {
+		admin, err := GetUserRepository().GetByEmail(e.OrganizationID, m.UserEmail)
+		if err != nil || admin == nil {
+			SendNotFound(w)
+			return
+		}
+		a.UserID = admin.ID
+	} else if ok, err := GetGroupRepository().GroupsExistAndBelongToOrg(e.OrganizationID, []string{m.GroupID}); err != nil || !ok {
+		SendNotFound(w)
+		return
+	}
+	existing, err := GetLocationRepository().GetAdmins(e.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	for _, other := range existing {
+		if other.UserID == a.UserID && other.GroupID == a.GroupID {
+			SendAlreadyExists(w)
+			return
+		}
+	}
+	if err := GetLocationRepository().AddAdmin(a); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityLocation,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, nil, map[string]string{"adminUserEmail": m.UserEmail, "adminGroupId": m.GroupID})
+	SendCreated(w, a.ID)
+}
+
+func (router *LocationRouter) removeAdmin(w http.ResponseWriter, r *http.Request) {
+	e := router.getLocationForOrgAdmin(w, r)
+	if e == nil {
+		return
+	}
+	vars := mux.Vars(r)
+	a, err := GetLocationRepository().GetAdmin(vars["adminId"])
+	if err != nil || a.LocationID != e.ID {
+		SendNotFound(w)
+		return
+	}
+	if err := GetLocationRepository().RemoveAdmin(a); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityLocation,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, map[string]string{"adminUserId": a.UserID, "adminGroupId": a.GroupID}, nil)
+	SendUpdated(w)
+}
+
+// getLocationForOrgAdmin loads the location referenced in the request path and
+// ensures the request user is an admin of the location's organization. Only
+// organization admins may assign location admins. On failure, the error
+// response has already been sent and nil is returned.
+func (router *LocationRouter) getLocationForOrgAdmin(w http.ResponseWriter, r *http.Request) *Location {
+	vars := mux.Vars(r)
+	e, err := GetLocationRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return nil
+	}
+	if !CanAdminOrg(GetRequestUser(r), e.OrganizationID) {
+		SendForbidden(w)
+		return nil
+	}
+	return e
+}
+
 func (router *LocationRouter) copyFromRestModel(m *CreateLocationRequest) *Location {
 	e := &Location{}
 	e.Name = m.Name
@@ -747,7 +952,44 @@ func (router *LocationRouter) copyFromRestModel(m *CreateLocationRequest) *Locat
 	return e
 }
 
-func (router *LocationRouter) copyToRestModel(e *Location, allowedBookers []*LocationGroup) *GetLocationResponse {
+// isValidAllowedAttributes returns true if all attribute rules of the request
+// refer to user attributes of the organization.
+func (router *LocationRouter) isValidAllowedAttributes(m *CreateLocationRequest, organizationID string) bool {
+	for _, rule := range m.AllowedBookerAttributes {
+		attribute, err := GetUserAttributeRepository().GetOne(rule.AttributeID)
+		if err != nil || attribute.OrganizationID != organizationID {
+			return false
+		}
+	}
+	return true
+}
+
+func (router *LocationRouter) getAllowedAttributeRules(m *CreateLocationRequest) []*LocationAttributeRule {
+	res := []*LocationAttributeRule{}
+	for _, rule := range m.AllowedBookerAttributes {
+		res = append(res, &LocationAttributeRule{
+			AttributeID: rule.AttributeID,
+			Value:       strings.TrimSpace(rule.Value),
+		})
+	}
+	return res
+}
+
+// getAuditModel returns the current state of the location for the audit log.
+func (router *LocationRouter) getAuditModel(id string) *GetLocationResponse {
+	e, err := GetLocationRepository().GetOne(id)
+	if err != nil {
+		return nil
+	}
+	allowedBookers, _ := GetLocationRepository().GetAllAllowedBookersForLocation(id)
+	if allowedBookers == nil {
+		allowedBookers = []*LocationGroup{}
+	}
+	allowedAttributes, _ := GetLocationRepository().GetAllowedAttributesForLocation(id)
+	return router.copyToRestModel(e, allowedBookers, allowedAttributes)
+}
+
+func (router *LocationRouter) copyToRestModel(e *Location, allowedBookers []*LocationGroup, allowedAttributes []*LocationAttributeRule) *GetLocationResponse {
 	m := &GetLocationResponse{}
 	m.ID = e.ID
 	m.OrganizationID = e.OrganizationID
@@ -771,6 +1013,15 @@ func (router *LocationRouter) copyToRestModel(e *Location, allowedBookers []*Loc
 			}
 		}
 	}
+	m.AllowedBookerAttributes = []LocationAttributeRuleRequest{}
+	for _, rule := range allowedAttributes {
+		if rule.LocationID == e.ID {
+			m.AllowedBookerAttributes = append(m.AllowedBookerAttributes, LocationAttributeRuleRequest{
+				AttributeID: rule.AttributeID,
+				Value:       rule.Value,
+			})
+		}
+	}
 
 	return m
 }
@@ -0,0 +1,242 @@
+package router
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const (
+	// How long a magic login link stays valid
+	magicLinkExpiry = 15 * time.Minute
+	// Maximum number of unused magic login links per user
+	maxActiveMagicLinksPerUser = 3
+	// Maximum number of magic login link requests per client IP within magicLinkExpiry
+	maxMagicLinkRequestsPerIP = 20
+)
+
+// Magic link request rate limiter (per client IP)
+type magicLinkRequestTracker struct {
+	mu       sync.Mutex
+	requests map[string][]time.Time
+}
+
+var magicLinkRequestsTracker = &magicLinkRequestTracker{
+	requests: make(map[string][]time.Time),
+}
+
+func (t *magicLinkRequestTracker) recordRequest(ip string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Clean up old entries
+	cutoff := time.Now().Add(-magicLinkExpiry)
+	for k, v := range t.requests {
+		recent := v[:0]
+		for _, ts := range v {
+			if ts.After(cutoff) {
+				recent = append(recent, ts)
+			}
+		}
+		if len(recent) == 0 {
+			delete(t.requests, k)
+		} else {
+			t.requests[k] = recent
+		}
+	}
+
+	if len(t.requests[ip]) >= maxMagicLinkRequestsPerIP {
+		return false
+	}
+	t.requests[ip] = append(t.requests[ip], time.Now())
+	return true
+}
+
+type InitMagicLinkRequest struct {
+	OrganizationID string `json:"organizationId" validate:"required,uuid"`
+	Email          string `json:"email" validate:"required,email,max=256"`
+}
+
+type MagicLinkLoginRequest struct {
+	Code              string          `json:"code,omitempty"`
+	PasskeyStateID    string          `json:"passkeyStateId,omitempty"`
+	PasskeyCredential json.RawMessage `json:"passkeyCredential,omitempty"`
+}
+
+func (router *AuthRouter) isMagicLinkLoginEnabled(organizationID string) bool {
+	enabled, _ := GetSettingsRepository().GetBool(organizationID, SettingAllowMagicLinkLogin.Name)
+	return enabled
+}
+
+// initMagicLinkLogin sends a single-use login link to the user. To not
+// disclose which accounts exist, the response is the same whether or not a
+// link has actually been sent.
+func (router *AuthRouter) initMagicLinkLogin(w http.ResponseWriter, r *http.Request) {
+	var m InitMagicLinkRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	if !magicLinkRequestsTracker.recordRequest(GetClientIP(r)) {
+		SendTooManyRequests(w)
+		return
+	}
+	if !router.isMagicLinkLoginEnabled(m.OrganizationID) {
+		SendUpdated(w)
+		return
+	}
+	user, err := GetUserRepository().GetByEmail(m.OrganizationID, m.Email)
+	if user == nil || err != nil {
+		log.Printf("Magic link request failed: user %s not found in org %s\n", m.Email, m.OrganizationID)
+		SendUpdated(w)
+		return
+	}
+	if !CanMagicLinkLogin(user) {
+		SendUpdated(w)
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if org == nil || err != nil {
+		SendUpdated(w)
+		return
+	}
+	existingStates, _ := GetAuthStateRepository().GetActiveByPayloadAndType(user.ID, AuthMagicLinkLogin)
+	if len(existingStates) >= maxActiveMagicLinksPerUser {
+		log.Printf("Max. magic link requests exceeded for user %s\n", user.Email)
+		SendUpdated(w)
+		return
+	}
+	authState := &AuthState{
+		AuthProviderID: GetSettingsRepository().GetNullUUID(),
+		Expiry:         time.Now().Add(magicLinkExpiry),
+		AuthStateType:  AuthMagicLinkLogin,
+		Payload:        user.ID,
+	}
+	if err := GetAuthStateRepository().Create(authState); err != nil {
+		log.Println(err)
+		SendUpdated(w)
+		return
+	}
+	if err := router.SendMagicLinkEmail(user, authState.ID, org); err != nil {
+		log.Printf("Magic link email failed: %s\n", err)
+	}
+	SendUpdated(w)
+}
+
+// completeMagicLinkLogin exchanges a magic login link for a session. If the
+// user has configured a second factor, it must be provided just like with a
+// password login. The link stays valid until the login succeeds.
+func (router *AuthRouter) completeMagicLinkLogin(w http.ResponseWriter, r *http.Request) {
+	var m MagicLinkLoginRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	vars := mux.Vars(r)
+	authState, err := GetAuthStateRepository().GetOneActive(vars["id"])
+	if err != nil || authState == nil {
+		SendNotFound(w)
+		return
+	}
+	if authState.AuthStateType != AuthMagicLinkLogin {
+		SendNotFound(w)
+		return
+	}
+	user, err := GetUserRepository().GetOne(authState.Payload)
+	if user == nil || err != nil {
+		SendNotFound(w)
+		return
+	}
+	if !router.isMagicLinkLoginEnabled(user.OrganizationID) {
+		GetAuthStateRepository().Delete(authState)
+		SendNotFound(w)
+		return
+	}
+	if !CanMagicLinkLogin(user) {
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodMagicLink, ErrorCode: router.getMagicLinkDenialReason(user)})
+		SendNotFound(w)
+		return
+	}
+	if !checkNetworkPolicy(w, r, user, AuthMethodMagicLink) {
+		return
+	}
+
+	passkeyResult := router.handlePasskey2FA(w, r, user, &AuthPasswordRequest{
+		Code:              m.Code,
+		PasskeyStateID:    m.PasskeyStateID,
+		PasskeyCredential: m.PasskeyCredential,
+	})
+	if passkeyResult == passkey2FAHandled {
+		return
+	}
+	if passkeyResult != passkey2FAVerified {
+		if user.TotpSecret != "" {
+			if m.Code == "" {
+				SendUnauthorized(w)
+				return
+			}
+			if !router.verifyTotpCode(w, r, user, m.Code) {
+				return
+			}
+		} else if IsTotpEnforcedForUser(user) {
+			// The link alone must not bypass an enforced second factor
+			recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodMagicLink, ErrorCode: AuthErrorTotpMissing})
+			SendForbidden(w)
+			return
+		} else if isSecondFactorRequiredForNetwork(user, GetClientIP(r)) {
+			recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodMagicLink, ErrorCode: AuthErrorSecondFactorRequired, ErrorDetail: "client IP " + GetClientIP(r)})
+			SendForbiddenCode(w, ResponseCodeSecondFactorRequired)
+			return
+		}
+	}
+
+	consumed, err := GetAuthStateRepository().Consume(authState)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	if !consumed {
+		SendNotFound(w)
+		return
+	}
+	router.createAndSendJWT(w, r, user, AuthMethodMagicLink, "", "", "")
+}
+
+// getMagicLinkDenialReason returns the auth event error code explaining why
+// CanMagicLinkLogin() is false for this user.
+func (router *AuthRouter) getMagicLinkDenialReason(user *User) string {
+	if user.AuthProviderID != "" {
+		return AuthErrorBoundToAuthProvider
+	}
+	if user.Disabled {
+		return AuthErrorUserDisabled
+	}
+	return AuthErrorServiceAccount
+}
+
+func (router *AuthRouter) SendMagicLinkEmail(user *User, ID string, org *Organization) error {
+	domain, err := GetOrganizationRepository().GetPrimaryDomain(org)
+	if err != nil {
+		return err
+	}
+	vars := map[string]string{
+		"recipientName":  user.GetSafeRecipientName(),
+		"recipientEmail": user.Email,
+		"confirmID":      ID,
+		"orgDomain":      FormatURL(domain.DomainName) + "/",
+	}
+	language := org.Language
+	if userLang, err := GetUserPreferencesRepository().Get(user.ID, PreferenceMailLanguage.Name); err == nil && userLang != "" {
+		language = userLang
+	}
+	return SendEmailWithOrg(&MailAddress{Address: user.Email}, GetEmailTemplatePathMagicLink(), language, vars, org.ID)
+}
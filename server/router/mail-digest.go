@@ -0,0 +1,189 @@
+package router
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const (
+	// Digests are sent at this hour in the user's timezone
+	mailDigestHour = 7
+	// Daily digests cover today and tomorrow, weekly digests the coming week
+	mailDigestDailyDays  = 2
+	mailDigestWeeklyDays = 7
+)
+
+var mailDigestMu sync.Mutex
+
+// isMailDigestEnabled returns true if the user receives booking information
+// as a digest instead of one mail per event.
+func isMailDigestEnabled(userID string) bool {
+	mode, err := GetUserPreferencesRepository().GetInt(userID, PreferenceMailNotifications.Name)
+	if err != nil {
+		return false
+	}
+	return mode == PreferenceMailNotificationsDailyDigest || mode == PreferenceMailNotificationsWeeklyDigest
+}
+
+// SendMailDigests sends the daily and weekly digests which are due.
+func SendMailDigests() {
+	SendMailDigestsAt(time.Now().UTC())
+}
+
+// SendMailDigestsAt sends the digests which are due at the given time. Each
+// user receives at most one digest per day.
+func SendMailDigestsAt(now time.Time) {
+	mailDigestMu.Lock()
+	defer mailDigestMu.Unlock()
+
+	recipients, err := GetMailDigestRepository().GetRecipients()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	num := 0
+	for _, recipient := range recipients {
+		sent, err := sendMailDigest(recipient, now)
+		if err != nil {
+			log.Println("Error sending mail digest to user " + recipient.UserID + ": " + err.Error())
+			continue
+		}
+		if sent {
+			num++
+		}
+	}
+	if num > 0 {
+		log.Printf("Sent %d mail digests", num)
+	}
+}
+
+// getUserTimezone returns the timezone of the user's preferred location or,
+// if there is none, the organization's default timezone.
+func getUserTimezone(user *User) *time.Location {
+	tz := ""
+	if locationID, err := GetUserPreferencesRepository().Get(user.ID, PreferenceLocation.Name); err == nil && locationID != "" {
+		if location, err := GetLocationRepository().GetOne(locationID); err == nil && location.OrganizationID == user.OrganizationID {
+			tz = GetLocationRepository().GetTimezone(location)
+		}
+	}
+	if tz == "" {
+		tz, _ = GetSettingsRepository().Get(user.OrganizationID, SettingDefaultTimezone.Name)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+// IsMailDigestDue returns true if a digest in the given mode is to be sent at
+// the local time now. Weekly digests are sent on the user's first day of the
+// week.
+func IsMailDigestDue(mode int, lastSent *time.Time, now time.Time, weekStartDay int) bool {
+	if now.Hour() < mailDigestHour {
+		return false
+	}
+	if mode == PreferenceMailNotificationsWeeklyDigest && int(now.Weekday()) != weekStartDay {
+		return false
+	}
+	if lastSent != nil && lastSent.In(now.Location()).Format("2006-01-02") == now.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
+
+func sendMailDigest(recipient *MailDigestRecipient, now time.Time) (bool, error) {
+	user, err := GetUserRepository().GetOne(recipient.UserID)
+	if err != nil {
+		return false, err
+	}
+	localNow := now.In(getUserTimezone(user))
+	weekStartDay, err := GetUserPreferencesRepository().GetInt(user.ID, PreferenceWeekStartDay.Name)
+	if err != nil {
+		weekStartDay = int(time.Monday)
+	}
+	if !IsMailDigestDue(recipient.Mode, recipient.LastSent, localNow, weekStartDay) {
+		return false, nil
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+
+	// Booking times are stored as wall clock time of their location
+	today := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
+	days := mailDigestDailyDays
+	if recipient.Mode == PreferenceMailNotificationsWeeklyDigest {
+		days = mailDigestWeeklyDays
+	}
+	end := today.AddDate(0, 0, days)
+	list, err := GetBookingRepository().GetAllByUser(user.ID, today)
+	if err != nil {
+		return false, err
+	}
+	bookings := []string{}
+	for _, e := range list {
+		if e.Enter.Before(end) {
+			bookings = append(bookings, formatMailDigestBooking(e))
+		}
+	}
+	approvals := []string{}
+	if notify, _ := GetUserPreferencesRepository().GetBool(user.ID, PreferenceApprovalNotifications.Name); notify &&
+		HasPermissionInAnyLocation(user, user.OrganizationID, PermissionApproveBookings) {
+		list, err := (&BookingRouter{}).getApprovableBookings(user)
+		if err != nil {
+			return false, err
+		}
+		for _, e := range list {
+			approvals = append(approvals, e.UserEmail+": "+formatMailDigestBooking(e))
+		}
+	}
+
+	if len(bookings) > 0 || len(approvals) > 0 {
+		domain, err := GetOrganizationRepository().GetPrimaryDomain(org)
+		if err != nil {
+			return false, err
+		}
+		vars := map[string]string{
+			"recipientName": user.GetSafeRecipientName(),
+			"orgDomain":     FormatURL(domain.DomainName) + "/",
+			"bookings":      strings.Join(bookings, "\n"),
+			"bookingCount":  strconv.Itoa(len(bookings)),
+			"approvals":     strings.Join(approvals, "\n"),
+			"approvalCount": strconv.Itoa(len(approvals)),
+			"hasBookings":   boolToMailVar(len(bookings) > 0),
+			"hasApprovals":  boolToMailVar(len(approvals) > 0),
+			"weekly":        boolToMailVar(recipient.Mode == PreferenceMailNotificationsWeeklyDigest),
+		}
+		if err := SendEmailWithOrg(&MailAddress{Address: user.Email}, GetEmailTemplatePathDigest(), getUserMailLanguage(user, org), vars, org.ID); err != nil {
+			return false, err
+		}
+	}
+	if err := GetMailDigestRepository().SetSent(user.ID, now); err != nil {
+		return false, err
+	}
+	return len(bookings) > 0 || len(approvals) > 0, nil
+}
+
+func formatMailDigestBooking(e *BookingDetails) string {
+	s := e.Enter.Format("2006-01-02 15:04") + " - " + e.Leave.Format("2006-01-02 15:04") + ": " +
+		e.Space.Name + ", " + e.Space.Location.Name
+	if e.Subject != "" {
+		s += " (" + e.Subject + ")"
+	}
+	return s
+}
+
+func boolToMailVar(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
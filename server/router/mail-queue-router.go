@@ -0,0 +1,158 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+type MailQueueRouter struct {
+}
+
+type GetFailedMailResponse struct {
+	ID            string     `json:"id"`
+	Recipient     string     `json:"recipient"`
+	RecipientName string     `json:"recipientName"`
+	Subject       string     `json:"subject"`
+	Attempts      int        `json:"attempts"`
+	LastAttempt   *time.Time `json:"lastAttempt"`
+	Error         string     `json:"error"`
+	Created       time.Time  `json:"created"`
+}
+
+type GetFailedMailListResponse struct {
+	Total   int                      `json:"total"`
+	Pending int                      `json:"pending"`
+	Items   []*GetFailedMailResponse `json:"items"`
+}
+
+const (
+	mailQueueDefaultLimit = 50
+	mailQueueMaxLimit     = 100
+)
+
+func (router *MailQueueRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/{id}/retry", router.retry).Methods("POST")
+	s.HandleFunc("/{id}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.getFailed).Methods("GET")
+}
+
+// getFailed returns the messages of the organization which could not be
+// delivered. Message bodies are not returned as they may contain login links.
+func (router *MailQueueRouter) getFailed(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	limit := mailQueueDefaultLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 {
+			SendBadRequest(w)
+			return
+		}
+		limit = min(parsed, mailQueueMaxLimit)
+	}
+	offset := 0
+	if param := r.URL.Query().Get("offset"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 0 {
+			SendBadRequest(w)
+			return
+		}
+		offset = parsed
+	}
+	total, err := GetMailQueueRepository().GetCount(user.OrganizationID, QueuedMailStatusDead)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	pending, err := GetMailQueueRepository().GetCount(user.OrganizationID, QueuedMailStatusPending)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	list, err := GetMailQueueRepository().GetDead(user.OrganizationID, limit, offset)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := &GetFailedMailListResponse{
+		Total:   total,
+		Pending: pending,
+		Items:   []*GetFailedMailResponse{},
+	}
+	for _, e := range list {
+		res.Items = append(res.Items, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+// retry puts a failed message back into the queue and triggers sending it.
+func (router *MailQueueRouter) retry(w http.ResponseWriter, r *http.Request) {
+	e := router.getFailedMailForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	if err := GetMailQueueRepository().Retry(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	go ProcessMailQueue()
+	SendUpdated(w)
+}
+
+func (router *MailQueueRouter) delete(w http.ResponseWriter, r *http.Request) {
+	e := router.getFailedMailForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	if err := GetMailQueueRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendUpdated(w)
+}
+
+func (router *MailQueueRouter) getFailedMailForAdmin(w http.ResponseWriter, r *http.Request) *QueuedMail {
+	vars := mux.Vars(r)
+	e, err := GetMailQueueRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return nil
+	}
+	if e.OrganizationID == "" || !HasPermission(GetRequestUser(r), e.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return nil
+	}
+	if e.Status != QueuedMailStatusDead {
+		SendBadRequest(w)
+		return nil
+	}
+	return e
+}
+
+func (router *MailQueueRouter) copyToRestModel(e *QueuedMail) *GetFailedMailResponse {
+	return &GetFailedMailResponse{
+		ID:            e.ID,
+		Recipient:     e.Recipient,
+		RecipientName: e.RecipientName,
+		Subject:       e.Subject,
+		Attempts:      e.Attempts,
+		LastAttempt:   e.LastAttempt,
+		Error:         e.Error,
+		Created:       e.Created,
+	}
+}
@@ -0,0 +1,157 @@
+package router
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const (
+	// Messages are moved to the dead letter queue after this many attempts
+	mailQueueMaxAttempts = 8
+	// Delay before the first retry, doubled with every further attempt
+	mailQueueRetryBaseDelay = time.Minute
+	mailQueueRetryMaxDelay  = 2 * time.Hour
+	// Claimed messages are not picked up by other workers during this period
+	mailQueueLease = 5 * time.Minute
+	mailQueueBatch = 100
+	// Number of messages sent in parallel
+	mailQueueWorkers = 5
+	// Window the per-organization rate limit applies to
+	mailQueueRateLimitWindow = time.Minute
+	// Maximum length of error messages kept for failed attempts
+	mailQueueMaxErrorLength = 512
+)
+
+var mailQueueMu sync.Mutex
+
+// EnqueueEmail stores a rendered message in the mail queue and triggers
+// sending it in the background.
+func EnqueueEmail(m *OutgoingMail) error {
+	now := time.Now().UTC()
+	e := &QueuedMail{
+		OrganizationID: m.OrganizationID,
+		Recipient:      m.Recipient.Address,
+		RecipientName:  m.Recipient.DisplayName,
+		Subject:        m.Subject,
+		Body:           m.Body,
+		BodyText:       m.BodyText,
+		Attachments:    m.Attachments,
+		Status:         QueuedMailStatusPending,
+		NextAttempt:    &now,
+		Created:        now,
+	}
+	if e.Attachments == nil {
+		e.Attachments = []*MailAttachment{}
+	}
+	if err := GetMailQueueRepository().Create(e); err != nil {
+		return err
+	}
+	go ProcessMailQueue()
+	return nil
+}
+
+// ProcessMailQueue sends all queued messages which are due, respecting the
+// per-organization rate limit.
+func ProcessMailQueue() {
+	mailQueueMu.Lock()
+	defer mailQueueMu.Unlock()
+
+	mails, err := GetMailQueueRepository().ClaimDue(mailQueueBatch, mailQueueLease)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	now := time.Now().UTC()
+	sentByOrg := map[string]int{}
+	limit := GetConfig().MailQueueOrgRateLimit
+	sem := make(chan struct{}, mailQueueWorkers)
+	var wg sync.WaitGroup
+	for _, m := range mails {
+		if limit > 0 && m.OrganizationID != "" {
+			if _, ok := sentByOrg[m.OrganizationID]; !ok {
+				count, err := GetMailLogRepository().GetCountByOrganizationSince(m.OrganizationID, now.Add(-mailQueueRateLimitWindow))
+				if err != nil {
+					log.Println(err)
+				}
+				sentByOrg[m.OrganizationID] = count
+			}
+			if sentByOrg[m.OrganizationID] >= limit {
+				if err := GetMailQueueRepository().Postpone(m, now.Add(mailQueueRateLimitWindow)); err != nil {
+					log.Println(err)
+				}
+				continue
+			}
+			sentByOrg[m.OrganizationID]++
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(m *QueuedMail) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			deliverQueuedMail(m)
+		}(m)
+	}
+	wg.Wait()
+}
+
+// deliverQueuedMail performs one delivery attempt. Sent messages are removed
+// from the queue, failed ones are rescheduled or moved to the dead letter
+// queue.
+func deliverQueuedMail(m *QueuedMail) {
+	err := DeliverEmail(&OutgoingMail{
+		Recipient: &MailAddress{
+			Address:     m.Recipient,
+			DisplayName: m.RecipientName,
+		},
+		Subject:        m.Subject,
+		Body:           m.Body,
+		BodyText:       m.BodyText,
+		Attachments:    m.Attachments,
+		OrganizationID: m.OrganizationID,
+	})
+	if err == nil {
+		if err := GetMailQueueRepository().Delete(m); err != nil {
+			log.Println(err)
+		}
+		if err := GetMailLogRepository().LogEmail(m.Subject, m.Recipient, m.OrganizationID); err != nil {
+			log.Printf("Failed to log email to database: %v\n", err)
+		}
+		return
+	}
+	now := time.Now().UTC()
+	m.Attempts++
+	m.LastAttempt = &now
+	m.Error = err.Error()
+	if len(m.Error) > mailQueueMaxErrorLength {
+		m.Error = m.Error[:mailQueueMaxErrorLength]
+	}
+	if m.Attempts >= mailQueueMaxAttempts || IsPermanentEmailError(err) {
+		log.Printf("Giving up sending email '%s' to %s after %d attempts: %s\n", m.Subject, m.Recipient, m.Attempts, m.Error)
+		m.Status = QueuedMailStatusDead
+		m.NextAttempt = nil
+		if err := GetMailLogRepository().LogFailedEmail(m.Subject, m.Recipient, m.OrganizationID, m.Error); err != nil {
+			log.Printf("Failed to log email to database: %v\n", err)
+		}
+	} else {
+		next := now.Add(GetMailQueueRetryDelay(m.Attempts))
+		m.NextAttempt = &next
+	}
+	if err := GetMailQueueRepository().Update(m); err != nil {
+		log.Println(err)
+	}
+}
+
+// GetMailQueueRetryDelay returns the delay before the next attempt after the
+// given number of failed attempts.
+func GetMailQueueRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(float64(mailQueueRetryBaseDelay) * math.Pow(2, float64(attempts-1)))
+	return min(delay, mailQueueRetryMaxDelay)
+}
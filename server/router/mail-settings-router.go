@@ -0,0 +1,305 @@
+package router
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type MailSettingsRouter struct {
+}
+
+type SetMailSettingsRequest struct {
+	// Empty to use the global mail service
+	SMTPHost               string `json:"smtpHost" validate:"max=253"`
+	SMTPPort               int    `json:"smtpPort" validate:"min=0,max=65535"`
+	SMTPTLSMode            string `json:"smtpTlsMode"`
+	SMTPInsecureSkipVerify bool   `json:"smtpInsecureSkipVerify"`
+	SMTPAuth               bool   `json:"smtpAuth"`
+	SMTPAuthMethod         string `json:"smtpAuthMethod"`
+	SMTPAuthUser           string `json:"smtpAuthUser" validate:"max=256"`
+	// Empty to keep the current password, unless the server or user changed
+	SMTPAuthPass  string `json:"smtpAuthPass" validate:"max=256"`
+	SenderAddress string `json:"senderAddress" validate:"max=254"`
+	SenderName    string `json:"senderName" validate:"max=78"`
+	ReplyTo       string `json:"replyTo" validate:"max=254"`
+}
+
+type GetMailSettingsResponse struct {
+	SMTPHost               string    `json:"smtpHost"`
+	SMTPPort               int       `json:"smtpPort"`
+	SMTPTLSMode            string    `json:"smtpTlsMode"`
+	SMTPInsecureSkipVerify bool      `json:"smtpInsecureSkipVerify"`
+	SMTPAuth               bool      `json:"smtpAuth"`
+	SMTPAuthMethod         string    `json:"smtpAuthMethod"`
+	SMTPAuthUser           string    `json:"smtpAuthUser"`
+	SMTPAuthPassSet        bool      `json:"smtpAuthPassSet"`
+	SenderAddress          string    `json:"senderAddress"`
+	SenderName             string    `json:"senderName"`
+	ReplyTo                string    `json:"replyTo"`
+	Updated                time.Time `json:"updated"`
+}
+
+type SendTestMailResponse struct {
+	Recipient string `json:"recipient"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+}
+
+var mailSettingsTLSModes = []string{SMTPTLSModeNone, SMTPTLSModeStartTLS, SMTPTLSModeImplicit}
+
+func (router *MailSettingsRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/test", router.sendTestMail).Methods("POST")
+	s.HandleFunc("/", router.get).Methods("GET")
+	s.HandleFunc("/", router.update).Methods("PUT")
+	s.HandleFunc("/", router.delete).Methods("DELETE")
+}
+
+// GetOrgMailTransport returns the mail transport configured by an
+// organization. It is used by DeliverEmail.
+func GetOrgMailTransport(organizationID string) (*MailTransport, error) {
+	e, err := GetMailSettingsRepository().Get(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	res := &MailTransport{
+		SenderName: e.SenderName,
+		ReplyTo:    e.ReplyTo,
+	}
+	if e.SMTPHost == "" {
+		return res, nil
+	}
+	pass := ""
+	if e.SMTPAuthPassEncrypted != "" {
+		pass, err = DecryptString(e.SMTPAuthPassEncrypted)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res.SenderAddress = e.SenderAddress
+	res.SMTP = &SMTPSettings{
+		Host:               e.SMTPHost,
+		Port:               e.SMTPPort,
+		TLSMode:            e.SMTPTLSMode,
+		InsecureSkipVerify: e.SMTPInsecureSkipVerify,
+		Auth:               e.SMTPAuth,
+		AuthMethod:         e.SMTPAuthMethod,
+		AuthUser:           e.SMTPAuthUser,
+		AuthPass:           pass,
+		PublicOnly:         !GetConfig().OrgSMTPAllowPrivateNetworks,
+	}
+	return res, nil
+}
+
+func (router *MailSettingsRouter) get(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	e, err := GetMailSettingsRepository().Get(user.OrganizationID)
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	SendJSON(w, router.copyToRestModel(e))
+}
+
+func (router *MailSettingsRouter) update(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	var m SetMailSettingsRequest
+	if UnmarshalValidateBody(r, &m) != nil || !router.isValidRequest(&m) {
+		SendBadRequest(w)
+		return
+	}
+	if ip := net.ParseIP(m.SMTPHost); ip != nil && !IsPublicIP(ip) && !GetConfig().OrgSMTPAllowPrivateNetworks {
+		SendBadRequestCode(w, ResponseCodeMailHostNotAllowed)
+		return
+	}
+	old, err := GetMailSettingsRepository().Get(user.OrganizationID)
+	if err != nil {
+		old = nil
+	}
+	e := &MailSettings{
+		OrganizationID: user.OrganizationID,
+		SenderName:     m.SenderName,
+		ReplyTo:        m.ReplyTo,
+		Updated:        time.Now().UTC(),
+	}
+	if m.SMTPHost != "" {
+		e.SMTPHost = m.SMTPHost
+		e.SMTPPort = m.SMTPPort
+		e.SMTPTLSMode = m.SMTPTLSMode
+		e.SMTPInsecureSkipVerify = m.SMTPInsecureSkipVerify
+		e.SenderAddress = m.SenderAddress
+	}
+	if m.SMTPHost != "" && m.SMTPAuth {
+		e.SMTPAuth = true
+		e.SMTPAuthMethod = m.SMTPAuthMethod
+		e.SMTPAuthUser = m.SMTPAuthUser
+		if m.SMTPAuthPass != "" {
+			e.SMTPAuthPassEncrypted, err = EncryptString(m.SMTPAuthPass)
+			if err != nil {
+				log.Println(err)
+				SendInternalServerError(w)
+				return
+			}
+		} else if old != nil && router.canKeepPassword(old, &m) {
+			e.SMTPAuthPassEncrypted = old.SMTPAuthPassEncrypted
+		}
+		if e.SMTPAuthPassEncrypted == "" {
+			SendBadRequestCode(w, ResponseCodeMailPasswordRequired)
+			return
+		}
+	}
+	if err := GetMailSettingsRepository().Save(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	action := AuditActionUpdate
+	var before *GetMailSettingsResponse
+	if old != nil {
+		before = router.copyToRestModel(old)
+	} else {
+		action = AuditActionCreate
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     action,
+		EntityType: AuditEntityMailSettings,
+		EntityID:   user.OrganizationID,
+	}, before, router.copyToRestModel(e))
+	SendUpdated(w)
+}
+
+// delete removes the organization's settings so that the global mail service
+// is used again.
+func (router *MailSettingsRouter) delete(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	e, err := GetMailSettingsRepository().Get(user.OrganizationID)
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	if err := GetMailSettingsRepository().Delete(user.OrganizationID); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionDelete,
+		EntityType: AuditEntityMailSettings,
+		EntityID:   user.OrganizationID,
+	}, router.copyToRestModel(e), nil)
+	SendUpdated(w)
+}
+
+// sendTestMail sends a mail to the requesting user using the saved settings.
+// The mail is sent right away instead of being queued so that errors can be
+// returned to the caller.
+func (router *MailSettingsRouter) sendTestMail(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	vars := map[string]string{
+		"recipientName": user.GetSafeRecipientName(),
+		"orgName":       org.Name,
+	}
+	m, err := RenderEmail(&MailAddress{Address: user.Email}, GetEmailTemplatePathTestMail(), getUserMailLanguage(user, org), vars, nil, org.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := &SendTestMailResponse{
+		Recipient: user.Email,
+		Success:   true,
+	}
+	if err := DeliverEmail(m); err != nil {
+		res.Success = false
+		res.Error = err.Error()
+	}
+	SendJSON(w, res)
+}
+
+// canKeepPassword returns true if the stored password may be used with the
+// new settings. Otherwise the password could be sent to a different server,
+// e.g. by calling sendTestMail after changing the host.
+func (router *MailSettingsRouter) canKeepPassword(old *MailSettings, m *SetMailSettingsRequest) bool {
+	return strings.EqualFold(old.SMTPHost, m.SMTPHost) &&
+		old.SMTPPort == m.SMTPPort &&
+		old.SMTPTLSMode == m.SMTPTLSMode &&
+		old.SMTPAuthUser == m.SMTPAuthUser &&
+		(old.SMTPInsecureSkipVerify || !m.SMTPInsecureSkipVerify)
+}
+
+func (router *MailSettingsRouter) isValidRequest(m *SetMailSettingsRequest) bool {
+	if m.SenderName != "" && ValidateDisplayName(m.SenderName) != nil {
+		return false
+	}
+	if m.ReplyTo != "" && ValidateEmailAddress(m.ReplyTo) != nil {
+		return false
+	}
+	if m.SMTPHost == "" {
+		// The sender address can only be changed when using an own SMTP server
+		return m.SenderAddress == ""
+	}
+	if strings.ContainsAny(m.SMTPHost, " :/\\@\r\n") {
+		return false
+	}
+	if m.SMTPPort < 1 || !slices.Contains(mailSettingsTLSModes, m.SMTPTLSMode) {
+		return false
+	}
+	if ValidateEmailAddress(m.SenderAddress) != nil {
+		return false
+	}
+	if m.SMTPAuth {
+		if m.SMTPAuthUser == "" || (m.SMTPAuthMethod != "PLAIN" && m.SMTPAuthMethod != "LOGIN") {
+			return false
+		}
+	}
+	return true
+}
+
+func (router *MailSettingsRouter) copyToRestModel(e *MailSettings) *GetMailSettingsResponse {
+	return &GetMailSettingsResponse{
+		SMTPHost:               e.SMTPHost,
+		SMTPPort:               e.SMTPPort,
+		SMTPTLSMode:            e.SMTPTLSMode,
+		SMTPInsecureSkipVerify: e.SMTPInsecureSkipVerify,
+		SMTPAuth:               e.SMTPAuth,
+		SMTPAuthMethod:         e.SMTPAuthMethod,
+		SMTPAuthUser:           e.SMTPAuthUser,
+		SMTPAuthPassSet:        e.SMTPAuthPassEncrypted != "",
+		SenderAddress:          e.SenderAddress,
+		SenderName:             e.SenderName,
+		ReplyTo:                e.ReplyTo,
+		Updated:                e.Updated,
+	}
+}
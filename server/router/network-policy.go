@@ -0,0 +1,110 @@
+package router
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"slices"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+// isNetworkListSetting returns true if the setting holds a list of networks
+// in CIDR notation.
+func isNetworkListSetting(name string) bool {
+	return name == SettingNetworkAllowlistUsers.Name ||
+		name == SettingNetworkAllowlistSpaceAdmins.Name ||
+		name == SettingNetworkAllowlistOrgAdmins.Name ||
+		name == SettingNetworkAllowlistServiceAccount.Name ||
+		name == SettingTrustedNetworks.Name
+}
+
+// getNetworkAllowlistSetting returns the name of the setting holding the
+// networks the user may authenticate from. Users holding admin permissions
+// through custom roles or location admin assignments are treated like the
+// corresponding built-in admins.
+func getNetworkAllowlistSetting(user *User) string {
+	switch user.Role {
+	case UserRoleServiceAccountRO, UserRoleServiceAccountRW:
+		return SettingNetworkAllowlistServiceAccount.Name
+	case UserRoleOrgAdmin, UserRoleSuperAdmin:
+		return SettingNetworkAllowlistOrgAdmins.Name
+	}
+	permissions := GetUserPermissions(user)
+	for _, p := range permissions {
+		if !slices.Contains(spaceAdminPermissions, p) {
+			return SettingNetworkAllowlistOrgAdmins.Name
+		}
+	}
+	if len(permissions) > 0 {
+		return SettingNetworkAllowlistSpaceAdmins.Name
+	}
+	return SettingNetworkAllowlistUsers.Name
+}
+
+// getOrgNetworks returns the networks configured in the setting. The boolean
+// is false if the stored value can not be parsed.
+func getOrgNetworks(organizationID, settingName string) ([]*net.IPNet, bool) {
+	value, _ := GetSettingsRepository().Get(organizationID, settingName)
+	networks, err := ParseNetworkList(value)
+	if err != nil {
+		log.Println("Error parsing setting " + settingName + " of org " + organizationID + ": " + err.Error())
+		return nil, false
+	}
+	return networks, true
+}
+
+// isNetworkAllowedForUser checks the client IP address against the network
+// allowlist for the user's permissions. An empty allowlist allows all networks.
+func isNetworkAllowedForUser(user *User, ip string) bool {
+	networks, ok := getOrgNetworks(user.OrganizationID, getNetworkAllowlistSetting(user))
+	if !ok {
+		return false
+	}
+	if len(networks) == 0 {
+		return true
+	}
+	return IsIPInNetworks(ip, networks)
+}
+
+// isSecondFactorRequiredForNetwork returns true if the organization requires
+// a second factor for logins from networks not listed as trusted.
+func isSecondFactorRequiredForNetwork(user *User, ip string) bool {
+	required, _ := GetSettingsRepository().GetBool(user.OrganizationID, SettingRequire2FAUntrustedNetworks.Name)
+	if !required {
+		return false
+	}
+	networks, ok := getOrgNetworks(user.OrganizationID, SettingTrustedNetworks.Name)
+	if !ok {
+		return true
+	}
+	return !IsIPInNetworks(ip, networks)
+}
+
+// checkNetworkPolicy denies the request if the client's network is not
+// allowed for the user. On denial, the event is recorded and the error
+// response has already been sent.
+func checkNetworkPolicy(w http.ResponseWriter, r *http.Request, user *User, authMethod string) bool {
+	ip := GetClientIP(r)
+	if isNetworkAllowedForUser(user, ip) {
+		return true
+	}
+	recordAuthEvent(r, &AuthEvent{User: user, Method: authMethod, ErrorCode: AuthErrorNetworkNotAllowed, ErrorDetail: "client IP " + ip})
+	SendForbiddenCode(w, ResponseCodeNetworkNotAllowed)
+	return false
+}
+
+// isNetworkPolicyLockout returns true if applying the setting would deny the
+// requesting admin's own network.
+func isNetworkPolicyLockout(r *http.Request, user *User, name, value string) bool {
+	if value == "" || name != getNetworkAllowlistSetting(user) {
+		return false
+	}
+	networks, err := ParseNetworkList(value)
+	if err != nil || len(networks) == 0 {
+		return false
+	}
+	return !IsIPInNetworks(GetClientIP(r), networks)
+}
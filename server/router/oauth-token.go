@@ -0,0 +1,120 @@
+package router
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+const (
+	OAuthGrantTypeClientCredentials = "client_credentials"
+
+	OAuthErrorInvalidRequest       = "invalid_request"
+	OAuthErrorInvalidClient        = "invalid_client"
+	OAuthErrorUnsupportedGrantType = "unsupported_grant_type"
+)
+
+// OAuthTokenResponse is the successful response of the token endpoint (RFC 6749, section 5.1).
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// OAuthErrorResponse is the error response of the token endpoint (RFC 6749, section 5.2).
+type OAuthErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (router *AuthRouter) sendOAuthError(w http.ResponseWriter, statusCode int, code string) {
+	if statusCode == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="seatsurfing"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	SendJSON(w, &OAuthErrorResponse{Error: code})
+}
+
+// issueOAuthToken implements the client credentials grant (RFC 6749, section
+// 4.4) for service accounts. The client ID is "{orgID}_{email}" and the
+// client secret is the password of the service account. The issued access
+// token is not bound to a session, so no refresh token is issued.
+func (router *AuthRouter) issueOAuthToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		router.sendOAuthError(w, http.StatusBadRequest, OAuthErrorInvalidRequest)
+		return
+	}
+	grantType := r.PostForm.Get("grant_type")
+	if grantType == "" {
+		router.sendOAuthError(w, http.StatusBadRequest, OAuthErrorInvalidRequest)
+		return
+	}
+	if grantType != OAuthGrantTypeClientCredentials {
+		router.sendOAuthError(w, http.StatusBadRequest, OAuthErrorUnsupportedGrantType)
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+	if len(clientID) < 36+2 || strings.Index(clientID, "_") != 36 || clientSecret == "" {
+		router.sendOAuthError(w, http.StatusUnauthorized, OAuthErrorInvalidClient)
+		return
+	}
+	organizationID := clientID[:36]
+	email := clientID[37:]
+	user, err := GetUserRepository().GetByEmail(organizationID, email)
+	if err == sql.ErrNoRows || (err == nil && user == nil) {
+		recordAuthEvent(r, &AuthEvent{OrganizationID: organizationID, Email: email, Method: AuthMethodClientCredentials, ErrorCode: AuthErrorUserNotFound})
+		router.sendOAuthError(w, http.StatusUnauthorized, OAuthErrorInvalidClient)
+		return
+	}
+	if err != nil {
+		recordAuthEvent(r, &AuthEvent{OrganizationID: organizationID, Email: email, Method: AuthMethodClientCredentials, ErrorCode: AuthErrorInternal, ErrorDetail: err.Error()})
+		SendInternalServerError(w)
+		return
+	}
+	if user.Role != UserRoleServiceAccountRO && user.Role != UserRoleServiceAccountRW {
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodClientCredentials, ErrorCode: AuthErrorNoServiceAccount})
+		router.sendOAuthError(w, http.StatusUnauthorized, OAuthErrorInvalidClient)
+		return
+	}
+	if user.HashedPassword == "" {
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodClientCredentials, ErrorCode: AuthErrorNoPasswordSet})
+		router.sendOAuthError(w, http.StatusUnauthorized, OAuthErrorInvalidClient)
+		return
+	}
+	if user.Disabled {
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodClientCredentials, ErrorCode: AuthErrorUserDisabled})
+		router.sendOAuthError(w, http.StatusUnauthorized, OAuthErrorInvalidClient)
+		return
+	}
+	if !GetUserRepository().CheckPassword(string(user.HashedPassword), clientSecret) {
+		recordAuthEvent(r, &AuthEvent{User: user, Method: AuthMethodClientCredentials, ErrorCode: AuthErrorWrongPassword, BanCheck: true})
+		router.sendOAuthError(w, http.StatusUnauthorized, OAuthErrorInvalidClient)
+		return
+	}
+	recordAuthEvent(r, &AuthEvent{User: user, Successful: true, Method: AuthMethodClientCredentials})
+	now := time.Now().UTC()
+	user.LastActivityAtUTC = &now
+	GetUserRepository().Update(user)
+	claims := router.CreateClaims(user, &Session{})
+	accessToken := router.CreateAccessToken(claims)
+	if accessToken == "" {
+		SendInternalServerError(w)
+		return
+	}
+	res := &OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(AccessTokenLifetime.Seconds()),
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	SendJSON(w, res)
+}
@@ -239,6 +239,13 @@ func (router *OrganizationRouter) addDomain(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	router.ensureOrgHasPrimaryDomain(e, domainName)
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionCreate,
+		EntityType:     AuditEntityDomain,
+		EntityID:       domainName,
+		EntityName:     domainName,
+		OrganizationID: e.ID,
+	}, nil, map[string]any{"domain": domainName})
 	SendCreated(w, domainName)
 }
 
@@ -327,6 +334,13 @@ func (router *OrganizationRouter) verifyDomain(w http.ResponseWriter, r *http.Re
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionVerify,
+		EntityType:     AuditEntityDomain,
+		EntityID:       domain.DomainName,
+		EntityName:     domain.DomainName,
+		OrganizationID: e.ID,
+	}, map[string]any{"active": false}, map[string]any{"active": true})
 	SendUpdated(w)
 }
 
@@ -354,6 +368,13 @@ func (router *OrganizationRouter) setPrimaryDomain(w http.ResponseWriter, r *htt
 		return
 	}
 	GetOrganizationRepository().SetPrimaryDomain(e, vars["domain"])
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityDomain,
+		EntityID:       domain.DomainName,
+		EntityName:     domain.DomainName,
+		OrganizationID: e.ID,
+	}, map[string]any{"primary": domain.Primary}, map[string]any{"primary": true})
 	SendUpdated(w)
 }
 
@@ -392,6 +413,13 @@ func (router *OrganizationRouter) removeDomain(w http.ResponseWriter, r *http.Re
 		return
 	}
 	router.ensureOrgHasPrimaryDomain(org, "")
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntityDomain,
+		EntityID:       vars["domain"],
+		EntityName:     vars["domain"],
+		OrganizationID: org.ID,
+	}, map[string]any{"domain": vars["domain"]}, nil)
 	SendUpdated(w)
 }
 
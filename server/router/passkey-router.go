@@ -384,6 +384,7 @@ func (router *UserRouter) finishPasskeyRegistration(w http.ResponseWriter, r *ht
 		SendInternalServerError(w)
 		return
 	}
+	SendSecurityNotification(user, SecurityEventPasskeyAdded, passkey.Name)
 	SendJSON(w, &PasskeyListItemResponse{
 		ID:        passkey.ID,
 		Name:      passkey.Name,
@@ -461,6 +462,7 @@ func (router *UserRouter) deletePasskey(w http.ResponseWriter, r *http.Request)
 		SendInternalServerError(w)
 		return
 	}
+	SendSecurityNotification(user, SecurityEventPasskeyRemoved, pk.Name)
 	SendUpdated(w)
 }
 
@@ -642,6 +644,10 @@ func (router *AuthRouter) finishPasskeyLogin(w http.ResponseWriter, r *http.Requ
 		SendUnauthorized(w)
 		return
 	}
+	// Passkeys count as a second factor, so only the network allowlist applies
+	if !checkNetworkPolicy(w, r, matchedUser, AuthMethodPasskey) {
+		return
+	}
 
 	// Clone detection (spec §7.5): if stored signCount > 0 and the returned
 	// signCount is not greater, the credential may have been cloned.
@@ -663,6 +669,7 @@ func (router *AuthRouter) finishPasskeyLogin(w http.ResponseWriter, r *http.Requ
 	}
 
 	recordAuthEvent(r, &AuthEvent{User: matchedUser, Successful: true, Method: AuthMethodPasskey, BanCheck: true})
+	recordLogin(r, matchedUser)
 	now := time.Now().UTC()
 	matchedUser.LastActivityAtUTC = &now
 	GetUserRepository().Update(matchedUser)
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"log"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type PasswordPolicy struct {
+	MinLength      int
+	MinCharClasses int
+	History        int
+	MaxAgeDays     int
+	CheckBreached  bool
+}
+
+// GetPasswordPolicy returns the password policy of the organization. Missing
+// settings fall back to the defaults of ValidatePassword().
+func GetPasswordPolicy(organizationID string) *PasswordPolicy {
+	policy := &PasswordPolicy{
+		MinLength:      8,
+		MinCharClasses: 4,
+	}
+	if v, err := GetSettingsRepository().GetInt(organizationID, SettingPasswordMinLength.Name); err == nil && v >= 8 {
+		policy.MinLength = v
+	}
+	if v, err := GetSettingsRepository().GetInt(organizationID, SettingPasswordMinCharClasses.Name); err == nil && v >= 1 {
+		policy.MinCharClasses = v
+	}
+	if v, err := GetSettingsRepository().GetInt(organizationID, SettingPasswordHistory.Name); err == nil {
+		policy.History = min(max(v, 0), MaxPasswordHistoryEntries)
+	}
+	if v, err := GetSettingsRepository().GetInt(organizationID, SettingPasswordMaxAgeDays.Name); err == nil {
+		policy.MaxAgeDays = max(v, 0)
+	}
+	policy.CheckBreached, _ = GetSettingsRepository().GetBool(organizationID, SettingPasswordCheckBreached.Name)
+	return policy
+}
+
+// checkPasswordPolicy validates a new password for the user against the
+// policy of the user's organization. Returns 0 if the password is acceptable
+// or the response code describing the violation.
+func checkPasswordPolicy(user *User, password string) int {
+	policy := GetPasswordPolicy(user.OrganizationID)
+	if !ValidatePasswordStrength(password, policy.MinLength, policy.MinCharClasses) {
+		return ResponseCodePasswordPolicyViolation
+	}
+	if policy.CheckBreached {
+		breached, err := IsPasswordBreached(password)
+		if err != nil {
+			log.Println("Error checking breached passwords: " + err.Error())
+		}
+		if breached {
+			return ResponseCodePasswordBreached
+		}
+	}
+	// Users who are about to be created don't have a password history yet
+	if policy.History > 0 && user.ID != "" {
+		if user.HashedPassword != "" && GetUserRepository().CheckPassword(string(user.HashedPassword), password) {
+			return ResponseCodePasswordReused
+		}
+		history, err := GetPasswordHistoryRepository().GetRecentByUserID(user.ID, policy.History)
+		if err != nil {
+			log.Println(err)
+		}
+		for _, e := range history {
+			if GetUserRepository().CheckPassword(e.HashedPassword, password) {
+				return ResponseCodePasswordReused
+			}
+		}
+	}
+	return 0
+}
+
+// setUserPassword sets the user's new password and records it in the
+// password history. The caller is responsible for persisting the user.
+func setUserPassword(user *User, password string) {
+	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(password))
+	recordPasswordHistory(user)
+}
+
+func recordPasswordHistory(user *User) {
+	e := &PasswordHistoryEntry{
+		UserID:         user.ID,
+		HashedPassword: string(user.HashedPassword),
+		Created:        time.Now().UTC(),
+	}
+	if err := GetPasswordHistoryRepository().Create(e); err != nil {
+		log.Println("Error recording password history: " + err.Error())
+	}
+}
+
+// isPasswordExpired checks if the user's password is older than the maximum
+// age allowed by the organization's password policy. For passwords set
+// before the history was recorded, the age is counted from now on.
+func isPasswordExpired(user *User) bool {
+	policy := GetPasswordPolicy(user.OrganizationID)
+	if policy.MaxAgeDays == 0 {
+		return false
+	}
+	lastChanged, err := GetPasswordHistoryRepository().GetLastChanged(user.ID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	if lastChanged == nil {
+		recordPasswordHistory(user)
+		return false
+	}
+	return lastChanged.Before(time.Now().UTC().AddDate(0, 0, -policy.MaxAgeDays))
+}
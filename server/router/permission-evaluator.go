@@ -0,0 +1,216 @@
+package router
+
+import (
+	"log"
+	"slices"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+// spaceAdminPermissions are the permissions implied by the built-in space
+// admin role and by location admin assignments.
+var spaceAdminPermissions = []Permission{
+	PermissionManageSpaces,
+	PermissionManageBookings,
+	PermissionApproveBookings,
+	PermissionViewReports,
+}
+
+// getAdminLocationIDs returns the locations the user has been assigned as
+// location admin.
+func getAdminLocationIDs(user *User) []string {
+	locationIDs, err := GetLocationRepository().GetAdminLocationIDs(user.ID)
+	if err != nil {
+		log.Println(err)
+		return []string{}
+	}
+	return locationIDs
+}
+
+// getBuiltInPermissions returns the organization-wide permissions implied by
+// a user's built-in role. Space admins who have been assigned to specific
+// locations only hold these permissions for those locations.
+func getBuiltInPermissions(user *User, adminLocationIDs []string) []Permission {
+	if GetUserRepository().IsOrgAdmin(user) {
+		return AllPermissions
+	}
+	if GetUserRepository().IsSpaceAdmin(user) && len(adminLocationIDs) == 0 {
+		return spaceAdminPermissions
+	}
+	return []Permission{}
+}
+
+func hasBuiltInPermission(user *User, adminLocationIDs []string, permission Permission) bool {
+	for _, p := range getBuiltInPermissions(user, adminLocationIDs) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// getPermissionGrants returns the permissions the user holds through custom
+// roles and location admin assignments.
+func getPermissionGrants(user *User, adminLocationIDs []string) []*PermissionGrant {
+	grants, err := GetRoleRepository().GetPermissionGrants(user.ID)
+	if err != nil {
+		log.Println(err)
+		grants = []*PermissionGrant{}
+	}
+	for _, locationID := range adminLocationIDs {
+		for _, p := range spaceAdminPermissions {
+			grants = append(grants, &PermissionGrant{
+				Permission: p,
+				LocationID: locationID,
+			})
+		}
+	}
+	return grants
+}
+
+// HasPermission returns true if the user holds the permission for the whole
+// organization, either through the built-in role or an unscoped custom role.
+func HasPermission(user *User, organizationID string, permission Permission) bool {
+	return HasLocationPermission(user, organizationID, permission, "")
+}
+
+// HasLocationPermission returns true if the user holds the permission for the
+// specified location. Organization-wide grants apply to every location. An
+// empty locationID only matches organization-wide grants.
+func HasLocationPermission(user *User, organizationID string, permission Permission, locationID string) bool {
+	if GetUserRepository().IsSuperAdmin(user) {
+		return true
+	}
+	if user.OrganizationID != organizationID {
+		return false
+	}
+	if GetUserRepository().IsOrgAdmin(user) {
+		return true
+	}
+	adminLocationIDs := getAdminLocationIDs(user)
+	if hasBuiltInPermission(user, adminLocationIDs, permission) {
+		return true
+	}
+	for _, grant := range getPermissionGrants(user, adminLocationIDs) {
+		if grant.Permission != permission {
+			continue
+		}
+		if grant.LocationID == "" || (locationID != "" && grant.LocationID == locationID) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermissionInAnyLocation returns true if the user holds the permission
+// organization-wide or for at least one location.
+func HasPermissionInAnyLocation(user *User, organizationID string, permission Permission) bool {
+	if HasPermission(user, organizationID, permission) {
+		return true
+	}
+	if user.OrganizationID != organizationID {
+		return false
+	}
+	for _, grant := range getPermissionGrants(user, getAdminLocationIDs(user)) {
+		if grant.Permission == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPermittedLocationIDs returns the locations for which the user holds the
+// permission. If the permission applies organization-wide, all is true and
+// no location IDs are returned.
+func GetPermittedLocationIDs(user *User, organizationID string, permission Permission) (all bool, locationIDs []string) {
+	if HasPermission(user, organizationID, permission) {
+		return true, nil
+	}
+	locationIDs = []string{}
+	if user.OrganizationID != organizationID {
+		return false, locationIDs
+	}
+	for _, grant := range getPermissionGrants(user, getAdminLocationIDs(user)) {
+		if grant.Permission == permission && !slices.Contains(locationIDs, grant.LocationID) {
+			locationIDs = append(locationIDs, grant.LocationID)
+		}
+	}
+	return false, locationIDs
+}
+
+// HasAnyPermission returns true if the user holds at least one of the
+// permissions organization-wide.
+func HasAnyPermission(user *User, organizationID string, permissions ...Permission) bool {
+	for _, p := range permissions {
+		if HasPermission(user, organizationID, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserPermissions returns all permissions the user holds in their own
+// organization, regardless of location scope.
+func GetUserPermissions(user *User) []Permission {
+	if GetUserRepository().IsSuperAdmin(user) {
+		return AllPermissions
+	}
+	found := map[Permission]bool{}
+	adminLocationIDs := getAdminLocationIDs(user)
+	for _, p := range getBuiltInPermissions(user, adminLocationIDs) {
+		found[p] = true
+	}
+	if len(found) < len(AllPermissions) {
+		for _, grant := range getPermissionGrants(user, adminLocationIDs) {
+			found[grant.Permission] = true
+		}
+	}
+	res := []Permission{}
+	for _, p := range AllPermissions {
+		if found[p] {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// canManageUser returns true if the request user may modify the target user.
+// Only organization admins may modify admins and service accounts; users who
+// manage users through a custom role are limited to regular users without
+// custom roles or location admin assignments, so they can't take over
+// accounts holding further permissions.
+func canManageUser(requestUser *User, target *User) bool {
+	if CanAdminOrg(requestUser, target.OrganizationID) {
+		return true
+	}
+	if !HasPermission(requestUser, target.OrganizationID, PermissionManageUsers) {
+		return false
+	}
+	if target.Role != UserRoleUser {
+		return false
+	}
+	assigned, err := GetRoleRepository().IsUserAssigned(target.ID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	if assigned {
+		return false
+	}
+	adminLocationIDs, err := GetLocationRepository().GetAdminLocationIDs(target.ID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return len(adminLocationIDs) == 0
+}
+
+// canAssignUserRole returns true if the request user may grant the specified
+// built-in role.
+func canAssignUserRole(requestUser *User, role UserRole) bool {
+	if CanAdminOrg(requestUser, requestUser.OrganizationID) {
+		return role <= requestUser.Role || isServiceAccountRole(int(role))
+	}
+	return role == UserRoleUser
+}
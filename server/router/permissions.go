@@ -36,3 +36,16 @@ func CanUpdatePassword(user *User) bool {
 	}
 	return CanResetPassword(user)
 }
+
+func CanMagicLinkLogin(user *User) bool {
+	if user.AuthProviderID != "" {
+		return false
+	}
+	if user.Disabled {
+		return false
+	}
+	if user.Role == UserRoleServiceAccountRO || user.Role == UserRoleServiceAccountRW {
+		return false
+	}
+	return true
+}
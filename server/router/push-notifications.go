@@ -0,0 +1,136 @@
+package router
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"log"
+	"sync"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+var vapidKey *ecdsa.PrivateKey
+var vapidKeyMu sync.Mutex
+
+// GetVapidKey returns the key identifying this installation to push services.
+// If VAPID_PRIVATE_KEY is not set, a key is generated once and stored
+// encrypted in the database, as browsers bind subscriptions to the key.
+func GetVapidKey() (*ecdsa.PrivateKey, error) {
+	vapidKeyMu.Lock()
+	defer vapidKeyMu.Unlock()
+	if vapidKey != nil {
+		return vapidKey, nil
+	}
+	if GetConfig().VapidPrivateKey != "" {
+		key, err := ParseVapidPrivateKey(GetConfig().VapidPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		vapidKey = key
+		return vapidKey, nil
+	}
+	value, err := GetSettingsRepository().GetGlobalString(SettingVapidPrivateKey.Name)
+	if err == nil && value != "" {
+		decrypted, err := DecryptString(value)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseVapidPrivateKey(decrypted)
+		if err != nil {
+			return nil, err
+		}
+		vapidKey = key
+		return vapidKey, nil
+	}
+	generated, err := GenerateVapidPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := EncryptString(generated)
+	if err != nil {
+		return nil, err
+	}
+	if err := GetSettingsRepository().SetGlobal(SettingVapidPrivateKey.Name, encrypted); err != nil {
+		return nil, err
+	}
+	key, err := ParseVapidPrivateKey(generated)
+	if err != nil {
+		return nil, err
+	}
+	vapidKey = key
+	return vapidKey, nil
+}
+
+// SendUserPushNotification sends a message rendered from an email template to
+// all devices the user has enabled push notifications on. Subscriptions the
+// push service reports as expired are removed. It returns false if no message
+// could be delivered.
+func SendUserPushNotification(userID, templateFile, language string, vars map[string]string) bool {
+	subscriptions, err := GetPushSubscriptionRepository().GetAllByUserID(userID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	if len(subscriptions) == 0 {
+		return false
+	}
+	key, err := GetVapidKey()
+	if err != nil {
+		log.Println("Error loading VAPID key: " + err.Error())
+		return false
+	}
+	m, err := RenderChatMessage(templateFile, language, vars)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	sent := false
+	for _, e := range subscriptions {
+		channel := &WebPushChannel{
+			Subscription: &WebPushSubscription{Endpoint: e.Endpoint, P256dh: e.P256dh, Auth: e.Auth},
+			VapidKey:     key,
+			Subject:      GetConfig().VapidSubject,
+		}
+		err := channel.Send(m)
+		if err == nil {
+			sent = true
+			continue
+		}
+		if errors.Is(err, ErrWebPushSubscriptionGone) {
+			if err := GetPushSubscriptionRepository().Delete(e); err != nil {
+				log.Println(err)
+			}
+			continue
+		}
+		log.Println("Error sending push notification to user " + userID + ": " + err.Error())
+	}
+	return sent
+}
+
+// sendPushNotification informs the booking's user via push about the result
+// of an approval.
+func (router *BookingRouter) sendPushNotification(e *Booking, notification BookingMailNotification) {
+	active, err := GetUserPreferencesRepository().GetBool(e.UserID, PreferencePushNotifications.Name)
+	if err != nil || !active {
+		return
+	}
+	user, err := GetUserRepository().GetOne(e.UserID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	vars, err := router.getBookingNotificationVars(e, user, org)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	SendUserPushNotification(user.ID, router.getBookingNotificationTemplate(notification), getUserMailLanguage(user, org), vars)
+}
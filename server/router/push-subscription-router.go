@@ -0,0 +1,147 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const maxPushSubscriptionsPerUser = 20
+
+type PushSubscriptionRouter struct {
+}
+
+type CreatePushSubscriptionKeys struct {
+	P256dh string `json:"p256dh" validate:"required"`
+	Auth   string `json:"auth" validate:"required"`
+}
+
+type CreatePushSubscriptionRequest struct {
+	Endpoint  string                     `json:"endpoint" validate:"required"`
+	Keys      CreatePushSubscriptionKeys `json:"keys"`
+	UserAgent string                     `json:"userAgent" validate:"max=255"`
+}
+
+type GetPushSubscriptionResponse struct {
+	ID        string    `json:"id"`
+	Endpoint  string    `json:"endpoint"`
+	UserAgent string    `json:"userAgent"`
+	Created   time.Time `json:"created"`
+}
+
+type GetVapidPublicKeyResponse struct {
+	PublicKey string `json:"publicKey"`
+}
+
+func (router *PushSubscriptionRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/vapid-key", router.getVapidPublicKey).Methods("GET")
+	s.HandleFunc("/{id}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.create).Methods("POST")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+func (router *PushSubscriptionRouter) getVapidPublicKey(w http.ResponseWriter, r *http.Request) {
+	key, err := GetVapidKey()
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	publicKey, err := GetVapidPublicKey(key)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendJSON(w, &GetVapidPublicKeyResponse{PublicKey: publicKey})
+}
+
+func (router *PushSubscriptionRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	list, err := GetPushSubscriptionRepository().GetAllByUserID(GetRequestUserID(r))
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetPushSubscriptionResponse{}
+	for _, e := range list {
+		res = append(res, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+func (router *PushSubscriptionRouter) create(w http.ResponseWriter, r *http.Request) {
+	var m CreatePushSubscriptionRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	if !ValidateWebPushEndpoint(m.Endpoint) || !ValidateWebPushKeys(m.Keys.P256dh, m.Keys.Auth) {
+		SendBadRequest(w)
+		return
+	}
+	requestUser := GetRequestUser(r)
+	existing, err := GetPushSubscriptionRepository().GetAllByUserID(requestUser.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	known := false
+	for _, e := range existing {
+		if e.Endpoint == m.Endpoint {
+			known = true
+		}
+	}
+	if !known && len(existing) >= maxPushSubscriptionsPerUser {
+		SendBadRequest(w)
+		return
+	}
+	e := &PushSubscription{
+		UserID:    requestUser.ID,
+		Endpoint:  m.Endpoint,
+		P256dh:    m.Keys.P256dh,
+		Auth:      m.Keys.Auth,
+		UserAgent: m.UserAgent,
+		Created:   time.Now().UTC(),
+	}
+	if err := GetPushSubscriptionRepository().Create(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendCreated(w, e.ID)
+}
+
+func (router *PushSubscriptionRouter) delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	e, err := GetPushSubscriptionRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	if e.UserID != GetRequestUserID(r) {
+		SendForbidden(w)
+		return
+	}
+	if err := GetPushSubscriptionRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	SendUpdated(w)
+}
+
+func (router *PushSubscriptionRouter) copyToRestModel(e *PushSubscription) *GetPushSubscriptionResponse {
+	m := &GetPushSubscriptionResponse{}
+	m.ID = e.ID
+	m.Endpoint = e.Endpoint
+	m.UserAgent = e.UserAgent
+	m.Created = e.Created
+	return m
+}
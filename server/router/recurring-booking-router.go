@@ -28,6 +28,8 @@ type CreateRecurringBookingRequest struct {
 	Cadence  Cadence        `json:"cadence" validate:"required,min=1,max=2"`
 	Cycle    int            `json:"cycle" validate:"required,min=1"`
 	Weekdays []time.Weekday `json:"weekdays" validate:"dive,min=0,max=6"`
+	// UserEmail allows delegates to book on behalf of another user
+	UserEmail string `json:"userEmail"`
 }
 
 type CreateRecurringBookingResponse struct {
@@ -140,7 +142,7 @@ func (router *RecurringBookingRouter) getOne(w http.ResponseWriter, r *http.Requ
 		SendForbidden(w)
 		return
 	}
-	if e.UserID != GetRequestUserID(r) && !CanSpaceAdminOrg(requestUser, location.OrganizationID) {
+	if e.UserID != GetRequestUserID(r) && !HasLocationPermission(requestUser, location.OrganizationID, PermissionManageBookings, location.ID) && !isDelegateOf(requestUser, e.UserID) {
 		SendForbidden(w)
 		return
 	}
@@ -169,7 +171,7 @@ func (router *RecurringBookingRouter) delete(w http.ResponseWriter, r *http.Requ
 		SendForbidden(w)
 		return
 	}
-	if (e.UserID != GetRequestUserID(r)) && !CanSpaceAdminOrg(GetRequestUser(r), location.OrganizationID) {
+	if (e.UserID != GetRequestUserID(r)) && !HasLocationPermission(GetRequestUser(r), location.OrganizationID, PermissionManageBookings, location.ID) && !isDelegateOf(GetRequestUser(r), e.UserID) {
 		SendForbidden(w)
 		return
 	}
@@ -233,6 +235,16 @@ func (router *RecurringBookingRouter) create(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	e.UserID = GetRequestUserID(r)
+	bookingUser := requestUser
+	if m.UserEmail != "" && m.UserEmail != requestUser.Email {
+		principal := getDelegationPrincipal(requestUser, m.UserEmail)
+		if principal == nil {
+			SendForbidden(w)
+			return
+		}
+		e.UserID = principal.ID
+		bookingUser = principal
+	}
 	if err := GetRecurringBookingRepository().Create(e); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
@@ -255,7 +267,7 @@ func (router *RecurringBookingRouter) create(w http.ResponseWriter, r *http.Requ
 				Leave: b.Leave,
 			},
 		}
-		valid, code := bookingRouter.checkBookingCreateUpdate(bookingReq, location, requestUser, "", 0)
+		valid, code := bookingRouter.checkBookingCreateUpdate(bookingReq, location, bookingUser, "", 0)
 		if valid {
 			conflicts, _ := GetBookingRepository().GetConflicts(e.SpaceID, b.Enter, b.Leave, "")
 			if len(conflicts) > 0 {
@@ -265,6 +277,7 @@ func (router *RecurringBookingRouter) create(w http.ResponseWriter, r *http.Requ
 		}
 		if valid {
 			b.Approved = !spaceRequiresApproval
+			b.CreatedByUserID = NullUUID(requestUser.ID)
 			if err := GetBookingRepository().Create(b); err != nil {
 				log.Println(err)
 				SendInternalServerError(w)
@@ -299,7 +312,7 @@ func (router *RecurringBookingRouter) getIcal(w http.ResponseWriter, r *http.Req
 		SendForbidden(w)
 		return
 	}
-	if e.UserID != GetRequestUserID(r) && !CanSpaceAdminOrg(requestUser, requestUser.OrganizationID) {
+	if e.UserID != GetRequestUserID(r) && !HasPermission(requestUser, requestUser.OrganizationID, PermissionManageBookings) && !isDelegateOf(requestUser, e.UserID) {
 		SendForbidden(w)
 		return
 	}
@@ -341,6 +354,9 @@ func (router *RecurringBookingRouter) onBookingCreated(e *RecurringBooking, book
 	if len(bookings) == 0 {
 		return
 	}
+	for _, b := range bookings {
+		dispatchBookingWebhook(WebhookEventBookingCreated, b)
+	}
 	if !approvalRequired {
 		br := &BookingRouter{}
 		for _, b := range bookings {
@@ -364,6 +380,7 @@ func (router *RecurringBookingRouter) onBookingDeleted(e *RecurringBooking) {
 	br := &BookingRouter{}
 	for _, b := range bookings {
 		if b.Enter.After(*now) {
+			dispatchBookingWebhook(WebhookEventBookingDeleted, &b.Booking)
 			caldavClient, caldavEvent, path, err := br.initCaldavEvent(&b.Booking)
 			if err == nil {
 				if b.CalDavID != "" {
@@ -0,0 +1,349 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+type RoleRouter struct {
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=3,max=256"`
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+type GetRoleResponse struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organizationId"`
+	CreateRoleRequest
+}
+
+type CreateRoleAssignmentRequest struct {
+	UserEmail  string `json:"userEmail" validate:"omitempty,email"`
+	GroupID    string `json:"groupId" validate:"omitempty,uuid"`
+	LocationID string `json:"locationId" validate:"omitempty,uuid"`
+}
+
+type GetRoleAssignmentResponse struct {
+	ID           string `json:"id"`
+	RoleID       string `json:"roleId"`
+	UserID       string `json:"userId"`
+	UserEmail    string `json:"userEmail"`
+	GroupID      string `json:"groupId"`
+	GroupName    string `json:"groupName"`
+	LocationID   string `json:"locationId"`
+	LocationName string `json:"locationName"`
+}
+
+func (router *RoleRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/{id}/assignment/{assignmentId}", router.deleteAssignment).Methods("DELETE")
+	s.HandleFunc("/{id}/assignment", router.getAssignments).Methods("GET")
+	s.HandleFunc("/{id}/assignment", router.createAssignment).Methods("POST")
+	s.HandleFunc("/{id}", router.getOne).Methods("GET")
+	s.HandleFunc("/{id}", router.update).Methods("PUT")
+	s.HandleFunc("/{id}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.create).Methods("POST")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+func (router *RoleRouter) getOne(w http.ResponseWriter, r *http.Request) {
+	e := router.getRoleForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	SendJSON(w, router.copyToRestModel(e))
+}
+
+func (router *RoleRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !CanAdminOrg(user, user.OrganizationID) {
+		SendForbidden(w)
+		return
+	}
+	list, err := GetRoleRepository().GetAll(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetRoleResponse{}
+	for _, e := range list {
+		res = append(res, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+func (router *RoleRouter) create(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !CanAdminOrg(user, user.OrganizationID) {
+		SendForbidden(w)
+		return
+	}
+	var m CreateRoleRequest
+	if UnmarshalValidateBody(r, &m) != nil || !router.isValidRequest(&m) {
+		SendBadRequest(w)
+		return
+	}
+	e := router.copyFromRestModel(&m)
+	e.OrganizationID = user.OrganizationID
+	if router.nameExists(e) {
+		SendAlreadyExists(w)
+		return
+	}
+	if err := GetRoleRepository().Create(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityRole,
+		EntityID:   e.ID,
+		EntityName: e.Name,
+	}, nil, router.copyToRestModel(e))
+	SendCreated(w, e.ID)
+}
+
+func (router *RoleRouter) update(w http.ResponseWriter, r *http.Request) {
+	var m CreateRoleRequest
+	if UnmarshalValidateBody(r, &m) != nil || !router.isValidRequest(&m) {
+		SendBadRequest(w)
+		return
+	}
+	e := router.getRoleForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	eNew := router.copyFromRestModel(&m)
+	eNew.ID = e.ID
+	eNew.OrganizationID = e.OrganizationID
+	if router.nameExists(eNew) {
+		SendAlreadyExists(w)
+		return
+	}
+	if err := GetRoleRepository().Update(eNew); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityRole,
+		EntityID:       e.ID,
+		EntityName:     eNew.Name,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), router.copyToRestModel(eNew))
+	SendUpdated(w)
+}
+
+func (router *RoleRouter) delete(w http.ResponseWriter, r *http.Request) {
+	e := router.getRoleForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	if err := GetRoleRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntityRole,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), nil)
+	SendUpdated(w)
+}
+
+func (router *RoleRouter) getAssignments(w http.ResponseWriter, r *http.Request) {
+	e := router.getRoleForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	list, err := GetRoleRepository().GetAssignments(e.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetRoleAssignmentResponse{}
+	for _, a := range list {
+		res = append(res, &GetRoleAssignmentResponse{
+			ID:           a.ID,
+			RoleID:       a.RoleID,
+			UserID:       a.UserID,
+			UserEmail:    a.UserEmail,
+			GroupID:      a.GroupID,
+			GroupName:    a.GroupName,
+			LocationID:   a.LocationID,
+			LocationName: a.LocationName,
+		})
+	}
+	SendJSON(w, res)
+}
+
+func (router *RoleRouter) createAssignment(w http.ResponseWriter, r *http.Request) {
+	var m CreateRoleAssignmentRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	m.UserEmail = strings.TrimSpace(m.UserEmail)
+	if (m.UserEmail == "") == (m.GroupID == "") {
+		SendBadRequest(w)
+		return
+	}
+	e := router.getRoleForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	a := &RoleAssignment{
+		RoleID:     e.ID,
+		GroupID:    m.GroupID,
+		LocationID: m.LocationID,
+	}
+	if m.UserEmail != "" {
+		assignee, err := GetUserRepository().GetByEmail(e.OrganizationID, m.UserEmail)
+		if err != nil || assignee == nil {
+			SendNotFound(w)
+			return
+		}
+		a.UserID = assignee.ID
+	} else if ok, err := GetGroupRepository().GroupsExistAndBelongToOrg(e.OrganizationID, []string{m.GroupID}); err != nil || !ok {
+		SendNotFound(w)
+		return
+	}
+	if m.LocationID != "" {
+		location, err := GetLocationRepository().GetOne(m.LocationID)
+		if err != nil || location == nil || location.OrganizationID != e.OrganizationID {
+			SendNotFound(w)
+			return
+		}
+	}
+	existing, err := GetRoleRepository().GetAssignments(e.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	for _, other := range existing {
+		if other.UserID == a.UserID && other.GroupID == a.GroupID && other.LocationID == a.LocationID {
+			SendAlreadyExists(w)
+			return
+		}
+	}
+	if err := GetRoleRepository().CreateAssignment(a); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityRole,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, nil, map[string]string{"userEmail": m.UserEmail, "groupId": m.GroupID, "locationId": m.LocationID})
+	SendCreated(w, a.ID)
+}
+
+func (router *RoleRouter) deleteAssignment(w http.ResponseWriter, r *http.Request) {
+	e := router.getRoleForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	vars := mux.Vars(r)
+	a, err := GetRoleRepository().GetAssignment(vars["assignmentId"])
+	if err != nil || a.RoleID != e.ID {
+		SendNotFound(w)
+		return
+	}
+	if err := GetRoleRepository().DeleteAssignment(a); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityRole,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, map[string]string{"userId": a.UserID, "groupId": a.GroupID, "locationId": a.LocationID}, nil)
+	SendUpdated(w)
+}
+
+// getRoleForAdmin loads the role referenced in the request path and ensures
+// the request user is an admin of the role's organization. On failure, the
+// error response has already been sent and nil is returned.
+func (router *RoleRouter) getRoleForAdmin(w http.ResponseWriter, r *http.Request) *Role {
+	vars := mux.Vars(r)
+	e, err := GetRoleRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return nil
+	}
+	if !CanAdminOrg(GetRequestUser(r), e.OrganizationID) {
+		SendForbidden(w)
+		return nil
+	}
+	return e
+}
+
+func (router *RoleRouter) isValidRequest(m *CreateRoleRequest) bool {
+	for _, p := range m.Permissions {
+		if !IsValidPermission(Permission(p)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (router *RoleRouter) nameExists(e *Role) bool {
+	list, err := GetRoleRepository().GetAll(e.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	for _, other := range list {
+		if other.ID != e.ID && strings.EqualFold(other.Name, e.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (router *RoleRouter) copyFromRestModel(m *CreateRoleRequest) *Role {
+	e := &Role{}
+	e.Name = m.Name
+	e.Permissions = []Permission{}
+	for _, p := range AllPermissions {
+		for _, requested := range m.Permissions {
+			if string(p) == requested {
+				e.Permissions = append(e.Permissions, p)
+				break
+			}
+		}
+	}
+	return e
+}
+
+func (router *RoleRouter) copyToRestModel(e *Role) *GetRoleResponse {
+	m := &GetRoleResponse{}
+	m.ID = e.ID
+	m.OrganizationID = e.OrganizationID
+	m.Name = e.Name
+	m.Permissions = []string{}
+	for _, p := range e.Permissions {
+		m.Permissions = append(m.Permissions, string(p))
+	}
+	return m
+}
@@ -34,8 +34,9 @@ func (c contextKey) String() string {
 }
 
 var (
-	contextKeyUserID    = contextKey("UserID")
-	contextKeySessionID = contextKey("SessionID")
+	contextKeyUserID     = contextKey("UserID")
+	contextKeySessionID  = contextKey("SessionID")
+	contextKeyApiTokenID = contextKey("ApiTokenID")
 )
 
 var (
@@ -59,9 +60,20 @@ var (
 
 	ResponseCodeGroupNameAlreadyExists = 4001
 
-	ResponseCodePasswordUpdateRequired = 5001
+	ResponseCodePasswordUpdateRequired  = 5001
+	ResponseCodePasswordPolicyViolation = 5002
+	ResponseCodePasswordBreached        = 5003
+	ResponseCodePasswordReused          = 5004
+	ResponseCodeNetworkNotAllowed       = 5005
+	ResponseCodeSecondFactorRequired    = 5006
+	ResponseCodeNetworkPolicyLockout    = 5007
 
 	ResponseCodeAuthProviderAlreadyExists = 6001
+
+	ResponseCodeEmailTemplateMissingVariable = 7001
+	ResponseCodeEmailTemplateUnknownVariable = 7002
+	ResponseCodeMailHostNotAllowed           = 7003
+	ResponseCodeMailPasswordRequired         = 7004
 )
 
 func sendErrorCode(w http.ResponseWriter, statusCode int, code int) {
@@ -210,7 +222,20 @@ func ExtractClaimsFromRequest(r *http.Request) (*Claims, string, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return config.GetConfig().JwtPublicKey, nil
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			// Tokens issued before key IDs were introduced
+			keySet := jwt.VerificationKeySet{}
+			for _, key := range GetSigningKeyRing().GetConfigVerificationKeys() {
+				keySet.Keys = append(keySet.Keys, key)
+			}
+			return keySet, nil
+		}
+		key := GetSigningKeyRing().GetVerificationKey(kid)
+		if key == nil {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		return key, nil
 	})
 	if err != nil {
 		return nil, "", errors.New("JWT header verification failed: parsing JWT failed with: " + err.Error())
@@ -326,6 +351,9 @@ func VerifyAuthMiddleware(next http.Handler) http.Handler {
 			if r.Method != "GET" && user.Role == UserRoleServiceAccountRO {
 				return false
 			}
+			if !checkNetworkPolicy(w, r, user, AuthMethodServiceAccount) {
+				return true
+			}
 			ctx := context.WithValue(r.Context(), contextKeyUserID, user.ID)
 			// Note: service accounts do not have sessions, so we do not set session ID in context
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -357,6 +385,67 @@ func VerifyAuthMiddleware(next http.Handler) http.Handler {
 		if r.Method != "GET" && user.Role == UserRoleServiceAccountRO {
 			return false
 		}
+		if !checkNetworkPolicy(w, r, user, AuthMethodServiceAccount) {
+			return true
+		}
+		ctx := context.WithValue(r.Context(), contextKeyUserID, user.ID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+		return true
+	}
+
+	var handleApiTokenAuth = func(w http.ResponseWriter, r *http.Request) bool {
+		authHeader := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer "+ApiTokenPrefix) {
+			return false
+		}
+		token, err := GetApiTokenRepository().GetValidByTokenHash(GetApiTokenHash(strings.TrimPrefix(authHeader, "Bearer ")))
+		if err != nil || token == nil {
+			return false
+		}
+		user, err := GetUserRepository().GetOne(token.UserID)
+		if err != nil || user == nil {
+			return false
+		}
+		if user.Disabled {
+			return false
+		}
+		if r.Method != "GET" && user.Role == UserRoleServiceAccountRO {
+			return false
+		}
+		if !checkNetworkPolicy(w, r, user, AuthMethodApiToken) {
+			return true
+		}
+		go GetApiTokenRepository().UpdateLastUsed(token.ID, GetClientIP(r))
+		// Scopes restrict the token in addition to the permissions of its owner
+		if !HasApiTokenScope(token, r) {
+			SendForbidden(w)
+			return true
+		}
+		ctx := context.WithValue(r.Context(), contextKeyUserID, user.ID)
+		ctx = context.WithValue(ctx, contextKeyApiTokenID, token.ID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+		return true
+	}
+
+	// Access tokens issued using the client credentials grant are not bound
+	// to a session and can only be used by service accounts
+	var handleClientCredentialsTokenAuth = func(w http.ResponseWriter, r *http.Request, claims *Claims) bool {
+		user, err := GetUserRepository().GetOne(claims.UserID)
+		if err != nil || user == nil {
+			return false
+		}
+		if user.Role != UserRoleServiceAccountRO && user.Role != UserRoleServiceAccountRW {
+			return false
+		}
+		if user.Disabled {
+			return false
+		}
+		if r.Method != "GET" && user.Role == UserRoleServiceAccountRO {
+			return false
+		}
+		if !checkNetworkPolicy(w, r, user, AuthMethodClientCredentials) {
+			return true
+		}
 		ctx := context.WithValue(r.Context(), contextKeyUserID, user.ID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 		return true
@@ -367,6 +456,9 @@ func VerifyAuthMiddleware(next http.Handler) http.Handler {
 		if err != nil {
 			return false
 		}
+		if claims.SessionID == "" {
+			return handleClientCredentialsTokenAuth(w, r, claims)
+		}
 		session, err := GetSessionRepository().GetOne(claims.SessionID)
 		if err != nil || session == nil {
 			return false
@@ -381,6 +473,9 @@ func VerifyAuthMiddleware(next http.Handler) http.Handler {
 		if user.Disabled {
 			return false
 		}
+		if !checkNetworkPolicy(w, r, user, AuthMethodAccessToken) {
+			return true
+		}
 		// Update session activity on each authenticated request
 		go GetSessionRepository().UpdateActivity(session.ID)
 		ctx := context.WithValue(r.Context(), contextKeyUserID, claims.UserID)
@@ -399,14 +494,14 @@ func VerifyAuthMiddleware(next http.Handler) http.Handler {
 			processedWithAuth := false
 			authHeader := r.Header.Get("Authorization")
 			if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
-				processedWithAuth = handleTokenAuth(w, r) || handleServiceAccountAuth(w, r)
+				processedWithAuth = handleTokenAuth(w, r) || handleApiTokenAuth(w, r) || handleServiceAccountAuth(w, r)
 			}
 			if !processedWithAuth {
 				next.ServeHTTP(w, r)
 			}
 			return
 		}
-		success := handleTokenAuth(w, r) || handleServiceAccountAuth(w, r)
+		success := handleTokenAuth(w, r) || handleApiTokenAuth(w, r) || handleServiceAccountAuth(w, r)
 		if !success {
 			SendUnauthorized(w)
 			return
@@ -453,6 +548,16 @@ func GetRequestSessionID(r *http.Request) string {
 	return sessionID.(string)
 }
 
+// GetRequestApiTokenID returns the ID of the API token used to authenticate
+// the request or an empty string if no API token was used.
+func GetRequestApiTokenID(r *http.Request) string {
+	tokenID := r.Context().Value(contextKeyApiTokenID)
+	if tokenID == nil {
+		return ""
+	}
+	return tokenID.(string)
+}
+
 // SetRequestUserID injects a user ID into the context so GetRequestUser can retrieve it.
 // Used by plugin HTTP dispatchers that receive the user ID via RPC.
 func SetRequestUserID(r *http.Request, userID string) *http.Request {
@@ -491,16 +596,6 @@ func CanAccessOrg(user *User, organizationID string) bool {
 	return false
 }
 
-func CanSpaceAdminOrg(user *User, organizationID string) bool {
-	if (user.OrganizationID == organizationID) && (GetUserRepository().IsSpaceAdmin(user)) {
-		return true
-	}
-	if GetUserRepository().IsSuperAdmin(user) {
-		return true
-	}
-	return false
-}
-
 // IsLocationWeekdayBookable checks whether every calendar day in [enter, leave)
 // falls on one of the location's bookable weekdays, honoring the org's
 // no-admin-restrictions setting for space admins.
@@ -508,7 +603,7 @@ func IsLocationWeekdayBookable(location *Location, user *User, enter, leave time
 	if location.BookableDays == "" {
 		return true
 	}
-	if CanSpaceAdminOrg(user, location.OrganizationID) {
+	if HasLocationPermission(user, location.OrganizationID, PermissionManageBookings, location.ID) {
 		noAdminRestrictions, _ := GetSettingsRepository().GetBool(location.OrganizationID, SettingNoAdminRestrictions.Name)
 		if noAdminRestrictions {
 			return true
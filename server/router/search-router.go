@@ -3,6 +3,7 @@ package router
 import (
 	"log"
 	"net/http"
+	"slices"
 
 	"github.com/gorilla/mux"
 
@@ -56,14 +57,26 @@ func (router *SearchRouter) getResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasAnyPermission(user, user.OrganizationID, PermissionManageSpaces, PermissionManageBookings, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
 
 	res := &GetSearchResultsResponse{}
 	if r.URL.Query().Get("includeUsers") == "1" {
-		if err := router.addUserResults(user, keyword, res); err != nil {
+		var attributeFilter *UserAttributeValue = nil
+		if attributeID := r.URL.Query().Get("userAttributeId"); attributeID != "" {
+			attribute, err := GetUserAttributeRepository().GetOne(attributeID)
+			if err != nil || attribute.OrganizationID != user.OrganizationID {
+				SendBadRequest(w)
+				return
+			}
+			attributeFilter = &UserAttributeValue{
+				AttributeID: attribute.ID,
+				Value:       r.URL.Query().Get("userAttributeValue"),
+			}
+		}
+		if err := router.addUserResults(user, keyword, attributeFilter, res); err != nil {
 			log.Println(err)
 			SendInternalServerError(w)
 			return
@@ -93,12 +106,24 @@ func (router *SearchRouter) getResults(w http.ResponseWriter, r *http.Request) {
 	SendJSON(w, res)
 }
 
-func (router *SearchRouter) addUserResults(user *User, keyword string, res *GetSearchResultsResponse) error {
+// addUserResults adds the users matching the keyword. If attributeFilter is
+// not nil, only users with the specified attribute value are included.
+func (router *SearchRouter) addUserResults(user *User, keyword string, attributeFilter *UserAttributeValue, res *GetSearchResultsResponse) error {
 	list, err := GetUserRepository().GetByKeyword(user.OrganizationID, keyword)
 	if err != nil {
 		return err
 	}
+	var matchingIDs []string = nil
+	if attributeFilter != nil {
+		matchingIDs, err = GetUserAttributeRepository().GetUserIDsByValue(attributeFilter.AttributeID, attributeFilter.Value)
+		if err != nil {
+			return err
+		}
+	}
 	for _, e := range list {
+		if matchingIDs != nil && !slices.Contains(matchingIDs, e.ID) {
+			continue
+		}
 		m := &GetUserSearchResponse{
 			ID:        e.ID,
 			Email:     e.Email,
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"net/http"
+
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+// exportAuthEvent forwards a recorded auth event to the SIEM exporter.
+func exportAuthEvent(r *http.Request, e *AuthEvent) {
+	event := &SecurityEvent{
+		Category:       SecurityEventCategoryAuth,
+		Action:         e.Method,
+		Successful:     e.Successful,
+		OrganizationID: e.OrganizationID,
+		Email:          e.Email,
+		SourceIP:       GetClientIP(r),
+		Device:         e.Device,
+		ErrorCode:      e.ErrorCode,
+		Detail:         e.ErrorDetail,
+	}
+	if e.User != nil {
+		event.UserID = e.User.ID
+		event.OrganizationID = e.User.OrganizationID
+		if event.Email == "" {
+			event.Email = e.User.Email
+		}
+	}
+	ExportSecurityEvent(event)
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const (
+	// Logins older than this are neither used for detection nor kept
+	LoginHistoryRetention = 180 * 24 * time.Hour
+	// Logins from this many different networks within rapidNetworkSwitchWindow are suspicious
+	rapidNetworkSwitchThreshold = 3
+	rapidNetworkSwitchWindow    = 1 * time.Hour
+)
+
+const (
+	SuspiciousLoginNewDevice          = "newDevice"
+	SuspiciousLoginNewNetwork         = "newNetwork"
+	SuspiciousLoginRapidNetworkSwitch = "rapidNetworkSwitch"
+)
+
+const (
+	SecurityEventPasskeyAdded       = "passkeyAdded"
+	SecurityEventPasskeyRemoved     = "passkeyRemoved"
+	SecurityEventTotpEnabled        = "totpEnabled"
+	SecurityEventTotpDisabled       = "totpDisabled"
+	SecurityEventAdminResetPasskeys = "adminResetPasskeys"
+	SecurityEventAdminResetTotp     = "adminResetTotp"
+)
+
+// DetectSuspiciousLogin compares a login with the user's previous logins
+// (newest first) and returns the reasons why it looks suspicious. The very
+// first known login of a user is never considered suspicious.
+func DetectSuspiciousLogin(history []*LoginHistoryEntry, device, network string, now time.Time) []string {
+	reasons := []string{}
+	if len(history) == 0 {
+		return reasons
+	}
+	knownDevice, knownNetwork := false, false
+	recentNetworks := []string{network}
+	for _, e := range history {
+		if e.Device == device {
+			knownDevice = true
+		}
+		if e.Network == network {
+			knownNetwork = true
+		}
+		if e.Created.After(now.Add(-rapidNetworkSwitchWindow)) && !slices.Contains(recentNetworks, e.Network) {
+			recentNetworks = append(recentNetworks, e.Network)
+		}
+	}
+	if !knownDevice {
+		reasons = append(reasons, SuspiciousLoginNewDevice)
+	}
+	if !knownNetwork {
+		reasons = append(reasons, SuspiciousLoginNewNetwork)
+	}
+	if len(recentNetworks) >= rapidNetworkSwitchThreshold {
+		reasons = append(reasons, SuspiciousLoginRapidNetworkSwitch)
+	}
+	return reasons
+}
+
+// recordLogin adds a successful interactive login to the user's login history
+// and notifies the user if the login looks suspicious.
+func recordLogin(r *http.Request, user *User) {
+	if user.Role == UserRoleServiceAccountRO || user.Role == UserRoleServiceAccountRW {
+		return
+	}
+	var ar AuthRouter
+	now := time.Now().UTC()
+	ip := GetClientIP(r)
+	entry := &LoginHistoryEntry{
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		Device:         ar.GetDeviceInfo(r),
+		IP:             ip,
+		Network:        GetIPNetwork(ip),
+		Created:        now,
+	}
+	if enabled, _ := GetSettingsRepository().GetBool(user.OrganizationID, SettingSuspiciousLoginNotifications.Name); enabled {
+		history, err := GetLoginHistoryRepository().GetByUserIDSince(user.ID, now.Add(-LoginHistoryRetention))
+		if err != nil {
+			log.Println("Error loading login history: " + err.Error())
+			return
+		}
+		entry.Reasons = strings.Join(DetectSuspiciousLogin(history, entry.Device, entry.Network, now), ",")
+	}
+	if err := GetLoginHistoryRepository().Create(entry); err != nil {
+		log.Println("Error recording login history: " + err.Error())
+		return
+	}
+	if entry.Reasons != "" {
+		// Don't delay the login while the email is being sent
+		go func() {
+			if err := sendSuspiciousLoginEmail(user, entry); err != nil {
+				log.Println("Error sending suspicious login email: " + err.Error())
+			}
+		}()
+	}
+}
+
+func getUserMailLanguage(user *User, org *Organization) string {
+	language := org.Language
+	if userLang, err := GetUserPreferencesRepository().Get(user.ID, PreferenceMailLanguage.Name); err == nil && userLang != "" {
+		language = userLang
+	}
+	return language
+}
+
+func getSecurityMailVars(user *User, org *Organization) (map[string]string, error) {
+	domain, err := GetOrganizationRepository().GetPrimaryDomain(org)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"recipientName":  user.GetSafeRecipientName(),
+		"recipientEmail": user.Email,
+		"orgDomain":      FormatURL(domain.DomainName) + "/",
+		"time":           time.Now().UTC().Format("2006-01-02 15:04") + " UTC",
+	}, nil
+}
+
+func sendSuspiciousLoginEmail(user *User, entry *LoginHistoryEntry) error {
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		return err
+	}
+	vars, err := getSecurityMailVars(user, org)
+	if err != nil {
+		return err
+	}
+	vars["time"] = entry.Created.Format("2006-01-02 15:04") + " UTC"
+	vars["device"] = entry.Device
+	vars["ip"] = entry.IP
+	for _, reason := range []string{SuspiciousLoginNewDevice, SuspiciousLoginNewNetwork, SuspiciousLoginRapidNetworkSwitch} {
+		vars[reason] = "0"
+	}
+	for _, reason := range strings.Split(entry.Reasons, ",") {
+		vars[reason] = "1"
+	}
+	return SendEmailWithOrg(&MailAddress{Address: user.Email}, GetEmailTemplatePathSuspiciousLogin(), getUserMailLanguage(user, org), vars, org.ID)
+}
+
+// SendSecurityNotification informs the user about a change of the
+// authentication methods of the account.
+func SendSecurityNotification(user *User, event string, passkeyName string) {
+	if user.Role == UserRoleServiceAccountRO || user.Role == UserRoleServiceAccountRW {
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		log.Println("Error sending security notification: " + err.Error())
+		return
+	}
+	vars, err := getSecurityMailVars(user, org)
+	if err != nil {
+		log.Println("Error sending security notification: " + err.Error())
+		return
+	}
+	for _, e := range []string{SecurityEventPasskeyAdded, SecurityEventPasskeyRemoved, SecurityEventTotpEnabled, SecurityEventTotpDisabled, SecurityEventAdminResetPasskeys, SecurityEventAdminResetTotp} {
+		vars[e] = "0"
+	}
+	vars[event] = "1"
+	vars["passkeyName"] = passkeyName
+	if err := SendEmailWithOrg(&MailAddress{Address: user.Email}, GetEmailTemplatePathSecurityNotification(), getUserMailLanguage(user, org), vars, org.ID); err != nil {
+		log.Println("Error sending security notification: " + err.Error())
+	}
+}
+
+var suspiciousLoginDigestMu sync.Mutex
+
+// SendSuspiciousLoginDigests sends the suspicious logins detected since the
+// last run to the org admins of each affected organization.
+func SendSuspiciousLoginDigests() {
+	suspiciousLoginDigestMu.Lock()
+	defer suspiciousLoginDigestMu.Unlock()
+
+	orgIDs, err := GetLoginHistoryRepository().GetOrgIDsWithPendingDigest()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, orgID := range orgIDs {
+		if err := sendSuspiciousLoginDigest(orgID); err != nil {
+			log.Println("Error sending suspicious login digest for org " + orgID + ": " + err.Error())
+		}
+	}
+}
+
+func sendSuspiciousLoginDigest(organizationID string) error {
+	entries, err := GetLoginHistoryRepository().GetPendingDigest(organizationID)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	org, err := GetOrganizationRepository().GetOne(organizationID)
+	if err != nil {
+		return err
+	}
+	admins, err := GetUserRepository().GetOrgAdmins(organizationID)
+	if err != nil {
+		return err
+	}
+	users := []string{}
+	for _, e := range entries {
+		if !slices.Contains(users, e.UserEmail) {
+			users = append(users, e.UserEmail)
+		}
+	}
+	for _, admin := range admins {
+		vars, err := getSecurityMailVars(admin, org)
+		if err != nil {
+			return err
+		}
+		vars["count"] = strconv.Itoa(len(entries))
+		vars["users"] = strings.Join(users, ", ")
+		if err := SendEmailWithOrg(&MailAddress{Address: admin.Email}, GetEmailTemplatePathSuspiciousLoginDigest(), getUserMailLanguage(admin, org), vars, org.ID); err != nil {
+			log.Println("Error sending suspicious login digest: " + err.Error())
+		}
+	}
+	return GetLoginHistoryRepository().SetDigestSent(organizationID, entries[len(entries)-1].Created)
+}
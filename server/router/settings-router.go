@@ -70,7 +70,8 @@ func (router *SettingsRouter) getSetting(w http.ResponseWriter, r *http.Request)
 	user := GetRequestUser(r)
 	vars := mux.Vars(r)
 	orgAdmin := CanAdminOrg(user, user.OrganizationID)
-	if !((orgAdmin && router.isValidSettingNameReadAdmin(vars["name"])) || (router.isValidSettingNameReadPublic(vars["name"]))) {
+	settingsAdmin := HasPermission(user, user.OrganizationID, PermissionManageSettings)
+	if !((settingsAdmin && router.isValidSettingNameReadAdmin(vars["name"])) || (router.isValidSettingNameReadPublic(vars["name"]))) {
 		SendForbidden(w)
 		return
 	}
@@ -136,7 +137,7 @@ func (router *SettingsRouter) getSetting(w http.ResponseWriter, r *http.Request)
 
 func (router *SettingsRouter) setSetting(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
 		SendForbidden(w)
 		return
 	}
@@ -158,6 +159,11 @@ func (router *SettingsRouter) setSetting(w http.ResponseWriter, r *http.Request)
 		SendBadRequest(w)
 		return
 	}
+	if isNetworkPolicyLockout(r, user, vars["name"], value.Value) {
+		SendBadRequestCode(w, ResponseCodeNetworkPolicyLockout)
+		return
+	}
+	oldValue, _ := GetSettingsRepository().Get(user.OrganizationID, vars["name"])
 	err := router.doSetOne(user.OrganizationID, vars["name"], value.Value)
 	if err != nil {
 		log.Println(err)
@@ -168,6 +174,7 @@ func (router *SettingsRouter) setSetting(w http.ResponseWriter, r *http.Request)
 		}
 		return
 	}
+	router.recordSettingChange(r, vars["name"], oldValue, value.Value)
 	SendUpdated(w)
 }
 
@@ -178,6 +185,7 @@ func (router *SettingsRouter) getAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	orgAdmin := CanAdminOrg(user, user.OrganizationID)
+	settingsAdmin := HasPermission(user, user.OrganizationID, PermissionManageSettings)
 	list, err := GetSettingsRepository().GetAll(user.OrganizationID)
 	if err != nil {
 		log.Println(err)
@@ -186,7 +194,7 @@ func (router *SettingsRouter) getAll(w http.ResponseWriter, r *http.Request) {
 	}
 	res := []*GetSettingsResponse{}
 	for _, e := range list {
-		if (orgAdmin && router.isValidSettingNameReadAdmin(e.Name)) || (router.isValidSettingNameReadPublic(e.Name)) {
+		if (settingsAdmin && router.isValidSettingNameReadAdmin(e.Name)) || (router.isValidSettingNameReadPublic(e.Name)) {
 			m := router.copyToRestModel(e)
 			res = append(res, m)
 		}
@@ -195,7 +203,7 @@ func (router *SettingsRouter) getAll(w http.ResponseWriter, r *http.Request) {
 		res = append(res, router.getSysSettingOrgSignupDelete())
 		res = append(res, router.getAdminWelcomeScreens(list))
 	}
-	if CanSpaceAdminOrg(user, user.OrganizationID) {
+	if len(GetUserPermissions(user)) > 0 {
 		res = append(res, router.getAdminMenuItems())
 	}
 	org, _ := GetOrganizationRepository().GetOne(user.OrganizationID)
@@ -225,7 +233,7 @@ func (router *SettingsRouter) getAll(w http.ResponseWriter, r *http.Request) {
 
 func (router *SettingsRouter) setAll(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
 		SendForbidden(w)
 		return
 	}
@@ -248,6 +256,11 @@ func (router *SettingsRouter) setAll(w http.ResponseWriter, r *http.Request) {
 			SendBadRequest(w)
 			return
 		}
+		if isNetworkPolicyLockout(r, user, e.Name, e.Value) {
+			SendBadRequestCode(w, ResponseCodeNetworkPolicyLockout)
+			return
+		}
+		oldValue, _ := GetSettingsRepository().Get(user.OrganizationID, e.Name)
 		err := router.doSetOne(user.OrganizationID, e.Name, e.Value)
 		if err != nil {
 			log.Println(err)
@@ -258,10 +271,23 @@ func (router *SettingsRouter) setAll(w http.ResponseWriter, r *http.Request) {
 			}
 			return
 		}
+		router.recordSettingChange(r, e.Name, oldValue, e.Value)
 	}
 	SendUpdated(w)
 }
 
+func (router *SettingsRouter) recordSettingChange(r *http.Request, name, oldValue, newValue string) {
+	if oldValue == newValue {
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionUpdate,
+		EntityType: AuditEntitySetting,
+		EntityID:   name,
+		EntityName: name,
+	}, map[string]string{name: oldValue}, map[string]string{name: newValue})
+}
+
 func (router *SettingsRouter) doSetOne(organizationID, name, value string) error {
 	// Kiosk secret: hash the plaintext before storing; empty value clears it.
 	if name == SettingKioskSecret.Name {
@@ -344,7 +370,27 @@ func (router *SettingsRouter) isValidSettingNameReadAdmin(name string) bool {
 		name == SettingNewUserDefaultMailNotification.Name ||
 		name == SettingTargetUtilizationHoursPerWeek.Name ||
 		name == SettingKioskSecret.Name ||
-		name == SettingKioskModeEnabled.Name {
+		name == SettingKioskModeEnabled.Name ||
+		name == SettingAllowMagicLinkLogin.Name ||
+		name == SettingPasswordMinLength.Name ||
+		name == SettingPasswordMinCharClasses.Name ||
+		name == SettingPasswordHistory.Name ||
+		name == SettingPasswordMaxAgeDays.Name ||
+		name == SettingPasswordCheckBreached.Name ||
+		name == SettingNetworkAllowlistUsers.Name ||
+		name == SettingNetworkAllowlistSpaceAdmins.Name ||
+		name == SettingNetworkAllowlistOrgAdmins.Name ||
+		name == SettingNetworkAllowlistServiceAccount.Name ||
+		name == SettingTrustedNetworks.Name ||
+		name == SettingRequire2FAUntrustedNetworks.Name ||
+		name == SettingSuspiciousLoginNotifications.Name ||
+		name == SettingChatApprovalWebhookURL.Name ||
+		name == SettingChatApprovalWebhookFormat.Name ||
+		name == SettingApprovalReminderHours.Name ||
+		name == SettingApprovalEscalationHours.Name ||
+		name == SettingApprovalEscalationGroupID.Name ||
+		name == SettingApprovalExpiryAction.Name ||
+		name == SettingApprovalExpiryHours.Name {
 		return true
 	}
 	return false
@@ -380,7 +426,27 @@ func (router *SettingsRouter) isValidSettingNameWrite(name string) bool {
 		name == SettingSubjectDefault.Name ||
 		name == SettingTargetUtilizationHoursPerWeek.Name ||
 		name == SettingKioskSecret.Name ||
-		name == SettingKioskModeEnabled.Name {
+		name == SettingKioskModeEnabled.Name ||
+		name == SettingAllowMagicLinkLogin.Name ||
+		name == SettingPasswordMinLength.Name ||
+		name == SettingPasswordMinCharClasses.Name ||
+		name == SettingPasswordHistory.Name ||
+		name == SettingPasswordMaxAgeDays.Name ||
+		name == SettingPasswordCheckBreached.Name ||
+		name == SettingNetworkAllowlistUsers.Name ||
+		name == SettingNetworkAllowlistSpaceAdmins.Name ||
+		name == SettingNetworkAllowlistOrgAdmins.Name ||
+		name == SettingNetworkAllowlistServiceAccount.Name ||
+		name == SettingTrustedNetworks.Name ||
+		name == SettingRequire2FAUntrustedNetworks.Name ||
+		name == SettingSuspiciousLoginNotifications.Name ||
+		name == SettingChatApprovalWebhookURL.Name ||
+		name == SettingChatApprovalWebhookFormat.Name ||
+		name == SettingApprovalReminderHours.Name ||
+		name == SettingApprovalEscalationHours.Name ||
+		name == SettingApprovalEscalationGroupID.Name ||
+		name == SettingApprovalExpiryAction.Name ||
+		name == SettingApprovalExpiryHours.Name {
 		return true
 	}
 	return false
@@ -477,6 +543,66 @@ func (router *SettingsRouter) getSettingType(name string) SettingType {
 	if name == SettingKioskModeEnabled.Name {
 		return SettingKioskModeEnabled.Type
 	}
+	if name == SettingAllowMagicLinkLogin.Name {
+		return SettingAllowMagicLinkLogin.Type
+	}
+	if name == SettingPasswordMinLength.Name {
+		return SettingPasswordMinLength.Type
+	}
+	if name == SettingPasswordMinCharClasses.Name {
+		return SettingPasswordMinCharClasses.Type
+	}
+	if name == SettingPasswordHistory.Name {
+		return SettingPasswordHistory.Type
+	}
+	if name == SettingPasswordMaxAgeDays.Name {
+		return SettingPasswordMaxAgeDays.Type
+	}
+	if name == SettingPasswordCheckBreached.Name {
+		return SettingPasswordCheckBreached.Type
+	}
+	if name == SettingNetworkAllowlistUsers.Name {
+		return SettingNetworkAllowlistUsers.Type
+	}
+	if name == SettingNetworkAllowlistSpaceAdmins.Name {
+		return SettingNetworkAllowlistSpaceAdmins.Type
+	}
+	if name == SettingNetworkAllowlistOrgAdmins.Name {
+		return SettingNetworkAllowlistOrgAdmins.Type
+	}
+	if name == SettingNetworkAllowlistServiceAccount.Name {
+		return SettingNetworkAllowlistServiceAccount.Type
+	}
+	if name == SettingTrustedNetworks.Name {
+		return SettingTrustedNetworks.Type
+	}
+	if name == SettingRequire2FAUntrustedNetworks.Name {
+		return SettingRequire2FAUntrustedNetworks.Type
+	}
+	if name == SettingSuspiciousLoginNotifications.Name {
+		return SettingSuspiciousLoginNotifications.Type
+	}
+	if name == SettingChatApprovalWebhookURL.Name {
+		return SettingChatApprovalWebhookURL.Type
+	}
+	if name == SettingChatApprovalWebhookFormat.Name {
+		return SettingChatApprovalWebhookFormat.Type
+	}
+	if name == SettingApprovalReminderHours.Name {
+		return SettingApprovalReminderHours.Type
+	}
+	if name == SettingApprovalEscalationHours.Name {
+		return SettingApprovalEscalationHours.Type
+	}
+	if name == SettingApprovalEscalationGroupID.Name {
+		return SettingApprovalEscalationGroupID.Type
+	}
+	if name == SettingApprovalExpiryAction.Name {
+		return SettingApprovalExpiryAction.Type
+	}
+	if name == SettingApprovalExpiryHours.Name {
+		return SettingApprovalExpiryHours.Type
+	}
 	return 0
 }
 
@@ -488,6 +614,12 @@ func (router *SettingsRouter) isValidSettingType(name string, value string) bool
 	if settingType == SettingTypeString && len(value) <= 256 {
 		return true
 	}
+	if settingType == SettingTypeString && isNetworkListSetting(name) && len(value) <= 2048 {
+		return true
+	}
+	if settingType == SettingTypeString && name == SettingChatApprovalWebhookURL.Name && len(value) <= 512 {
+		return true
+	}
 	if settingType == SettingTypeBool && (value == "1" || value == "0") {
 		return true
 	}
@@ -522,6 +654,20 @@ func (router *SettingsRouter) isValidSettingValue(name string, value string) boo
 		}
 		return true
 	}
+	if name == SettingApprovalReminderHours.Name || name == SettingApprovalEscalationHours.Name || name == SettingApprovalExpiryHours.Name {
+		if !ValidateNumber(value, 0, 9999) {
+			return false
+		}
+		return true
+	}
+	if name == SettingApprovalEscalationGroupID.Name && !ValidateGUID(value) {
+		return false
+	}
+	if name == SettingApprovalExpiryAction.Name &&
+		value != SettingApprovalExpiryActionApprove &&
+		value != SettingApprovalExpiryActionDecline {
+		return false
+	}
 	if name == SettingBookingRetentionDays.Name {
 		if !ValidateNumber(value, 30, 999) {
 			return false
@@ -534,9 +680,45 @@ func (router *SettingsRouter) isValidSettingValue(name string, value string) boo
 		}
 		return true
 	}
+	if name == SettingPasswordMinLength.Name {
+		if !ValidateNumber(value, 8, 64) {
+			return false
+		}
+		return true
+	}
+	if name == SettingPasswordMinCharClasses.Name {
+		if !ValidateNumber(value, 1, 4) {
+			return false
+		}
+		return true
+	}
+	if name == SettingPasswordHistory.Name {
+		if !ValidateNumber(value, 0, MaxPasswordHistoryEntries) {
+			return false
+		}
+		return true
+	}
+	if name == SettingPasswordMaxAgeDays.Name {
+		if !ValidateNumber(value, 0, 9999) {
+			return false
+		}
+		return true
+	}
+	if isNetworkListSetting(name) {
+		if _, err := ParseNetworkList(value); err != nil {
+			return false
+		}
+		return true
+	}
 	if name == SettingDefaultTimezone.Name && !IsValidTimeZone(value) {
 		return false
 	}
+	if name == SettingChatApprovalWebhookURL.Name && !ValidateChatWebhookURL(value) {
+		return false
+	}
+	if name == SettingChatApprovalWebhookFormat.Name && !IsValidChatFormat(value) {
+		return false
+	}
 	if name == SettingConfluenceServerSharedSecret.Name && len(value) > 256 {
 		return false
 	}
@@ -0,0 +1,291 @@
+package router
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+// How long issued access tokens are valid
+const AccessTokenLifetime = 15 * time.Minute
+
+// How long a new signing key is published before it is used for signing, so
+// that verifiers caching the key set pick it up in time
+const signingKeyPrepublishPeriod = 15 * time.Minute
+
+// Additional time a superseded key stays valid to tolerate clock skew
+const signingKeyExpiryLeeway = 5 * time.Minute
+
+// How often the key set is re-read from the database
+const signingKeyReloadInterval = 1 * time.Minute
+
+// Minimum time between two reloads triggered by an unknown key ID
+const signingKeyUnknownKIDReloadInterval = 10 * time.Second
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
+
+type signingKeyEntry struct {
+	kid        string
+	publicKey  *rsa.PublicKey
+	privateKey *rsa.PrivateKey // nil for verification-only keys
+	activates  time.Time
+}
+
+// SigningKeyRing holds the keys used for signing and verifying access tokens.
+// It consists of the key pair from the configuration, additional
+// verification-only keys from the configuration and, if key rotation is
+// enabled, the database-backed rotating keys.
+type SigningKeyRing struct {
+	mu     sync.RWMutex
+	keys   []*signingKeyEntry
+	loaded time.Time
+}
+
+var signingKeyRing *SigningKeyRing
+var signingKeyRingOnce sync.Once
+
+func GetSigningKeyRing() *SigningKeyRing {
+	signingKeyRingOnce.Do(func() {
+		signingKeyRing = &SigningKeyRing{}
+	})
+	return signingKeyRing
+}
+
+// GetKeyID returns the RFC 7638 JWK thumbprint of the public key.
+func GetKeyID(key *rsa.PublicKey) string {
+	jwk := toJSONWebKey(key, "")
+	thumbprintInput := `{"e":"` + jwk.E + `","kty":"RSA","n":"` + jwk.N + `"}`
+	hash := sha256.Sum256([]byte(thumbprintInput))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func toJSONWebKey(key *rsa.PublicKey, kid string) *JSONWebKey {
+	return &JSONWebKey{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: "RS512",
+		KeyID:     kid,
+		N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// Reload re-reads the key set from the configuration and the database.
+func (k *SigningKeyRing) Reload() error {
+	keys := []*signingKeyEntry{}
+	dbKeys, dbErr := GetSigningKeyRepository().GetAllValid()
+	for _, e := range dbKeys {
+		entry, err := k.parseSigningKey(e)
+		if err != nil {
+			log.Printf("Error parsing signing key %s: %s\n", e.ID, err)
+			continue
+		}
+		keys = append(keys, entry)
+	}
+	config := GetConfig()
+	keys = append(keys, &signingKeyEntry{
+		kid:        GetKeyID(config.JwtPublicKey),
+		publicKey:  config.JwtPublicKey,
+		privateKey: config.JwtPrivateKey,
+	})
+	for _, publicKey := range config.JwtAdditionalPublicKeys {
+		keys = append(keys, &signingKeyEntry{
+			kid:       GetKeyID(publicKey),
+			publicKey: publicKey,
+		})
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.loaded = time.Now()
+	k.mu.Unlock()
+	return dbErr
+}
+
+func (k *SigningKeyRing) parseSigningKey(e *SigningKey) (*signingKeyEntry, error) {
+	privateKeyPEM, err := DecryptString(e.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block containing the key")
+	}
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &signingKeyEntry{
+		kid:        GetKeyID(&privateKey.PublicKey),
+		publicKey:  &privateKey.PublicKey,
+		privateKey: privateKey,
+		activates:  e.Activates,
+	}, nil
+}
+
+func (k *SigningKeyRing) getKeys() []*signingKeyEntry {
+	k.mu.RLock()
+	keys, loaded := k.keys, k.loaded
+	k.mu.RUnlock()
+	if keys == nil || time.Since(loaded) > signingKeyReloadInterval {
+		if err := k.Reload(); err != nil {
+			log.Println("Error loading signing keys: " + err.Error())
+		}
+		k.mu.RLock()
+		keys = k.keys
+		k.mu.RUnlock()
+	}
+	return keys
+}
+
+// GetSigningKey returns the key ID and private key to sign new access tokens
+// with. This is the newest active database key if key rotation is enabled,
+// otherwise the key pair from the configuration.
+func (k *SigningKeyRing) GetSigningKey() (string, *rsa.PrivateKey) {
+	config := GetConfig()
+	if config.JwtKeyRotationDays > 0 {
+		now := time.Now()
+		for _, entry := range k.getKeys() {
+			if entry.privateKey != nil && !entry.activates.IsZero() && !entry.activates.After(now) {
+				return entry.kid, entry.privateKey
+			}
+		}
+	}
+	return GetKeyID(config.JwtPublicKey), config.JwtPrivateKey
+}
+
+// GetVerificationKey returns the public key with the given key ID or nil if
+// the key is unknown or has expired.
+func (k *SigningKeyRing) GetVerificationKey(kid string) *rsa.PublicKey {
+	if key := k.findKey(k.getKeys(), kid); key != nil {
+		return key
+	}
+	// The key might have been created by another instance since the last reload
+	k.mu.RLock()
+	loaded := k.loaded
+	k.mu.RUnlock()
+	if time.Since(loaded) < signingKeyUnknownKIDReloadInterval {
+		return nil
+	}
+	if err := k.Reload(); err != nil {
+		log.Println("Error loading signing keys: " + err.Error())
+	}
+	k.mu.RLock()
+	keys := k.keys
+	k.mu.RUnlock()
+	return k.findKey(keys, kid)
+}
+
+func (k *SigningKeyRing) findKey(keys []*signingKeyEntry, kid string) *rsa.PublicKey {
+	for _, entry := range keys {
+		if entry.kid == kid {
+			return entry.publicKey
+		}
+	}
+	return nil
+}
+
+// GetConfigVerificationKeys returns the public keys from the configuration,
+// used for verifying tokens issued without a key ID.
+func (k *SigningKeyRing) GetConfigVerificationKeys() []*rsa.PublicKey {
+	config := GetConfig()
+	return append([]*rsa.PublicKey{config.JwtPublicKey}, config.JwtAdditionalPublicKeys...)
+}
+
+// GetJSONWebKeySet returns all currently valid public keys, including keys
+// which will be used for signing in the near future.
+func (k *SigningKeyRing) GetJSONWebKeySet() *JSONWebKeySet {
+	res := &JSONWebKeySet{
+		Keys: []*JSONWebKey{},
+	}
+	for _, entry := range k.getKeys() {
+		res.Keys = append(res.Keys, toJSONWebKey(entry.publicKey, entry.kid))
+	}
+	return res
+}
+
+// Rotate creates a new signing key if key rotation is enabled and the newest
+// key is older than the rotation interval, expires superseded keys and deletes
+// expired ones. If key rotation is disabled, all database keys are expired
+// after the lifetime of the tokens they might have signed.
+func (k *SigningKeyRing) Rotate() error {
+	repo := GetSigningKeyRepository()
+	grace := AccessTokenLifetime + signingKeyExpiryLeeway
+	rotationDays := GetConfig().JwtKeyRotationDays
+	if rotationDays <= 0 {
+		if err := repo.ExpireAll(grace); err != nil {
+			return err
+		}
+	} else {
+		now := time.Now().UTC()
+		latest, err := repo.GetLatestActivation()
+		if err != nil {
+			return err
+		}
+		if latest == nil || latest.Before(now.Add(-time.Duration(rotationDays)*24*time.Hour)) {
+			if err := k.createSigningKey(now, now.Add(signingKeyPrepublishPeriod)); err != nil {
+				return err
+			}
+			log.Println("Created new JWT signing key")
+		}
+		if err := repo.ExpireSuperseded(grace); err != nil {
+			return err
+		}
+	}
+	if err := repo.DeleteExpired(); err != nil {
+		return err
+	}
+	return k.Reload()
+}
+
+func (k *SigningKeyRing) createSigningKey(now, activates time.Time) error {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return err
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicKeyBytes,
+	})
+	encryptedPrivateKey, err := EncryptString(string(privateKeyPEM))
+	if err != nil {
+		return err
+	}
+	e := &SigningKey{
+		PrivateKey: encryptedPrivateKey,
+		PublicKey:  string(publicKeyPEM),
+		Created:    now,
+		Activates:  activates,
+	}
+	return GetSigningKeyRepository().Create(e)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -21,20 +22,21 @@ type SpaceAttributeValueRequest struct {
 }
 
 type CreateSpaceRequest struct {
-	Name                  string                       `json:"name" validate:"required,max=128"`
-	X                     uint                         `json:"x" validate:"max=100000"`
-	Y                     uint                         `json:"y" validate:"max=100000"`
-	Width                 uint                         `json:"width" validate:"max=5000"`
-	Height                uint                         `json:"height" validate:"max=5000"`
-	Rotation              uint                         `json:"rotation" validate:"max=359"`
-	RequireSubject        bool                         `json:"requireSubject"`
-	Enabled               bool                         `json:"enabled"`
-	KioskEnabled          bool                         `json:"kioskEnabled"`
-	Shape                 string                       `json:"shape" validate:"oneof=rect circle trapezoid"`
-	FontSize              string                       `json:"fontSize" validate:"oneof=small normal big bigger"`
-	Attributes            []SpaceAttributeValueRequest `json:"attributes" validate:"dive"`
-	ApproverGroupIDs      []string                     `json:"approverGroupIds" validate:"dive,uuid"`
-	AllowedBookerGroupIDs []string                     `json:"allowedBookerGroupIds" validate:"dive,uuid"`
+	Name                   string                       `json:"name" validate:"required,max=128"`
+	X                      uint                         `json:"x" validate:"max=100000"`
+	Y                      uint                         `json:"y" validate:"max=100000"`
+	Width                  uint                         `json:"width" validate:"max=5000"`
+	Height                 uint                         `json:"height" validate:"max=5000"`
+	Rotation               uint                         `json:"rotation" validate:"max=359"`
+	RequireSubject         bool                         `json:"requireSubject"`
+	Enabled                bool                         `json:"enabled"`
+	KioskEnabled           bool                         `json:"kioskEnabled"`
+	Shape                  string                       `json:"shape" validate:"oneof=rect circle trapezoid"`
+	FontSize               string                       `json:"fontSize" validate:"oneof=small normal big bigger"`
+	Attributes             []SpaceAttributeValueRequest `json:"attributes" validate:"dive"`
+	ApproverGroupIDs       []string                     `json:"approverGroupIds" validate:"dive,uuid"`
+	AllowedBookerGroupIDs  []string                     `json:"allowedBookerGroupIds" validate:"dive,uuid"`
+	SecondApproverGroupIDs []string                     `json:"secondApproverGroupIds" validate:"dive,uuid"`
 }
 
 type UpdateSpaceRequest struct {
@@ -212,7 +214,7 @@ func (router *SpaceRouter) _getAvailability(spaceID string, w http.ResponseWrite
 		return
 	}
 	var showNames bool = false
-	if CanSpaceAdminOrg(user, location.OrganizationID) {
+	if HasLocationPermission(user, location.OrganizationID, PermissionManageBookings, location.ID) {
 		showNames = true
 	} else {
 		showNames, _ = GetSettingsRepository().GetBool(location.OrganizationID, SettingShowNames.Name)
@@ -251,6 +253,18 @@ func (router *SpaceRouter) _getAvailability(spaceID string, w http.ResponseWrite
 		SendInternalServerError(w)
 		return
 	}
+	locationAllowedAttributes, err := GetLocationRepository().GetAllowedAttributesForLocation(locationId)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	userAttributeValues, err := GetUserAttributeRepository().GetValuesForUser(user.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
 	approvers, err := GetSpaceRepository().GetAllApproversForSpaceList(spaceIds)
 	if err != nil {
 		log.Println(err)
@@ -261,7 +275,7 @@ func (router *SpaceRouter) _getAvailability(spaceID string, w http.ResponseWrite
 	if r.URL.Query().Has("attributes") {
 		json.Unmarshal([]byte(r.URL.Query().Get("attributes")), &attributes)
 	}
-	isAllowedToBookLocation := router.IsUserAllowedToBookLocation(locationAllowedBookers, userGroups)
+	isAllowedToBookLocation := router.IsUserAllowedToBookLocation(locationAllowedBookers, locationAllowedAttributes, userGroups, userAttributeValues)
 	isValidWeekday := IsLocationWeekdayBookable(location, user, enter, leave)
 	res := []*GetSpaceAvailabilityResponse{}
 	for _, e := range list {
@@ -323,7 +337,7 @@ func (router *SpaceRouter) _getAvailability(spaceID string, w http.ResponseWrite
 
 func (router *SpaceRouter) IsApprovalRequired(e *Space, approvers []*SpaceGroup) bool {
 	for _, approver := range approvers {
-		if approver.SpaceID == e.ID {
+		if approver.SpaceID == e.ID && approver.Level == ApprovalLevelFirst {
 			return true
 		}
 	}
@@ -345,8 +359,8 @@ func (router *SpaceRouter) IsUserAllowedToBookSpace(e *Space, allowedBookers []*
 	return !restricted
 }
 
-func (router *SpaceRouter) IsUserAllowedToBookLocation(allowedBookers []*LocationGroup, userGroups []*Group) bool {
-	restricted := false
+func (router *SpaceRouter) IsUserAllowedToBookLocation(allowedBookers []*LocationGroup, allowedAttributes []*LocationAttributeRule, userGroups []*Group, userAttributeValues []*UserAttributeValue) bool {
+	restricted := len(allowedAttributes) > 0
 	for _, allowedBooker := range allowedBookers {
 		restricted = true
 		for _, userGroup := range userGroups {
@@ -355,6 +369,9 @@ func (router *SpaceRouter) IsUserAllowedToBookLocation(allowedBookers []*Locatio
 			}
 		}
 	}
+	if matchesLocationAttributeRules(allowedAttributes, userAttributeValues) {
+		return true
+	}
 	return !restricted
 }
 
@@ -375,7 +392,7 @@ func (router *SpaceRouter) bulkUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -399,9 +416,18 @@ func (router *SpaceRouter) bulkUpdate(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				res.Deletes = append(res.Deletes, BulkUpdateItemResponse{ID: deleteID, Success: false})
 			} else {
+				before := router.getAuditModel(e.ID)
 				if err := GetSpaceRepository().Delete(e); err != nil {
 					res.Deletes = append(res.Deletes, BulkUpdateItemResponse{ID: deleteID, Success: false})
 				} else {
+					recordAuditLog(r, &AuditLogEntry{
+						Action:         AuditActionDelete,
+						EntityType:     AuditEntitySpace,
+						EntityID:       e.ID,
+						EntityName:     e.Name,
+						OrganizationID: location.OrganizationID,
+					}, before, nil)
+					dispatchSpaceWebhook(WebhookEventSpaceDeleted, location.OrganizationID, e)
 					res.Deletes = append(res.Deletes, BulkUpdateItemResponse{ID: deleteID, Success: true})
 				}
 			}
@@ -426,6 +452,14 @@ func (router *SpaceRouter) bulkUpdate(w http.ResponseWriter, r *http.Request) {
 				if err := router.applyAllowBookers(e, &mSpace); err != nil {
 					log.Println("Could not apply allow bookers:", err)
 				}
+				recordAuditLog(r, &AuditLogEntry{
+					Action:         AuditActionCreate,
+					EntityType:     AuditEntitySpace,
+					EntityID:       e.ID,
+					EntityName:     e.Name,
+					OrganizationID: location.OrganizationID,
+				}, nil, router.getAuditModel(e.ID))
+				dispatchSpaceWebhook(WebhookEventSpaceCreated, location.OrganizationID, e)
 				res.Creates = append(res.Creates, BulkUpdateItemResponse{ID: e.ID, Success: true})
 			}
 		}
@@ -437,6 +471,7 @@ func (router *SpaceRouter) bulkUpdate(w http.ResponseWriter, r *http.Request) {
 			e := router.copyFromRestModel(&mSpace.CreateSpaceRequest)
 			e.ID = mSpace.ID
 			e.LocationID = vars["locationId"]
+			before := router.getAuditModel(e.ID)
 			if err := GetSpaceRepository().Update(e); err != nil {
 				log.Println(err)
 				res.Updates = append(res.Updates, BulkUpdateItemResponse{ID: "", Success: false})
@@ -450,6 +485,14 @@ func (router *SpaceRouter) bulkUpdate(w http.ResponseWriter, r *http.Request) {
 				if err := router.applyAllowBookers(e, &mSpace.CreateSpaceRequest); err != nil {
 					log.Println("Could not apply allow bookers:", err)
 				}
+				recordAuditLog(r, &AuditLogEntry{
+					Action:         AuditActionUpdate,
+					EntityType:     AuditEntitySpace,
+					EntityID:       e.ID,
+					EntityName:     e.Name,
+					OrganizationID: location.OrganizationID,
+				}, before, router.getAuditModel(e.ID))
+				dispatchSpaceWebhook(WebhookEventSpaceUpdated, location.OrganizationID, e)
 				res.Updates = append(res.Updates, BulkUpdateItemResponse{ID: e.ID, Success: true})
 			}
 		}
@@ -521,10 +564,11 @@ func (router *SpaceRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
+	before := router.getAuditModel(e.ID)
 	if err := GetSpaceRepository().Update(e); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
@@ -537,6 +581,14 @@ func (router *SpaceRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	router.applySpaceAttributes(availableAttributes, e, &m)
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntitySpace,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: location.OrganizationID,
+	}, before, router.getAuditModel(e.ID))
+	dispatchSpaceWebhook(WebhookEventSpaceUpdated, location.OrganizationID, e)
 	SendUpdated(w)
 }
 
@@ -553,14 +605,23 @@ func (router *SpaceRouter) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
+	before := router.getAuditModel(e.ID)
 	if err := GetSpaceRepository().Delete(e); err != nil {
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntitySpace,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: location.OrganizationID,
+	}, before, nil)
+	dispatchSpaceWebhook(WebhookEventSpaceDeleted, location.OrganizationID, e)
 	SendUpdated(w)
 }
 
@@ -579,7 +640,7 @@ func (router *SpaceRouter) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -595,6 +656,14 @@ func (router *SpaceRouter) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	router.applySpaceAttributes(availableAttributes, e, &m)
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionCreate,
+		EntityType:     AuditEntitySpace,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: location.OrganizationID,
+	}, nil, router.getAuditModel(e.ID))
+	dispatchSpaceWebhook(WebhookEventSpaceCreated, location.OrganizationID, e)
 	SendCreated(w, e.ID)
 }
 
@@ -638,47 +707,54 @@ func (router *SpaceRouter) applySpaceAttributes(availableAttributes []*SpaceAttr
 }
 
 func (router *SpaceRouter) applyApprovers(space *Space, m *CreateSpaceRequest) error {
-	existingApprovers, err := GetSpaceRepository().GetApproverGroupIDs(space.ID)
+	existingApprovers, err := GetSpaceRepository().GetApproverGroupIDsForLevel(space.ID, ApprovalLevelFirst)
 	if err != nil {
 		return err
 	}
-	// Check deletes
-	removes := []string{}
-	for _, approver := range existingApprovers {
-		found := false
-		for _, mApprover := range m.ApproverGroupIDs {
-			if approver == mApprover {
-				found = true
-				break
-			}
-		}
-		if !found {
-			removes = append(removes, approver)
-		}
+	existingSecondApprovers, err := GetSpaceRepository().GetApproverGroupIDsForLevel(space.ID, ApprovalLevelSecond)
+	if err != nil {
+		return err
 	}
-	// Check creates
-	adds := []string{}
-	for _, mApprover := range m.ApproverGroupIDs {
-		found := false
-		for _, approver := range existingApprovers {
-			if approver == mApprover {
-				found = true
-				break
-			}
-		}
-		if !found {
-			adds = append(adds, mApprover)
+	// A group can only approve on one level, the first level takes precedence
+	secondApprovers := []string{}
+	for _, groupID := range m.SecondApproverGroupIDs {
+		if !slices.Contains(m.ApproverGroupIDs, groupID) {
+			secondApprovers = append(secondApprovers, groupID)
 		}
 	}
-	if err := GetSpaceRepository().AddApprovers(space, adds); err != nil {
+	adds, removes := router.getGroupChanges(existingApprovers, m.ApproverGroupIDs)
+	secondAdds, secondRemoves := router.getGroupChanges(existingSecondApprovers, secondApprovers)
+	// Remove first so that groups can move between levels
+	if err := GetSpaceRepository().RemoveApprovers(space, append(removes, secondRemoves...)); err != nil {
 		return err
 	}
-	if err := GetSpaceRepository().RemoveApprovers(space, removes); err != nil {
+	if err := GetSpaceRepository().AddApproversForLevel(space, adds, ApprovalLevelFirst); err != nil {
+		return err
+	}
+	if err := GetSpaceRepository().AddApproversForLevel(space, secondAdds, ApprovalLevelSecond); err != nil {
 		return err
 	}
 	return nil
 }
 
+// getGroupChanges returns the groups to add and to remove to get from the
+// existing to the requested groups.
+func (router *SpaceRouter) getGroupChanges(existing, requested []string) (adds, removes []string) {
+	adds = []string{}
+	removes = []string{}
+	for _, groupID := range existing {
+		if !slices.Contains(requested, groupID) {
+			removes = append(removes, groupID)
+		}
+	}
+	for _, groupID := range requested {
+		if !slices.Contains(existing, groupID) && !slices.Contains(adds, groupID) {
+			adds = append(adds, groupID)
+		}
+	}
+	return adds, removes
+}
+
 func (router *SpaceRouter) applyAllowBookers(space *Space, m *CreateSpaceRequest) error {
 	existingAllowBookers, err := GetSpaceRepository().GetAllowedBookersGroupIDs(space)
 	if err != nil {
@@ -729,7 +805,7 @@ func (router *SpaceRouter) addApprovers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -753,11 +829,19 @@ func (router *SpaceRouter) addApprovers(w http.ResponseWriter, r *http.Request)
 		SendBadRequest(w)
 		return
 	}
+	before := router.getAuditModel(e.ID)
 	if err := GetSpaceRepository().AddApprovers(e, approvers); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntitySpace,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: location.OrganizationID,
+	}, before, router.getAuditModel(e.ID))
 	SendUpdated(w)
 }
 
@@ -769,7 +853,7 @@ func (router *SpaceRouter) removeApprovers(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -783,11 +867,19 @@ func (router *SpaceRouter) removeApprovers(w http.ResponseWriter, r *http.Reques
 		SendBadRequest(w)
 		return
 	}
+	before := router.getAuditModel(e.ID)
 	if err := GetSpaceRepository().RemoveApprovers(e, approvers); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntitySpace,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: location.OrganizationID,
+	}, before, router.getAuditModel(e.ID))
 	SendUpdated(w)
 }
 
@@ -799,7 +891,7 @@ func (router *SpaceRouter) getApprovers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -837,7 +929,7 @@ func (router *SpaceRouter) addAllowedBookers(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -861,11 +953,19 @@ func (router *SpaceRouter) addAllowedBookers(w http.ResponseWriter, r *http.Requ
 		SendBadRequest(w)
 		return
 	}
+	before := router.getAuditModel(e.ID)
 	if err := GetSpaceRepository().AddAllowedBookers(e, approvers); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntitySpace,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: location.OrganizationID,
+	}, before, router.getAuditModel(e.ID))
 	SendUpdated(w)
 }
 
@@ -877,7 +977,7 @@ func (router *SpaceRouter) removeAllowedBookers(w http.ResponseWriter, r *http.R
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -891,11 +991,19 @@ func (router *SpaceRouter) removeAllowedBookers(w http.ResponseWriter, r *http.R
 		SendBadRequest(w)
 		return
 	}
+	before := router.getAuditModel(e.ID)
 	if err := GetSpaceRepository().RemoveAllowedBookers(e, approvers); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntitySpace,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: location.OrganizationID,
+	}, before, router.getAuditModel(e.ID))
 	SendUpdated(w)
 }
 
@@ -907,7 +1015,7 @@ func (router *SpaceRouter) getAllowedBookers(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, location.OrganizationID) {
+	if !HasLocationPermission(user, location.OrganizationID, PermissionManageSpaces, location.ID) {
 		SendForbidden(w)
 		return
 	}
@@ -953,6 +1061,17 @@ func (router *SpaceRouter) copyFromRestModel(m *CreateSpaceRequest) *Space {
 	return e
 }
 
+func (router *SpaceRouter) getAuditModel(id string) *GetSpaceResponse {
+	e, err := GetSpaceRepository().GetOne(id)
+	if err != nil {
+		return nil
+	}
+	attributes, _ := GetSpaceAttributeValueRepository().GetAllForEntity(e.ID, SpaceAttributeValueEntityTypeSpace)
+	approvers, _ := GetSpaceRepository().GetAllApproversForSpaceList([]string{e.ID})
+	allowedBookers, _ := GetSpaceRepository().GetAllAllowedBookersForSpaceList([]string{e.ID})
+	return router.copyToRestModel(e, attributes, approvers, allowedBookers)
+}
+
 func (router *SpaceRouter) copyToRestModel(e *Space, attributes []*SpaceAttributeValue, approvers, allowedBookers []*SpaceGroup) *GetSpaceResponse {
 	m := &GetSpaceResponse{}
 	m.ID = e.ID
@@ -980,9 +1099,14 @@ func (router *SpaceRouter) copyToRestModel(e *Space, attributes []*SpaceAttribut
 	}
 	if approvers != nil {
 		m.ApproverGroupIDs = []string{}
+		m.SecondApproverGroupIDs = []string{}
 		for _, approver := range approvers {
 			if approver.SpaceID == e.ID {
-				m.ApproverGroupIDs = append(m.ApproverGroupIDs, approver.GroupID)
+				if approver.Level == ApprovalLevelSecond {
+					m.SecondApproverGroupIDs = append(m.SecondApproverGroupIDs, approver.GroupID)
+				} else {
+					m.ApproverGroupIDs = append(m.ApproverGroupIDs, approver.GroupID)
+				}
 			}
 		}
 	}
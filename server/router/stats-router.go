@@ -26,6 +26,13 @@ type GetWeekdayResponse struct {
 	BookingsByWeekday [7]int `json:"bookingsByWeekday"`
 }
 
+type GetUserAttributeStatsItem struct {
+	Value       string `json:"value"`
+	NumUsers    int    `json:"numUsers"`
+	NumBookers  int    `json:"numBookers"`
+	NumBookings int    `json:"numBookings"`
+}
+
 type GetStatsResponse struct {
 	NumUsers             int    `json:"numUsers"`
 	NumBookings          int    `json:"numBookings"`
@@ -43,6 +50,7 @@ func (router *StatsRouter) SetupRoutes(s *mux.Router) {
 	s.HandleFunc("/", router.getStats).Methods("GET")
 	s.HandleFunc("/load", router.getLoad).Methods("GET")
 	s.HandleFunc("/weekday", router.getWeekday).Methods("GET")
+	s.HandleFunc("/attribute/{attributeId}", router.getByUserAttribute).Methods("GET")
 }
 
 func getDateRanges() (thisWeekEnter, thisWeekLeave, lastWeekEnter, lastWeekLeave, nextWeekEnter, nextWeekLeave, lastMonthEnter, lastMonthLeave time.Time) {
@@ -73,7 +81,7 @@ func getDateRanges() (thisWeekEnter, thisWeekLeave, lastWeekEnter, lastWeekLeave
 
 func (router *StatsRouter) getLoad(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionViewReports) {
 		SendForbidden(w)
 		return
 	}
@@ -83,24 +91,9 @@ func (router *StatsRouter) getLoad(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	locationId := r.URL.Query().Get("location")
-	var location *Location = nil
-	if locationId != "" {
-		if uuid.Validate(locationId) != nil {
-			SendBadRequest(w)
-			return
-		}
-		var err error
-		location, err = GetLocationRepository().GetOne(locationId)
-		if err != nil {
-			log.Println(err)
-			SendInternalServerError(w)
-			return
-		}
-		if location == nil || location.OrganizationID != user.OrganizationID {
-			SendBadRequest(w)
-			return
-		}
+	location, ok := router.getLocationFilter(w, r, user)
+	if !ok {
+		return
 	}
 
 	thisWeekEnter, thisWeekLeave, lastWeekEnter, lastWeekLeave, nextWeekEnter, nextWeekLeave, lastMonthEnter, lastMonthLeave := getDateRanges()
@@ -121,7 +114,7 @@ func (router *StatsRouter) getLoad(w http.ResponseWriter, r *http.Request) {
 
 func (router *StatsRouter) getStats(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionViewReports) {
 		SendForbidden(w)
 		return
 	}
@@ -131,6 +124,11 @@ func (router *StatsRouter) getStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	location, ok := router.getLocationFilter(w, r, user)
+	if !ok {
+		return
+	}
+
 	now := time.Now().UTC()
 	todayEnter := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	todayLeave := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
@@ -141,10 +139,15 @@ func (router *StatsRouter) getStats(w http.ResponseWriter, r *http.Request) {
 
 	m := &GetStatsResponse{}
 	m.NumUsers, _ = GetUserRepository().GetCount(user.OrganizationID)
-	m.NumLocations, _ = GetLocationRepository().GetCount(user.OrganizationID)
-	m.NumSpaces, _ = GetSpaceRepository().GetCount(user.OrganizationID)
+	if location != nil {
+		m.NumLocations = 1
+		m.NumSpaces, _ = GetSpaceRepository().GetCountByLocation(user.OrganizationID, *location)
+	} else {
+		m.NumLocations, _ = GetLocationRepository().GetCount(user.OrganizationID)
+		m.NumSpaces, _ = GetSpaceRepository().GetCount(user.OrganizationID)
+	}
 
-	counts, _ := GetBookingRepository().GetCountsSummary(user.OrganizationID,
+	counts, _ := GetBookingRepository().GetCountsSummary(user.OrganizationID, location,
 		DateRange{Enter: todayEnter, Leave: todayLeave},
 		DateRange{Enter: yesterdayEnter, Leave: yesterdayLeave},
 		DateRange{Enter: thisWeekEnter, Leave: thisWeekLeave})
@@ -159,18 +162,18 @@ func (router *StatsRouter) getStats(w http.ResponseWriter, r *http.Request) {
 		{Enter: thisWeekEnter, Leave: thisWeekLeave},
 		{Enter: lastWeekEnter, Leave: lastWeekLeave},
 		{Enter: lastMonthEnter, Leave: lastMonthLeave},
-	}, nil)
+	}, location)
 	if load != nil {
 		m.SpaceLoadNextWeek, m.SpaceLoadThisWeek, m.SpaceLoadLastWeek, m.SpaceLoadLastMonth = load[0], load[1], load[2], load[3]
 	}
 
-	m.BookingsByWeekday, _ = GetBookingRepository().GetCountByWeekday(user.OrganizationID, nil, nil, nil)
+	m.BookingsByWeekday, _ = GetBookingRepository().GetCountByWeekday(user.OrganizationID, location, nil, nil)
 	SendJSON(w, m)
 }
 
 func (router *StatsRouter) getWeekday(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionViewReports) {
 		SendForbidden(w)
 		return
 	}
@@ -180,46 +183,117 @@ func (router *StatsRouter) getWeekday(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	locationId := r.URL.Query().Get("location")
-	var location *Location = nil
-	if locationId != "" {
-		if uuid.Validate(locationId) != nil {
-			SendBadRequest(w)
-			return
-		}
-		var err error
-		location, err = GetLocationRepository().GetOne(locationId)
-		if err != nil {
-			log.Println(err)
-			SendInternalServerError(w)
-			return
-		}
-		if location == nil || location.OrganizationID != user.OrganizationID {
-			SendBadRequest(w)
-			return
-		}
+	location, ok := router.getLocationFilter(w, r, user)
+	if !ok {
+		return
 	}
 
-	var enter, leave *time.Time
-	period := r.URL.Query().Get("period")
-	if period != "" {
-		thisWeekEnter, thisWeekLeave, lastWeekEnter, lastWeekLeave, nextWeekEnter, nextWeekLeave, lastMonthEnter, lastMonthLeave := getDateRanges()
-		switch period {
-		case "thisWeek":
-			enter, leave = &thisWeekEnter, &thisWeekLeave
-		case "lastWeek":
-			enter, leave = &lastWeekEnter, &lastWeekLeave
-		case "nextWeek":
-			enter, leave = &nextWeekEnter, &nextWeekLeave
-		case "lastMonth":
-			enter, leave = &lastMonthEnter, &lastMonthLeave
-		default:
-			SendBadRequest(w)
-			return
-		}
+	enter, leave, ok := router.getPeriodFilter(w, r)
+	if !ok {
+		return
 	}
 
 	m := &GetWeekdayResponse{}
 	m.BookingsByWeekday, _ = GetBookingRepository().GetCountByWeekday(user.OrganizationID, location, enter, leave)
 	SendJSON(w, m)
 }
+
+func (router *StatsRouter) getByUserAttribute(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermissionInAnyLocation(user, user.OrganizationID, PermissionViewReports) {
+		SendForbidden(w)
+		return
+	}
+	hideStats, _ := GetSettingsRepository().GetBool(user.OrganizationID, SettingHideStats.Name)
+	if hideStats {
+		SendNotFound(w)
+		return
+	}
+
+	vars := mux.Vars(r)
+	attribute, err := GetUserAttributeRepository().GetOne(vars["attributeId"])
+	if err != nil || attribute.OrganizationID != user.OrganizationID {
+		SendNotFound(w)
+		return
+	}
+	location, ok := router.getLocationFilter(w, r, user)
+	if !ok {
+		return
+	}
+	enter, leave, ok := router.getPeriodFilter(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := GetBookingRepository().GetCountByUserAttribute(user.OrganizationID, attribute.ID, location, enter, leave)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetUserAttributeStatsItem{}
+	for _, e := range list {
+		res = append(res, &GetUserAttributeStatsItem{
+			Value:       e.Value,
+			NumUsers:    e.NumUsers,
+			NumBookers:  e.NumBookers,
+			NumBookings: e.NumBookings,
+		})
+	}
+	SendJSON(w, res)
+}
+
+// getPeriodFilter parses the optional period query parameter. On failure, the
+// error response has already been sent and ok is false.
+func (router *StatsRouter) getPeriodFilter(w http.ResponseWriter, r *http.Request) (enter, leave *time.Time, ok bool) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		return nil, nil, true
+	}
+	thisWeekEnter, thisWeekLeave, lastWeekEnter, lastWeekLeave, nextWeekEnter, nextWeekLeave, lastMonthEnter, lastMonthLeave := getDateRanges()
+	switch period {
+	case "thisWeek":
+		return &thisWeekEnter, &thisWeekLeave, true
+	case "lastWeek":
+		return &lastWeekEnter, &lastWeekLeave, true
+	case "nextWeek":
+		return &nextWeekEnter, &nextWeekLeave, true
+	case "lastMonth":
+		return &lastMonthEnter, &lastMonthLeave, true
+	}
+	SendBadRequest(w)
+	return nil, nil, false
+}
+
+// getLocationFilter parses the optional location query parameter. Users who
+// may only view reports for specific locations must select one of them. On
+// failure, the error response has already been sent and ok is false.
+func (router *StatsRouter) getLocationFilter(w http.ResponseWriter, r *http.Request, user *User) (location *Location, ok bool) {
+	locationId := r.URL.Query().Get("location")
+	if locationId == "" {
+		if !HasPermission(user, user.OrganizationID, PermissionViewReports) {
+			SendForbidden(w)
+			return nil, false
+		}
+		return nil, true
+	}
+	if uuid.Validate(locationId) != nil {
+		SendBadRequest(w)
+		return nil, false
+	}
+	location, err := GetLocationRepository().GetOne(locationId)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return nil, false
+	}
+	if location == nil || location.OrganizationID != user.OrganizationID {
+		SendBadRequest(w)
+		return nil, false
+	}
+	if !HasLocationPermission(user, user.OrganizationID, PermissionViewReports, location.ID) {
+		SendForbidden(w)
+		return nil, false
+	}
+	return location, true
+}
@@ -0,0 +1,238 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func createTestApiToken(t *testing.T, userID string, payload string) *CreateApiTokenResponse {
+	req := NewHTTPRequest("POST", "/api-token/", userID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	var resBody *CreateApiTokenResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	return resBody
+}
+
+func TestApiTokenCreateAndList(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	token := createTestApiToken(t, user.ID, `{"name": "Calendar sync", "scopes": ["bookings:read", "spaces:read"]}`)
+	CheckStringNotEmpty(t, token.ID)
+	CheckTestBool(t, true, len(token.Token) > len(ApiTokenPrefix))
+	CheckTestString(t, ApiTokenPrefix, token.Token[:len(ApiTokenPrefix)])
+	CheckTestString(t, "Calendar sync", token.Name)
+	CheckTestInt(t, 2, len(token.Scopes))
+	CheckTestBool(t, true, token.Expiry == nil)
+
+	req := NewHTTPRequest("GET", "/api-token/", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []map[string]any
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, token.ID, list[0]["id"].(string))
+	_, hasToken := list[0]["token"]
+	CheckTestBool(t, false, hasToken)
+}
+
+func TestApiTokenCreateInvalidScope(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	payload := `{"name": "Test", "scopes": ["bookings:delete"]}`
+	req := NewHTTPRequest("POST", "/api-token/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	payload = `{"name": "Test", "scopes": []}`
+	req = NewHTTPRequest("POST", "/api-token/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+}
+
+func TestApiTokenCreateExpiryInPast(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	payload := `{"name": "Test", "scopes": ["bookings:read"], "expiry": "` + time.Now().Add(-1*time.Hour).Format(time.RFC3339) + `"}`
+	req := NewHTTPRequest("POST", "/api-token/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+}
+
+func TestApiTokenScopeEnforcement(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	token := createTestApiToken(t, user.ID, `{"name": "Test", "scopes": ["bookings:read"]}`)
+
+	req := NewHTTPRequestBearer("GET", "/booking/", token.Token, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	req = NewHTTPRequestBearer("GET", "/user/me", token.Token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	req = NewHTTPRequestBearer("POST", "/booking/", token.Token, bytes.NewBufferString("{}"))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequestBearer("GET", "/location/", token.Token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Tokens cannot be used to manage tokens
+	req = NewHTTPRequestBearer("GET", "/api-token/", token.Token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Scopes do not grant more permissions than the user has
+	adminToken := createTestApiToken(t, user.ID, `{"name": "Test", "scopes": ["admin:settings"]}`)
+	req = NewHTTPRequestBearer("PUT", "/setting/", adminToken.Token, bytes.NewBufferString("[]"))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestApiTokenCannotChangeOwnAccount(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	token := createTestApiToken(t, admin.ID, `{"name": "Test", "scopes": ["users:read", "admin:users"]}`)
+
+	payload := `{"password": "` + TestPasswordNew + `"}`
+	for _, url := range []string{"/user/me/password", "/user/" + admin.ID + "/password", "/user/" + user.ID + "/password"} {
+		req := NewHTTPRequestBearer("PUT", url, token.Token, bytes.NewBufferString(payload))
+		res := ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	}
+
+	payload = `{"email": "` + admin.Email + `", "firstname": "John", "lastname": "Doe", "role": 20, "password": "` + TestPasswordNew + `"}`
+	req := NewHTTPRequestBearer("PUT", "/user/"+admin.ID, token.Token, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	e, _ := GetUserRepository().GetOne(admin.ID)
+	CheckTestBool(t, false, GetUserRepository().CheckPassword(string(e.HashedPassword), TestPasswordNew))
+
+	// Other users can still be managed
+	req = NewHTTPRequestBearer("GET", "/user/"+user.ID, token.Token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
+func TestApiTokenLastUsed(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	token := createTestApiToken(t, user.ID, `{"name": "Test", "scopes": ["bookings:read"]}`)
+
+	req := NewHTTPRequestBearer("GET", "/booking/", token.Token, nil)
+	req.RemoteAddr = "192.0.2.10:1234"
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	var e *ApiToken
+	for i := 0; i < 50; i++ {
+		e, _ = GetApiTokenRepository().GetOne(token.ID)
+		if e != nil && e.LastUsed != nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	CheckTestBool(t, true, e.LastUsed != nil)
+	CheckTestString(t, "192.0.2.10", e.LastUsedIP)
+}
+
+func TestApiTokenExpired(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	payload := `{"name": "Test", "scopes": ["bookings:read"], "expiry": "` + time.Now().Add(1*time.Hour).Format(time.RFC3339) + `"}`
+	token := createTestApiToken(t, user.ID, payload)
+
+	req := NewHTTPRequestBearer("GET", "/booking/", token.Token, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	_, err := GetDatabase().DB().Exec("UPDATE api_tokens SET expiry = $1 WHERE id = $2", time.Now().UTC().Add(-1*time.Minute), token.ID)
+	CheckTestIsNil(t, err)
+	req = NewHTTPRequestBearer("GET", "/booking/", token.Token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestApiTokenDelete(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	other := CreateTestUserInOrg(org)
+	token := createTestApiToken(t, user.ID, `{"name": "Test", "scopes": ["bookings:read"]}`)
+
+	req := NewHTTPRequest("DELETE", "/api-token/"+token.ID, other.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/api-token/"+token.ID, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequestBearer("GET", "/booking/", token.Token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestApiTokenAdminManageServiceAccount(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	sa := CreateTestServiceAccountRW(org)
+
+	// Regular users cannot issue tokens for service accounts
+	payload := `{"name": "Test", "scopes": ["bookings:read"], "userId": "` + sa.ID + `"}`
+	req := NewHTTPRequest("POST", "/api-token/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Admins cannot issue tokens for regular users
+	payload = `{"name": "Test", "scopes": ["bookings:read"], "userId": "` + user.ID + `"}`
+	req = NewHTTPRequest("POST", "/api-token/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	token := createTestApiToken(t, admin.ID, `{"name": "Test", "scopes": ["bookings:read"], "userId": "`+sa.ID+`"}`)
+	CheckTestString(t, sa.ID, token.UserID)
+
+	req = NewHTTPRequest("GET", "/api-token/user/"+sa.ID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetApiTokenResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+
+	req = NewHTTPRequest("GET", "/api-token/user/"+sa.ID, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequestBearer("GET", "/user/me", token.Token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/api-token/"+token.ID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
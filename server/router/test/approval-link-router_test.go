@@ -0,0 +1,144 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func createTestApprovalLink(bookingID, approverID string) string {
+	authState := &AuthState{
+		AuthProviderID: GetSettingsRepository().GetNullUUID(),
+		Expiry:         time.Now().Add(time.Hour),
+		AuthStateType:  AuthBookingApproval,
+		Payload:        bookingID + ":" + approverID,
+	}
+	GetAuthStateRepository().Create(authState)
+	return GetActionLinkToken(authState.ID)
+}
+
+func TestApprovalLinkApprove(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 2)
+	approver, bookingID := b.Approvers[0][0], b.ID
+	token := createTestApprovalLink(bookingID, approver.ID)
+
+	req := NewHTTPRequest("GET", "/approval-link/"+token, "", nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *GetApprovalLinkResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestString(t, bookingID, resBody.Booking.ID)
+	CheckTestString(t, approver.Email, resBody.ApproverEmail)
+	CheckTestBool(t, false, resBody.Booking.Approved)
+
+	req = NewHTTPRequest("POST", "/approval-link/"+token, "", bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	booking, err := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, err == nil)
+	CheckTestBool(t, true, booking.Approved)
+
+	// Links can only be used once
+	req = NewHTTPRequest("POST", "/approval-link/"+token, "", bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestApprovalLinkDecline(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 2)
+	approver, bookingID := b.Approvers[0][0], b.ID
+	token := createTestApprovalLink(bookingID, approver.ID)
+
+	req := NewHTTPRequest("POST", "/approval-link/"+token, "", bytes.NewBufferString(`{"approved": false}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	_, err := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, err != nil)
+}
+
+func TestApprovalLinkMissingDecision(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1)
+	approver, bookingID := b.Approvers[0][0], b.ID
+	token := createTestApprovalLink(bookingID, approver.ID)
+
+	req := NewHTTPRequest("POST", "/approval-link/"+token, "", bytes.NewBufferString(`{}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	booking, err := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, err == nil)
+	CheckTestBool(t, false, booking.Approved)
+
+	// The link can still be used
+	req = NewHTTPRequest("POST", "/approval-link/"+token, "", bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
+
+func TestApprovalLinkOtherApproverActedFirst(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 2)
+	approver1, approver2, bookingID := b.Approvers[0][0], b.Approvers[0][1], b.ID
+	token1 := createTestApprovalLink(bookingID, approver1.ID)
+	token2 := createTestApprovalLink(bookingID, approver2.ID)
+
+	req := NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver2.ID, bytes.NewBufferString(`{"approved": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	for _, token := range []string{token1, token2} {
+		req = NewHTTPRequest("GET", "/approval-link/"+token, "", nil)
+		res = ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+	}
+}
+
+func TestApprovalLinkInvalidSignature(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 2)
+	approver, bookingID := b.Approvers[0][0], b.ID
+	token := createTestApprovalLink(bookingID, approver.ID)
+	id, ok := ParseActionLinkToken(token)
+	CheckTestBool(t, true, ok)
+
+	req := NewHTTPRequest("POST", "/approval-link/"+id+".invalid", "", bytes.NewBufferString(`{"approved": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+	req = NewHTTPRequest("POST", "/approval-link/"+id, "", bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+
+	booking, _ := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, false, booking.Approved)
+}
+
+func TestApprovalLinkNoLongerApprover(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 2)
+	org, approver, bookingID := b.Org, b.Approvers[0][0], b.ID
+	other := CreateTestUserOrgAdmin(org)
+	token := createTestApprovalLink(bookingID, other.ID)
+
+	req := NewHTTPRequest("POST", "/approval-link/"+token, "", bytes.NewBufferString(`{"approved": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// The link of a valid approver still works
+	token = createTestApprovalLink(bookingID, approver.ID)
+	req = NewHTTPRequest("POST", "/approval-link/"+token, "", bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
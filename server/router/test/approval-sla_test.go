@@ -0,0 +1,217 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func TestApprovalTwoStage(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1, 1)
+	approver1, approver2, bookingID := b.Approvers[0][0], b.Approvers[1][0], b.ID
+
+	// Second-level approvers can't approve before the first level
+	req := NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver2.ID, bytes.NewBufferString(`{"approved": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver1.ID, bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	booking, _ := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, false, booking.Approved)
+	CheckTestInt(t, ApprovalLevelSecond, booking.ApprovalLevel)
+
+	// First-level approvers can't approve the second level
+	req = NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver1.ID, bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver2.ID, bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	booking, _ = GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, booking.Approved)
+}
+
+func TestApprovalTwoStageSameApprover(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1, 1)
+	approver1, approver2, bookingID := b.Approvers[0][0], b.Approvers[1][0], b.ID
+	secondLevelGroupIDs, _ := GetSpaceRepository().GetApproverGroupIDsForLevel(b.Space.ID, ApprovalLevelSecond)
+	secondLevelGroup, _ := GetGroupRepository().GetOne(secondLevelGroupIDs[0])
+	GetGroupRepository().AddMembers(secondLevelGroup, []string{approver1.ID})
+
+	req := NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver1.ID, bytes.NewBufferString(`{"approved": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	booking, _ := GetBookingRepository().GetOne(bookingID)
+	CheckTestInt(t, ApprovalLevelSecond, booking.ApprovalLevel)
+	CheckTestString(t, approver1.ID, string(booking.ApprovalFirstApproverID))
+
+	// Members of both levels can't approve both levels on their own
+	req = NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver1.ID, bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	count, _ := GetBookingRepository().GetBookingsCountRequiringApproval(approver1.ID)
+	CheckTestInt(t, 0, count)
+	count, _ = GetBookingRepository().GetBookingsCountRequiringApproval(approver2.ID)
+	CheckTestInt(t, 1, count)
+
+	req = NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver2.ID, bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	booking, _ = GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, booking.Approved)
+}
+
+func TestApprovalTwoStageDeclineOnFirstLevel(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1, 1)
+	approver1, bookingID := b.Approvers[0][0], b.ID
+
+	req := NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", approver1.ID, bytes.NewBufferString(`{"approved": false}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	_, err := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, err != nil)
+}
+
+func TestApprovalSLAReminder(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1)
+	org, bookingID := b.Org, b.ID
+	GetSettingsRepository().Set(org.ID, SettingApprovalReminderHours.Name, "24")
+
+	ProcessPendingApprovalsAt(time.Now().UTC().Add(23 * time.Hour))
+	booking, _ := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, booking.ApprovalReminderSentAtUTC == nil)
+
+	ProcessPendingApprovalsAt(time.Now().UTC().Add(25 * time.Hour))
+	booking, _ = GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, booking.ApprovalReminderSentAtUTC != nil)
+	CheckTestBool(t, false, booking.Approved)
+}
+
+func TestApprovalSLAEscalation(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1)
+	org, bookingID := b.Org, b.ID
+	fallback := CreateTestUserOrgAdmin(org)
+	fallbackGroup := CreateTestGroup(org, fallback)
+	GetSettingsRepository().Set(org.ID, SettingApprovalEscalationHours.Name, "48")
+	GetSettingsRepository().Set(org.ID, SettingApprovalEscalationGroupID.Name, fallbackGroup.ID)
+
+	req := NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", fallback.ID, bytes.NewBufferString(`{"approved": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	ProcessPendingApprovalsAt(time.Now().UTC().Add(49 * time.Hour))
+	booking, _ := GetBookingRepository().GetOne(bookingID)
+	CheckTestString(t, fallbackGroup.ID, string(booking.ApprovalEscalationGroupID))
+
+	req = NewHTTPRequest("POST", "/booking/"+bookingID+"/approve", fallback.ID, bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	booking, _ = GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, booking.Approved)
+}
+
+func TestApprovalSLAEscalationForeignGroup(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1)
+	org, bookingID := b.Org, b.ID
+	org2 := CreateTestOrg("test2.com")
+	foreignGroup := CreateTestGroup(org2, CreateTestUserOrgAdmin(org2))
+	GetSettingsRepository().Set(org.ID, SettingApprovalEscalationHours.Name, "48")
+	GetSettingsRepository().Set(org.ID, SettingApprovalEscalationGroupID.Name, foreignGroup.ID)
+
+	ProcessPendingApprovalsAt(time.Now().UTC().Add(49 * time.Hour))
+	booking, _ := GetBookingRepository().GetOne(bookingID)
+	CheckTestString(t, "", string(booking.ApprovalEscalationGroupID))
+}
+
+func TestApprovalSLAExpiryDecline(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1)
+	org, bookingID := b.Org, b.ID
+	GetSettingsRepository().Set(org.ID, SettingApprovalExpiryAction.Name, SettingApprovalExpiryActionDecline)
+	GetSettingsRepository().Set(org.ID, SettingApprovalExpiryHours.Name, "12")
+	enter, _ := time.Parse(time.RFC3339, "2030-09-01T08:30:00Z")
+
+	ProcessPendingApprovalsAt(enter.Add(-13 * time.Hour))
+	_, err := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, err == nil)
+
+	ProcessPendingApprovalsAt(enter.Add(-11 * time.Hour))
+	_, err = GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, err != nil)
+}
+
+func TestApprovalSLAExpiryDeclineLocationTimezone(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1)
+	b.Location.Timezone = "America/New_York"
+	GetLocationRepository().Update(b.Location)
+	GetSettingsRepository().Set(b.Org.ID, SettingApprovalExpiryAction.Name, SettingApprovalExpiryActionDecline)
+	GetSettingsRepository().Set(b.Org.ID, SettingApprovalExpiryHours.Name, "12")
+	// 08:30 in New York (UTC-4) is 12:30 UTC
+	enter, _ := time.Parse(time.RFC3339, "2030-09-01T12:30:00Z")
+
+	ProcessPendingApprovalsAt(enter.Add(-13 * time.Hour))
+	_, err := GetBookingRepository().GetOne(b.ID)
+	CheckTestBool(t, true, err == nil)
+
+	ProcessPendingApprovalsAt(enter.Add(-11 * time.Hour))
+	_, err = GetBookingRepository().GetOne(b.ID)
+	CheckTestBool(t, true, err != nil)
+}
+
+func TestApprovalSLAExpiryApprove(t *testing.T) {
+	ClearTestDB()
+	b := CreateTestApprovalBooking(t, 1, 1)
+	org, bookingID := b.Org, b.ID
+	GetSettingsRepository().Set(org.ID, SettingApprovalExpiryAction.Name, SettingApprovalExpiryActionApprove)
+	GetSettingsRepository().Set(org.ID, SettingApprovalExpiryHours.Name, "12")
+	enter, _ := time.Parse(time.RFC3339, "2030-09-01T08:30:00Z")
+
+	ProcessPendingApprovalsAt(enter.Add(-11 * time.Hour))
+	booking, err := GetBookingRepository().GetOne(bookingID)
+	CheckTestBool(t, true, err == nil)
+	CheckTestBool(t, true, booking.Approved)
+}
+
+func TestApprovalSLASettingsValidation(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	for _, payload := range []string{
+		`{"value": "-1"}`,
+		`{"value": "10000"}`,
+	} {
+		req := NewHTTPRequest("PUT", "/setting/"+SettingApprovalReminderHours.Name, admin.ID, bytes.NewBufferString(payload))
+		res := ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	}
+	req := NewHTTPRequest("PUT", "/setting/"+SettingApprovalExpiryAction.Name, admin.ID, bytes.NewBufferString(`{"value": "ignore"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	req = NewHTTPRequest("PUT", "/setting/"+SettingApprovalEscalationGroupID.Name, admin.ID, bytes.NewBufferString(`{"value": "abc"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	req = NewHTTPRequest("PUT", "/setting/"+SettingApprovalExpiryAction.Name, admin.ID, bytes.NewBufferString(`{"value": "decline"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	req = NewHTTPRequest("PUT", "/setting/"+SettingApprovalReminderHours.Name, admin.ID, bytes.NewBufferString(`{"value": "24"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
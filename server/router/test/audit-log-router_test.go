@@ -0,0 +1,228 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func TestGetAuditChanges(t *testing.T) {
+	type entity struct {
+		Name         string `json:"name"`
+		Enabled      bool   `json:"enabled"`
+		ClientSecret string `json:"clientSecret"`
+	}
+	before := &entity{Name: "A", Enabled: true, ClientSecret: "s1"}
+	after := &entity{Name: "B", Enabled: true, ClientSecret: "s2"}
+
+	changes := GetAuditChanges(before, after)
+	CheckTestInt(t, 2, len(changes))
+	CheckTestString(t, "A", changes["name"].Old.(string))
+	CheckTestString(t, "B", changes["name"].New.(string))
+	CheckTestString(t, "[redacted]", changes["clientSecret"].Old.(string))
+	CheckTestString(t, "[redacted]", changes["clientSecret"].New.(string))
+
+	changes = GetAuditChanges(nil, after)
+	CheckTestInt(t, 3, len(changes))
+	CheckTestIsNil(t, changes["name"].Old)
+	CheckTestBool(t, true, changes["enabled"].New.(bool))
+
+	var nilEntity *entity
+	changes = GetAuditChanges(before, nilEntity)
+	CheckTestInt(t, 3, len(changes))
+	CheckTestIsNil(t, changes["name"].New)
+
+	CheckTestInt(t, 0, len(GetAuditChanges(before, before)))
+}
+
+func TestAuditLogForbidden(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	loginResponse := LoginTestUser(user.ID)
+
+	req := NewHTTPRequest("GET", "/audit-log/", loginResponse.UserID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("GET", "/audit-log/export", loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestAuditLogSettingChange(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	loginResponse := LoginTestUser(admin.ID)
+
+	payload := `{"value": "7"}`
+	req := NewHTTPRequest("PUT", "/setting/"+SettingMaxBookingsPerUser.Name, loginResponse.UserID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	// Setting the same value again does not create another entry
+	req = NewHTTPRequest("PUT", "/setting/"+SettingMaxBookingsPerUser.Name, loginResponse.UserID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/audit-log/?entityType="+AuditEntitySetting, loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *GetAuditLogListResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 1, resBody.Total)
+	CheckTestInt(t, 1, len(resBody.Items))
+	e := resBody.Items[0]
+	CheckTestString(t, AuditActionUpdate, e.Action)
+	CheckTestString(t, SettingMaxBookingsPerUser.Name, e.EntityID)
+	CheckTestString(t, admin.ID, e.ActorUserID)
+	CheckTestString(t, admin.Email, e.ActorEmail)
+	var changes map[string]*AuditChange
+	json.Unmarshal(e.Changes, &changes)
+	CheckTestString(t, "7", changes[SettingMaxBookingsPerUser.Name].New.(string))
+}
+
+func TestAuditLogFilterAndPaging(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	admin := CreateTestUserOrgAdmin(org)
+	loginResponse := LoginTestUser(admin.ID)
+
+	req := NewHTTPRequest("POST", "/group/", loginResponse.UserID, bytes.NewBufferString(`{"name": "G1"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	id := res.Header().Get("X-Object-Id")
+	req = NewHTTPRequest("PUT", "/group/"+id, loginResponse.UserID, bytes.NewBufferString(`{"name": "G2"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	req = NewHTTPRequest("DELETE", "/group/"+id, loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	// Entries of other organizations are never returned
+	org2 := CreateTestOrg("test2.com")
+	GetAuditLogRepository().Create(&AuditLogEntry{
+		OrganizationID: org2.ID,
+		Timestamp:      time.Now().UTC(),
+		Action:         AuditActionCreate,
+		EntityType:     AuditEntityGroup,
+		EntityID:       id,
+	})
+
+	req = NewHTTPRequest("GET", "/audit-log/?entityType="+AuditEntityGroup+"&entityId="+id, loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *GetAuditLogListResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 3, resBody.Total)
+	CheckTestString(t, AuditActionDelete, resBody.Items[0].Action)
+	CheckTestString(t, AuditActionUpdate, resBody.Items[1].Action)
+	CheckTestString(t, AuditActionCreate, resBody.Items[2].Action)
+	var changes map[string]*AuditChange
+	json.Unmarshal(resBody.Items[1].Changes, &changes)
+	CheckTestInt(t, 1, len(changes))
+	CheckTestString(t, "G1", changes["name"].Old.(string))
+	CheckTestString(t, "G2", changes["name"].New.(string))
+
+	req = NewHTTPRequest("GET", "/audit-log/?action="+AuditActionUpdate+"&actor=test.com", loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 1, resBody.Total)
+
+	req = NewHTTPRequest("GET", "/audit-log/?actor=nobody", loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 0, resBody.Total)
+
+	req = NewHTTPRequest("GET", "/audit-log/?entityType="+AuditEntityGroup+"&limit=2&offset=2", loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 3, resBody.Total)
+	CheckTestInt(t, 1, len(resBody.Items))
+	CheckTestString(t, AuditActionCreate, resBody.Items[0].Action)
+
+	end := url.QueryEscape(time.Now().Add(-1 * time.Hour).Format(time.RFC3339Nano))
+	req = NewHTTPRequest("GET", "/audit-log/?end="+end, loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 0, resBody.Total)
+
+	req = NewHTTPRequest("GET", "/audit-log/?start=invalid", loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+}
+
+func TestAuditLogExport(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	loginResponse := LoginTestUser(admin.ID)
+
+	GetAuditLogRepository().Create(&AuditLogEntry{
+		OrganizationID: org.ID,
+		Timestamp:      time.Now().UTC(),
+		ActorUserID:    admin.ID,
+		ActorEmail:     admin.Email,
+		Action:         AuditActionCreate,
+		EntityType:     AuditEntityLocation,
+		EntityID:       "1",
+		EntityName:     "=HYPERLINK(\"http://test\")",
+		Changes:        `{"name":{"old":null,"new":"x"}}`,
+	})
+
+	req := NewHTTPRequest("GET", "/audit-log/export", loginResponse.UserID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	CheckTestString(t, "text/csv; charset=utf-8", res.Header().Get("Content-Type"))
+	records, err := csv.NewReader(res.Body).ReadAll()
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 2, len(records))
+	CheckTestString(t, "entityName", records[0][6])
+	CheckTestString(t, admin.Email, records[1][2])
+	CheckTestString(t, AuditActionCreate, records[1][3])
+	CheckTestString(t, "'=HYPERLINK(\"http://test\")", records[1][6])
+	CheckTestString(t, `{"name":{"old":null,"new":"x"}}`, records[1][8])
+}
+
+func TestAuditLogUserRoleChange(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	loginResponse := LoginTestUser(admin.ID)
+
+	payload := `{"email": "` + user.Email + `", "firstname": "F", "lastname": "L", "role": ` + strconv.Itoa(int(UserRoleSpaceAdmin)) + `}`
+	req := NewHTTPRequest("PUT", "/user/"+user.ID, loginResponse.UserID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	list, err := GetAuditLogRepository().GetFiltered(&AuditLogFilter{
+		OrganizationID: org.ID,
+		Start:          time.Now().Add(-1 * time.Hour),
+		End:            time.Now().Add(1 * time.Hour),
+		EntityType:     AuditEntityUser,
+	}, 0, 0)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, user.ID, list[0].EntityID)
+	var changes map[string]*AuditChange
+	json.Unmarshal([]byte(list[0].Changes), &changes)
+	CheckTestInt(t, int(UserRoleUser), int(changes["role"].Old.(float64)))
+	CheckTestInt(t, int(UserRoleSpaceAdmin), int(changes["role"].New.(float64)))
+}
@@ -142,6 +142,87 @@ func TestAuthRefreshNonExistent(t *testing.T) {
 	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
 }
 
+func loginTestUserWithPassword(t *testing.T, org *Organization, user *User) *JWTResponse {
+	payload := "{ \"email\": \"" + user.Email + "\", \"password\": \"" + TestPassword + "\", \"organizationId\": \"" + org.ID + "\" }"
+	req := NewHTTPRequest("POST", "/auth/login", "", bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *JWTResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	return resBody
+}
+
+func TestAuthRefreshReuseRevokesFamily(t *testing.T) {
+	ClearTestDB()
+
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(TestPassword))
+	GetUserRepository().Update(user)
+	loginRes := loginTestUserWithPassword(t, org, user)
+
+	// Rotate refresh token
+	payload := "{ \"refreshToken\": \"" + loginRes.RefreshToken + "\" }"
+	req := NewHTTPRequest("POST", "/auth/refresh", "", bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var refreshRes *JWTResponse
+	json.Unmarshal(res.Body.Bytes(), &refreshRes)
+	oldToken, _ := GetRefreshTokenRepository().GetOne(loginRes.RefreshToken)
+	newToken, _ := GetRefreshTokenRepository().GetOne(refreshRes.RefreshToken)
+	CheckTestBool(t, true, oldToken.Rotated != nil)
+	CheckTestBool(t, true, newToken.Rotated == nil)
+	CheckTestString(t, oldToken.FamilyID, newToken.FamilyID)
+
+	// Reuse the rotated refresh token
+	payload = "{ \"refreshToken\": \"" + loginRes.RefreshToken + "\" }"
+	req = NewHTTPRequest("POST", "/auth/refresh", "", bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+
+	// The whole family and the session are revoked
+	payload = "{ \"refreshToken\": \"" + refreshRes.RefreshToken + "\" }"
+	req = NewHTTPRequest("POST", "/auth/refresh", "", bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+	session, _ := GetSessionRepository().GetOne(newToken.SessionID)
+	CheckTestBool(t, true, session == nil)
+	req = NewHTTPRequestWithAccessToken("GET", "/user/me", refreshRes.AccessToken, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+
+	// Reuse is recorded as auth event
+	successful := false
+	list, err := GetAuthAttemptRepository().GetFiltered(&AuthAttemptFilter{OrganizationID: org.ID, Method: AuthMethodRefreshToken, Successful: &successful}, 10, 0)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, AuthErrorRefreshTokenReuse, list[0].ErrorCode)
+}
+
+func TestAuthRefreshOtherSessionUnaffected(t *testing.T) {
+	ClearTestDB()
+
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(TestPassword))
+	GetUserRepository().Update(user)
+	loginRes1 := loginTestUserWithPassword(t, org, user)
+	loginRes2 := loginTestUserWithPassword(t, org, user)
+
+	payload := "{ \"refreshToken\": \"" + loginRes1.RefreshToken + "\" }"
+	req := NewHTTPRequest("POST", "/auth/refresh", "", bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	req = NewHTTPRequest("POST", "/auth/refresh", "", bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+
+	payload = "{ \"refreshToken\": \"" + loginRes2.RefreshToken + "\" }"
+	req = NewHTTPRequest("POST", "/auth/refresh", "", bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
 func TestAuthPasswordReset(t *testing.T) {
 	ClearTestDB()
 
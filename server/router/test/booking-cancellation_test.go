@@ -0,0 +1,174 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func TestBookingsDeleteWithReason(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	_, space := CreateTestLocationAndSpace(org)
+	space.Name = "Desk 1"
+	GetSpaceRepository().Update(space)
+	enter := time.Date(2030, 9, 1, 8, 30, 0, 0, time.UTC)
+	leave := time.Date(2030, 9, 1, 17, 0, 0, 0, time.UTC)
+	id := CreateTestBooking(user, space, enter, leave, true).ID
+
+	req := NewHTTPRequest("DELETE", "/booking/"+id+"?reason="+url.QueryEscape("Space reassigned"), admin.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	list, err := GetBookingCancellationRepository().GetAllByOrg(org.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "")
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, id, list[0].BookingID)
+	CheckTestString(t, "Space reassigned", list[0].Reason)
+	CheckTestString(t, admin.ID, list[0].CancelledByUserID)
+	CheckTestString(t, user.Email, list[0].UserEmail)
+	CheckTestString(t, "Desk 1", list[0].SpaceName)
+	CheckTestBool(t, false, list[0].Bulk)
+}
+
+func TestBookingsDeleteReasonTooLong(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	_, space := CreateTestLocationAndSpace(org)
+	enter := time.Date(2030, 9, 1, 8, 30, 0, 0, time.UTC)
+	leave := time.Date(2030, 9, 1, 17, 0, 0, 0, time.UTC)
+	id := CreateTestBooking(admin, space, enter, leave, true).ID
+
+	req := NewHTTPRequest("DELETE", "/booking/"+id+"?reason="+strings.Repeat("x", 501), admin.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	_, err := GetBookingRepository().GetOne(id)
+	CheckTestIsNil(t, err)
+}
+
+func TestBookingsBulkCancelBySpace(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user1 := CreateTestUserInOrg(org)
+	user2 := CreateTestUserInOrg(org)
+	_, space1 := CreateTestLocationAndSpace(org)
+	_, space2 := CreateTestLocationAndSpace(org)
+	enter := time.Date(2030, 9, 1, 8, 30, 0, 0, time.UTC)
+	leave := time.Date(2030, 9, 1, 17, 0, 0, 0, time.UTC)
+	id1 := CreateTestBooking(user1, space1, enter, leave, true).ID
+	id2 := CreateTestBooking(user2, space1, enter.AddDate(0, 0, 1), leave.AddDate(0, 0, 1), true).ID
+	id3 := CreateTestBooking(user2, space2, enter, leave, true).ID
+	id4 := CreateTestBooking(user1, space1, enter.AddDate(0, 0, 4), leave.AddDate(0, 0, 4), true).ID
+
+	payload := `{"start": "2030-09-01T00:00:00Z", "end": "2030-09-03T00:00:00Z", "spaceId": "` + space1.ID + `", "reason": "Emergency closure"}`
+	req := NewHTTPRequest("POST", "/booking/cancel/", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *BulkCancelBookingsResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 2, resBody.Count)
+	CheckTestInt(t, 0, resBody.Failed)
+
+	for _, id := range []string{id1, id2} {
+		_, err := GetBookingRepository().GetOne(id)
+		CheckTestBool(t, true, err != nil)
+	}
+	for _, id := range []string{id3, id4} {
+		_, err := GetBookingRepository().GetOne(id)
+		CheckTestIsNil(t, err)
+	}
+
+	start := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339))
+	end := url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+	req = NewHTTPRequest("GET", "/booking/report/cancellations/?start="+start+"&end="+end, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var report []*GetBookingCancellationResponse
+	json.Unmarshal(res.Body.Bytes(), &report)
+	CheckTestInt(t, 2, len(report))
+	for _, e := range report {
+		CheckTestString(t, "Emergency closure", e.Reason)
+		CheckTestString(t, admin.Email, e.CancelledByEmail)
+		CheckTestBool(t, true, e.Bulk)
+	}
+}
+
+func TestBookingsBulkCancelByUser(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user1 := CreateTestUserInOrg(org)
+	user2 := CreateTestUserInOrg(org)
+	_, space1 := CreateTestLocationAndSpace(org)
+	_, space2 := CreateTestLocationAndSpace(org)
+	enter := time.Date(2030, 9, 1, 8, 30, 0, 0, time.UTC)
+	leave := time.Date(2030, 9, 1, 17, 0, 0, 0, time.UTC)
+	id1 := CreateTestBooking(user1, space1, enter, leave, true).ID
+	id2 := CreateTestBooking(user2, space2, enter, leave, true).ID
+
+	payload := `{"start": "2030-09-01T00:00:00Z", "end": "2030-09-02T00:00:00Z", "userId": "` + user1.ID + `", "reason": "Left the company"}`
+	req := NewHTTPRequest("POST", "/booking/cancel/", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	_, err := GetBookingRepository().GetOne(id1)
+	CheckTestBool(t, true, err != nil)
+	_, err = GetBookingRepository().GetOne(id2)
+	CheckTestIsNil(t, err)
+}
+
+func TestBookingsBulkCancelInvalid(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	org2 := CreateTestOrg("test2.com")
+	_, foreignSpace := CreateTestLocationAndSpace(org2)
+
+	for _, payload := range []string{
+		`{"start": "2030-09-01T00:00:00Z", "end": "2030-09-02T00:00:00Z"}`,
+		`{"start": "2030-09-01T00:00:00Z", "end": "2030-09-02T00:00:00Z", "reason": "  "}`,
+		`{"start": "2030-09-02T00:00:00Z", "end": "2030-09-01T00:00:00Z", "reason": "Closed"}`,
+		`{"start": "2030-09-01T00:00:00Z", "end": "2030-09-02T00:00:00Z", "reason": "Closed", "spaceId": "` + foreignSpace.ID + `"}`,
+	} {
+		req := NewHTTPRequest("POST", "/booking/cancel/", admin.ID, bytes.NewBufferString(payload))
+		res := ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	}
+}
+
+func TestBookingsBulkCancelForbidden(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	_, space := CreateTestLocationAndSpace(org)
+	enter := time.Date(2030, 9, 1, 8, 30, 0, 0, time.UTC)
+	leave := time.Date(2030, 9, 1, 17, 0, 0, 0, time.UTC)
+	id := CreateTestBooking(user, space, enter, leave, true).ID
+
+	payload := `{"start": "2030-09-01T00:00:00Z", "end": "2030-09-02T00:00:00Z", "reason": "Closed"}`
+	req := NewHTTPRequest("POST", "/booking/cancel/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	_, err := GetBookingRepository().GetOne(id)
+	CheckTestIsNil(t, err)
+
+	start := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339))
+	end := url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+	req = NewHTTPRequest("GET", "/booking/report/cancellations/?start="+start+"&end="+end, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
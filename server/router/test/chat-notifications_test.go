@@ -0,0 +1,164 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func waitForChatTestMessage(t *testing.T, receiver *webhookTestReceiver, num int) string {
+	for i := 0; i < 100; i++ {
+		if receiver.count() >= num {
+			receiver.mu.Lock()
+			defer receiver.mu.Unlock()
+			return string(receiver.bodies[num-1])
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("expected %d chat messages, got %d", num, receiver.count())
+	return ""
+}
+
+func TestChatNotificationPreferences(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	loginResponse := LoginTestUser(user.ID)
+
+	payload := `{"value": "ftp://hooks.test.com/abc"}`
+	req := NewHTTPRequest("PUT", "/preference/"+PreferenceChatWebhookURL.Name, loginResponse.UserID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	payload = `{"value": "irc"}`
+	req = NewHTTPRequest("PUT", "/preference/"+PreferenceChatWebhookFormat.Name, loginResponse.UserID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	payload = `{"value": "https://hooks.test.com/abc"}`
+	req = NewHTTPRequest("PUT", "/preference/"+PreferenceChatWebhookURL.Name, loginResponse.UserID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	// The URL contains a secret and is stored encrypted
+	stored, _ := GetUserPreferencesRepository().Get(user.ID, PreferenceChatWebhookURL.Name)
+	CheckTestBool(t, false, strings.Contains(stored, "hooks.test.com"))
+
+	req = NewHTTPRequest("GET", "/preference/"+PreferenceChatWebhookURL.Name, loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody string
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestString(t, "https://hooks.test.com/abc", resBody)
+}
+
+func TestChatNotificationBookingCreated(t *testing.T) {
+	ClearTestDB()
+	receiver := newWebhookTestReceiver(http.StatusOK)
+	defer receiver.receiver.Close()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	loginResponse := LoginTestUser(user.ID)
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	_, space := CreateTestLocationAndSpace(org)
+	space.Name = "Desk 42"
+	GetSpaceRepository().Update(space)
+
+	payload := `[{"name": "chat_webhook_url", "value": "` + receiver.receiver.URL + `"}, {"name": "chat_webhook_format", "value": "mattermost"}, {"name": "chat_notifications", "value": "1"}]`
+	req := NewHTTPRequest("PUT", "/preference/", loginResponse.UserID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	payload = "{\"spaceId\": \"" + space.ID + "\", \"enter\": \"2030-09-01T08:30:00Z\", \"leave\": \"2030-09-01T17:00:00Z\"}"
+	req = NewHTTPRequest("POST", "/booking/", loginResponse.UserID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	id := res.Header().Get("X-Object-Id")
+
+	body := waitForChatTestMessage(t, receiver, 1)
+	CheckTestBool(t, true, strings.Contains(body, "\"attachments\""))
+	CheckTestBool(t, true, strings.Contains(body, "Desk 42"))
+
+	req = NewHTTPRequest("DELETE", "/booking/"+id, loginResponse.UserID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	waitForChatTestMessage(t, receiver, 2)
+}
+
+func TestChatNotificationDisabled(t *testing.T) {
+	ClearTestDB()
+	receiver := newWebhookTestReceiver(http.StatusOK)
+	defer receiver.receiver.Close()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	loginResponse := LoginTestUser(user.ID)
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	_, space := CreateTestLocationAndSpace(org)
+	GetUserPreferencesRepository().Set(user.ID, PreferenceChatNotifications.Name, "0")
+	encrypted, _ := EncryptString(receiver.receiver.URL)
+	GetUserPreferencesRepository().Set(user.ID, PreferenceChatWebhookURL.Name, encrypted)
+
+	payload := "{\"spaceId\": \"" + space.ID + "\", \"enter\": \"2030-09-01T08:30:00Z\", \"leave\": \"2030-09-01T17:00:00Z\"}"
+	req := NewHTTPRequest("POST", "/booking/", loginResponse.UserID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	time.Sleep(250 * time.Millisecond)
+	CheckTestInt(t, 0, receiver.count())
+}
+
+func TestChatNotificationApprovalRequest(t *testing.T) {
+	ClearTestDB()
+	receiver := newWebhookTestReceiver(http.StatusOK)
+	defer receiver.receiver.Close()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	adminLogin := LoginTestUser(admin.ID)
+	user := CreateTestUserInOrg(org)
+	loginResponse := LoginTestUser(user.ID)
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	group := &Group{Name: "Approvers", OrganizationID: org.ID}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{admin.ID})
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	_, space := CreateTestLocationAndSpace(org)
+	CheckTestIsNil(t, GetSpaceRepository().AddApprovers(space, []string{group.ID}))
+
+	payload := `[{"name": "chat_approval_webhook_url", "value": "` + receiver.receiver.URL + `"}, {"name": "chat_approval_webhook_format", "value": "teams"}]`
+	req := NewHTTPRequest("PUT", "/setting/", adminLogin.UserID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	payload = "{\"spaceId\": \"" + space.ID + "\", \"enter\": \"2030-09-01T08:30:00Z\", \"leave\": \"2030-09-01T17:00:00Z\"}"
+	req = NewHTTPRequest("POST", "/booking/", loginResponse.UserID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	body := waitForChatTestMessage(t, receiver, 1)
+	CheckTestBool(t, true, strings.Contains(body, "AdaptiveCard"))
+	CheckTestBool(t, true, strings.Contains(body, user.Email))
+}
+
+func TestChatNotificationApprovalSettingsInvalid(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	loginResponse := LoginTestUser(admin.ID)
+
+	payload := `{"value": "hooks.test.com/abc"}`
+	req := NewHTTPRequest("PUT", "/setting/"+SettingChatApprovalWebhookURL.Name, loginResponse.UserID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	payload = `{"value": "irc"}`
+	req = NewHTTPRequest("PUT", "/setting/"+SettingChatApprovalWebhookFormat.Name, loginResponse.UserID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+}
@@ -0,0 +1,225 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func TestDelegationCRUD(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	principal := CreateTestUserInOrg(org)
+	delegate := CreateTestUserInOrg(org)
+	other := CreateTestUserInOrg(org)
+
+	payload := `{"delegateEmail": "` + delegate.Email + `"}`
+	req := NewHTTPRequest("POST", "/delegation/", principal.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	id := res.Header().Get("X-Object-Id")
+
+	// Duplicate
+	req = NewHTTPRequest("POST", "/delegation/", principal.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusConflict, res.Code)
+
+	// Self
+	payload = `{"delegateEmail": "` + principal.Email + `"}`
+	req = NewHTTPRequest("POST", "/delegation/", principal.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	// Unknown user
+	payload = `{"delegateEmail": "nobody@test.com"}`
+	req = NewHTTPRequest("POST", "/delegation/", principal.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+
+	req = NewHTTPRequest("GET", "/delegation/", principal.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetDelegationResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, delegate.ID, list[0].DelegateUserID)
+	CheckTestString(t, delegate.Email, list[0].DelegateEmail)
+	CheckTestBool(t, true, list[0].Active)
+
+	req = NewHTTPRequest("GET", "/delegation/principals", delegate.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var principals []*GetUserInfoSmall
+	json.Unmarshal(res.Body.Bytes(), &principals)
+	CheckTestInt(t, 1, len(principals))
+	CheckTestString(t, principal.ID, principals[0].UserID)
+
+	req = NewHTTPRequest("DELETE", "/delegation/"+id, other.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/delegation/"+id, principal.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/delegation/principals", delegate.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &principals)
+	CheckTestInt(t, 0, len(principals))
+}
+
+func TestDelegationBookOnBehalf(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	_, space := CreateTestLocationAndSpace(org)
+	principal := CreateTestUserInOrg(org)
+	delegate := CreateTestUserInOrg(org)
+	other := CreateTestUserInOrg(org)
+
+	payload := `{"spaceId": "` + space.ID + `", "enter": "2030-09-01T08:30:00Z", "leave": "2030-09-01T17:00:00Z", "userEmail": "` + principal.Email + `"}`
+
+	// Not yet delegated
+	req := NewHTTPRequest("POST", "/booking/", delegate.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/delegation/", principal.ID, bytes.NewBufferString(`{"delegateEmail": "`+delegate.Email+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	req = NewHTTPRequest("POST", "/booking/", delegate.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	id := res.Header().Get("X-Object-Id")
+
+	req = NewHTTPRequest("GET", "/booking/"+id, delegate.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *GetBookingResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestString(t, principal.ID, resBody.UserID)
+	CheckTestString(t, delegate.ID, resBody.CreatedByUserID)
+	CheckTestString(t, delegate.Email, resBody.CreatedByEmail)
+
+	// The booking belongs to the principal
+	req = NewHTTPRequest("GET", "/booking/", principal.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetBookingResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	req = NewHTTPRequest("GET", "/booking/", delegate.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 0, len(list))
+	req = NewHTTPRequest("GET", "/booking/delegated/", delegate.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, id, list[0].ID)
+
+	// Other users can neither read nor modify the booking
+	req = NewHTTPRequest("GET", "/booking/"+id, other.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	payload = `{"spaceId": "` + space.ID + `", "enter": "2030-09-02T08:30:00Z", "leave": "2030-09-02T17:00:00Z"}`
+	req = NewHTTPRequest("PUT", "/booking/"+id, other.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Delegates must not take over the booking
+	payload = `{"spaceId": "` + space.ID + `", "enter": "2030-09-02T08:30:00Z", "leave": "2030-09-02T17:00:00Z", "userEmail": "` + delegate.Email + `"}`
+	req = NewHTTPRequest("PUT", "/booking/"+id, delegate.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	payload = `{"spaceId": "` + space.ID + `", "enter": "2030-09-02T08:30:00Z", "leave": "2030-09-02T17:00:00Z", "userEmail": "` + principal.Email + `"}`
+	req = NewHTTPRequest("PUT", "/booking/"+id, delegate.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	booking, _ := GetBookingRepository().GetOne(id)
+	CheckTestString(t, principal.ID, booking.UserID)
+	CheckTestString(t, delegate.Email, booking.CreatedByEmail)
+
+	req = NewHTTPRequest("DELETE", "/booking/"+id, other.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	req = NewHTTPRequest("DELETE", "/booking/"+id, delegate.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
+
+func TestDelegationExpired(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	_, space := CreateTestLocationAndSpace(org)
+	principal := CreateTestUserInOrg(org)
+	delegate := CreateTestUserInOrg(org)
+
+	// Expiry must be in the future
+	expiry := time.Now().Add(-1 * time.Hour)
+	payload := `{"delegateEmail": "` + delegate.Email + `", "expiry": "` + expiry.Format(time.RFC3339) + `"}`
+	req := NewHTTPRequest("POST", "/delegation/", principal.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	e := &Delegation{
+		OrganizationID: org.ID,
+		UserID:         principal.ID,
+		DelegateUserID: delegate.ID,
+		Expiry:         &expiry,
+	}
+	CheckTestIsNil(t, GetDelegationRepository().Create(e))
+
+	payload = `{"spaceId": "` + space.ID + `", "enter": "2030-09-01T08:30:00Z", "leave": "2030-09-01T17:00:00Z", "userEmail": "` + principal.Email + `"}`
+	req = NewHTTPRequest("POST", "/booking/", delegate.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("GET", "/delegation/", principal.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetDelegationResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	CheckTestBool(t, false, list[0].Active)
+}
+
+func TestDelegationGroup(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	_, space := CreateTestLocationAndSpace(org)
+	principal := CreateTestUserInOrg(org)
+	delegate := CreateTestUserInOrg(org)
+
+	group := &Group{OrganizationID: org.ID, Name: "Assistants"}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{delegate.ID})
+
+	req := NewHTTPRequest("POST", "/delegation/", principal.ID, bytes.NewBufferString(`{"delegateGroupId": "`+group.ID+`"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	payload := `{"spaceId": "` + space.ID + `", "enter": "2030-09-01T08:30:00Z", "leave": "2030-09-01T17:00:00Z", "userEmail": "` + principal.Email + `"}`
+	req = NewHTTPRequest("POST", "/booking/", delegate.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	// Deleting the group revokes the delegation
+	GetGroupRepository().Delete(group)
+	ok, _ := GetDelegationRepository().IsDelegate(principal.ID, delegate.ID)
+	CheckTestBool(t, false, ok)
+}
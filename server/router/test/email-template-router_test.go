@@ -0,0 +1,143 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func TestEmailTemplateRouterCRUD(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+
+	req := NewHTTPRequest("GET", "/email-template/", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Built-in template is returned if there is no custom one
+	req = NewHTTPRequest("GET", "/email-template/booking-created/en", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var tpl *GetEmailTemplateResponse
+	json.Unmarshal(res.Body.Bytes(), &tpl)
+	CheckTestBool(t, false, tpl.Custom)
+	CheckTestString(t, "Your Seatsurfing booking", tpl.Subject)
+
+	payload := `{"subject": "Booked: {{spaceName}}", "bodyHtml": "<p>{{spaceName}} on {{date}}</p>"}`
+	req = NewHTTPRequest("PUT", "/email-template/booking-created/en", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/email-template/booking-created/en", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &tpl)
+	CheckTestBool(t, true, tpl.Custom)
+	CheckTestString(t, "Booked: {{spaceName}}", tpl.Subject)
+
+	req = NewHTTPRequest("GET", "/email-template/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetEmailTemplateDefinitionResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, len(EmailTemplateDefinitions), len(list))
+	for _, item := range list {
+		if item.Name == "booking-created" {
+			CheckTestInt(t, 1, len(item.CustomLanguages))
+			CheckTestString(t, "en", item.CustomLanguages[0])
+		} else {
+			CheckTestInt(t, 0, len(item.CustomLanguages))
+		}
+	}
+
+	req = NewHTTPRequest("DELETE", "/email-template/booking-created/en", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/email-template/booking-created/en", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestEmailTemplateRouterValidation(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	payload := `{"subject": "Welcome", "bodyHtml": "<p>Hello</p>"}`
+	req := NewHTTPRequest("PUT", "/email-template/notexisting/en", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+
+	req = NewHTTPRequest("PUT", "/email-template/invite-user/xx", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	req = NewHTTPRequest("PUT", "/email-template/invite-user/en", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, "7001", res.Header().Get("X-Error-Code"))
+
+	payload = `{"subject": "Welcome", "bodyHtml": "<p>{{orgDomain}}ui/setpw/{{confirmID}}/ {{spaceName}}</p>"}`
+	req = NewHTTPRequest("PUT", "/email-template/invite-user/en", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, "7002", res.Header().Get("X-Error-Code"))
+}
+
+func TestEmailTemplateRouterPreview(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	payload := `{"subject": "Booked: {{spaceName}}", "bodyHtml": "<p>{{recipientName}} booked {{spaceName}}</p>", "bodyText": "{{spaceName}}"}`
+	req := NewHTTPRequest("POST", "/email-template/booking-created/en/preview", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var preview *GetEmailTemplatePreviewResponse
+	json.Unmarshal(res.Body.Bytes(), &preview)
+	CheckTestString(t, "Booked: Desk 42", preview.Subject)
+	CheckTestBool(t, true, strings.Contains(preview.BodyHTML, "<p>Jane Doe booked Desk 42</p>"))
+	CheckTestBool(t, false, strings.Contains(preview.BodyHTML, "{{footer}}"))
+	CheckTestBool(t, true, strings.HasPrefix(preview.BodyText, "Desk 42\n\n-- \n"))
+}
+
+func TestEmailTemplateUsedForSending(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+
+	payload := `{"subject": "Reset", "bodyHtml": "<p>Custom reset link: {{orgDomain}}ui/resetpw/{{confirmID}}/</p>"}`
+	req := NewHTTPRequest("PUT", "/email-template/resetpw/en", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	// Custom template is used for its language only
+	SendMailMockContent = ""
+	err := SendEmailWithOrg(&MailAddress{Address: user.Email}, GetEmailTemplatePathResetpassword(), "en", map[string]string{
+		"recipientName": "Test",
+		"orgDomain":     "https://test.com/",
+		"confirmID":     "123",
+	}, org.ID)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "Custom reset link: https://test.com/ui/resetpw/123/"))
+
+	SendMailMockContent = ""
+	err = SendEmailWithOrg(&MailAddress{Address: user.Email}, GetEmailTemplatePathResetpassword(), "de", map[string]string{
+		"recipientName": "Test",
+		"orgDomain":     "https://test.com/",
+		"confirmID":     "123",
+	}, org.ID)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, strings.Contains(SendMailMockContent, "Custom reset link"))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "Hallo Test,"))
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 
+	. "github.com/seatsurfing/seatsurfing/server/api"
 	. "github.com/seatsurfing/seatsurfing/server/repository"
 	. "github.com/seatsurfing/seatsurfing/server/router"
 	. "github.com/seatsurfing/seatsurfing/server/testutil"
@@ -515,7 +516,7 @@ func TestLocationFloorPlanDesignForbidden(t *testing.T) {
 	res = ExecuteTestRequest(req)
 	CheckTestResponseCode(t, http.StatusOK, res.Code)
 
-	// Regular user cannot POST (CanSpaceAdminOrg required)
+	// Regular user cannot POST (manage_spaces permission required)
 	validPayload := `{"designData": "{\"version\":1,\"elements\":[]}"}`
 	req = NewHTTPRequest("POST", "/location/"+id+"/floorplan-design", regularUser.ID, bytes.NewBufferString(validPayload))
 	res = ExecuteTestRequest(req)
@@ -574,3 +575,149 @@ func TestLocationMapTypeDesigned(t *testing.T) {
 	CheckTestBool(t, true, len(svgData) > 0)
 	CheckTestBool(t, true, string(svgData[:4]) == "<svg")
 }
+
+func TestLocationAdminScopedToLocation(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	location1, _ := CreateTestLocationAndSpace(org)
+	location2, _ := CreateTestLocationAndSpace(org)
+
+	// Only org admins may assign location admins
+	req := NewHTTPRequest("POST", "/location/"+location1.ID+"/admin", user.ID, bytes.NewBufferString(`{"userEmail": "`+user.Email+`"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/location/"+location1.ID+"/admin", admin.ID, bytes.NewBufferString(`{"userEmail": "`+user.Email+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	adminID := res.Header().Get("X-Object-Id")
+
+	req = NewHTTPRequest("POST", "/location/"+location1.ID+"/admin", admin.ID, bytes.NewBufferString(`{"userEmail": "`+user.Email+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusConflict, res.Code)
+
+	req = NewHTTPRequest("GET", "/location/"+location1.ID+"/admin", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var admins []*GetLocationAdminResponse
+	json.Unmarshal(res.Body.Bytes(), &admins)
+	CheckTestInt(t, 1, len(admins))
+	CheckTestString(t, user.Email, admins[0].UserEmail)
+
+	payload := `{"name": "H234", "x": 50, "y": 100, "width": 200, "height": 300, "rotation": 90, "enabled": true, "shape": "rect", "fontSize": "normal"}`
+	req = NewHTTPRequest("POST", "/location/"+location1.ID+"/space/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	req = NewHTTPRequest("POST", "/location/"+location2.ID+"/space/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("GET", "/location/?permission=manage_spaces", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var locations []*GetLocationResponse
+	json.Unmarshal(res.Body.Bytes(), &locations)
+	CheckTestInt(t, 1, len(locations))
+	CheckTestString(t, location1.ID, locations[0].ID)
+
+	// Unfiltered list still contains all locations for booking
+	req = NewHTTPRequest("GET", "/location/", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &locations)
+	CheckTestInt(t, 2, len(locations))
+
+	// Reports are limited to the administered location
+	req = NewHTTPRequest("GET", "/stats/", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	req = NewHTTPRequest("GET", "/stats/?location="+location1.ID, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	req = NewHTTPRequest("GET", "/stats/weekday?location="+location2.ID, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/location/"+location1.ID+"/admin/"+adminID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("POST", "/location/"+location1.ID+"/space/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestLocationAdminRestrictsSpaceAdmin(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	admin := CreateTestUserOrgAdmin(org)
+	spaceAdmin := CreateTestUserOrgSpaceAdmin(org)
+	location1, _ := CreateTestLocationAndSpace(org)
+	location2, _ := CreateTestLocationAndSpace(org)
+
+	payload := `{"name": "Building 2a", "enabled": true}`
+	req := NewHTTPRequest("PUT", "/location/"+location2.ID, spaceAdmin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	group := &Group{OrganizationID: org.ID, Name: "Facility Building 1"}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{spaceAdmin.ID})
+	req = NewHTTPRequest("POST", "/location/"+location1.ID+"/admin", admin.ID, bytes.NewBufferString(`{"groupId": "`+group.ID+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	// Once assigned to a location, the space admin may no longer manage other locations
+	req = NewHTTPRequest("PUT", "/location/"+location2.ID, spaceAdmin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	payload = `{"name": "Building 1a", "enabled": true}`
+	req = NewHTTPRequest("PUT", "/location/"+location1.ID, spaceAdmin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/user/me", spaceAdmin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var me *GetUserSelfResponse
+	json.Unmarshal(res.Body.Bytes(), &me)
+	CheckTestInt(t, 4, len(me.LocationScopedPermissions))
+}
+
+func TestLocationAdminGroupMembersProtected(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	location, _ := CreateTestLocationAndSpace(org)
+	roleID := createRoleTestRole(t, admin.ID, "User Manager", []Permission{PermissionManageUsers})
+	req := NewHTTPRequest("POST", "/role/"+roleID+"/assignment", admin.ID, bytes.NewBufferString(`{"userEmail": "`+user.Email+`"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	group := &Group{OrganizationID: org.ID, Name: "Facility Building 1"}
+	GetGroupRepository().Create(group)
+	req = NewHTTPRequest("POST", "/location/"+location.ID+"/admin", admin.ID, bytes.NewBufferString(`{"groupId": "`+group.ID+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	// Users managing users may not make themselves location admins
+	req = NewHTTPRequest("PUT", "/group/"+group.ID+"/member", user.ID, bytes.NewBufferString(`["`+user.ID+`"]`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	req = NewHTTPRequest("DELETE", "/group/"+group.ID, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	locationIDs, _ := GetLocationRepository().GetAdminLocationIDs(user.ID)
+	CheckTestInt(t, 0, len(locationIDs))
+
+	req = NewHTTPRequest("PUT", "/group/"+group.ID+"/member", admin.ID, bytes.NewBufferString(`["`+user.ID+`"]`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
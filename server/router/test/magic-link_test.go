@@ -0,0 +1,159 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func requestTestMagicLink(t *testing.T, org *Organization, email string) string {
+	SendMailMockContent = ""
+	payload := "{ \"email\": \"" + email + "\", \"organizationId\": \"" + org.ID + "\" }"
+	req := NewHTTPRequest("POST", "/auth/magic-link", "", bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	rx := regexp.MustCompile(`/login/magic/([0-9a-fA-F]{8}\b-[0-9a-fA-F]{4}\b-[0-9a-fA-F]{4}\b-[0-9a-fA-F]{4}\b-[0-9a-fA-F]{12})?/"`)
+	confirmTokens := rx.FindStringSubmatch(SendMailMockContent)
+	if len(confirmTokens) != 2 {
+		return ""
+	}
+	return confirmTokens[1]
+}
+
+func TestMagicLinkLogin(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingAllowMagicLinkLogin.Name, "1")
+	user := CreateTestUserInOrg(org)
+
+	confirmID := requestTestMagicLink(t, org, user.Email)
+	CheckStringNotEmpty(t, confirmID)
+
+	req := NewHTTPRequest("POST", "/auth/magic-link/"+confirmID, "", bytes.NewBufferString("{}"))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *JWTResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckStringNotEmpty(t, resBody.AccessToken)
+	CheckStringNotEmpty(t, resBody.RefreshToken)
+
+	// Links can only be used once
+	req = NewHTTPRequest("POST", "/auth/magic-link/"+confirmID, "", bytes.NewBufferString("{}"))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestMagicLinkDisabled(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	confirmID := requestTestMagicLink(t, org, user.Email)
+	CheckTestString(t, "", confirmID)
+
+	req := NewHTTPRequest("GET", "/auth/org/test.com", "", nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *AuthPreflightResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestBool(t, false, resBody.MagicLinkLogin)
+}
+
+func TestMagicLinkUnknownUser(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingAllowMagicLinkLogin.Name, "1")
+
+	// Same response as for existing users
+	confirmID := requestTestMagicLink(t, org, "unknown@test.com")
+	CheckTestString(t, "", confirmID)
+}
+
+func TestMagicLinkServiceAccount(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingAllowMagicLinkLogin.Name, "1")
+	sa := CreateTestServiceAccountRW(org)
+
+	confirmID := requestTestMagicLink(t, org, sa.Email)
+	CheckTestString(t, "", confirmID)
+}
+
+func TestMagicLinkMaxActiveLinks(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingAllowMagicLinkLogin.Name, "1")
+	user := CreateTestUserInOrg(org)
+
+	for i := 0; i < 3; i++ {
+		CheckStringNotEmpty(t, requestTestMagicLink(t, org, user.Email))
+	}
+	CheckTestString(t, "", requestTestMagicLink(t, org, user.Email))
+}
+
+func TestMagicLinkExpired(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingAllowMagicLinkLogin.Name, "1")
+	user := CreateTestUserInOrg(org)
+	authState := &AuthState{
+		AuthProviderID: GetSettingsRepository().GetNullUUID(),
+		Expiry:         time.Now().Add(-1 * time.Minute),
+		AuthStateType:  AuthMagicLinkLogin,
+		Payload:        user.ID,
+	}
+	GetAuthStateRepository().Create(authState)
+
+	req := NewHTTPRequest("POST", "/auth/magic-link/"+authState.ID, "", bytes.NewBufferString("{}"))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestMagicLinkRequiresTotp(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingAllowMagicLinkLogin.Name, "1")
+	user := CreateTestUserInOrg(org)
+	secret := "JBSWY3DPEHPK3PXP"
+	encryptedSecret, _ := EncryptString(secret)
+	user.TotpSecret = NullString(encryptedSecret)
+	GetUserRepository().Update(user)
+
+	confirmID := requestTestMagicLink(t, org, user.Email)
+	req := NewHTTPRequest("POST", "/auth/magic-link/"+confirmID, "", bytes.NewBufferString("{}"))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+
+	req = NewHTTPRequest("POST", "/auth/magic-link/"+confirmID, "", bytes.NewBufferString(`{"code": "000000"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	code, _ := totp.GenerateCodeCustom(secret, time.Now(), *TotpOptions)
+	req = NewHTTPRequest("POST", "/auth/magic-link/"+confirmID, "", bytes.NewBufferString(`{"code": "`+code+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
+func TestMagicLinkEnforcedTotpNotConfigured(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingAllowMagicLinkLogin.Name, "1")
+	GetSettingsRepository().Set(org.ID, SettingEnforceTOTP.Name, "1")
+	user := CreateTestUserInOrg(org)
+
+	confirmID := requestTestMagicLink(t, org, user.Email)
+	req := NewHTTPRequest("POST", "/auth/magic-link/"+confirmID, "", bytes.NewBufferString("{}"))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func TestIsMailDigestDue(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	// 2030-09-02 is a Monday
+	monday := time.Date(2030, 9, 2, 8, 0, 0, 0, berlin)
+	early := time.Date(2030, 9, 2, 6, 59, 0, 0, berlin)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	daily, weekly := PreferenceMailNotificationsDailyDigest, PreferenceMailNotificationsWeeklyDigest
+	CheckTestBool(t, true, IsMailDigestDue(daily, nil, monday, 1))
+	CheckTestBool(t, false, IsMailDigestDue(daily, nil, early, 1))
+	CheckTestBool(t, true, IsMailDigestDue(daily, nil, tuesday, 1))
+	CheckTestBool(t, true, IsMailDigestDue(weekly, nil, monday, 1))
+	CheckTestBool(t, false, IsMailDigestDue(weekly, nil, tuesday, 1))
+	CheckTestBool(t, true, IsMailDigestDue(weekly, nil, tuesday, 2))
+
+	// Sent shortly after midnight local time, which is the day before in UTC
+	lastSent := time.Date(2030, 9, 1, 22, 30, 0, 0, time.UTC)
+	CheckTestBool(t, false, IsMailDigestDue(daily, &lastSent, monday, 1))
+	CheckTestBool(t, true, IsMailDigestDue(daily, &lastSent, tuesday, 1))
+}
+
+func TestMailDigestDaily(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingDefaultTimezone.Name, "Europe/Berlin")
+	user := CreateTestUserInOrg(org)
+	GetUserPreferencesRepository().Set(user.ID, PreferenceMailNotifications.Name, "2")
+	other := CreateTestUserInOrg(org)
+	location, space := CreateTestLocationAndSpace(org)
+	location.Name = "First floor"
+	GetLocationRepository().Update(location)
+	space.Name = "Desk 42"
+	GetSpaceRepository().Update(space)
+
+	CreateTestBooking(user, space, time.Date(2030, 9, 3, 9, 0, 0, 0, time.UTC), time.Date(2030, 9, 3, 17, 0, 0, 0, time.UTC), true)
+	CreateTestBooking(user, space, time.Date(2030, 9, 6, 9, 0, 0, 0, time.UTC), time.Date(2030, 9, 6, 17, 0, 0, 0, time.UTC), true)
+	CreateTestBooking(other, space, time.Date(2030, 9, 2, 9, 0, 0, 0, time.UTC), time.Date(2030, 9, 2, 17, 0, 0, 0, time.UTC), true)
+
+	// 05:00 at the user's location
+	SendMailMockContent = ""
+	SendMailDigestsAt(time.Date(2030, 9, 2, 3, 0, 0, 0, time.UTC))
+	CheckTestString(t, "", SendMailMockContent)
+
+	// 08:00 at the user's location
+	SendMailDigestsAt(time.Date(2030, 9, 2, 6, 0, 0, 0, time.UTC))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "2030-09-03 09:00 - 2030-09-03 17:00: Desk 42, First floor"))
+	CheckTestBool(t, false, strings.Contains(SendMailMockContent, "2030-09-06"))
+	CheckTestBool(t, false, strings.Contains(SendMailMockContent, "2030-09-02 09:00"))
+
+	// Only one digest per day
+	SendMailMockContent = ""
+	SendMailDigestsAt(time.Date(2030, 9, 2, 12, 0, 0, 0, time.UTC))
+	CheckTestString(t, "", SendMailMockContent)
+
+	// Nothing is sent if there is nothing to report
+	SendMailDigestsAt(time.Date(2030, 9, 4, 6, 0, 0, 0, time.UTC))
+	CheckTestString(t, "", SendMailMockContent)
+	SendMailDigestsAt(time.Date(2030, 9, 5, 6, 0, 0, 0, time.UTC))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "2030-09-06 09:00"))
+}
+
+func TestMailDigestWeekly(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingDefaultTimezone.Name, "Europe/Berlin")
+	user := CreateTestUserInOrg(org)
+	GetUserPreferencesRepository().Set(user.ID, PreferenceMailNotifications.Name, "3")
+	GetUserPreferencesRepository().Set(user.ID, PreferenceWeekStartDay.Name, "1")
+	_, space := CreateTestLocationAndSpace(org)
+	CreateTestBooking(user, space, time.Date(2030, 9, 6, 9, 0, 0, 0, time.UTC), time.Date(2030, 9, 6, 17, 0, 0, 0, time.UTC), true)
+	CreateTestBooking(user, space, time.Date(2030, 9, 10, 9, 0, 0, 0, time.UTC), time.Date(2030, 9, 10, 17, 0, 0, 0, time.UTC), true)
+
+	// Sunday
+	SendMailMockContent = ""
+	SendMailDigestsAt(time.Date(2030, 9, 1, 6, 0, 0, 0, time.UTC))
+	CheckTestString(t, "", SendMailMockContent)
+
+	// Monday
+	SendMailDigestsAt(time.Date(2030, 9, 2, 6, 0, 0, 0, time.UTC))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "2030-09-06 09:00"))
+	CheckTestBool(t, false, strings.Contains(SendMailMockContent, "2030-09-10 09:00"))
+}
+
+func TestMailDigestPendingApprovals(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingDefaultTimezone.Name, "Europe/Berlin")
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	approver := CreateTestUserOrgAdmin(org)
+	GetUserPreferencesRepository().Set(approver.ID, PreferenceMailNotifications.Name, "2")
+	GetUserPreferencesRepository().Set(approver.ID, PreferenceApprovalNotifications.Name, "1")
+	group := CreateTestGroup(org, approver)
+	user := CreateTestUserInOrg(org)
+	_, space := CreateTestLocationAndSpace(org)
+	GetSpaceRepository().AddApprovers(space, []string{group.ID})
+
+	// Approvers in digest mode do not get a mail per booking
+	SendMailMockContent = ""
+	payload := `{"spaceId": "` + space.ID + `", "enter": "2030-09-20T08:30:00Z", "leave": "2030-09-20T17:00:00Z"}`
+	req := NewHTTPRequest("POST", "/booking/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	CheckTestBool(t, false, strings.Contains(SendMailMockContent, user.Email))
+
+	SendMailDigestsAt(time.Date(2030, 9, 2, 6, 0, 0, 0, time.UTC))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, approver.GetSafeRecipientName()))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, user.Email+": 2030-09-20 08:30"))
+}
+
+func TestMailDigestPreferenceValidation(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	for value, code := range map[string]int{"0": 204, "1": 204, "2": 204, "3": 204, "4": 400, "-1": 400} {
+		payload := `{"value": "` + value + `"}`
+		req := NewHTTPRequest("PUT", "/preference/"+PreferenceMailNotifications.Name, user.ID, bytes.NewBufferString(payload))
+		res := ExecuteTestRequest(req)
+		CheckTestResponseCode(t, code, res.Code)
+	}
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func enqueueMailQueueTestMail(t *testing.T, orgID, recipient string) *QueuedMail {
+	err := EnqueueEmail(&OutgoingMail{
+		Recipient:      &MailAddress{Address: recipient},
+		Subject:        "Test " + recipient,
+		Body:           "<p>Hello</p>",
+		OrganizationID: orgID,
+	})
+	CheckTestIsNil(t, err)
+	ProcessMailQueue()
+	var id string
+	if err := GetDatabase().DB().QueryRow("SELECT id FROM mail_queue WHERE recipient = $1", recipient).Scan(&id); err != nil {
+		return nil
+	}
+	res, _ := GetMailQueueRepository().GetOne(id)
+	return res
+}
+
+func TestMailQueueRetryAndDeadLetter(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	defer func() { SendMailMockError = nil }()
+
+	SendMailMockError = errors.New("connection refused")
+	mail := enqueueMailQueueTestMail(t, org.ID, "user1@test.com")
+	CheckTestBool(t, true, mail != nil)
+	CheckTestBool(t, true, mail.Status == QueuedMailStatusPending)
+	CheckTestInt(t, 1, mail.Attempts)
+	CheckTestString(t, "connection refused", mail.Error)
+	CheckTestBool(t, true, mail.NextAttempt.After(time.Now().UTC()))
+
+	// Last attempt fails, too
+	now := time.Now().UTC()
+	mail.Attempts = 7
+	mail.NextAttempt = &now
+	CheckTestIsNil(t, GetMailQueueRepository().Update(mail))
+	ProcessMailQueue()
+	mail, _ = GetMailQueueRepository().GetOne(mail.ID)
+	CheckTestBool(t, true, mail.Status == QueuedMailStatusDead)
+	CheckTestInt(t, 8, mail.Attempts)
+	CheckTestBool(t, true, mail.NextAttempt == nil)
+
+	var status MailLogStatus
+	var errorMessage string
+	GetDatabase().DB().QueryRow("SELECT status, error FROM mail_logs WHERE recipient = $1", "user1@test.com").Scan(&status, &errorMessage)
+	CheckTestBool(t, true, status == MailLogStatusFailed)
+	CheckTestString(t, "connection refused", errorMessage)
+}
+
+func TestMailQueuePermanentError(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	defer func() { SendMailMockError = nil }()
+
+	SendMailMockError = &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	mail := enqueueMailQueueTestMail(t, org.ID, "user1@test.com")
+	CheckTestBool(t, true, mail.Status == QueuedMailStatusDead)
+	CheckTestInt(t, 1, mail.Attempts)
+}
+
+func TestMailQueueSent(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+
+	mail := enqueueMailQueueTestMail(t, org.ID, "user1@test.com")
+	CheckTestBool(t, true, mail == nil)
+	CheckTestString(t, "<p>Hello</p>", SendMailMockContent)
+	count, err := GetMailLogRepository().GetCountByOrganizationSince(org.ID, time.Now().Add(-time.Minute))
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 1, count)
+}
+
+func TestMailQueueOrgRateLimit(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	defer func(limit int) { GetConfig().MailQueueOrgRateLimit = limit }(GetConfig().MailQueueOrgRateLimit)
+	GetConfig().MailQueueOrgRateLimit = 1
+
+	mail := enqueueMailQueueTestMail(t, org.ID, "user1@test.com")
+	CheckTestBool(t, true, mail == nil)
+	mail = enqueueMailQueueTestMail(t, org.ID, "user2@test.com")
+	CheckTestBool(t, true, mail != nil)
+	CheckTestBool(t, true, mail.Status == QueuedMailStatusPending)
+	CheckTestInt(t, 0, mail.Attempts)
+	CheckTestBool(t, true, mail.NextAttempt.After(time.Now().UTC()))
+}
+
+func TestMailQueueRouterFailedMessages(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	org2 := CreateTestOrg("test2.com")
+	admin2 := CreateTestUserOrgAdmin(org2)
+	defer func() { SendMailMockError = nil }()
+
+	SendMailMockError = &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	mail := enqueueMailQueueTestMail(t, org.ID, "user1@test.com")
+	enqueueMailQueueTestMail(t, org.ID, "user2@test.com")
+	SendMailMockError = nil
+
+	req := NewHTTPRequest("GET", "/mail-queue/", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("GET", "/mail-queue/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list *GetFailedMailListResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 2, list.Total)
+	CheckTestInt(t, 0, list.Pending)
+	CheckTestInt(t, 2, len(list.Items))
+	CheckTestString(t, "550 mailbox unavailable", list.Items[0].Error)
+
+	// Admins of other organizations cannot access the message
+	req = NewHTTPRequest("POST", "/mail-queue/"+mail.ID+"/retry", admin2.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/mail-queue/"+mail.ID+"/retry", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	ProcessMailQueue()
+	_, err := GetMailQueueRepository().GetOne(mail.ID)
+	CheckTestBool(t, true, err != nil)
+
+	req = NewHTTPRequest("GET", "/mail-queue/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, list.Total)
+
+	req = NewHTTPRequest("DELETE", "/mail-queue/"+list.Items[0].ID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/mail-queue/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 0, list.Total)
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func TestMailSettingsRouterCRUD(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+
+	req := NewHTTPRequest("GET", "/mail-settings/", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("GET", "/mail-settings/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+
+	payload := `{
+		"smtpHost": "mail.example.com", "smtpPort": 587, "smtpTlsMode": "starttls",
+		"smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "user", "smtpAuthPass": "secret",
+		"senderAddress": "booking@example.com", "senderName": "Example Inc.", "replyTo": "facility@example.com"
+	}`
+	req = NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/mail-settings/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	CheckTestBool(t, false, strings.Contains(res.Body.String(), "secret"))
+	var settings *GetMailSettingsResponse
+	json.Unmarshal(res.Body.Bytes(), &settings)
+	CheckTestString(t, "mail.example.com", settings.SMTPHost)
+	CheckTestInt(t, 587, settings.SMTPPort)
+	CheckTestString(t, "starttls", settings.SMTPTLSMode)
+	CheckTestBool(t, true, settings.SMTPAuthPassSet)
+	CheckTestString(t, "booking@example.com", settings.SenderAddress)
+	CheckTestString(t, "Example Inc.", settings.SenderName)
+	CheckTestString(t, "facility@example.com", settings.ReplyTo)
+
+	// Password is stored encrypted and kept if not sent again
+	e, _ := GetMailSettingsRepository().Get(org.ID)
+	CheckTestBool(t, true, e.SMTPAuthPassEncrypted != "" && e.SMTPAuthPassEncrypted != "secret")
+	payload = `{
+		"smtpHost": "mail.example.com", "smtpPort": 587, "smtpTlsMode": "starttls",
+		"smtpAuth": true, "smtpAuthMethod": "LOGIN", "smtpAuthUser": "user",
+		"senderAddress": "booking@example.com"
+	}`
+	req = NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	transport, err := GetOrgMailTransport(org.ID)
+	CheckTestIsNil(t, err)
+	CheckTestString(t, "secret", transport.SMTP.AuthPass)
+	CheckTestString(t, "LOGIN", transport.SMTP.AuthMethod)
+	CheckTestString(t, "", transport.SenderName)
+
+	// ... but must be sent again if it would be sent to another server or user
+	for _, payload := range []string{
+		`{"smtpHost": "mail.attacker.com", "smtpPort": 587, "smtpTlsMode": "starttls", "smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "user", "senderAddress": "booking@example.com"}`,
+		`{"smtpHost": "mail.example.com", "smtpPort": 2525, "smtpTlsMode": "starttls", "smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "user", "senderAddress": "booking@example.com"}`,
+		`{"smtpHost": "mail.example.com", "smtpPort": 587, "smtpTlsMode": "none", "smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "user", "senderAddress": "booking@example.com"}`,
+		`{"smtpHost": "mail.example.com", "smtpPort": 587, "smtpTlsMode": "starttls", "smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "other", "senderAddress": "booking@example.com"}`,
+		`{"smtpHost": "mail.example.com", "smtpPort": 587, "smtpTlsMode": "starttls", "smtpInsecureSkipVerify": true, "smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "user", "senderAddress": "booking@example.com"}`,
+	} {
+		req = NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+		res = ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+		CheckTestString(t, strconv.Itoa(ResponseCodeMailPasswordRequired), res.Header().Get("X-Error-Code"))
+	}
+	transport, _ = GetOrgMailTransport(org.ID)
+	CheckTestString(t, "mail.example.com", transport.SMTP.Host)
+
+	payload = `{
+		"smtpHost": "mail2.example.com", "smtpPort": 465, "smtpTlsMode": "tls",
+		"smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "user", "smtpAuthPass": "secret2",
+		"senderAddress": "booking@example.com"
+	}`
+	req = NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	transport, _ = GetOrgMailTransport(org.ID)
+	CheckTestString(t, "secret2", transport.SMTP.AuthPass)
+	CheckTestInt(t, 465, transport.SMTP.Port)
+
+	req = NewHTTPRequest("DELETE", "/mail-settings/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/mail-settings/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestMailSettingsRouterSenderOnly(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	payload := `{"senderName": "Example Inc.", "replyTo": "facility@example.com"}`
+	req := NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	transport, err := GetOrgMailTransport(org.ID)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, transport.SMTP == nil)
+	CheckTestString(t, "Example Inc.", transport.SenderName)
+	CheckTestString(t, "facility@example.com", transport.ReplyTo)
+
+	// Without an own SMTP server, the global sender address is used
+	req = NewHTTPRequest("POST", "/mail-settings/test", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	CheckTestString(t, "Example Inc.", SendMailMockSender.DisplayName)
+	CheckTestBool(t, true, SendMailMockSender.Address != "booking@example.com")
+}
+
+func TestMailSettingsRouterValidation(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	payloads := []string{
+		// Sender address requires own SMTP server
+		`{"senderAddress": "booking@example.com"}`,
+		// Missing port
+		`{"smtpHost": "mail.example.com", "smtpTlsMode": "starttls", "senderAddress": "booking@example.com"}`,
+		// Invalid TLS mode
+		`{"smtpHost": "mail.example.com", "smtpPort": 25, "smtpTlsMode": "ssl", "senderAddress": "booking@example.com"}`,
+		// Missing sender address
+		`{"smtpHost": "mail.example.com", "smtpPort": 25, "smtpTlsMode": "none"}`,
+		// Invalid host
+		`{"smtpHost": "mail.example.com:25", "smtpPort": 25, "smtpTlsMode": "none", "senderAddress": "booking@example.com"}`,
+		// Invalid auth method
+		`{"smtpHost": "mail.example.com", "smtpPort": 25, "smtpTlsMode": "none", "senderAddress": "booking@example.com", "smtpAuth": true, "smtpAuthMethod": "CRAM-MD5", "smtpAuthUser": "user", "smtpAuthPass": "secret"}`,
+		// Missing password
+		`{"smtpHost": "mail.example.com", "smtpPort": 25, "smtpTlsMode": "none", "senderAddress": "booking@example.com", "smtpAuth": true, "smtpAuthMethod": "PLAIN", "smtpAuthUser": "user"}`,
+		// Invalid reply-to
+		`{"replyTo": "no-address"}`,
+	}
+	for i, payload := range payloads {
+		req := NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+		res := ExecuteTestRequest(req)
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("Expected bad request for payload %d, got %d", i, res.Code)
+		}
+	}
+
+	payload := `{"smtpHost": "127.0.0.1", "smtpPort": 25, "smtpTlsMode": "none", "senderAddress": "booking@example.com"}`
+	req := NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodeMailHostNotAllowed), res.Header().Get("X-Error-Code"))
+}
+
+func TestMailSettingsRouterSendTestMail(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	defer func() { SendMailMockError = nil }()
+
+	req := NewHTTPRequest("POST", "/mail-settings/test", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	payload := `{"smtpHost": "mail.example.com", "smtpPort": 587, "smtpTlsMode": "starttls", "senderAddress": "booking@example.com", "senderName": "Example Inc."}`
+	req = NewHTTPRequest("PUT", "/mail-settings/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("POST", "/mail-settings/test", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var result *SendTestMailResponse
+	json.Unmarshal(res.Body.Bytes(), &result)
+	CheckTestBool(t, true, result.Success)
+	CheckTestString(t, admin.Email, result.Recipient)
+	CheckTestString(t, "booking@example.com", SendMailMockSender.Address)
+	CheckTestString(t, "Example Inc.", SendMailMockSender.DisplayName)
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, org.Name))
+
+	SendMailMockError = errors.New("connection refused")
+	req = NewHTTPRequest("POST", "/mail-settings/test", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &result)
+	CheckTestBool(t, false, result.Success)
+	CheckTestString(t, "connection refused", result.Error)
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func newTestPasswordLoginRequest(org *Organization, user *User, code string, clientIP string) *http.Request {
+	payload := "{ \"email\": \"" + user.Email + "\", \"password\": \"" + TestPassword + "\", \"organizationId\": \"" + org.ID + "\", \"code\": \"" + code + "\" }"
+	req := NewHTTPRequest("POST", "/auth/login", "", bytes.NewBufferString(payload))
+	req.RemoteAddr = net.JoinHostPort(clientIP, "51234")
+	return req
+}
+
+func createTestUserWithPassword(org *Organization, role UserRole) *User {
+	user := CreateTestUserInOrg(org)
+	user.Role = role
+	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(TestPassword))
+	GetUserRepository().Update(user)
+	return user
+}
+
+func TestNetworkPolicyLoginAllowlistPerRole(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingNetworkAllowlistOrgAdmins.Name, "192.0.2.0/24")
+	admin := createTestUserWithPassword(org, UserRoleOrgAdmin)
+	user := createTestUserWithPassword(org, UserRoleUser)
+
+	res := ExecuteTestRequest(newTestPasswordLoginRequest(org, admin, "", "198.51.100.7"))
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodeNetworkNotAllowed), res.Header().Get("X-Error-Code"))
+
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, admin, "", "192.0.2.10"))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	// The allowlist for admins does not apply to regular users
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, user, "", "198.51.100.7"))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	count, err := GetAuthAttemptRepository().CountFiltered(&AuthAttemptFilter{
+		OrganizationID: org.ID,
+		Start:          time.Now().Add(-1 * time.Hour),
+		End:            time.Now().Add(1 * time.Hour),
+		ErrorCode:      AuthErrorNetworkNotAllowed,
+	})
+	CheckTestBool(t, true, err == nil)
+	CheckTestInt(t, 1, count)
+}
+
+func TestNetworkPolicyLoginAllowlistCustomAdmins(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingNetworkAllowlistOrgAdmins.Name, "192.0.2.0/24")
+	GetSettingsRepository().Set(org.ID, SettingNetworkAllowlistSpaceAdmins.Name, "203.0.113.0/24")
+	admin := createTestUserWithPassword(org, UserRoleOrgAdmin)
+	settingsManager := createTestUserWithPassword(org, UserRoleUser)
+	locationAdmin := createTestUserWithPassword(org, UserRoleUser)
+
+	roleID := createRoleTestRole(t, admin.ID, "Settings Manager", []Permission{PermissionManageSettings})
+	req := NewHTTPRequest("POST", "/role/"+roleID+"/assignment", admin.ID, bytes.NewBufferString(`{"userEmail": "`+settingsManager.Email+`"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	location, _ := CreateTestLocationAndSpace(org)
+	req = NewHTTPRequest("POST", "/location/"+location.ID+"/admin", admin.ID, bytes.NewBufferString(`{"userEmail": "`+locationAdmin.Email+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	// Users with admin permissions are restricted like the built-in admins
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, settingsManager, "", "198.51.100.7"))
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, settingsManager, "", "192.0.2.10"))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, locationAdmin, "", "198.51.100.7"))
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, locationAdmin, "", "203.0.113.10"))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
+func TestNetworkPolicyRequire2FAFromUntrustedNetworks(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingTrustedNetworks.Name, "192.0.2.0/24")
+	GetSettingsRepository().Set(org.ID, SettingRequire2FAUntrustedNetworks.Name, "1")
+	user := createTestUserWithPassword(org, UserRoleUser)
+
+	res := ExecuteTestRequest(newTestPasswordLoginRequest(org, user, "", "192.0.2.10"))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, user, "", "198.51.100.7"))
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodeSecondFactorRequired), res.Header().Get("X-Error-Code"))
+
+	// Users with TOTP configured can log in from untrusted networks
+	secret := "JBSWY3DPEHPK3PXP"
+	encryptedSecret, _ := EncryptString(secret)
+	user.TotpSecret = NullString(encryptedSecret)
+	GetUserRepository().Update(user)
+	code, _ := totp.GenerateCodeCustom(secret, time.Now(), *TotpOptions)
+	res = ExecuteTestRequest(newTestPasswordLoginRequest(org, user, code, "198.51.100.7"))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
+func TestNetworkPolicyAccessToken(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	GetSettingsRepository().Set(org.ID, SettingNetworkAllowlistUsers.Name, "192.0.2.0/24, 2001:db8::/32")
+
+	req := NewHTTPRequest("GET", "/user/me", user.ID, nil)
+	req.RemoteAddr = net.JoinHostPort("2001:db8::1", "51234")
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	req = NewHTTPRequest("GET", "/user/me", user.ID, nil)
+	req.RemoteAddr = net.JoinHostPort("198.51.100.7", "51234")
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodeNetworkNotAllowed), res.Header().Get("X-Error-Code"))
+}
+
+func TestNetworkPolicyServiceAccount(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingNetworkAllowlistServiceAccount.Name, "192.0.2.10")
+	sa := CreateTestServiceAccountRW(org)
+	sa.HashedPassword = NullString(GetUserRepository().GetHashedPassword(TestPassword))
+	GetUserRepository().Update(sa)
+
+	req, _ := http.NewRequest("GET", "/user/", nil)
+	req.SetBasicAuth(org.ID+"_"+sa.Email, TestPassword)
+	req.RemoteAddr = net.JoinHostPort("192.0.2.10", "51234")
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	req, _ = http.NewRequest("GET", "/user/", nil)
+	req.SetBasicAuth(org.ID+"_"+sa.Email, TestPassword)
+	req.RemoteAddr = net.JoinHostPort("192.0.2.11", "51234")
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestNetworkPolicySettingsValidation(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	payload := `{"value": "192.0.2.0/24, 192.0.2.300"}`
+	req := NewHTTPRequest("PUT", "/setting/"+SettingTrustedNetworks.Name, admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	// Admins must not lock themselves out
+	payload = `{"value": "192.0.2.0/24"}`
+	req = NewHTTPRequest("PUT", "/setting/"+SettingNetworkAllowlistOrgAdmins.Name, admin.ID, bytes.NewBufferString(payload))
+	req.RemoteAddr = net.JoinHostPort("198.51.100.7", "51234")
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodeNetworkPolicyLockout), res.Header().Get("X-Error-Code"))
+
+	req = NewHTTPRequest("PUT", "/setting/"+SettingNetworkAllowlistOrgAdmins.Name, admin.ID, bytes.NewBufferString(payload))
+	req.RemoteAddr = net.JoinHostPort("192.0.2.10", "51234")
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
+
+func TestNetworkPolicyForwardedForSpoofing(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingNetworkAllowlistOrgAdmins.Name, "192.0.2.0/24")
+	admin := createTestUserWithPassword(org, UserRoleOrgAdmin)
+
+	// Forwarding headers sent by clients are ignored
+	req := newTestPasswordLoginRequest(org, admin, "", "198.51.100.7")
+	req.Header.Set("X-Forwarded-For", "192.0.2.10")
+	req.Header.Set("X-Real-IP", "192.0.2.10")
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodeNetworkNotAllowed), res.Header().Get("X-Error-Code"))
+
+	// Behind a trusted proxy, the first untrusted hop from the right is the client
+	trustedProxies := GetConfig().TrustedProxies
+	defer func() { GetConfig().TrustedProxies = trustedProxies }()
+	GetConfig().TrustedProxies, _ = ParseNetworkList("10.0.0.0/8")
+
+	req = newTestPasswordLoginRequest(org, admin, "", "10.0.0.1")
+	req.Header.Set("X-Forwarded-For", "192.0.2.10, 198.51.100.7, 10.0.0.2")
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = newTestPasswordLoginRequest(org, admin, "", "10.0.0.1")
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 192.0.2.10, 10.0.0.2")
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func createTestServiceAccountWithPassword(org *Organization, role UserRole) *User {
+	user := &User{
+		Email:          uuid.New().String() + "@test.com",
+		OrganizationID: org.ID,
+		Role:           role,
+		HashedPassword: NullString(GetUserRepository().GetHashedPassword(TestPassword)),
+	}
+	if err := GetUserRepository().Create(user); err != nil {
+		panic(err)
+	}
+	return user
+}
+
+func newTestOAuthTokenRequest(form url.Values, clientID, clientSecret string) *http.Request {
+	req, _ := http.NewRequest("POST", "/auth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if clientID != "" {
+		req.SetBasicAuth(clientID, clientSecret)
+	}
+	return req
+}
+
+func checkTestOAuthError(t *testing.T, expectedCode int, expectedError string, res *httptest.ResponseRecorder) {
+	CheckTestResponseCode(t, expectedCode, res.Code)
+	var resBody *OAuthErrorResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestString(t, expectedError, resBody.Error)
+}
+
+func TestOAuthClientCredentialsBasicAuth(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	sa := createTestServiceAccountWithPassword(org, UserRoleServiceAccountRW)
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req := newTestOAuthTokenRequest(form, org.ID+"_"+sa.Email, TestPassword)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	CheckTestString(t, "no-store", res.Header().Get("Cache-Control"))
+	var resBody *OAuthTokenResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckStringNotEmpty(t, resBody.AccessToken)
+	CheckTestString(t, "Bearer", resBody.TokenType)
+	CheckTestInt(t, 900, resBody.ExpiresIn)
+
+	req = NewHTTPRequestWithAccessToken("GET", "/user/me", resBody.AccessToken, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var me *GetUserResponse
+	json.Unmarshal(res.Body.Bytes(), &me)
+	CheckTestString(t, sa.ID, me.ID)
+}
+
+func TestOAuthClientCredentialsFormParams(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	sa := createTestServiceAccountWithPassword(org, UserRoleServiceAccountRW)
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {org.ID + "_" + sa.Email},
+		"client_secret": {TestPassword},
+	}
+	req := newTestOAuthTokenRequest(form, "", "")
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
+func TestOAuthClientCredentialsInvalid(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	sa := createTestServiceAccountWithPassword(org, UserRoleServiceAccountRW)
+	user := CreateTestUserInOrg(org)
+	user.HashedPassword = NullString(GetUserRepository().GetHashedPassword(TestPassword))
+	GetUserRepository().Update(user)
+
+	form := url.Values{"grant_type": {"password"}}
+	res := ExecuteTestRequest(newTestOAuthTokenRequest(form, org.ID+"_"+sa.Email, TestPassword))
+	checkTestOAuthError(t, http.StatusBadRequest, OAuthErrorUnsupportedGrantType, res)
+
+	res = ExecuteTestRequest(newTestOAuthTokenRequest(url.Values{}, org.ID+"_"+sa.Email, TestPassword))
+	checkTestOAuthError(t, http.StatusBadRequest, OAuthErrorInvalidRequest, res)
+
+	form = url.Values{"grant_type": {"client_credentials"}}
+	res = ExecuteTestRequest(newTestOAuthTokenRequest(form, org.ID+"_"+sa.Email, "wrong"))
+	checkTestOAuthError(t, http.StatusUnauthorized, OAuthErrorInvalidClient, res)
+	CheckStringNotEmpty(t, res.Header().Get("WWW-Authenticate"))
+
+	res = ExecuteTestRequest(newTestOAuthTokenRequest(form, org.ID+"_unknown@test.com", TestPassword))
+	checkTestOAuthError(t, http.StatusUnauthorized, OAuthErrorInvalidClient, res)
+
+	// Regular users cannot use the client credentials grant
+	res = ExecuteTestRequest(newTestOAuthTokenRequest(form, org.ID+"_"+user.Email, TestPassword))
+	checkTestOAuthError(t, http.StatusUnauthorized, OAuthErrorInvalidClient, res)
+
+	sa.Disabled = true
+	GetUserRepository().Update(sa)
+	res = ExecuteTestRequest(newTestOAuthTokenRequest(form, org.ID+"_"+sa.Email, TestPassword))
+	checkTestOAuthError(t, http.StatusUnauthorized, OAuthErrorInvalidClient, res)
+}
+
+func TestOAuthClientCredentialsTokenReadOnly(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	sa := createTestServiceAccountWithPassword(org, UserRoleServiceAccountRO)
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	res := ExecuteTestRequest(newTestOAuthTokenRequest(form, org.ID+"_"+sa.Email, TestPassword))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *OAuthTokenResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+
+	req := NewHTTPRequestWithAccessToken("GET", "/booking/", resBody.AccessToken, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	req = NewHTTPRequestWithAccessToken("POST", "/booking/", resBody.AccessToken, strings.NewReader("{}"))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestOAuthClientCredentialsTokenDisabledAccount(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	sa := createTestServiceAccountWithPassword(org, UserRoleServiceAccountRW)
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	res := ExecuteTestRequest(newTestOAuthTokenRequest(form, org.ID+"_"+sa.Email, TestPassword))
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *OAuthTokenResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+
+	sa.Disabled = true
+	GetUserRepository().Update(sa)
+	req := NewHTTPRequestWithAccessToken("GET", "/user/me", resBody.AccessToken, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestSessionlessTokenRegularUser(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	router := &AuthRouter{}
+	claims := router.CreateClaims(user, &Session{})
+	accessToken := router.CreateAccessToken(claims)
+	req := NewHTTPRequestWithAccessToken("GET", "/user/me", accessToken, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+}
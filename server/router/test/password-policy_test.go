@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func setTestPassword(t *testing.T, user *User, password string, expectedCode int) {
+	payload := `{"password": "` + password + `"}`
+	req := NewHTTPRequest("PUT", "/user/"+user.ID+"/password", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, expectedCode, res.Code)
+}
+
+func TestPasswordPolicyMinLengthAndCharClasses(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	GetSettingsRepository().Set(org.ID, SettingPasswordMinLength.Name, "12")
+	payload := `{"password": "` + TestPassword + `"}`
+	req := NewHTTPRequest("PUT", "/user/"+user.ID+"/password", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodePasswordPolicyViolation), res.Header().Get("X-Error-Code"))
+
+	setTestPassword(t, user, "Sea!surf1ng!!", http.StatusNoContent)
+
+	// Fewer character classes required
+	GetSettingsRepository().Set(org.ID, SettingPasswordMinLength.Name, "8")
+	GetSettingsRepository().Set(org.ID, SettingPasswordMinCharClasses.Name, "2")
+	setTestPassword(t, user, "seasurfing1", http.StatusNoContent)
+	setTestPassword(t, user, "seasurfing", http.StatusBadRequest)
+}
+
+func TestPasswordPolicyHistory(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingPasswordHistory.Name, "2")
+	user := CreateTestUserInOrg(org)
+
+	setTestPassword(t, user, TestPassword, http.StatusNoContent)
+	setTestPassword(t, user, TestPasswordNew, http.StatusNoContent)
+
+	payload := `{"password": "` + TestPassword + `"}`
+	req := NewHTTPRequest("PUT", "/user/"+user.ID+"/password", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodePasswordReused), res.Header().Get("X-Error-Code"))
+
+	// Passwords older than the configured history can be reused
+	setTestPassword(t, user, "Third!Pass1", http.StatusNoContent)
+	setTestPassword(t, user, TestPassword, http.StatusNoContent)
+}
+
+func TestPasswordPolicyAdminCreateAndUpdate(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingPasswordMinLength.Name, "12")
+	GetSettingsRepository().Set(org.ID, SettingPasswordHistory.Name, "2")
+	admin := CreateTestUserOrgAdmin(org)
+
+	payload := `{"email": "new@test.com", "firstname": "John", "lastname": "Doe", "password": "` + TestPassword + `"}`
+	req := NewHTTPRequest("POST", "/user/", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodePasswordPolicyViolation), res.Header().Get("X-Error-Code"))
+
+	payload = `{"email": "new@test.com", "firstname": "John", "lastname": "Doe", "password": "Sea!surf1ng!!"}`
+	req = NewHTTPRequest("POST", "/user/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	id := res.Header().Get("X-Object-Id")
+
+	payload = `{"email": "new@test.com", "firstname": "John", "lastname": "Doe", "password": "` + TestPassword + `"}`
+	req = NewHTTPRequest("PUT", "/user/"+id, admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodePasswordPolicyViolation), res.Header().Get("X-Error-Code"))
+
+	// The password set on creation is part of the history
+	payload = `{"email": "new@test.com", "firstname": "John", "lastname": "Doe", "password": "Changed!Pass12"}`
+	req = NewHTTPRequest("PUT", "/user/"+id, admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	payload = `{"email": "new@test.com", "firstname": "John", "lastname": "Doe", "password": "Sea!surf1ng!!"}`
+	req = NewHTTPRequest("PUT", "/user/"+id, admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodePasswordReused), res.Header().Get("X-Error-Code"))
+}
+
+func TestPasswordPolicyBreached(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingPasswordCheckBreached.Name, "1")
+	user := CreateTestUserInOrg(org)
+
+	// SHA-1 of "Sea!surf1ng" is 06F1195A880D7A4AF605EF4456C84CB7F541A5BB
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "06F11.txt"), []byte("95A880D7A4AF605EF4456C84CB7F541A5BB:2\n"), 0o600)
+	oldPath := GetConfig().BreachedPasswordsPath
+	GetConfig().BreachedPasswordsPath = dir
+	defer func() { GetConfig().BreachedPasswordsPath = oldPath }()
+
+	payload := `{"password": "` + TestPassword + `"}`
+	req := NewHTTPRequest("PUT", "/user/"+user.ID+"/password", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodePasswordBreached), res.Header().Get("X-Error-Code"))
+
+	setTestPassword(t, user, TestPasswordNew, http.StatusNoContent)
+}
+
+func TestPasswordPolicyMaxAge(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingPasswordMaxAgeDays.Name, "90")
+	user := CreateTestUserInOrg(org)
+	setTestPassword(t, user, TestPassword, http.StatusNoContent)
+	loginTestUserWithPassword(t, org, user)
+
+	_, err := GetDatabase().DB().Exec("UPDATE password_history SET created = $1 WHERE user_id = $2",
+		time.Now().UTC().AddDate(0, 0, -91), user.ID)
+	CheckTestIsNil(t, err)
+
+	payload := "{ \"email\": \"" + user.Email + "\", \"password\": \"" + TestPassword + "\", \"organizationId\": \"" + org.ID + "\" }"
+	req := NewHTTPRequest("POST", "/auth/login", "", bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodePasswordUpdateRequired), res.Header().Get("X-Error-Code"))
+
+	payload = "{ \"email\": \"" + user.Email + "\", \"password\": \"" + TestPassword + "\", \"newPassword\": \"" + TestPasswordNew + "\", \"organizationId\": \"" + org.ID + "\" }"
+	req = NewHTTPRequest("POST", "/auth/updatepw", "", bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
@@ -0,0 +1,283 @@
+package test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+// pushTestService stands in for a browser vendor's push service and decrypts
+// received messages with the keys of a simulated browser.
+type pushTestService struct {
+	mu         sync.Mutex
+	status     int
+	key        *ecdh.PrivateKey
+	authSecret []byte
+	messages   []*WebPushMessage
+	paths      []string
+	server     *httptest.Server
+}
+
+func newPushTestService(status int) *pushTestService {
+	s := &pushTestService{status: status}
+	s.key, _ = ecdh.P256().GenerateKey(rand.Reader)
+	s.authSecret = make([]byte, 16)
+	rand.Read(s.authSecret)
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !strings.HasPrefix(req.Header.Get("Authorization"), "vapid t=") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if s.status == http.StatusCreated {
+			plaintext, err := DecryptWebPushPayload(body, s.key, s.authSecret)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			m := &WebPushMessage{}
+			json.Unmarshal(plaintext, m)
+			s.messages = append(s.messages, m)
+			s.paths = append(s.paths, req.URL.Path)
+		}
+		w.WriteHeader(s.status)
+	}))
+	return s
+}
+
+func (s *pushTestService) subscriptionPayload(endpoint string) string {
+	return `{"endpoint": "` + endpoint + `", ` +
+		`"keys": {"p256dh": "` + base64.RawURLEncoding.EncodeToString(s.key.PublicKey().Bytes()) + `", ` +
+		`"auth": "` + base64.RawURLEncoding.EncodeToString(s.authSecret) + `"}, ` +
+		`"userAgent": "Test Browser"}`
+}
+
+func (s *pushTestService) waitForMessage(t *testing.T, num int) *WebPushMessage {
+	for i := 0; i < 100; i++ {
+		s.mu.Lock()
+		if len(s.messages) >= num {
+			defer s.mu.Unlock()
+			return s.messages[num-1]
+		}
+		s.mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("expected %d push messages", num)
+	return nil
+}
+
+func subscribePushTestUser(t *testing.T, userID string, service *pushTestService) string {
+	req := NewHTTPRequest("POST", "/push-subscription/", userID, bytes.NewBufferString(service.subscriptionPayload(service.server.URL+"/push/"+userID)))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	return res.Header().Get("X-Object-Id")
+}
+
+func TestPushSubscriptionCRUD(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	user2 := CreateTestUserInOrg(org)
+	service := newPushTestService(http.StatusCreated)
+	defer service.server.Close()
+
+	req := NewHTTPRequest("GET", "/push-subscription/vapid-key", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var keyRes *GetVapidPublicKeyResponse
+	json.Unmarshal(res.Body.Bytes(), &keyRes)
+	publicKey, err := base64.RawURLEncoding.DecodeString(keyRes.PublicKey)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 65, len(publicKey))
+
+	id := subscribePushTestUser(t, user.ID, service)
+	// Subscribing again from the same browser updates the subscription
+	id2 := subscribePushTestUser(t, user.ID, service)
+	CheckTestString(t, id, id2)
+
+	req = NewHTTPRequest("GET", "/push-subscription/", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetPushSubscriptionResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, id, list[0].ID)
+	CheckTestString(t, "Test Browser", list[0].UserAgent)
+
+	req = NewHTTPRequest("GET", "/push-subscription/", user2.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 0, len(list))
+
+	req = NewHTTPRequest("DELETE", "/push-subscription/"+id, user2.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/push-subscription/"+id, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	list2, _ := GetPushSubscriptionRepository().GetAllByUserID(user.ID)
+	CheckTestInt(t, 0, len(list2))
+}
+
+func TestPushSubscriptionInvalid(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	service := newPushTestService(http.StatusCreated)
+	defer service.server.Close()
+
+	for _, payload := range []string{
+		service.subscriptionPayload("ftp://push.test.com/abc"),
+		service.subscriptionPayload("push.test.com/abc"),
+		`{"endpoint": "https://push.test.com/abc", "keys": {"p256dh": "abc", "auth": "abc"}}`,
+		`{"endpoint": "https://push.test.com/abc"}`,
+	} {
+		req := NewHTTPRequest("POST", "/push-subscription/", user.ID, bytes.NewBufferString(payload))
+		res := ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	}
+}
+
+func TestPushNotificationApproval(t *testing.T) {
+	ClearTestDB()
+	service := newPushTestService(http.StatusCreated)
+	defer service.server.Close()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	group := &Group{Name: "Approvers", OrganizationID: org.ID}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{admin.ID})
+	_, space := CreateTestLocationAndSpace(org)
+	space.Name = "Desk 42"
+	GetSpaceRepository().Update(space)
+	CheckTestIsNil(t, GetSpaceRepository().AddApprovers(space, []string{group.ID}))
+
+	subscribePushTestUser(t, admin.ID, service)
+	subscribePushTestUser(t, user.ID, service)
+	GetUserPreferencesRepository().Set(admin.ID, PreferencePushApprovalRequests.Name, "1")
+	GetUserPreferencesRepository().Set(user.ID, PreferencePushNotifications.Name, "1")
+
+	// The approver is notified about the new booking
+	payload := "{\"spaceId\": \"" + space.ID + "\", \"enter\": \"2030-09-01T08:30:00Z\", \"leave\": \"2030-09-01T17:00:00Z\"}"
+	req := NewHTTPRequest("POST", "/booking/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	id := res.Header().Get("X-Object-Id")
+
+	m := service.waitForMessage(t, 1)
+	CheckTestBool(t, true, m.Title != "")
+	CheckTestBool(t, true, strings.Contains(m.Body, user.Email))
+	CheckTestBool(t, true, strings.Contains(m.Body, "Desk 42"))
+
+	// The user is notified about the result
+	payload = `{"approved": true}`
+	req = NewHTTPRequest("POST", "/booking/"+id+"/approve", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	m = service.waitForMessage(t, 2)
+	CheckTestBool(t, true, strings.Contains(m.Body, "Desk 42"))
+}
+
+func TestPushNotificationApprovalRequestsOptOut(t *testing.T) {
+	ClearTestDB()
+	service := newPushTestService(http.StatusCreated)
+	defer service.server.Close()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	group := &Group{Name: "Approvers", OrganizationID: org.ID}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{admin.ID})
+	_, space := CreateTestLocationAndSpace(org)
+	CheckTestIsNil(t, GetSpaceRepository().AddApprovers(space, []string{group.ID}))
+
+	// Approval results don't include approval requests
+	subscribePushTestUser(t, admin.ID, service)
+	subscribePushTestUser(t, user.ID, service)
+	GetUserPreferencesRepository().Set(admin.ID, PreferencePushNotifications.Name, "1")
+	GetUserPreferencesRepository().Set(user.ID, PreferencePushNotifications.Name, "1")
+
+	payload := "{\"spaceId\": \"" + space.ID + "\", \"enter\": \"2030-09-01T08:30:00Z\", \"leave\": \"2030-09-01T17:00:00Z\"}"
+	req := NewHTTPRequest("POST", "/booking/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	id := res.Header().Get("X-Object-Id")
+
+	req = NewHTTPRequest("POST", "/booking/"+id+"/approve", admin.ID, bytes.NewBufferString(`{"approved": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	service.waitForMessage(t, 1)
+	time.Sleep(500 * time.Millisecond)
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	CheckTestInt(t, 1, len(service.paths))
+	CheckTestString(t, "/push/"+user.ID, service.paths[0])
+}
+
+func TestPushNotificationDelivery(t *testing.T) {
+	ClearTestDB()
+	service := newPushTestService(http.StatusCreated)
+	defer service.server.Close()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	subscribePushTestUser(t, user.ID, service)
+
+	CheckTestBool(t, true, SendUserPushNotification(user.ID, GetEmailTemplatePathBookingReminder(), "en", getPushTestReminderVars()))
+	service.waitForMessage(t, 1)
+
+	// Users without subscriptions don't receive anything
+	user2 := CreateTestUserInOrg(org)
+	CheckTestBool(t, false, SendUserPushNotification(user2.ID, GetEmailTemplatePathBookingReminder(), "en", getPushTestReminderVars()))
+}
+
+func TestPushNotificationPruneExpired(t *testing.T) {
+	ClearTestDB()
+	service := newPushTestService(http.StatusGone)
+	defer service.server.Close()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	subscribePushTestUser(t, user.ID, service)
+
+	CheckTestBool(t, false, SendUserPushNotification(user.ID, GetEmailTemplatePathBookingReminder(), "en", getPushTestReminderVars()))
+	list, err := GetPushSubscriptionRepository().GetAllByUserID(user.ID)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 0, len(list))
+}
+
+func getPushTestReminderVars() map[string]string {
+	return map[string]string{
+		"orgDomain":     "https://test.com/",
+		"recipientName": "Test",
+		"date":          "2030-09-01 08:30 - 2030-09-01 17:00",
+		"areaName":      "Area 1",
+		"spaceName":     "Desk 42",
+		"subject":       "—",
+	}
+}
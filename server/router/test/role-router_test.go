@@ -0,0 +1,227 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func createRoleTestRole(t *testing.T, adminID, name string, permissions []Permission) string {
+	m := &CreateRoleRequest{Name: name, Permissions: []string{}}
+	for _, p := range permissions {
+		m.Permissions = append(m.Permissions, string(p))
+	}
+	payload, _ := json.Marshal(m)
+	req := NewHTTPRequest("POST", "/role/", adminID, bytes.NewBuffer(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	return res.Header().Get("X-Object-Id")
+}
+
+func TestRoleCRUD(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+
+	// Regular users cannot manage roles
+	req := NewHTTPRequest("POST", "/role/", user.ID, bytes.NewBufferString(`{"name": "Reporting", "permissions": ["view_reports"]}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Unknown permission
+	req = NewHTTPRequest("POST", "/role/", admin.ID, bytes.NewBufferString(`{"name": "Reporting", "permissions": ["do_anything"]}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	id := createRoleTestRole(t, admin.ID, "Reporting", []Permission{PermissionViewReports})
+
+	// Duplicate name
+	req = NewHTTPRequest("POST", "/role/", admin.ID, bytes.NewBufferString(`{"name": "reporting", "permissions": []}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusConflict, res.Code)
+
+	req = NewHTTPRequest("PUT", "/role/"+id, admin.ID, bytes.NewBufferString(`{"name": "Reports", "permissions": ["view_reports", "view_audit_log"]}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/role/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetRoleResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, "Reports", list[0].Name)
+	CheckTestInt(t, 2, len(list[0].Permissions))
+
+	req = NewHTTPRequest("DELETE", "/role/"+id, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/role/"+id, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestRoleLocationScopedSpaceManagement(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	location1, _ := CreateTestLocationAndSpace(org)
+	location2, _ := CreateTestLocationAndSpace(org)
+	roleID := createRoleTestRole(t, admin.ID, "Facility Manager", []Permission{PermissionManageSpaces})
+
+	payload := `{"name": "H234", "x": 50, "y": 100, "width": 200, "height": 300, "rotation": 90, "enabled": true, "shape": "rect", "fontSize": "normal"}`
+	req := NewHTTPRequest("POST", "/location/"+location1.ID+"/space/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/role/"+roleID+"/assignment", admin.ID, bytes.NewBufferString(`{"userEmail": "`+user.Email+`", "locationId": "`+location1.ID+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	req = NewHTTPRequest("POST", "/location/"+location1.ID+"/space/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	req = NewHTTPRequest("POST", "/location/"+location2.ID+"/space/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Creating locations requires an organization-wide grant
+	req = NewHTTPRequest("POST", "/location/", user.ID, bytes.NewBufferString(`{"name": "Building 3", "enabled": true}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("GET", "/user/me", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var me *GetUserSelfResponse
+	json.Unmarshal(res.Body.Bytes(), &me)
+	CheckTestInt(t, 1, len(me.Permissions))
+	CheckTestString(t, string(PermissionManageSpaces), me.Permissions[0])
+	CheckTestBool(t, false, me.SpaceAdmin)
+}
+
+func TestRoleAssignedThroughGroup(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	roleID := createRoleTestRole(t, admin.ID, "User Manager", []Permission{PermissionManageUsers})
+
+	group := &Group{OrganizationID: org.ID, Name: "HR"}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{user.ID})
+
+	req := NewHTTPRequest("GET", "/user/count", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/role/"+roleID+"/assignment", admin.ID, bytes.NewBufferString(`{"groupId": "`+group.ID+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	req = NewHTTPRequest("GET", "/user/count", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	// Managing users does not include managing settings
+	req = NewHTTPRequest("PUT", "/setting/"+SettingAllowAnyUser.Name, user.ID, bytes.NewBufferString("1"))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Users with a custom role may not create admins
+	payload := `{"email": "newadmin@test.com", "firstname": "John", "lastname": "Doe", "password": "` + TestPassword + `", "role": ` + strconv.Itoa(int(UserRoleOrgAdmin)) + `}`
+	req = NewHTTPRequest("POST", "/user/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	created, _ := GetUserRepository().GetOne(res.Header().Get("X-Object-Id"))
+	CheckTestInt(t, int(UserRoleUser), int(created.Role))
+
+	// ... nor modify them
+	req = NewHTTPRequest("DELETE", "/user/"+admin.ID, user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// ... nor change the members of groups holding roles
+	req = NewHTTPRequest("PUT", "/group/"+group.ID+"/member", user.ID, bytes.NewBufferString(`["`+created.ID+`"]`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Deleting the group revokes the role
+	GetGroupRepository().Delete(group)
+	req = NewHTTPRequest("GET", "/user/count", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestRoleUserManagerCannotTakeOverPrivilegedUsers(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	admin := CreateTestUserOrgAdmin(org)
+	manager := CreateTestUserInOrg(org)
+	settingsManager := CreateTestUserInOrg(org)
+	groupSettingsManager := CreateTestUserInOrg(org)
+	locationAdmin := CreateTestUserInOrg(org)
+	user := CreateTestUserInOrg(org)
+
+	userManagerRoleID := createRoleTestRole(t, admin.ID, "User Manager", []Permission{PermissionManageUsers})
+	req := NewHTTPRequest("POST", "/role/"+userManagerRoleID+"/assignment", admin.ID, bytes.NewBufferString(`{"userEmail": "`+manager.Email+`"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	settingsRoleID := createRoleTestRole(t, admin.ID, "Settings Manager", []Permission{PermissionManageSettings})
+	req = NewHTTPRequest("POST", "/role/"+settingsRoleID+"/assignment", admin.ID, bytes.NewBufferString(`{"userEmail": "`+settingsManager.Email+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	group := &Group{OrganizationID: org.ID, Name: "IT"}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{groupSettingsManager.ID})
+	req = NewHTTPRequest("POST", "/role/"+settingsRoleID+"/assignment", admin.ID, bytes.NewBufferString(`{"groupId": "`+group.ID+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	location, _ := CreateTestLocationAndSpace(org)
+	req = NewHTTPRequest("POST", "/location/"+location.ID+"/admin", admin.ID, bytes.NewBufferString(`{"userEmail": "`+locationAdmin.Email+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	// Taking over users holding further permissions would grant them to the manager
+	for _, target := range []*User{settingsManager, groupSettingsManager, locationAdmin} {
+		req = NewHTTPRequest("PUT", "/user/"+target.ID+"/password", manager.ID, bytes.NewBufferString(`{"password": "`+TestPasswordNew+`"}`))
+		res = ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+		req = NewHTTPRequest("DELETE", "/user/"+target.ID+"/totp", manager.ID, nil)
+		res = ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+		req = NewHTTPRequest("DELETE", "/user/"+target.ID+"/passkeys", manager.ID, nil)
+		res = ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+		payload := `{"email": "` + target.Email + `", "firstname": "John", "lastname": "Doe", "role": ` + strconv.Itoa(int(UserRoleUser)) + `}`
+		req = NewHTTPRequest("PUT", "/user/"+target.ID, manager.ID, bytes.NewBufferString(payload))
+		res = ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+		req = NewHTTPRequest("DELETE", "/user/"+target.ID, manager.ID, nil)
+		res = ExecuteTestRequest(req)
+		CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+	}
+
+	// Regular users can still be managed
+	req = NewHTTPRequest("PUT", "/user/"+user.ID+"/password", manager.ID, bytes.NewBufferString(`{"password": "`+TestPasswordNew+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	req = NewHTTPRequest("DELETE", "/user/"+user.ID, manager.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+}
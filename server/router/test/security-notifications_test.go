@@ -0,0 +1,130 @@
+package test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const (
+	testUserAgentChromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	testUserAgentFirefoxLinux  = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+)
+
+func loginTestUserFrom(t *testing.T, org *Organization, user *User, clientIP, userAgent string) {
+	req := newTestPasswordLoginRequest(org, user, "", clientIP)
+	req.Header.Set("User-Agent", userAgent)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
+func waitForTestMail(t *testing.T, text string) {
+	for i := 0; i < 100; i++ {
+		if strings.Contains(SendMailMockContent, text) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("expected mail containing %q", text)
+}
+
+func TestDetectSuspiciousLogin(t *testing.T) {
+	now := time.Now()
+	CheckTestInt(t, 0, len(DetectSuspiciousLogin([]*LoginHistoryEntry{}, "Chrome on Windows", "192.0.2.0/24", now)))
+
+	history := []*LoginHistoryEntry{
+		{Device: "Chrome on Windows", Network: "192.0.2.0/24", Created: now.Add(-48 * time.Hour)},
+	}
+	CheckTestInt(t, 0, len(DetectSuspiciousLogin(history, "Chrome on Windows", "192.0.2.0/24", now)))
+	reasons := DetectSuspiciousLogin(history, "Firefox on Linux", "198.51.100.0/24", now)
+	CheckTestString(t, SuspiciousLoginNewDevice+","+SuspiciousLoginNewNetwork, strings.Join(reasons, ","))
+
+	history = []*LoginHistoryEntry{
+		{Device: "Chrome on Windows", Network: "198.51.100.0/24", Created: now.Add(-10 * time.Minute)},
+		{Device: "Chrome on Windows", Network: "203.0.113.0/24", Created: now.Add(-20 * time.Minute)},
+		{Device: "Chrome on Windows", Network: "192.0.2.0/24", Created: now.Add(-48 * time.Hour)},
+	}
+	reasons = DetectSuspiciousLogin(history, "Chrome on Windows", "192.0.2.0/24", now)
+	CheckTestString(t, SuspiciousLoginRapidNetworkSwitch, strings.Join(reasons, ","))
+}
+
+func TestSuspiciousLoginNotification(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := createTestUserWithPassword(org, UserRoleUser)
+
+	// The first login establishes the baseline
+	SendMailMockContent = ""
+	loginTestUserFrom(t, org, user, "192.0.2.10", testUserAgentChromeWindows)
+	CheckTestString(t, "", SendMailMockContent)
+
+	loginTestUserFrom(t, org, user, "192.0.2.11", testUserAgentChromeWindows)
+	CheckTestString(t, "", SendMailMockContent)
+
+	loginTestUserFrom(t, org, user, "198.51.100.7", testUserAgentFirefoxLinux)
+	waitForTestMail(t, "unusual sign-in")
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "198.51.100.7"))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "device or browser that has not been used"))
+	CheckTestBool(t, false, strings.Contains(SendMailMockContent, "several different networks"))
+
+	// Org admins receive a digest once
+	admin := CreateTestUserOrgAdmin(org)
+	SendMailMockContent = ""
+	SendSuspiciousLoginDigests()
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, admin.GetSafeRecipientName()))
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, user.Email))
+	SendMailMockContent = ""
+	SendSuspiciousLoginDigests()
+	CheckTestString(t, "", SendMailMockContent)
+}
+
+func TestSuspiciousLoginNotificationDisabled(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingSuspiciousLoginNotifications.Name, "0")
+	user := createTestUserWithPassword(org, UserRoleUser)
+
+	SendMailMockContent = ""
+	loginTestUserFrom(t, org, user, "192.0.2.10", testUserAgentChromeWindows)
+	loginTestUserFrom(t, org, user, "198.51.100.7", testUserAgentFirefoxLinux)
+	CheckTestString(t, "", SendMailMockContent)
+}
+
+func TestSecurityNotificationAdminResetTotp(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	encryptedSecret, _ := EncryptString("JBSWY3DPEHPK3PXP")
+	user.TotpSecret = NullString(encryptedSecret)
+	GetUserRepository().Update(user)
+
+	SendMailMockContent = ""
+	req := NewHTTPRequest("DELETE", "/user/"+user.ID+"/totp", admin.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "An administrator has disabled two-factor authentication"))
+	CheckTestBool(t, false, strings.Contains(SendMailMockContent, "passkey"))
+}
+
+func TestSecurityNotificationDisableTotp(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	encryptedSecret, _ := EncryptString("JBSWY3DPEHPK3PXP")
+	user.TotpSecret = NullString(encryptedSecret)
+	GetUserRepository().Update(user)
+
+	SendMailMockContent = ""
+	req := NewHTTPRequest("POST", "/user/totp/disable", user.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+	CheckTestBool(t, true, strings.Contains(SendMailMockContent, "authenticator app has been disabled"))
+}
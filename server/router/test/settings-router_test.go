@@ -164,6 +164,19 @@ func TestSettingsReadAdmin(t *testing.T) {
 		SettingKioskModeEnabled.Name,
 		SettingHideReports.Name,
 		SettingHideStats.Name,
+		SettingAllowMagicLinkLogin.Name,
+		SettingPasswordMinLength.Name,
+		SettingPasswordMinCharClasses.Name,
+		SettingPasswordHistory.Name,
+		SettingPasswordMaxAgeDays.Name,
+		SettingPasswordCheckBreached.Name,
+		SettingNetworkAllowlistUsers.Name,
+		SettingNetworkAllowlistSpaceAdmins.Name,
+		SettingNetworkAllowlistOrgAdmins.Name,
+		SettingNetworkAllowlistServiceAccount.Name,
+		SettingTrustedNetworks.Name,
+		SettingRequire2FAUntrustedNetworks.Name,
+		SettingSuspiciousLoginNotifications.Name,
 	}
 	forbiddenSettings := []string{
 		SettingDatabaseVersion.Name,
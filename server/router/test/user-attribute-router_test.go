@@ -0,0 +1,274 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func createUserAttributeTestAttribute(t *testing.T, adminID, payload string) string {
+	req := NewHTTPRequest("POST", "/user-attribute/", adminID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	return res.Header().Get("X-Object-Id")
+}
+
+func TestUserAttributesCRUD(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	// 1. Create
+	id := createUserAttributeTestAttribute(t, admin.ID, `{"label": "Department", "type": 3, "claimName": "department"}`)
+
+	// 2. Read
+	req := NewHTTPRequest("GET", "/user-attribute/"+id, admin.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *GetUserAttributeResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestString(t, "Department", resBody.Label)
+	CheckTestInt(t, 3, resBody.Type)
+	CheckTestString(t, "department", resBody.ClaimName)
+
+	// 3. Update
+	payload := `{"label": "Cost center", "type": 1, "claimName": ""}`
+	req = NewHTTPRequest("PUT", "/user-attribute/"+id, admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/user-attribute/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody2 []*GetUserAttributeResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody2)
+	CheckTestInt(t, 1, len(resBody2))
+	CheckTestString(t, "Cost center", resBody2[0].Label)
+	CheckTestString(t, "", resBody2[0].ClaimName)
+
+	// 4. Delete
+	req = NewHTTPRequest("DELETE", "/user-attribute/"+id, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/user-attribute/"+id, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestUserAttributesCreateForbidden(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	payload := `{"label": "Department", "type": 3}`
+	req := NewHTTPRequest("POST", "/user-attribute/", user.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestUserAttributesSingleManagerAttribute(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	createUserAttributeTestAttribute(t, admin.ID, `{"label": "Manager", "type": 10}`)
+	req := NewHTTPRequest("POST", "/user-attribute/", admin.ID, bytes.NewBufferString(`{"label": "Line manager", "type": 10}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusConflict, res.Code)
+}
+
+func TestUserAttributesValues(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	id := createUserAttributeTestAttribute(t, admin.ID, `{"label": "Department", "type": 3}`)
+
+	// Regular users cannot set values
+	req := NewHTTPRequest("POST", "/user/"+user.ID+"/attribute/"+id, user.ID, bytes.NewBufferString(`{"value": "Sales"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/user/"+user.ID+"/attribute/"+id, admin.ID, bytes.NewBufferString(`{"value": " Sales "}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	// Users can read their own values
+	req = NewHTTPRequest("GET", "/user/"+user.ID+"/attribute", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody []*GetSpaceAttributeValueResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 1, len(resBody))
+	CheckTestString(t, id, resBody[0].AttributeID)
+	CheckTestString(t, "Sales", resBody[0].Value)
+
+	// Other users cannot
+	other := CreateTestUserInOrg(org)
+	req = NewHTTPRequest("GET", "/user/"+user.ID+"/attribute", other.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Search by attribute value
+	req = NewHTTPRequest("GET", "/search/?includeUsers=1&query=&userAttributeId="+id+"&userAttributeValue=sales", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var searchRes *GetSearchResultsResponse
+	json.Unmarshal(res.Body.Bytes(), &searchRes)
+	CheckTestInt(t, 1, len(searchRes.Users))
+	CheckTestString(t, user.ID, searchRes.Users[0].ID)
+
+	req = NewHTTPRequest("DELETE", "/user/"+user.ID+"/attribute/"+id, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/user/"+user.ID+"/attribute", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody2 []*GetSpaceAttributeValueResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody2)
+	CheckTestInt(t, 0, len(resBody2))
+}
+
+func TestUserAttributesLocationEligibility(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	attributeID := createUserAttributeTestAttribute(t, admin.ID, `{"label": "Department", "type": 3}`)
+
+	payload := `{"name": "Location 1", "enabled": true, "allowedBookerAttributes": [{"attributeId": "` + attributeID + `", "value": "Sales"}]}`
+	req := NewHTTPRequest("POST", "/location/", admin.ID, bytes.NewBufferString(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	locationID := res.Header().Get("X-Object-Id")
+
+	req = NewHTTPRequest("GET", "/location/"+locationID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var location *GetLocationResponse
+	json.Unmarshal(res.Body.Bytes(), &location)
+	CheckTestInt(t, 1, len(location.AllowedBookerAttributes))
+	CheckTestString(t, "Sales", location.AllowedBookerAttributes[0].Value)
+
+	space := &Space{Name: "H234", LocationID: locationID, Enabled: true}
+	if err := GetSpaceRepository().Create(space); err != nil {
+		t.Fatalf("Expected nil error, but got %s\n%s", err, debug.Stack())
+	}
+
+	// User without matching attribute value
+	payload = `{"spaceId": "` + space.ID + `", "enter": "2030-09-01T08:30:00Z", "leave": "2030-09-01T17:00:00Z"}`
+	req = NewHTTPRequest("POST", "/booking/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+	CheckTestString(t, strconv.Itoa(ResponseCodeBookingNotAllowedBooker), res.Header().Get("X-Error-Code"))
+
+	// User with matching attribute value
+	GetUserAttributeRepository().SetValue(attributeID, user.ID, "sales")
+	req = NewHTTPRequest("POST", "/booking/", user.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+}
+
+func TestUserAttributesManagerPresenceReport(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	manager := CreateTestUserInOrg(org)
+	report := CreateTestUserInOrg(org)
+	CreateTestUserInOrg(org)
+	attributeID := createUserAttributeTestAttribute(t, admin.ID, `{"label": "Manager", "type": 10}`)
+
+	now := time.Now()
+	start := url.QueryEscape(now.Format(JsDateTimeFormatWithTimezone))
+	end := url.QueryEscape(now.Add(24 * time.Hour).Format(JsDateTimeFormatWithTimezone))
+
+	// No reports yet
+	req := NewHTTPRequest("GET", "/booking/report/presence/?start="+start+"&end="+end, manager.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("POST", "/user/"+report.ID+"/attribute/"+attributeID, admin.ID, bytes.NewBufferString(`{"value": "`+manager.Email+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/user/me", manager.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var self *GetUserSelfResponse
+	json.Unmarshal(res.Body.Bytes(), &self)
+	CheckTestBool(t, true, self.IsManager)
+
+	// Managers only see their reports
+	req = NewHTTPRequest("GET", "/booking/report/presence/?start="+start+"&end="+end+"&attributeId="+attributeID, manager.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *GetPresenceReportResult
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 1, len(resBody.Users))
+	CheckTestString(t, report.ID, resBody.Users[0].UserID)
+	CheckTestInt(t, 1, len(resBody.AttributeValues))
+	CheckTestString(t, manager.Email, resBody.AttributeValues[0])
+
+	// Admins see everyone
+	req = NewHTTPRequest("GET", "/booking/report/presence/?start="+start+"&end="+end, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody2 *GetPresenceReportResult
+	json.Unmarshal(res.Body.Bytes(), &resBody2)
+	CheckTestInt(t, 4, len(resBody2.Users))
+}
+
+func TestUserAttributesStats(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user1 := CreateTestUserInOrg(org)
+	user2 := CreateTestUserInOrg(org)
+	attributeID := createUserAttributeTestAttribute(t, admin.ID, `{"label": "Department", "type": 3}`)
+	GetUserAttributeRepository().SetValue(attributeID, user1.ID, "Sales")
+	GetUserAttributeRepository().SetValue(attributeID, user2.ID, "Sales")
+
+	location := &Location{Name: "Location 1", OrganizationID: org.ID, Enabled: true}
+	GetLocationRepository().Create(location)
+	space := &Space{Name: "H234", LocationID: location.ID, Enabled: true}
+	GetSpaceRepository().Create(space)
+	GetBookingRepository().Create(&Booking{
+		UserID:  user1.ID,
+		SpaceID: space.ID,
+		Enter:   time.Now().Add(1 * time.Hour),
+		Leave:   time.Now().Add(2 * time.Hour),
+	})
+
+	req := NewHTTPRequest("GET", "/stats/attribute/"+attributeID, admin.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody []*GetUserAttributeStatsItem
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 2, len(resBody))
+	// Users without a value
+	CheckTestString(t, "", resBody[0].Value)
+	CheckTestInt(t, 1, resBody[0].NumUsers)
+	CheckTestInt(t, 0, resBody[0].NumBookings)
+	CheckTestString(t, "Sales", resBody[1].Value)
+	CheckTestInt(t, 2, resBody[1].NumUsers)
+	CheckTestInt(t, 1, resBody[1].NumBookers)
+	CheckTestInt(t, 1, resBody[1].NumBookings)
+
+	req = NewHTTPRequest("GET", "/stats/attribute/"+attributeID, user1.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
@@ -0,0 +1,220 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func importTestUsers(t *testing.T, userID, data string, dryRun bool) *ImportUsersResponse {
+	payload, _ := json.Marshal(&ImportUsersRequest{Data: data, DryRun: dryRun})
+	req := NewHTTPRequest("POST", "/user/import", userID, bytes.NewBuffer(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *ImportUsersResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	return resBody
+}
+
+func TestUserImportDryRun(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	existing := CreateTestUserInOrg(org)
+	GetGroupRepository().Create(&Group{OrganizationID: org.ID, Name: "Sales"})
+
+	data := "email,firstname,lastname,role,groups,preference.mail_language\n" +
+		"alice@test.com,Alice,Doe,user,Sales,de\n" +
+		"not-an-email,Bob,Doe,user,,\n" +
+		existing.Email + ",Carol,Doe,user,,\n" +
+		"dave@test.com,Dave,Doe,superAdmin,,\n" +
+		"erin@test.com,Erin,Doe,user,Marketing,\n" +
+		"frank@test.com,Frank,Doe,user,,xx\n" +
+		"ALICE@test.com,Alice,Doe,user,,\n"
+	resBody := importTestUsers(t, admin.ID, data, true)
+	CheckTestBool(t, true, resBody.DryRun)
+	CheckTestInt(t, 1, resBody.NumValid)
+	CheckTestInt(t, 0, resBody.NumCreated)
+	CheckTestInt(t, 6, len(resBody.Errors))
+	expected := []struct {
+		line  int
+		error string
+	}{
+		{3, UserImportErrorInvalidEmail},
+		{4, UserImportErrorUserExists},
+		{5, UserImportErrorInvalidRole},
+		{6, UserImportErrorUnknownGroup},
+		{7, UserImportErrorInvalidPreference + ":mail_language"},
+		{8, UserImportErrorDuplicateEmail},
+	}
+	for i, e := range expected {
+		CheckTestInt(t, e.line, resBody.Errors[i].Line)
+		CheckTestString(t, e.error, resBody.Errors[i].Error)
+	}
+
+	// Invalid rows prevent the import
+	resBody = importTestUsers(t, admin.ID, data, false)
+	CheckTestInt(t, 0, resBody.NumCreated)
+	user, _ := GetUserRepository().GetByEmail(org.ID, "alice@test.com")
+	CheckTestIsNil(t, user)
+}
+
+func TestUserImportUnknownColumn(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	resBody := importTestUsers(t, admin.ID, "email,firstname,department\nalice@test.com,Alice,Sales\n", true)
+	CheckTestInt(t, 2, len(resBody.Errors))
+	CheckTestInt(t, 1, resBody.Errors[0].Line)
+	CheckTestString(t, UserImportErrorUnknownColumn+":department", resBody.Errors[0].Error)
+	CheckTestString(t, UserImportErrorMissingColumn+":lastname", resBody.Errors[1].Error)
+}
+
+func TestUserImportCreate(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	group := &Group{OrganizationID: org.ID, Name: "Sales"}
+	GetGroupRepository().Create(group)
+
+	data := "email,firstname,lastname,role,groups,preference.mail_language\n" +
+		"alice@test.com,Alice,Doe,spaceAdmin,sales,de\n" +
+		"bob@test.com,Bob,Doe,,,\n"
+	resBody := importTestUsers(t, admin.ID, data, false)
+	CheckTestInt(t, 0, len(resBody.Errors))
+	CheckTestInt(t, 2, resBody.NumCreated)
+
+	alice, err := GetUserRepository().GetByEmail(org.ID, "alice@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	CheckTestString(t, "Alice", alice.Firstname)
+	CheckTestInt(t, int(UserRoleSpaceAdmin), int(alice.Role))
+	members, _ := GetGroupRepository().GetMemberUserIDs(group)
+	CheckTestBool(t, true, Contains(members, alice.ID))
+	lang, _ := GetUserPreferencesRepository().Get(alice.ID, PreferenceMailLanguage.Name)
+	CheckTestString(t, "de", lang)
+
+	bob, err := GetUserRepository().GetByEmail(org.ID, "bob@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	CheckTestInt(t, int(UserRoleUser), int(bob.Role))
+	CheckTestBool(t, false, Contains(members, bob.ID))
+}
+
+func TestUserImportGroupNotAllowed(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	admin := CreateTestUserOrgAdmin(org)
+	userManager := CreateTestUserInOrg(org)
+	roleID := createRoleTestRole(t, admin.ID, "User Manager", []Permission{PermissionManageUsers})
+	req := NewHTTPRequest("POST", "/role/"+roleID+"/assignment", admin.ID, bytes.NewBufferString(`{"userEmail": "`+userManager.Email+`"}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	GetGroupRepository().Create(&Group{OrganizationID: org.ID, Name: "Sales"})
+	roleGroup := &Group{OrganizationID: org.ID, Name: "Reporting"}
+	GetGroupRepository().Create(roleGroup)
+	req = NewHTTPRequest("POST", "/role/"+roleID+"/assignment", admin.ID, bytes.NewBufferString(`{"groupId": "`+roleGroup.ID+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	locationAdminGroup := &Group{OrganizationID: org.ID, Name: "Facility"}
+	GetGroupRepository().Create(locationAdminGroup)
+	location, _ := CreateTestLocationAndSpace(org)
+	req = NewHTTPRequest("POST", "/location/"+location.ID+"/admin", admin.ID, bytes.NewBufferString(`{"groupId": "`+locationAdminGroup.ID+`"}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	// Users managing users may not import users into privileged groups
+	data := "email,firstname,lastname,groups\n" +
+		"alice@test.com,Alice,Doe,Sales\n" +
+		"bob@test.com,Bob,Doe,Sales;Reporting\n" +
+		"carol@test.com,Carol,Doe,Facility\n"
+	resBody := importTestUsers(t, userManager.ID, data, false)
+	CheckTestInt(t, 0, resBody.NumCreated)
+	CheckTestInt(t, 2, len(resBody.Errors))
+	CheckTestInt(t, 3, resBody.Errors[0].Line)
+	CheckTestString(t, UserImportErrorGroupNotAllowed, resBody.Errors[0].Error)
+	CheckTestInt(t, 4, resBody.Errors[1].Line)
+	CheckTestString(t, UserImportErrorGroupNotAllowed, resBody.Errors[1].Error)
+	user, _ := GetUserRepository().GetByEmail(org.ID, "bob@test.com")
+	CheckTestIsNil(t, user)
+
+	// Org admins may
+	resBody = importTestUsers(t, admin.ID, data, false)
+	CheckTestInt(t, 0, len(resBody.Errors))
+	CheckTestInt(t, 3, resBody.NumCreated)
+}
+
+func TestUserImportSubscriptionExceeded(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+
+	data := "email,firstname,lastname\n"
+	for i := 0; i < DefaultUserLimit; i++ {
+		data += "user" + string(rune('a'+i)) + "@test.com,John,Doe\n"
+	}
+	payload, _ := json.Marshal(&ImportUsersRequest{Data: data})
+	req := NewHTTPRequest("POST", "/user/import", admin.ID, bytes.NewBuffer(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusPaymentRequired, res.Code)
+	count, _ := GetUserRepository().GetCount(org.ID)
+	CheckTestInt(t, 1, count)
+}
+
+func TestUserImportForbidden(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	payload, _ := json.Marshal(&ImportUsersRequest{Data: "email,firstname,lastname\n"})
+	req := NewHTTPRequest("POST", "/user/import", user.ID, bytes.NewBuffer(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("GET", "/user/export", user.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestUserExport(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+	group := &Group{OrganizationID: org.ID, Name: "Sales"}
+	GetGroupRepository().Create(group)
+	GetGroupRepository().AddMembers(group, []string{user.ID})
+
+	req := NewHTTPRequest("GET", "/user/export", admin.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	records, err := csv.NewReader(res.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	CheckTestInt(t, 3, len(records))
+	CheckTestString(t, "email,firstname,lastname,role,groups,authProvider,lastActivity", strings.Join(records[0], ","))
+	for _, record := range records[1:] {
+		if record[0] == user.Email {
+			CheckTestString(t, "user", record[3])
+			CheckTestString(t, "Sales", record[4])
+		} else {
+			CheckTestString(t, admin.Email, record[0])
+			CheckTestString(t, "orgAdmin", record[3])
+			CheckTestString(t, "", record[4])
+		}
+	}
+}
@@ -632,7 +632,7 @@ func TestUserGetByEmailNotFound(t *testing.T) {
 	org := CreateTestOrg("test.com")
 	admin := CreateTestUserOrgAdmin(org)
 
-	// Admin can use getOneByEmail because CanSpaceAdminOrg=true; but user not found → 404
+	// Admin can use getOneByEmail because admins may see names; but user not found → 404
 	req := NewHTTPRequest("GET", "/user/byEmail/nobody@example.com", admin.ID, nil)
 	res := ExecuteTestRequest(req)
 	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
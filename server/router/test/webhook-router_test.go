@@ -0,0 +1,266 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type webhookTestReceiver struct {
+	mu       sync.Mutex
+	status   int
+	bodies   [][]byte
+	headers  []http.Header
+	receiver *httptest.Server
+}
+
+func newWebhookTestReceiver(status int) *webhookTestReceiver {
+	r := &webhookTestReceiver{status: status}
+	r.receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.bodies = append(r.bodies, body)
+		r.headers = append(r.headers, req.Header.Clone())
+		status := r.status
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	return r
+}
+
+func (r *webhookTestReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func createWebhookTestWebhook(t *testing.T, adminID, url string, events []string) *CreateWebhookResponse {
+	m := &CreateWebhookRequest{Name: "Test", URL: url, Events: events, Enabled: true}
+	payload, _ := json.Marshal(m)
+	req := NewHTTPRequest("POST", "/webhook/", adminID, bytes.NewBuffer(payload))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	var resBody *CreateWebhookResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestString(t, res.Header().Get("X-Object-Id"), resBody.ID)
+	CheckTestBool(t, true, strings.HasPrefix(resBody.Secret, "whsec_"))
+	return resBody
+}
+
+func getWebhookTestDeliveries(t *testing.T, adminID, webhookID string) []*GetWebhookDeliveryResponse {
+	req := NewHTTPRequest("GET", "/webhook/"+webhookID+"/delivery/", adminID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetWebhookDeliveryResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	return list
+}
+
+func waitForWebhookTestDelivery(t *testing.T, adminID, webhookID, status string) *GetWebhookDeliveryResponse {
+	for i := 0; i < 100; i++ {
+		list := getWebhookTestDeliveries(t, adminID, webhookID)
+		if len(list) > 0 && list[0].Status == status {
+			return list[0]
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("no delivery with status %s for webhook %s", status, webhookID)
+	return nil
+}
+
+func TestWebhookCRUD(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	user := CreateTestUserInOrg(org)
+
+	// Regular users cannot manage webhooks
+	req := NewHTTPRequest("POST", "/webhook/", user.ID, bytes.NewBufferString(`{"name": "Test", "url": "https://example.com/hook", "events": ["booking.created"]}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	// Unknown event
+	req = NewHTTPRequest("POST", "/webhook/", admin.ID, bytes.NewBufferString(`{"name": "Test", "url": "https://example.com/hook", "events": ["booking.exploded"]}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	// Invalid URL scheme
+	req = NewHTTPRequest("POST", "/webhook/", admin.ID, bytes.NewBufferString(`{"name": "Test", "url": "ftp://example.com/hook", "events": ["booking.created"]}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+
+	webhook := createWebhookTestWebhook(t, admin.ID, "https://example.com/hook", []string{"booking.created"})
+
+	req = NewHTTPRequest("PUT", "/webhook/"+webhook.ID, admin.ID, bytes.NewBufferString(`{"name": "Bookings", "url": "https://example.com/hook2", "events": ["booking.created", "booking.deleted"], "enabled": false}`))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/webhook/", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var list []*GetWebhookResponse
+	json.Unmarshal(res.Body.Bytes(), &list)
+	CheckTestInt(t, 1, len(list))
+	CheckTestString(t, "Bookings", list[0].Name)
+	CheckTestString(t, "https://example.com/hook2", list[0].URL)
+	CheckTestInt(t, 2, len(list[0].Events))
+	CheckTestBool(t, false, list[0].Enabled)
+
+	// The secret is never returned after creation
+	CheckTestBool(t, false, strings.Contains(res.Body.String(), webhook.Secret))
+
+	// Rotate secret
+	req = NewHTTPRequest("POST", "/webhook/"+webhook.ID+"/secret", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var secret *GetWebhookSecretResponse
+	json.Unmarshal(res.Body.Bytes(), &secret)
+	CheckTestBool(t, true, strings.HasPrefix(secret.Secret, "whsec_"))
+	CheckTestBool(t, true, secret.Secret != webhook.Secret)
+
+	req = NewHTTPRequest("DELETE", "/webhook/"+webhook.ID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNoContent, res.Code)
+
+	req = NewHTTPRequest("GET", "/webhook/"+webhook.ID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusNotFound, res.Code)
+}
+
+func TestWebhookForeignOrg(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	org2 := CreateTestOrg("test2.com")
+	admin2 := CreateTestUserOrgAdmin(org2)
+
+	webhook := createWebhookTestWebhook(t, admin.ID, "https://example.com/hook", []string{"booking.created"})
+
+	req := NewHTTPRequest("GET", "/webhook/"+webhook.ID, admin2.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+
+	req = NewHTTPRequest("DELETE", "/webhook/"+webhook.ID, admin2.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusForbidden, res.Code)
+}
+
+func TestWebhookTestEventSigned(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	receiver := newWebhookTestReceiver(http.StatusOK)
+	defer receiver.receiver.Close()
+
+	webhook := createWebhookTestWebhook(t, admin.ID, receiver.receiver.URL, []string{"booking.created"})
+
+	req := NewHTTPRequest("POST", "/webhook/"+webhook.ID+"/test", admin.ID, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var delivery *GetWebhookDeliveryResponse
+	json.Unmarshal(res.Body.Bytes(), &delivery)
+	CheckTestString(t, "succeeded", delivery.Status)
+	CheckTestInt(t, http.StatusOK, delivery.ResponseCode)
+
+	CheckTestInt(t, 1, receiver.count())
+	header := receiver.headers[0]
+	CheckTestString(t, "webhook.test", header.Get(WebhookHeaderEvent))
+	CheckTestString(t, delivery.ID, header.Get(WebhookHeaderDelivery))
+	CheckTestIsNil(t, VerifyWebhookSignature(webhook.Secret, header.Get(WebhookHeaderSignature), receiver.bodies[0], 5*time.Minute))
+	CheckTestBool(t, true, VerifyWebhookSignature("whsec_wrong", header.Get(WebhookHeaderSignature), receiver.bodies[0], 5*time.Minute) != nil)
+
+	var payload *WebhookPayload
+	json.Unmarshal(receiver.bodies[0], &payload)
+	CheckTestString(t, "webhook.test", payload.Event)
+	CheckTestString(t, org.ID, payload.OrganizationID)
+}
+
+func TestWebhookSpaceCreatedDelivered(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	receiver := newWebhookTestReceiver(http.StatusNoContent)
+	defer receiver.receiver.Close()
+
+	webhook := createWebhookTestWebhook(t, admin.ID, receiver.receiver.URL, []string{"space.created"})
+
+	req := NewHTTPRequest("POST", "/location/", admin.ID, bytes.NewBufferString(`{"name": "Location 1", "enabled": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	locationID := res.Header().Get("X-Object-Id")
+
+	payload := `{"name": "H234", "x": 50, "y": 100, "width": 200, "height": 300, "rotation": 90, "enabled": true}`
+	req = NewHTTPRequest("POST", "/location/"+locationID+"/space/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	spaceID := res.Header().Get("X-Object-Id")
+
+	delivery := waitForWebhookTestDelivery(t, admin.ID, webhook.ID, "succeeded")
+	CheckTestString(t, "space.created", delivery.Event)
+	CheckTestInt(t, 1, delivery.Attempts)
+	CheckTestInt(t, http.StatusNoContent, delivery.ResponseCode)
+	CheckTestBool(t, true, strings.Contains(delivery.Payload, spaceID))
+	CheckTestInt(t, 1, receiver.count())
+}
+
+func TestWebhookFailedDeliveryRetried(t *testing.T) {
+	ClearTestDB()
+	org := CreateTestOrg("test.com")
+	admin := CreateTestUserOrgAdmin(org)
+	receiver := newWebhookTestReceiver(http.StatusInternalServerError)
+	defer receiver.receiver.Close()
+
+	webhook := createWebhookTestWebhook(t, admin.ID, receiver.receiver.URL, []string{"space.created"})
+
+	req := NewHTTPRequest("POST", "/location/", admin.ID, bytes.NewBufferString(`{"name": "Location 1", "enabled": true}`))
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+	locationID := res.Header().Get("X-Object-Id")
+
+	payload := `{"name": "H234", "x": 50, "y": 100, "width": 200, "height": 300, "rotation": 90, "enabled": true}`
+	req = NewHTTPRequest("POST", "/location/"+locationID+"/space/", admin.ID, bytes.NewBufferString(payload))
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, res.Code)
+
+	// The failed attempt stays pending with a scheduled retry
+	var delivery *GetWebhookDeliveryResponse
+	for i := 0; i < 100 && (delivery == nil || delivery.Attempts == 0); i++ {
+		time.Sleep(50 * time.Millisecond)
+		if list := getWebhookTestDeliveries(t, admin.ID, webhook.ID); len(list) > 0 {
+			delivery = list[0]
+		}
+	}
+	CheckTestString(t, "pending", delivery.Status)
+	CheckTestInt(t, 1, delivery.Attempts)
+	CheckTestInt(t, http.StatusInternalServerError, delivery.ResponseCode)
+	CheckTestBool(t, true, delivery.NextAttempt != nil && delivery.NextAttempt.After(time.Now()))
+
+	req = NewHTTPRequest("GET", "/webhook/"+webhook.ID, admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *GetWebhookResponse
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	CheckTestInt(t, 1, resBody.ConsecutiveFailures)
+
+	// Pending deliveries cannot be retried manually
+	req = NewHTTPRequest("POST", "/webhook/"+webhook.ID+"/delivery/"+delivery.ID+"/retry", admin.ID, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusBadRequest, res.Code)
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	CheckTestBool(t, true, GetWebhookRetryDelay(1) == 30*time.Second)
+	CheckTestBool(t, true, GetWebhookRetryDelay(2) == 60*time.Second)
+	CheckTestBool(t, true, GetWebhookRetryDelay(4) == 4*time.Minute)
+	CheckTestBool(t, true, GetWebhookRetryDelay(20) == 6*time.Hour)
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/router"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+)
+
+func getTestTokenKeyID(t *testing.T, token string) string {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func getTestJSONWebKeySet(t *testing.T) *JSONWebKeySet {
+	req := NewHTTPRequest("GET", "/.well-known/jwks.json", "", nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	var resBody *JSONWebKeySet
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+	return resBody
+}
+
+func createTestSignedToken(t *testing.T, user *User, key *rsa.PrivateKey, kid string) string {
+	router := &AuthRouter{}
+	session := router.CreateSession(nil, user)
+	claims := router.CreateClaims(user, session)
+	installID, _ := GetSettingsRepository().GetGlobalString(SettingInstallID.Name)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Issuer:    installID,
+		Audience:  jwt.ClaimStrings{installID},
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+	}
+	accessToken := jwt.NewWithClaims(jwt.SigningMethodRS512, claims)
+	if kid != "" {
+		accessToken.Header["kid"] = kid
+	}
+	token, err := accessToken.SignedString(key)
+	CheckTestBool(t, true, err == nil)
+	return token
+}
+
+func TestJSONWebKeySet(t *testing.T) {
+	ClearTestDB()
+	GetSigningKeyRing().Reload()
+
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	jwks := getTestJSONWebKeySet(t)
+	CheckTestInt(t, 1, len(jwks.Keys))
+	CheckTestString(t, "RSA", jwks.Keys[0].KeyType)
+	CheckTestString(t, "RS512", jwks.Keys[0].Algorithm)
+	CheckTestString(t, "sig", jwks.Keys[0].Use)
+	CheckTestString(t, GetKeyID(GetConfig().JwtPublicKey), jwks.Keys[0].KeyID)
+
+	token := GetTestJWT(user.ID)
+	CheckTestString(t, jwks.Keys[0].KeyID, getTestTokenKeyID(t, token))
+}
+
+func TestTokenWithoutKeyID(t *testing.T) {
+	ClearTestDB()
+
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	token := createTestSignedToken(t, user, GetConfig().JwtPrivateKey, "")
+	req := NewHTTPRequestWithAccessToken("GET", "/user/me", token, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+}
+
+func TestTokenUnknownKeyID(t *testing.T) {
+	ClearTestDB()
+
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+
+	foreignKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token := createTestSignedToken(t, user, foreignKey, GetKeyID(&foreignKey.PublicKey))
+	req := NewHTTPRequestWithAccessToken("GET", "/user/me", token, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+
+	// Foreign key with the key ID of the configured key
+	token = createTestSignedToken(t, user, foreignKey, GetKeyID(GetConfig().JwtPublicKey))
+	req = NewHTTPRequestWithAccessToken("GET", "/user/me", token, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	ClearTestDB()
+	GetConfig().JwtKeyRotationDays = 1
+	defer func() {
+		GetConfig().JwtKeyRotationDays = 0
+		GetSigningKeyRing().Rotate()
+	}()
+
+	org := CreateTestOrg("test.com")
+	user := CreateTestUserInOrg(org)
+	configKeyID := GetKeyID(GetConfig().JwtPublicKey)
+
+	// New key is published, but not yet used for signing
+	CheckTestIsNil(t, GetSigningKeyRing().Rotate())
+	jwks := getTestJSONWebKeySet(t)
+	CheckTestInt(t, 2, len(jwks.Keys))
+	newKeyID := jwks.Keys[0].KeyID
+	CheckTestBool(t, true, newKeyID != configKeyID)
+	oldToken := GetTestJWT(user.ID)
+	CheckTestString(t, configKeyID, getTestTokenKeyID(t, oldToken))
+
+	// A second rotation within the interval does not create another key
+	CheckTestIsNil(t, GetSigningKeyRing().Rotate())
+	CheckTestInt(t, 2, len(getTestJSONWebKeySet(t).Keys))
+
+	// Activate new key
+	_, err := GetDatabase().DB().Exec("UPDATE signing_keys SET activates = $1", time.Now().UTC().Add(-1*time.Minute))
+	CheckTestIsNil(t, err)
+	CheckTestIsNil(t, GetSigningKeyRing().Rotate())
+	newToken := GetTestJWT(user.ID)
+	CheckTestString(t, newKeyID, getTestTokenKeyID(t, newToken))
+
+	// Both tokens are valid
+	req := NewHTTPRequestWithAccessToken("GET", "/user/me", oldToken, nil)
+	res := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+	req = NewHTTPRequestWithAccessToken("GET", "/user/me", newToken, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusOK, res.Code)
+
+	// Key is rotated again after the interval, the superseded key expires after the token lifetime
+	_, err = GetDatabase().DB().Exec("UPDATE signing_keys SET activates = $1, created = $1", time.Now().UTC().Add(-25*time.Hour))
+	CheckTestIsNil(t, err)
+	CheckTestIsNil(t, GetSigningKeyRing().Rotate())
+	CheckTestInt(t, 3, len(getTestJSONWebKeySet(t).Keys))
+	_, err = GetDatabase().DB().Exec("UPDATE signing_keys SET activates = $1 WHERE activates > $2", time.Now().UTC().Add(-1*time.Hour), time.Now().UTC())
+	CheckTestIsNil(t, err)
+	CheckTestIsNil(t, GetSigningKeyRing().Rotate())
+	CheckTestInt(t, 2, len(getTestJSONWebKeySet(t).Keys))
+	req = NewHTTPRequestWithAccessToken("GET", "/user/me", newToken, nil)
+	res = ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusUnauthorized, res.Code)
+}
@@ -13,6 +13,8 @@ var unauthorizedRoutes = []string{
 	"/robots.txt",
 	"/healthcheck/",
 	"/kiosk/",
+	"/.well-known/",
+	"/approval-link/",
 }
 
 var unauthorizedRoutesMu sync.RWMutex
@@ -0,0 +1,216 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+)
+
+type UserAttributeRouter struct {
+}
+
+type CreateUserAttributeRequest struct {
+	Label     string `json:"label" validate:"required,max=256"`
+	Type      int    `json:"type" validate:"oneof=1 2 3 10"`
+	ClaimName string `json:"claimName" validate:"max=256"`
+}
+
+type GetUserAttributeResponse struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organizationId"`
+	CreateUserAttributeRequest
+}
+
+type LocationAttributeRuleRequest struct {
+	AttributeID string `json:"attributeId" validate:"required,uuid"`
+	Value       string `json:"value" validate:"required,max=256"`
+}
+
+func (router *UserAttributeRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/{id}", router.getOne).Methods("GET")
+	s.HandleFunc("/{id}", router.update).Methods("PUT")
+	s.HandleFunc("/{id}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.create).Methods("POST")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+func (router *UserAttributeRouter) getOne(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	e, err := GetUserAttributeRepository().GetOne(vars["id"])
+	if err != nil {
+		log.Println(err)
+		SendNotFound(w)
+		return
+	}
+	user := GetRequestUser(r)
+	if !CanAccessOrg(user, e.OrganizationID) {
+		SendForbidden(w)
+		return
+	}
+	SendJSON(w, router.copyToRestModel(e))
+}
+
+func (router *UserAttributeRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	list, err := GetUserAttributeRepository().GetAll(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetUserAttributeResponse{}
+	for _, e := range list {
+		res = append(res, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+func (router *UserAttributeRouter) update(w http.ResponseWriter, r *http.Request) {
+	var m CreateUserAttributeRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	vars := mux.Vars(r)
+	e, err := GetUserAttributeRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	user := GetRequestUser(r)
+	if !HasPermission(user, e.OrganizationID, PermissionManageUsers) {
+		SendForbidden(w)
+		return
+	}
+	eNew := router.copyFromRestModel(&m)
+	eNew.ID = e.ID
+	eNew.OrganizationID = e.OrganizationID
+	if eNew.Type == UserAttributeTypeManager && e.Type != UserAttributeTypeManager && router.hasManagerAttribute(e.OrganizationID) {
+		SendAlreadyExists(w)
+		return
+	}
+	if err := GetUserAttributeRepository().Update(eNew); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityUserAttribute,
+		EntityID:       e.ID,
+		EntityName:     eNew.Label,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), router.copyToRestModel(eNew))
+	SendUpdated(w)
+}
+
+func (router *UserAttributeRouter) delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	e, err := GetUserAttributeRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	user := GetRequestUser(r)
+	if !HasPermission(user, e.OrganizationID, PermissionManageUsers) {
+		SendForbidden(w)
+		return
+	}
+	if err := GetUserAttributeRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntityUserAttribute,
+		EntityID:       e.ID,
+		EntityName:     e.Label,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), nil)
+	SendUpdated(w)
+}
+
+func (router *UserAttributeRouter) create(w http.ResponseWriter, r *http.Request) {
+	var m CreateUserAttributeRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
+		SendForbidden(w)
+		return
+	}
+	e := router.copyFromRestModel(&m)
+	e.OrganizationID = user.OrganizationID
+	if e.Type == UserAttributeTypeManager && router.hasManagerAttribute(e.OrganizationID) {
+		SendAlreadyExists(w)
+		return
+	}
+	if err := GetUserAttributeRepository().Create(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityUserAttribute,
+		EntityID:   e.ID,
+		EntityName: e.Label,
+	}, nil, router.copyToRestModel(e))
+	SendCreated(w, e.ID)
+}
+
+// hasManagerAttribute returns true if the organization already defines a
+// manager attribute. Only one is allowed so that reports are unambiguous.
+func (router *UserAttributeRouter) hasManagerAttribute(organizationID string) bool {
+	e, err := GetUserAttributeRepository().GetManagerAttribute(organizationID)
+	return err != nil || e != nil
+}
+
+func (router *UserAttributeRouter) copyFromRestModel(m *CreateUserAttributeRequest) *UserAttribute {
+	e := &UserAttribute{}
+	e.Label = m.Label
+	e.Type = SettingType(m.Type)
+	e.ClaimName = strings.TrimSpace(m.ClaimName)
+	return e
+}
+
+func (router *UserAttributeRouter) copyToRestModel(e *UserAttribute) *GetUserAttributeResponse {
+	m := &GetUserAttributeResponse{}
+	m.ID = e.ID
+	m.OrganizationID = e.OrganizationID
+	m.Label = e.Label
+	m.Type = int(e.Type)
+	m.ClaimName = e.ClaimName
+	return m
+}
+
+// normalizeUserAttributeValue trims the value and lowercases manager email
+// addresses so they can be compared to user emails.
+func normalizeUserAttributeValue(attribute *UserAttribute, value string) string {
+	value = strings.TrimSpace(value)
+	if attribute.Type == UserAttributeTypeManager {
+		value = strings.ToLower(value)
+	}
+	return value
+}
+
+// matchesLocationAttributeRules returns true if any of the user's attribute
+// values matches one of the location's attribute rules.
+func matchesLocationAttributeRules(rules []*LocationAttributeRule, values []*UserAttributeValue) bool {
+	for _, rule := range rules {
+		for _, value := range values {
+			if rule.AttributeID == value.AttributeID && strings.EqualFold(rule.Value, value.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,432 @@
+package router
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type ImportUsersRequest struct {
+	Data           string `json:"data" validate:"required"`
+	DryRun         bool   `json:"dryRun"`
+	SendInvitation bool   `json:"sendInvitation"`
+}
+
+type ImportUsersError struct {
+	Line  int    `json:"line"`
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+type ImportUsersResponse struct {
+	DryRun     bool                `json:"dryRun"`
+	NumValid   int                 `json:"numValid"`
+	NumCreated int                 `json:"numCreated"`
+	Errors     []*ImportUsersError `json:"errors"`
+}
+
+const (
+	userImportMaxRows          = 5000
+	userImportPreferencePrefix = "preference."
+	userImportGroupSeparator   = ";"
+)
+
+// Error codes reported for invalid rows of a user import
+const (
+	UserImportErrorInvalidCSV          = "invalid_csv"
+	UserImportErrorUnknownColumn       = "unknown_column"
+	UserImportErrorMissingColumn       = "missing_column"
+	UserImportErrorInvalidEmail        = "invalid_email"
+	UserImportErrorDuplicateEmail      = "duplicate_email"
+	UserImportErrorUserExists          = "user_exists"
+	UserImportErrorInvalidName         = "invalid_name"
+	UserImportErrorInvalidRole         = "invalid_role"
+	UserImportErrorUnknownGroup        = "unknown_group"
+	UserImportErrorGroupNotAllowed     = "group_not_allowed"
+	UserImportErrorUnknownAuthProvider = "unknown_auth_provider"
+	UserImportErrorInvalidPreference   = "invalid_preference"
+)
+
+var userCSVColumns = []string{"email", "firstname", "lastname", "role", "groups", "authProvider", "lastActivity"}
+
+var userCSVRoles = map[UserRole]string{
+	UserRoleUser:             "user",
+	UserRoleSpaceAdmin:       "spaceAdmin",
+	UserRoleOrgAdmin:         "orgAdmin",
+	UserRoleServiceAccountRO: "serviceAccountRO",
+	UserRoleServiceAccountRW: "serviceAccountRW",
+	UserRoleSuperAdmin:       "superAdmin",
+}
+
+// userImportRow is a validated row of a user import
+type userImportRow struct {
+	user        *User
+	groups      []*Group
+	preferences map[string]string
+}
+
+func (router *UserRouter) exportCSV(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
+		SendForbidden(w)
+		return
+	}
+	groupNames, err := GetGroupRepository().GetGroupNamesByUser(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	authProviders, err := GetAuthProviderRepository().GetAll(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	authProviderNames := make(map[string]string)
+	for _, e := range authProviders {
+		authProviderNames[e.ID] = e.Name
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"users.csv\"")
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	writer.Write(userCSVColumns)
+	const pageSize = 1000
+	for offset := 0; ; offset += pageSize {
+		list, err := GetUserRepository().GetAll(user.OrganizationID, pageSize, offset)
+		if err != nil {
+			log.Println(err)
+			break
+		}
+		for _, e := range list {
+			lastActivity := ""
+			if e.LastActivityAtUTC != nil {
+				lastActivity = e.LastActivityAtUTC.UTC().Format(time.RFC3339)
+			}
+			writer.Write([]string{
+				escapeCSVFormula(e.Email),
+				escapeCSVFormula(e.Firstname),
+				escapeCSVFormula(e.Lastname),
+				userCSVRoles[e.Role],
+				escapeCSVFormula(strings.Join(groupNames[e.ID], userImportGroupSeparator)),
+				escapeCSVFormula(authProviderNames[string(e.AuthProviderID)]),
+				lastActivity,
+			})
+		}
+		if len(list) < pageSize {
+			break
+		}
+	}
+	writer.Flush()
+}
+
+func (router *UserRouter) importCSV(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
+		SendForbidden(w)
+		return
+	}
+	var m ImportUsersRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	org, err := GetOrganizationRepository().GetOne(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	rows, res, err := router.parseImportCSV(user, m.Data)
+	if err != nil {
+		SendBadRequest(w)
+		return
+	}
+	res.DryRun = m.DryRun
+	if m.DryRun || len(res.Errors) > 0 {
+		SendJSON(w, res)
+		return
+	}
+	if !GetUserRepository().CanCreateUsers(org, len(rows)) {
+		SendPaymentRequired(w)
+		return
+	}
+	// Create all users before applying any side effects, so that a failure
+	// can be rolled back without leaving a partial import
+	created := []*User{}
+	for _, row := range rows {
+		e := row.user
+		// Users without identity provider set their password via the invitation
+		e.PasswordPending = m.SendInvitation && e.AuthProviderID == ""
+		if err := GetUserRepository().Create(e); err != nil {
+			log.Println(err)
+			router.rollbackImport(created)
+			SendInternalServerError(w)
+			return
+		}
+		created = append(created, e)
+	}
+	prefRouter := &UserPreferencesRouter{}
+	for _, row := range rows {
+		e := row.user
+		for _, group := range row.groups {
+			if err := GetGroupRepository().AddMembers(group, []string{e.ID}); err != nil {
+				log.Println(err)
+			}
+		}
+		for name, value := range row.preferences {
+			if err := prefRouter.doSetOne(e.ID, name, value); err != nil {
+				log.Println(err)
+			}
+		}
+		if e.PasswordPending {
+			if err := router.sendImportInvitation(e, org); err != nil {
+				log.Printf("User invitation email failed: %s\n", err)
+			}
+		}
+		recordAuditLog(r, &AuditLogEntry{Action: AuditActionCreate, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email, OrganizationID: e.OrganizationID}, nil, router.getAuditModel(e))
+		dispatchUserWebhook(WebhookEventUserCreated, e)
+		res.NumCreated++
+	}
+	SendJSON(w, res)
+}
+
+// rollbackImport deletes the users created by an import which failed.
+func (router *UserRouter) rollbackImport(users []*User) {
+	for _, e := range users {
+		if err := GetUserRepository().Delete(e); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (router *UserRouter) sendImportInvitation(e *User, org *Organization) error {
+	authState := &AuthState{
+		AuthProviderID: GetSettingsRepository().GetNullUUID(),
+		Expiry:         time.Now().Add(time.Hour * 72), // 3 days
+		AuthStateType:  AuthInviteUser,
+		Payload:        e.ID,
+	}
+	if err := GetAuthStateRepository().Create(authState); err != nil {
+		return err
+	}
+	authRouter := &AuthRouter{}
+	return authRouter.SendUserInvitationEmail(e, authState.ID, org)
+}
+
+// parseImportCSV validates the CSV data of a user import. The first line must
+// contain the column names. Rows which fail validation are reported in the
+// response together with their line number. An error is only returned if the
+// data cannot be processed at all.
+func (router *UserRouter) parseImportCSV(admin *User, data string) ([]*userImportRow, *ImportUsersResponse, error) {
+	res := &ImportUsersResponse{Errors: []*ImportUsersError{}}
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, "\ufeff")))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int)
+	prefRouter := &UserPreferencesRouter{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if prefName, ok := strings.CutPrefix(name, userImportPreferencePrefix); ok && prefRouter.isValidPreferenceName(prefName) {
+			columns[name] = i
+			continue
+		}
+		column := router.getImportColumnName(name)
+		if column == "" {
+			res.Errors = append(res.Errors, &ImportUsersError{Line: 1, Error: UserImportErrorUnknownColumn + ":" + name})
+			continue
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{"email", "firstname", "lastname"} {
+		if _, ok := columns[column]; !ok {
+			res.Errors = append(res.Errors, &ImportUsersError{Line: 1, Error: UserImportErrorMissingColumn + ":" + column})
+		}
+	}
+	if len(res.Errors) > 0 {
+		return nil, res, nil
+	}
+
+	groups, err := GetGroupRepository().GetAll(admin.OrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	authProviders, err := GetAuthProviderRepository().GetAll(admin.OrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	groupRouter := &GroupRouter{}
+	manageableGroups := make(map[string]bool)
+	rows := []*userImportRow{}
+	seenEmails := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			res.Errors = append(res.Errors, &ImportUsersError{Line: parseErr.StartLine, Error: UserImportErrorInvalidCSV})
+			if parseErr.Err == csv.ErrFieldCount {
+				continue
+			}
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows)+len(res.Errors) >= userImportMaxRows {
+			return nil, nil, errors.New("too many rows")
+		}
+		get := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		email := strings.ToLower(get("email"))
+		if email == "" && strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		addError := func(code string) {
+			res.Errors = append(res.Errors, &ImportUsersError{Line: line, Email: email, Error: code})
+		}
+		if !isValidEmail(email) || len(email) > 256 {
+			addError(UserImportErrorInvalidEmail)
+			continue
+		}
+		if seenEmails[email] {
+			addError(UserImportErrorDuplicateEmail)
+			continue
+		}
+		seenEmails[email] = true
+		if existing, err := GetUserRepository().GetByEmail(admin.OrganizationID, email); err == nil && existing != nil {
+			addError(UserImportErrorUserExists)
+			continue
+		}
+		firstname, lastname := get("firstname"), get("lastname")
+		if len(firstname) > 128 || len(lastname) > 128 || !IsValidHumanName(firstname) || !IsValidHumanName(lastname) {
+			addError(UserImportErrorInvalidName)
+			continue
+		}
+		role, ok := router.getImportRole(get("role"))
+		if !ok || !canAssignUserRole(admin, role) || isServiceAccountRole(int(role)) {
+			addError(UserImportErrorInvalidRole)
+			continue
+		}
+		row := &userImportRow{preferences: make(map[string]string)}
+		if row.groups, ok = router.getImportGroups(get("groups"), groups); !ok {
+			addError(UserImportErrorUnknownGroup)
+			continue
+		}
+		allowed := true
+		for _, group := range row.groups {
+			if _, ok := manageableGroups[group.ID]; !ok {
+				manageableGroups[group.ID] = groupRouter.canManageMembers(admin, group)
+			}
+			allowed = allowed && manageableGroups[group.ID]
+		}
+		if !allowed {
+			addError(UserImportErrorGroupNotAllowed)
+			continue
+		}
+		authProviderID := ""
+		if name := get("authProvider"); name != "" {
+			for _, e := range authProviders {
+				if strings.EqualFold(e.Name, name) || e.ID == name {
+					authProviderID = e.ID
+				}
+			}
+			if authProviderID == "" {
+				addError(UserImportErrorUnknownAuthProvider)
+				continue
+			}
+		}
+		valid := true
+		for column, i := range columns {
+			prefName, ok := strings.CutPrefix(column, userImportPreferencePrefix)
+			value := strings.TrimSpace(record[i])
+			if !ok || value == "" {
+				continue
+			}
+			if !prefRouter.isValidPreferenceType(prefName, value) || !prefRouter.isValidPreferenceValue(prefName, value, admin) {
+				addError(UserImportErrorInvalidPreference + ":" + prefName)
+				valid = false
+				break
+			}
+			row.preferences[prefName] = value
+		}
+		if !valid {
+			continue
+		}
+		row.user = &User{
+			OrganizationID: admin.OrganizationID,
+			Email:          email,
+			Firstname:      firstname,
+			Lastname:       lastname,
+			Role:           role,
+			AuthProviderID: NullUUID(authProviderID),
+			HashedPassword: NullString(""),
+		}
+		rows = append(rows, row)
+	}
+	res.NumValid = len(rows)
+	return rows, res, nil
+}
+
+func (router *UserRouter) getImportColumnName(name string) string {
+	for _, column := range userCSVColumns {
+		if strings.EqualFold(column, name) {
+			return column
+		}
+	}
+	return ""
+}
+
+func (router *UserRouter) getImportRole(name string) (UserRole, bool) {
+	if name == "" {
+		return UserRoleUser, true
+	}
+	for role, roleName := range userCSVRoles {
+		if strings.EqualFold(roleName, name) {
+			return role, true
+		}
+	}
+	return UserRoleUser, false
+}
+
+func (router *UserRouter) getImportGroups(value string, groups []*Group) ([]*Group, bool) {
+	res := []*Group{}
+	for _, name := range strings.Split(value, userImportGroupSeparator) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, group := range groups {
+			if strings.EqualFold(group.Name, name) {
+				res = append(res, group)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return res, true
+}
@@ -192,7 +192,14 @@ func (router *UserPreferencesRouter) isValidPreferenceName(name string) bool {
 		name == PreferenceApprovalNotifications.Name ||
 		name == Preference24HourTime.Name ||
 		name == PreferenceDateFormat.Name ||
-		name == PreferenceWeekStartDay.Name {
+		name == PreferenceWeekStartDay.Name ||
+		name == PreferenceChatWebhookURL.Name ||
+		name == PreferenceChatWebhookFormat.Name ||
+		name == PreferenceChatNotifications.Name ||
+		name == PreferenceChatReminder.Name ||
+		name == PreferencePushNotifications.Name ||
+		name == PreferencePushReminder.Name ||
+		name == PreferencePushApprovalRequests.Name {
 		return true
 	}
 	return false
@@ -265,6 +272,27 @@ func (router *UserPreferencesRouter) getPreferenceType(name string) SettingType
 	if name == PreferenceWeekStartDay.Name {
 		return PreferenceWeekStartDay.Type
 	}
+	if name == PreferenceChatWebhookURL.Name {
+		return PreferenceChatWebhookURL.Type
+	}
+	if name == PreferenceChatWebhookFormat.Name {
+		return PreferenceChatWebhookFormat.Type
+	}
+	if name == PreferenceChatNotifications.Name {
+		return PreferenceChatNotifications.Type
+	}
+	if name == PreferenceChatReminder.Name {
+		return PreferenceChatReminder.Type
+	}
+	if name == PreferencePushNotifications.Name {
+		return PreferencePushNotifications.Type
+	}
+	if name == PreferencePushReminder.Name {
+		return PreferencePushReminder.Type
+	}
+	if name == PreferencePushApprovalRequests.Name {
+		return PreferencePushApprovalRequests.Type
+	}
 	return 0
 }
 
@@ -322,12 +350,24 @@ func (router *UserPreferencesRouter) isValidPreferenceValue(name string, value s
 		}
 		return GetConfig().IsValidLanguageCode(value)
 	}
+	if name == PreferenceMailNotifications.Name {
+		i, _ := strconv.Atoi(value)
+		if i < PreferenceMailNotificationsOff || i > PreferenceMailNotificationsWeeklyDigest {
+			return false
+		}
+	}
 	if name == PreferenceWeekStartDay.Name {
 		i, _ := strconv.Atoi(value)
 		if i != 0 && i != 1 && i != 6 {
 			return false
 		}
 	}
+	if name == PreferenceChatWebhookURL.Name {
+		return value == "" || ValidateChatWebhookURL(value)
+	}
+	if name == PreferenceChatWebhookFormat.Name {
+		return IsValidChatFormat(value)
+	}
 	if name == PreferenceBookedColor.Name ||
 		name == PreferenceNotBookedColor.Name ||
 		name == PreferenceSelfBookedColor.Name ||
@@ -343,8 +383,8 @@ func (router *UserPreferencesRouter) isValidPreferenceValue(name string, value s
 		if !ValidateGUID(value) {
 			return false
 		}
-		location, _ := GetLocationRepository().GetOne(value)
-		return location.OrganizationID == user.OrganizationID
+		location, err := GetLocationRepository().GetOne(value)
+		return err == nil && location.OrganizationID == user.OrganizationID
 	}
 
 	return len(value) <= 512
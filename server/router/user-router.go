@@ -90,7 +90,10 @@ type GetUserResponse struct {
 }
 
 type GetUserSelfResponse struct {
-	IsPrimaryDomain bool `json:"isPrimaryDomain"`
+	IsPrimaryDomain           bool     `json:"isPrimaryDomain"`
+	Permissions               []string `json:"permissions"`
+	LocationScopedPermissions []string `json:"locationScopedPermissions"`
+	IsManager                 bool     `json:"isManager"`
 	GetUserResponse
 }
 
@@ -157,6 +160,11 @@ func (router *UserRouter) SetupRoutes(s *mux.Router) {
 	s.HandleFunc("/{id}/api-token", router.getApiToken).Methods("GET")
 	s.HandleFunc("/{id}/api-token", router.generateApiToken).Methods("POST")
 	s.HandleFunc("/{id}/api-token", router.revokeApiToken).Methods("DELETE")
+	s.HandleFunc("/{id}/attribute", router.getAttributes).Methods("GET")
+	s.HandleFunc("/{id}/attribute/{attributeId}", router.setAttribute).Methods("POST")
+	s.HandleFunc("/{id}/attribute/{attributeId}", router.deleteAttribute).Methods("DELETE")
+	s.HandleFunc("/export", router.exportCSV).Methods("GET")
+	s.HandleFunc("/import", router.importCSV).Methods("POST")
 	s.HandleFunc("/merge/init", router.mergeInit).Methods("POST")
 	s.HandleFunc("/merge/finish/{id}", router.mergeFinish).Methods("POST")
 	s.HandleFunc("/merge", router.getMergeRequests).Methods("GET")
@@ -174,7 +182,7 @@ func (router *UserRouter) SetupRoutes(s *mux.Router) {
 
 func (router *UserRouter) adminResetPasskeys(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -184,7 +192,7 @@ func (router *UserRouter) adminResetPasskeys(w http.ResponseWriter, r *http.Requ
 		SendNotFound(w)
 		return
 	}
-	if e.OrganizationID != user.OrganizationID {
+	if e.OrganizationID != user.OrganizationID || !canManageUser(user, e) {
 		SendForbidden(w)
 		return
 	}
@@ -193,12 +201,14 @@ func (router *UserRouter) adminResetPasskeys(w http.ResponseWriter, r *http.Requ
 		SendInternalServerError(w)
 		return
 	}
+	SendSecurityNotification(e, SecurityEventAdminResetPasskeys, "")
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionResetPasskeys, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email}, nil, nil)
 	SendUpdated(w)
 }
 
 func (router *UserRouter) adminResetTotp(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -208,19 +218,128 @@ func (router *UserRouter) adminResetTotp(w http.ResponseWriter, r *http.Request)
 		SendNotFound(w)
 		return
 	}
-	if e.OrganizationID != user.OrganizationID {
+	if e.OrganizationID != user.OrganizationID || !canManageUser(user, e) {
 		SendForbidden(w)
 		return
 	}
+	before := router.getAuditModel(e)
+	hadTotp := e.TotpSecret != ""
 	e.TotpSecret = NullString("")
 	if err := GetUserRepository().Update(e); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	if hadTotp {
+		SendSecurityNotification(e, SecurityEventAdminResetTotp, "")
+	}
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionResetTotp, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email}, before, router.getAuditModel(e))
+	SendUpdated(w)
+}
+
+func (router *UserRouter) getAttributes(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	vars := mux.Vars(r)
+	e, err := GetUserRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return
+	}
+	if e.ID != user.ID && !(e.OrganizationID == user.OrganizationID && HasPermission(user, user.OrganizationID, PermissionManageUsers)) {
+		SendForbidden(w)
+		return
+	}
+	list, err := GetUserAttributeRepository().GetValuesForUser(e.ID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetSpaceAttributeValueResponse{}
+	for _, val := range list {
+		res = append(res, &GetSpaceAttributeValueResponse{
+			AttributeID: val.AttributeID,
+			Value:       val.Value,
+		})
+	}
+	SendJSON(w, res)
+}
+
+func (router *UserRouter) setAttribute(w http.ResponseWriter, r *http.Request) {
+	e, attribute := router.getUserAttributeForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	var m SetSpaceAttributeValueRequest
+	if UnmarshalValidateBody(r, &m) != nil {
+		SendBadRequest(w)
+		return
+	}
+	before := router.getAttributeAuditModel(e, attribute)
+	if err := GetUserAttributeRepository().SetValue(attribute.ID, e.ID, normalizeUserAttributeValue(attribute, m.Value)); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionUpdate, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email}, before, router.getAttributeAuditModel(e, attribute))
 	SendUpdated(w)
 }
 
+func (router *UserRouter) deleteAttribute(w http.ResponseWriter, r *http.Request) {
+	e, attribute := router.getUserAttributeForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	before := router.getAttributeAuditModel(e, attribute)
+	if err := GetUserAttributeRepository().DeleteValue(attribute.ID, e.ID); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionUpdate, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email}, before, router.getAttributeAuditModel(e, attribute))
+	SendUpdated(w)
+}
+
+// getAttributeAuditModel returns the user's current value of the attribute
+// for the audit log.
+func (router *UserRouter) getAttributeAuditModel(e *User, attribute *UserAttribute) map[string]string {
+	res := map[string]string{}
+	values, _ := GetUserAttributeRepository().GetValuesForUser(e.ID)
+	for _, value := range values {
+		if value.AttributeID == attribute.ID {
+			res["attribute:"+attribute.Label] = value.Value
+		}
+	}
+	return res
+}
+
+// getUserAttributeForAdmin loads the user and the user attribute from the
+// request, sending an error response and returning nil if the request user
+// may not manage the user's attribute values.
+func (router *UserRouter) getUserAttributeForAdmin(w http.ResponseWriter, r *http.Request) (*User, *UserAttribute) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
+		SendForbidden(w)
+		return nil, nil
+	}
+	vars := mux.Vars(r)
+	e, err := GetUserRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return nil, nil
+	}
+	if e.OrganizationID != user.OrganizationID || !canManageUser(user, e) {
+		SendForbidden(w)
+		return nil, nil
+	}
+	attribute, err := GetUserAttributeRepository().GetOne(vars["attributeId"])
+	if err != nil || attribute.OrganizationID != e.OrganizationID {
+		SendNotFound(w)
+		return nil, nil
+	}
+	return e, attribute
+}
+
 func (router *UserRouter) disableTotp(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
 	if user == nil {
@@ -231,12 +350,16 @@ func (router *UserRouter) disableTotp(w http.ResponseWriter, r *http.Request) {
 		SendForbidden(w)
 		return
 	}
+	hadTotp := user.TotpSecret != ""
 	user.TotpSecret = NullString("")
 	if err := GetUserRepository().Update(user); err != nil {
 		log.Println(err)
 		SendInternalServerError(w)
 		return
 	}
+	if hadTotp {
+		SendSecurityNotification(user, SecurityEventTotpDisabled, "")
+	}
 	SendUpdated(w)
 }
 
@@ -328,6 +451,7 @@ func (router *UserRouter) validateTotp(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	SendSecurityNotification(user, SecurityEventTotpEnabled, "")
 	SendUpdated(w)
 }
 
@@ -455,7 +579,7 @@ func (router *UserRouter) mergeFinish(w http.ResponseWriter, r *http.Request) {
 
 func (router *UserRouter) getCount(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -473,11 +597,6 @@ func (router *UserRouter) setPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !ValidatePassword(m.Password) {
-		SendBadRequest(w)
-		return
-	}
-
 	vars := mux.Vars(r)
 	user := GetRequestUser(r)
 	e := user
@@ -489,11 +608,16 @@ func (router *UserRouter) setPassword(w http.ResponseWriter, r *http.Request) {
 		}
 		e = eUser
 	}
-	if !CanAdminOrg(user, e.OrganizationID) && (user.ID != e.ID) {
+	if !canManageUser(user, e) && (user.ID != e.ID) {
 		SendForbidden(w)
 		return
 	}
-	e.HashedPassword = NullString(GetUserRepository().GetHashedPassword(m.Password))
+	if code := checkPasswordPolicy(e, m.Password); code != 0 {
+		SendBadRequestCode(w, code)
+		return
+	}
+	before := router.getAuditModel(e)
+	setUserPassword(e, m.Password)
 	e.PasswordUpdateRequired = user.ID != e.ID
 	if err := GetUserRepository().Update(e); err != nil {
 		log.Println(err)
@@ -501,6 +625,9 @@ func (router *UserRouter) setPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	GetSessionRepository().DeleteOfUser(e)
+	if user.ID != e.ID {
+		recordAuditLog(r, &AuditLogEntry{Action: AuditActionUpdate, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email, OrganizationID: e.OrganizationID}, before, router.getAuditModel(e))
+	}
 	SendUpdated(w)
 }
 
@@ -549,6 +676,17 @@ func (router *UserRouter) getSelf(w http.ResponseWriter, r *http.Request) {
 	res := &GetUserSelfResponse{
 		GetUserResponse: *router.copyToRestModel(e, false, passkeyCount > 0),
 	}
+	res.Permissions = []string{}
+	res.LocationScopedPermissions = []string{}
+	for _, p := range GetUserPermissions(e) {
+		res.Permissions = append(res.Permissions, string(p))
+		if !HasPermission(e, e.OrganizationID, p) {
+			res.LocationScopedPermissions = append(res.LocationScopedPermissions, string(p))
+		}
+	}
+	if reportIDs, err := GetUserAttributeRepository().GetReportIDs(e); err == nil {
+		res.IsManager = len(reportIDs) > 0
+	}
 	res.Organization = GetOrganizationResponse{
 		ID: org.ID,
 		CreateOrganizationRequest: CreateOrganizationRequest{
@@ -565,7 +703,7 @@ func (router *UserRouter) getSelf(w http.ResponseWriter, r *http.Request) {
 func (router *UserRouter) getOneByEmail(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
 	var showNames bool = false
-	if CanSpaceAdminOrg(user, user.OrganizationID) {
+	if HasAnyPermission(user, user.OrganizationID, PermissionManageBookings, PermissionManageUsers) {
 		showNames = true
 	} else {
 		showNames, _ = GetSettingsRepository().GetBool(user.OrganizationID, SettingShowNames.Name)
@@ -600,7 +738,7 @@ func (router *UserRouter) getOneByEmail(w http.ResponseWriter, r *http.Request)
 
 func (router *UserRouter) getOne(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -628,7 +766,7 @@ func (router *UserRouter) getOne(w http.ResponseWriter, r *http.Request) {
 func (router *UserRouter) getAll(w http.ResponseWriter, r *http.Request) {
 	search := r.URL.Query().Get("q")
 	user := GetRequestUser(r)
-	if !CanSpaceAdminOrg(user, user.OrganizationID) {
+	if !HasAnyPermission(user, user.OrganizationID, PermissionManageUsers, PermissionManageBookings, PermissionManageSpaces) {
 		SendForbidden(w)
 		return
 	}
@@ -678,11 +816,6 @@ func (router *UserRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if m.Password != "" && !ValidatePassword(m.Password) {
-		SendBadRequest(w)
-		return
-	}
-
 	vars := mux.Vars(r)
 	e, err := GetUserRepository().GetOne(vars["id"])
 	if err != nil {
@@ -690,10 +823,16 @@ func (router *UserRouter) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) {
+	if !canManageUser(user, e) {
 		SendForbidden(w)
 		return
 	}
+	if m.Password != "" && !m.SendInvitation {
+		if code := checkPasswordPolicy(e, m.Password); code != 0 {
+			SendBadRequestCode(w, code)
+			return
+		}
+	}
 
 	if m.AuthProviderID != "" {
 		if !ValidateGUID(m.AuthProviderID) {
@@ -712,7 +851,7 @@ func (router *UserRouter) update(w http.ResponseWriter, r *http.Request) {
 	if user.ID == e.ID {
 		// Prevent users from changing their own role
 		eNew.Role = e.Role
-	} else if eNew.Role > user.Role && eNew.Role != UserRoleServiceAccountRO && eNew.Role != UserRoleServiceAccountRW {
+	} else if !canAssignUserRole(user, eNew.Role) {
 		eNew.Role = e.Role
 	}
 	eNew.OrganizationID = e.OrganizationID
@@ -726,7 +865,7 @@ func (router *UserRouter) update(w http.ResponseWriter, r *http.Request) {
 		GetSessionRepository().DeleteOfUser(e)
 	} else if m.Password != "" {
 		// Admin provided a new password - update it
-		eNew.HashedPassword = NullString(GetUserRepository().GetHashedPassword(m.Password))
+		setUserPassword(eNew, m.Password)
 		eNew.AuthProviderID = NullUUID("")
 		eNew.PasswordPending = false
 		GetSessionRepository().DeleteOfUser(e)
@@ -789,6 +928,8 @@ func (router *UserRouter) update(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionUpdate, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: eNew.Email, OrganizationID: e.OrganizationID}, router.getAuditModel(e), router.getAuditModel(eNew))
+	dispatchUserWebhook(WebhookEventUserUpdated, eNew)
 	SendUpdated(w)
 }
 
@@ -800,7 +941,7 @@ func (router *UserRouter) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, e.OrganizationID) || e.ID == user.ID {
+	if !canManageUser(user, e) || e.ID == user.ID {
 		SendForbidden(w)
 		return
 	}
@@ -809,12 +950,14 @@ func (router *UserRouter) delete(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionDelete, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email, OrganizationID: e.OrganizationID}, router.getAuditModel(e), nil)
+	dispatchUserWebhook(WebhookEventUserDeleted, e)
 	SendUpdated(w)
 }
 
 func (router *UserRouter) create(w http.ResponseWriter, r *http.Request) {
 	user := GetRequestUser(r)
-	if !CanAdminOrg(user, user.OrganizationID) {
+	if !HasPermission(user, user.OrganizationID, PermissionManageUsers) {
 		SendForbidden(w)
 		return
 	}
@@ -833,11 +976,6 @@ func (router *UserRouter) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if m.Password != "" && !ValidatePassword(m.Password) {
-		SendBadRequest(w)
-		return
-	}
-
 	if m.OrganizationID != "" && m.OrganizationID != user.OrganizationID && !GetUserRepository().IsSuperAdmin(user) {
 		SendForbidden(w)
 		return
@@ -859,9 +997,15 @@ func (router *UserRouter) create(w http.ResponseWriter, r *http.Request) {
 	if e.OrganizationID == "" || !GetUserRepository().IsSuperAdmin(user) {
 		e.OrganizationID = user.OrganizationID
 	}
-	if e.Role > user.Role && e.Role != UserRoleServiceAccountRO && e.Role != UserRoleServiceAccountRW {
+	if !canAssignUserRole(user, e.Role) {
 		e.Role = UserRoleUser
 	}
+	if m.Password != "" && !m.SendInvitation {
+		if code := checkPasswordPolicy(&User{OrganizationID: e.OrganizationID}, m.Password); code != 0 {
+			SendBadRequestCode(w, code)
+			return
+		}
+	}
 	org, err := GetOrganizationRepository().GetOne(e.OrganizationID)
 	if err != nil {
 		log.Println(err)
@@ -882,6 +1026,9 @@ func (router *UserRouter) create(w http.ResponseWriter, r *http.Request) {
 		SendInternalServerError(w)
 		return
 	}
+	if e.HashedPassword != "" {
+		recordPasswordHistory(e)
+	}
 
 	// Send invitation email if requested
 	if m.SendInvitation {
@@ -904,9 +1051,39 @@ func (router *UserRouter) create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionCreate, EntityType: AuditEntityUser, EntityID: e.ID, EntityName: e.Email, OrganizationID: e.OrganizationID}, nil, router.getAuditModel(e))
+	dispatchUserWebhook(WebhookEventUserCreated, e)
 	SendCreated(w, e.ID)
 }
 
+type userAuditModel struct {
+	Email           string `json:"email"`
+	Firstname       string `json:"firstname"`
+	Lastname        string `json:"lastname"`
+	AtlassianID     string `json:"atlassianId"`
+	Role            int    `json:"role"`
+	AuthProviderID  string `json:"authProviderId"`
+	Password        string `json:"password"`
+	PasswordPending bool   `json:"passwordPending"`
+	Disabled        bool   `json:"disabled"`
+	TotpSecret      string `json:"totpSecret"`
+}
+
+func (router *UserRouter) getAuditModel(e *User) *userAuditModel {
+	return &userAuditModel{
+		Email:           e.Email,
+		Firstname:       e.Firstname,
+		Lastname:        e.Lastname,
+		AtlassianID:     string(e.AtlassianID),
+		Role:            int(e.Role),
+		AuthProviderID:  string(e.AuthProviderID),
+		Password:        string(e.HashedPassword),
+		PasswordPending: e.PasswordPending,
+		Disabled:        e.Disabled,
+		TotpSecret:      string(e.TotpSecret),
+	}
+}
+
 func (router *UserRouter) copyFromRestModel(m *CreateUserRequest) *User {
 	e := &User{}
 	e.Email = m.Email
@@ -1029,6 +1206,7 @@ func (router *UserRouter) generateApiToken(w http.ResponseWriter, r *http.Reques
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionCreate, EntityType: AuditEntityApiToken, EntityID: e.ID, EntityName: e.Email, OrganizationID: e.OrganizationID}, nil, nil)
 	res := &GenerateApiTokenResponse{
 		Token: rawToken,
 	}
@@ -1060,5 +1238,6 @@ func (router *UserRouter) revokeApiToken(w http.ResponseWriter, r *http.Request)
 		SendInternalServerError(w)
 		return
 	}
+	recordAuditLog(r, &AuditLogEntry{Action: AuditActionDelete, EntityType: AuditEntityApiToken, EntityID: e.ID, EntityName: e.Email, OrganizationID: e.OrganizationID}, nil, nil)
 	w.WriteHeader(http.StatusNoContent)
 }
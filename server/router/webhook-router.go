@@ -0,0 +1,406 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type WebhookRouter struct {
+}
+
+type CreateWebhookRequest struct {
+	Name    string   `json:"name" validate:"required,min=1,max=256"`
+	URL     string   `json:"url" validate:"required,max=1024"`
+	Events  []string `json:"events" validate:"required,min=1"`
+	Enabled bool     `json:"enabled"`
+}
+
+type GetWebhookResponse struct {
+	ID                  string     `json:"id"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	DisabledAt          *time.Time `json:"disabledAt"`
+	Created             time.Time  `json:"created"`
+	CreateWebhookRequest
+}
+
+type CreateWebhookResponse struct {
+	GetWebhookResponse
+	// Signing secret, only returned on creation and rotation
+	Secret string `json:"secret"`
+}
+
+type GetWebhookSecretResponse struct {
+	Secret string `json:"secret"`
+}
+
+type GetWebhookDeliveryResponse struct {
+	ID           string     `json:"id"`
+	WebhookID    string     `json:"webhookId"`
+	Event        string     `json:"event"`
+	Status       string     `json:"status"`
+	Attempts     int        `json:"attempts"`
+	NextAttempt  *time.Time `json:"nextAttempt"`
+	LastAttempt  *time.Time `json:"lastAttempt"`
+	ResponseCode int        `json:"responseCode"`
+	Error        string     `json:"error"`
+	Created      time.Time  `json:"created"`
+	Payload      string     `json:"payload"`
+}
+
+const (
+	// Prefix of webhook signing secrets
+	webhookSecretPrefix = "whsec_"
+	// Number of deliveries returned in the delivery log
+	webhookDeliveryLogSize = 100
+)
+
+var webhookDeliveryStatusNames = map[WebhookDeliveryStatus]string{
+	WebhookDeliveryStatusPending:   "pending",
+	WebhookDeliveryStatusSucceeded: "succeeded",
+	WebhookDeliveryStatusFailed:    "failed",
+}
+
+func (router *WebhookRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/events", router.getEvents).Methods("GET")
+	s.HandleFunc("/{id}/delivery/{deliveryId}/retry", router.retryDelivery).Methods("POST")
+	s.HandleFunc("/{id}/delivery/", router.getDeliveries).Methods("GET")
+	s.HandleFunc("/{id}/secret", router.rotateSecret).Methods("POST")
+	s.HandleFunc("/{id}/test", router.sendTestEvent).Methods("POST")
+	s.HandleFunc("/{id}", router.getOne).Methods("GET")
+	s.HandleFunc("/{id}", router.update).Methods("PUT")
+	s.HandleFunc("/{id}", router.delete).Methods("DELETE")
+	s.HandleFunc("/", router.create).Methods("POST")
+	s.HandleFunc("/", router.getAll).Methods("GET")
+}
+
+func (router *WebhookRouter) getEvents(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	SendJSON(w, WebhookEvents)
+}
+
+func (router *WebhookRouter) getOne(w http.ResponseWriter, r *http.Request) {
+	e := router.getWebhookForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	SendJSON(w, router.copyToRestModel(e))
+}
+
+func (router *WebhookRouter) getAll(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	list, err := GetWebhookRepository().GetAll(user.OrganizationID)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetWebhookResponse{}
+	for _, e := range list {
+		res = append(res, router.copyToRestModel(e))
+	}
+	SendJSON(w, res)
+}
+
+func (router *WebhookRouter) create(w http.ResponseWriter, r *http.Request) {
+	user := GetRequestUser(r)
+	if !HasPermission(user, user.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return
+	}
+	var m CreateWebhookRequest
+	if UnmarshalValidateBody(r, &m) != nil || !router.isValidRequest(&m) {
+		SendBadRequest(w)
+		return
+	}
+	secret, secretEncrypted, err := router.generateSecret()
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	e := router.copyFromRestModel(&m)
+	e.OrganizationID = user.OrganizationID
+	e.SecretEncrypted = secretEncrypted
+	e.Created = time.Now().UTC()
+	if err := GetWebhookRepository().Create(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:     AuditActionCreate,
+		EntityType: AuditEntityWebhook,
+		EntityID:   e.ID,
+		EntityName: e.Name,
+	}, nil, router.copyToRestModel(e))
+	res := &CreateWebhookResponse{
+		GetWebhookResponse: *router.copyToRestModel(e),
+		Secret:             secret,
+	}
+	w.Header().Set("X-Object-ID", e.ID)
+	w.WriteHeader(http.StatusCreated)
+	SendJSON(w, res)
+}
+
+func (router *WebhookRouter) update(w http.ResponseWriter, r *http.Request) {
+	var m CreateWebhookRequest
+	if UnmarshalValidateBody(r, &m) != nil || !router.isValidRequest(&m) {
+		SendBadRequest(w)
+		return
+	}
+	e := router.getWebhookForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	eNew := router.copyFromRestModel(&m)
+	eNew.ID = e.ID
+	eNew.OrganizationID = e.OrganizationID
+	eNew.SecretEncrypted = e.SecretEncrypted
+	eNew.ConsecutiveFailures = e.ConsecutiveFailures
+	eNew.DisabledAt = e.DisabledAt
+	eNew.Created = e.Created
+	if err := GetWebhookRepository().Update(eNew); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityWebhook,
+		EntityID:       e.ID,
+		EntityName:     eNew.Name,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), router.copyToRestModel(eNew))
+	SendUpdated(w)
+}
+
+func (router *WebhookRouter) delete(w http.ResponseWriter, r *http.Request) {
+	e := router.getWebhookForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	if err := GetWebhookRepository().Delete(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionDelete,
+		EntityType:     AuditEntityWebhook,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, router.copyToRestModel(e), nil)
+	SendUpdated(w)
+}
+
+func (router *WebhookRouter) rotateSecret(w http.ResponseWriter, r *http.Request) {
+	e := router.getWebhookForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	secret, secretEncrypted, err := router.generateSecret()
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	before := map[string]string{"secret": e.SecretEncrypted}
+	e.SecretEncrypted = secretEncrypted
+	if err := GetWebhookRepository().Update(e); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	recordAuditLog(r, &AuditLogEntry{
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityWebhook,
+		EntityID:       e.ID,
+		EntityName:     e.Name,
+		OrganizationID: e.OrganizationID,
+	}, before, map[string]string{"secret": e.SecretEncrypted})
+	SendJSON(w, &GetWebhookSecretResponse{Secret: secret})
+}
+
+func (router *WebhookRouter) getDeliveries(w http.ResponseWriter, r *http.Request) {
+	e := router.getWebhookForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	list, err := GetWebhookRepository().GetDeliveries(e.ID, webhookDeliveryLogSize)
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	res := []*GetWebhookDeliveryResponse{}
+	for _, d := range list {
+		res = append(res, router.copyDeliveryToRestModel(d))
+	}
+	SendJSON(w, res)
+}
+
+// retryDelivery schedules a finished delivery to be sent again immediately.
+func (router *WebhookRouter) retryDelivery(w http.ResponseWriter, r *http.Request) {
+	e := router.getWebhookForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	vars := mux.Vars(r)
+	d, err := GetWebhookRepository().GetDelivery(vars["deliveryId"])
+	if err != nil || d.WebhookID != e.ID {
+		SendNotFound(w)
+		return
+	}
+	if !e.Enabled || d.Status == WebhookDeliveryStatusPending || d.Event == WebhookEventTest {
+		SendBadRequest(w)
+		return
+	}
+	now := time.Now().UTC()
+	d.Status = WebhookDeliveryStatusPending
+	d.Attempts = 0
+	d.NextAttempt = &now
+	if err := GetWebhookRepository().UpdateDelivery(d); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	go DeliverPendingWebhooks()
+	SendUpdated(w)
+}
+
+// sendTestEvent synchronously sends a test event to the webhook, even if it
+// is disabled, and returns the result. Test events are not retried.
+func (router *WebhookRouter) sendTestEvent(w http.ResponseWriter, r *http.Request) {
+	e := router.getWebhookForAdmin(w, r)
+	if e == nil {
+		return
+	}
+	now := time.Now().UTC()
+	payload, err := newWebhookPayload(e.OrganizationID, WebhookEventTest, now, &WebhookTestData{
+		WebhookID: e.ID,
+		Message:   "This is a test event sent by Seatsurfing.",
+	})
+	if err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	d := &WebhookDelivery{
+		WebhookID:      e.ID,
+		OrganizationID: e.OrganizationID,
+		Event:          WebhookEventTest,
+		Payload:        string(payload),
+		Status:         WebhookDeliveryStatusPending,
+		Created:        now,
+	}
+	if err := GetWebhookRepository().CreateDelivery(d); err != nil {
+		log.Println(err)
+		SendInternalServerError(w)
+		return
+	}
+	deliverWebhook(e, d, false)
+	SendJSON(w, router.copyDeliveryToRestModel(d))
+}
+
+// getWebhookForAdmin loads the webhook referenced in the request path and
+// ensures the request user may manage the settings of the webhook's
+// organization. On failure, the error response has already been sent and nil
+// is returned.
+func (router *WebhookRouter) getWebhookForAdmin(w http.ResponseWriter, r *http.Request) *Webhook {
+	vars := mux.Vars(r)
+	e, err := GetWebhookRepository().GetOne(vars["id"])
+	if err != nil {
+		SendNotFound(w)
+		return nil
+	}
+	if !HasPermission(GetRequestUser(r), e.OrganizationID, PermissionManageSettings) {
+		SendForbidden(w)
+		return nil
+	}
+	return e
+}
+
+func (router *WebhookRouter) isValidRequest(m *CreateWebhookRequest) bool {
+	u, err := url.ParseRequestURI(m.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil {
+		return false
+	}
+	for _, event := range m.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return false
+		}
+	}
+	return true
+}
+
+// generateSecret returns a new random signing secret and its encrypted form.
+func (router *WebhookRouter) generateSecret() (string, string, error) {
+	rawBytes := make([]byte, 32)
+	if _, err := rand.Read(rawBytes); err != nil {
+		return "", "", err
+	}
+	secret := webhookSecretPrefix + hex.EncodeToString(rawBytes)
+	secretEncrypted, err := EncryptString(secret)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, secretEncrypted, nil
+}
+
+func (router *WebhookRouter) copyFromRestModel(m *CreateWebhookRequest) *Webhook {
+	return &Webhook{
+		Name:    m.Name,
+		URL:     m.URL,
+		Events:  slices.Compact(slices.Sorted(slices.Values(m.Events))),
+		Enabled: m.Enabled,
+	}
+}
+
+func (router *WebhookRouter) copyToRestModel(e *Webhook) *GetWebhookResponse {
+	m := &GetWebhookResponse{}
+	m.ID = e.ID
+	m.Name = e.Name
+	m.URL = e.URL
+	m.Events = e.Events
+	m.Enabled = e.Enabled
+	m.ConsecutiveFailures = e.ConsecutiveFailures
+	m.DisabledAt = e.DisabledAt
+	m.Created = e.Created
+	return m
+}
+
+func (router *WebhookRouter) copyDeliveryToRestModel(e *WebhookDelivery) *GetWebhookDeliveryResponse {
+	return &GetWebhookDeliveryResponse{
+		ID:           e.ID,
+		WebhookID:    e.WebhookID,
+		Event:        e.Event,
+		Status:       webhookDeliveryStatusNames[e.Status],
+		Attempts:     e.Attempts,
+		NextAttempt:  e.NextAttempt,
+		LastAttempt:  e.LastAttempt,
+		ResponseCode: e.ResponseCode,
+		Error:        e.Error,
+		Created:      e.Created,
+		Payload:      e.Payload,
+	}
+}
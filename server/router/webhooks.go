@@ -0,0 +1,290 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	. "github.com/seatsurfing/seatsurfing/server/api"
+	. "github.com/seatsurfing/seatsurfing/server/repository"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+const (
+	// Deliveries are given up after this many attempts
+	webhookMaxAttempts = 10
+	// Webhooks are disabled after this many consecutive failed attempts
+	webhookMaxConsecutiveFailures = 20
+	// Delay before the first retry, doubled with every further attempt
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = 6 * time.Hour
+	// Claimed deliveries are not picked up by other workers during this period
+	webhookDeliveryLease = 5 * time.Minute
+	webhookDeliveryBatch = 50
+	// Maximum number of response body bytes kept for failed deliveries
+	webhookMaxResponseLog = 256
+)
+
+// WebhookPayload is the JSON body sent to webhook receivers.
+type WebhookPayload struct {
+	ID             string    `json:"id"`
+	Event          string    `json:"event"`
+	Timestamp      time.Time `json:"timestamp"`
+	OrganizationID string    `json:"organizationId"`
+	Data           any       `json:"data"`
+}
+
+type WebhookBookingData struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"userId"`
+	UserEmail    string    `json:"userEmail"`
+	SpaceID      string    `json:"spaceId"`
+	SpaceName    string    `json:"spaceName"`
+	LocationID   string    `json:"locationId"`
+	LocationName string    `json:"locationName"`
+	Enter        time.Time `json:"enter"`
+	Leave        time.Time `json:"leave"`
+	Subject      string    `json:"subject"`
+	Approved     bool      `json:"approved"`
+	RecurringID  string    `json:"recurringId,omitempty"`
+}
+
+type WebhookUserData struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Firstname string `json:"firstname"`
+	Lastname  string `json:"lastname"`
+	Role      int    `json:"role"`
+	Disabled  bool   `json:"disabled"`
+}
+
+type WebhookSpaceData struct {
+	ID         string `json:"id"`
+	LocationID string `json:"locationId"`
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+}
+
+type WebhookTestData struct {
+	WebhookID string `json:"webhookId"`
+	Message   string `json:"message"`
+}
+
+var webhookDeliveryMu sync.Mutex
+
+// dispatchWebhookEvent stores a delivery for every enabled webhook of the
+// organization which subscribed to the event and triggers sending them in
+// the background.
+func dispatchWebhookEvent(organizationID, event string, data any) {
+	webhooks, err := GetWebhookRepository().GetEnabledForEvent(organizationID, event)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+	now := time.Now().UTC()
+	payload, err := newWebhookPayload(organizationID, event, now, data)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, webhook := range webhooks {
+		d := &WebhookDelivery{
+			WebhookID:      webhook.ID,
+			OrganizationID: organizationID,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         WebhookDeliveryStatusPending,
+			NextAttempt:    &now,
+			Created:        now,
+		}
+		if err := GetWebhookRepository().CreateDelivery(d); err != nil {
+			log.Println(err)
+		}
+	}
+	go DeliverPendingWebhooks()
+}
+
+func newWebhookPayload(organizationID, event string, timestamp time.Time, data any) ([]byte, error) {
+	return json.Marshal(&WebhookPayload{
+		ID:             uuid.New().String(),
+		Event:          event,
+		Timestamp:      timestamp,
+		OrganizationID: organizationID,
+		Data:           data,
+	})
+}
+
+func dispatchBookingWebhook(event string, e *Booking) {
+	space, err := GetSpaceRepository().GetOne(e.SpaceID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	location, err := GetLocationRepository().GetOne(space.LocationID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	data := &WebhookBookingData{
+		ID:           e.ID,
+		UserID:       e.UserID,
+		SpaceID:      e.SpaceID,
+		SpaceName:    space.Name,
+		LocationID:   location.ID,
+		LocationName: location.Name,
+		Subject:      e.Subject,
+		Approved:     e.Approved,
+		RecurringID:  string(e.RecurringID),
+	}
+	data.Enter, _ = GetLocationRepository().AttachTimezoneInformation(e.Enter, location)
+	data.Leave, _ = GetLocationRepository().AttachTimezoneInformation(e.Leave, location)
+	if user, err := GetUserRepository().GetOne(e.UserID); err == nil {
+		data.UserEmail = user.Email
+	}
+	dispatchWebhookEvent(location.OrganizationID, event, data)
+}
+
+func dispatchUserWebhook(event string, e *User) {
+	dispatchWebhookEvent(e.OrganizationID, event, &WebhookUserData{
+		ID:        e.ID,
+		Email:     e.Email,
+		Firstname: e.Firstname,
+		Lastname:  e.Lastname,
+		Role:      int(e.Role),
+		Disabled:  e.Disabled,
+	})
+}
+
+func dispatchSpaceWebhook(event, organizationID string, e *Space) {
+	dispatchWebhookEvent(organizationID, event, &WebhookSpaceData{
+		ID:         e.ID,
+		LocationID: e.LocationID,
+		Name:       e.Name,
+		Enabled:    e.Enabled,
+	})
+}
+
+// DeliverPendingWebhooks sends all deliveries which are due.
+func DeliverPendingWebhooks() {
+	webhookDeliveryMu.Lock()
+	defer webhookDeliveryMu.Unlock()
+
+	deliveries, err := GetWebhookRepository().ClaimDueDeliveries(webhookDeliveryBatch, webhookDeliveryLease)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func(d *WebhookDelivery) {
+			defer wg.Done()
+			webhook, err := GetWebhookRepository().GetOne(d.WebhookID)
+			if err != nil || !webhook.Enabled {
+				d.Status = WebhookDeliveryStatusFailed
+				d.NextAttempt = nil
+				d.Error = "webhook disabled"
+				if err := GetWebhookRepository().UpdateDelivery(d); err != nil {
+					log.Println(err)
+				}
+				return
+			}
+			deliverWebhook(webhook, d, true)
+		}(d)
+	}
+	wg.Wait()
+}
+
+// deliverWebhook performs one delivery attempt and saves its result. If retry
+// is false, a failed attempt is final and does not count towards disabling
+// the webhook (used for test events).
+func deliverWebhook(webhook *Webhook, d *WebhookDelivery, retry bool) {
+	now := time.Now().UTC()
+	d.Attempts++
+	d.LastAttempt = &now
+	d.ResponseCode, d.Error = sendWebhookRequest(webhook, d)
+	if d.Error == "" {
+		d.Status = WebhookDeliveryStatusSucceeded
+		d.NextAttempt = nil
+		if err := GetWebhookRepository().ResetFailures(webhook.ID); err != nil {
+			log.Println(err)
+		}
+	} else if !retry || d.Attempts >= webhookMaxAttempts {
+		d.Status = WebhookDeliveryStatusFailed
+		d.NextAttempt = nil
+	} else {
+		next := now.Add(GetWebhookRetryDelay(d.Attempts))
+		d.Status = WebhookDeliveryStatusPending
+		d.NextAttempt = &next
+	}
+	if err := GetWebhookRepository().UpdateDelivery(d); err != nil {
+		log.Println(err)
+	}
+	if d.Error == "" || !retry {
+		return
+	}
+	disabled, err := GetWebhookRepository().RecordFailure(webhook.ID, webhookMaxConsecutiveFailures)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if disabled {
+		log.Printf("Disabled webhook %s of organization %s after %d consecutive failures", webhook.ID, webhook.OrganizationID, webhookMaxConsecutiveFailures)
+		if err := GetWebhookRepository().FailPendingDeliveries(webhook.ID, "webhook disabled"); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// sendWebhookRequest posts the signed payload to the webhook's URL. Returns
+// the HTTP status code and an empty error string on success.
+func sendWebhookRequest(webhook *Webhook, d *WebhookDelivery) (int, string) {
+	secret, err := DecryptString(webhook.SecretEncrypted)
+	if err != nil {
+		log.Println(err)
+		return 0, "could not decrypt signing secret"
+	}
+	payload := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Seatsurfing-Webhook/"+GetProductVersion())
+	req.Header.Set(WebhookHeaderEvent, d.Event)
+	req.Header.Set(WebhookHeaderDelivery, d.ID)
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(secret, time.Now(), payload))
+	res, err := GetWebhookHTTPClient().Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(res.Body, webhookMaxResponseLog))
+		return res.StatusCode, ""
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, webhookMaxResponseLog))
+	msg := "HTTP " + strconv.Itoa(res.StatusCode)
+	if len(body) > 0 {
+		msg += ": " + string(body)
+	}
+	return res.StatusCode, msg
+}
+
+// GetWebhookRetryDelay returns the delay before the next attempt after the
+// given number of failed attempts.
+func GetWebhookRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(float64(webhookRetryBaseDelay) * math.Pow(2, float64(attempts-1)))
+	return min(delay, webhookRetryMaxDelay)
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// How long clients may cache the JSON Web Key Set, must be shorter than the
+// signing key prepublish period
+const jwksMaxAgeSeconds = "300"
+
+type WellKnownRouter struct {
+}
+
+func (router *WellKnownRouter) SetupRoutes(s *mux.Router) {
+	s.HandleFunc("/jwks.json", router.getJSONWebKeySet).Methods("GET")
+}
+
+func (router *WellKnownRouter) getJSONWebKeySet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age="+jwksMaxAgeSeconds)
+	SendJSON(w, GetSigningKeyRing().GetJSONWebKeySet())
+}
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
@@ -34,23 +35,40 @@ type LoginResponse struct {
 }
 
 var DatabaseTables = [...]string{
+	"api_tokens",
 	"auth_attempts",
 	"auth_providers",
 	"auth_states",
 	"bookings",
+	"booking_cancellations",
 	"buddies",
 	"debug_time_issues",
 	"groups",
 	"location_allowed_bookers",
 	"locations",
+	"login_history",
+	"audit_log",
+	"delegations",
+	"email_templates",
+	"roles",
+	"roles_assignments",
+	"locations_admins",
+	"locations_allowed_attributes",
+	"user_attributes",
+	"user_attribute_values",
 	"mail_logs",
+	"mail_queue",
 	"organizations",
 	"organizations_domains",
+	"organizations_mail_settings",
 	"passkeys",
+	"password_history",
+	"push_subscriptions",
 	"recurring_bookings",
 	"refresh_tokens",
 	"sessions",
 	"settings",
+	"signing_keys",
 	"space_attribute_values",
 	"space_attributes",
 	"spaces",
@@ -60,7 +78,10 @@ var DatabaseTables = [...]string{
 	"spaces_attributes_values",
 	"users",
 	"users_groups",
+	"users_mail_digests",
 	"users_preferences",
+	"webhook_deliveries",
+	"webhooks",
 }
 
 func GetTestJWT(userID string) string {
@@ -247,6 +268,22 @@ func CreateTestGroup(org *Organization, user *User) *Group {
 	return group
 }
 
+// CreateTestBooking creates a booking of the space for the user without
+// checking any booking rules.
+func CreateTestBooking(user *User, space *Space, enter, leave time.Time, approved bool) *Booking {
+	booking := &Booking{
+		UserID:   user.ID,
+		SpaceID:  space.ID,
+		Enter:    enter,
+		Leave:    leave,
+		Approved: approved,
+	}
+	if err := GetBookingRepository().Create(booking); err != nil {
+		panic(err)
+	}
+	return booking
+}
+
 func CreateTestBooking9To5(user *User, space *Space, offsetDay int) *Booking {
 	now := time.Now()
 	enterTime := time.Date(now.Year(), now.Month(), now.Day()+offsetDay, 9, 0, 0, 0, time.Local)
@@ -263,6 +300,57 @@ func CreateTestBooking9To5(user *User, space *Space, offsetDay int) *Booking {
 	return booking
 }
 
+// TestApprovalBooking is a booking pending approval created by
+// CreateTestApprovalBooking.
+type TestApprovalBooking struct {
+	Org       *Organization
+	User      *User
+	Location  *Location
+	Space     *Space
+	Approvers [][]*User // Approvers per approval level
+	ID        string
+}
+
+// CreateTestApprovalBooking creates an organization with a space requiring
+// approval and a booking from 2030-09-01 08:30 to 17:00 pending approval. The
+// arguments specify the number of approvers (org admins) per approval level,
+// each level being approved by its own group.
+func CreateTestApprovalBooking(t *testing.T, approversPerLevel ...int) *TestApprovalBooking {
+	org := CreateTestOrg("test.com")
+	GetSettingsRepository().Set(org.ID, SettingMaxDaysInAdvance.Name, "5000")
+	GetSettingsRepository().Set(org.ID, SettingFeatureGroups.Name, "1")
+	res := &TestApprovalBooking{
+		Org:  org,
+		User: CreateTestUserInOrg(org),
+	}
+	res.Location = &Location{
+		Name:           "Test",
+		OrganizationID: org.ID,
+		Enabled:        true,
+	}
+	GetLocationRepository().Create(res.Location)
+	res.Space = &Space{Name: "Test 1", LocationID: res.Location.ID, Enabled: true}
+	GetSpaceRepository().Create(res.Space)
+	for i, num := range approversPerLevel {
+		approvers := []*User{}
+		group := CreateTestGroup(org, nil)
+		for j := 0; j < num; j++ {
+			approver := CreateTestUserOrgAdmin(org)
+			GetGroupRepository().AddMembers(group, []string{approver.ID})
+			approvers = append(approvers, approver)
+		}
+		GetSpaceRepository().AddApproversForLevel(res.Space, []string{group.ID}, i+1)
+		res.Approvers = append(res.Approvers, approvers)
+	}
+
+	payload := "{\"spaceId\": \"" + res.Space.ID + "\", \"enter\": \"2030-09-01T08:30:00Z\", \"leave\": \"2030-09-01T17:00:00Z\"}"
+	req := NewHTTPRequest("POST", "/booking/", res.User.ID, bytes.NewBufferString(payload))
+	rr := ExecuteTestRequest(req)
+	CheckTestResponseCode(t, http.StatusCreated, rr.Code)
+	res.ID = rr.Header().Get("X-Object-Id")
+	return res
+}
+
 func DropTestDB() {
 	for _, s := range DatabaseTables {
 		GetDatabase().DB().Exec("DROP TABLE IF EXISTS " + s)
@@ -354,6 +442,7 @@ func TestRunner(m *testing.M) {
 	os.Setenv("MOCK_SENDMAIL", "1")
 	os.Setenv("ALLOW_ORG_DELETE", "1")
 	os.Setenv("LOGIN_PROTECTION_MAX_FAILS", "3")
+	os.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "1")
 	GetConfig().ReadConfig()
 	db := GetDatabase()
 	DropTestDB()
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+)
+
+// GetActionLinkToken returns a token for links in emails which perform an
+// action without logging in. It consists of the ID of the server-side state
+// and a signature so that forged tokens are rejected before a lookup.
+func GetActionLinkToken(id string) string {
+	return id + "." + computeActionLinkSignature(id)
+}
+
+// ParseActionLinkToken verifies a token created by GetActionLinkToken and
+// returns the ID it contains.
+func ParseActionLinkToken(token string) (string, bool) {
+	id, signature, found := strings.Cut(token, ".")
+	if !found || id == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(computeActionLinkSignature(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+func computeActionLinkSignature(id string) string {
+	mac := hmac.New(sha256.New, []byte(GetConfig().CryptKey))
+	mac.Write([]byte("action-link:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
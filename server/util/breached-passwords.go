@@ -0,0 +1,47 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+)
+
+// IsPasswordBreached checks the password against the breached password list
+// in BREACHED_PASSWORDS_PATH. The list uses the k-anonymity range format of
+// the Pwned Passwords API: one file per 5 character SHA-1 hash prefix (e.g.
+// "21BD1.txt"), each line containing the remaining 35 characters of a hash
+// and a count, separated by a colon. Only the file matching the password's
+// prefix is read. Returns false if no list is configured.
+func IsPasswordBreached(password string) (bool, error) {
+	basePath := GetConfig().BreachedPasswordsPath
+	if basePath == "" {
+		return false, nil
+	}
+	hash := sha1.Sum([]byte(password))
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	prefix, suffix := hexHash[:5], hexHash[5:]
+	file, err := os.Open(filepath.Join(basePath, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lineSuffix, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(lineSuffix, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,232 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	ChatFormatSlack      = "slack"
+	ChatFormatTeams      = "teams"
+	ChatFormatMattermost = "mattermost"
+	chatMaxResponseLog   = 512
+)
+
+var ChatFormats = []string{ChatFormatSlack, ChatFormatTeams, ChatFormatMattermost}
+
+var ErrChatFormatUnknown = errors.New("unknown chat message format")
+
+// ChatMessage is a notification independent of the service it is posted to.
+type ChatMessage struct {
+	Title     string
+	Lines     []string
+	LinkURL   string
+	LinkLabel string
+}
+
+// NotificationChannel delivers notifications in addition to email.
+type NotificationChannel interface {
+	Send(m *ChatMessage) error
+}
+
+// ChatWebhookChannel posts messages to an incoming webhook of Slack,
+// Microsoft Teams or Mattermost.
+type ChatWebhookChannel struct {
+	URL    string
+	Format string
+}
+
+func (c *ChatWebhookChannel) Send(m *ChatMessage) error {
+	payload, err := BuildChatPayload(c.Format, m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Seatsurfing-Webhook/"+GetProductVersion())
+	res, err := GetWebhookHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(res.Body, chatMaxResponseLog))
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, chatMaxResponseLog))
+	msg := "HTTP " + strconv.Itoa(res.StatusCode)
+	if len(body) > 0 {
+		msg += ": " + string(body)
+	}
+	return errors.New(msg)
+}
+
+// IsValidChatFormat returns true if messages can be built in the format.
+func IsValidChatFormat(format string) bool {
+	return slices.Contains(ChatFormats, format)
+}
+
+// ValidateChatWebhookURL checks an incoming webhook URL. The target address is
+// checked again when the message is sent.
+func ValidateChatWebhookURL(s string) bool {
+	if len(s) > 512 {
+		return false
+	}
+	u, err := url.ParseRequestURI(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" && u.User == nil
+}
+
+// BuildChatPayload returns the JSON body of an incoming webhook request in the
+// given format.
+func BuildChatPayload(format string, m *ChatMessage) ([]byte, error) {
+	switch format {
+	case ChatFormatSlack:
+		return json.Marshal(buildSlackPayload(m))
+	case ChatFormatTeams:
+		return json.Marshal(buildTeamsPayload(m))
+	case ChatFormatMattermost:
+		return json.Marshal(buildMattermostPayload(m))
+	}
+	return nil, ErrChatFormatUnknown
+}
+
+// buildSlackPayload creates a Block Kit message. The text is used as fallback
+// for notifications.
+func buildSlackPayload(m *ChatMessage) map[string]any {
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": m.Title},
+		},
+	}
+	if len(m.Lines) > 0 {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": escape.Replace(strings.Join(m.Lines, "\n"))},
+		})
+	}
+	if m.LinkURL != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "actions",
+			"elements": []map[string]any{
+				{
+					"type": "button",
+					"text": map[string]any{"type": "plain_text", "text": m.LinkLabel},
+					"url":  m.LinkURL,
+				},
+			},
+		})
+	}
+	return map[string]any{
+		"text":   m.Title,
+		"blocks": blocks,
+	}
+}
+
+// buildTeamsPayload creates a message with an Adaptive Card as accepted by
+// Teams workflows and incoming webhooks.
+func buildTeamsPayload(m *ChatMessage) map[string]any {
+	body := []map[string]any{
+		{
+			"type":   "TextBlock",
+			"text":   m.Title,
+			"size":   "Medium",
+			"weight": "Bolder",
+			"wrap":   true,
+		},
+	}
+	for _, line := range m.Lines {
+		body = append(body, map[string]any{
+			"type": "TextBlock",
+			"text": line,
+			"wrap": true,
+		})
+	}
+	actions := []map[string]any{}
+	if m.LinkURL != "" {
+		actions = append(actions, map[string]any{
+			"type":  "Action.OpenUrl",
+			"title": m.LinkLabel,
+			"url":   m.LinkURL,
+		})
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+					"actions": actions,
+				},
+			},
+		},
+	}
+}
+
+// buildMattermostPayload creates a message with a Slack-compatible message
+// attachment as supported by Mattermost.
+func buildMattermostPayload(m *ChatMessage) map[string]any {
+	text := strings.Join(m.Lines, "\n")
+	if m.LinkURL != "" {
+		text += "\n\n[" + m.LinkLabel + "](" + m.LinkURL + ")"
+	}
+	return map[string]any{
+		"attachments": []map[string]any{
+			{
+				"fallback":   m.Title,
+				"title":      m.Title,
+				"title_link": m.LinkURL,
+				"text":       strings.TrimSpace(text),
+			},
+		},
+	}
+}
+
+// RenderChatMessage creates a message from a built-in email template. The
+// subject becomes the title, the paragraphs the lines of the message and the
+// first button its link.
+func RenderChatMessage(templateFile, language string, vars map[string]string) (*ChatMessage, error) {
+	actualTemplateFile, err := GetEmailTemplatePath(templateFile, language)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(actualTemplateFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading json template file: %v", err)
+	}
+	t, err := parseMailTemplate(data)
+	if err != nil {
+		return nil, err
+	}
+	m := &ChatMessage{
+		Title: ReplaceVarsInText(t.Subject, vars),
+		Lines: []string{},
+	}
+	for _, paragraph := range t.Paragraphs {
+		if s := strings.TrimSpace(ReplaceVarsInText(paragraph, vars)); s != "" {
+			m.Lines = append(m.Lines, s)
+		}
+	}
+	if len(t.Buttons) > 0 {
+		m.LinkURL = ReplaceVarsInText(t.Buttons[0].URL, vars)
+		m.LinkLabel = ReplaceVarsInText(t.Buttons[0].Label, vars)
+	}
+	return m, nil
+}
@@ -0,0 +1,283 @@
+package util
+
+import (
+	"errors"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+)
+
+var (
+	// ErrEmailTemplateMissingVariable indicates that a custom template does not use a required variable
+	ErrEmailTemplateMissingVariable = errors.New("email template misses a required variable")
+	// ErrEmailTemplateUnknownVariable indicates that a custom template uses a variable which is not available
+	ErrEmailTemplateUnknownVariable = errors.New("email template uses an unknown variable")
+)
+
+// EmailTemplateDefinition describes a built-in email template which can be
+// overridden by organizations.
+type EmailTemplateDefinition struct {
+	Name string
+	Path func() string
+	// Variables replaced with their value
+	Variables []string
+	// Variables set to "1" or "0", used with {{if x}}…{{end}}
+	Conditions []string
+	// Variables a custom template must use, e.g. because the mail is useless
+	// without the link containing them
+	RequiredVariables []string
+}
+
+// CustomEmailTemplate is an organization-defined replacement for a built-in
+// email template in one language.
+type CustomEmailTemplate struct {
+	Subject  string
+	Headline string
+	BodyHTML string
+	BodyText string
+}
+
+// OrgEmailTemplateProvider returns an organization's custom template for the
+// given template name and language, or nil if there is none.
+type OrgEmailTemplateProvider func(organizationID, name, language string) (*CustomEmailTemplate, error)
+
+var orgEmailTemplateProvider OrgEmailTemplateProvider
+
+func SetOrgEmailTemplateProvider(p OrgEmailTemplateProvider) {
+	orgEmailTemplateProvider = p
+}
+
+var bookingEmailTemplateVariables = []string{"recipientName", "orgDomain", "date", "areaName", "spaceName", "subject"}
+
+var EmailTemplateDefinitions = []*EmailTemplateDefinition{
+	{Name: "booking-created", Path: GetEmailTemplatePathBookingCreated, Variables: bookingEmailTemplateVariables},
+	{Name: "booking-updated", Path: GetEmailTemplatePathBookingUpdated, Variables: bookingEmailTemplateVariables},
+	{
+		Name:       "booking-deleted",
+		Path:       GetEmailTemplatePathBookingDeleted,
+		Variables:  append(slices.Clone(bookingEmailTemplateVariables), "reason"),
+		Conditions: []string{"hasReason"},
+	},
+	{Name: "booking-approved", Path: GetEmailTemplatePathBookingApproved, Variables: bookingEmailTemplateVariables},
+	{Name: "booking-declined", Path: GetEmailTemplatePathBookingDeclined, Variables: bookingEmailTemplateVariables},
+	{Name: "booking-reminder", Path: GetEmailTemplatePathBookingReminder, Variables: bookingEmailTemplateVariables},
+	{
+		Name:       "booking-approval-request",
+		Path:       GetEmailTemplatePathBookingApprovalRequest,
+		Variables:  append(slices.Clone(bookingEmailTemplateVariables), "userEmail", "actionToken", "pendingHours"),
+		Conditions: []string{"secondLevel", "reminder", "escalated"},
+	},
+	{
+		Name:       "booking-delegate",
+		Path:       GetEmailTemplatePathBookingDelegate,
+		Variables:  append(slices.Clone(bookingEmailTemplateVariables), "principalName", "reason"),
+		Conditions: []string{"created", "updated", "deleted", "approved", "declined", "hasReason"},
+	},
+	{
+		Name:      "recurring-booking-created",
+		Path:      GetEmailTemplatePathRecurringBookingCreated,
+		Variables: []string{"recipientName", "date", "areaName", "spaceName", "subject"},
+	},
+	{
+		Name:              "invite-user",
+		Path:              GetEmailTemplatePathInviteUser,
+		Variables:         []string{"recipientName", "orgDomain", "confirmID"},
+		RequiredVariables: []string{"orgDomain", "confirmID"},
+	},
+	{
+		Name:              "resetpw",
+		Path:              GetEmailTemplatePathResetpassword,
+		Variables:         []string{"recipientName", "orgDomain", "confirmID"},
+		RequiredVariables: []string{"orgDomain", "confirmID"},
+	},
+	{
+		Name:              "magic-link",
+		Path:              GetEmailTemplatePathMagicLink,
+		Variables:         []string{"recipientName", "orgDomain", "confirmID"},
+		RequiredVariables: []string{"orgDomain", "confirmID"},
+	},
+	{
+		Name:              "change-email",
+		Path:              GetEmailTemplatePathChangeEmailAddress,
+		Variables:         []string{"recipientName", "code"},
+		RequiredVariables: []string{"code"},
+	},
+	{
+		Name:              "confirm-delete-org",
+		Path:              GetEmailTemplatePathConfirmDeleteOrg,
+		Variables:         []string{"recipientName", "orgDomain", "orgName", "confirmID"},
+		RequiredVariables: []string{"orgDomain", "confirmID"},
+	},
+	{
+		Name:       "security-notification",
+		Path:       GetEmailTemplatePathSecurityNotification,
+		Variables:  []string{"recipientName", "orgDomain", "time", "passkeyName"},
+		Conditions: []string{"passkeyAdded", "passkeyRemoved", "totpEnabled", "totpDisabled", "adminResetTotp", "adminResetPasskeys"},
+	},
+	{
+		Name:       "suspicious-login",
+		Path:       GetEmailTemplatePathSuspiciousLogin,
+		Variables:  []string{"recipientName", "orgDomain", "time", "ip", "device"},
+		Conditions: []string{"newDevice", "newNetwork", "rapidNetworkSwitch"},
+	},
+	{
+		Name:      "suspicious-login-digest",
+		Path:      GetEmailTemplatePathSuspiciousLoginDigest,
+		Variables: []string{"recipientName", "orgDomain", "count", "users"},
+	},
+	{
+		Name:       "digest",
+		Path:       GetEmailTemplatePathDigest,
+		Variables:  []string{"recipientName", "orgDomain", "bookings", "bookingCount", "approvals", "approvalCount"},
+		Conditions: []string{"hasBookings", "hasApprovals", "weekly"},
+	},
+}
+
+// Values used for previewing templates
+var emailTemplateSampleValues = map[string]string{
+	"recipientName": "Jane Doe",
+	"orgDomain":     "https://seatsurfing.example.com/",
+	"date":          "2026-01-05 09:00 - 17:00",
+	"areaName":      "First floor",
+	"spaceName":     "Desk 42",
+	"subject":       "Team meeting",
+	"userEmail":     "john.doe@example.com",
+	"principalName": "John Doe",
+	"confirmID":     "00000000-0000-0000-0000-000000000000",
+	"actionToken":   "00000000-0000-0000-0000-000000000000.c2lnbmF0dXJl",
+	"code":          "123456",
+	"orgName":       "Example Inc.",
+	"time":          "2026-01-05 08:15",
+	"passkeyName":   "Security key",
+	"ip":            "192.0.2.1",
+	"device":        "Firefox on Linux",
+	"count":         "2",
+	"users":         "jane.doe@example.com, john.doe@example.com",
+	"bookings":      "2026-01-05 09:00 - 17:00: Desk 42, First floor\n2026-01-06 09:00 - 17:00: Desk 42, First floor",
+	"bookingCount":  "2",
+	"approvals":     "john.doe@example.com: 2026-01-07 09:00 - 17:00, Desk 42, First floor",
+	"approvalCount": "1",
+	"pendingHours":  "24",
+	"reason":        "Emergency closure",
+}
+
+var emailTemplateVariableRegex = regexp.MustCompile(`{{(?:if !?)?([A-Za-z0-9_]+)}}`)
+
+// GetEmailTemplateDefinition returns the definition of the template with the
+// given name, or nil if it does not exist or cannot be overridden.
+func GetEmailTemplateDefinition(name string) *EmailTemplateDefinition {
+	for _, def := range EmailTemplateDefinitions {
+		if def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// GetEmailTemplateName returns the name of the template stored in
+// templateFile, e.g. "booking-created" for res/email-booking-created.json.
+func GetEmailTemplateName(templateFile string) string {
+	name := strings.TrimSuffix(filepath.Base(templateFile), ".json")
+	return strings.TrimPrefix(name, "email-")
+}
+
+// GetSampleVars returns sample values for all variables of the template.
+// The first condition is set, all others are unset.
+func (def *EmailTemplateDefinition) GetSampleVars() map[string]string {
+	vars := map[string]string{}
+	for _, key := range def.Variables {
+		vars[key] = emailTemplateSampleValues[key]
+	}
+	for i, key := range def.Conditions {
+		vars[key] = "0"
+		if i == 0 {
+			vars[key] = "1"
+		}
+	}
+	return vars
+}
+
+// Validate checks that a custom template uses all required variables in its
+// bodies and no variables which are not available for the template.
+func (def *EmailTemplateDefinition) Validate(t *CustomEmailTemplate) error {
+	for _, s := range []string{t.Subject, t.Headline, t.BodyHTML, t.BodyText} {
+		for _, match := range emailTemplateVariableRegex.FindAllStringSubmatch(s, -1) {
+			key := match[1]
+			if key != "end" && !slices.Contains(def.Variables, key) && !slices.Contains(def.Conditions, key) {
+				return ErrEmailTemplateUnknownVariable
+			}
+		}
+	}
+	for _, key := range def.RequiredVariables {
+		if !strings.Contains(t.BodyHTML, "{{"+key+"}}") {
+			return ErrEmailTemplateMissingVariable
+		}
+		if t.BodyText != "" && !strings.Contains(t.BodyText, "{{"+key+"}}") {
+			return ErrEmailTemplateMissingVariable
+		}
+	}
+	return nil
+}
+
+// GetBuiltinEmailTemplate returns the built-in template in the given language
+// in the format of a custom template, to be used as a starting point for
+// customization.
+func GetBuiltinEmailTemplate(def *EmailTemplateDefinition, language string) (*CustomEmailTemplate, error) {
+	templateFile, err := GetEmailTemplatePath(def.Path(), language)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(templateFile)
+	if err != nil {
+		return nil, err
+	}
+	mailTemplate, err := parseMailTemplate(data)
+	if err != nil {
+		return nil, err
+	}
+	return &CustomEmailTemplate{
+		Subject:  mailTemplate.Subject,
+		Headline: mailTemplate.Headline,
+		BodyHTML: renderMailTemplateBody(mailTemplate),
+	}, nil
+}
+
+// RenderCustomEmailTemplate renders a custom template into the email layout.
+// Variables are HTML-escaped in the HTML body only. The footer placeholder
+// is kept in the HTML body.
+func RenderCustomEmailTemplate(t *CustomEmailTemplate, vars map[string]string) (subject, body, bodyText string, err error) {
+	layout, err := GetEmailHTMLLayout()
+	if err != nil {
+		return "", "", "", err
+	}
+	if t.Headline == "" {
+		layout = strings.ReplaceAll(layout, "<h2>{{headline}}</h2>", "")
+	}
+	layout = strings.ReplaceAll(layout, "{{headline}}", html.EscapeString(t.Headline))
+	body = strings.ReplaceAll(layout, "{{body}}", t.BodyHTML)
+	body = ReplaceVarsInTemplate(body, vars)
+	subject = ReplaceVarsInText(t.Subject, vars)
+	bodyText = ReplaceVarsInText(t.BodyText, vars)
+	return subject, body, bodyText, nil
+}
+
+// getOrgEmailTemplate returns the organization's custom template for the
+// given template file, or nil if the built-in template is to be used.
+func getOrgEmailTemplate(organizationID, templateFile, language string) (*CustomEmailTemplate, error) {
+	if organizationID == "" || orgEmailTemplateProvider == nil {
+		return nil, nil
+	}
+	def := GetEmailTemplateDefinition(GetEmailTemplateName(templateFile))
+	if def == nil {
+		return nil, nil
+	}
+	if !GetConfig().IsValidLanguageCode(language) {
+		language = EmailTemplateDefaultLanguage
+	}
+	return orgEmailTemplateProvider(organizationID, def.Name, language)
+}
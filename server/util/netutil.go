@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	. "github.com/seatsurfing/seatsurfing/server/config"
@@ -62,3 +63,97 @@ func GetHTTPClientInsecureSkipVerify() *http.Client {
 		Transport: tr,
 	}
 }
+
+// GetClientIP returns the IP address of the client which sent the request.
+// The X-Forwarded-For and X-Real-IP headers are only read if the request was
+// received from a proxy listed in TRUSTED_PROXIES, as clients can set them to
+// arbitrary values. X-Forwarded-For is walked from the right, skipping
+// trusted proxies, so the first untrusted hop is the client.
+func GetClientIP(r *http.Request) string {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+	trustedProxies := GetConfig().TrustedProxies
+	if !IsIPInNetworks(remoteIP, trustedProxies) {
+		return remoteIP
+	}
+	if forwardedFor := strings.Join(r.Header.Values("X-Forwarded-For"), ","); forwardedFor != "" {
+		clientIP := remoteIP
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(hops[i])
+			if net.ParseIP(ip) == nil {
+				break
+			}
+			clientIP = ip
+			if !IsIPInNetworks(ip, trustedProxies) {
+				break
+			}
+		}
+		return clientIP
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" && net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return remoteIP
+}
+
+// ParseNetworkList parses a list of networks in CIDR notation, separated by
+// commas or whitespace. Single IP addresses are accepted as host networks.
+func ParseNetworkList(s string) ([]*net.IPNet, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	res := []*net.IPNet{}
+	for _, field := range fields {
+		if !strings.Contains(field, "/") {
+			ip := net.ParseIP(field)
+			if ip == nil {
+				return nil, &net.ParseError{Type: "IP address", Text: field}
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(field)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ipNet)
+	}
+	return res, nil
+}
+
+// IsIPInNetworks returns true if the IP address is contained in one of the
+// networks.
+func IsIPInNetworks(ip string, networks []*net.IPNet) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIPNetwork returns the network an IP address belongs to, used to
+// recognize clients connecting from the same network: the /24 network for
+// IPv4 and the /48 network for IPv6 addresses.
+func GetIPNetwork(ip string) string {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return ""
+	}
+	if ip4 := parsedIP.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsedIP.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
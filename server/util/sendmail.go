@@ -12,12 +12,18 @@ import (
 	"log"
 	"mime"
 	"mime/multipart"
+	"net"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	. "github.com/seatsurfing/seatsurfing/server/config"
 )
@@ -85,28 +91,39 @@ func isM365SMTPServer(host string) bool {
 	return false
 }
 
-// getOptimalSMTPSettings returns optimal settings for the given SMTP host
-func getOptimalSMTPSettings(config *Config) (port int, startTLS bool, authMethod string) {
+// getOptimalSMTPSettings returns the globally configured SMTP settings,
+// optimized for the given SMTP host
+func getOptimalSMTPSettings(config *Config) *SMTPSettings {
 	// Default settings
-	port = config.SMTPPort
-	startTLS = config.SMTPStartTLS
-	authMethod = config.SMTPAuthMethod
+	settings := &SMTPSettings{
+		Host:               config.SMTPHost,
+		Port:               config.SMTPPort,
+		TLSMode:            SMTPTLSModeNone,
+		InsecureSkipVerify: config.SMTPInsecureSkipVerify,
+		Auth:               config.SMTPAuth,
+		AuthMethod:         config.SMTPAuthMethod,
+		AuthUser:           config.SMTPAuthUser,
+		AuthPass:           config.SMTPAuthPass,
+	}
+	if config.SMTPStartTLS {
+		settings.TLSMode = SMTPTLSModeStartTLS
+	}
 
 	// M365 optimal settings
 	if isM365SMTPServer(config.SMTPHost) {
 		// Override with M365-specific settings if not explicitly configured
 		if config.SMTPPort == 25 { // Default port, likely not configured for M365
-			port = 587
+			settings.Port = 587
 		}
 		if !config.SMTPStartTLS { // Force STARTTLS for M365
-			startTLS = true
+			settings.TLSMode = SMTPTLSModeStartTLS
 		}
 		if config.SMTPAuthMethod == "PLAIN" || config.SMTPAuthMethod == "" {
-			authMethod = "LOGIN" // M365 often works better with LOGIN
+			settings.AuthMethod = "LOGIN" // M365 often works better with LOGIN
 		}
 	}
 
-	return port, startTLS, authMethod
+	return settings
 }
 
 const EmailTemplateDefaultLanguage = "en"
@@ -133,6 +150,82 @@ func SetGlobalEmailFooterProvider(p GlobalEmailFooterProvider) {
 
 var SendMailMockContent = ""
 
+// SendMailMockError is returned by DeliverEmail if MOCK_SENDMAIL is enabled
+var SendMailMockError error
+
+// OutgoingMail is a fully rendered message ready for delivery
+type OutgoingMail struct {
+	Recipient *MailAddress
+	Subject   string
+	Body      string
+	// Optional plain text alternative of the HTML body
+	BodyText       string
+	Attachments    []*MailAttachment
+	OrganizationID string
+}
+
+// EmailQueueCallback is a function that queues a rendered message for
+// asynchronous delivery
+type EmailQueueCallback func(m *OutgoingMail) error
+
+var emailQueueCallback EmailQueueCallback
+
+// SetEmailQueueCallback sets the callback function for queueing emails. If
+// no callback is set, emails are sent synchronously.
+func SetEmailQueueCallback(callback EmailQueueCallback) {
+	emailQueueCallback = callback
+}
+
+const (
+	SMTPTLSModeNone     = "none"
+	SMTPTLSModeStartTLS = "starttls"
+	// TLS from the start of the connection (SMTPS), usually on port 465
+	SMTPTLSModeImplicit = "tls"
+)
+
+// SMTPSettings configures the connection to an SMTP server
+type SMTPSettings struct {
+	Host               string
+	Port               int
+	TLSMode            string
+	InsecureSkipVerify bool
+	Auth               bool
+	AuthMethod         string
+	AuthUser           string
+	AuthPass           string
+	// Refuse connecting to loopback, private and link-local addresses
+	PublicOnly bool
+}
+
+// MailTransport defines how and from which sender mails are delivered
+type MailTransport struct {
+	// SMTP server to use, nil for the globally configured mail service
+	SMTP          *SMTPSettings
+	SenderAddress string
+	SenderName    string
+	ReplyTo       string
+}
+
+// OrgMailTransportProvider returns an organization's mail transport, or nil
+// if the global mail service is to be used.
+type OrgMailTransportProvider func(organizationID string) (*MailTransport, error)
+
+var orgMailTransportProvider OrgMailTransportProvider
+
+func SetOrgMailTransportProvider(p OrgMailTransportProvider) {
+	orgMailTransportProvider = p
+}
+
+// SendMailMockSender is set to the sender of the last mail passed to
+// DeliverEmail if MOCK_SENDMAIL is enabled
+var SendMailMockSender *MailAddress
+
+// ErrMailHostNotAllowed indicates that an organization's SMTP server
+// resolves to a non-public address
+var ErrMailHostNotAllowed = errors.New("smtp server address not allowed")
+
+const smtpDialTimeout = 30 * time.Second
+
 type MailButton struct {
 	Paragraph string `json:"paragraph"`
 	URL       string `json:"url"`
@@ -170,6 +263,22 @@ func GetEmailTemplatePathResetpassword() string {
 	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-resetpw.json")
 }
 
+func GetEmailTemplatePathMagicLink() string {
+	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-magic-link.json")
+}
+
+func GetEmailTemplatePathSuspiciousLogin() string {
+	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-suspicious-login.json")
+}
+
+func GetEmailTemplatePathSuspiciousLoginDigest() string {
+	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-suspicious-login-digest.json")
+}
+
+func GetEmailTemplatePathSecurityNotification() string {
+	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-security-notification.json")
+}
+
 func GetEmailTemplatePathInviteUser() string {
 	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-invite-user.json")
 }
@@ -210,10 +319,22 @@ func GetEmailTemplatePathBookingApprovalRequest() string {
 	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-booking-approval-request.json")
 }
 
+func GetEmailTemplatePathBookingDelegate() string {
+	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-booking-delegate.json")
+}
+
 func GetEmailTemplatePathBookingReminder() string {
 	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-booking-reminder.json")
 }
 
+func GetEmailTemplatePathTestMail() string {
+	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-test-mail.json")
+}
+
+func GetEmailTemplatePathDigest() string {
+	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-digest.json")
+}
+
 func GetEmailTemplatePathFooter() string {
 	return filepath.Join(GetConfig().FilesystemBasePath, "./res/email-footer.json")
 }
@@ -236,11 +357,26 @@ func GetHTMLMailTemplate(jsonTemplate []byte) (*MailTemplate, string, error) {
 	if err != nil {
 		return nil, "", err
 	}
+	jsonContent, err := parseMailTemplate(jsonTemplate)
+	if err != nil {
+		return nil, "", err
+	}
+	s = strings.ReplaceAll(s, "{{headline}}", html.EscapeString(jsonContent.Headline))
+	s = strings.ReplaceAll(s, "{{body}}", renderMailTemplateBody(jsonContent))
+	return jsonContent, s, nil
+}
+
+func parseMailTemplate(jsonTemplate []byte) (*MailTemplate, error) {
 	var jsonContent MailTemplate
 	if err := json.Unmarshal(jsonTemplate, &jsonContent); err != nil {
-		return nil, "", fmt.Errorf("error unmarshaling json template: %v", err)
+		return nil, fmt.Errorf("error unmarshaling json template: %v", err)
 	}
-	s = strings.ReplaceAll(s, "{{headline}}", html.EscapeString(jsonContent.Headline))
+	return &jsonContent, nil
+}
+
+// renderMailTemplateBody renders the paragraphs and buttons of a template
+// into HTML, without the surrounding layout.
+func renderMailTemplateBody(jsonContent *MailTemplate) string {
 	body := ""
 	for _, paragraph := range jsonContent.Paragraphs {
 		body += "<p>" + html.EscapeString(paragraph) + "</p>"
@@ -257,8 +393,7 @@ func GetHTMLMailTemplate(jsonTemplate []byte) (*MailTemplate, string, error) {
 		text := strings.ReplaceAll(html.EscapeString(jsonContent.FinalInfo.Text), "{{link}}", anchor)
 		body += "<p class=\"small\">" + text + "</p>"
 	}
-	s = strings.ReplaceAll(s, "{{body}}", body)
-	return &jsonContent, s, nil
+	return body
 }
 
 func SendEmail(recipient *MailAddress, templateFile, language string, vars map[string]string) error {
@@ -274,25 +409,60 @@ func SendEmailWithAttachments(recipient *MailAddress, templateFile, language str
 }
 
 func SendEmailWithAttachmentsAndOrg(recipient *MailAddress, templateFile, language string, vars map[string]string, attachments []*MailAttachment, organizationID string) error {
-	actualTemplateFile, err := GetEmailTemplatePath(templateFile, language)
+	m, err := RenderEmail(recipient, templateFile, language, vars, attachments, organizationID)
 	if err != nil {
 		return err
 	}
+	return sendOutgoingMail(m)
+}
+
+// RenderEmail renders the template into a message ready for delivery. The
+// organization's custom template is used if there is one.
+func RenderEmail(recipient *MailAddress, templateFile, language string, vars map[string]string, attachments []*MailAttachment, organizationID string) (*OutgoingMail, error) {
+	customTemplate, err := getOrgEmailTemplate(organizationID, templateFile, language)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Failed to get custom email template for '%s': %v\n", templateFile, err)
+	} else if customTemplate != nil {
+		subject, body, bodyText, err := RenderCustomEmailTemplate(customTemplate, vars)
+		if err != nil {
+			return nil, err
+		}
+		return newOutgoingMail(recipient, subject, body, bodyText, language, attachments, organizationID)
+	}
+	actualTemplateFile, err := GetEmailTemplatePath(templateFile, language)
+	if err != nil {
+		return nil, err
+	}
 	actualTemplateData, err := os.ReadFile(actualTemplateFile)
 	if err != nil {
-		return fmt.Errorf("error reading json template file: %v", err)
+		return nil, fmt.Errorf("error reading json template file: %v", err)
 	}
 	mailTemplate, body, err := GetHTMLMailTemplate(actualTemplateData)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	body = ReplaceVarsInTemplate(body, vars)
-	return SendEmailWithBodyAndAttachmentAndOrg(recipient, mailTemplate.Subject, body, language, attachments, organizationID)
+	subject := ReplaceVarsInText(mailTemplate.Subject, vars)
+	return newOutgoingMail(recipient, subject, body, "", language, attachments, organizationID)
 }
 
+// ReplaceVarsInTemplate replaces the variables in an HTML template, escaping
+// their values. Line breaks in values are kept.
 func ReplaceVarsInTemplate(body string, vars map[string]string) string {
+	return replaceVars(body, vars, func(s string) string {
+		return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+	})
+}
+
+// ReplaceVarsInText replaces the variables in a plain text template such as
+// a subject.
+func ReplaceVarsInText(body string, vars map[string]string) string {
+	return replaceVars(body, vars, func(s string) string { return s })
+}
+
+func replaceVars(body string, vars map[string]string, escape func(string) string) string {
 	for key, val := range vars {
-		rx := regexp.MustCompile(`{{if ` + key + `}}(.*?){{end}}`)
+		rx := regexp.MustCompile(`(?s){{if ` + key + `}}(.*?){{end}}`)
 		if val == "1" {
 			body = rx.ReplaceAllString(body, "$1")
 		} else {
@@ -300,7 +470,7 @@ func ReplaceVarsInTemplate(body string, vars map[string]string) string {
 		}
 	}
 	for key, val := range vars {
-		rx := regexp.MustCompile(`{{if \!` + key + `}}(.*?){{end}}`)
+		rx := regexp.MustCompile(`(?s){{if \!` + key + `}}(.*?){{end}}`)
 		if val != "1" {
 			body = rx.ReplaceAllString(body, "$1")
 		} else {
@@ -308,7 +478,7 @@ func ReplaceVarsInTemplate(body string, vars map[string]string) string {
 		}
 	}
 	for key, val := range vars {
-		body = strings.ReplaceAll(body, "{{"+key+"}}", html.EscapeString(val))
+		body = strings.ReplaceAll(body, "{{"+key+"}}", escape(val))
 	}
 	return body
 }
@@ -326,34 +496,74 @@ func SendEmailWithBodyAndAttachment(recipient *MailAddress, subject, body, langu
 }
 
 func SendEmailWithBodyAndAttachmentAndOrg(recipient *MailAddress, subject, body, language string, attachments []*MailAttachment, organizationID string) error {
+	m, err := newOutgoingMail(recipient, subject, body, "", language, attachments, organizationID)
+	if err != nil {
+		return err
+	}
+	return sendOutgoingMail(m)
+}
+
+func sendOutgoingMail(m *OutgoingMail) error {
+	if GetConfig().MockSendmail {
+		SendMailMockContent = m.Body
+		return nil
+	}
+	if emailQueueCallback != nil {
+		return emailQueueCallback(m)
+	}
+	if err := DeliverEmail(m); err != nil {
+		return err
+	}
+	// Log email to database if sending was successful
+	if emailLogCallback != nil {
+		if logErr := emailLogCallback(m.Subject, m.Recipient.Address, m.OrganizationID); logErr != nil {
+			log.Printf("Failed to log email to database: %v\n", logErr)
+		}
+	}
+	return nil
+}
+
+// newOutgoingMail validates the recipient and subject and renders the footer
+// into the body.
+func newOutgoingMail(recipient *MailAddress, subject, body, bodyText, language string, attachments []*MailAttachment, organizationID string) (*OutgoingMail, error) {
 	// Validate and sanitize recipient address
 	recipient.Address = SanitizeEmailAddress(recipient.Address)
 	if err := ValidateEmailAddress(recipient.Address); err != nil {
-		return fmt.Errorf("invalid recipient email address: %w", err)
+		return nil, fmt.Errorf("invalid recipient email address: %w", err)
 	}
 
 	// Validate and sanitize display name
 	recipient.DisplayName = SanitizeDisplayName(recipient.DisplayName)
 	if err := ValidateDisplayName(recipient.DisplayName); err != nil {
-		return fmt.Errorf("invalid recipient display name: %w", err)
+		return nil, fmt.Errorf("invalid recipient display name: %w", err)
 	}
 
 	// Validate and sanitize subject
 	subject = SanitizeEmailSubject(subject)
 	if err := ValidateEmailSubject(subject); err != nil {
-		return fmt.Errorf("invalid email subject: %w", err)
+		return nil, fmt.Errorf("invalid email subject: %w", err)
 	}
 
-	logoData, err := os.ReadFile(filepath.Join(GetConfig().FilesystemBasePath, "./res/seatsurfing.png"))
+	footer, footerText, err := GetEmailFooter(language)
 	if err != nil {
-		return fmt.Errorf("error reading logo file: %v", err)
+		return nil, err
 	}
-	attachments = append(attachments, &MailAttachment{
-		Filename:  "seatsurfing.png",
-		Data:      logoData,
-		MimeType:  "image/png",
-		ContentID: "seatsurfing-logo",
-	})
+	if bodyText != "" && footerText != "" {
+		bodyText += "\n\n-- \n" + footerText
+	}
+	return &OutgoingMail{
+		Recipient:      recipient,
+		Subject:        subject,
+		Body:           strings.ReplaceAll(body, "{{footer}}", footer),
+		BodyText:       bodyText,
+		Attachments:    attachments,
+		OrganizationID: organizationID,
+	}, nil
+}
+
+// GetEmailFooter returns the footer for the given language as HTML and as
+// plain text.
+func GetEmailFooter(language string) (string, string, error) {
 	var footerJSON []byte
 	if globalEmailFooterProvider != nil {
 		if dbFooter, err := globalEmailFooterProvider(language); err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -365,74 +575,145 @@ func SendEmailWithBodyAndAttachmentAndOrg(recipient *MailAddress, subject, body,
 	if footerJSON == nil {
 		footerFile, err := GetEmailTemplatePath(GetEmailTemplatePathFooter(), language)
 		if err != nil {
-			return fmt.Errorf("error getting footer template path: %v", err)
+			return "", "", fmt.Errorf("error getting footer template path: %v", err)
 		}
 		footerJSON, err = os.ReadFile(footerFile)
 		if err != nil {
-			return fmt.Errorf("error reading footer template file: %v", err)
+			return "", "", fmt.Errorf("error reading footer template file: %v", err)
 		}
 	}
 	var jsonFooter []string
 	if err := json.Unmarshal(footerJSON, &jsonFooter); err != nil {
-		return fmt.Errorf("error unmarshaling footer json: %v", err)
+		return "", "", fmt.Errorf("error unmarshaling footer json: %v", err)
 	}
 	footer := ""
 	for _, paragraph := range jsonFooter {
 		footer += "<p>" + html.EscapeString(paragraph) + "</p>"
 	}
-	body = strings.ReplaceAll(body, "{{footer}}", footer)
-	if GetConfig().MockSendmail {
-		SendMailMockContent = body
-		return nil
+	return footer, strings.Join(jsonFooter, "\n"), nil
+}
+
+// DeliverEmail sends a rendered message via the configured mail service.
+// The logo is attached on every attempt and not stored with the message.
+func DeliverEmail(m *OutgoingMail) error {
+	transport, err := getMailTransport(m.OrganizationID)
+	if err != nil {
+		return err
 	}
 	sender := &MailAddress{
-		Address:     GetConfig().MailSenderAddress,
-		DisplayName: "Seatsurfing",
+		Address:     transport.SenderAddress,
+		DisplayName: transport.SenderName,
+	}
+	if GetConfig().MockSendmail {
+		SendMailMockContent = m.Body
+		SendMailMockSender = sender
+		return SendMailMockError
+	}
+	logoData, err := os.ReadFile(filepath.Join(GetConfig().FilesystemBasePath, "./res/seatsurfing.png"))
+	if err != nil {
+		return fmt.Errorf("error reading logo file: %v", err)
 	}
-	if GetConfig().MailService == "acs" {
-		err = acsDialAndSend(recipient, sender, subject, "", body, attachments)
+	attachments := append(slices.Clone(m.Attachments), &MailAttachment{
+		Filename:  "seatsurfing.png",
+		Data:      logoData,
+		MimeType:  "image/png",
+		ContentID: "seatsurfing-logo",
+	})
+	recipient, subject, body := m.Recipient, m.Subject, m.Body
+	if transport.SMTP == nil {
+		return acsDialAndSend(recipient, sender, transport.ReplyTo, subject, m.BodyText, body, attachments)
+	}
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "From: %s\n", (&mail.Address{Name: sender.DisplayName, Address: sender.Address}).String())
+	fmt.Fprintf(buf, "To: %s\n", recipient.Address)
+	if transport.ReplyTo != "" {
+		fmt.Fprintf(buf, "Reply-To: %s\n", transport.ReplyTo)
+	}
+	fmt.Fprintf(buf, "Subject: %s\n", mime.QEncoding.Encode("UTF-8", subject))
+	buf.WriteString("MIME-Version: 1.0\n")
+
+	writer := multipart.NewWriter(buf)
+	boundary := writer.Boundary()
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=\"%s\"\n", boundary)
+
+	// Write body
+	fmt.Fprintf(buf, "\n--%s\n", boundary)
+	if m.BodyText != "" {
+		alternative := multipart.NewWriter(nil).Boundary()
+		fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=\"%s\"\n", alternative)
+		fmt.Fprintf(buf, "\n--%s\n", alternative)
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\n")
+		fmt.Fprintf(buf, "\n%s", mimeBase64([]byte(m.BodyText)))
+		fmt.Fprintf(buf, "--%s\n", alternative)
+		buf.WriteString("Content-Type: text/html; charset=utf-8\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\n")
+		fmt.Fprintf(buf, "\n%s", mimeBase64([]byte(body)))
+		fmt.Fprintf(buf, "--%s--\n", alternative)
 	} else {
-		buf := bytes.NewBuffer(nil)
-		fmt.Fprintf(buf, "From: %s\n", sender.DisplayName+" <"+sender.Address+">")
-		fmt.Fprintf(buf, "To: %s\n", recipient.Address)
-		fmt.Fprintf(buf, "Subject: %s\n", mime.QEncoding.Encode("UTF-8", subject))
-		buf.WriteString("MIME-Version: 1.0\n")
-
-		writer := multipart.NewWriter(buf)
-		boundary := writer.Boundary()
-		fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=\"%s\"\n", boundary)
-
-		// Write body
-		fmt.Fprintf(buf, "\n--%s\n", boundary)
 		buf.WriteString("Content-Type: text/html; charset=utf-8\n")
 		buf.WriteString("Content-Transfer-Encoding: base64\n")
 		fmt.Fprintf(buf, "\n%s", mimeBase64([]byte(body)))
+	}
 
-		// Write attachments
-		for _, attachment := range attachments {
-			fmt.Fprintf(buf, "--%s\n", boundary)
-			fmt.Fprintf(buf, "Content-Disposition: attachment; filename=\"%s\"\n", attachment.Filename)
-			fmt.Fprintf(buf, "Content-Type: %s\n", attachment.MimeType)
-			if attachment.ContentID != "" {
-				fmt.Fprintf(buf, "Content-ID: <%s>\n", attachment.ContentID)
-			}
-			buf.WriteString("Content-Transfer-Encoding: base64\n")
-			fmt.Fprintf(buf, "\n%s", mimeBase64(attachment.Data))
+	// Write attachments
+	for _, attachment := range attachments {
+		fmt.Fprintf(buf, "--%s\n", boundary)
+		fmt.Fprintf(buf, "Content-Disposition: attachment; filename=\"%s\"\n", attachment.Filename)
+		fmt.Fprintf(buf, "Content-Type: %s\n", attachment.MimeType)
+		if attachment.ContentID != "" {
+			fmt.Fprintf(buf, "Content-ID: <%s>\n", attachment.ContentID)
 		}
-		fmt.Fprintf(buf, "--%s--\n", boundary)
-
-		to := []string{recipient.Address}
-		err = smtpDialAndSend(sender.Address, to, buf.Bytes())
+		buf.WriteString("Content-Transfer-Encoding: base64\n")
+		fmt.Fprintf(buf, "\n%s", mimeBase64(attachment.Data))
 	}
+	fmt.Fprintf(buf, "--%s--\n", boundary)
 
-	// Log email to database if sending was successful
-	if err == nil && emailLogCallback != nil {
-		if logErr := emailLogCallback(subject, recipient.Address, organizationID); logErr != nil {
-			log.Printf("Failed to log email to database: %v\n", logErr)
+	to := []string{recipient.Address}
+	return smtpDialAndSend(transport.SMTP, sender.Address, to, buf.Bytes())
+}
+
+// getMailTransport returns the organization's mail transport or the global
+// one. Organizations without an own SMTP server may still set the sender
+// name and reply-to address.
+func getMailTransport(organizationID string) (*MailTransport, error) {
+	config := GetConfig()
+	transport := &MailTransport{
+		SenderAddress: config.MailSenderAddress,
+		SenderName:    "Seatsurfing",
+	}
+	if config.MailService != "acs" {
+		transport.SMTP = getOptimalSMTPSettings(config)
+	}
+	if organizationID == "" || orgMailTransportProvider == nil {
+		return transport, nil
+	}
+	orgTransport, err := orgMailTransportProvider(organizationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transport, nil
 		}
+		return nil, err
+	}
+	if orgTransport == nil {
+		return transport, nil
+	}
+	if orgTransport.SMTP != nil {
+		transport.SMTP = orgTransport.SMTP
+		transport.SenderAddress = orgTransport.SenderAddress
+	}
+	if orgTransport.SenderName != "" {
+		transport.SenderName = orgTransport.SenderName
 	}
+	transport.ReplyTo = orgTransport.ReplyTo
+	return transport, nil
+}
 
-	return err
+// IsPermanentEmailError returns true if retrying the delivery is pointless,
+// i.e. the SMTP server rejected the message with a 5xx reply.
+func IsPermanentEmailError(err error) bool {
+	var tpErr *textproto.Error
+	return errors.As(err, &tpErr) && tpErr.Code >= 500
 }
 
 // ValidateEmailAddress checks if an email address is valid and safe from header injection
@@ -588,7 +869,7 @@ func GetLocalPartFromEmailAddress(email string) string {
 	return email[:idx]
 }
 
-func acsDialAndSend(recipient, sender *MailAddress, subject, bodyPlainText, bodyHTML string, attachments []*MailAttachment) error {
+func acsDialAndSend(recipient, sender *MailAddress, replyTo, subject, bodyPlainText, bodyHTML string, attachments []*MailAttachment) error {
 	attachmentsList := []ACSAttachment{}
 	for _, attachment := range attachments {
 		attachmentsList = append(attachmentsList, ACSAttachment{
@@ -615,27 +896,25 @@ func acsDialAndSend(recipient, sender *MailAddress, subject, bodyPlainText, body
 		},
 		Attachments: attachmentsList,
 	}
+	if replyTo != "" {
+		mail.ReplyTo = []ACSAddress{{Address: replyTo}}
+	}
 	return ACSSendEmail(GetConfig().ACSHost, GetConfig().ACSAccessKey, mail)
 }
 
-func smtpDialAndSend(from string, to []string, msg []byte) error {
-	config := GetConfig()
-
-	// Get optimal settings for the SMTP server
-	port, startTLS, authMethod := getOptimalSMTPSettings(config)
-	addr := config.SMTPHost + ":" + strconv.Itoa(port)
-	c, err := smtp.Dial(addr)
+func smtpDialAndSend(settings *SMTPSettings, from string, to []string, msg []byte) error {
+	c, err := dialSMTP(settings)
 	if err != nil {
 		log.Println("Error dialing SMTP server:", err)
 		return err
 	}
 	defer c.Close()
 
-	if startTLS {
+	if settings.TLSMode == SMTPTLSModeStartTLS {
 		if ok, _ := c.Extension("STARTTLS"); ok {
 			tlsConfig := &tls.Config{
-				ServerName:         config.SMTPHost,
-				InsecureSkipVerify: config.SMTPInsecureSkipVerify,
+				ServerName:         settings.Host,
+				InsecureSkipVerify: settings.InsecureSkipVerify,
 			}
 			if err = c.StartTLS(tlsConfig); err != nil {
 				log.Println("Error starting TLS with SMTP server:", err)
@@ -648,18 +927,18 @@ func smtpDialAndSend(from string, to []string, msg []byte) error {
 		}
 	}
 
-	if config.SMTPAuth {
+	if settings.Auth {
 		var auth smtp.Auth
-		actualAuthMethod := strings.ToUpper(authMethod)
+		actualAuthMethod := strings.ToUpper(settings.AuthMethod)
 
 		switch actualAuthMethod {
 		case "LOGIN":
-			auth = NewLoginAuth(config.SMTPAuthUser, config.SMTPAuthPass)
+			auth = NewLoginAuth(settings.AuthUser, settings.AuthPass)
 		case "PLAIN", "":
-			auth = smtp.PlainAuth("", config.SMTPAuthUser, config.SMTPAuthPass, config.SMTPHost)
+			auth = smtp.PlainAuth("", settings.AuthUser, settings.AuthPass, settings.Host)
 		default:
-			log.Printf("Warning: Unknown SMTP auth method '%s', falling back to PLAIN", config.SMTPAuthMethod)
-			auth = smtp.PlainAuth("", config.SMTPAuthUser, config.SMTPAuthPass, config.SMTPHost)
+			log.Printf("Warning: Unknown SMTP auth method '%s', falling back to PLAIN", settings.AuthMethod)
+			auth = smtp.PlainAuth("", settings.AuthUser, settings.AuthPass, settings.Host)
 		}
 
 		if err = c.Auth(auth); err != nil {
@@ -668,7 +947,7 @@ func smtpDialAndSend(from string, to []string, msg []byte) error {
 			// For M365 compatibility, try LOGIN method if PLAIN fails
 			if actualAuthMethod == "PLAIN" {
 				log.Println("Retrying with LOGIN authentication method for M365 compatibility...")
-				loginAuth := NewLoginAuth(config.SMTPAuthUser, config.SMTPAuthPass)
+				loginAuth := NewLoginAuth(settings.AuthUser, settings.AuthPass)
 				if err = c.Auth(loginAuth); err != nil {
 					log.Println("Error authenticating with SMTP server using LOGIN method:", err)
 					return err
@@ -704,3 +983,41 @@ func smtpDialAndSend(from string, to []string, msg []byte) error {
 	}
 	return c.Quit()
 }
+
+// dialSMTP connects to the SMTP server, using TLS right away if configured.
+func dialSMTP(settings *SMTPSettings) (*smtp.Client, error) {
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+	if settings.PublicOnly {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !IsPublicIP(ip) {
+				return ErrMailHostNotAllowed
+			}
+			return nil
+		}
+	}
+	addr := net.JoinHostPort(settings.Host, strconv.Itoa(settings.Port))
+	var conn net.Conn
+	var err error
+	if settings.TLSMode == SMTPTLSModeImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+			ServerName:         settings.Host,
+			InsecureSkipVerify: settings.InsecureSkipVerify,
+		})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, err := smtp.NewClient(conn, settings.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,427 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+)
+
+const (
+	SiemProtocolUDP = "udp"
+	SiemProtocolTCP = "tcp"
+	SiemProtocolTLS = "tls"
+
+	SiemFormatRFC5424 = "rfc5424"
+	SiemFormatCEF     = "cef"
+)
+
+const (
+	SecurityEventCategoryAuth  = "auth"
+	SecurityEventCategoryAdmin = "admin"
+)
+
+const (
+	// Syslog facilities, see RFC 5424 section 6.2.1
+	syslogFacilityAuthPriv = 10
+	syslogFacilityLogAudit = 13
+	// Syslog severities, see RFC 5424 section 6.2.1
+	syslogSeverityWarning = 4
+	syslogSeverityNotice  = 5
+	syslogSeverityInfo    = 6
+	// SD-ID of the structured data element, 32473 is the example enterprise
+	// number reserved by RFC 5612
+	syslogSDID       = "seatsurfing@32473"
+	siemWriteTimeout = 5 * time.Second
+)
+
+// SecurityEvent is an authentication event or administrative action
+// exported to a SIEM.
+type SecurityEvent struct {
+	Timestamp      time.Time
+	Category       string
+	Action         string
+	Successful     bool
+	OrganizationID string
+	UserID         string
+	Email          string
+	SourceIP       string
+	Device         string
+	Target         string
+	ErrorCode      string
+	Detail         string
+}
+
+type SiemExporterConfig struct {
+	Address       string // host:port of the syslog receiver
+	Protocol      string // SiemProtocolUDP, SiemProtocolTCP or SiemProtocolTLS
+	Format        string // SiemFormatRFC5424 or SiemFormatCEF
+	TLSConfig     *tls.Config
+	QueueSize     int
+	MaxRetries    int
+	RetryInterval time.Duration
+}
+
+// SiemExporter sends security events to a syslog receiver. Events are queued
+// and sent by a background worker so that callers are never blocked by the
+// network. If the queue is full, new events are dropped. Failed sends are
+// retried with a new connection up to MaxRetries times.
+type SiemExporter struct {
+	config   *SiemExporterConfig
+	hostname string
+	queue    chan *SecurityEvent
+	done     chan struct{}
+	conn     net.Conn
+	dropped  atomic.Uint64
+	mu       sync.RWMutex
+	closed   bool
+}
+
+var siemExporter *SiemExporter
+var siemExporterOnce sync.Once
+
+// GetSiemExporter returns the exporter configured via the SIEM_* environment
+// variables or nil if no SIEM_SYSLOG_ADDRESS is set.
+func GetSiemExporter() *SiemExporter {
+	siemExporterOnce.Do(func() {
+		c := GetConfig()
+		if c.SiemSyslogAddress == "" {
+			return
+		}
+		cfg := &SiemExporterConfig{
+			Address:       c.SiemSyslogAddress,
+			Protocol:      c.SiemSyslogProtocol,
+			Format:        c.SiemFormat,
+			QueueSize:     c.SiemQueueSize,
+			MaxRetries:    5,
+			RetryInterval: 1 * time.Second,
+		}
+		if c.SiemSyslogProtocol == SiemProtocolTLS {
+			tlsConfig, err := getSiemTLSConfig(c)
+			if err != nil {
+				log.Println("Error: Could not initialize SIEM exporter: " + err.Error())
+				return
+			}
+			cfg.TLSConfig = tlsConfig
+		}
+		siemExporter = NewSiemExporter(cfg)
+		log.Println("Exporting security events to " + c.SiemSyslogProtocol + "://" + c.SiemSyslogAddress + " (" + c.SiemFormat + ")")
+	})
+	return siemExporter
+}
+
+func getSiemTLSConfig(c *Config) (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(c.SiemSyslogAddress)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: c.SiemTLSInsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if c.SiemTLSCACert != "" {
+		pem, err := os.ReadFile(c.SiemTLSCACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in " + c.SiemTLSCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// ExportSecurityEvent queues the event for the configured SIEM exporter, if any.
+func ExportSecurityEvent(e *SecurityEvent) {
+	if exporter := GetSiemExporter(); exporter != nil {
+		exporter.Export(e)
+	}
+}
+
+// CloseSiemExporter sends all queued events, waiting at most timeout.
+func CloseSiemExporter(timeout time.Duration) {
+	if exporter := GetSiemExporter(); exporter != nil {
+		exporter.Close(timeout)
+	}
+}
+
+func NewSiemExporter(config *SiemExporterConfig) *SiemExporter {
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	e := &SiemExporter{
+		config:   config,
+		hostname: hostname,
+		queue:    make(chan *SecurityEvent, config.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Export queues the event without blocking. Returns false if the event was
+// dropped because the queue is full or the exporter is closed.
+func (e *SiemExporter) Export(event *SecurityEvent) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return false
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	select {
+	case e.queue <- event:
+		return true
+	default:
+		if e.dropped.Add(1)%100 == 1 {
+			log.Println("Warning: SIEM export queue is full, dropping security events")
+		}
+		return false
+	}
+}
+
+// Dropped returns the number of events dropped so far.
+func (e *SiemExporter) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// Close stops accepting events and waits at most timeout for the queued
+// events to be sent.
+func (e *SiemExporter) Close(timeout time.Duration) {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+	select {
+	case <-e.done:
+	case <-time.After(timeout):
+		log.Println("Warning: Timeout while sending queued security events")
+	}
+}
+
+func (e *SiemExporter) run() {
+	defer close(e.done)
+	for event := range e.queue {
+		e.send(e.frame(e.format(event)))
+	}
+	if e.conn != nil {
+		e.conn.Close()
+	}
+}
+
+func (e *SiemExporter) send(msg []byte) {
+	var err error
+	for attempt := 0; attempt <= e.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(e.config.RetryInterval * time.Duration(attempt))
+		}
+		if e.conn == nil {
+			if e.conn, err = e.dial(); err != nil {
+				e.conn = nil
+				continue
+			}
+		}
+		e.conn.SetWriteDeadline(time.Now().Add(siemWriteTimeout))
+		if _, err = e.conn.Write(msg); err == nil {
+			return
+		}
+		e.conn.Close()
+		e.conn = nil
+	}
+	e.dropped.Add(1)
+	log.Println("Error sending security event to SIEM: " + err.Error())
+}
+
+func (e *SiemExporter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: siemWriteTimeout}
+	switch e.config.Protocol {
+	case SiemProtocolTLS:
+		return tls.DialWithDialer(dialer, "tcp", e.config.Address, e.config.TLSConfig)
+	case SiemProtocolTCP:
+		return dialer.Dial("tcp", e.config.Address)
+	default:
+		return dialer.Dial("udp", e.config.Address)
+	}
+}
+
+// frame prepares a message for the transport. Datagrams carry exactly one
+// message. On streams, RFC 5424 messages use octet counting (RFC 6587,
+// RFC 5425) while CEF messages are terminated by a newline as expected by
+// most CEF receivers.
+func (e *SiemExporter) frame(msg string) []byte {
+	if e.config.Protocol == SiemProtocolUDP || e.config.Protocol == "" {
+		return []byte(msg)
+	}
+	if e.config.Format == SiemFormatCEF {
+		return []byte(msg + "\n")
+	}
+	return []byte(strconv.Itoa(len(msg)) + " " + msg)
+}
+
+func (e *SiemExporter) format(event *SecurityEvent) string {
+	if e.config.Format == SiemFormatCEF {
+		return FormatSecurityEventCEF(event, e.hostname)
+	}
+	return FormatSecurityEventRFC5424(event, e.hostname)
+}
+
+func getSecurityEventSyslogPriority(event *SecurityEvent) int {
+	if event.Category == SecurityEventCategoryAdmin {
+		return syslogFacilityLogAudit*8 + syslogSeverityNotice
+	}
+	if !event.Successful {
+		return syslogFacilityAuthPriv*8 + syslogSeverityWarning
+	}
+	return syslogFacilityAuthPriv*8 + syslogSeverityInfo
+}
+
+func getSecurityEventOutcome(event *SecurityEvent) string {
+	if event.Successful {
+		return "success"
+	}
+	return "failure"
+}
+
+func getSecurityEventMessage(event *SecurityEvent) string {
+	msg := event.Category + " " + event.Action + " " + getSecurityEventOutcome(event)
+	if event.ErrorCode != "" {
+		msg += ": " + event.ErrorCode
+	}
+	if event.Detail != "" {
+		msg += " (" + event.Detail + ")"
+	}
+	return msg
+}
+
+// FormatSecurityEventRFC5424 formats the event as a syslog message according
+// to RFC 5424 with the event attributes as structured data.
+func FormatSecurityEventRFC5424(event *SecurityEvent, hostname string) string {
+	params := [][2]string{
+		{"action", event.Action},
+		{"outcome", getSecurityEventOutcome(event)},
+		{"organizationId", event.OrganizationID},
+		{"userId", event.UserID},
+		{"user", event.Email},
+		{"srcIp", event.SourceIP},
+		{"device", event.Device},
+		{"target", event.Target},
+		{"errorCode", event.ErrorCode},
+	}
+	var sd strings.Builder
+	sd.WriteString("[" + syslogSDID)
+	for _, p := range params {
+		if p[1] == "" {
+			continue
+		}
+		sd.WriteString(" " + p[0] + "=\"" + escapeSyslogParamValue(p[1]) + "\"")
+	}
+	sd.WriteString("]")
+	return fmt.Sprintf("<%d>1 %s %s seatsurfing %d %s %s %s",
+		getSecurityEventSyslogPriority(event),
+		event.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z"),
+		toSyslogHeaderField(hostname, 255),
+		os.Getpid(),
+		toSyslogHeaderField(event.Category, 32),
+		sd.String(),
+		getSecurityEventMessage(event))
+}
+
+// FormatSecurityEventCEF formats the event in ArcSight Common Event Format
+// with a BSD syslog header.
+func FormatSecurityEventCEF(event *SecurityEvent, hostname string) string {
+	severity := 3
+	name := "Authentication succeeded"
+	if event.Category == SecurityEventCategoryAdmin {
+		severity = 5
+		name = "Administrative action"
+	} else if !event.Successful {
+		severity = 6
+		name = "Authentication failed"
+	}
+	ext := [][2]string{
+		{"rt", strconv.FormatInt(event.Timestamp.UnixMilli(), 10)},
+		{"act", event.Action},
+		{"outcome", getSecurityEventOutcome(event)},
+		{"suid", event.UserID},
+		{"suser", event.Email},
+		{"src", event.SourceIP},
+		{"requestClientApplication", event.Device},
+		{"reason", event.ErrorCode},
+		{"cs1", event.OrganizationID},
+		{"cs2", event.Target},
+		{"msg", event.Detail},
+	}
+	labels := map[string]string{
+		"cs1": "organizationId",
+		"cs2": "target",
+	}
+	extensions := []string{}
+	for _, e := range ext {
+		if e[1] == "" {
+			continue
+		}
+		if label, ok := labels[e[0]]; ok {
+			extensions = append(extensions, e[0]+"Label="+label)
+		}
+		extensions = append(extensions, e[0]+"="+escapeCEFExtensionValue(e[1]))
+	}
+	return fmt.Sprintf("<%d>%s %s CEF:0|Seatsurfing|Seatsurfing|%s|%s|%s|%d|%s",
+		getSecurityEventSyslogPriority(event),
+		event.Timestamp.UTC().Format(time.Stamp),
+		toSyslogHeaderField(hostname, 255),
+		escapeCEFHeaderValue(GetProductVersion()),
+		escapeCEFHeaderValue(event.Category+":"+event.Action),
+		escapeCEFHeaderValue(name),
+		severity,
+		strings.Join(extensions, " "))
+}
+
+// toSyslogHeaderField restricts s to printable US-ASCII without spaces as
+// required for syslog header fields.
+func toSyslogHeaderField(s string, maxLength int) string {
+	res := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(res) > maxLength {
+		res = res[:maxLength]
+	}
+	if res == "" {
+		return "-"
+	}
+	return res
+}
+
+func escapeSyslogParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+func escapeCEFHeaderValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ").Replace(s)
+}
+
+func escapeCEFExtensionValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,29 @@
+package test
+
+import (
+	"testing"
+
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func TestActionLinkToken(t *testing.T) {
+	token := GetActionLinkToken("a4c2a1e6-5b0e-4c7e-9a54-5a8f0b4b2f11")
+	id, ok := ParseActionLinkToken(token)
+	CheckTestBool(t, true, ok)
+	CheckTestString(t, "a4c2a1e6-5b0e-4c7e-9a54-5a8f0b4b2f11", id)
+}
+
+func TestActionLinkTokenInvalid(t *testing.T) {
+	token := GetActionLinkToken("a4c2a1e6-5b0e-4c7e-9a54-5a8f0b4b2f11")
+	for _, s := range []string{
+		"",
+		"a4c2a1e6-5b0e-4c7e-9a54-5a8f0b4b2f11",
+		"a4c2a1e6-5b0e-4c7e-9a54-5a8f0b4b2f11.",
+		"a4c2a1e6-5b0e-4c7e-9a54-5a8f0b4b2f12" + token[36:],
+		token + "x",
+	} {
+		_, ok := ParseActionLinkToken(s)
+		CheckTestBool(t, false, ok)
+	}
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func TestIsPasswordBreached(t *testing.T) {
+	dir := t.TempDir()
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	content := "003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004\r\n"
+	os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(content), 0o600)
+	oldPath := GetConfig().BreachedPasswordsPath
+	GetConfig().BreachedPasswordsPath = dir
+	defer func() { GetConfig().BreachedPasswordsPath = oldPath }()
+
+	breached, err := IsPasswordBreached("password")
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, breached)
+
+	// No range file exists for the prefix of this password
+	breached, err = IsPasswordBreached("Sea!surf1ng")
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, breached)
+}
+
+func TestIsPasswordBreachedNotConfigured(t *testing.T) {
+	oldPath := GetConfig().BreachedPasswordsPath
+	GetConfig().BreachedPasswordsPath = ""
+	defer func() { GetConfig().BreachedPasswordsPath = oldPath }()
+
+	breached, err := IsPasswordBreached("password")
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, breached)
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func TestGetEmailTemplateName(t *testing.T) {
+	CheckTestString(t, "booking-created", GetEmailTemplateName(GetEmailTemplatePathBookingCreated()))
+	CheckTestString(t, "resetpw", GetEmailTemplateName(GetEmailTemplatePathResetpassword()))
+	CheckTestBool(t, true, GetEmailTemplateDefinition("booking-created") != nil)
+	CheckTestBool(t, true, GetEmailTemplateDefinition("footer") == nil)
+}
+
+func TestEmailTemplateDefinitionsHaveBuiltinTemplates(t *testing.T) {
+	for _, def := range EmailTemplateDefinitions {
+		for _, language := range []string{"en", "de"} {
+			builtin, err := GetBuiltinEmailTemplate(def, language)
+			CheckTestIsNil(t, err)
+			CheckStringNotEmpty(t, builtin.Subject)
+			CheckStringNotEmpty(t, builtin.BodyHTML)
+			CheckTestIsNil(t, def.Validate(builtin))
+		}
+	}
+}
+
+func TestEmailTemplateValidate(t *testing.T) {
+	def := GetEmailTemplateDefinition("invite-user")
+	tpl := &CustomEmailTemplate{
+		Subject:  "Welcome {{recipientName}}",
+		BodyHTML: "<a href=\"{{orgDomain}}ui/setpw/{{confirmID}}/\">Set password</a>",
+	}
+	CheckTestIsNil(t, def.Validate(tpl))
+
+	tpl.BodyText = "Set your password"
+	CheckTestBool(t, true, def.Validate(tpl) == ErrEmailTemplateMissingVariable)
+	tpl.BodyText = "Set your password: {{orgDomain}}ui/setpw/{{confirmID}}/"
+	CheckTestIsNil(t, def.Validate(tpl))
+
+	tpl.Headline = "Hello {{userEmail}}"
+	CheckTestBool(t, true, def.Validate(tpl) == ErrEmailTemplateUnknownVariable)
+	tpl.Headline = ""
+
+	tpl.BodyHTML = "<p>Hello</p>"
+	CheckTestBool(t, true, def.Validate(tpl) == ErrEmailTemplateMissingVariable)
+}
+
+func TestRenderCustomEmailTemplate(t *testing.T) {
+	tpl := &CustomEmailTemplate{
+		Subject:  "Booking for {{spaceName}}",
+		Headline: "Hi {{recipientName}}",
+		BodyHTML: "<p>{{if created}}\nBooked {{spaceName}}\n{{end}}</p>",
+		BodyText: "Booked {{spaceName}}",
+	}
+	vars := map[string]string{
+		"recipientName": "John <Test>",
+		"spaceName":     "Desk <1>",
+		"created":       "1",
+	}
+	subject, body, bodyText, err := RenderCustomEmailTemplate(tpl, vars)
+	CheckTestIsNil(t, err)
+	CheckTestString(t, "Booking for Desk <1>", subject)
+	CheckTestString(t, "Booked Desk <1>", bodyText)
+	CheckTestBool(t, true, strings.Contains(body, "<h2>Hi John &lt;Test&gt;</h2>"))
+	CheckTestBool(t, true, strings.Contains(body, "<p>\nBooked Desk &lt;1&gt;\n</p>"))
+	CheckTestBool(t, true, strings.Contains(body, "{{footer}}"))
+
+	tpl.Headline = ""
+	_, body, _, err = RenderCustomEmailTemplate(tpl, vars)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, false, strings.Contains(body, "<h2>"))
+}
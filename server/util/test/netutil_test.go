@@ -20,3 +20,84 @@ func TestNetutilHttpClientCustomResolver(t *testing.T) {
 	CheckTestBool(t, true, err == nil)
 	CheckTestInt(t, 200, res.StatusCode)
 }
+
+func TestGetClientIPRemoteAddr(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	CheckTestString(t, "192.0.2.10", GetClientIP(req))
+}
+
+func setTestTrustedProxies(t *testing.T, value string) {
+	trustedProxies := GetConfig().TrustedProxies
+	t.Cleanup(func() { GetConfig().TrustedProxies = trustedProxies })
+	networks, err := ParseNetworkList(value)
+	CheckTestBool(t, true, err == nil)
+	GetConfig().TrustedProxies = networks
+}
+
+func TestGetClientIPForwardedFor(t *testing.T) {
+	setTestTrustedProxies(t, "10.0.0.0/8")
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.5, 198.51.100.7, 10.0.0.2")
+	CheckTestString(t, "198.51.100.7", GetClientIP(req))
+}
+
+func TestGetClientIPForwardedForUntrustedProxy(t *testing.T) {
+	setTestTrustedProxies(t, "10.0.0.0/8")
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	req.Header.Set("X-Real-IP", "198.51.100.7")
+	CheckTestString(t, "192.0.2.10", GetClientIP(req))
+}
+
+func TestGetClientIPForwardedForNoTrustedProxies(t *testing.T) {
+	setTestTrustedProxies(t, "")
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	CheckTestString(t, "10.0.0.1", GetClientIP(req))
+}
+
+func TestGetClientIPRealIP(t *testing.T) {
+	setTestTrustedProxies(t, "10.0.0.0/8")
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	req.Header.Set("X-Real-IP", "2001:db8::1")
+	CheckTestString(t, "2001:db8::1", GetClientIP(req))
+}
+
+func TestGetClientIPInvalidHeader(t *testing.T) {
+	setTestTrustedProxies(t, "10.0.0.0/8")
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	req.Header.Set("X-Forwarded-For", "not-an-ip")
+	CheckTestString(t, "10.0.0.1", GetClientIP(req))
+}
+
+func TestParseNetworkList(t *testing.T) {
+	networks, err := ParseNetworkList("192.0.2.0/24, 198.51.100.7\n2001:db8::/32")
+	CheckTestBool(t, true, err == nil)
+	CheckTestInt(t, 3, len(networks))
+	CheckTestBool(t, true, IsIPInNetworks("192.0.2.99", networks))
+	CheckTestBool(t, true, IsIPInNetworks("198.51.100.7", networks))
+	CheckTestBool(t, false, IsIPInNetworks("198.51.100.8", networks))
+	CheckTestBool(t, true, IsIPInNetworks("2001:db8::1", networks))
+	CheckTestBool(t, false, IsIPInNetworks("not-an-ip", networks))
+
+	networks, err = ParseNetworkList("")
+	CheckTestBool(t, true, err == nil)
+	CheckTestInt(t, 0, len(networks))
+
+	_, err = ParseNetworkList("192.0.2.0/24, 192.0.2.300")
+	CheckTestBool(t, false, err == nil)
+	_, err = ParseNetworkList("192.0.2.0/33")
+	CheckTestBool(t, false, err == nil)
+}
+
+func TestGetIPNetwork(t *testing.T) {
+	CheckTestString(t, "192.0.2.0/24", GetIPNetwork("192.0.2.99"))
+	CheckTestString(t, "2001:db8:1::/48", GetIPNetwork("2001:db8:1:2::1"))
+	CheckTestString(t, "", GetIPNetwork("not-an-ip"))
+}
@@ -1,6 +1,9 @@
 package test
 
 import (
+	"errors"
+	"fmt"
+	"net/textproto"
 	"path/filepath"
 	"testing"
 
@@ -72,3 +75,10 @@ func TestReplaceVarsInTemplateConditions(t *testing.T) {
 	expected = "Hello John, you don't have a code."
 	CheckTestString(t, expected, result)
 }
+
+func TestIsPermanentEmailError(t *testing.T) {
+	CheckTestBool(t, true, IsPermanentEmailError(&textproto.Error{Code: 550, Msg: "mailbox unavailable"}))
+	CheckTestBool(t, true, IsPermanentEmailError(fmt.Errorf("rcpt: %w", &textproto.Error{Code: 553, Msg: "invalid"})))
+	CheckTestBool(t, false, IsPermanentEmailError(&textproto.Error{Code: 451, Msg: "try again later"}))
+	CheckTestBool(t, false, IsPermanentEmailError(errors.New("connection refused")))
+}
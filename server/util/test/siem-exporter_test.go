@@ -0,0 +1,240 @@
+package test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func getTestSecurityEvent() *SecurityEvent {
+	return &SecurityEvent{
+		Timestamp:      time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC),
+		Category:       SecurityEventCategoryAuth,
+		Action:         "password",
+		Successful:     false,
+		OrganizationID: "6b9a4c44-6d0c-4c8f-9fd1-1f3b4b8d2b3a",
+		Email:          "foo@test.com",
+		SourceIP:       "192.0.2.10",
+		Device:         "Firefox on Linux",
+		ErrorCode:      "invalid_password",
+		Detail:         `quote " and bracket ]`,
+	}
+}
+
+func readTestSyslogStream(t *testing.T, l net.Listener) *bufio.Reader {
+	l.(interface{ SetDeadline(time.Time) error }).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return bufio.NewReader(conn)
+}
+
+func TestFormatSecurityEventRFC5424(t *testing.T) {
+	msg := FormatSecurityEventRFC5424(getTestSecurityEvent(), "host1")
+	CheckTestBool(t, true, strings.HasPrefix(msg, "<84>1 2024-03-01T12:30:15.000Z host1 seatsurfing "))
+	CheckTestBool(t, true, strings.Contains(msg, ` auth [seatsurfing@32473 action="password" outcome="failure" organizationId="6b9a4c44-6d0c-4c8f-9fd1-1f3b4b8d2b3a" user="foo@test.com" srcIp="192.0.2.10" device="Firefox on Linux" errorCode="invalid_password"] `))
+	CheckTestBool(t, true, strings.HasSuffix(msg, ` auth password failure: invalid_password (quote " and bracket ])`))
+	CheckTestBool(t, false, strings.Contains(msg, "userId="))
+
+	e := getTestSecurityEvent()
+	e.Category = SecurityEventCategoryAdmin
+	e.Action = "setting_updated"
+	e.Successful = true
+	e.ErrorCode = ""
+	e.Target = `a"b]c\d`
+	msg = FormatSecurityEventRFC5424(e, "host with spaces")
+	CheckTestBool(t, true, strings.HasPrefix(msg, "<109>1 2024-03-01T12:30:15.000Z hostwithspaces seatsurfing "))
+	CheckTestBool(t, true, strings.Contains(msg, ` target="a\"b\]c\\d"`))
+}
+
+func TestFormatSecurityEventCEF(t *testing.T) {
+	e := getTestSecurityEvent()
+	e.Detail = "a=b|c\nd"
+	msg := FormatSecurityEventCEF(e, "host1")
+	CheckTestBool(t, true, strings.HasPrefix(msg, "<84>Mar  1 12:30:15 host1 CEF:0|Seatsurfing|Seatsurfing|"))
+	CheckTestBool(t, true, strings.Contains(msg, "|auth:password|Authentication failed|6|rt=1709296215000 act=password outcome=failure suser=foo@test.com src=192.0.2.10 requestClientApplication=Firefox on Linux reason=invalid_password cs1Label=organizationId cs1=6b9a4c44-6d0c-4c8f-9fd1-1f3b4b8d2b3a msg=a\\=b|c\\nd"))
+}
+
+func TestSiemExporterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	exporter := NewSiemExporter(&SiemExporterConfig{
+		Address:   conn.LocalAddr().String(),
+		Protocol:  SiemProtocolUDP,
+		Format:    SiemFormatRFC5424,
+		QueueSize: 10,
+	})
+	defer exporter.Close(time.Second)
+
+	CheckTestBool(t, true, exporter.Export(getTestSecurityEvent()))
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, strings.HasPrefix(string(buf[:n]), "<84>1 2024-03-01T12:30:15.000Z "))
+}
+
+func TestSiemExporterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	exporter := NewSiemExporter(&SiemExporterConfig{
+		Address:   l.Addr().String(),
+		Protocol:  SiemProtocolTCP,
+		Format:    SiemFormatCEF,
+		QueueSize: 10,
+	})
+	exporter.Export(getTestSecurityEvent())
+	e := getTestSecurityEvent()
+	e.Successful = true
+	e.ErrorCode = ""
+	exporter.Export(e)
+	exporter.Close(5 * time.Second)
+
+	reader := readTestSyslogStream(t, l)
+	line, err := reader.ReadString('\n')
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, strings.Contains(line, "|Authentication failed|6|"))
+	line, err = reader.ReadString('\n')
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, strings.Contains(line, "|Authentication succeeded|3|"))
+	CheckTestUint(t, 0, uint(exporter.Dropped()))
+}
+
+func TestSiemExporterTLS(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	exporter := NewSiemExporter(&SiemExporterConfig{
+		Address:   l.Addr().String(),
+		Protocol:  SiemProtocolTLS,
+		Format:    SiemFormatRFC5424,
+		TLSConfig: &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"},
+		QueueSize: 10,
+	})
+	defer exporter.Close(time.Second)
+	exporter.Export(getTestSecurityEvent())
+
+	// Messages are framed using octet counting
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	length, err := reader.ReadString(' ')
+	CheckTestIsNil(t, err)
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	CheckTestIsNil(t, err)
+	buf := make([]byte, n)
+	_, err = io.ReadFull(reader, buf)
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, strings.HasPrefix(string(buf), "<84>1 "))
+	CheckTestBool(t, true, strings.HasSuffix(string(buf), "bracket ])"))
+}
+
+func TestSiemExporterRetry(t *testing.T) {
+	// Reserve a port, then close it so that the first attempts fail
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	l.Close()
+	exporter := NewSiemExporter(&SiemExporterConfig{
+		Address:       address,
+		Protocol:      SiemProtocolTCP,
+		Format:        SiemFormatCEF,
+		QueueSize:     10,
+		MaxRetries:    10,
+		RetryInterval: 50 * time.Millisecond,
+	})
+	exporter.Export(getTestSecurityEvent())
+	time.Sleep(100 * time.Millisecond)
+	l, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	reader := readTestSyslogStream(t, l)
+	line, err := reader.ReadString('\n')
+	CheckTestIsNil(t, err)
+	CheckTestBool(t, true, strings.Contains(line, "CEF:0|"))
+	exporter.Close(time.Second)
+	CheckTestUint(t, 0, uint(exporter.Dropped()))
+}
+
+func TestSiemExporterQueueFull(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	l.Close()
+	exporter := NewSiemExporter(&SiemExporterConfig{
+		Address:       address,
+		Protocol:      SiemProtocolTCP,
+		Format:        SiemFormatRFC5424,
+		QueueSize:     2,
+		MaxRetries:    1,
+		RetryInterval: 100 * time.Millisecond,
+	})
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		exporter.Export(getTestSecurityEvent())
+	}
+	// Exporting must never block the caller
+	CheckTestBool(t, true, time.Since(start) < 100*time.Millisecond)
+	CheckTestBool(t, true, exporter.Dropped() >= 7)
+	exporter.Close(5 * time.Second)
+	CheckTestBool(t, false, exporter.Export(getTestSecurityEvent()))
+	CheckTestUint(t, 10, uint(exporter.Dropped()))
+}
@@ -190,3 +190,12 @@ func TestInvalidWeekdaysLists(t *testing.T) {
 		CheckTestBool(t, IsValidWeekdaysList(input), false)
 	}
 }
+
+func TestValidatePasswordStrength(t *testing.T) {
+	CheckTestBool(t, true, ValidatePasswordStrength("alllowercase", 8, 1))
+	CheckTestBool(t, false, ValidatePasswordStrength("alllowercase", 8, 2))
+	CheckTestBool(t, true, ValidatePasswordStrength("lowercase123", 8, 2))
+	CheckTestBool(t, false, ValidatePasswordStrength("Sea!surf1ng", 12, 4))
+	CheckTestBool(t, true, ValidatePasswordStrength("Sea!surf1ng!!", 12, 4))
+	CheckTestBool(t, false, ValidatePasswordStrength(strings.Repeat("aA1!", 17), 8, 4))
+}
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+type webPushTestUserAgent struct {
+	key        *ecdh.PrivateKey
+	authSecret []byte
+}
+
+func newWebPushTestUserAgent(t *testing.T) *webPushTestUserAgent {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	CheckTestIsNil(t, err)
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+	return &webPushTestUserAgent{key: key, authSecret: authSecret}
+}
+
+func (ua *webPushTestUserAgent) subscription(endpoint string) *WebPushSubscription {
+	return &WebPushSubscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(ua.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(ua.authSecret),
+	}
+}
+
+func getWebPushTestVapidKey(t *testing.T) *ecdsa.PrivateKey {
+	s, err := GenerateVapidPrivateKey()
+	CheckTestIsNil(t, err)
+	key, err := ParseVapidPrivateKey(s)
+	CheckTestIsNil(t, err)
+	return key
+}
+
+func TestWebPushDecryptRFC8291Example(t *testing.T) {
+	// Test vector from RFC 8291, Appendix A
+	uaPrivate, _ := base64.RawURLEncoding.DecodeString("q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94")
+	authSecret, _ := base64.RawURLEncoding.DecodeString("BTBZMqHH6r4Tts7J_aSIgg")
+	content, _ := base64.RawURLEncoding.DecodeString("DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+	key, err := ecdh.P256().NewPrivateKey(uaPrivate)
+	CheckTestIsNil(t, err)
+	plaintext, err := DecryptWebPushPayload(content, key, authSecret)
+	CheckTestIsNil(t, err)
+	CheckTestString(t, "When I grow up, I want to be a watermelon", string(plaintext))
+}
+
+func TestWebPushEncryptRoundTrip(t *testing.T) {
+	ua := newWebPushTestUserAgent(t)
+	content, err := EncryptWebPushPayload([]byte("Hello"), ua.key.PublicKey().Bytes(), ua.authSecret)
+	CheckTestIsNil(t, err)
+	// salt (16) + rs (4) + idlen (1) + key (65) + "Hello" + delimiter + tag (16)
+	CheckTestInt(t, 86+5+1+16, len(content))
+	CheckTestBool(t, true, bytes.Equal([]byte{0, 0, 0x10, 0, 65}, content[16:21]))
+	plaintext, err := DecryptWebPushPayload(content, ua.key, ua.authSecret)
+	CheckTestIsNil(t, err)
+	CheckTestString(t, "Hello", string(plaintext))
+
+	// A different authentication secret must not decrypt the message
+	other := newWebPushTestUserAgent(t)
+	_, err = DecryptWebPushPayload(content, ua.key, other.authSecret)
+	CheckTestBool(t, true, err != nil)
+
+	_, err = EncryptWebPushPayload(make([]byte, 4000), ua.key.PublicKey().Bytes(), ua.authSecret)
+	CheckTestBool(t, true, err == ErrWebPushPayloadTooLarge)
+}
+
+func TestWebPushValidateKeys(t *testing.T) {
+	ua := newWebPushTestUserAgent(t)
+	sub := ua.subscription("https://push.test.com/abc")
+	CheckTestBool(t, true, ValidateWebPushKeys(sub.P256dh, sub.Auth))
+	CheckTestBool(t, true, ValidateWebPushKeys(sub.P256dh+"=", sub.Auth+"=="))
+	CheckTestBool(t, false, ValidateWebPushKeys(sub.P256dh, "abc"))
+	CheckTestBool(t, false, ValidateWebPushKeys(sub.Auth, sub.Auth))
+	CheckTestBool(t, false, ValidateWebPushKeys("B"+strings.Repeat("A", 86), sub.Auth))
+}
+
+func TestWebPushVapidKey(t *testing.T) {
+	s, err := GenerateVapidPrivateKey()
+	CheckTestIsNil(t, err)
+	key, err := ParseVapidPrivateKey(s)
+	CheckTestIsNil(t, err)
+	publicKey, err := GetVapidPublicKey(key)
+	CheckTestIsNil(t, err)
+	b, err := base64.RawURLEncoding.DecodeString(publicKey)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, 65, len(b))
+	CheckTestInt(t, 4, int(b[0]))
+
+	_, err = ParseVapidPrivateKey("invalid")
+	CheckTestBool(t, true, err != nil)
+}
+
+func TestWebPushChannelSend(t *testing.T) {
+	ua := newWebPushTestUserAgent(t)
+	vapidKey := getWebPushTestVapidKey(t)
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	defer func(allow bool) { GetConfig().WebhookAllowPrivateNetworks = allow }(GetConfig().WebhookAllowPrivateNetworks)
+	GetConfig().WebhookAllowPrivateNetworks = true
+
+	channel := &WebPushChannel{
+		Subscription: ua.subscription(server.URL + "/push/abc"),
+		VapidKey:     vapidKey,
+		Subject:      "mailto:admin@test.com",
+		TTL:          60,
+	}
+	CheckTestIsNil(t, channel.Send(getChatTestMessage()))
+
+	CheckTestString(t, "aes128gcm", header.Get("Content-Encoding"))
+	CheckTestString(t, "60", header.Get("TTL"))
+	plaintext, err := DecryptWebPushPayload(body, ua.key, ua.authSecret)
+	CheckTestIsNil(t, err)
+	var m WebPushMessage
+	CheckTestIsNil(t, json.Unmarshal(plaintext, &m))
+	CheckTestString(t, "Your booking", m.Title)
+	CheckTestString(t, "Space: Desk <1>\nArea: First floor", m.Body)
+	CheckTestString(t, "https://test.com/ui/bookings/", m.URL)
+
+	// Verify the VAPID token with the public key sent along
+	authorization := header.Get("Authorization")
+	CheckTestBool(t, true, strings.HasPrefix(authorization, "vapid t="))
+	parts := strings.Split(strings.TrimPrefix(authorization, "vapid t="), ", k=")
+	CheckTestInt(t, 2, len(parts))
+	publicKey, _ := GetVapidPublicKey(vapidKey)
+	CheckTestString(t, publicKey, parts[1])
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(parts[0], claims, func(token *jwt.Token) (any, error) {
+		return &vapidKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	CheckTestIsNil(t, err)
+	CheckTestString(t, server.URL, claims["aud"].(string))
+	CheckTestString(t, "mailto:admin@test.com", claims["sub"].(string))
+}
+
+func TestWebPushChannelGone(t *testing.T) {
+	ua := newWebPushTestUserAgent(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+	defer func(allow bool) { GetConfig().WebhookAllowPrivateNetworks = allow }(GetConfig().WebhookAllowPrivateNetworks)
+	GetConfig().WebhookAllowPrivateNetworks = true
+
+	channel := &WebPushChannel{
+		Subscription: ua.subscription(server.URL),
+		VapidKey:     getWebPushTestVapidKey(t),
+		Subject:      "mailto:admin@test.com",
+	}
+	err := channel.Send(getChatTestMessage())
+	CheckTestBool(t, true, err == ErrWebPushSubscriptionGone)
+}
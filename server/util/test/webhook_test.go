@@ -0,0 +1,72 @@
+package test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+	. "github.com/seatsurfing/seatsurfing/server/testutil"
+	. "github.com/seatsurfing/seatsurfing/server/util"
+)
+
+func TestWebhookSignatureRoundtrip(t *testing.T) {
+	payload := []byte(`{"event":"booking.created"}`)
+	header := SignWebhookPayload("whsec_test", time.Now(), payload)
+	CheckTestBool(t, true, strings.HasPrefix(header, "t="))
+	CheckTestBool(t, true, strings.Contains(header, ",v1="))
+	CheckTestIsNil(t, VerifyWebhookSignature("whsec_test", header, payload, 5*time.Minute))
+}
+
+func TestWebhookSignatureInvalid(t *testing.T) {
+	payload := []byte(`{"event":"booking.created"}`)
+	header := SignWebhookPayload("whsec_test", time.Now(), payload)
+	CheckTestBool(t, true, VerifyWebhookSignature("whsec_other", header, payload, 5*time.Minute) == ErrWebhookSignatureInvalid)
+	CheckTestBool(t, true, VerifyWebhookSignature("whsec_test", header, []byte(`{}`), 5*time.Minute) == ErrWebhookSignatureInvalid)
+	CheckTestBool(t, true, VerifyWebhookSignature("whsec_test", "v1=abc", payload, 0) == ErrWebhookSignatureInvalid)
+	CheckTestBool(t, true, VerifyWebhookSignature("whsec_test", "", payload, 0) == ErrWebhookSignatureInvalid)
+}
+
+func TestWebhookSignatureExpired(t *testing.T) {
+	payload := []byte(`{"event":"booking.created"}`)
+	header := SignWebhookPayload("whsec_test", time.Now().Add(-10*time.Minute), payload)
+	CheckTestBool(t, true, VerifyWebhookSignature("whsec_test", header, payload, 5*time.Minute) == ErrWebhookSignatureExpired)
+	CheckTestIsNil(t, VerifyWebhookSignature("whsec_test", header, payload, 0))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	CheckTestBool(t, true, IsPublicIP(net.ParseIP("8.8.8.8")))
+	CheckTestBool(t, true, IsPublicIP(net.ParseIP("2001:4860:4860::8888")))
+	CheckTestBool(t, false, IsPublicIP(net.ParseIP("127.0.0.1")))
+	CheckTestBool(t, false, IsPublicIP(net.ParseIP("10.1.2.3")))
+	CheckTestBool(t, false, IsPublicIP(net.ParseIP("192.168.0.1")))
+	CheckTestBool(t, false, IsPublicIP(net.ParseIP("169.254.169.254")))
+	CheckTestBool(t, false, IsPublicIP(net.ParseIP("::1")))
+	CheckTestBool(t, false, IsPublicIP(net.ParseIP("0.0.0.0")))
+}
+
+func TestWebhookHTTPClientPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	defer func(allow bool) { GetConfig().WebhookAllowPrivateNetworks = allow }(GetConfig().WebhookAllowPrivateNetworks)
+
+	GetConfig().WebhookAllowPrivateNetworks = false
+	_, err := GetWebhookHTTPClient().Post(server.URL, "application/json", nil)
+	CheckTestBool(t, true, err != nil && strings.Contains(err.Error(), ErrWebhookAddressNotAllowed.Error()))
+
+	GetConfig().WebhookAllowPrivateNetworks = true
+	res, err := GetWebhookHTTPClient().Post(server.URL, "application/json", nil)
+	CheckTestIsNil(t, err)
+	CheckTestInt(t, http.StatusNoContent, res.StatusCode)
+	res.Body.Close()
+
+	// Clients are reused per setting
+	CheckTestBool(t, true, GetWebhookHTTPClient() == GetWebhookHTTPClient())
+	GetConfig().WebhookAllowPrivateNetworks = false
+	CheckTestBool(t, true, GetWebhookHTTPClient() == GetWebhookHTTPClient())
+}
@@ -25,8 +25,15 @@ func ValidateEmail(s string) bool {
 }
 
 func ValidatePassword(s string) bool {
+	return ValidatePasswordStrength(s, 8, 4)
+}
+
+// ValidatePasswordStrength checks that the password has at least minLength
+// characters and contains at least minCharClasses of the character classes
+// upper case, lower case, digits and special characters.
+func ValidatePasswordStrength(s string, minLength, minCharClasses int) bool {
 	l := len([]rune(s))
-	if l < 8 || l > 64 {
+	if l < minLength || l > 64 {
 		return false
 	}
 	var hasUpper, hasLower, hasDigit, hasSpecial bool
@@ -42,7 +49,13 @@ func ValidatePassword(s string) bool {
 			hasSpecial = true
 		}
 	}
-	return hasUpper && hasLower && hasDigit && hasSpecial
+	numCharClasses := 0
+	for _, b := range []bool{hasUpper, hasLower, hasDigit, hasSpecial} {
+		if b {
+			numCharClasses++
+		}
+	}
+	return numCharClasses >= minCharClasses
 }
 
 func ValidateURL(s string) bool {
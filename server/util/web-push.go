@@ -0,0 +1,321 @@
+package util
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	webPushRecordSize  = 4096
+	webPushDefaultTTL  = 24 * 60 * 60
+	webPushAuthLength  = 16
+	webPushKeyLength   = 65
+	webPushVapidExpiry = 12 * time.Hour
+	// A push message including the 86 byte header must not exceed 4096 bytes
+	webPushMaxBodyBytes = webPushRecordSize - 86 - 16 - 1
+)
+
+var (
+	// ErrWebPushSubscriptionGone is returned if the push service reports that
+	// the subscription has expired or has been removed by the user.
+	ErrWebPushSubscriptionGone = errors.New("push subscription is no longer valid")
+	ErrWebPushPayloadTooLarge  = errors.New("push message payload too large")
+	ErrWebPushInvalidKey       = errors.New("invalid push subscription key")
+)
+
+// WebPushSubscription is a push subscription as returned by the browser's
+// PushManager. The keys are base64url encoded.
+type WebPushSubscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// WebPushMessage is the JSON payload shown by the service worker.
+type WebPushMessage struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+// WebPushChannel sends encrypted messages (RFC 8291) to a browser push
+// subscription, authenticated with VAPID (RFC 8292).
+type WebPushChannel struct {
+	Subscription *WebPushSubscription
+	VapidKey     *ecdsa.PrivateKey
+	Subject      string
+	TTL          int
+}
+
+func (c *WebPushChannel) Send(m *ChatMessage) error {
+	payload, err := json.Marshal(&WebPushMessage{Title: m.Title, Body: strings.Join(m.Lines, "\n"), URL: m.LinkURL})
+	if err != nil {
+		return err
+	}
+	return c.SendPayload(payload)
+}
+
+// SendPayload encrypts and posts the payload to the subscription's endpoint.
+func (c *WebPushChannel) SendPayload(payload []byte) error {
+	userAgentKey, err := DecodeWebPushKey(c.Subscription.P256dh)
+	if err != nil || len(userAgentKey) != webPushKeyLength {
+		return ErrWebPushInvalidKey
+	}
+	authSecret, err := DecodeWebPushKey(c.Subscription.Auth)
+	if err != nil || len(authSecret) != webPushAuthLength {
+		return ErrWebPushInvalidKey
+	}
+	content, err := EncryptWebPushPayload(payload, userAgentKey, authSecret)
+	if err != nil {
+		return err
+	}
+	authorization, err := BuildVapidAuthorization(c.Subscription.Endpoint, c.VapidKey, c.Subject)
+	if err != nil {
+		return err
+	}
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = webPushDefaultTTL
+	}
+	req, err := http.NewRequest(http.MethodPost, c.Subscription.Endpoint, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(ttl))
+	req.Header.Set("Urgency", "normal")
+	res, err := GetWebhookHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(res.Body, chatMaxResponseLog))
+		return nil
+	}
+	if res.StatusCode == http.StatusGone || res.StatusCode == http.StatusNotFound {
+		return ErrWebPushSubscriptionGone
+	}
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, chatMaxResponseLog))
+	msg := "HTTP " + strconv.Itoa(res.StatusCode)
+	if len(resBody) > 0 {
+		msg += ": " + string(resBody)
+	}
+	return errors.New(msg)
+}
+
+// EncryptWebPushPayload encrypts the payload for the user agent's public key
+// and authentication secret using the aes128gcm content coding (RFC 8188) as
+// specified in RFC 8291. The result is a single record including the header.
+func EncryptWebPushPayload(payload, userAgentKey, authSecret []byte) ([]byte, error) {
+	if len(payload) > webPushMaxBodyBytes {
+		return nil, ErrWebPushPayloadTooLarge
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(userAgentKey)
+	if err != nil {
+		return nil, ErrWebPushInvalidKey
+	}
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	cek, nonce, err := deriveWebPushKeys(sharedSecret, authSecret, userAgentKey, asPublic, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// The single and therefore last record is terminated by a 0x02 delimiter
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// DecryptWebPushPayload reverses EncryptWebPushPayload using the user agent's
+// private key. Browsers do this on receipt, it is used for tests and local
+// push service stand-ins.
+func DecryptWebPushPayload(content []byte, userAgentKey *ecdh.PrivateKey, authSecret []byte) ([]byte, error) {
+	if len(content) < 21 {
+		return nil, ErrWebPushInvalidKey
+	}
+	salt := content[:16]
+	idLen := int(content[20])
+	if len(content) < 21+idLen {
+		return nil, ErrWebPushInvalidKey
+	}
+	asPublicBytes := content[21 : 21+idLen]
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		return nil, ErrWebPushInvalidKey
+	}
+	sharedSecret, err := userAgentKey.ECDH(asPublic)
+	if err != nil {
+		return nil, err
+	}
+	cek, nonce, err := deriveWebPushKeys(sharedSecret, authSecret, userAgentKey.PublicKey().Bytes(), asPublicBytes, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, content[21+idLen:], nil)
+	if err != nil {
+		return nil, err
+	}
+	end := bytes.LastIndexByte(plaintext, 0x02)
+	if end < 0 {
+		return nil, ErrWebPushInvalidKey
+	}
+	return plaintext[:end], nil
+}
+
+func deriveWebPushKeys(sharedSecret, authSecret, uaPublic, asPublic, salt []byte) ([]byte, []byte, error) {
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
+
+// BuildVapidAuthorization returns the Authorization header value identifying
+// the application server to the push service of the endpoint.
+func BuildVapidAuthorization(endpoint string, key *ecdsa.PrivateKey, subject string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	claims := jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(webPushVapidExpiry).Unix(),
+		"sub": subject,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := GetVapidPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + token + ", k=" + publicKey, nil
+}
+
+// GenerateVapidPrivateKey creates a new VAPID key pair and returns the private
+// key base64url encoded.
+func GenerateVapidPrivateKey() (string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	b, err := key.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ParseVapidPrivateKey parses a base64url encoded raw P-256 private key.
+func ParseVapidPrivateKey(s string) (*ecdsa.PrivateKey, error) {
+	b, err := DecodeWebPushKey(s)
+	if err != nil {
+		return nil, err
+	}
+	return ecdsa.ParseRawPrivateKey(elliptic.P256(), b)
+}
+
+// GetVapidPublicKey returns the uncompressed public key base64url encoded,
+// as required by PushManager.subscribe() as applicationServerKey.
+func GetVapidPublicKey(key *ecdsa.PrivateKey) (string, error) {
+	b, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeWebPushKey decodes base64url with or without padding.
+func DecodeWebPushKey(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+// ValidateWebPushEndpoint checks the push service URL of a subscription. The
+// target address is checked again when a message is sent.
+func ValidateWebPushEndpoint(s string) bool {
+	if len(s) > 2048 {
+		return false
+	}
+	u, err := url.ParseRequestURI(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" && u.User == nil
+}
+
+// ValidateWebPushKeys checks the keys of a push subscription.
+func ValidateWebPushKeys(p256dh, auth string) bool {
+	key, err := DecodeWebPushKey(p256dh)
+	if err != nil || len(key) != webPushKeyLength {
+		return false
+	}
+	if _, err := ecdh.P256().NewPublicKey(key); err != nil {
+		return false
+	}
+	secret, err := DecodeWebPushKey(auth)
+	return err == nil && len(secret) == webPushAuthLength
+}
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	. "github.com/seatsurfing/seatsurfing/server/config"
+)
+
+const (
+	WebhookHeaderSignature = "X-Seatsurfing-Signature"
+	WebhookHeaderEvent     = "X-Seatsurfing-Event"
+	WebhookHeaderDelivery  = "X-Seatsurfing-Delivery"
+	webhookRequestTimeout  = 10 * time.Second
+)
+
+var (
+	ErrWebhookAddressNotAllowed = errors.New("webhook target address not allowed")
+	ErrWebhookSignatureInvalid  = errors.New("invalid webhook signature")
+	ErrWebhookSignatureExpired  = errors.New("webhook signature timestamp out of tolerance")
+)
+
+// SignWebhookPayload returns the signature header value for a webhook
+// payload in the form "t=<unix timestamp>,v1=<hex HMAC-SHA256>". The HMAC is
+// computed over "<unix timestamp>.<payload>" so that receivers can reject
+// replayed requests.
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + computeWebhookSignature(secret, ts, payload)
+}
+
+// VerifyWebhookSignature checks a signature header created by
+// SignWebhookPayload. Signatures older or newer than tolerance are rejected
+// (a tolerance of 0 disables the check).
+func VerifyWebhookSignature(secret, header string, payload []byte, tolerance time.Duration) error {
+	var ts string
+	signatures := []string{}
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrWebhookSignatureInvalid
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrWebhookSignatureExpired
+		}
+	}
+	expected := computeWebhookSignature(secret, ts, payload)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrWebhookSignatureInvalid
+}
+
+func computeWebhookSignature(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsPublicIP returns false for loopback, private, link-local, multicast and
+// unspecified addresses.
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+var (
+	webhookHTTPClients   = make(map[bool]*http.Client)
+	webhookHTTPClientsMu sync.Mutex
+)
+
+// GetWebhookHTTPClient returns the HTTP client used for delivering webhooks.
+// Unless WEBHOOK_ALLOW_PRIVATE_NETWORKS is enabled, connections to non-public
+// addresses are refused. The check is performed on the resolved address at
+// connect time so that it cannot be bypassed via DNS. Redirects are not
+// followed. The client is shared so that connections can be reused.
+func GetWebhookHTTPClient() *http.Client {
+	allowPrivate := GetConfig().WebhookAllowPrivateNetworks
+	webhookHTTPClientsMu.Lock()
+	defer webhookHTTPClientsMu.Unlock()
+	client, ok := webhookHTTPClients[allowPrivate]
+	if !ok {
+		client = newWebhookHTTPClient(allowPrivate)
+		webhookHTTPClients[allowPrivate] = client
+	}
+	return client
+}
+
+func newWebhookHTTPClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   webhookRequestTimeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if allowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !IsPublicIP(ip) {
+				return ErrWebhookAddressNotAllowed
+			}
+			return nil
+		},
+	}
+	tr := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: webhookRequestTimeout,
+	}
+	return &http.Client{
+		Timeout:   webhookRequestTimeout,
+		Transport: tr,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
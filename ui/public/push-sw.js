@@ -0,0 +1,26 @@
+// Service worker showing Web Push notifications sent by the Seatsurfing server.
+// Payloads are JSON objects with title, body and an optional url to open.
+
+self.addEventListener("push", (event) => {
+  let message = { title: "Seatsurfing", body: "" };
+  if (event.data) {
+    try {
+      message = event.data.json();
+    } catch {
+      message.body = event.data.text();
+    }
+  }
+  event.waitUntil(
+    self.registration.showNotification(message.title, {
+      body: message.body,
+      icon: "/ui/favicon-192.png",
+      data: { url: message.url || "/ui/" },
+    }),
+  );
+});
+
+self.addEventListener("notificationclick", (event) => {
+  event.notification.close();
+  const url = event.notification.data && event.notification.data.url;
+  event.waitUntil(self.clients.openWindow(url || "/ui/"));
+});
@@ -0,0 +1,200 @@
+import React from "react";
+import { TranslationFunc, withTranslation } from "./withTranslation";
+import { Button, Form, InputGroup, ListGroup } from "react-bootstrap";
+import Delegation from "@/types/Delegation";
+import Formatting from "@/util/Formatting";
+import AjaxError from "@/util/AjaxError";
+import DateTimePicker from "./DateTimePicker";
+import ConfirmModal from "./ConfirmModal";
+
+interface State {
+  delegations: Delegation[];
+  loading: boolean;
+  submitting: boolean;
+  newEmail: string;
+  newExpires: boolean;
+  newExpiry: Date;
+  error: string;
+  delegationPendingDelete: Delegation | null;
+}
+
+interface Props {
+  t: TranslationFunc;
+  hidden?: boolean;
+}
+
+class DelegationSettings extends React.Component<Props, State> {
+  constructor(props: Props) {
+    super(props);
+    const expiry = new Date();
+    expiry.setDate(expiry.getDate() + 14);
+    this.state = {
+      delegations: [],
+      loading: true,
+      submitting: false,
+      newEmail: "",
+      newExpires: false,
+      newExpiry: expiry,
+      error: "",
+      delegationPendingDelete: null,
+    };
+  }
+
+  componentDidMount() {
+    if (!this.props.hidden) {
+      this.loadDelegations();
+    }
+  }
+
+  componentDidUpdate(prevProps: Props) {
+    if (prevProps.hidden && !this.props.hidden) {
+      this.loadDelegations();
+    }
+  }
+
+  loadDelegations = () => {
+    Delegation.list()
+      .then((delegations) => this.setState({ delegations, loading: false }))
+      .catch(() => this.setState({ loading: false }));
+  };
+
+  addDelegation = (evt: React.FormEvent) => {
+    evt.preventDefault();
+    const e = new Delegation();
+    e.delegateEmail = this.state.newEmail.trim();
+    e.expiry = this.state.newExpires ? this.state.newExpiry : null;
+    this.setState({ submitting: true, error: "" });
+    e.save()
+      .then(() => {
+        this.setState({ newEmail: "", newExpires: false, submitting: false });
+        this.loadDelegations();
+      })
+      .catch((err) => {
+        let error = this.props.t("errorSave");
+        if (err instanceof AjaxError && err.httpStatusCode === 404) {
+          error = this.props.t("errorUserNotFound");
+        } else if (err instanceof AjaxError && err.httpStatusCode === 409) {
+          error = this.props.t("errorDelegationExists");
+        }
+        this.setState({ submitting: false, error });
+      });
+  };
+
+  onDeleteConfirmed = () => {
+    const delegation = this.state.delegationPendingDelete;
+    if (!delegation) {
+      return;
+    }
+    this.setState({ delegationPendingDelete: null });
+    delegation.delete().then(() => {
+      const delegations = this.state.delegations.filter(
+        (d) => d.id !== delegation.id,
+      );
+      this.setState({ delegations });
+    });
+  };
+
+  getName = (e: Delegation): string => {
+    return e.delegateEmail ? e.delegateEmail : e.delegateGroupName;
+  };
+
+  render() {
+    const { delegations, loading, submitting, error } = this.state;
+    return (
+      <div hidden={this.props.hidden} className="margin-top-15">
+        <p>{this.props.t("delegatesHint")}</p>
+        {loading ? null : (
+          <>
+            {delegations.length > 0 && (
+              <ListGroup className="mb-3">
+                {delegations.map((e) => (
+                  <ListGroup.Item
+                    key={e.id}
+                    className="d-flex justify-content-between align-items-center"
+                  >
+                    <span>
+                      <strong>{this.getName(e)}</strong>
+                      {e.expiry ? (
+                        <small className="text-muted ms-2">
+                          {this.props.t(
+                            e.active ? "expiresAt" : "expiredAt",
+                            {
+                              date: Formatting.getFormatterShort(false).format(
+                                e.expiry,
+                              ),
+                            },
+                          )}
+                        </small>
+                      ) : null}
+                    </span>
+                    <Button
+                      variant="outline-danger"
+                      size="sm"
+                      onClick={() =>
+                        this.setState({ delegationPendingDelete: e })
+                      }
+                    >
+                      {this.props.t("delete")}
+                    </Button>
+                  </ListGroup.Item>
+                ))}
+              </ListGroup>
+            )}
+            <Form onSubmit={this.addDelegation} style={{ maxWidth: 400 }}>
+              <InputGroup className="mb-2">
+                <Form.Control
+                  type="email"
+                  placeholder={this.props.t("emailPlaceholder")}
+                  value={this.state.newEmail}
+                  onChange={(e) => this.setState({ newEmail: e.target.value })}
+                  disabled={submitting}
+                  required={true}
+                />
+                <Button
+                  variant="primary"
+                  type="submit"
+                  disabled={submitting || !this.state.newEmail.trim()}
+                >
+                  {this.props.t("add")}
+                </Button>
+              </InputGroup>
+              <Form.Check
+                type="switch"
+                id="check-delegationExpires"
+                label={this.props.t("delegationExpires")}
+                checked={this.state.newExpires}
+                onChange={(e) =>
+                  this.setState({ newExpires: e.target.checked })
+                }
+              />
+              {this.state.newExpires ? (
+                <DateTimePicker
+                  id="delegationExpiry"
+                  enableTime={true}
+                  value={this.state.newExpiry}
+                  minDate={new Date()}
+                  onChange={(value: Date) =>
+                    this.setState({ newExpiry: value })
+                  }
+                />
+              ) : null}
+            </Form>
+            {error && <p className="text-danger mt-2">{error}</p>}
+          </>
+        )}
+        <ConfirmModal
+          show={this.state.delegationPendingDelete != null}
+          message={this.props.t("confirmDeleteDelegation", {
+            name: this.state.delegationPendingDelete
+              ? this.getName(this.state.delegationPendingDelete)
+              : "",
+          })}
+          onCancel={() => this.setState({ delegationPendingDelete: null })}
+          onConfirm={this.onDeleteConfirmed}
+        />
+      </div>
+    );
+  }
+}
+
+export default withTranslation(DelegationSettings as any);
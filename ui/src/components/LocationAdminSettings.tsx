@@ -0,0 +1,149 @@
+import React from "react";
+import { TranslationFunc, withTranslation } from "./withTranslation";
+import { Alert, Button, Col, Form, Row, Table } from "react-bootstrap";
+import { Trash2 as IconDelete } from "react-feather";
+import Location, { LocationAdmin } from "@/types/Location";
+import Group from "@/types/Group";
+import AjaxError from "@/util/AjaxError";
+
+interface State {
+  admins: LocationAdmin[];
+  userEmail: string;
+  groupId: string;
+  error: string;
+}
+
+interface Props {
+  t: TranslationFunc;
+  location: Location;
+  groups: Group[];
+}
+
+class LocationAdminSettings extends React.Component<Props, State> {
+  constructor(props: Props) {
+    super(props);
+    this.state = {
+      admins: [],
+      userEmail: "",
+      groupId: "",
+      error: "",
+    };
+  }
+
+  componentDidMount() {
+    this.loadAdmins();
+  }
+
+  loadAdmins = () => {
+    this.props.location
+      .getAdmins()
+      .then((admins) => this.setState({ admins }))
+      .catch(() => {});
+  };
+
+  addAdmin = (e: any) => {
+    e.preventDefault();
+    const admin = new LocationAdmin();
+    admin.userEmail = this.state.groupId ? "" : this.state.userEmail;
+    admin.groupId = this.state.groupId;
+    this.setState({ error: "" });
+    this.props.location
+      .addAdmin(admin)
+      .then(() => {
+        this.setState({ userEmail: "", groupId: "" });
+        this.loadAdmins();
+      })
+      .catch((e) => {
+        let text = this.props.t("errorSave");
+        if (e instanceof AjaxError && e.httpStatusCode === 404) {
+          text = this.props.t("errorAssigneeNotFound");
+        } else if (e instanceof AjaxError && e.httpStatusCode === 409) {
+          text = this.props.t("errorAssignmentExists");
+        }
+        this.setState({ error: text });
+      });
+  };
+
+  removeAdmin = (admin: LocationAdmin) => {
+    this.props.location.removeAdmin(admin).then(() => this.loadAdmins());
+  };
+
+  render() {
+    return (
+      <>
+        <div
+          className="d-flex justify-content-between flex-wrap flex-md-nowrap align-items-center pt-3 pb-2 mb-3 border-bottom"
+          style={{ marginTop: "50px" }}
+        >
+          <h4>{this.props.t("areaAdmins")}</h4>
+        </div>
+        <p className="text-muted">{this.props.t("areaAdminsHint")}</p>
+        {this.state.error ? (
+          <Alert variant="danger">{this.state.error}</Alert>
+        ) : (
+          <></>
+        )}
+        <Form onSubmit={this.addAdmin}>
+          <Form.Group as={Row}>
+            <Col sm="4">
+              <Form.Control
+                type="email"
+                placeholder={this.props.t("emailPlaceholder")}
+                value={this.state.userEmail}
+                disabled={this.state.groupId !== ""}
+                onChange={(e: any) =>
+                  this.setState({ userEmail: e.target.value })
+                }
+                required={this.state.groupId === ""}
+              />
+            </Col>
+            <Col sm="3" hidden={this.props.groups.length === 0}>
+              <Form.Select
+                value={this.state.groupId}
+                onChange={(e: any) =>
+                  this.setState({ groupId: e.target.value })
+                }
+              >
+                <option value="">({this.props.t("group")})</option>
+                {this.props.groups.map((group) => (
+                  <option key={group.id} value={group.id}>
+                    {group.name}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+            <Col sm="2">
+              <Button type="submit" variant="outline-secondary">
+                {this.props.t("add")}
+              </Button>
+            </Col>
+          </Form.Group>
+        </Form>
+        <Table hover>
+          <tbody>
+            {this.state.admins.map((admin) => (
+              <tr key={admin.id}>
+                <td>
+                  {admin.groupId
+                    ? this.props.t("group") + ": " + admin.groupName
+                    : admin.userEmail}
+                </td>
+                <td className="text-end">
+                  <Button
+                    className="btn-sm"
+                    variant="outline-secondary"
+                    onClick={() => this.removeAdmin(admin)}
+                  >
+                    <IconDelete className="feather" /> {this.props.t("remove")}
+                  </Button>
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </Table>
+      </>
+    );
+  }
+}
+
+export default withTranslation(LocationAdminSettings as any);
@@ -77,7 +77,11 @@ class NavBar extends React.Component<Props, State> {
       if (user.email === user.atlassianId) {
         this.setState({ allowMergeInit: true });
       }
-      if (user.role >= User.UserRoleSpaceAdmin) {
+      if (
+        user.role >= User.UserRoleSpaceAdmin ||
+        user.permissions.length > 0 ||
+        user.isManager
+      ) {
         this.setState({ allowAdmin: true });
       }
     });
@@ -0,0 +1,236 @@
+import React from "react";
+import { TranslationFunc, withTranslation } from "./withTranslation";
+import { Alert, Button, Form, ListGroup } from "react-bootstrap";
+import WebPushSubscription from "@/types/WebPushSubscription";
+import UserPreference from "@/types/UserPreference";
+import Formatting from "@/util/Formatting";
+import SaveButton from "./SaveButton";
+
+interface State {
+  loading: boolean;
+  submitting: boolean;
+  supported: boolean;
+  subscribed: boolean;
+  subscriptions: WebPushSubscription[];
+  pushNotifications: boolean;
+  pushApprovalRequests: boolean;
+  pushReminder: boolean;
+  error: string;
+  saved: boolean;
+}
+
+interface Props {
+  t: TranslationFunc;
+  hidden?: boolean;
+}
+
+class PushNotificationSettings extends React.Component<Props, State> {
+  constructor(props: Props) {
+    super(props);
+    this.state = {
+      loading: true,
+      submitting: false,
+      supported: false,
+      subscribed: false,
+      subscriptions: [],
+      pushNotifications: false,
+      pushApprovalRequests: false,
+      pushReminder: false,
+      error: "",
+      saved: false,
+    };
+  }
+
+  componentDidMount() {
+    if (!this.props.hidden) {
+      this.loadData();
+    }
+  }
+
+  componentDidUpdate(prevProps: Props) {
+    if (prevProps.hidden && !this.props.hidden) {
+      this.loadData();
+    }
+  }
+
+  loadData = async () => {
+    const supported = WebPushSubscription.isSupported();
+    try {
+      const [subscriptions, preferences] = await Promise.all([
+        WebPushSubscription.list(),
+        UserPreference.list(),
+      ]);
+      let subscribed = false;
+      if (supported) {
+        const current = await WebPushSubscription.getBrowserSubscription();
+        subscribed =
+          current != null &&
+          subscriptions.some((s) => s.endpoint === current.endpoint);
+      }
+      const getBool = (name: string) =>
+        preferences.some((p) => p.name === name && p.value === "1");
+      this.setState({
+        loading: false,
+        supported,
+        subscribed,
+        subscriptions,
+        pushNotifications: getBool(UserPreference.PREF_PUSH_NOTIFICATIONS),
+        pushApprovalRequests: getBool(
+          UserPreference.PREF_PUSH_APPROVAL_REQUESTS,
+        ),
+        pushReminder: getBool(UserPreference.PREF_PUSH_REMINDER),
+      });
+    } catch {
+      this.setState({ loading: false, supported });
+    }
+  };
+
+  toggleSubscription = async () => {
+    this.setState({ submitting: true, error: "", saved: false });
+    try {
+      if (this.state.subscribed) {
+        await WebPushSubscription.unsubscribe();
+      } else {
+        await WebPushSubscription.subscribe();
+      }
+      this.setState({ submitting: false });
+      this.loadData();
+    } catch {
+      this.setState({
+        submitting: false,
+        error: this.props.t("pushSubscribeError"),
+      });
+    }
+  };
+
+  deleteSubscription = (e: WebPushSubscription) => {
+    e.delete().then(() => this.loadData());
+  };
+
+  savePreferences = async (evt: React.FormEvent) => {
+    evt.preventDefault();
+    this.setState({ submitting: true, error: "", saved: false });
+    try {
+      await UserPreference.setAll([
+        new UserPreference(
+          UserPreference.PREF_PUSH_NOTIFICATIONS,
+          this.state.pushNotifications ? "1" : "0",
+        ),
+        new UserPreference(
+          UserPreference.PREF_PUSH_APPROVAL_REQUESTS,
+          this.state.pushApprovalRequests ? "1" : "0",
+        ),
+        new UserPreference(
+          UserPreference.PREF_PUSH_REMINDER,
+          this.state.pushReminder ? "1" : "0",
+        ),
+      ]);
+      this.setState({ submitting: false, saved: true });
+    } catch {
+      this.setState({ submitting: false, error: this.props.t("errorSave") });
+    }
+  };
+
+  render() {
+    if (this.state.loading) {
+      return <div hidden={this.props.hidden}></div>;
+    }
+    const { subscriptions, submitting } = this.state;
+    return (
+      <Form onSubmit={this.savePreferences} hidden={this.props.hidden}>
+        <h5 className="margin-top-15">{this.props.t("pushNotifications")}</h5>
+        <p>{this.props.t("pushNotificationsHint")}</p>
+        {this.state.supported ? (
+          <Button
+            variant={this.state.subscribed ? "outline-danger" : "primary"}
+            onClick={this.toggleSubscription}
+            disabled={submitting}
+          >
+            {this.props.t(
+              this.state.subscribed ? "pushUnsubscribe" : "pushSubscribe",
+            )}
+          </Button>
+        ) : (
+          <p className="text-muted">{this.props.t("pushNotSupported")}</p>
+        )}
+        {subscriptions.length > 0 && (
+          <>
+            <h6 className="margin-top-15">{this.props.t("pushDevices")}</h6>
+            <ListGroup className="mb-3">
+              {subscriptions.map((e) => (
+                <ListGroup.Item
+                  key={e.id}
+                  className="d-flex justify-content-between align-items-center"
+                >
+                  <span>
+                    <span className="text-break">{e.userAgent || "—"}</span>
+                    <small className="text-muted ms-2">
+                      {Formatting.getFormatterShort(false).format(e.created)}
+                    </small>
+                  </span>
+                  <Button
+                    variant="outline-danger"
+                    size="sm"
+                    onClick={() => this.deleteSubscription(e)}
+                  >
+                    {this.props.t("delete")}
+                  </Button>
+                </ListGroup.Item>
+              ))}
+            </ListGroup>
+          </>
+        )}
+        <Form.Group className="margin-top-15">
+          <Form.Check
+            type="switch"
+            id="pushNotifications"
+            label={this.props.t("pushNotificationsApprovals")}
+            checked={this.state.pushNotifications}
+            onChange={(e: any) =>
+              this.setState({ pushNotifications: e.target.checked })
+            }
+          />
+        </Form.Group>
+        <Form.Group className="margin-top-15">
+          <Form.Check
+            type="switch"
+            id="pushApprovalRequests"
+            label={this.props.t("pushNotificationsApprovalRequests")}
+            checked={this.state.pushApprovalRequests}
+            onChange={(e: any) =>
+              this.setState({ pushApprovalRequests: e.target.checked })
+            }
+          />
+        </Form.Group>
+        <Form.Group className="margin-top-15">
+          <Form.Check
+            type="switch"
+            id="pushReminder"
+            label={this.props.t("chatReminderBookingInfo")}
+            checked={this.state.pushReminder}
+            onChange={(e: any) =>
+              this.setState({ pushReminder: e.target.checked })
+            }
+          />
+        </Form.Group>
+        {this.state.saved && (
+          <Alert variant="success" className="margin-top-15">
+            {this.props.t("entryUpdated")}
+          </Alert>
+        )}
+        {this.state.error && (
+          <Alert variant="danger" className="margin-top-15">
+            {this.state.error}
+          </Alert>
+        )}
+        <SaveButton
+          className="margin-top-15"
+          submitting={submitting}
+          disabled={submitting}
+        />
+      </Form>
+    );
+  }
+}
+
+export default withTranslation(PushNotificationSettings as any);
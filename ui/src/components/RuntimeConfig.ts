@@ -32,6 +32,9 @@ interface RuntimeUserInfos {
   superAdmin: boolean;
   spaceAdmin: boolean;
   orgAdmin: boolean;
+  permissions: string[];
+  locationScopedPermissions: string[];
+  isManager: boolean;
   pluginMenuItems: any[];
   pluginWelcomeScreens: any[];
   featureGroups: boolean;
@@ -89,6 +92,9 @@ export default class RuntimeConfig {
       superAdmin: false,
       spaceAdmin: false,
       orgAdmin: false,
+      permissions: [],
+      locationScopedPermissions: [],
+      isManager: false,
       pluginMenuItems: [],
       pluginWelcomeScreens: [],
       featureGroups: false,
@@ -257,6 +263,10 @@ export default class RuntimeConfig {
     RuntimeConfig.INFOS.superAdmin = user.superAdmin;
     RuntimeConfig.INFOS.spaceAdmin = user.spaceAdmin;
     RuntimeConfig.INFOS.orgAdmin = user.admin;
+    RuntimeConfig.INFOS.permissions = user.permissions;
+    RuntimeConfig.INFOS.locationScopedPermissions =
+      user.locationScopedPermissions;
+    RuntimeConfig.INFOS.isManager = user.isManager;
     RuntimeConfig.INFOS.idpLogin = !user.requirePassword;
     RuntimeConfig.INFOS.totpEnabled = user.totpEnabled;
     RuntimeConfig.INFOS.hasPasskeys = user.hasPasskeys;
@@ -300,6 +310,16 @@ export default class RuntimeConfig {
     };
   }
 
+  static hasPermission(permission: string): boolean {
+    return RuntimeConfig.INFOS.permissions.indexOf(permission) >= 0;
+  }
+
+  static isLocationScoped(permission: string): boolean {
+    return (
+      RuntimeConfig.INFOS.locationScopedPermissions.indexOf(permission) >= 0
+    );
+  }
+
   static async logOut(): Promise<void> {
     const credentials = Ajax.PERSISTER.readCredentialsFromLocalStorage();
     const logoutUrl = credentials.logoutUrl;
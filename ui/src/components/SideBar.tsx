@@ -12,6 +12,13 @@ import {
   Icon,
   Clock as IconApproval,
   Shield as IconShield,
+  FileText as IconAuditLog,
+  Key as IconRoles,
+  Tag as IconUserAttributes,
+  Share2 as IconWebhooks,
+  Mail as IconMailQueue,
+  Edit3 as IconEmailTemplates,
+  Send as IconMailSettings,
 } from "react-feather";
 import { Badge, Nav } from "react-bootstrap";
 import { NextRouter } from "next/router";
@@ -27,6 +34,7 @@ import Booking from "@/types/Booking";
 import AjaxError from "@/util/AjaxError";
 import RendererUtils from "@/util/RendererUtils";
 import Event from "@/util/Event";
+import Role from "@/types/Role";
 
 interface State {
   approvalCount: number;
@@ -84,8 +92,11 @@ class SideBar extends React.Component<Props, State> {
   };
 
   pollApprovalCount = async () => {
-    // Do nothing if we don't have an access token
-    if (!Ajax.hasAccessToken()) {
+    // Do nothing if we don't have an access token or may not approve
+    if (
+      !Ajax.hasAccessToken() ||
+      !RuntimeConfig.hasPermission(Role.PERMISSION_APPROVE_BOOKINGS)
+    ) {
       return;
     }
 
@@ -106,7 +117,13 @@ class SideBar extends React.Component<Props, State> {
       "/admin/organizations",
       "/admin/users",
       "/admin/groups",
+      "/admin/roles",
+      "/admin/user-attributes",
       "/admin/settings",
+      "/admin/webhooks",
+      "/admin/mail-queue",
+      "/admin/email-templates",
+      "/admin/mail-settings",
       "/admin/locations",
       "/admin/bookings",
       "/admin/approvals",
@@ -164,72 +181,199 @@ class SideBar extends React.Component<Props, State> {
         </li>
       );
     }
-    let orgAdminItems = <></>;
-    if (RuntimeConfig.INFOS.orgAdmin) {
-      orgAdminItems = (
-        <>
+    let orgAdminItems = (
+      <>
+        {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_USERS) && (
+          <>
+            <li className="nav-item">
+              <Nav.Link as={Link} eventKey="/admin/users" href="/admin/users">
+                <this.SidebarIcon
+                  icon={IconUsers}
+                  title={this.props.t("users")}
+                />
+                <span className="d-none d-md-inline">
+                  {" "}
+                  {this.props.t("users")}
+                </span>
+              </Nav.Link>
+            </li>
+            <li className="nav-item">
+              <Nav.Link
+                as={Link}
+                eventKey="/admin/groups"
+                href="/admin/groups"
+                disabled={
+                  !RuntimeConfig.INFOS.featureGroups &&
+                  !RuntimeConfig.INFOS.cloudHosted
+                }
+              >
+                <this.SidebarIcon
+                  icon={IconGroups}
+                  title={this.props.t("groups")}
+                />
+                <span className="d-none d-md-inline">
+                  {" "}
+                  {this.props.t("groups")}
+                </span>
+                <PremiumFeatureIcon className="d-none d-md-inline" />
+              </Nav.Link>
+            </li>
+            <li className="nav-item">
+              <Nav.Link
+                as={Link}
+                eventKey="/admin/user-attributes"
+                href="/admin/user-attributes"
+              >
+                <this.SidebarIcon
+                  icon={IconUserAttributes}
+                  title={this.props.t("userAttributes")}
+                />
+                <span className="d-none d-md-inline">
+                  {" "}
+                  {this.props.t("userAttributes")}
+                </span>
+              </Nav.Link>
+            </li>
+          </>
+        )}
+        {RuntimeConfig.INFOS.orgAdmin && (
+          <li className="nav-item">
+            <Nav.Link as={Link} eventKey="/admin/roles" href="/admin/roles">
+              <this.SidebarIcon
+                icon={IconRoles}
+                title={this.props.t("roles")}
+              />
+              <span className="d-none d-md-inline">
+                {" "}
+                {this.props.t("roles")}
+              </span>
+            </Nav.Link>
+          </li>
+        )}
+        {RuntimeConfig.hasPermission(Role.PERMISSION_VIEW_AUDIT_LOG) && (
+          <>
+            <li className="nav-item">
+              <Nav.Link as={Link} eventKey="/admin/audit" href="/admin/audit">
+                <this.SidebarIcon
+                  icon={IconShield}
+                  title={this.props.t("audit")}
+                />
+                <span className="d-none d-md-inline">
+                  {" "}
+                  {this.props.t("audit")}
+                </span>
+              </Nav.Link>
+            </li>
+            <li className="nav-item">
+              <Nav.Link
+                as={Link}
+                eventKey="/admin/audit-log"
+                href="/admin/audit-log"
+              >
+                <this.SidebarIcon
+                  icon={IconAuditLog}
+                  title={this.props.t("auditLog")}
+                />
+                <span className="d-none d-md-inline">
+                  {" "}
+                  {this.props.t("auditLog")}
+                </span>
+              </Nav.Link>
+            </li>
+          </>
+        )}
+        {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_SETTINGS) && (
           <li className="nav-item">
-            <Nav.Link as={Link} eventKey="/admin/users" href="/admin/users">
+            <Nav.Link
+              as={Link}
+              eventKey="/admin/settings"
+              href="/admin/settings"
+            >
               <this.SidebarIcon
-                icon={IconUsers}
-                title={this.props.t("users")}
+                icon={IconSettings}
+                title={this.props.t("settings")}
               />
               <span className="d-none d-md-inline">
                 {" "}
-                {this.props.t("users")}
+                {this.props.t("settings")}
               </span>
             </Nav.Link>
           </li>
+        )}
+        {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_SETTINGS) && (
           <li className="nav-item">
             <Nav.Link
               as={Link}
-              eventKey="/admin/groups"
-              href="/admin/groups"
-              disabled={
-                !RuntimeConfig.INFOS.featureGroups &&
-                !RuntimeConfig.INFOS.cloudHosted
-              }
+              eventKey="/admin/webhooks"
+              href="/admin/webhooks"
             >
               <this.SidebarIcon
-                icon={IconGroups}
-                title={this.props.t("groups")}
+                icon={IconWebhooks}
+                title={this.props.t("webhooks")}
               />
               <span className="d-none d-md-inline">
                 {" "}
-                {this.props.t("groups")}
+                {this.props.t("webhooks")}
               </span>
-              <PremiumFeatureIcon className="d-none d-md-inline" />
             </Nav.Link>
           </li>
+        )}
+        {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_SETTINGS) && (
           <li className="nav-item">
-            <Nav.Link as={Link} eventKey="/admin/audit" href="/admin/audit">
+            <Nav.Link
+              as={Link}
+              eventKey="/admin/mail-queue"
+              href="/admin/mail-queue"
+            >
               <this.SidebarIcon
-                icon={IconShield}
-                title={this.props.t("audit")}
+                icon={IconMailQueue}
+                title={this.props.t("mailQueue")}
               />
               <span className="d-none d-md-inline">
                 {" "}
-                {this.props.t("audit")}
+                {this.props.t("mailQueue")}
               </span>
             </Nav.Link>
           </li>
+        )}
+        {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_SETTINGS) && (
           <li className="nav-item">
             <Nav.Link
               as={Link}
-              eventKey="/admin/settings"
-              href="/admin/settings"
+              eventKey="/admin/email-templates"
+              href="/admin/email-templates"
             >
               <this.SidebarIcon
-                icon={IconSettings}
-                title={this.props.t("settings")}
+                icon={IconEmailTemplates}
+                title={this.props.t("emailTemplates")}
               />
               <span className="d-none d-md-inline">
                 {" "}
-                {this.props.t("settings")}
+                {this.props.t("emailTemplates")}
               </span>
             </Nav.Link>
           </li>
-          {RuntimeConfig.INFOS.pluginMenuItems.map((item) => {
+        )}
+        {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_SETTINGS) && (
+          <li className="nav-item">
+            <Nav.Link
+              as={Link}
+              eventKey="/admin/mail-settings"
+              href="/admin/mail-settings"
+            >
+              <this.SidebarIcon
+                icon={IconMailSettings}
+                title={this.props.t("mailSettings")}
+              />
+              <span className="d-none d-md-inline">
+                {" "}
+                {this.props.t("mailSettings")}
+              </span>
+            </Nav.Link>
+          </li>
+        )}
+        {RuntimeConfig.INFOS.orgAdmin &&
+          RuntimeConfig.INFOS.pluginMenuItems.map((item) => {
             if (item.visibility !== "admin") {
               return;
             }
@@ -237,7 +381,9 @@ class SideBar extends React.Component<Props, State> {
             if (!PluginIcon) {
               PluginIcon = dynamic(
                 () =>
-                  import("react-feather/dist/icons/" + item.icon.toLowerCase()),
+                  import(
+                    "react-feather/dist/icons/" + item.icon.toLowerCase()
+                  ),
                 { ssr: true },
               ) as Icon;
               this.dynamicIcons.set(item.icon, PluginIcon);
@@ -255,9 +401,8 @@ class SideBar extends React.Component<Props, State> {
               </li>
             );
           })}
-        </>
-      );
-    }
+      </>
+    );
     return (
       <Nav
         id="sidebarMenu"
@@ -282,87 +427,95 @@ class SideBar extends React.Component<Props, State> {
                 </span>
               </Nav.Link>
             </li>
-            <li className="nav-item">
-              <Nav.Link
-                as={Link}
-                eventKey="/admin/locations"
-                href="/admin/locations"
-              >
-                <this.SidebarIcon
-                  icon={IconMap}
-                  title={this.props.t("areas")}
-                />
-                <span className="d-none d-md-inline">
-                  {" "}
-                  {this.props.t("areas")}
-                </span>
-              </Nav.Link>
-            </li>
-            <li className="nav-item">
-              <Nav.Link
-                as={Link}
-                eventKey="/admin/bookings"
-                href="/admin/bookings"
-              >
-                <this.SidebarIcon
-                  icon={IconBook}
-                  title={this.props.t("bookings")}
-                />
-                <span className="d-none d-md-inline">
-                  {" "}
-                  {this.props.t("bookings")}
-                </span>
-              </Nav.Link>
-            </li>
-            <li className="nav-item">
-              <Nav.Link
-                as={Link}
-                eventKey="/admin/approvals"
-                href="/admin/approvals"
-                disabled={
-                  !RuntimeConfig.INFOS.featureGroups &&
-                  !RuntimeConfig.INFOS.cloudHosted
-                }
-              >
-                <this.SidebarIcon
-                  icon={IconApproval}
-                  title={this.props.t("approvals")}
-                />
-                <span className="d-none d-md-inline position-relative">
-                  {" "}
-                  {this.props.t("approvals")}
-                  <Badge
-                    bg="primary"
-                    hidden={this.state.approvalCount === 0}
-                    className="position-absolute top-50 start-100 translate-middle-y"
-                    style={{
-                      marginLeft: "5px",
-                    }}
-                  >
-                    {RendererUtils.numberPlus(this.state.approvalCount, 9)}
-                  </Badge>
-                </span>
-                <PremiumFeatureIcon className="d-none d-md-inline" />
-              </Nav.Link>
-            </li>
-            {!RuntimeConfig.INFOS.hideReports && (
+            {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_SPACES) && (
               <li className="nav-item">
                 <Nav.Link
                   as={Link}
-                  eventKey="/admin/report/analysis"
-                  href="/admin/report/analysis"
+                  eventKey="/admin/locations"
+                  href="/admin/locations"
                 >
                   <this.SidebarIcon
-                    icon={IconAnalysis}
-                    title={this.props.t("analysis")}
+                    icon={IconMap}
+                    title={this.props.t("areas")}
                   />
                   <span className="d-none d-md-inline">
                     {" "}
-                    {this.props.t("analysis")}
+                    {this.props.t("areas")}
                   </span>
                 </Nav.Link>
               </li>
             )}
+            {RuntimeConfig.hasPermission(Role.PERMISSION_MANAGE_BOOKINGS) && (
+              <li className="nav-item">
+                <Nav.Link
+                  as={Link}
+                  eventKey="/admin/bookings"
+                  href="/admin/bookings"
+                >
+                  <this.SidebarIcon
+                    icon={IconBook}
+                    title={this.props.t("bookings")}
+                  />
+                  <span className="d-none d-md-inline">
+                    {" "}
+                    {this.props.t("bookings")}
+                  </span>
+                </Nav.Link>
+              </li>
+            )}
+            {RuntimeConfig.hasPermission(Role.PERMISSION_APPROVE_BOOKINGS) && (
+              <li className="nav-item">
+                <Nav.Link
+                  as={Link}
+                  eventKey="/admin/approvals"
+                  href="/admin/approvals"
+                  disabled={
+                    !RuntimeConfig.INFOS.featureGroups &&
+                    !RuntimeConfig.INFOS.cloudHosted
+                  }
+                >
+                  <this.SidebarIcon
+                    icon={IconApproval}
+                    title={this.props.t("approvals")}
+                  />
+                  <span className="d-none d-md-inline position-relative">
+                    {" "}
+                    {this.props.t("approvals")}
+                    <Badge
+                      bg="primary"
+                      hidden={this.state.approvalCount === 0}
+                      className="position-absolute top-50 start-100 translate-middle-y"
+                      style={{
+                        marginLeft: "5px",
+                      }}
+                    >
+                      {RendererUtils.numberPlus(this.state.approvalCount, 9)}
+                    </Badge>
+                  </span>
+                  <PremiumFeatureIcon className="d-none d-md-inline" />
+                </Nav.Link>
+              </li>
+            )}
+            {!RuntimeConfig.INFOS.hideReports &&
+              (RuntimeConfig.hasPermission(Role.PERMISSION_VIEW_REPORTS) ||
+                RuntimeConfig.INFOS.isManager) && (
+                <li className="nav-item">
+                  <Nav.Link
+                    as={Link}
+                    eventKey="/admin/report/analysis"
+                    href="/admin/report/analysis"
+                  >
+                    <this.SidebarIcon
+                      icon={IconAnalysis}
+                      title={this.props.t("analysis")}
+                    />
+                    <span className="d-none d-md-inline">
+                      {" "}
+                      {this.props.t("analysis")}
+                    </span>
+                  </Nav.Link>
+                </li>
+              )}
             {RuntimeConfig.INFOS.pluginMenuItems.map((item) => {
               if (item.visibility !== "spaceadmin") {
                 return;
@@ -0,0 +1,468 @@
+import React from "react";
+import {
+  Table,
+  Form,
+  Col,
+  Row,
+  Button,
+  Modal,
+  Pagination,
+} from "react-bootstrap";
+import { Search as IconSearch, Download as IconDownload } from "react-feather";
+import FullLayout from "@/components/FullLayout";
+import { NextRouter } from "next/router";
+import Loading from "@/components/Loading";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import AuditLogEntry, { AuditLogListParams } from "@/types/AuditLogEntry";
+import DateUtil from "@/util/DateUtil";
+import Formatting from "@/util/Formatting";
+import DateTimePicker from "@/components/DateTimePicker";
+
+const PAGE_SIZE = 50;
+
+interface State {
+  loading: boolean;
+  start: Date;
+  end: Date;
+  filterActor: string;
+  filterAction: string;
+  filterEntityType: string;
+  page: number;
+  total: number;
+  selectedItem: AuditLogEntry | null;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class AuditLog extends React.Component<Props, State> {
+  data: AuditLogEntry[];
+
+  constructor(props: any) {
+    super(props);
+    this.data = [];
+
+    const getDateFromQuery = (
+      paramName: string,
+      defaultOffsetDays: number,
+    ): Date => {
+      const queryValue = this.props.router.query[paramName] as string;
+      if (queryValue && DateUtil.isValidDateTime(queryValue)) {
+        return new Date(queryValue);
+      }
+      const defaultDate = new Date();
+      defaultDate.setDate(defaultDate.getDate() + defaultOffsetDays);
+      return defaultOffsetDays < 0
+        ? DateUtil.setHoursToMin(defaultDate)
+        : DateUtil.setHoursToMax(defaultDate);
+    };
+
+    const actionQuery = this.props.router.query["action"] as string;
+    const entityTypeQuery = this.props.router.query["entityType"] as string;
+    this.state = {
+      loading: true,
+      start: getDateFromQuery("start", -7),
+      end: getDateFromQuery("end", 0),
+      filterActor: (this.props.router.query["actor"] as string) ?? "",
+      filterAction:
+        AuditLogEntry.ACTIONS.indexOf(actionQuery) >= 0 ? actionQuery : "",
+      filterEntityType:
+        AuditLogEntry.ENTITY_TYPES.indexOf(entityTypeQuery) >= 0
+          ? entityTypeQuery
+          : "",
+      page: 0,
+      total: 0,
+      selectedItem: null,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadItems();
+  };
+
+  updateUrlParams = () => {
+    const currentQuery = {
+      start: DateUtil.formatToDateTimeString(this.state.start),
+      end: DateUtil.formatToDateTimeString(this.state.end),
+      ...(this.state.filterActor && { actor: this.state.filterActor }),
+      ...(this.state.filterAction && { action: this.state.filterAction }),
+      ...(this.state.filterEntityType && {
+        entityType: this.state.filterEntityType,
+      }),
+    };
+    this.props.router.replace(
+      {
+        pathname: this.props.router.pathname,
+        query: currentQuery,
+      },
+      undefined,
+      { shallow: true },
+    );
+  };
+
+  getFilterParams = (): AuditLogListParams => {
+    return {
+      start: this.state.start,
+      end: DateUtil.setSecondsToMax(this.state.end),
+      actor: this.state.filterActor,
+      action: this.state.filterAction,
+      entityType: this.state.filterEntityType,
+    };
+  };
+
+  loadItems = async (page: number = 0) => {
+    const result = await AuditLogEntry.list({
+      ...this.getFilterParams(),
+      limit: PAGE_SIZE,
+      offset: page * PAGE_SIZE,
+    });
+    this.data = result.items;
+    this.setState({ loading: false, total: result.total, page: page });
+    this.updateUrlParams();
+  };
+
+  onFilterSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({ loading: true });
+    this.loadItems();
+  };
+
+  onPageSelect = (page: number) => {
+    this.setState({ loading: true });
+    this.loadItems(page);
+  };
+
+  onExport = () => {
+    AuditLogEntry.export(this.getFilterParams());
+  };
+
+  getActionLabel = (action: string): string => {
+    if (AuditLogEntry.ACTIONS.indexOf(action) < 0) {
+      return action;
+    }
+    return this.props.t("auditaction_" + action);
+  };
+
+  getEntityTypeLabel = (entityType: string): string => {
+    if (AuditLogEntry.ENTITY_TYPES.indexOf(entityType) < 0) {
+      return entityType;
+    }
+    return this.props.t("auditentity_" + entityType);
+  };
+
+  formatValue = (value: any): string => {
+    if (value === null || value === undefined) {
+      return "";
+    }
+    if (typeof value === "object") {
+      return JSON.stringify(value);
+    }
+    return String(value);
+  };
+
+  renderItem = (item: AuditLogEntry) => {
+    return (
+      <tr key={item.id} onClick={() => this.setState({ selectedItem: item })}>
+        <td>{Formatting.getFormatterShort().format(item.timestamp)}</td>
+        <td>{item.actorEmail}</td>
+        <td>{this.getActionLabel(item.action)}</td>
+        <td>{this.getEntityTypeLabel(item.entityType)}</td>
+        <td>{item.entityName || item.entityId}</td>
+      </tr>
+    );
+  };
+
+  renderPagination = () => {
+    const numPages = Math.ceil(this.state.total / PAGE_SIZE);
+    if (numPages <= 1) {
+      return <></>;
+    }
+    const items = [];
+    for (let i = 0; i < numPages; i++) {
+      items.push(
+        <Pagination.Item
+          key={i}
+          active={i === this.state.page}
+          onClick={() => this.onPageSelect(i)}
+        >
+          {i + 1}
+        </Pagination.Item>,
+      );
+    }
+    return (
+      <Pagination>
+        <Pagination.Prev
+          disabled={this.state.page === 0}
+          onClick={() => this.onPageSelect(this.state.page - 1)}
+        />
+        {items}
+        <Pagination.Next
+          disabled={this.state.page >= numPages - 1}
+          onClick={() => this.onPageSelect(this.state.page + 1)}
+        />
+      </Pagination>
+    );
+  };
+
+  renderChanges = (item: AuditLogEntry) => {
+    const keys = Object.keys(item.changes);
+    if (keys.length === 0) {
+      return <></>;
+    }
+    return (
+      <Table size="sm">
+        <thead>
+          <tr>
+            <th>{this.props.t("auditAttribute")}</th>
+            <th>{this.props.t("auditOldValue")}</th>
+            <th>{this.props.t("auditNewValue")}</th>
+          </tr>
+        </thead>
+        <tbody>
+          {keys.map((key) => (
+            <tr key={key}>
+              <td>{key}</td>
+              <td style={{ wordBreak: "break-all" }}>
+                {this.formatValue(item.changes[key].old)}
+              </td>
+              <td style={{ wordBreak: "break-all" }}>
+                {this.formatValue(item.changes[key].new)}
+              </td>
+            </tr>
+          ))}
+        </tbody>
+      </Table>
+    );
+  };
+
+  renderDetailsModal = () => {
+    const item = this.state.selectedItem;
+    if (!item) {
+      return <></>;
+    }
+    return (
+      <Modal
+        show={true}
+        onHide={() => this.setState({ selectedItem: null })}
+        size="lg"
+      >
+        <Modal.Header closeButton>
+          <Modal.Title>{this.props.t("details")}</Modal.Title>
+        </Modal.Header>
+        <Modal.Body>
+          <Table>
+            <tbody>
+              <tr>
+                <th>{this.props.t("timestamp")}</th>
+                <td>{Formatting.getFormatterShort().format(item.timestamp)}</td>
+              </tr>
+              <tr>
+                <th>{this.props.t("actor")}</th>
+                <td>{item.actorEmail}</td>
+              </tr>
+              <tr>
+                <th>{this.props.t("ipAddress")}</th>
+                <td>{item.ip}</td>
+              </tr>
+              <tr>
+                <th>{this.props.t("auditAction")}</th>
+                <td>{this.getActionLabel(item.action)}</td>
+              </tr>
+              <tr>
+                <th>{this.props.t("auditEntityType")}</th>
+                <td>{this.getEntityTypeLabel(item.entityType)}</td>
+              </tr>
+              <tr>
+                <th>{this.props.t("auditEntity")}</th>
+                <td>
+                  {item.entityName}
+                  {item.entityId && item.entityId !== item.entityName
+                    ? ` (${item.entityId})`
+                    : ""}
+                </td>
+              </tr>
+            </tbody>
+          </Table>
+          {this.renderChanges(item)}
+        </Modal.Body>
+        <Modal.Footer>
+          <Button
+            variant="secondary"
+            onClick={() => this.setState({ selectedItem: null })}
+          >
+            {this.props.t("close")}
+          </Button>
+        </Modal.Footer>
+      </Modal>
+    );
+  };
+
+  render() {
+    const buttons = (
+      <>
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          onClick={this.onExport}
+        >
+          <IconDownload className="feather" /> {this.props.t("exportCsv")}
+        </Button>
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          type="submit"
+          form="form"
+        >
+          <IconSearch className="feather" /> {this.props.t("search")}
+        </Button>
+      </>
+    );
+    const form = (
+      <Form onSubmit={this.onFilterSubmit} id="form">
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="input-start">
+            {this.props.t("from")}
+          </Form.Label>
+          <Col sm="4">
+            <DateTimePicker
+              id="input-start"
+              value={this.state.start}
+              onChange={(value: Date | null) => {
+                if (value != null) this.setState({ start: value });
+              }}
+              clearIcon={null}
+              required={true}
+              enableTime={true}
+            />
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="input-end">
+            {this.props.t("end")}
+          </Form.Label>
+          <Col sm="4">
+            <DateTimePicker
+              id="input-end"
+              value={this.state.end}
+              onChange={(value: Date | null) => {
+                if (value != null) this.setState({ end: value });
+              }}
+              clearIcon={null}
+              required={true}
+              enableTime={true}
+            />
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="input-actor">
+            {this.props.t("actor")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Control
+              id="input-actor"
+              type="text"
+              value={this.state.filterActor}
+              placeholder={this.props.t("emailPlaceholder")}
+              onChange={(e: any) =>
+                this.setState({ filterActor: e.target.value })
+              }
+            />
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="action-select">
+            {this.props.t("auditAction")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Select
+              id="action-select"
+              value={this.state.filterAction}
+              onChange={(e: any) =>
+                this.setState({ filterAction: e.target.value })
+              }
+            >
+              <option value="">({this.props.t("all")})</option>
+              {AuditLogEntry.ACTIONS.map((action) => (
+                <option key={action} value={action}>
+                  {this.getActionLabel(action)}
+                </option>
+              ))}
+            </Form.Select>
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="entity-type-select">
+            {this.props.t("auditEntityType")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Select
+              id="entity-type-select"
+              value={this.state.filterEntityType}
+              onChange={(e: any) =>
+                this.setState({ filterEntityType: e.target.value })
+              }
+            >
+              <option value="">({this.props.t("all")})</option>
+              {AuditLogEntry.ENTITY_TYPES.map((entityType) => (
+                <option key={entityType} value={entityType}>
+                  {this.getEntityTypeLabel(entityType)}
+                </option>
+              ))}
+            </Form.Select>
+          </Col>
+        </Form.Group>
+      </Form>
+    );
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("auditLog")}>
+          {form}
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    const rows = this.data.map((item) => this.renderItem(item));
+    if (rows.length === 0) {
+      return (
+        <FullLayout headline={this.props.t("auditLog")} buttons={buttons}>
+          {form}
+          <p>{this.props.t("noRecords")}</p>
+        </FullLayout>
+      );
+    }
+    return (
+      <FullLayout headline={this.props.t("auditLog")} buttons={buttons}>
+        {form}
+        <Table
+          striped={true}
+          hover={true}
+          className="clickable-table caption-top"
+          id="datatable"
+        >
+          <caption>
+            {this.props.t("numRecords")}: {this.state.total}
+          </caption>
+          <thead>
+            <tr>
+              <th>{this.props.t("timestamp")}</th>
+              <th>{this.props.t("actor")}</th>
+              <th>{this.props.t("auditAction")}</th>
+              <th>{this.props.t("auditEntityType")}</th>
+              <th>{this.props.t("auditEntity")}</th>
+            </tr>
+          </thead>
+          <tbody>{rows}</tbody>
+        </Table>
+        {this.renderPagination()}
+        {this.renderDetailsModal()}
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(AuditLog as any));
@@ -60,6 +60,7 @@ interface State {
   subject: string;
   typeaheadSelected: [{ email: string }];
   showDeleteConfirm: boolean;
+  cancelReason: string;
 }
 
 interface Props {
@@ -125,6 +126,7 @@ class EditBooking extends React.Component<Props, State> {
       subject: "",
       typeaheadSelected: [{ email: "" }],
       showDeleteConfirm: false,
+      cancelReason: "",
     };
   }
 
@@ -432,12 +434,12 @@ class EditBooking extends React.Component<Props, State> {
   };
 
   deleteItem = () => {
-    this.setState({ showDeleteConfirm: true });
+    this.setState({ showDeleteConfirm: true, cancelReason: "" });
   };
 
   confirmDeleteItem = () => {
     this.setState({ showDeleteConfirm: false });
-    this.entity.delete().then(() => {
+    this.entity.delete(this.state.cancelReason.trim()).then(() => {
       this.setState({ goBack: true });
     });
   };
@@ -935,15 +937,35 @@ class EditBooking extends React.Component<Props, State> {
         <ConfirmModal
           show={this.state.showDeleteConfirm}
           message={
-            this.state.showDeleteConfirm
-              ? RendererUtils.decodeHtmlEntities(
-                  this.props.t("confirmCancelBooking", {
-                    enter: Formatting.getBookingDateFormatter().format(
-                      this.entity.enter,
-                    ),
-                  }),
-                )
-              : ""
+            this.state.showDeleteConfirm ? (
+              <>
+                <p>
+                  {RendererUtils.decodeHtmlEntities(
+                    this.props.t("confirmCancelBooking", {
+                      enter: Formatting.getBookingDateFormatter().format(
+                        this.entity.enter,
+                      ),
+                    }),
+                  )}
+                </p>
+                <Form.Label htmlFor="input-cancelReason">
+                  {this.props.t("cancellationReason")}
+                </Form.Label>
+                <Form.Control
+                  id="input-cancelReason"
+                  as="textarea"
+                  rows={2}
+                  value={this.state.cancelReason}
+                  onChange={(e: any) =>
+                    this.setState({ cancelReason: e.target.value })
+                  }
+                  maxLength={500}
+                />
+                <Form.Text>{this.props.t("cancellationReasonHint")}</Form.Text>
+              </>
+            ) : (
+              ""
+            )
           }
           onCancel={() => this.setState({ showDeleteConfirm: false })}
           onConfirm={this.confirmDeleteItem}
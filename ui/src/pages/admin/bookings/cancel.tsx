@@ -0,0 +1,285 @@
+import React from "react";
+import { Form, Col, Row, Button, Alert } from "react-bootstrap";
+import { ChevronLeft as IconBack, XCircle as IconCancel } from "react-feather";
+import { NextRouter } from "next/router";
+import Link from "next/link";
+import FullLayout from "@/components/FullLayout";
+import Loading from "@/components/Loading";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import DateTimePicker from "@/components/DateTimePicker";
+import UserSearchTypeahead from "@/components/UserSearchTypeahead";
+import ConfirmModal from "@/components/ConfirmModal";
+import Booking from "@/types/Booking";
+import Location from "@/types/Location";
+import Space from "@/types/Space";
+import Role from "@/types/Role";
+import DateUtil from "@/util/DateUtil";
+
+interface State {
+  loading: boolean;
+  submitting: boolean;
+  start: Date;
+  end: Date;
+  locationId: string;
+  spaceId: string;
+  userId: string;
+  reason: string;
+  showConfirm: boolean;
+  cancelledCount: number | null;
+  failedCount: number;
+  error: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class BulkCancelBookings extends React.Component<Props, State> {
+  locations: Location[];
+  spaces: Space[];
+
+  constructor(props: any) {
+    super(props);
+    this.locations = [];
+    this.spaces = [];
+    this.state = {
+      loading: true,
+      submitting: false,
+      start: DateUtil.setHoursToMin(new Date()),
+      end: DateUtil.setHoursToMax(new Date()),
+      locationId: "",
+      spaceId: "",
+      userId: "",
+      reason: "",
+      showConfirm: false,
+      cancelledCount: null,
+      failedCount: 0,
+      error: false,
+    };
+  }
+
+  componentDidMount = () => {
+    Location.list(Role.PERMISSION_MANAGE_BOOKINGS).then((locations) => {
+      this.locations = locations;
+      this.setState({ loading: false });
+    });
+  };
+
+  onLocationChange = (locationId: string) => {
+    this.spaces = [];
+    this.setState({ locationId, spaceId: "" });
+    if (locationId) {
+      Space.list(locationId).then((spaces) => {
+        this.spaces = spaces;
+        this.forceUpdate();
+      });
+    }
+  };
+
+  onUserSelected = (selected: any[]) => {
+    this.setState({ userId: selected[0]?.id ?? "" });
+  };
+
+  onSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({ showConfirm: true });
+  };
+
+  performCancel = () => {
+    this.setState({
+      showConfirm: false,
+      submitting: true,
+      cancelledCount: null,
+      failedCount: 0,
+      error: false,
+    });
+    Booking.bulkCancel(
+      this.state.start,
+      DateUtil.setSecondsToMax(this.state.end),
+      this.state.locationId,
+      this.state.spaceId,
+      this.state.userId,
+      this.state.reason.trim(),
+    ).then(
+      (res) => {
+        this.setState({
+          submitting: false,
+          cancelledCount: res.count,
+          failedCount: res.failed,
+        });
+      },
+      () => {
+        this.setState({ submitting: false, error: true });
+      },
+    );
+  };
+
+  render() {
+    const backButton = (
+      <Link href="/admin/bookings" className="btn btn-sm btn-outline-secondary">
+        <IconBack className="feather" /> {this.props.t("back")}
+      </Link>
+    );
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("bulkCancel")}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let hint = <></>;
+    if (this.state.cancelledCount !== null) {
+      hint = (
+        <>
+          <Alert variant="success">
+            {this.props.t("bulkCancelSuccess", {
+              num: this.state.cancelledCount,
+            })}
+          </Alert>
+          {this.state.failedCount > 0 && (
+            <Alert variant="danger">
+              {this.props.t("bulkCancelFailed", {
+                num: this.state.failedCount,
+              })}
+            </Alert>
+          )}
+        </>
+      );
+    } else if (this.state.error) {
+      hint = <Alert variant="danger">{this.props.t("errorSave")}</Alert>;
+    }
+
+    return (
+      <FullLayout headline={this.props.t("bulkCancel")} buttons={backButton}>
+        <Form onSubmit={this.onSubmit} id="form">
+          {hint}
+          <p>{this.props.t("bulkCancelHint")}</p>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-enter">
+              {this.props.t("enter")}
+            </Form.Label>
+            <Col sm="4">
+              <DateTimePicker
+                id="input-enter"
+                value={this.state.start}
+                onChange={(value: Date | null) => {
+                  if (value != null) this.setState({ start: value });
+                }}
+                clearIcon={null}
+                required={true}
+                enableTime={true}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-leave">
+              {this.props.t("leave")}
+            </Form.Label>
+            <Col sm="4">
+              <DateTimePicker
+                id="input-leave"
+                value={this.state.end}
+                onChange={(value: Date | null) => {
+                  if (value != null) this.setState({ end: value });
+                }}
+                clearIcon={null}
+                required={true}
+                enableTime={true}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="area-select">
+              {this.props.t("area")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Select
+                id="area-select"
+                value={this.state.locationId}
+                onChange={(e: any) => this.onLocationChange(e.target.value)}
+              >
+                <option value="">({this.props.t("all")})</option>
+                {this.locations.map((location) => (
+                  <option key={location.id} value={location.id}>
+                    {location.name}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="space-select">
+              {this.props.t("space")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Select
+                id="space-select"
+                value={this.state.spaceId}
+                disabled={!this.state.locationId}
+                onChange={(e: any) =>
+                  this.setState({ spaceId: e.target.value })
+                }
+              >
+                <option value="">({this.props.t("all")})</option>
+                {this.spaces.map((space) => (
+                  <option key={space.id} value={space.id}>
+                    {space.name}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("user")}
+            </Form.Label>
+            <Col sm="4">
+              <UserSearchTypeahead
+                t={this.props.t}
+                multiple={false}
+                onChange={this.onUserSelected}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-reason">
+              {this.props.t("cancellationReason")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="input-reason"
+                as="textarea"
+                rows={3}
+                value={this.state.reason}
+                onChange={(e: any) =>
+                  this.setState({ reason: e.target.value })
+                }
+                required={true}
+                maxLength={500}
+              />
+              <Form.Text>{this.props.t("bulkCancelReasonHint")}</Form.Text>
+            </Col>
+          </Form.Group>
+          <Button
+            variant="danger"
+            type="submit"
+            disabled={this.state.submitting || !this.state.reason.trim()}
+          >
+            <IconCancel className="feather" /> {this.props.t("bulkCancel")}
+          </Button>
+        </Form>
+        <ConfirmModal
+          show={this.state.showConfirm}
+          message={this.props.t("confirmBulkCancel")}
+          onCancel={() => this.setState({ showConfirm: false })}
+          onConfirm={this.performCancel}
+        />
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(BulkCancelBookings as any));
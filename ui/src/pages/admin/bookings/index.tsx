@@ -5,6 +5,7 @@ import {
   Search as IconSearch,
   Download as IconDownload,
   X as IconX,
+  XCircle as IconBulkCancel,
   RefreshCw as IconRecurring,
 } from "react-feather";
 import FullLayout from "@/components/FullLayout";
@@ -37,6 +38,7 @@ interface State {
   filterOption: "enter_leave" | "current" | "today";
   filterLocation: string;
   cancelBookingItem: Booking | null;
+  cancelReason: string;
   alertMessage: string | null;
 }
 
@@ -93,6 +95,7 @@ class Bookings extends React.Component<Props, State> {
             : "current",
       filterLocation: this.props.router.query["location"] as string,
       cancelBookingItem: null,
+      cancelReason: "",
       alertMessage: null,
     };
     this.loadSettings();
@@ -170,14 +173,14 @@ class Bookings extends React.Component<Props, State> {
   };
 
   cancelBooking = (booking: Booking) => {
-    this.setState({ cancelBookingItem: booking });
+    this.setState({ cancelBookingItem: booking, cancelReason: "" });
   };
 
   performCancelBooking = (booking: Booking) => {
     this.setState({
       loading: true,
     });
-    booking.delete().then(
+    booking.delete(this.state.cancelReason.trim()).then(
       () => {
         this.loadItems();
       },
@@ -312,6 +315,12 @@ class Bookings extends React.Component<Props, State> {
       <>
         {this.data && this.data.length > 0 ? downloadButton : <></>}
         {searchButton}
+        <Link
+          href="/admin/bookings/cancel"
+          className="btn btn-sm btn-outline-secondary"
+        >
+          <IconBulkCancel className="feather" /> {this.props.t("bulkCancel")}
+        </Link>
         <Link
           href="/admin/bookings/add"
           className="btn btn-sm btn-outline-secondary"
@@ -477,15 +486,35 @@ class Bookings extends React.Component<Props, State> {
         <ConfirmModal
           show={this.state.cancelBookingItem !== null}
           message={
-            this.state.cancelBookingItem
-              ? RendererUtils.decodeHtmlEntities(
-                  this.props.t("confirmCancelBooking", {
-                    enter: Formatting.getBookingDateFormatter().format(
-                      this.state.cancelBookingItem.enter,
-                    ),
-                  }),
-                )
-              : ""
+            this.state.cancelBookingItem ? (
+              <>
+                <p>
+                  {RendererUtils.decodeHtmlEntities(
+                    this.props.t("confirmCancelBooking", {
+                      enter: Formatting.getBookingDateFormatter().format(
+                        this.state.cancelBookingItem.enter,
+                      ),
+                    }),
+                  )}
+                </p>
+                <Form.Label htmlFor="input-cancelReason">
+                  {this.props.t("cancellationReason")}
+                </Form.Label>
+                <Form.Control
+                  id="input-cancelReason"
+                  as="textarea"
+                  rows={2}
+                  value={this.state.cancelReason}
+                  onChange={(e: any) =>
+                    this.setState({ cancelReason: e.target.value })
+                  }
+                  maxLength={500}
+                />
+                <Form.Text>{this.props.t("cancellationReasonHint")}</Form.Text>
+              </>
+            ) : (
+              ""
+            )
           }
           onCancel={() => this.setState({ cancelBookingItem: null })}
           onConfirm={() => {
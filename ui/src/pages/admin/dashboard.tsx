@@ -28,6 +28,7 @@ import DateUtil from "@/util/DateUtil";
 import Navigation from "@/util/Navigation";
 import UpdateChecker from "@/util/UpdateChecker";
 import CloudHint from "@/components/CloudHint";
+import Role from "@/types/Role";
 
 interface State {
   loading: boolean;
@@ -67,8 +68,7 @@ class Dashboard extends React.Component<Props, State> {
 
   componentDidMount = async () => {
     await Promise.all([
-      this.loadItems(),
-      this.loadLocations(),
+      this.loadLocations().then(this.loadItems),
       this.getUserInfo(),
       this.checkUpdates(),
     ]);
@@ -82,25 +82,48 @@ class Dashboard extends React.Component<Props, State> {
     this.setState({ latestVersion: await UpdateChecker.check() });
   };
 
+  isStatsHidden = (): boolean => {
+    return (
+      RuntimeConfig.INFOS.hideStats ||
+      !RuntimeConfig.hasPermission(Role.PERMISSION_VIEW_REPORTS)
+    );
+  };
+
+  // Users who may only view reports for specific areas must select one of them
+  isStatsLocationScoped = (): boolean => {
+    return RuntimeConfig.isLocationScoped(Role.PERMISSION_VIEW_REPORTS);
+  };
+
   getUserInfo = async (): Promise<void> => {
     const user = await User.getSelf();
     this.setState({ spaceAdmin: user.spaceAdmin, orgAdmin: user.admin });
   };
 
   loadLocations = async (): Promise<void> => {
-    this.locations = await Location.list();
+    this.locations = await Location.list(Role.PERMISSION_VIEW_REPORTS);
   };
 
   loadItems = async (): Promise<void> => {
-    if (RuntimeConfig.INFOS.hideStats) {
+    if (this.isStatsHidden()) {
       return;
     }
-    const stats = await Stats.get();
-    this.setState({ stats });
+    let locationId: string | null = null;
+    if (this.isStatsLocationScoped()) {
+      if (this.locations.length === 0) {
+        return;
+      }
+      locationId = this.locations[0].id;
+    }
+    const stats = await Stats.get(locationId);
+    this.setState({
+      stats,
+      selectedUtilizationLocationId: locationId,
+      selectedWeekdayLocationId: locationId,
+    });
   };
 
   updateLoad = async (locationId: string | null = null): Promise<void> => {
-    if (RuntimeConfig.INFOS.hideStats) {
+    if (this.isStatsHidden()) {
       return;
     }
     const statsLoad = await StatsLoad.getLoad(locationId);
@@ -116,7 +139,7 @@ class Dashboard extends React.Component<Props, State> {
     locationId: string | null = null,
     period: string | null = null,
   ): Promise<void> => {
-    if (RuntimeConfig.INFOS.hideStats) {
+    if (this.isStatsHidden()) {
       return;
     }
     const bookingsByWeekday = await StatsLoad.getWeekday(locationId, period);
@@ -250,7 +273,9 @@ class Dashboard extends React.Component<Props, State> {
     const yesterdayDateString = DateUtil.getDateString(-1);
 
     let statsContent = <></>;
-    if (RuntimeConfig.INFOS.hideStats) {
+    if (!RuntimeConfig.hasPermission(Role.PERMISSION_VIEW_REPORTS)) {
+      // Users without access to reports see no statistics at all
+    } else if (RuntimeConfig.INFOS.hideStats) {
       statsContent = <p>{this.props.t("statsHiddenByAdmin")}</p>;
     } else {
       statsContent = (
@@ -384,6 +409,7 @@ class Dashboard extends React.Component<Props, State> {
                         </Dropdown.Toggle>
                         <Dropdown.Menu align="end">
                           <Dropdown.Item
+                            hidden={this.isStatsLocationScoped()}
                             onClick={() => {
                               this.updateWeekdayChart(
                                 null,
@@ -462,6 +488,7 @@ class Dashboard extends React.Component<Props, State> {
                       </Dropdown.Toggle>
                       <Dropdown.Menu align="end">
                         <Dropdown.Item
+                          hidden={this.isStatsLocationScoped()}
                           onClick={() => {
                             this.updateLoad();
                           }}
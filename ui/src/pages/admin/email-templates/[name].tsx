@@ -0,0 +1,382 @@
+import React from "react";
+import { Form, Col, Row, Button, Alert, Modal } from "react-bootstrap";
+import {
+  ChevronLeft as IconBack,
+  Save as IconSave,
+  Eye as IconPreview,
+  RotateCcw as IconReset,
+} from "react-feather";
+import { NextRouter } from "next/router";
+import FullLayout from "@/components/FullLayout";
+import Link from "next/link";
+import Loading from "@/components/Loading";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import ConfirmModal from "@/components/ConfirmModal";
+import EmailTemplate, {
+  EmailTemplateDefinition,
+  EmailTemplatePreview,
+} from "@/types/EmailTemplate";
+import AjaxError from "@/util/AjaxError";
+import ErrorText from "@/types/ErrorText";
+
+interface State {
+  loading: boolean;
+  saved: boolean;
+  error: boolean;
+  errorText: string;
+  language: string;
+  subject: string;
+  headline: string;
+  bodyHtml: string;
+  bodyText: string;
+  preview: EmailTemplatePreview | null;
+  showResetConfirm: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class EditEmailTemplate extends React.Component<Props, State> {
+  entity: EmailTemplate = new EmailTemplate();
+  definition: EmailTemplateDefinition = new EmailTemplateDefinition();
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      loading: true,
+      saved: false,
+      error: false,
+      errorText: "",
+      language: EmailTemplate.LANGUAGES[0],
+      subject: "",
+      headline: "",
+      bodyHtml: "",
+      bodyText: "",
+      preview: null,
+      showResetConfirm: false,
+    };
+  }
+
+  componentDidMount = () => {
+    const { name } = this.props.router.query;
+    if (typeof name !== "string") {
+      return;
+    }
+    EmailTemplate.listDefinitions().then((list) => {
+      this.definition =
+        list.find((def) => def.name === name) ?? this.definition;
+      this.loadData(this.state.language);
+    });
+  };
+
+  loadData = (language: string) => {
+    const { name } = this.props.router.query;
+    EmailTemplate.get(name as string, language).then((template) => {
+      this.entity = template;
+      this.setState({
+        loading: false,
+        language: language,
+        subject: template.subject,
+        headline: template.headline,
+        bodyHtml: template.bodyHtml,
+        bodyText: template.bodyText,
+      });
+    });
+  };
+
+  onLanguageChange = (language: string) => {
+    this.setState({ loading: true, saved: false, error: false });
+    this.loadData(language);
+  };
+
+  applyState = () => {
+    this.entity.subject = this.state.subject;
+    this.entity.headline = this.state.headline;
+    this.entity.bodyHtml = this.state.bodyHtml;
+    this.entity.bodyText = this.state.bodyText;
+  };
+
+  handleError = (e: any) => {
+    let text = this.props.t("errorSave");
+    if (e instanceof AjaxError && e.appErrorCode) {
+      text = ErrorText.getTextForAppCode(e.appErrorCode, this.props.t);
+    }
+    this.setState({ error: true, errorText: text });
+  };
+
+  onSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({ error: false, saved: false });
+    this.applyState();
+    this.entity
+      .save()
+      .then(() => {
+        this.entity.custom = true;
+        this.setState({ saved: true });
+      })
+      .catch(this.handleError);
+  };
+
+  onPreview = () => {
+    this.setState({ error: false, saved: false });
+    this.applyState();
+    this.entity
+      .preview()
+      .then((preview) => this.setState({ preview: preview }))
+      .catch(this.handleError);
+  };
+
+  onReset = () => {
+    this.entity.delete().then(() => {
+      this.setState({ loading: true, saved: false, error: false });
+      this.loadData(this.state.language);
+    });
+  };
+
+  renderVariables = () => {
+    const variables = this.definition.variables.map((v) => {
+      const required = this.definition.requiredVariables.includes(v);
+      return (
+        <li key={v}>
+          <code>{"{{" + v + "}}"}</code>
+          {required ? " (" + this.props.t("required") + ")" : ""}
+        </li>
+      );
+    });
+    const conditions = this.definition.conditions.map((v) => (
+      <li key={v}>
+        <code>{"{{if " + v + "}}…{{end}}"}</code>
+      </li>
+    ));
+    return (
+      <ul className="list-unstyled">
+        {variables}
+        {conditions}
+      </ul>
+    );
+  };
+
+  renderPreview = () => {
+    if (!this.state.preview) {
+      return <></>;
+    }
+    return (
+      <Modal
+        show={true}
+        size="lg"
+        onHide={() => this.setState({ preview: null })}
+      >
+        <Modal.Header closeButton>
+          <Modal.Title>{this.state.preview.subject}</Modal.Title>
+        </Modal.Header>
+        <Modal.Body>
+          <iframe
+            title={this.props.t("emailTemplatePreview")}
+            srcDoc={this.state.preview.bodyHtml}
+            sandbox=""
+            style={{ width: "100%", height: "60vh", border: 0 }}
+          />
+          {this.state.preview.bodyText ? (
+            <pre className="mt-3">{this.state.preview.bodyText}</pre>
+          ) : (
+            <></>
+          )}
+        </Modal.Body>
+      </Modal>
+    );
+  };
+
+  render() {
+    const backButton = (
+      <Link
+        href="/admin/email-templates"
+        className="btn btn-sm btn-outline-secondary"
+      >
+        <IconBack className="feather" /> {this.props.t("back")}
+      </Link>
+    );
+    const headline = this.props.t("editEmailTemplate");
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={headline} buttons={backButton}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let hint = <></>;
+    if (this.state.saved) {
+      hint = <Alert variant="success">{this.props.t("entryUpdated")}</Alert>;
+    } else if (this.state.error) {
+      hint = <Alert variant="danger">{this.state.errorText}</Alert>;
+    }
+    let customHint = <></>;
+    if (!this.entity.custom) {
+      customHint = (
+        <Alert variant="info">{this.props.t("emailTemplateBuiltinHint")}</Alert>
+      );
+    }
+
+    let buttonReset = <></>;
+    if (this.entity.custom) {
+      buttonReset = (
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          onClick={() => this.setState({ showResetConfirm: true })}
+        >
+          <IconReset className="feather" /> {this.props.t("emailTemplateReset")}
+        </Button>
+      );
+    }
+    const buttons = (
+      <>
+        {backButton} {buttonReset}{" "}
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          onClick={this.onPreview}
+        >
+          <IconPreview className="feather" />{" "}
+          {this.props.t("emailTemplatePreview")}
+        </Button>{" "}
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          type="submit"
+          form="form"
+        >
+          <IconSave className="feather" /> {this.props.t("save")}
+        </Button>
+      </>
+    );
+
+    return (
+      <FullLayout headline={headline} buttons={buttons}>
+        <Form onSubmit={this.onSubmit} id="form">
+          {hint}
+          {customHint}
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("name")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                plaintext={true}
+                readOnly={true}
+                value={this.definition.name}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="language">
+              {this.props.t("language")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Select
+                id="language"
+                value={this.state.language}
+                onChange={(e: any) => this.onLanguageChange(e.target.value)}
+              >
+                {EmailTemplate.LANGUAGES.map((language) => (
+                  <option key={language} value={language}>
+                    {language.toUpperCase()}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="subject">
+              {this.props.t("emailTemplateSubject")}
+            </Form.Label>
+            <Col sm="6">
+              <Form.Control
+                id="subject"
+                type="text"
+                value={this.state.subject}
+                maxLength={256}
+                onChange={(e: any) =>
+                  this.setState({ subject: e.target.value })
+                }
+                required={true}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="headline">
+              {this.props.t("emailTemplateHeadline")}
+            </Form.Label>
+            <Col sm="6">
+              <Form.Control
+                id="headline"
+                type="text"
+                value={this.state.headline}
+                maxLength={256}
+                onChange={(e: any) =>
+                  this.setState({ headline: e.target.value })
+                }
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="bodyHtml">
+              {this.props.t("emailTemplateBodyHtml")}
+            </Form.Label>
+            <Col sm="6">
+              <Form.Control
+                id="bodyHtml"
+                as="textarea"
+                rows={12}
+                className="font-monospace"
+                value={this.state.bodyHtml}
+                onChange={(e: any) =>
+                  this.setState({ bodyHtml: e.target.value })
+                }
+                required={true}
+              />
+            </Col>
+            <Col sm="4">
+              <Form.Text>{this.props.t("emailTemplateVariables")}</Form.Text>
+              {this.renderVariables()}
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="bodyText">
+              {this.props.t("emailTemplateBodyText")}
+            </Form.Label>
+            <Col sm="6">
+              <Form.Control
+                id="bodyText"
+                as="textarea"
+                rows={8}
+                className="font-monospace"
+                value={this.state.bodyText}
+                onChange={(e: any) =>
+                  this.setState({ bodyText: e.target.value })
+                }
+              />
+              <Form.Text>{this.props.t("emailTemplateBodyTextHint")}</Form.Text>
+            </Col>
+          </Form.Group>
+        </Form>
+        {this.renderPreview()}
+        <ConfirmModal
+          show={this.state.showResetConfirm}
+          message={this.props.t("confirmResetEmailTemplate")}
+          onCancel={() => this.setState({ showResetConfirm: false })}
+          onConfirm={() => {
+            this.setState({ showResetConfirm: false });
+            this.onReset();
+          }}
+        />
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(EditEmailTemplate as any));
@@ -0,0 +1,104 @@
+import React from "react";
+import { Badge, Table } from "react-bootstrap";
+import FullLayout from "@/components/FullLayout";
+import Loading from "@/components/Loading";
+import { NextRouter } from "next/router";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import EmailTemplate, { EmailTemplateDefinition } from "@/types/EmailTemplate";
+
+interface State {
+  selectedItem: string;
+  loading: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class EmailTemplates extends React.Component<Props, State> {
+  data: EmailTemplateDefinition[] = [];
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      selectedItem: "",
+      loading: true,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadItems();
+  };
+
+  loadItems = () => {
+    EmailTemplate.listDefinitions().then((list) => {
+      this.data = list;
+      this.setState({ loading: false });
+    });
+  };
+
+  onItemSelect = (def: EmailTemplateDefinition) => {
+    this.setState({ selectedItem: def.name });
+  };
+
+  renderItem = (def: EmailTemplateDefinition) => {
+    return (
+      <tr key={def.name} onClick={() => this.onItemSelect(def)}>
+        <td>{def.name}</td>
+        <td>
+          {EmailTemplate.LANGUAGES.map((language) =>
+            def.customLanguages.includes(language) ? (
+              <Badge bg="primary" key={language} className="me-1">
+                {language.toUpperCase()}
+              </Badge>
+            ) : (
+              <Badge bg="secondary" key={language} className="me-1">
+                {language.toUpperCase()}
+              </Badge>
+            ),
+          )}
+        </td>
+      </tr>
+    );
+  };
+
+  render() {
+    if (this.state.selectedItem) {
+      this.props.router.push(
+        `/admin/email-templates/${this.state.selectedItem}`,
+      );
+      return <></>;
+    }
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("emailTemplates")}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    return (
+      <FullLayout headline={this.props.t("emailTemplates")}>
+        <p>{this.props.t("emailTemplatesHint")}</p>
+        <Table
+          striped={true}
+          hover={true}
+          className="clickable-table caption-top"
+        >
+          <thead>
+            <tr>
+              <th>{this.props.t("name")}</th>
+              <th>{this.props.t("emailTemplateCustomized")}</th>
+            </tr>
+          </thead>
+          <tbody>{this.data.map((item) => this.renderItem(item))}</tbody>
+        </Table>
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(EmailTemplates as any));
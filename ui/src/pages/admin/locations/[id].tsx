@@ -38,6 +38,7 @@ import { TranslationFunc, withTranslation } from "@/components/withTranslation";
 import SpaceAttributeValue from "@/types/SpaceAttributeValue";
 import SpaceAttribute from "@/types/SpaceAttribute";
 import Group from "@/types/Group";
+import UserAttribute from "@/types/UserAttribute";
 import Location from "@/types/Location";
 import Ajax from "@/util/Ajax";
 import Space from "@/types/Space";
@@ -49,6 +50,7 @@ import PremiumFeatureIcon from "@/components/PremiumFeatureIcon";
 import FloorPlanDesigner from "@/components/FloorPlanDesigner";
 import WeekdaySelection from "@/components/WeekdaySelection";
 import ConfirmModal from "@/components/ConfirmModal";
+import LocationAdminSettings from "@/components/LocationAdminSettings";
 
 const IconTrapezoid = ({ className }: { className?: string }) => (
   <svg
@@ -88,6 +90,7 @@ interface SpaceState {
   attributes: Map<string, string>;
   enabledAttributes: string[];
   approvers: any[] | undefined;
+  secondApprovers: any[] | undefined;
   allowBookers: any[] | undefined;
 }
 
@@ -426,6 +429,9 @@ interface State {
   showEditSpaceDetailsModal: boolean;
   selectedSpaceMouseDownTimestamp: number;
   locationAllowBookers: any[] | undefined;
+  locationAllowAttributes: { attributeId: string; value: string }[];
+  newAllowAttributeId: string;
+  newAllowAttributeValue: string;
   showDesignerModal: boolean;
   gridEnabled: boolean;
   outline: boolean;
@@ -441,6 +447,7 @@ interface Props {
 class EditLocation extends React.Component<Props, State> {
   entity: Location = new Location();
   groups: Group[] = [];
+  userAttributes: UserAttribute[] = [];
   mapData: any = null;
   timezones: string[];
   ExcellentExport: any;
@@ -479,6 +486,9 @@ class EditLocation extends React.Component<Props, State> {
       showEditSpaceDetailsModal: false,
       selectedSpaceMouseDownTimestamp: 0,
       locationAllowBookers: [],
+      locationAllowAttributes: [],
+      newAllowAttributeId: "",
+      newAllowAttributeValue: "",
       showDesignerModal: false,
       gridEnabled: false,
       outline: false,
@@ -488,7 +498,11 @@ class EditLocation extends React.Component<Props, State> {
   }
 
   componentDidMount = () => {
-    const promises = [this.loadData(), this.loadTimezones()];
+    const promises = [
+      this.loadData(),
+      this.loadTimezones(),
+      this.loadUserAttributes(),
+    ];
     Promise.all(promises).then(() => {
       this.setState({
         loading: false,
@@ -505,6 +519,12 @@ class EditLocation extends React.Component<Props, State> {
     });
   };
 
+  loadUserAttributes = async (): Promise<void> => {
+    return UserAttribute.list().then((list) => {
+      this.userAttributes = list;
+    });
+  };
+
   loadData = async (locationId?: string): Promise<void> => {
     if (!locationId) {
       const { id } = this.props.router.query;
@@ -560,6 +580,7 @@ class EditLocation extends React.Component<Props, State> {
                               location.allowedBookerGroupIds.includes(g.id),
                             )
                           : [],
+                      locationAllowAttributes: location.allowedBookerAttributes,
                       loading: false,
                     });
                   });
@@ -634,6 +655,9 @@ class EditLocation extends React.Component<Props, State> {
         space.approverGroupIds = RuntimeConfig.INFOS.featureGroups
           ? item.approvers?.map((e: any) => e.id) || []
           : [];
+        space.secondApproverGroupIds = RuntimeConfig.INFOS.featureGroups
+          ? item.secondApprovers?.map((e: any) => e.id) || []
+          : [];
         space.allowedBookerGroupIds = RuntimeConfig.INFOS.featureGroups
           ? item.allowBookers?.map((e: any) => e.id) || []
           : [];
@@ -699,6 +723,7 @@ class EditLocation extends React.Component<Props, State> {
     this.entity.allowedBookerGroupIds = RuntimeConfig.INFOS.featureGroups
       ? this.state.locationAllowBookers?.map((e: any) => e.id) || []
       : [];
+    this.entity.allowedBookerAttributes = this.state.locationAllowAttributes;
     this.entity
       .save()
       .then(() => {
@@ -847,6 +872,10 @@ class EditLocation extends React.Component<Props, State> {
         e && e.approverGroupIds
           ? this.groups.filter((g) => e.approverGroupIds.includes(g.id))
           : [],
+      secondApprovers:
+        e && e.secondApproverGroupIds
+          ? this.groups.filter((g) => e.secondApproverGroupIds.includes(g.id))
+          : [],
       allowBookers:
         e && e.allowedBookerGroupIds
           ? this.groups.filter((g) => e.allowedBookerGroupIds.includes(g.id))
@@ -1228,6 +1257,18 @@ class EditLocation extends React.Component<Props, State> {
     this.setState({ spaces: spaces, changed: true });
   };
 
+  onSecondApproversSearchSelected = (selected: any) => {
+    if (this.state.selectedSpace == null) {
+      return;
+    }
+    const spaces = this.state.spaces;
+    const space = { ...spaces[this.state.selectedSpace] };
+    space.secondApprovers = selected.map((e: any) => e as Group);
+    space.changed = true;
+    spaces[this.state.selectedSpace] = space;
+    this.setState({ spaces: spaces, changed: true });
+  };
+
   onAllowBookersSearchSelected = (selected: any) => {
     if (this.state.selectedSpace == null) {
       return;
@@ -1246,6 +1287,33 @@ class EditLocation extends React.Component<Props, State> {
     });
   };
 
+  addLocationAllowAttribute = () => {
+    const value = this.state.newAllowAttributeValue.trim();
+    if (!this.state.newAllowAttributeId || !value) {
+      return;
+    }
+    const rules = this.state.locationAllowAttributes.filter(
+      (e) =>
+        e.attributeId !== this.state.newAllowAttributeId || e.value !== value,
+    );
+    rules.push({ attributeId: this.state.newAllowAttributeId, value });
+    this.setState({
+      locationAllowAttributes: rules,
+      newAllowAttributeValue: "",
+    });
+  };
+
+  removeLocationAllowAttribute = (index: number) => {
+    const rules = [...this.state.locationAllowAttributes];
+    rules.splice(index, 1);
+    this.setState({ locationAllowAttributes: rules });
+  };
+
+  getUserAttributeLabel = (attributeId: string): string => {
+    const attribute = this.userAttributes.find((a) => a.id === attributeId);
+    return attribute ? attribute.label : attributeId;
+  };
+
   getSpaceAttributeRows = () => {
     const res: any = [];
     this.state.availableAttributes.forEach((a) => {
@@ -1482,6 +1550,28 @@ class EditLocation extends React.Component<Props, State> {
                 </Form.Text>
               </Col>
             </Form.Group>
+            <Form.Group as={Row}>
+              <Form.Label column sm="4" htmlFor="search-second-approvers-input">
+                {this.props.t("secondApprovers")}
+              </Form.Label>
+              <Col sm="8">
+                <GroupSearchTypeahead
+                  t={this.props.t}
+                  disabled={!RuntimeConfig.INFOS.featureGroups}
+                  id="search-second-approvers"
+                  inputProps={{ id: "search-second-approvers-input" }}
+                  multiple={true}
+                  onChange={this.onSecondApproversSearchSelected}
+                  defaultSelected={this.getSelectedSpace()?.secondApprovers}
+                />
+                <Form.Text
+                  className="text-muted"
+                  hidden={!RuntimeConfig.INFOS.featureGroups}
+                >
+                  {this.props.t("setSecondApproversHint")}
+                </Form.Text>
+              </Col>
+            </Form.Group>
             <Form.Group as={Row}>
               <Form.Label column sm="4" htmlFor="search-allowbookers-input">
                 {this.props.t("allowBookers")}
@@ -2278,9 +2368,76 @@ class EditLocation extends React.Component<Props, State> {
               </Form.Text>
             </Col>
           </Form.Group>
+          <Form.Group as={Row} hidden={this.userAttributes.length === 0}>
+            <Form.Label column sm="2" htmlFor="location-allowed-attribute">
+              {this.props.t("allowedBookerAttributes")}
+            </Form.Label>
+            <Col sm="4">
+              {this.state.locationAllowAttributes.map((rule, i) => (
+                <InputGroup
+                  key={"allow-attribute-" + rule.attributeId + "-" + rule.value}
+                  size="sm"
+                  className="mb-1"
+                >
+                  <InputGroup.Text>
+                    {this.getUserAttributeLabel(rule.attributeId)}
+                  </InputGroup.Text>
+                  <Form.Control type="text" value={rule.value} readOnly />
+                  <Button
+                    variant="outline-secondary"
+                    onClick={() => this.removeLocationAllowAttribute(i)}
+                  >
+                    <IconDelete className="feather" />
+                  </Button>
+                </InputGroup>
+              ))}
+              <InputGroup size="sm">
+                <Form.Select
+                  id="location-allowed-attribute"
+                  value={this.state.newAllowAttributeId}
+                  onChange={(e: any) =>
+                    this.setState({ newAllowAttributeId: e.target.value })
+                  }
+                >
+                  <option value="">({this.props.t("none")})</option>
+                  {this.userAttributes.map((attribute) => (
+                    <option key={attribute.id} value={attribute.id}>
+                      {attribute.label}
+                    </option>
+                  ))}
+                </Form.Select>
+                <Form.Control
+                  type="text"
+                  placeholder={this.props.t("value")}
+                  value={this.state.newAllowAttributeValue}
+                  onChange={(e: any) =>
+                    this.setState({ newAllowAttributeValue: e.target.value })
+                  }
+                />
+                <Button
+                  variant="outline-secondary"
+                  onClick={this.addLocationAllowAttribute}
+                  disabled={
+                    !this.state.newAllowAttributeId ||
+                    !this.state.newAllowAttributeValue.trim()
+                  }
+                >
+                  {this.props.t("add")}
+                </Button>
+              </InputGroup>
+              <Form.Text className="text-muted">
+                {this.props.t("allowedBookerAttributesHint")}
+              </Form.Text>
+            </Col>
+          </Form.Group>
         </Form>
         {floorPlan}
         {attributeTable}
+        {this.entity.id && RuntimeConfig.INFOS.orgAdmin ? (
+          <LocationAdminSettings location={this.entity} groups={this.groups} />
+        ) : (
+          <></>
+        )}
         {spaceTable}
         {this.getEditSpaceDetailsModal()}
         <ConfirmModal
@@ -13,6 +13,8 @@ import withReadyRouter from "@/components/withReadyRouter";
 import { TranslationFunc, withTranslation } from "@/components/withTranslation";
 import Ajax from "@/util/Ajax";
 import Location from "@/types/Location";
+import Role from "@/types/Role";
+import RuntimeConfig from "@/components/RuntimeConfig";
 
 import RendererUtils from "@/util/RendererUtils";
 import Navigation from "@/util/Navigation";
@@ -48,7 +50,7 @@ class Locations extends React.Component<Props, State> {
   };
 
   loadItems = () => {
-    Location.list().then((list) => {
+    Location.list(Role.PERMISSION_MANAGE_SPACES).then((list) => {
       this.data = list;
       this.setState({ loading: false });
     });
@@ -126,18 +128,24 @@ class Locations extends React.Component<Props, State> {
     let buttons = (
       <>
         {this.data && this.data.length > 0 ? downloadButton : <></>}
-        <Link
-          href="/admin/attributes"
-          className="btn btn-sm btn-outline-secondary"
-        >
-          <IconTag className="feather" /> {this.props.t("attributes")}
-        </Link>
-        <Link
-          href="/admin/locations/add"
-          className="btn btn-sm btn-outline-secondary"
-        >
-          <IconPlus className="feather" /> {this.props.t("add")}
-        </Link>
+        {RuntimeConfig.isLocationScoped(Role.PERMISSION_MANAGE_SPACES) ? (
+          <></>
+        ) : (
+          <>
+            <Link
+              href="/admin/attributes"
+              className="btn btn-sm btn-outline-secondary"
+            >
+              <IconTag className="feather" /> {this.props.t("attributes")}
+            </Link>
+            <Link
+              href="/admin/locations/add"
+              className="btn btn-sm btn-outline-secondary"
+            >
+              <IconPlus className="feather" /> {this.props.t("add")}
+            </Link>
+          </>
+        )}
       </>
     );
 
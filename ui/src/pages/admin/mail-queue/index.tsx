@@ -0,0 +1,194 @@
+import React from "react";
+import { Alert, Button, Pagination, Table } from "react-bootstrap";
+import {
+  RefreshCw as IconRefresh,
+  Trash2 as IconDelete,
+} from "react-feather";
+import FullLayout from "@/components/FullLayout";
+import Loading from "@/components/Loading";
+import { NextRouter } from "next/router";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import FailedMail from "@/types/FailedMail";
+import Formatting from "@/util/Formatting";
+
+const PAGE_SIZE = 50;
+
+interface State {
+  loading: boolean;
+  page: number;
+  total: number;
+  pending: number;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class MailQueue extends React.Component<Props, State> {
+  data: FailedMail[] = [];
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      loading: true,
+      page: 0,
+      total: 0,
+      pending: 0,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadItems();
+  };
+
+  loadItems = async (page: number = 0) => {
+    const result = await FailedMail.list(PAGE_SIZE, page * PAGE_SIZE);
+    this.data = result.items;
+    this.setState({
+      loading: false,
+      total: result.total,
+      pending: result.pending,
+      page: page,
+    });
+  };
+
+  onPageSelect = (page: number) => {
+    this.setState({ loading: true });
+    this.loadItems(page);
+  };
+
+  onRetry = (item: FailedMail) => {
+    item.retry().then(() => this.loadItems(this.state.page));
+  };
+
+  onDelete = (item: FailedMail) => {
+    item.delete().then(() => this.loadItems(this.state.page));
+  };
+
+  renderItem = (item: FailedMail) => {
+    return (
+      <tr key={item.id}>
+        <td>
+          {item.lastAttempt
+            ? Formatting.getFormatterShort().format(item.lastAttempt)
+            : ""}
+        </td>
+        <td>{item.recipient}</td>
+        <td>{item.subject}</td>
+        <td>{item.attempts}</td>
+        <td>{item.error}</td>
+        <td className="text-end text-nowrap">
+          <Button
+            className="btn-sm"
+            variant="outline-secondary"
+            onClick={() => this.onRetry(item)}
+          >
+            <IconRefresh className="feather" /> {this.props.t("retry")}
+          </Button>{" "}
+          <Button
+            className="btn-sm"
+            variant="outline-secondary"
+            onClick={() => this.onDelete(item)}
+          >
+            <IconDelete className="feather" /> {this.props.t("delete")}
+          </Button>
+        </td>
+      </tr>
+    );
+  };
+
+  renderPagination = () => {
+    const numPages = Math.ceil(this.state.total / PAGE_SIZE);
+    if (numPages <= 1) {
+      return <></>;
+    }
+    const items = [];
+    for (let i = 0; i < numPages; i++) {
+      items.push(
+        <Pagination.Item
+          key={i}
+          active={i === this.state.page}
+          onClick={() => this.onPageSelect(i)}
+        >
+          {i + 1}
+        </Pagination.Item>,
+      );
+    }
+    return (
+      <Pagination>
+        <Pagination.Prev
+          disabled={this.state.page === 0}
+          onClick={() => this.onPageSelect(this.state.page - 1)}
+        />
+        {items}
+        <Pagination.Next
+          disabled={this.state.page >= numPages - 1}
+          onClick={() => this.onPageSelect(this.state.page + 1)}
+        />
+      </Pagination>
+    );
+  };
+
+  render() {
+    const buttons = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        onClick={() => this.onPageSelect(this.state.page)}
+      >
+        <IconRefresh className="feather" /> {this.props.t("refresh")}
+      </Button>
+    );
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("mailQueue")} buttons={buttons}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let pendingHint = <></>;
+    if (this.state.pending > 0) {
+      pendingHint = (
+        <Alert variant="info">
+          {this.props.t("mailQueuePending", { num: this.state.pending })}
+        </Alert>
+      );
+    }
+    if (this.data.length === 0) {
+      return (
+        <FullLayout headline={this.props.t("mailQueue")} buttons={buttons}>
+          {pendingHint}
+          <p>{this.props.t("noRecords")}</p>
+        </FullLayout>
+      );
+    }
+    return (
+      <FullLayout headline={this.props.t("mailQueue")} buttons={buttons}>
+        {pendingHint}
+        <Table striped={true} className="caption-top">
+          <caption>
+            {this.props.t("numRecords")}: {this.state.total}
+          </caption>
+          <thead>
+            <tr>
+              <th>{this.props.t("mailQueueLastAttempt")}</th>
+              <th>{this.props.t("mailQueueRecipient")}</th>
+              <th>{this.props.t("mailQueueSubject")}</th>
+              <th>{this.props.t("mailQueueAttempts")}</th>
+              <th>{this.props.t("error")}</th>
+              <th></th>
+            </tr>
+          </thead>
+          <tbody>{this.data.map((item) => this.renderItem(item))}</tbody>
+        </Table>
+        {this.renderPagination()}
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(MailQueue as any));
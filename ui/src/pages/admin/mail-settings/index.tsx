@@ -0,0 +1,495 @@
+import React from "react";
+import { Form, Col, Row, Button, Alert } from "react-bootstrap";
+import {
+  Save as IconSave,
+  Send as IconSend,
+  RotateCcw as IconReset,
+} from "react-feather";
+import { NextRouter } from "next/router";
+import FullLayout from "@/components/FullLayout";
+import Loading from "@/components/Loading";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import ConfirmModal from "@/components/ConfirmModal";
+import MailSettings, { TestMailResult } from "@/types/MailSettings";
+import AjaxError from "@/util/AjaxError";
+import ErrorText from "@/types/ErrorText";
+
+interface State {
+  loading: boolean;
+  saved: boolean;
+  error: boolean;
+  errorText: string;
+  custom: boolean;
+  ownServer: boolean;
+  smtpHost: string;
+  smtpPort: number;
+  smtpTlsMode: string;
+  smtpInsecureSkipVerify: boolean;
+  smtpAuth: boolean;
+  smtpAuthMethod: string;
+  smtpAuthUser: string;
+  smtpAuthPass: string;
+  senderAddress: string;
+  senderName: string;
+  replyTo: string;
+  testMailResult: TestMailResult | null;
+  sendingTestMail: boolean;
+  showResetConfirm: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class MailSettingsPage extends React.Component<Props, State> {
+  entity: MailSettings = new MailSettings();
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      loading: true,
+      saved: false,
+      error: false,
+      errorText: "",
+      custom: false,
+      ownServer: false,
+      smtpHost: "",
+      smtpPort: 587,
+      smtpTlsMode: "starttls",
+      smtpInsecureSkipVerify: false,
+      smtpAuth: false,
+      smtpAuthMethod: "PLAIN",
+      smtpAuthUser: "",
+      smtpAuthPass: "",
+      senderAddress: "",
+      senderName: "",
+      replyTo: "",
+      testMailResult: null,
+      sendingTestMail: false,
+      showResetConfirm: false,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadData();
+  };
+
+  loadData = () => {
+    MailSettings.get().then((settings) => {
+      this.entity = settings ?? new MailSettings();
+      this.setState({
+        loading: false,
+        custom: settings !== null,
+        ownServer: this.entity.smtpHost !== "",
+        smtpHost: this.entity.smtpHost,
+        smtpPort: this.entity.smtpHost ? this.entity.smtpPort : 587,
+        smtpTlsMode: this.entity.smtpTlsMode || "starttls",
+        smtpInsecureSkipVerify: this.entity.smtpInsecureSkipVerify,
+        smtpAuth: this.entity.smtpAuth,
+        smtpAuthMethod: this.entity.smtpAuthMethod || "PLAIN",
+        smtpAuthUser: this.entity.smtpAuthUser,
+        smtpAuthPass: "",
+        senderAddress: this.entity.senderAddress,
+        senderName: this.entity.senderName,
+        replyTo: this.entity.replyTo,
+      });
+    });
+  };
+
+  handleError = (e: any) => {
+    let text = this.props.t("errorSave");
+    if (e instanceof AjaxError && e.appErrorCode) {
+      text = ErrorText.getTextForAppCode(e.appErrorCode, this.props.t);
+    }
+    this.setState({ error: true, errorText: text });
+  };
+
+  // The stored password is only kept if it is sent to the same server and user
+  canKeepPassword = (): boolean => {
+    return (
+      this.entity.smtpAuthPassSet &&
+      this.state.smtpHost.toLowerCase() ===
+        this.entity.smtpHost.toLowerCase() &&
+      Number(this.state.smtpPort) === this.entity.smtpPort &&
+      this.state.smtpTlsMode === this.entity.smtpTlsMode &&
+      this.state.smtpAuthUser === this.entity.smtpAuthUser &&
+      (this.entity.smtpInsecureSkipVerify ||
+        !this.state.smtpInsecureSkipVerify)
+    );
+  };
+
+  onSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({ error: false, saved: false, testMailResult: null });
+    const ownServer = this.state.ownServer;
+    this.entity.smtpHost = ownServer ? this.state.smtpHost : "";
+    this.entity.smtpPort = ownServer ? Number(this.state.smtpPort) : 0;
+    this.entity.smtpTlsMode = ownServer ? this.state.smtpTlsMode : "";
+    this.entity.smtpInsecureSkipVerify =
+      ownServer && this.state.smtpInsecureSkipVerify;
+    this.entity.smtpAuth = ownServer && this.state.smtpAuth;
+    this.entity.smtpAuthMethod = this.state.smtpAuthMethod;
+    this.entity.smtpAuthUser = this.state.smtpAuthUser;
+    this.entity.smtpAuthPass = this.state.smtpAuthPass;
+    this.entity.senderAddress = ownServer ? this.state.senderAddress : "";
+    this.entity.senderName = this.state.senderName;
+    this.entity.replyTo = this.state.replyTo;
+    this.entity
+      .save()
+      .then(() => {
+        this.setState({ saved: true });
+        this.loadData();
+      })
+      .catch(this.handleError);
+  };
+
+  onSendTestMail = () => {
+    this.setState({
+      error: false,
+      saved: false,
+      testMailResult: null,
+      sendingTestMail: true,
+    });
+    MailSettings.sendTestMail()
+      .then((result) =>
+        this.setState({ testMailResult: result, sendingTestMail: false }),
+      )
+      .catch((e) => {
+        this.setState({ sendingTestMail: false });
+        this.handleError(e);
+      });
+  };
+
+  onReset = () => {
+    MailSettings.delete().then(() => {
+      this.setState({ loading: true, saved: false, error: false });
+      this.loadData();
+    });
+  };
+
+  renderTestMailResult = () => {
+    const result = this.state.testMailResult;
+    if (!result) {
+      return <></>;
+    }
+    if (result.success) {
+      return (
+        <Alert variant="success">
+          {this.props.t("mailSettingsTestMailSent", {
+            email: result.recipient,
+          })}
+        </Alert>
+      );
+    }
+    return (
+      <Alert variant="danger">
+        {this.props.t("mailSettingsTestMailFailed")}
+        <pre className="mb-0 mt-2">{result.error}</pre>
+      </Alert>
+    );
+  };
+
+  renderServerSettings = () => {
+    if (!this.state.ownServer) {
+      return <></>;
+    }
+    return (
+      <>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="smtpHost">
+            {this.props.t("mailSettingsSmtpHost")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Control
+              id="smtpHost"
+              type="text"
+              value={this.state.smtpHost}
+              maxLength={253}
+              onChange={(e: any) => this.setState({ smtpHost: e.target.value })}
+              required={true}
+            />
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="smtpPort">
+            {this.props.t("mailSettingsSmtpPort")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Control
+              id="smtpPort"
+              type="number"
+              min={1}
+              max={65535}
+              value={this.state.smtpPort}
+              onChange={(e: any) => this.setState({ smtpPort: e.target.value })}
+              required={true}
+            />
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="smtpTlsMode">
+            {this.props.t("mailSettingsSmtpTlsMode")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Select
+              id="smtpTlsMode"
+              value={this.state.smtpTlsMode}
+              onChange={(e: any) =>
+                this.setState({ smtpTlsMode: e.target.value })
+              }
+            >
+              {MailSettings.TLS_MODES.map((mode) => (
+                <option key={mode} value={mode}>
+                  {this.props.t("mailSettingsTlsMode_" + mode)}
+                </option>
+              ))}
+            </Form.Select>
+            <Form.Check
+              type="checkbox"
+              id="smtpInsecureSkipVerify"
+              label={this.props.t("mailSettingsSmtpInsecureSkipVerify")}
+              checked={this.state.smtpInsecureSkipVerify}
+              disabled={this.state.smtpTlsMode === "none"}
+              onChange={(e: any) =>
+                this.setState({ smtpInsecureSkipVerify: e.target.checked })
+              }
+            />
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2">
+            {this.props.t("mailSettingsSmtpAuth")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Check
+              type="switch"
+              id="smtpAuth"
+              label={this.props.t("mailSettingsSmtpAuthEnabled")}
+              checked={this.state.smtpAuth}
+              onChange={(e: any) =>
+                this.setState({ smtpAuth: e.target.checked })
+              }
+            />
+          </Col>
+        </Form.Group>
+        {this.state.smtpAuth ? this.renderAuthSettings() : <></>}
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="senderAddress">
+            {this.props.t("mailSettingsSenderAddress")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Control
+              id="senderAddress"
+              type="email"
+              value={this.state.senderAddress}
+              maxLength={254}
+              onChange={(e: any) =>
+                this.setState({ senderAddress: e.target.value })
+              }
+              required={true}
+            />
+          </Col>
+        </Form.Group>
+      </>
+    );
+  };
+
+  renderAuthSettings = () => {
+    return (
+      <>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="smtpAuthMethod">
+            {this.props.t("mailSettingsSmtpAuthMethod")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Select
+              id="smtpAuthMethod"
+              value={this.state.smtpAuthMethod}
+              onChange={(e: any) =>
+                this.setState({ smtpAuthMethod: e.target.value })
+              }
+            >
+              {MailSettings.AUTH_METHODS.map((method) => (
+                <option key={method} value={method}>
+                  {method}
+                </option>
+              ))}
+            </Form.Select>
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="smtpAuthUser">
+            {this.props.t("username")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Control
+              id="smtpAuthUser"
+              type="text"
+              autoComplete="off"
+              value={this.state.smtpAuthUser}
+              maxLength={256}
+              onChange={(e: any) =>
+                this.setState({ smtpAuthUser: e.target.value })
+              }
+              required={true}
+            />
+          </Col>
+        </Form.Group>
+        <Form.Group as={Row}>
+          <Form.Label column sm="2" htmlFor="smtpAuthPass">
+            {this.props.t("password")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Control
+              id="smtpAuthPass"
+              type="password"
+              autoComplete="new-password"
+              value={this.state.smtpAuthPass}
+              maxLength={256}
+              placeholder={
+                this.canKeepPassword()
+                  ? this.props.t("mailSettingsPasswordUnchanged")
+                  : ""
+              }
+              onChange={(e: any) =>
+                this.setState({ smtpAuthPass: e.target.value })
+              }
+              required={!this.canKeepPassword()}
+            />
+          </Col>
+        </Form.Group>
+      </>
+    );
+  };
+
+  render() {
+    const headline = this.props.t("mailSettings");
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={headline}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let hint = <></>;
+    if (this.state.saved) {
+      hint = <Alert variant="success">{this.props.t("entryUpdated")}</Alert>;
+    } else if (this.state.error) {
+      hint = <Alert variant="danger">{this.state.errorText}</Alert>;
+    }
+
+    let buttonReset = <></>;
+    if (this.state.custom) {
+      buttonReset = (
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          onClick={() => this.setState({ showResetConfirm: true })}
+        >
+          <IconReset className="feather" /> {this.props.t("mailSettingsReset")}
+        </Button>
+      );
+    }
+    const buttons = (
+      <>
+        {buttonReset}{" "}
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          onClick={this.onSendTestMail}
+          disabled={this.state.sendingTestMail}
+        >
+          <IconSend className="feather" />{" "}
+          {this.props.t("mailSettingsSendTestMail")}
+        </Button>{" "}
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          type="submit"
+          form="form"
+        >
+          <IconSave className="feather" /> {this.props.t("save")}
+        </Button>
+      </>
+    );
+
+    return (
+      <FullLayout headline={headline} buttons={buttons}>
+        <Form onSubmit={this.onSubmit} id="form">
+          {hint}
+          {this.renderTestMailResult()}
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("mailSettingsServer")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Check
+                type="radio"
+                id="server-global"
+                label={this.props.t("mailSettingsServerGlobal")}
+                checked={!this.state.ownServer}
+                onChange={() => this.setState({ ownServer: false })}
+              />
+              <Form.Check
+                type="radio"
+                id="server-own"
+                label={this.props.t("mailSettingsServerOwn")}
+                checked={this.state.ownServer}
+                onChange={() => this.setState({ ownServer: true })}
+              />
+            </Col>
+          </Form.Group>
+          {this.renderServerSettings()}
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="senderName">
+              {this.props.t("mailSettingsSenderName")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="senderName"
+                type="text"
+                value={this.state.senderName}
+                maxLength={78}
+                placeholder="Seatsurfing"
+                onChange={(e: any) =>
+                  this.setState({ senderName: e.target.value })
+                }
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="replyTo">
+              {this.props.t("mailSettingsReplyTo")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="replyTo"
+                type="email"
+                value={this.state.replyTo}
+                maxLength={254}
+                onChange={(e: any) =>
+                  this.setState({ replyTo: e.target.value })
+                }
+              />
+            </Col>
+          </Form.Group>
+        </Form>
+        <ConfirmModal
+          show={this.state.showResetConfirm}
+          message={this.props.t("confirmResetMailSettings")}
+          onCancel={() => this.setState({ showResetConfirm: false })}
+          onConfirm={() => {
+            this.setState({ showResetConfirm: false });
+            this.onReset();
+          }}
+        />
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(MailSettingsPage as any));
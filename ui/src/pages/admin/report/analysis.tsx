@@ -14,6 +14,8 @@ import { TranslationFunc, withTranslation } from "@/components/withTranslation";
 import DateUtil from "@/util/DateUtil";
 import Ajax from "@/util/Ajax";
 import Location from "@/types/Location";
+import Role from "@/types/Role";
+import UserAttribute, { UserAttributeStats } from "@/types/UserAttribute";
 
 import AjaxError from "@/util/AjaxError";
 import ErrorText from "@/types/ErrorText";
@@ -27,6 +29,8 @@ interface State {
   start: Date;
   end: Date;
   locationId: string;
+  attributeId: string;
+  attributeValue: string;
   error: boolean;
   errorCode: number;
 }
@@ -38,12 +42,16 @@ interface Props {
 
 class ReportAnalysis extends React.Component<Props, State> {
   locations: Location[];
+  attributes: UserAttribute[];
+  attributeStats: UserAttributeStats[];
   data: any;
   ExcellentExport: any;
 
   constructor(props: any) {
     super(props);
     this.locations = [];
+    this.attributes = [];
+    this.attributeStats = [];
     this.data = [];
     let end = new Date();
     let start = new Date();
@@ -53,6 +61,8 @@ class ReportAnalysis extends React.Component<Props, State> {
       start,
       end,
       locationId: "",
+      attributeId: "",
+      attributeValue: "",
       error: false,
       errorCode: 0,
     };
@@ -63,11 +73,26 @@ class ReportAnalysis extends React.Component<Props, State> {
       this.props.router.push("/404");
       return;
     }
-    Location.list().then((locations) => (this.locations = locations));
     import("excellentexport").then(
       (imp) => (this.ExcellentExport = imp.default),
     );
-    this.loadItems();
+    Promise.all([
+      Location.list(Role.PERMISSION_VIEW_REPORTS),
+      UserAttribute.list(),
+    ]).then(([locations, attributes]) => {
+      this.locations = locations;
+      this.attributes = attributes;
+      // Users limited to specific areas must select one of them
+      if (this.isLocationScoped() && locations.length > 0) {
+        this.setState({ locationId: locations[0].id }, this.loadItems);
+      } else {
+        this.loadItems();
+      }
+    });
+  };
+
+  isLocationScoped = (): boolean => {
+    return RuntimeConfig.isLocationScoped(Role.PERMISSION_VIEW_REPORTS);
   };
 
   loadItems = async () => {
@@ -82,9 +107,17 @@ class ReportAnalysis extends React.Component<Props, State> {
       "&end=" +
       encodeURIComponent(DateUtil.convertToFakeUTCDate(end).toISOString());
     params += "&locationId=" + encodeURIComponent(this.state.locationId);
+    if (this.state.attributeId) {
+      params += "&attributeId=" + encodeURIComponent(this.state.attributeId);
+      if (this.state.attributeValue) {
+        params +=
+          "&attributeValue=" + encodeURIComponent(this.state.attributeValue);
+      }
+    }
     try {
       const res = await Ajax.get("/booking/report/presence/?" + params);
       this.data = res.json;
+      this.attributeStats = await this.loadAttributeStats();
       this.setState({ loading: false });
     } catch (e: any) {
       const errorCode: number = AjaxError.getAppErrorCode(e);
@@ -92,8 +125,36 @@ class ReportAnalysis extends React.Component<Props, State> {
     }
   };
 
+  // Utilization by attribute value requires access to the statistics
+  loadAttributeStats = async (): Promise<UserAttributeStats[]> => {
+    const attribute = this.getSelectedAttribute();
+    if (
+      !attribute ||
+      RuntimeConfig.INFOS.hideStats ||
+      !RuntimeConfig.hasPermission(Role.PERMISSION_VIEW_REPORTS)
+    ) {
+      return [];
+    }
+    return attribute.getStats(this.state.locationId || null);
+  };
+
+  getSelectedAttribute = (): UserAttribute | undefined => {
+    return this.attributes.find((a) => a.id === this.state.attributeId);
+  };
+
+  // Returns the indices of the users, grouped by attribute value if selected
+  getUserOrder = (): number[] => {
+    const order: number[] = this.data.users.map((_: any, i: number) => i);
+    const values: string[] | undefined = this.data.attributeValues;
+    if (values) {
+      order.sort((a, b) => values[a].localeCompare(values[b]));
+    }
+    return order;
+  };
+
   getRows = () => {
-    return this.data.users.map((user: any, i: number) => {
+    return this.getUserOrder().map((i: number) => {
+      const user = this.data.users[i];
       let j = 0;
       let cols = this.data.presences[i].map((num: number) => {
         let val = num > 0 ? <IconCheck className="feather" /> : "-";
@@ -109,6 +170,11 @@ class ReportAnalysis extends React.Component<Props, State> {
           <td className="no-wrap" title={user.email}>
             {RendererUtils.fullname(user.firstname, user.lastname)}
           </td>
+          {this.data.attributeValues ? (
+            <td className="no-wrap">{this.data.attributeValues[i]}</td>
+          ) : (
+            <></>
+          )}
           {cols}
         </tr>
       );
@@ -222,7 +288,9 @@ class ReportAnalysis extends React.Component<Props, State> {
                 this.setState({ locationId: e.target.value })
               }
             >
-              <option value="">({this.props.t("all")})</option>
+              <option value="" hidden={this.isLocationScoped()}>
+                ({this.props.t("all")})
+              </option>
               {this.locations.map((location) => (
                 <option key={location.id} value={location.id}>
                   {location.name}
@@ -231,6 +299,37 @@ class ReportAnalysis extends React.Component<Props, State> {
             </Form.Select>
           </Col>
         </Form.Group>
+        <Form.Group as={Row} hidden={this.attributes.length === 0}>
+          <Form.Label column sm="2">
+            {this.props.t("groupBy")}
+          </Form.Label>
+          <Col sm="4">
+            <Form.Select
+              value={this.state.attributeId}
+              onChange={(e: any) =>
+                this.setState({ attributeId: e.target.value })
+              }
+            >
+              <option value="">({this.props.t("none")})</option>
+              {this.attributes.map((attribute) => (
+                <option key={attribute.id} value={attribute.id}>
+                  {attribute.label}
+                </option>
+              ))}
+            </Form.Select>
+          </Col>
+          <Col sm="4">
+            <Form.Control
+              type="text"
+              placeholder={this.props.t("filterByValue")}
+              value={this.state.attributeValue}
+              disabled={!this.state.attributeId}
+              onChange={(e: any) =>
+                this.setState({ attributeValue: e.target.value })
+              }
+            />
+          </Col>
+        </Form.Group>
       </Form>
     );
 
@@ -263,9 +362,40 @@ class ReportAnalysis extends React.Component<Props, State> {
       );
     }
 
+    const attribute = this.getSelectedAttribute();
+    let attributeStats = <></>;
+    if (attribute && this.attributeStats.length > 0) {
+      attributeStats = (
+        <Table striped={true} className="caption-top" responsive={true}>
+          <caption>
+            {this.props.t("utilizationBy", { attribute: attribute.label })}
+          </caption>
+          <thead>
+            <tr>
+              <th>{attribute.label}</th>
+              <th>{this.props.t("users")}</th>
+              <th>{this.props.t("bookers")}</th>
+              <th>{this.props.t("bookings")}</th>
+            </tr>
+          </thead>
+          <tbody>
+            {this.attributeStats.map((item) => (
+              <tr key={"stats-" + item.value}>
+                <td>{item.value || "-"}</td>
+                <td>{item.numUsers}</td>
+                <td>{item.numBookers}</td>
+                <td>{item.numBookings}</td>
+              </tr>
+            ))}
+          </tbody>
+        </Table>
+      );
+    }
+
     return (
       <FullLayout headline={this.props.t("analysis")} buttons={buttons}>
         {form}
+        {attributeStats}
         <Table
           striped={true}
           hover={true}
@@ -276,6 +406,11 @@ class ReportAnalysis extends React.Component<Props, State> {
           <thead>
             <tr>
               <th className="no-wrap">{this.props.t("name")}</th>
+              {this.data.attributeValues && attribute ? (
+                <th className="no-wrap">{attribute.label}</th>
+              ) : (
+                <></>
+              )}
               {this.data.dates.map((date: string) => {
                 const d = new Date(date);
                 return (
@@ -0,0 +1,391 @@
+import React from "react";
+import { Form, Col, Row, Button, Alert, Table } from "react-bootstrap";
+import {
+  ChevronLeft as IconBack,
+  Save as IconSave,
+  Trash2 as IconDelete,
+} from "react-feather";
+import { NextRouter } from "next/router";
+import FullLayout from "@/components/FullLayout";
+import Link from "next/link";
+import Loading from "@/components/Loading";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import RuntimeConfig from "@/components/RuntimeConfig";
+import ConfirmModal from "@/components/ConfirmModal";
+import Role, { RoleAssignment } from "@/types/Role";
+import Group from "@/types/Group";
+import Location from "@/types/Location";
+import AjaxError from "@/util/AjaxError";
+import ErrorText from "@/types/ErrorText";
+
+interface State {
+  loading: boolean;
+  saved: boolean;
+  error: boolean;
+  errorText: string;
+  goBack: boolean;
+  name: string;
+  permissions: string[];
+  assignments: RoleAssignment[];
+  assignUserEmail: string;
+  assignGroupId: string;
+  assignLocationId: string;
+  assignError: string;
+  showDeleteConfirm: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class EditRole extends React.Component<Props, State> {
+  entity: Role = new Role();
+  groups: Group[] = [];
+  locations: Location[] = [];
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      loading: true,
+      saved: false,
+      error: false,
+      errorText: "",
+      goBack: false,
+      name: "",
+      permissions: [],
+      assignments: [],
+      assignUserEmail: "",
+      assignGroupId: "",
+      assignLocationId: "",
+      assignError: "",
+      showDeleteConfirm: false,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadData();
+  };
+
+  loadData = () => {
+    let promises: Promise<any>[] = [
+      Location.list().then((list) => (this.locations = list)),
+    ];
+    if (RuntimeConfig.INFOS.featureGroups) {
+      promises.push(Group.list().then((list) => (this.groups = list)));
+    }
+    const { id } = this.props.router.query;
+    if (id && typeof id === "string" && id !== "add") {
+      promises.push(
+        Role.get(id).then((role) => {
+          this.entity = role;
+          return this.loadAssignments();
+        }),
+      );
+    }
+    Promise.all(promises).then(() => {
+      this.setState({
+        name: this.entity.name,
+        permissions: this.entity.permissions,
+        loading: false,
+      });
+    });
+  };
+
+  loadAssignments = () => {
+    return this.entity.getAssignments().then((assignments) => {
+      this.setState({ assignments: assignments });
+    });
+  };
+
+  onSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({
+      error: false,
+      saved: false,
+    });
+    this.entity.name = this.state.name;
+    this.entity.permissions = this.state.permissions;
+    this.entity
+      .save()
+      .then(() => {
+        this.props.router.push("/admin/roles/" + this.entity.id);
+        this.setState({ saved: true });
+      })
+      .catch((e) => {
+        let text = this.props.t("errorSave");
+        if (e instanceof AjaxError && e.httpStatusCode === 409) {
+          text = this.props.t("errorRoleExists");
+        } else if (e instanceof AjaxError && e.appErrorCode) {
+          text = ErrorText.getTextForAppCode(e.appErrorCode, this.props.t);
+        }
+        this.setState({
+          error: true,
+          errorText: text,
+        });
+      });
+  };
+
+  setPermission = (permission: string, checked: boolean) => {
+    let permissions = this.state.permissions.filter((p) => p !== permission);
+    if (checked) {
+      permissions.push(permission);
+    }
+    this.setState({ permissions: permissions });
+  };
+
+  addAssignment = (e: any) => {
+    e.preventDefault();
+    let assignment = new RoleAssignment();
+    assignment.userEmail = this.state.assignGroupId
+      ? ""
+      : this.state.assignUserEmail;
+    assignment.groupId = this.state.assignGroupId;
+    assignment.locationId = this.state.assignLocationId;
+    this.setState({ assignError: "" });
+    this.entity
+      .addAssignment(assignment)
+      .then(() => {
+        this.setState({
+          assignUserEmail: "",
+          assignGroupId: "",
+          assignLocationId: "",
+        });
+        this.loadAssignments();
+      })
+      .catch((e) => {
+        let text = this.props.t("errorSave");
+        if (e instanceof AjaxError && e.httpStatusCode === 404) {
+          text = this.props.t("errorAssigneeNotFound");
+        } else if (e instanceof AjaxError && e.httpStatusCode === 409) {
+          text = this.props.t("errorAssignmentExists");
+        }
+        this.setState({ assignError: text });
+      });
+  };
+
+  removeAssignment = (assignment: RoleAssignment) => {
+    this.entity.removeAssignment(assignment).then(() => {
+      this.loadAssignments();
+    });
+  };
+
+  renderAssignment = (assignment: RoleAssignment) => {
+    return (
+      <tr key={assignment.id}>
+        <td>
+          {assignment.groupId
+            ? this.props.t("group") + ": " + assignment.groupName
+            : assignment.userEmail}
+        </td>
+        <td>
+          {assignment.locationId
+            ? assignment.locationName
+            : this.props.t("allAreas")}
+        </td>
+        <td className="text-end">
+          <Button
+            className="btn-sm"
+            variant="outline-secondary"
+            onClick={() => this.removeAssignment(assignment)}
+          >
+            <IconDelete className="feather" /> {this.props.t("remove")}
+          </Button>
+        </td>
+      </tr>
+    );
+  };
+
+  renderAssignments = () => {
+    return (
+      <>
+        <div
+          className="d-flex justify-content-between flex-wrap flex-md-nowrap align-items-center pt-3 pb-2 mb-3 border-bottom"
+          style={{ marginTop: "50px" }}
+        >
+          <h4>{this.props.t("assignments")}</h4>
+        </div>
+        {this.state.assignError ? (
+          <Alert variant="danger">{this.state.assignError}</Alert>
+        ) : (
+          <></>
+        )}
+        <Form onSubmit={this.addAssignment}>
+          <Form.Group as={Row}>
+            <Col sm="4">
+              <Form.Control
+                type="email"
+                placeholder={this.props.t("emailPlaceholder")}
+                value={this.state.assignUserEmail}
+                disabled={this.state.assignGroupId !== ""}
+                onChange={(e: any) =>
+                  this.setState({ assignUserEmail: e.target.value })
+                }
+                required={this.state.assignGroupId === ""}
+              />
+            </Col>
+            <Col sm="3" hidden={this.groups.length === 0}>
+              <Form.Select
+                value={this.state.assignGroupId}
+                onChange={(e: any) =>
+                  this.setState({ assignGroupId: e.target.value })
+                }
+              >
+                <option value="">({this.props.t("group")})</option>
+                {this.groups.map((group) => (
+                  <option key={group.id} value={group.id}>
+                    {group.name}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+            <Col sm="3">
+              <Form.Select
+                value={this.state.assignLocationId}
+                onChange={(e: any) =>
+                  this.setState({ assignLocationId: e.target.value })
+                }
+              >
+                <option value="">{this.props.t("allAreas")}</option>
+                {this.locations.map((location) => (
+                  <option key={location.id} value={location.id}>
+                    {location.name}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+            <Col sm="2">
+              <Button type="submit" variant="outline-secondary">
+                {this.props.t("add")}
+              </Button>
+            </Col>
+          </Form.Group>
+        </Form>
+        <Table hover>
+          <tbody>
+            {this.state.assignments.map((a) => this.renderAssignment(a))}
+          </tbody>
+        </Table>
+      </>
+    );
+  };
+
+  render() {
+    if (this.state.goBack) {
+      this.props.router.push("/admin/roles");
+      return <></>;
+    }
+
+    let backButton = (
+      <Link href="/admin/roles" className="btn btn-sm btn-outline-secondary">
+        <IconBack className="feather" /> {this.props.t("back")}
+      </Link>
+    );
+    let buttons = backButton;
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("editRole")} buttons={buttons}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let hint = <></>;
+    if (this.state.saved) {
+      hint = <Alert variant="success">{this.props.t("entryUpdated")}</Alert>;
+    } else if (this.state.error) {
+      hint = <Alert variant="danger">{this.state.errorText}</Alert>;
+    }
+
+    let buttonDelete = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        onClick={() => this.setState({ showDeleteConfirm: true })}
+      >
+        <IconDelete className="feather" /> {this.props.t("delete")}
+      </Button>
+    );
+    let buttonSave = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        type="submit"
+        form="form"
+      >
+        <IconSave className="feather" /> {this.props.t("save")}
+      </Button>
+    );
+    if (this.entity.id) {
+      buttons = (
+        <>
+          {backButton} {buttonDelete} {buttonSave}
+        </>
+      );
+    } else {
+      buttons = (
+        <>
+          {backButton} {buttonSave}
+        </>
+      );
+    }
+
+    return (
+      <FullLayout headline={this.props.t("editRole")} buttons={buttons}>
+        <Form onSubmit={this.onSubmit} id="form">
+          {hint}
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="name">
+              {this.props.t("name")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="name"
+                type="text"
+                value={this.state.name}
+                minLength={3}
+                onChange={(e: any) => this.setState({ name: e.target.value })}
+                required={true}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("permissions")}
+            </Form.Label>
+            <Col sm="6">
+              {Role.PERMISSIONS.map((permission) => (
+                <Form.Check
+                  key={permission}
+                  type="checkbox"
+                  id={"permission-" + permission}
+                  label={this.props.t("permission_" + permission)}
+                  checked={this.state.permissions.includes(permission)}
+                  onChange={(e: any) =>
+                    this.setPermission(permission, e.target.checked)
+                  }
+                />
+              ))}
+            </Col>
+          </Form.Group>
+        </Form>
+        {this.entity.id ? this.renderAssignments() : <></>}
+        <ConfirmModal
+          show={this.state.showDeleteConfirm}
+          message={this.props.t("confirmDeleteRole")}
+          onCancel={() => this.setState({ showDeleteConfirm: false })}
+          onConfirm={() => {
+            this.setState({ showDeleteConfirm: false });
+            this.entity.delete().then(() => {
+              this.setState({ goBack: true });
+            });
+          }}
+        />
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(EditRole as any));
@@ -0,0 +1,114 @@
+import React from "react";
+import { Table } from "react-bootstrap";
+import { Plus as IconPlus } from "react-feather";
+import FullLayout from "@/components/FullLayout";
+import Loading from "@/components/Loading";
+import Link from "next/link";
+import { NextRouter } from "next/router";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import Role from "@/types/Role";
+
+interface State {
+  selectedItem: string;
+  loading: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class Roles extends React.Component<Props, State> {
+  data: Role[] = [];
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      selectedItem: "",
+      loading: true,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadItems();
+  };
+
+  loadItems = () => {
+    Role.list().then((list) => {
+      this.data = list;
+      this.setState({ loading: false });
+    });
+  };
+
+  onItemSelect = (role: Role) => {
+    this.setState({ selectedItem: role.id });
+  };
+
+  renderItem = (role: Role) => {
+    return (
+      <tr key={role.id} onClick={() => this.onItemSelect(role)}>
+        <td>{role.name}</td>
+        <td>
+          {role.permissions
+            .map((p) => this.props.t("permission_" + p))
+            .join(", ")}
+        </td>
+      </tr>
+    );
+  };
+
+  render() {
+    if (this.state.selectedItem) {
+      this.props.router.push(`/admin/roles/${this.state.selectedItem}`);
+      return <></>;
+    }
+    const buttons = (
+      <Link
+        href="/admin/roles/add"
+        className="btn btn-sm btn-outline-secondary"
+      >
+        <IconPlus className="feather" /> {this.props.t("add")}
+      </Link>
+    );
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("roles")} buttons={buttons}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let rows = this.data.map((item) => this.renderItem(item));
+    if (rows.length === 0) {
+      return (
+        <FullLayout headline={this.props.t("roles")} buttons={buttons}>
+          <p>{this.props.t("noRecords")}</p>
+        </FullLayout>
+      );
+    }
+    return (
+      <FullLayout headline={this.props.t("roles")} buttons={buttons}>
+        <Table
+          striped={true}
+          hover={true}
+          className="clickable-table caption-top"
+        >
+          <caption>
+            {this.props.t("numRecords")}: {rows.length}
+          </caption>
+          <thead>
+            <tr>
+              <th>{this.props.t("name")}</th>
+              <th>{this.props.t("permissions")}</th>
+            </tr>
+          </thead>
+          <tbody>{rows}</tbody>
+        </Table>
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(Roles as any));
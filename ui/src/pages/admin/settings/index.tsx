@@ -30,10 +30,12 @@ import CloudFeatureHint from "@/components/CloudFeatureHint";
 import UrlInput from "@/components/form/UrlInput";
 import Domain from "@/types/Domain";
 import Organization from "@/types/Organization";
+import UserPreference from "@/types/UserPreference";
 import AuthProvider from "@/types/AuthProvider";
 import Ajax from "@/util/Ajax";
 import User from "@/types/User";
 import OrgSettings from "@/types/Settings";
+import Group from "@/types/Group";
 
 import CopyToClipboardButton from "@/components/CopyToClipboardButton";
 import ReloadModal from "@/components/ReloadModal";
@@ -42,6 +44,8 @@ import RendererUtils from "@/util/RendererUtils";
 import UpdateChecker from "@/util/UpdateChecker";
 import ConfirmModal from "@/components/ConfirmModal";
 import AlertModal from "@/components/AlertModal";
+import AjaxError from "@/util/AjaxError";
+import ErrorText from "@/types/ErrorText";
 
 interface State {
   allowAnyUser: boolean;
@@ -72,6 +76,7 @@ interface State {
   submitting: boolean;
   showSavedModal: boolean;
   error: boolean;
+  errorText: string;
   newDomain: string;
   domains: Domain[];
   latestVersion: any;
@@ -84,6 +89,26 @@ interface State {
   kioskModeEnabled: boolean;
   hideReports: boolean;
   hideStats: boolean;
+  allowMagicLinkLogin: boolean;
+  passwordMinLength: number;
+  passwordMinCharClasses: number;
+  passwordHistory: number;
+  passwordMaxAgeDays: number;
+  passwordCheckBreached: boolean;
+  networkAllowlistUsers: string;
+  networkAllowlistSpaceAdmins: string;
+  networkAllowlistOrgAdmins: string;
+  networkAllowlistServiceAccounts: string;
+  trustedNetworks: string;
+  require2faUntrustedNetworks: boolean;
+  suspiciousLoginNotifications: boolean;
+  chatApprovalWebhookUrl: string;
+  chatApprovalWebhookFormat: string;
+  approvalReminderHours: number;
+  approvalEscalationHours: number;
+  approvalEscalationGroupId: string;
+  approvalExpiryAction: string;
+  approvalExpiryHours: number;
   installId: string;
   removeDomainName: string | null;
   verifyDomainName: string | null;
@@ -99,6 +124,7 @@ interface Props {
 class Settings extends React.Component<Props, State> {
   org: Organization | null;
   authProviders: AuthProvider[];
+  groups: Group[];
   timezones: string[];
   maxConcurrentBookingsPerUserLastValue: number;
 
@@ -106,6 +132,7 @@ class Settings extends React.Component<Props, State> {
     super(props);
     this.org = null;
     this.authProviders = [];
+    this.groups = [];
     this.maxConcurrentBookingsPerUserLastValue = 1;
     this.timezones = [];
     this.state = {
@@ -137,6 +164,7 @@ class Settings extends React.Component<Props, State> {
       submitting: false,
       showSavedModal: false,
       error: false,
+      errorText: "",
       newDomain: "",
       domains: [],
       latestVersion: null,
@@ -149,6 +177,26 @@ class Settings extends React.Component<Props, State> {
       kioskModeEnabled: false,
       hideReports: false,
       hideStats: false,
+      allowMagicLinkLogin: false,
+      passwordMinLength: 8,
+      passwordMinCharClasses: 4,
+      passwordHistory: 0,
+      passwordMaxAgeDays: 0,
+      passwordCheckBreached: false,
+      networkAllowlistUsers: "",
+      networkAllowlistSpaceAdmins: "",
+      networkAllowlistOrgAdmins: "",
+      networkAllowlistServiceAccounts: "",
+      trustedNetworks: "",
+      require2faUntrustedNetworks: false,
+      suspiciousLoginNotifications: true,
+      chatApprovalWebhookUrl: "",
+      chatApprovalWebhookFormat: UserPreference.CHAT_FORMATS[0],
+      approvalReminderHours: 0,
+      approvalEscalationHours: 0,
+      approvalEscalationGroupId: "",
+      approvalExpiryAction: "",
+      approvalExpiryHours: 0,
       installId: "",
       removeDomainName: null,
       verifyDomainName: null,
@@ -162,6 +210,7 @@ class Settings extends React.Component<Props, State> {
       this.loadSettings(),
       this.loadItems(),
       this.loadAuthProviders(),
+      this.loadGroups(),
       this.loadTimezones(),
       this.checkUpdates(),
     ];
@@ -194,6 +243,12 @@ class Settings extends React.Component<Props, State> {
     });
   };
 
+  loadGroups = async (): Promise<void> => {
+    return Group.list().then((list) => {
+      this.groups = list;
+    });
+  };
+
   loadSettings = async (): Promise<void> => {
     return OrgSettings.list().then((settings) => {
       const state: any = {};
@@ -263,6 +318,46 @@ class Settings extends React.Component<Props, State> {
           state.hideReports = s.value === "1";
         if (s.name === Organization.PREF_HIDE_STATS)
           state.hideStats = s.value === "1";
+        if (s.name === Organization.PREF_ALLOW_MAGIC_LINK_LOGIN)
+          state.allowMagicLinkLogin = s.value === "1";
+        if (s.name === Organization.PREF_PASSWORD_MIN_LENGTH)
+          state.passwordMinLength = window.parseInt(s.value);
+        if (s.name === Organization.PREF_PASSWORD_MIN_CHAR_CLASSES)
+          state.passwordMinCharClasses = window.parseInt(s.value);
+        if (s.name === Organization.PREF_PASSWORD_HISTORY)
+          state.passwordHistory = window.parseInt(s.value);
+        if (s.name === Organization.PREF_PASSWORD_MAX_AGE_DAYS)
+          state.passwordMaxAgeDays = window.parseInt(s.value);
+        if (s.name === Organization.PREF_PASSWORD_CHECK_BREACHED)
+          state.passwordCheckBreached = s.value === "1";
+        if (s.name === Organization.PREF_NETWORK_ALLOWLIST_USERS)
+          state.networkAllowlistUsers = s.value;
+        if (s.name === Organization.PREF_NETWORK_ALLOWLIST_SPACE_ADMINS)
+          state.networkAllowlistSpaceAdmins = s.value;
+        if (s.name === Organization.PREF_NETWORK_ALLOWLIST_ORG_ADMINS)
+          state.networkAllowlistOrgAdmins = s.value;
+        if (s.name === Organization.PREF_NETWORK_ALLOWLIST_SERVICE_ACCOUNTS)
+          state.networkAllowlistServiceAccounts = s.value;
+        if (s.name === Organization.PREF_TRUSTED_NETWORKS)
+          state.trustedNetworks = s.value;
+        if (s.name === Organization.PREF_REQUIRE_2FA_UNTRUSTED_NETWORKS)
+          state.require2faUntrustedNetworks = s.value === "1";
+        if (s.name === Organization.PREF_SUSPICIOUS_LOGIN_NOTIFICATIONS)
+          state.suspiciousLoginNotifications = s.value === "1";
+        if (s.name === Organization.PREF_CHAT_APPROVAL_WEBHOOK_URL)
+          state.chatApprovalWebhookUrl = s.value;
+        if (s.name === Organization.PREF_CHAT_APPROVAL_WEBHOOK_FORMAT && s.value)
+          state.chatApprovalWebhookFormat = s.value;
+        if (s.name === Organization.PREF_APPROVAL_REMINDER_HOURS && s.value)
+          state.approvalReminderHours = window.parseInt(s.value);
+        if (s.name === Organization.PREF_APPROVAL_ESCALATION_HOURS && s.value)
+          state.approvalEscalationHours = window.parseInt(s.value);
+        if (s.name === Organization.PREF_APPROVAL_ESCALATION_GROUP_ID)
+          state.approvalEscalationGroupId = s.value;
+        if (s.name === Organization.PREF_APPROVAL_EXPIRY_ACTION)
+          state.approvalExpiryAction = s.value;
+        if (s.name === Organization.PREF_APPROVAL_EXPIRY_HOURS && s.value)
+          state.approvalExpiryHours = window.parseInt(s.value);
         if (s.name === Organization.PREF_SYS_INSTALL_ID)
           state.installId = s.value;
       });
@@ -319,6 +414,7 @@ class Settings extends React.Component<Props, State> {
     this.setState({
       submitting: true,
       error: false,
+      errorText: "",
     });
     const payload = [
       new OrgSettings(
@@ -442,6 +538,71 @@ class Settings extends React.Component<Props, State> {
         Organization.PREF_HIDE_STATS,
         this.state.hideStats ? "1" : "0",
       ),
+      new OrgSettings(
+        Organization.PREF_ALLOW_MAGIC_LINK_LOGIN,
+        this.state.allowMagicLinkLogin ? "1" : "0",
+      ),
+      new OrgSettings(
+        Organization.PREF_PASSWORD_MIN_LENGTH,
+        this.state.passwordMinLength.toString(),
+      ),
+      new OrgSettings(
+        Organization.PREF_PASSWORD_MIN_CHAR_CLASSES,
+        this.state.passwordMinCharClasses.toString(),
+      ),
+      new OrgSettings(
+        Organization.PREF_PASSWORD_HISTORY,
+        this.state.passwordHistory.toString(),
+      ),
+      new OrgSettings(
+        Organization.PREF_PASSWORD_MAX_AGE_DAYS,
+        this.state.passwordMaxAgeDays.toString(),
+      ),
+      new OrgSettings(
+        Organization.PREF_PASSWORD_CHECK_BREACHED,
+        this.state.passwordCheckBreached ? "1" : "0",
+      ),
+      new OrgSettings(Organization.PREF_NETWORK_ALLOWLIST_USERS, this.state.networkAllowlistUsers),
+      new OrgSettings(Organization.PREF_NETWORK_ALLOWLIST_SPACE_ADMINS, this.state.networkAllowlistSpaceAdmins),
+      new OrgSettings(Organization.PREF_NETWORK_ALLOWLIST_ORG_ADMINS, this.state.networkAllowlistOrgAdmins),
+      new OrgSettings(Organization.PREF_NETWORK_ALLOWLIST_SERVICE_ACCOUNTS, this.state.networkAllowlistServiceAccounts),
+      new OrgSettings(Organization.PREF_TRUSTED_NETWORKS, this.state.trustedNetworks),
+      new OrgSettings(
+        Organization.PREF_REQUIRE_2FA_UNTRUSTED_NETWORKS,
+        this.state.require2faUntrustedNetworks ? "1" : "0",
+      ),
+      new OrgSettings(
+        Organization.PREF_SUSPICIOUS_LOGIN_NOTIFICATIONS,
+        this.state.suspiciousLoginNotifications ? "1" : "0",
+      ),
+      new OrgSettings(
+        Organization.PREF_CHAT_APPROVAL_WEBHOOK_URL,
+        this.state.chatApprovalWebhookUrl,
+      ),
+      new OrgSettings(
+        Organization.PREF_CHAT_APPROVAL_WEBHOOK_FORMAT,
+        this.state.chatApprovalWebhookFormat,
+      ),
+      new OrgSettings(
+        Organization.PREF_APPROVAL_REMINDER_HOURS,
+        this.state.approvalReminderHours.toString(),
+      ),
+      new OrgSettings(
+        Organization.PREF_APPROVAL_ESCALATION_HOURS,
+        this.state.approvalEscalationHours.toString(),
+      ),
+      new OrgSettings(
+        Organization.PREF_APPROVAL_ESCALATION_GROUP_ID,
+        this.state.approvalEscalationGroupId,
+      ),
+      new OrgSettings(
+        Organization.PREF_APPROVAL_EXPIRY_ACTION,
+        this.state.approvalExpiryAction,
+      ),
+      new OrgSettings(
+        Organization.PREF_APPROVAL_EXPIRY_HOURS,
+        this.state.approvalExpiryHours.toString(),
+      ),
     ];
     try {
       await OrgSettings.setAll(payload);
@@ -449,10 +610,14 @@ class Settings extends React.Component<Props, State> {
         submitting: false,
         showSavedModal: true,
       });
-    } catch {
+    } catch (e) {
       this.setState({
         submitting: false,
         error: true,
+        errorText:
+          e instanceof AjaxError && e.appErrorCode
+            ? ErrorText.getTextForAppCode(e.appErrorCode, this.props.t)
+            : "",
       });
     }
   };
@@ -750,7 +915,9 @@ class Settings extends React.Component<Props, State> {
     }
 
     const hint = this.state.error ? (
-      <Alert variant="danger">{this.props.t("errorSave")}</Alert>
+      <Alert variant="danger">
+        {this.state.errorText || this.props.t("errorSave")}
+      </Alert>
     ) : (
       <></>
     );
@@ -937,6 +1104,229 @@ class Settings extends React.Component<Props, State> {
               </Form.Select>
             </Col>
           </Form.Group>
+          <Form.Group as={Row}>
+            <Col sm="6">
+              <Form.Check
+                type="checkbox"
+                id="check-allowMagicLinkLogin"
+                label={this.props.t("allowMagicLinkLogin")}
+                checked={this.state.allowMagicLinkLogin}
+                onChange={(e: any) =>
+                  this.setState({ allowMagicLinkLogin: e.target.checked })
+                }
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-passwordMinLength">
+              {this.props.t("passwordMinLength")}
+            </Form.Label>
+            <Col sm="4">
+              <InputGroup>
+                <Form.Control
+                  id="input-passwordMinLength"
+                  type="number"
+                  value={this.state.passwordMinLength}
+                  onChange={(e: any) =>
+                    this.setState({ passwordMinLength: e.target.value })
+                  }
+                  min="8"
+                  max="64"
+                />
+              </InputGroup>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-passwordMinCharClasses">
+              {this.props.t("passwordMinCharClasses")}
+            </Form.Label>
+            <Col sm="4">
+              <InputGroup>
+                <Form.Control
+                  id="input-passwordMinCharClasses"
+                  type="number"
+                  value={this.state.passwordMinCharClasses}
+                  onChange={(e: any) =>
+                    this.setState({ passwordMinCharClasses: e.target.value })
+                  }
+                  min="1"
+                  max="4"
+                />
+              </InputGroup>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-passwordHistory">
+              {this.props.t("passwordHistory")}
+            </Form.Label>
+            <Col sm="4">
+              <InputGroup>
+                <Form.Control
+                  id="input-passwordHistory"
+                  type="number"
+                  value={this.state.passwordHistory}
+                  onChange={(e: any) =>
+                    this.setState({ passwordHistory: e.target.value })
+                  }
+                  min="0"
+                  max="24"
+                />
+              </InputGroup>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-passwordMaxAgeDays">
+              {this.props.t("passwordMaxAgeDays")}
+            </Form.Label>
+            <Col sm="4">
+              <InputGroup>
+                <Form.Control
+                  id="input-passwordMaxAgeDays"
+                  type="number"
+                  value={this.state.passwordMaxAgeDays}
+                  onChange={(e: any) =>
+                    this.setState({ passwordMaxAgeDays: e.target.value })
+                  }
+                  min="0"
+                  max="9999"
+                />
+                <InputGroup.Text>{this.props.t("days")}</InputGroup.Text>
+              </InputGroup>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Col sm="6">
+              <Form.Check
+                type="checkbox"
+                id="check-passwordCheckBreached"
+                label={this.props.t("passwordCheckBreached")}
+                checked={this.state.passwordCheckBreached}
+                onChange={(e: any) =>
+                  this.setState({ passwordCheckBreached: e.target.checked })
+                }
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-networkAllowlistUsers">
+              {this.props.t("networkAllowlistUsers")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="input-networkAllowlistUsers"
+                as="textarea"
+                rows={2}
+                value={this.state.networkAllowlistUsers}
+                onChange={(e: any) =>
+                  this.setState({ networkAllowlistUsers: e.target.value })
+                }
+                placeholder="192.0.2.0/24, 2001:db8::/32"
+                maxLength={2048}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-networkAllowlistSpaceAdmins">
+              {this.props.t("networkAllowlistSpaceAdmins")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="input-networkAllowlistSpaceAdmins"
+                as="textarea"
+                rows={2}
+                value={this.state.networkAllowlistSpaceAdmins}
+                onChange={(e: any) =>
+                  this.setState({ networkAllowlistSpaceAdmins: e.target.value })
+                }
+                placeholder="192.0.2.0/24, 2001:db8::/32"
+                maxLength={2048}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-networkAllowlistOrgAdmins">
+              {this.props.t("networkAllowlistOrgAdmins")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="input-networkAllowlistOrgAdmins"
+                as="textarea"
+                rows={2}
+                value={this.state.networkAllowlistOrgAdmins}
+                onChange={(e: any) =>
+                  this.setState({ networkAllowlistOrgAdmins: e.target.value })
+                }
+                placeholder="192.0.2.0/24, 2001:db8::/32"
+                maxLength={2048}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-networkAllowlistServiceAccounts">
+              {this.props.t("networkAllowlistServiceAccounts")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="input-networkAllowlistServiceAccounts"
+                as="textarea"
+                rows={2}
+                value={this.state.networkAllowlistServiceAccounts}
+                onChange={(e: any) =>
+                  this.setState({ networkAllowlistServiceAccounts: e.target.value })
+                }
+                placeholder="192.0.2.0/24, 2001:db8::/32"
+                maxLength={2048}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-trustedNetworks">
+              {this.props.t("trustedNetworks")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="input-trustedNetworks"
+                as="textarea"
+                rows={2}
+                value={this.state.trustedNetworks}
+                onChange={(e: any) =>
+                  this.setState({ trustedNetworks: e.target.value })
+                }
+                placeholder="192.0.2.0/24, 2001:db8::/32"
+                maxLength={2048}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Col sm="6">
+              <Form.Check
+                type="checkbox"
+                id="check-require2faUntrustedNetworks"
+                label={this.props.t("require2faUntrustedNetworks")}
+                checked={this.state.require2faUntrustedNetworks}
+                onChange={(e: any) =>
+                  this.setState({
+                    require2faUntrustedNetworks: e.target.checked,
+                  })
+                }
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Col sm="6">
+              <Form.Check
+                type="checkbox"
+                id="check-suspiciousLoginNotifications"
+                label={this.props.t("suspiciousLoginNotifications")}
+                checked={this.state.suspiciousLoginNotifications}
+                onChange={(e: any) =>
+                  this.setState({
+                    suspiciousLoginNotifications: e.target.checked,
+                  })
+                }
+              />
+            </Col>
+          </Form.Group>
           <Form.Group as={Row}>
             <Form.Label column sm="2" htmlFor="input-defaultTimezone">
               {this.props.t("defaultTimezone")}
@@ -1353,6 +1743,154 @@ class Settings extends React.Component<Props, State> {
               />
             </Col>
           </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-chatApprovalWebhookUrl">
+              {this.props.t("chatApprovalWebhookUrl")}
+            </Form.Label>
+            <Col sm="4">
+              <UrlInput
+                id="input-chatApprovalWebhookUrl"
+                value={this.state.chatApprovalWebhookUrl}
+                onChange={(e: any) =>
+                  this.setState({ chatApprovalWebhookUrl: e.target.value })
+                }
+                maxLength={512}
+              />
+              <Form.Text>{this.props.t("chatApprovalWebhookUrlHint")}</Form.Text>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label
+              column
+              sm="2"
+              htmlFor="input-chatApprovalWebhookFormat"
+            >
+              {this.props.t("chatWebhookFormat")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Select
+                id="input-chatApprovalWebhookFormat"
+                value={this.state.chatApprovalWebhookFormat}
+                onChange={(e: any) =>
+                  this.setState({ chatApprovalWebhookFormat: e.target.value })
+                }
+              >
+                {UserPreference.CHAT_FORMATS.map((format) => (
+                  <option key={format} value={format}>
+                    {this.props.t("chatFormat_" + format)}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-approvalReminderHours">
+              {this.props.t("approvalReminderHours")}
+            </Form.Label>
+            <Col sm="4">
+              <InputGroup>
+                <Form.Control
+                  id="input-approvalReminderHours"
+                  type="number"
+                  value={this.state.approvalReminderHours}
+                  onChange={(e: any) =>
+                    this.setState({ approvalReminderHours: e.target.value })
+                  }
+                  min="0"
+                  max="9999"
+                />
+                <InputGroup.Text>{this.props.t("hours")}</InputGroup.Text>
+              </InputGroup>
+              <Form.Text>{this.props.t("approvalReminderHoursHint")}</Form.Text>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-approvalEscalationHours">
+              {this.props.t("approvalEscalationHours")}
+            </Form.Label>
+            <Col sm="4">
+              <InputGroup>
+                <Form.Control
+                  id="input-approvalEscalationHours"
+                  type="number"
+                  value={this.state.approvalEscalationHours}
+                  onChange={(e: any) =>
+                    this.setState({ approvalEscalationHours: e.target.value })
+                  }
+                  min="0"
+                  max="9999"
+                />
+                <InputGroup.Text>{this.props.t("hours")}</InputGroup.Text>
+              </InputGroup>
+              <Form.Text>{this.props.t("approvalEscalationHoursHint")}</Form.Text>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label
+              column
+              sm="2"
+              htmlFor="input-approvalEscalationGroupId"
+            >
+              {this.props.t("approvalEscalationGroup")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Select
+                id="input-approvalEscalationGroupId"
+                value={this.state.approvalEscalationGroupId}
+                onChange={(e: any) =>
+                  this.setState({ approvalEscalationGroupId: e.target.value })
+                }
+              >
+                <option value="">{this.props.t("none")}</option>
+                {this.groups.map((group) => (
+                  <option key={group.id} value={group.id}>
+                    {group.name}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-approvalExpiryAction">
+              {this.props.t("approvalExpiryAction")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Select
+                id="input-approvalExpiryAction"
+                value={this.state.approvalExpiryAction}
+                onChange={(e: any) =>
+                  this.setState({ approvalExpiryAction: e.target.value })
+                }
+              >
+                {Organization.APPROVAL_EXPIRY_ACTIONS.map((action) => (
+                  <option key={action} value={action}>
+                    {this.props.t("approvalExpiryAction_" + (action || "none"))}
+                  </option>
+                ))}
+              </Form.Select>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="input-approvalExpiryHours">
+              {this.props.t("approvalExpiryHours")}
+            </Form.Label>
+            <Col sm="4">
+              <InputGroup>
+                <Form.Control
+                  id="input-approvalExpiryHours"
+                  type="number"
+                  value={this.state.approvalExpiryHours}
+                  onChange={(e: any) =>
+                    this.setState({ approvalExpiryHours: e.target.value })
+                  }
+                  min="0"
+                  max="9999"
+                />
+                <InputGroup.Text>{this.props.t("hours")}</InputGroup.Text>
+              </InputGroup>
+              <Form.Text>{this.props.t("approvalExpiryHoursHint")}</Form.Text>
+            </Col>
+          </Form.Group>
 
           {/* REPORTS */}
 
@@ -0,0 +1,255 @@
+import React from "react";
+import { Form, Col, Row, Button, Alert } from "react-bootstrap";
+import {
+  ChevronLeft as IconBack,
+  Save as IconSave,
+  Trash2 as IconDelete,
+} from "react-feather";
+import { NextRouter } from "next/router";
+import FullLayout from "@/components/FullLayout";
+import Loading from "@/components/Loading";
+import Link from "next/link";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import UserAttribute from "@/types/UserAttribute";
+import ConfirmModal from "@/components/ConfirmModal";
+import AjaxError from "@/util/AjaxError";
+
+interface State {
+  loading: boolean;
+  submitting: boolean;
+  saved: boolean;
+  error: boolean;
+  conflict: boolean;
+  goBack: boolean;
+  label: string;
+  type: number;
+  claimName: string;
+  showDeleteConfirm: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class EditUserAttribute extends React.Component<Props, State> {
+  entity: UserAttribute = new UserAttribute();
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      loading: true,
+      submitting: false,
+      saved: false,
+      error: false,
+      conflict: false,
+      goBack: false,
+      label: "",
+      type: UserAttribute.TYPE_STRING,
+      claimName: "",
+      showDeleteConfirm: false,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadData();
+  };
+
+  loadData = () => {
+    const { id } = this.props.router.query;
+    if (id && typeof id === "string" && id !== "add") {
+      UserAttribute.get(id).then((e) => {
+        this.entity = e;
+        this.setState({
+          label: e.label,
+          type: Number(e.type),
+          claimName: e.claimName,
+          loading: false,
+        });
+      });
+    } else {
+      this.setState({ loading: false });
+    }
+  };
+
+  onSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({
+      error: false,
+      conflict: false,
+      saved: false,
+    });
+    this.entity.label = this.state.label;
+    this.entity.type = Number(this.state.type);
+    this.entity.claimName = this.state.claimName;
+    this.entity
+      .save()
+      .then(() => {
+        this.props.router.push("/admin/user-attributes/" + this.entity.id);
+        this.setState({ saved: true });
+      })
+      .catch((e) => {
+        if (e instanceof AjaxError && e.httpStatusCode === 409) {
+          this.setState({ conflict: true });
+        } else {
+          this.setState({ error: true });
+        }
+      });
+  };
+
+  deleteItem = () => {
+    this.setState({ showDeleteConfirm: true });
+  };
+
+  render() {
+    if (this.state.goBack) {
+      this.props.router.push("/admin/user-attributes");
+      return <></>;
+    }
+
+    const backButton = (
+      <Link
+        href="/admin/user-attributes"
+        className="btn btn-sm btn-outline-secondary"
+      >
+        <IconBack className="feather" /> {this.props.t("back")}
+      </Link>
+    );
+    let buttons = backButton;
+
+    if (this.state.loading) {
+      return (
+        <FullLayout
+          headline={this.props.t("editUserAttribute")}
+          buttons={buttons}
+        >
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let hint = <></>;
+    if (this.state.saved) {
+      hint = <Alert variant="success">{this.props.t("entryUpdated")}</Alert>;
+    } else if (this.state.conflict) {
+      hint = (
+        <Alert variant="danger">
+          {this.props.t("errorManagerAttributeExists")}
+        </Alert>
+      );
+    } else if (this.state.error) {
+      hint = <Alert variant="danger">{this.props.t("errorSave")}</Alert>;
+    }
+
+    const buttonDelete = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        onClick={this.deleteItem}
+        disabled={false}
+      >
+        <IconDelete className="feather" /> {this.props.t("delete")}
+      </Button>
+    );
+    const buttonSave = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        type="submit"
+        form="form"
+      >
+        <IconSave className="feather" /> {this.props.t("save")}
+      </Button>
+    );
+    if (this.entity.id) {
+      buttons = (
+        <>
+          {backButton} {buttonDelete} {buttonSave}
+        </>
+      );
+    } else {
+      buttons = (
+        <>
+          {backButton} {buttonSave}
+        </>
+      );
+    }
+
+    return (
+      <FullLayout
+        headline={this.props.t("editUserAttribute")}
+        buttons={buttons}
+      >
+        <Form onSubmit={this.onSubmit} id="form">
+          {hint}
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("name")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                type="text"
+                value={this.state.label}
+                onChange={(e: any) => this.setState({ label: e.target.value })}
+                required={true}
+                autoFocus={true}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("type")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Select
+                value={this.state.type}
+                onChange={(e: any) => this.setState({ type: e.target.value })}
+              >
+                <option value="1">{this.props.t("number")}</option>
+                <option value="2">{this.props.t("boolean")}</option>
+                <option value="3">{this.props.t("text")}</option>
+                <option value="10">{this.props.t("manager")}</option>
+              </Form.Select>
+              <Form.Text className="text-muted">
+                {Number(this.state.type) === UserAttribute.TYPE_MANAGER
+                  ? this.props.t("managerAttributeHint")
+                  : ""}
+              </Form.Text>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("claimName")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                type="text"
+                value={this.state.claimName}
+                onChange={(e: any) =>
+                  this.setState({ claimName: e.target.value })
+                }
+              />
+              <Form.Text className="text-muted">
+                {this.props.t("claimNameHint")}
+              </Form.Text>
+            </Col>
+          </Form.Group>
+        </Form>
+        <ConfirmModal
+          show={this.state.showDeleteConfirm}
+          message={this.props.t("confirmDeleteUserAttribute")}
+          onCancel={() => this.setState({ showDeleteConfirm: false })}
+          onConfirm={() => {
+            this.setState({ showDeleteConfirm: false });
+            this.entity.delete().then(() => {
+              this.setState({ goBack: true });
+            });
+          }}
+        />
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(EditUserAttribute as any));
@@ -0,0 +1,161 @@
+import React from "react";
+import { Table } from "react-bootstrap";
+import { Plus as IconPlus, Download as IconDownload } from "react-feather";
+import FullLayout from "@/components/FullLayout";
+import { NextRouter } from "next/router";
+import Link from "next/link";
+import Loading from "@/components/Loading";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import UserAttribute from "@/types/UserAttribute";
+
+interface State {
+  selectedItem: string;
+  loading: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class UserAttributes extends React.Component<Props, State> {
+  data: UserAttribute[] = [];
+  ExcellentExport: any;
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      selectedItem: "",
+      loading: true,
+    };
+  }
+
+  componentDidMount = async () => {
+    this.ExcellentExport = (await import("excellentexport")).default;
+    this.loadItems();
+  };
+
+  loadItems = async () => {
+    this.data = await UserAttribute.list();
+    this.setState({ loading: false });
+  };
+
+  onItemSelect = (e: UserAttribute) => {
+    this.setState({ selectedItem: e.id });
+  };
+
+  getTextForType = (type: Number) => {
+    if (type === 1) return this.props.t("number");
+    if (type === 2) return this.props.t("boolean");
+    if (type === 3) return this.props.t("text");
+    if (type === 10) return this.props.t("manager");
+    return "";
+  };
+
+  renderItem = (e: UserAttribute) => {
+    return (
+      <tr key={e.id} onClick={() => this.onItemSelect(e)}>
+        <td>{e.label}</td>
+        <td>{this.getTextForType(e.type)}</td>
+        <td>{e.claimName}</td>
+      </tr>
+    );
+  };
+
+  exportTable = (e: any) => {
+    const t = this.props.t;
+    const headers = [t("name"), t("type"), t("claimName")];
+    const rows = this.data.map((item) => [
+      item.label,
+      this.getTextForType(item.type),
+      item.claimName,
+    ]);
+    return this.ExcellentExport.convert(
+      {
+        anchor: e.target,
+        filename: "seatsurfing-user-attributes",
+        format: "xlsx",
+      },
+      [
+        {
+          name: "Seatsurfing User Attributes",
+          from: { array: [headers, ...rows] },
+        },
+      ],
+    );
+  };
+
+  render() {
+    if (this.state.selectedItem) {
+      this.props.router.push(
+        `/admin/user-attributes/${this.state.selectedItem}`,
+      );
+      return <></>;
+    }
+
+    // eslint-disable-next-line
+    const downloadButton = (
+      <a
+        download="seatsurfing-user-attributes.xlsx"
+        href="#"
+        className="btn btn-sm btn-outline-secondary"
+        onClick={this.exportTable}
+      >
+        <IconDownload className="feather" /> {this.props.t("download")}
+      </a>
+    );
+    const buttons = (
+      <>
+        {this.data && this.data.length > 0 ? downloadButton : <></>}
+        <Link
+          href="/admin/user-attributes/add"
+          className="btn btn-sm btn-outline-secondary"
+        >
+          <IconPlus className="feather" /> {this.props.t("add")}
+        </Link>
+      </>
+    );
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("userAttributes")} buttons={buttons}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    const rows = this.data.map((item) => this.renderItem(item));
+    if (rows.length === 0) {
+      return (
+        <FullLayout headline={this.props.t("userAttributes")} buttons={buttons}>
+          <p>{this.props.t("noRecords")}</p>
+        </FullLayout>
+      );
+    }
+    return (
+      <FullLayout headline={this.props.t("userAttributes")} buttons={buttons}>
+        <Table
+          striped={true}
+          hover={true}
+          className="clickable-table caption-top"
+          id="datatable"
+        >
+          <caption>
+            {this.props.t("numRecords")}: {rows.length}
+          </caption>
+          <thead>
+            <tr>
+              <th>{this.props.t("name")}</th>
+              <th>{this.props.t("type")}</th>
+              <th>{this.props.t("claimName")}</th>
+            </tr>
+          </thead>
+          <tbody>{rows}</tbody>
+        </Table>
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(UserAttributes as any));
@@ -26,6 +26,7 @@ import { TranslationFunc, withTranslation } from "@/components/withTranslation";
 import User from "@/types/User";
 import OrgSettings from "@/types/Settings";
 import Organization from "@/types/Organization";
+import UserAttribute from "@/types/UserAttribute";
 
 import AuthProvider from "@/types/AuthProvider";
 import ErrorText from "@/types/ErrorText";
@@ -64,6 +65,7 @@ interface State {
   generatedToken: string;
   lastActivity: Date | null;
   pendingConfirmAction: PendingConfirmAction | null;
+  attributeValues: Map<string, string>;
 }
 
 interface Props {
@@ -77,6 +79,8 @@ class EditUser extends React.Component<Props, State> {
   usersMax: number = 0;
   usersCur: number = -1;
   adminUserRole: number = 0;
+  attributes: UserAttribute[] = [];
+  originalAttributeValues: Map<string, string> = new Map<string, string>();
 
   constructor(props: any) {
     super(props);
@@ -106,6 +110,7 @@ class EditUser extends React.Component<Props, State> {
       generatedToken: "",
       lastActivity: null,
       pendingConfirmAction: null,
+      attributeValues: new Map<string, string>(),
     };
   }
 
@@ -128,6 +133,7 @@ class EditUser extends React.Component<Props, State> {
         return [me];
       }),
       AuthProvider.list(),
+      UserAttribute.list(),
     ];
     const { id } = this.props.router.query;
     if (id && typeof id === "string" && id !== "add") {
@@ -138,8 +144,9 @@ class EditUser extends React.Component<Props, State> {
       this.usersCur = values[1];
       this.adminUserRole = values[2][0].role;
       this.authProviders = values[3];
-      if (values.length >= 5) {
-        const user = values[4];
+      this.attributes = values[4];
+      if (values.length >= 6) {
+        const user = values[5];
         this.entity = user;
         // Determine auth method from user data
         let authMethod = User.AuthMethodPassword;
@@ -156,6 +163,13 @@ class EditUser extends React.Component<Props, State> {
         const apiTokenConfigured = isServiceAccount
           ? await User.getApiTokenStatus(user.id).catch(() => false)
           : false;
+        const attributeValues = new Map<string, string>();
+        if (this.attributes.length > 0) {
+          (await UserAttribute.getValues(user.id)).forEach((av) =>
+            attributeValues.set(av.attributeId, av.value),
+          );
+        }
+        this.originalAttributeValues = new Map(attributeValues);
         this.setState({
           email: user.email,
           originalEmail: user.email,
@@ -169,6 +183,7 @@ class EditUser extends React.Component<Props, State> {
           hasPasskeys: user.hasPasskeys,
           apiTokenConfigured,
           lastActivity: user.lastActivity,
+          attributeValues,
         });
       }
       this.setState({
@@ -214,6 +229,7 @@ class EditUser extends React.Component<Props, State> {
 
     try {
       await this.entity.save();
+      await this.saveAttributeValues();
       this.props.router.push(
         `/admin/users/${encodeURIComponent(this.entity.id)}`,
       );
@@ -236,6 +252,65 @@ class EditUser extends React.Component<Props, State> {
     }
   };
 
+  saveAttributeValues = async (): Promise<void> => {
+    const promises: Promise<void>[] = [];
+    this.attributes.forEach((a) => {
+      const value = (this.state.attributeValues.get(a.id) || "").trim();
+      const original = this.originalAttributeValues.get(a.id) || "";
+      if (value === original) {
+        return;
+      }
+      if (value) {
+        promises.push(UserAttribute.setValue(this.entity.id, a.id, value));
+      } else {
+        promises.push(UserAttribute.deleteValue(this.entity.id, a.id));
+      }
+    });
+    await Promise.all(promises);
+    this.originalAttributeValues = new Map(this.state.attributeValues);
+  };
+
+  setAttributeValue = (attributeId: string, value: string) => {
+    const attributeValues = new Map(this.state.attributeValues);
+    attributeValues.set(attributeId, value);
+    this.setState({ attributeValues });
+  };
+
+  getAttributeInput = (attribute: UserAttribute) => {
+    const value = this.state.attributeValues.get(attribute.id) || "";
+    if (attribute.type === UserAttribute.TYPE_BOOL) {
+      return (
+        <Form.Check
+          type="checkbox"
+          id={"user-attribute-" + attribute.id}
+          label={RendererUtils.capitalize(this.props.t("yes"))}
+          checked={value === "1"}
+          disabled={attribute.claimName !== ""}
+          onChange={(e: any) =>
+            this.setAttributeValue(attribute.id, e.target.checked ? "1" : "0")
+          }
+        />
+      );
+    }
+    return (
+      <Form.Control
+        id={"user-attribute-" + attribute.id}
+        type={
+          attribute.type === UserAttribute.TYPE_INT
+            ? "number"
+            : attribute.type === UserAttribute.TYPE_MANAGER
+              ? "email"
+              : "text"
+        }
+        value={value}
+        readOnly={attribute.claimName !== ""}
+        onChange={(e: any) =>
+          this.setAttributeValue(attribute.id, e.target.value)
+        }
+      />
+    );
+  };
+
   deleteItem = () => {
     this.setState({ pendingConfirmAction: "delete" });
   };
@@ -750,6 +825,32 @@ class EditUser extends React.Component<Props, State> {
             </Col>
           </Form.Group>
 
+          {/* Organization-defined profile attributes */}
+          {this.entity.id &&
+            !this.isServiceAccount(this.state.role) &&
+            this.attributes.map((attribute) => (
+              <Form.Group as={Row} key={"attribute-" + attribute.id}>
+                <Form.Label
+                  htmlFor={"user-attribute-" + attribute.id}
+                  column
+                  sm="2"
+                >
+                  {attribute.label}
+                </Form.Label>
+                <Col sm="4">
+                  {this.getAttributeInput(attribute)}
+                  <Form.Text
+                    className="text-muted"
+                    hidden={attribute.claimName === ""}
+                  >
+                    {this.props.t("syncedFromClaim", {
+                      claim: attribute.claimName,
+                    })}
+                  </Form.Text>
+                </Col>
+              </Form.Group>
+            ))}
+
           {/* Second factor reset — only shown for existing non-service-account users */}
           <Form.Group
             as={Row}
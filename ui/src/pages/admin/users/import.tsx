@@ -0,0 +1,203 @@
+import React from "react";
+import { Form, Col, Row, Button, Alert, Table } from "react-bootstrap";
+import {
+  ChevronLeft as IconBack,
+  CheckCircle as IconValidate,
+  Upload as IconUpload,
+} from "react-feather";
+import { NextRouter } from "next/router";
+import FullLayout from "@/components/FullLayout";
+import Link from "next/link";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import User, { UserImportError, UserImportResult } from "@/types/User";
+import AjaxError from "@/util/AjaxError";
+
+interface State {
+  submitting: boolean;
+  data: string;
+  sendInvitation: boolean;
+  result: UserImportResult | null;
+  error: boolean;
+  limitExceeded: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class ImportUsers extends React.Component<Props, State> {
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      submitting: false,
+      data: "",
+      sendInvitation: true,
+      result: null,
+      error: false,
+      limitExceeded: false,
+    };
+  }
+
+  onFileSelected = (e: any) => {
+    const files: FileList | null = e.target.files;
+    if (!files || files.length === 0) {
+      return;
+    }
+    const file = files[0];
+    file.text().then((data) => {
+      this.setState({ data, result: null });
+    });
+  };
+
+  runImport = (dryRun: boolean) => {
+    this.setState({ submitting: true, error: false, limitExceeded: false });
+    User.importCSV(this.state.data, dryRun, this.state.sendInvitation)
+      .then((result) => {
+        this.setState({ result, submitting: false });
+      })
+      .catch((e) => {
+        this.setState({
+          submitting: false,
+          error: true,
+          limitExceeded: e instanceof AjaxError && e.httpStatusCode === 402,
+        });
+      });
+  };
+
+  getErrorText = (e: UserImportError): string => {
+    const [code, detail] = e.error.split(":", 2);
+    return this.props.t("importError_" + code, { detail: detail || "" });
+  };
+
+  renderResult = () => {
+    const result = this.state.result;
+    if (!result) {
+      return <></>;
+    }
+    if (result.errors.length === 0) {
+      return (
+        <Alert variant="success">
+          {result.dryRun
+            ? this.props.t("importValid", { num: result.numValid })
+            : this.props.t("importDone", { num: result.numCreated })}
+        </Alert>
+      );
+    }
+    return (
+      <>
+        <Alert variant="danger">
+          {this.props.t("importInvalid", { num: result.errors.length })}
+        </Alert>
+        <Table striped={true}>
+          <thead>
+            <tr>
+              <th>{this.props.t("line")}</th>
+              <th>{this.props.t("emailAddress")}</th>
+              <th>{this.props.t("error")}</th>
+            </tr>
+          </thead>
+          <tbody>
+            {result.errors.map((e, i) => (
+              <tr key={"error-" + i}>
+                <td>{e.line}</td>
+                <td>{e.email}</td>
+                <td>{this.getErrorText(e)}</td>
+              </tr>
+            ))}
+          </tbody>
+        </Table>
+      </>
+    );
+  };
+
+  render() {
+    const backButton = (
+      <Link href="/admin/users" className="btn btn-sm btn-outline-secondary">
+        <IconBack className="feather" /> {this.props.t("back")}
+      </Link>
+    );
+    const buttonValidate = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        onClick={() => this.runImport(true)}
+        disabled={!this.state.data || this.state.submitting}
+      >
+        <IconValidate className="feather" /> {this.props.t("validate")}
+      </Button>
+    );
+    const buttonImport = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        onClick={() => this.runImport(false)}
+        disabled={
+          !this.state.result ||
+          !this.state.result.dryRun ||
+          this.state.result.errors.length > 0 ||
+          this.state.submitting
+        }
+      >
+        <IconUpload className="feather" /> {this.props.t("import")}
+      </Button>
+    );
+    const buttons = (
+      <>
+        {backButton} {buttonValidate} {buttonImport}
+      </>
+    );
+
+    let hint = <></>;
+    if (this.state.limitExceeded) {
+      hint = (
+        <Alert variant="danger">
+          {this.props.t("errorSubscriptionLimit")}
+        </Alert>
+      );
+    } else if (this.state.error) {
+      hint = <Alert variant="danger">{this.props.t("errorSave")}</Alert>;
+    }
+
+    return (
+      <FullLayout headline={this.props.t("importUsers")} buttons={buttons}>
+        {hint}
+        <Form>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="import-file">
+              {this.props.t("csvFile")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="import-file"
+                type="file"
+                accept=".csv,text/csv"
+                onChange={this.onFileSelected}
+              />
+              <Form.Text className="text-muted">
+                {this.props.t("importUsersHint")}
+              </Form.Text>
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Col sm={{ span: 4, offset: 2 }}>
+              <Form.Check
+                type="checkbox"
+                id="import-send-invitation"
+                label={this.props.t("authMethodInvitation")}
+                checked={this.state.sendInvitation}
+                onChange={(e: any) =>
+                  this.setState({ sendInvitation: e.target.checked })
+                }
+              />
+            </Col>
+          </Form.Group>
+        </Form>
+        {this.renderResult()}
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(ImportUsers as any));
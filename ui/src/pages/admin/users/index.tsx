@@ -1,6 +1,10 @@
 import React from "react";
-import { Table } from "react-bootstrap";
-import { Plus as IconPlus, Download as IconDownload } from "react-feather";
+import { Table, Button } from "react-bootstrap";
+import {
+  Plus as IconPlus,
+  Download as IconDownload,
+  Upload as IconUpload,
+} from "react-feather";
 import FullLayout from "@/components/FullLayout";
 import Loading from "@/components/Loading";
 import Link from "next/link";
@@ -102,6 +106,19 @@ class Users extends React.Component<Props, State> {
     const buttons = (
       <>
         {this.data && this.data.length > 0 ? downloadButton : <></>}
+        <Button
+          className="btn-sm"
+          variant="outline-secondary"
+          onClick={() => User.exportCSV()}
+        >
+          <IconDownload className="feather" /> {this.props.t("exportCsv")}
+        </Button>
+        <Link
+          href="/admin/users/import"
+          className="btn btn-sm btn-outline-secondary"
+        >
+          <IconUpload className="feather" /> {this.props.t("import")}
+        </Link>
         <Link
           href="/admin/users/add"
           className="btn btn-sm btn-outline-secondary"
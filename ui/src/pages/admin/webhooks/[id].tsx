@@ -0,0 +1,491 @@
+import React from "react";
+import {
+  Form,
+  Col,
+  Row,
+  Button,
+  Alert,
+  Table,
+  Badge,
+  Modal,
+  InputGroup,
+} from "react-bootstrap";
+import {
+  ChevronLeft as IconBack,
+  Save as IconSave,
+  Trash2 as IconDelete,
+  Send as IconSend,
+  RefreshCw as IconRefresh,
+  Key as IconKey,
+} from "react-feather";
+import { NextRouter } from "next/router";
+import FullLayout from "@/components/FullLayout";
+import Link from "next/link";
+import Loading from "@/components/Loading";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import ConfirmModal from "@/components/ConfirmModal";
+import CopyToClipboardButton from "@/components/CopyToClipboardButton";
+import Webhook, { WebhookDelivery } from "@/types/Webhook";
+import Formatting from "@/util/Formatting";
+
+interface State {
+  loading: boolean;
+  saved: boolean;
+  error: boolean;
+  goBack: boolean;
+  name: string;
+  url: string;
+  events: string[];
+  enabled: boolean;
+  deliveries: WebhookDelivery[];
+  testResult: WebhookDelivery | null;
+  secret: string;
+  showDeleteConfirm: boolean;
+  showRotateConfirm: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class EditWebhook extends React.Component<Props, State> {
+  entity: Webhook = new Webhook();
+  availableEvents: string[] = [];
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      loading: true,
+      saved: false,
+      error: false,
+      goBack: false,
+      name: "",
+      url: "",
+      events: [],
+      enabled: true,
+      deliveries: [],
+      testResult: null,
+      secret: "",
+      showDeleteConfirm: false,
+      showRotateConfirm: false,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadData();
+  };
+
+  loadData = () => {
+    let promises: Promise<any>[] = [
+      Webhook.getEvents().then((list) => (this.availableEvents = list)),
+    ];
+    const { id } = this.props.router.query;
+    if (id && typeof id === "string" && id !== "add") {
+      promises.push(
+        Webhook.get(id).then((webhook) => {
+          this.entity = webhook;
+          return this.loadDeliveries();
+        }),
+      );
+    }
+    Promise.all(promises).then(() => {
+      this.setState({
+        name: this.entity.name,
+        url: this.entity.url,
+        events: this.entity.events,
+        enabled: this.entity.enabled,
+        loading: false,
+      });
+    });
+  };
+
+  loadDeliveries = () => {
+    return this.entity.getDeliveries().then((deliveries) => {
+      this.setState({ deliveries: deliveries });
+    });
+  };
+
+  onSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({
+      error: false,
+      saved: false,
+    });
+    const isNew = !this.entity.id;
+    this.entity.name = this.state.name;
+    this.entity.url = this.state.url;
+    this.entity.events = this.state.events;
+    this.entity.enabled = this.state.enabled;
+    this.entity
+      .save()
+      .then(() => {
+        this.props.router.push("/admin/webhooks/" + this.entity.id);
+        this.setState({
+          saved: true,
+          secret: isNew ? this.entity.secret : "",
+        });
+        if (!isNew) {
+          Webhook.get(this.entity.id).then((webhook) => {
+            this.entity = webhook;
+            this.forceUpdate();
+          });
+        }
+      })
+      .catch(() => {
+        this.setState({ error: true });
+      });
+  };
+
+  setEvent = (event: string, checked: boolean) => {
+    let events = this.state.events.filter((e) => e !== event);
+    if (checked) {
+      events.push(event);
+    }
+    this.setState({ events: events });
+  };
+
+  sendTestEvent = () => {
+    this.setState({ testResult: null });
+    this.entity.sendTestEvent().then((delivery) => {
+      this.setState({ testResult: delivery });
+      this.loadDeliveries();
+    });
+  };
+
+  rotateSecret = () => {
+    this.entity.rotateSecret().then((secret) => {
+      this.setState({ secret: secret });
+    });
+  };
+
+  retryDelivery = (delivery: WebhookDelivery) => {
+    delivery.retry().then(() => {
+      this.loadDeliveries();
+    });
+  };
+
+  renderDeliveryStatus = (delivery: WebhookDelivery) => {
+    if (delivery.status === WebhookDelivery.STATUS_SUCCEEDED) {
+      return <Badge bg="success">{this.props.t("successful")}</Badge>;
+    }
+    if (delivery.status === WebhookDelivery.STATUS_FAILED) {
+      return <Badge bg="danger">{this.props.t("failed")}</Badge>;
+    }
+    return <Badge bg="secondary">{this.props.t("pending")}</Badge>;
+  };
+
+  renderDelivery = (delivery: WebhookDelivery) => {
+    const canRetry =
+      delivery.status === WebhookDelivery.STATUS_FAILED &&
+      delivery.event !== "webhook.test" &&
+      this.entity.enabled;
+    return (
+      <tr key={delivery.id}>
+        <td>{Formatting.getFormatterShort().format(delivery.created)}</td>
+        <td>{delivery.event}</td>
+        <td>{this.renderDeliveryStatus(delivery)}</td>
+        <td>{delivery.attempts}</td>
+        <td>{delivery.responseCode || ""}</td>
+        <td>
+          {delivery.error}
+          {delivery.nextAttempt ? (
+            <div className="text-muted">
+              {this.props.t("webhookNextAttempt")}:{" "}
+              {Formatting.getFormatterShort().format(delivery.nextAttempt)}
+            </div>
+          ) : (
+            <></>
+          )}
+        </td>
+        <td className="text-end">
+          {canRetry ? (
+            <Button
+              className="btn-sm"
+              variant="outline-secondary"
+              onClick={() => this.retryDelivery(delivery)}
+            >
+              <IconRefresh className="feather" /> {this.props.t("retry")}
+            </Button>
+          ) : (
+            <></>
+          )}
+        </td>
+      </tr>
+    );
+  };
+
+  renderDeliveries = () => {
+    let testResult = <></>;
+    if (this.state.testResult) {
+      if (this.state.testResult.status === WebhookDelivery.STATUS_SUCCEEDED) {
+        testResult = (
+          <Alert variant="success">{this.props.t("webhookTestSucceeded")}</Alert>
+        );
+      } else {
+        testResult = (
+          <Alert variant="danger">
+            {this.props.t("webhookTestFailed")}: {this.state.testResult.error}
+          </Alert>
+        );
+      }
+    }
+    return (
+      <>
+        <div
+          className="d-flex justify-content-between flex-wrap flex-md-nowrap align-items-center pt-3 pb-2 mb-3 border-bottom"
+          style={{ marginTop: "50px" }}
+        >
+          <h4>{this.props.t("webhookDeliveries")}</h4>
+          <div className="btn-toolbar mb-2 mb-md-0">
+            <Button
+              className="btn-sm"
+              variant="outline-secondary"
+              onClick={() => this.loadDeliveries()}
+            >
+              <IconRefresh className="feather" /> {this.props.t("refresh")}
+            </Button>
+            &nbsp;
+            <Button
+              className="btn-sm"
+              variant="outline-secondary"
+              onClick={this.sendTestEvent}
+            >
+              <IconSend className="feather" />{" "}
+              {this.props.t("webhookSendTestEvent")}
+            </Button>
+          </div>
+        </div>
+        {testResult}
+        {this.state.deliveries.length === 0 ? (
+          <p>{this.props.t("noRecords")}</p>
+        ) : (
+          <Table hover>
+            <thead>
+              <tr>
+                <th>{this.props.t("created")}</th>
+                <th>{this.props.t("event")}</th>
+                <th>{this.props.t("webhookStatus")}</th>
+                <th>{this.props.t("webhookAttempts")}</th>
+                <th>{this.props.t("webhookResponseCode")}</th>
+                <th>{this.props.t("error")}</th>
+                <th></th>
+              </tr>
+            </thead>
+            <tbody>
+              {this.state.deliveries.map((d) => this.renderDelivery(d))}
+            </tbody>
+          </Table>
+        )}
+      </>
+    );
+  };
+
+  renderSecretModal = () => {
+    return (
+      <Modal
+        show={this.state.secret !== ""}
+        onHide={() => this.setState({ secret: "" })}
+      >
+        <Modal.Header closeButton>
+          <Modal.Title>{this.props.t("webhookSecret")}</Modal.Title>
+        </Modal.Header>
+        <Modal.Body>
+          <Alert variant="warning">
+            {this.props.t("webhookSecretOnceWarning")}
+          </Alert>
+          <InputGroup>
+            <Form.Control type="text" readOnly value={this.state.secret} />
+            <CopyToClipboardButton text={this.state.secret} />
+          </InputGroup>
+        </Modal.Body>
+        <Modal.Footer>
+          <Button
+            variant="secondary"
+            onClick={() => this.setState({ secret: "" })}
+          >
+            {this.props.t("close")}
+          </Button>
+        </Modal.Footer>
+      </Modal>
+    );
+  };
+
+  render() {
+    if (this.state.goBack) {
+      this.props.router.push("/admin/webhooks");
+      return <></>;
+    }
+
+    let backButton = (
+      <Link href="/admin/webhooks" className="btn btn-sm btn-outline-secondary">
+        <IconBack className="feather" /> {this.props.t("back")}
+      </Link>
+    );
+    let buttons = backButton;
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("editWebhook")} buttons={buttons}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let hint = <></>;
+    if (this.state.saved) {
+      hint = <Alert variant="success">{this.props.t("entryUpdated")}</Alert>;
+    } else if (this.state.error) {
+      hint = <Alert variant="danger">{this.props.t("errorSave")}</Alert>;
+    }
+    let disabledHint = <></>;
+    if (this.entity.disabledAt) {
+      disabledHint = (
+        <Alert variant="warning">
+          {this.props.t("webhookAutoDisabledHint", {
+            date: Formatting.getFormatterShort().format(
+              this.entity.disabledAt,
+            ),
+          })}
+        </Alert>
+      );
+    }
+
+    let buttonDelete = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        onClick={() => this.setState({ showDeleteConfirm: true })}
+      >
+        <IconDelete className="feather" /> {this.props.t("delete")}
+      </Button>
+    );
+    let buttonRotate = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        onClick={() => this.setState({ showRotateConfirm: true })}
+      >
+        <IconKey className="feather" /> {this.props.t("webhookRotateSecret")}
+      </Button>
+    );
+    let buttonSave = (
+      <Button
+        className="btn-sm"
+        variant="outline-secondary"
+        type="submit"
+        form="form"
+      >
+        <IconSave className="feather" /> {this.props.t("save")}
+      </Button>
+    );
+    if (this.entity.id) {
+      buttons = (
+        <>
+          {backButton} {buttonDelete} {buttonRotate} {buttonSave}
+        </>
+      );
+    } else {
+      buttons = (
+        <>
+          {backButton} {buttonSave}
+        </>
+      );
+    }
+
+    return (
+      <FullLayout headline={this.props.t("editWebhook")} buttons={buttons}>
+        <Form onSubmit={this.onSubmit} id="form">
+          {hint}
+          {disabledHint}
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="name">
+              {this.props.t("name")}
+            </Form.Label>
+            <Col sm="4">
+              <Form.Control
+                id="name"
+                type="text"
+                value={this.state.name}
+                maxLength={256}
+                onChange={(e: any) => this.setState({ name: e.target.value })}
+                required={true}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2" htmlFor="url">
+              {this.props.t("webhookUrl")}
+            </Form.Label>
+            <Col sm="6">
+              <Form.Control
+                id="url"
+                type="url"
+                placeholder="https://"
+                value={this.state.url}
+                maxLength={1024}
+                onChange={(e: any) => this.setState({ url: e.target.value })}
+                required={true}
+              />
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Form.Label column sm="2">
+              {this.props.t("webhookEvents")}
+            </Form.Label>
+            <Col sm="6">
+              {this.availableEvents.map((event) => (
+                <Form.Check
+                  key={event}
+                  type="checkbox"
+                  id={"event-" + event}
+                  label={event}
+                  checked={this.state.events.includes(event)}
+                  onChange={(e: any) => this.setEvent(event, e.target.checked)}
+                />
+              ))}
+            </Col>
+          </Form.Group>
+          <Form.Group as={Row}>
+            <Col sm={{ span: 6, offset: 2 }}>
+              <Form.Check
+                type="switch"
+                id="enabled"
+                label={this.props.t("enabled")}
+                checked={this.state.enabled}
+                onChange={(e: any) =>
+                  this.setState({ enabled: e.target.checked })
+                }
+              />
+            </Col>
+          </Form.Group>
+        </Form>
+        {this.entity.id ? this.renderDeliveries() : <></>}
+        {this.renderSecretModal()}
+        <ConfirmModal
+          show={this.state.showRotateConfirm}
+          message={this.props.t("confirmRotateWebhookSecret")}
+          onCancel={() => this.setState({ showRotateConfirm: false })}
+          onConfirm={() => {
+            this.setState({ showRotateConfirm: false });
+            this.rotateSecret();
+          }}
+        />
+        <ConfirmModal
+          show={this.state.showDeleteConfirm}
+          message={this.props.t("confirmDeleteWebhook")}
+          onCancel={() => this.setState({ showDeleteConfirm: false })}
+          onConfirm={() => {
+            this.setState({ showDeleteConfirm: false });
+            this.entity.delete().then(() => {
+              this.setState({ goBack: true });
+            });
+          }}
+        />
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(EditWebhook as any));
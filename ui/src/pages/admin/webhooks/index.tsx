@@ -0,0 +1,124 @@
+import React from "react";
+import { Badge, Table } from "react-bootstrap";
+import { Plus as IconPlus } from "react-feather";
+import FullLayout from "@/components/FullLayout";
+import Loading from "@/components/Loading";
+import Link from "next/link";
+import { NextRouter } from "next/router";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import Webhook from "@/types/Webhook";
+
+interface State {
+  selectedItem: string;
+  loading: boolean;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class Webhooks extends React.Component<Props, State> {
+  data: Webhook[] = [];
+
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      selectedItem: "",
+      loading: true,
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadItems();
+  };
+
+  loadItems = () => {
+    Webhook.list().then((list) => {
+      this.data = list;
+      this.setState({ loading: false });
+    });
+  };
+
+  onItemSelect = (webhook: Webhook) => {
+    this.setState({ selectedItem: webhook.id });
+  };
+
+  renderStatus = (webhook: Webhook) => {
+    if (webhook.disabledAt) {
+      return <Badge bg="danger">{this.props.t("webhookAutoDisabled")}</Badge>;
+    }
+    if (!webhook.enabled) {
+      return <Badge bg="secondary">{this.props.t("disabled")}</Badge>;
+    }
+    return <Badge bg="success">{this.props.t("enabled")}</Badge>;
+  };
+
+  renderItem = (webhook: Webhook) => {
+    return (
+      <tr key={webhook.id} onClick={() => this.onItemSelect(webhook)}>
+        <td>{webhook.name}</td>
+        <td>{webhook.url}</td>
+        <td>{webhook.events.join(", ")}</td>
+        <td>{this.renderStatus(webhook)}</td>
+      </tr>
+    );
+  };
+
+  render() {
+    if (this.state.selectedItem) {
+      this.props.router.push(`/admin/webhooks/${this.state.selectedItem}`);
+      return <></>;
+    }
+    const buttons = (
+      <Link
+        href="/admin/webhooks/add"
+        className="btn btn-sm btn-outline-secondary"
+      >
+        <IconPlus className="feather" /> {this.props.t("add")}
+      </Link>
+    );
+
+    if (this.state.loading) {
+      return (
+        <FullLayout headline={this.props.t("webhooks")} buttons={buttons}>
+          <Loading />
+        </FullLayout>
+      );
+    }
+
+    let rows = this.data.map((item) => this.renderItem(item));
+    if (rows.length === 0) {
+      return (
+        <FullLayout headline={this.props.t("webhooks")} buttons={buttons}>
+          <p>{this.props.t("noRecords")}</p>
+        </FullLayout>
+      );
+    }
+    return (
+      <FullLayout headline={this.props.t("webhooks")} buttons={buttons}>
+        <Table
+          striped={true}
+          hover={true}
+          className="clickable-table caption-top"
+        >
+          <caption>
+            {this.props.t("numRecords")}: {rows.length}
+          </caption>
+          <thead>
+            <tr>
+              <th>{this.props.t("name")}</th>
+              <th>{this.props.t("webhookUrl")}</th>
+              <th>{this.props.t("webhookEvents")}</th>
+              <th>{this.props.t("webhookStatus")}</th>
+            </tr>
+          </thead>
+          <tbody>{rows}</tbody>
+        </Table>
+      </FullLayout>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(Webhooks as any));
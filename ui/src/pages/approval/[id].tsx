@@ -0,0 +1,180 @@
+import React from "react";
+import { Button, Form } from "react-bootstrap";
+import { NextRouter } from "next/router";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import SeatsurfingLogo from "@/components/SeatsurfingLogo";
+import Loading from "@/components/Loading";
+import Ajax from "@/util/Ajax";
+import AjaxError from "@/util/AjaxError";
+import Formatting from "@/util/Formatting";
+import Navigation from "@/util/Navigation";
+import Booking from "@/types/Booking";
+
+type Result = "" | "approved" | "declined" | "invalid" | "gone" | "forbidden";
+
+interface State {
+  loading: boolean;
+  submitting: boolean;
+  booking: Booking | null;
+  approverEmail: string;
+  approve: boolean;
+  result: Result;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class ApprovalLink extends React.Component<Props, State> {
+  constructor(props: any) {
+    super(props);
+    this.state = {
+      loading: true,
+      submitting: false,
+      booking: null,
+      approverEmail: "",
+      approve: this.props.router.query["action"] !== "decline",
+      result: "",
+    };
+  }
+
+  componentDidMount = () => {
+    this.loadData();
+  };
+
+  getUrl = (): string => {
+    const { id } = this.props.router.query;
+    return Navigation.PATH_API_APPROVAL_LINK + id;
+  };
+
+  loadData = async () => {
+    try {
+      const res = await Ajax.get(this.getUrl(), () => true);
+      const booking = new Booking();
+      booking.deserialize(res.json.booking);
+      this.setState({
+        loading: false,
+        booking: booking,
+        approverEmail: res.json.approverEmail,
+      });
+    } catch (err) {
+      this.onError(err);
+    }
+  };
+
+  onSubmit = async (e: any) => {
+    e.preventDefault();
+    this.setState({ submitting: true });
+    const approved = this.state.approve;
+    try {
+      await Ajax.postData(this.getUrl(), { approved: approved }, () => true);
+      this.setState({
+        submitting: false,
+        result: approved ? "approved" : "declined",
+      });
+    } catch (err) {
+      this.onError(err);
+    }
+  };
+
+  onError = (err: any) => {
+    let result: Result = "invalid";
+    if (err instanceof AjaxError && err.httpStatusCode === 410) {
+      result = "gone";
+    } else if (err instanceof AjaxError && err.httpStatusCode === 403) {
+      result = "forbidden";
+    }
+    this.setState({ loading: false, submitting: false, result: result });
+  };
+
+  renderContent() {
+    if (this.state.loading) {
+      return <Loading showText={false} paddingTop={false} />;
+    }
+    if (this.state.result === "approved") {
+      return <p>{this.props.t("approvalLinkApproved")}</p>;
+    }
+    if (this.state.result === "declined") {
+      return <p>{this.props.t("approvalLinkDeclined")}</p>;
+    }
+    if (this.state.result === "gone") {
+      return <p>{this.props.t("approvalLinkGone")}</p>;
+    }
+    if (this.state.result === "forbidden") {
+      return <p>{this.props.t("approvalLinkForbidden")}</p>;
+    }
+    if (this.state.result === "invalid" || !this.state.booking) {
+      return <p>{this.props.t("approvalLinkInvalid")}</p>;
+    }
+    const booking = this.state.booking;
+    return (
+      <>
+        <p>
+          {this.props.t("approvalLinkIntro", {
+            email: this.state.approverEmail,
+          })}
+        </p>
+        <p>
+          {this.props.t("user")}: {booking.user.email}
+          <br />
+          {this.props.t("area")}: {booking.space.location.name}
+          <br />
+          {this.props.t("space")}: {booking.space.name}
+          <br />
+          {this.props.t("enter")}:{" "}
+          {Formatting.getFormatterShort().format(booking.enter)}
+          <br />
+          {this.props.t("leave")}:{" "}
+          {Formatting.getFormatterShort().format(booking.leave)}
+          {booking.subject ? (
+            <>
+              <br />
+              {this.props.t("subject")}: {booking.subject}
+            </>
+          ) : (
+            <></>
+          )}
+        </p>
+        <Form.Group>
+          <Form.Check
+            type="radio"
+            id="approval-link-approve"
+            label={this.props.t("approvalLinkApprove")}
+            checked={this.state.approve}
+            onChange={() => this.setState({ approve: true })}
+          />
+          <Form.Check
+            type="radio"
+            id="approval-link-decline"
+            label={this.props.t("approvalLinkDecline")}
+            checked={!this.state.approve}
+            onChange={() => this.setState({ approve: false })}
+          />
+        </Form.Group>
+        <Button
+          className="margin-top-10"
+          variant={this.state.approve ? "primary" : "danger"}
+          type="submit"
+          disabled={this.state.submitting}
+        >
+          {this.props.t("approvalLinkConfirm")}
+        </Button>
+      </>
+    );
+  }
+
+  render() {
+    return (
+      <div className="container-center">
+        <Form className="container-center-inner" onSubmit={this.onSubmit}>
+          <SeatsurfingLogo />
+          {this.renderContent()}
+        </Form>
+      </div>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(ApprovalLink as any));
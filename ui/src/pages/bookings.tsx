@@ -7,6 +7,7 @@ import {
   LogIn as IconEnter,
   LogOut as IconLeave,
   MapPin as IconLocation,
+  User as IconUser,
   Clock as IconPending,
   RefreshCw as IconRecurring,
 } from "react-feather";
@@ -56,12 +57,14 @@ interface Props {
 
 class Bookings extends React.Component<Props, State> {
   data: Booking[];
+  delegatedData: Booking[];
   workdayStart: string;
 
   constructor(props: any) {
     super(props);
     this.workdayStart = UserPreference.DEFAULT_WORKDAY_START;
     this.data = [];
+    this.delegatedData = [];
     this.state = {
       loading: true,
       deletingItem: false,
@@ -85,10 +88,12 @@ class Bookings extends React.Component<Props, State> {
   loadData = () => {
     Promise.all([
       Booking.list(),
+      Booking.listDelegated(),
       UserPreference.getOne(UserPreference.PREF_WORKDAY_START),
       UserPreference.getOne(UserPreference.PREF_WORKDAYS),
-    ]).then(([list, workDayStart, workdays]) => {
+    ]).then(([list, delegatedList, workDayStart, workdays]) => {
       this.data = list;
+      this.delegatedData = delegatedList;
       this.workdayStart = Validation.isValidTimeString(workDayStart)
         ? workDayStart
         : UserPreference.DEFAULT_WORKDAY_START;
@@ -152,7 +157,7 @@ class Bookings extends React.Component<Props, State> {
     );
   };
 
-  renderItem = (item: Booking) => {
+  renderItem = (item: Booking, delegated: boolean = false) => {
     const formatter = Formatting.getBookingDateFormatter();
 
     let pending = <></>;
@@ -185,6 +190,13 @@ class Bookings extends React.Component<Props, State> {
         <h6 hidden={!item.subject}>{item.subject}</h6>
         <p>
           {pending}
+          {delegated ? (
+            <>
+              <IconUser className="feather" />
+              &nbsp;{item.user.email}
+              <br />
+            </>
+          ) : null}
           <IconLocation className="feather" />
           &nbsp;{item.space.location.name}, {item.space.name}
           <br />
@@ -203,7 +215,7 @@ class Bookings extends React.Component<Props, State> {
       return <Loading />;
     }
 
-    if (this.data.length === 0) {
+    if (this.data.length === 0 && this.delegatedData.length === 0) {
       return (
         <>
           <NavBar />
@@ -298,6 +310,19 @@ class Bookings extends React.Component<Props, State> {
             </ListGroup>
           </Form>
 
+          {/* bookings managed on behalf of colleagues */}
+          <Form
+            className="form-signin"
+            hidden={this.delegatedData.length === 0}
+          >
+            <h5 className="margin-top-15">
+              {this.props.t("bookingsOfColleagues")}
+            </h5>
+            <ListGroup>
+              {this.delegatedData.map((item) => this.renderItem(item, true))}
+            </ListGroup>
+          </Form>
+
           {/* calendar view */}
           <div
             className={this.state.calendarShow ? "d-none d-lg-block" : "d-none"}
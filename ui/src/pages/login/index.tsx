@@ -17,7 +17,7 @@ import ThemeSelector from "@/components/ThemeSelector";
 import Validation from "@/util/Validation";
 import Navigation from "@/util/Navigation";
 import AjaxError from "@/util/AjaxError";
-import { ResponseCode } from "@/types/ErrorText";
+import ErrorText, { ResponseCode } from "@/types/ErrorText";
 import TotpInput from "@/components/TotpInput";
 import Passkey, {
   prepareRequestOptions,
@@ -37,9 +37,11 @@ interface State {
   inPasskeyLogin: boolean;
   passkeyLoginFailed: boolean;
   invalid: boolean;
+  policyError: string;
   redirect: string | null;
   requirePassword: boolean;
   disablePasswordLogin: boolean;
+  magicLinkLogin: boolean;
   providers: AuthProvider[] | null;
   inPasswordSubmit: boolean;
   inAuthProviderLogin: boolean;
@@ -76,9 +78,11 @@ class Login extends React.Component<Props, State> {
       inPasskeyLogin: false,
       passkeyLoginFailed: false,
       invalid: false,
+      policyError: "",
       redirect: null,
       requirePassword: false,
       disablePasswordLogin: false,
+      magicLinkLogin: false,
       providers: null,
       inPasswordSubmit: false,
       inAuthProviderLogin: false,
@@ -113,6 +117,7 @@ class Login extends React.Component<Props, State> {
         providers: res.json.authProviders,
         noPasswords: !res.json.requirePassword,
         disablePasswordLogin: res.json.disablePasswordLogin,
+        magicLinkLogin: res.json.magicLinkLogin,
         singleOrgMode: true,
         loading: false,
       },
@@ -178,6 +183,7 @@ class Login extends React.Component<Props, State> {
     e.preventDefault();
     this.setState({
       inPasswordSubmit: true,
+      policyError: "",
     });
     const payload: any = {
       email: this.state.email,
@@ -237,6 +243,24 @@ class Login extends React.Component<Props, State> {
           });
           return;
         }
+        if (
+          err instanceof AjaxError &&
+          err.httpStatusCode === 403 &&
+          (err.appErrorCode === ResponseCode.NetworkNotAllowed ||
+            err.appErrorCode === ResponseCode.SecondFactorRequired)
+        ) {
+          this.setState({
+            policyError: ErrorText.getTextForAppCode(
+              err.appErrorCode,
+              this.props.t,
+            ),
+            requireTotp: false,
+            requirePasskey: false,
+            code: "",
+            inPasswordSubmit: false,
+          });
+          return;
+        }
         this.setState({
           invalid: true,
           inPasswordSubmit: false,
@@ -363,7 +387,8 @@ class Login extends React.Component<Props, State> {
     // do not redirect to admin pages for non-admin users (to prevent "auto logout")
     if (
       Navigation.isAdminPath(redirectUrl) &&
-      !RuntimeConfig.INFOS?.spaceAdmin
+      !RuntimeConfig.INFOS?.spaceAdmin &&
+      !RuntimeConfig.INFOS?.permissions?.length
     ) {
       return Navigation.PATH_PAGE_SEARCH;
     }
@@ -716,6 +741,11 @@ class Login extends React.Component<Props, State> {
           <Form.Control.Feedback type="invalid">
             {this.props.t("errorInvalidEmail")}
           </Form.Control.Feedback>
+          {this.state.policyError && (
+            <p className="text-danger margin-top-10">
+              {this.state.policyError}
+            </p>
+          )}
           {this.state.passkeyAvailable && (
             <p className="margin-top-25">
               <Button
@@ -738,6 +768,9 @@ class Login extends React.Component<Props, State> {
           <p className="margin-top-50" hidden={!this.org}>
             <Link href="/resetpw">{this.props.t("forgotPassword")}</Link>
           </p>
+          <p hidden={!this.org || !this.state.magicLinkLogin}>
+            <Link href="/login/magic">{this.props.t("magicLinkRequest")}</Link>
+          </p>
         </Form>
         {copyrightFooter}
       </div>
@@ -0,0 +1,238 @@
+import React from "react";
+import { Button, Form } from "react-bootstrap";
+import { NextRouter } from "next/router";
+import Link from "next/link";
+import withReadyRouter from "@/components/withReadyRouter";
+import { TranslationFunc, withTranslation } from "@/components/withTranslation";
+import SeatsurfingLogo from "@/components/SeatsurfingLogo";
+import Loading from "@/components/Loading";
+import RuntimeConfig from "@/components/RuntimeConfig";
+import TotpInput from "@/components/TotpInput";
+import Ajax from "@/util/Ajax";
+import AjaxCredentials from "@/util/AjaxCredentials";
+import AjaxError from "@/util/AjaxError";
+import JwtDecoder from "@/util/JwtDecoder";
+import Navigation from "@/util/Navigation";
+import ErrorText, { ResponseCode } from "@/types/ErrorText";
+import {
+  prepareRequestOptions,
+  serializeAssertionResponse,
+  PasskeyChallengeResponse,
+} from "@/types/Passkey";
+
+interface State {
+  loading: boolean;
+  invalid: boolean;
+  secondFactorRequired: boolean;
+  networkNotAllowed: boolean;
+  requireTotp: boolean;
+  totpInvalid: boolean;
+  code: string;
+}
+
+interface Props {
+  router: NextRouter;
+  t: TranslationFunc;
+}
+
+class CompleteMagicLinkLogin extends React.Component<Props, State> {
+  submitted: boolean;
+
+  constructor(props: any) {
+    super(props);
+    this.submitted = false;
+    this.state = {
+      loading: true,
+      invalid: false,
+      secondFactorRequired: false,
+      networkNotAllowed: false,
+      requireTotp: false,
+      totpInvalid: false,
+      code: "",
+    };
+  }
+
+  componentDidMount = () => {
+    if (this.submitted) {
+      return;
+    }
+    this.submitted = true;
+    this.submit({});
+  };
+
+  submit = async (payload: any) => {
+    const { id } = this.props.router.query;
+    this.setState({ loading: true });
+    try {
+      const res = await Ajax.postData(
+        `${Navigation.PATH_API_AUTH_MAGIC_LINK}/${id}`,
+        payload,
+        () => true,
+      );
+      await this.onSuccessfulLogin(res.json);
+    } catch (err) {
+      this.onError(err);
+    }
+  };
+
+  onSuccessfulLogin = async (data: {
+    accessToken: string;
+    logoutUrl: string;
+    refreshToken: string;
+  }): Promise<void> => {
+    const credentials: AjaxCredentials = {
+      accessToken: data.accessToken,
+      accessTokenExpiry: JwtDecoder.getExpiryDate(data.accessToken),
+      logoutUrl: data.logoutUrl,
+      profilePageUrl: "",
+    };
+    Ajax.PERSISTER.updateCredentialsLocalStorage(credentials);
+    Ajax.PERSISTER.persistRefreshTokenInLocalStorage(data.refreshToken);
+    await RuntimeConfig.loadUserAndSettings();
+    this.props.router.push(Navigation.PATH_PAGE_SEARCH);
+  };
+
+  onError = (err: any) => {
+    if (!(err instanceof AjaxError)) {
+      this.setState({ loading: false, invalid: true });
+      return;
+    }
+    if (err.httpStatusCode === 401) {
+      if (err.responseBody) {
+        try {
+          const body: PasskeyChallengeResponse = JSON.parse(err.responseBody);
+          if (body.requirePasskey) {
+            const rawOpts =
+              body.passkeyChallenge?.publicKey ?? body.passkeyChallenge;
+            this.performPasskeyAssertion(
+              body.stateId,
+              rawOpts,
+              body.allowTotpFallback,
+            );
+            return;
+          }
+        } catch {}
+      }
+      this.setState({ loading: false, requireTotp: true });
+      return;
+    }
+    if (err.httpStatusCode === 400 && this.state.requireTotp) {
+      this.setState({ loading: false, totpInvalid: true, code: "" });
+      return;
+    }
+    if (
+      err.httpStatusCode === 403 &&
+      err.appErrorCode === ResponseCode.NetworkNotAllowed
+    ) {
+      this.setState({ loading: false, networkNotAllowed: true });
+      return;
+    }
+    if (err.httpStatusCode === 403) {
+      this.setState({ loading: false, secondFactorRequired: true });
+      return;
+    }
+    this.setState({ loading: false, invalid: true });
+  };
+
+  performPasskeyAssertion = async (
+    stateId: string,
+    rawOptions: any,
+    allowTotpFallback: boolean,
+  ) => {
+    try {
+      const publicKeyOptions = prepareRequestOptions(rawOptions);
+      const credential = await navigator.credentials.get({
+        publicKey: publicKeyOptions,
+      });
+      if (!credential) {
+        throw new Error("No credential returned");
+      }
+      const serialized = serializeAssertionResponse(
+        credential as PublicKeyCredential,
+      );
+      await this.submit({
+        passkeyStateId: stateId,
+        passkeyCredential: serialized,
+      });
+    } catch {
+      if (allowTotpFallback) {
+        this.setState({ loading: false, requireTotp: true });
+      } else {
+        this.setState({ loading: false, invalid: true });
+      }
+    }
+  };
+
+  onTotpSubmit = (e: any) => {
+    e.preventDefault();
+    this.setState({ totpInvalid: false });
+    this.submit({ code: this.state.code });
+  };
+
+  renderContent() {
+    if (this.state.invalid) {
+      return <p>{this.props.t("magicLinkInvalid")}</p>;
+    }
+    if (this.state.networkNotAllowed) {
+      return (
+        <p>
+          {ErrorText.getTextForAppCode(
+            ResponseCode.NetworkNotAllowed,
+            this.props.t,
+          )}
+        </p>
+      );
+    }
+    if (this.state.secondFactorRequired) {
+      return <p>{this.props.t("magicLinkSecondFactorRequired")}</p>;
+    }
+    if (this.state.requireTotp) {
+      return (
+        <>
+          <p>{this.props.t("enterTotpCode")}</p>
+          <Form.Group>
+            <TotpInput
+              value={this.state.code}
+              onChange={(value: string) =>
+                this.setState({ code: value, totpInvalid: false })
+              }
+              onComplete={(value: string) => {
+                this.setState({ code: value, totpInvalid: false }, () => {
+                  this.onTotpSubmit(new Event("submit") as any);
+                });
+              }}
+              disabled={this.state.loading}
+              invalid={this.state.totpInvalid}
+              required={true}
+            />
+          </Form.Group>
+          <Button
+            className="margin-top-10"
+            variant="primary"
+            type="submit"
+            disabled={this.state.loading}
+          >
+            {this.props.t("proceedToLogin")}
+          </Button>
+        </>
+      );
+    }
+    return <Loading showText={false} paddingTop={false} />;
+  }
+
+  render() {
+    return (
+      <div className="container-center">
+        <Form className="container-center-inner" onSubmit={this.onTotpSubmit}>
+          <SeatsurfingLogo />
+          {this.renderContent()}
+          <p className="margin-top-50">
+            <Link href="/login">{this.props.t("back")}</Link>
+          </p>
+        </Form>
+      </div>
+    );
+  }
+}
+
+export default withTranslation(withReadyRouter(CompleteMagicLinkLogin as any));
@@ -27,6 +27,8 @@ import ReloadModal from "@/components/ReloadModal";
 import { PreferencesTab } from "@/util/Navigation";
 import CONSTANT from "@/util/Contant";
 import WeekdaySelection from "@/components/WeekdaySelection";
+import DelegationSettings from "@/components/DelegationSettings";
+import PushNotificationSettings from "@/components/PushNotificationSettings";
 
 interface State {
   loading: boolean;
@@ -54,12 +56,16 @@ interface State {
   caldavCalendars: any[];
   caldavCalendarsLoaded: boolean;
   caldavError: boolean;
-  mailNotifications: boolean;
+  mailNotifications: number;
   mailReminder: boolean;
   mailLanguage: string;
   use24HourTime: boolean;
   dateFormat: string;
   weekStartDay: number;
+  chatWebhookUrl: string;
+  chatWebhookFormat: string;
+  chatNotifications: boolean;
+  chatReminder: boolean;
   activeSessions: Session[];
   currentSessionId: string;
   showPasswordChangedModal: boolean;
@@ -76,6 +82,7 @@ const TAB_MAP: Record<PreferencesTab, string> = {
   style: "tab-style",
   security: "tab-security",
   integration: "tab-integrations",
+  delegation: "tab-delegation",
 };
 
 const COLOR_BOOKED: string = "#ff453a";
@@ -117,12 +124,16 @@ class Preferences extends React.Component<Props, State> {
       caldavCalendars: [],
       caldavCalendarsLoaded: false,
       caldavError: false,
-      mailNotifications: false,
+      mailNotifications: UserPreference.PreferenceMailNotifications.Off,
       mailReminder: false,
       mailLanguage: "",
       use24HourTime: true,
       dateFormat: "Y-m-d",
       weekStartDay: 1,
+      chatWebhookUrl: "",
+      chatWebhookFormat: UserPreference.CHAT_FORMATS[0],
+      chatNotifications: false,
+      chatReminder: false,
       activeSessions: [],
       currentSessionId: "",
       showPasswordChangedModal: false,
@@ -197,7 +208,7 @@ class Preferences extends React.Component<Props, State> {
       if (s.name === UserPreference.PREF_CALDAV_PATH)
         state.caldavCalendar = s.value;
       if (s.name === UserPreference.PREF_MAIL_NOTIFICATIONS)
-        state.mailNotifications = s.value === "1";
+        state.mailNotifications = window.parseInt(s.value);
       if (s.name === UserPreference.PREF_MAIL_REMINDER)
         state.mailReminder = s.value === "1";
       if (s.name === UserPreference.PREF_MAIL_LANGUAGE)
@@ -206,6 +217,14 @@ class Preferences extends React.Component<Props, State> {
         state.use24HourTime = s.value === "1";
       if (s.name === UserPreference.PREF_DATE_FORMAT)
         state.dateFormat = s.value;
+      if (s.name === UserPreference.PREF_CHAT_WEBHOOK_URL)
+        state.chatWebhookUrl = s.value;
+      if (s.name === UserPreference.PREF_CHAT_WEBHOOK_FORMAT && s.value)
+        state.chatWebhookFormat = s.value;
+      if (s.name === UserPreference.PREF_CHAT_NOTIFICATIONS)
+        state.chatNotifications = s.value === "1";
+      if (s.name === UserPreference.PREF_CHAT_REMINDER)
+        state.chatReminder = s.value === "1";
       if (s.name === UserPreference.PREF_WEEK_START_DAY) {
         const v = parseInt(s.value);
         state.weekStartDay = CONSTANT.WEEK_START_DAYS.includes(v) ? v : 1;
@@ -247,7 +266,7 @@ class Preferences extends React.Component<Props, State> {
       ),
       new UserPreference(
         UserPreference.PREF_MAIL_NOTIFICATIONS,
-        this.state.mailNotifications ? "1" : "0",
+        this.state.mailNotifications.toString(),
       ),
       new UserPreference(
         UserPreference.PREF_MAIL_REMINDER,
@@ -446,6 +465,35 @@ class Preferences extends React.Component<Props, State> {
     }
   };
 
+  saveChatSettings = async (e: any) => {
+    e.preventDefault();
+    this.setState({ submitting: true, saved: false, error: false });
+    const payload = [
+      new UserPreference(
+        UserPreference.PREF_CHAT_WEBHOOK_URL,
+        this.state.chatWebhookUrl,
+      ),
+      new UserPreference(
+        UserPreference.PREF_CHAT_WEBHOOK_FORMAT,
+        this.state.chatWebhookFormat,
+      ),
+      new UserPreference(
+        UserPreference.PREF_CHAT_NOTIFICATIONS,
+        this.state.chatNotifications ? "1" : "0",
+      ),
+      new UserPreference(
+        UserPreference.PREF_CHAT_REMINDER,
+        this.state.chatReminder ? "1" : "0",
+      ),
+    ];
+    try {
+      await UserPreference.setAll(payload);
+      this.setState({ submitting: false, saved: true });
+    } catch {
+      this.setState({ submitting: false, error: true });
+    }
+  };
+
   renderBookingColor(
     stateKey: keyof Pick<
       State,
@@ -555,6 +603,11 @@ class Preferences extends React.Component<Props, State> {
                   {this.props.t("integrations")}
                 </Nav.Link>
               </Nav.Item>
+              <Nav.Item>
+                <Nav.Link eventKey="tab-delegation">
+                  {this.props.t("delegates")}
+                </Nav.Link>
+              </Nav.Item>
             </Nav>
             {hint}
 
@@ -715,16 +768,24 @@ class Preferences extends React.Component<Props, State> {
                 <Form.Label htmlFor="mailNotifications">
                   {this.props.t("mailNotifications")}
                 </Form.Label>
+                <Form.Select
+                  id="mailNotifications"
+                  value={this.state.mailNotifications}
+                  onChange={(e: any) =>
+                    this.setState({
+                      mailNotifications: window.parseInt(e.target.value),
+                    })
+                  }
+                >
+                  {Object.entries(
+                    UserPreference.PreferenceMailNotifications,
+                  ).map(([name, value]) => (
+                    <option key={"mail-notifications-" + value} value={value}>
+                      {this.props.t("mailNotifications" + name)}
+                    </option>
+                  ))}
+                </Form.Select>
                 <div className="text-left">
-                  <Form.Check
-                    type="switch"
-                    id="mailNotifications"
-                    label={this.props.t("mailNotificationsBookingInfo")}
-                    checked={this.state.mailNotifications}
-                    onChange={(e: any) =>
-                      this.setState({ mailNotifications: e.target.checked })
-                    }
-                  />
                   <Form.Check
                     type="switch"
                     id="mailReminder"
@@ -1008,6 +1069,14 @@ class Preferences extends React.Component<Props, State> {
               ></iframe>
             </div>
 
+            {/* ---------- */}
+            {/* DELEGATION */}
+            {/* ---------- */}
+
+            <DelegationSettings
+              hidden={this.state.activeTab !== "tab-delegation"}
+            />
+
             {/* ------------ */}
             {/* INTEGRATIONS */}
             {/* ------------ */}
@@ -1125,6 +1194,78 @@ class Preferences extends React.Component<Props, State> {
                 />
               </ButtonGroup>
             </Form>
+            <Form
+              onSubmit={this.saveChatSettings}
+              hidden={this.state.activeTab !== "tab-integrations"}
+            >
+              <h5 className="margin-top-15">
+                {this.props.t("chatNotifications")}
+              </h5>
+              <Form.Group className="margin-top-15">
+                <Form.Label htmlFor="chatWebhookUrl">
+                  {this.props.t("chatWebhookUrl")}
+                </Form.Label>
+                <UrlInput
+                  id="chatWebhookUrl"
+                  placeholder="https://…"
+                  value={this.state.chatWebhookUrl}
+                  onChange={(e: any) =>
+                    this.setState({ chatWebhookUrl: e.target.value })
+                  }
+                />
+                <Form.Text>{this.props.t("chatWebhookUrlHint")}</Form.Text>
+              </Form.Group>
+              <Form.Group className="margin-top-15">
+                <Form.Label htmlFor="chatWebhookFormat">
+                  {this.props.t("chatWebhookFormat")}
+                </Form.Label>
+                <Form.Select
+                  id="chatWebhookFormat"
+                  value={this.state.chatWebhookFormat}
+                  onChange={(e: any) =>
+                    this.setState({ chatWebhookFormat: e.target.value })
+                  }
+                >
+                  {UserPreference.CHAT_FORMATS.map((format) => (
+                    <option key={format} value={format}>
+                      {this.props.t("chatFormat_" + format)}
+                    </option>
+                  ))}
+                </Form.Select>
+              </Form.Group>
+              <Form.Group className="margin-top-15">
+                <Form.Check
+                  type="switch"
+                  id="chatNotifications"
+                  label={this.props.t("chatNotificationsBookingInfo")}
+                  checked={this.state.chatNotifications}
+                  onChange={(e: any) =>
+                    this.setState({ chatNotifications: e.target.checked })
+                  }
+                  disabled={this.state.chatWebhookUrl === ""}
+                />
+              </Form.Group>
+              <Form.Group className="margin-top-15">
+                <Form.Check
+                  type="switch"
+                  id="chatReminder"
+                  label={this.props.t("chatReminderBookingInfo")}
+                  checked={this.state.chatReminder}
+                  onChange={(e: any) =>
+                    this.setState({ chatReminder: e.target.checked })
+                  }
+                  disabled={this.state.chatWebhookUrl === ""}
+                />
+              </Form.Group>
+              <SaveButton
+                className="margin-top-15"
+                submitting={this.state.submitting}
+                disabled={this.state.submitting}
+              />
+            </Form>
+            <PushNotificationSettings
+              hidden={this.state.activeTab !== "tab-integrations"}
+            />
           </div>
         </div>
         <Modal
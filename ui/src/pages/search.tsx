@@ -82,6 +82,7 @@ import RendererUtils from "@/util/RendererUtils";
 import SpaceApprovalIcon from "@/components/SpaceApprovalIcon";
 import ConfirmModal from "@/components/ConfirmModal";
 import AlertModal from "@/components/AlertModal";
+import Delegation from "@/types/Delegation";
 
 interface State {
   earliestEnterDate: Date;
@@ -119,6 +120,7 @@ interface State {
   activeTabFilterModal: string;
   createdBookingId: string;
   subject: string;
+  bookFor: string;
   showRecurringOptions: boolean;
   cancelSeries: boolean;
   recurrence: {
@@ -159,6 +161,7 @@ class Search extends React.Component<Props, State> {
   searchContainerRef: RefObject<any>;
   transformWrapperRef: React.RefObject<ReactZoomPanPinchContentRef | null>;
   buddies: Buddy[];
+  principals: User[];
   availableAttributes: SpaceAttribute[];
   recurrenceMaxEndDate: Date;
 
@@ -175,6 +178,7 @@ class Search extends React.Component<Props, State> {
     this.locations = [];
     this.mapData = null;
     this.buddies = [];
+    this.principals = [];
     this.availableAttributes = [];
     this.searchContainerRef = React.createRef();
     this.transformWrapperRef = React.createRef();
@@ -228,6 +232,7 @@ class Search extends React.Component<Props, State> {
       activeTabFilterModal: "tab-filter-area",
       createdBookingId: "",
       subject: "",
+      bookFor: "",
       showRecurringOptions: false,
       cancelSeries: false,
       recurrence: {
@@ -366,6 +371,7 @@ class Search extends React.Component<Props, State> {
       promises.push(this.loadBuddies());
     }
     promises.push(this.loadAvailableAttributes());
+    promises.push(this.loadPrincipals());
     Promise.all(promises).then(() => {
       this.initDates();
       if (this.state.locationId === "" && this.locations.length > 0) {
@@ -632,6 +638,16 @@ class Search extends React.Component<Props, State> {
     });
   };
 
+  loadPrincipals = async (): Promise<void> => {
+    return Delegation.listPrincipals()
+      .then((list) => {
+        this.principals = list;
+      })
+      .catch(() => {
+        this.principals = [];
+      });
+  };
+
   loadMap = async (locationId: string) => {
     this.setState({ loading: true });
     return Location.get(locationId).then((location) => {
@@ -1072,6 +1088,7 @@ class Search extends React.Component<Props, State> {
       booking.cadence = this.state.recurrence.cadence;
       booking.cycle = this.state.recurrence.cycle;
       booking.weekdays = this.state.recurrence.weekdays;
+      booking.userEmail = this.state.bookFor;
     } else {
       booking = new Booking();
       booking.subject = this.state.subject;
@@ -1081,6 +1098,7 @@ class Search extends React.Component<Props, State> {
         booking.leave.setSeconds(booking.leave.getSeconds() - 1);
       }
       booking.space = this.state.selectedSpace;
+      booking.user.email = this.state.bookFor;
     }
     try {
       await booking.save();
@@ -2453,6 +2471,33 @@ class Search extends React.Component<Props, State> {
                 </Row>
               );
             })}
+            <Form.Group
+              as={Row}
+              style={{ marginTop: "25px" }}
+              hidden={this.principals.length === 0}
+            >
+              <Form.Label column sm="4" htmlFor="bookFor">
+                {this.props.t("bookFor")}:
+              </Form.Label>
+              <Col sm="8">
+                <Form.Select
+                  id="bookFor"
+                  value={this.state.bookFor}
+                  onChange={(e: any) =>
+                    this.setState({ bookFor: e.target.value })
+                  }
+                >
+                  <option value="">{this.props.t("myself")}</option>
+                  {this.principals.map((user) => (
+                    <option key={user.id} value={user.email}>
+                      {user.firstname || user.lastname
+                        ? `${user.firstname} ${user.lastname} (${user.email})`
+                        : user.email}
+                    </option>
+                  ))}
+                </Form.Select>
+              </Col>
+            </Form.Group>
             <Form.Group
               as={Row}
               style={{ marginTop: "25px" }}
@@ -0,0 +1,161 @@
+import Ajax from "../util/Ajax";
+
+export interface AuditLogListParams {
+  start?: Date;
+  end?: Date;
+  actor?: string;
+  action?: string;
+  entityType?: string;
+  entityId?: string;
+  limit?: number;
+  offset?: number;
+}
+
+export interface AuditLogListResult {
+  total: number;
+  items: AuditLogEntry[];
+}
+
+export interface AuditLogChange {
+  old: any;
+  new: any;
+}
+
+export default class AuditLogEntry {
+  static readonly ACTIONS = [
+    "create",
+    "update",
+    "delete",
+    "approve",
+    "decline",
+    "reset_totp",
+    "reset_passkeys",
+    "verify",
+  ];
+
+  static readonly ENTITY_TYPES = [
+    "location",
+    "space",
+    "group",
+    "user",
+    "setting",
+    "booking",
+    "auth_provider",
+    "domain",
+    "api_token",
+    "role",
+    "user_attribute",
+    "webhook",
+    "email_template",
+    "mail_settings",
+  ];
+
+  id: string;
+  timestamp: Date;
+  actorUserId: string;
+  actorEmail: string;
+  action: string;
+  entityType: string;
+  entityId: string;
+  entityName: string;
+  ip: string;
+  changes: { [key: string]: AuditLogChange };
+
+  constructor() {
+    this.id = "";
+    this.timestamp = new Date();
+    this.actorUserId = "";
+    this.actorEmail = "";
+    this.action = "";
+    this.entityType = "";
+    this.entityId = "";
+    this.entityName = "";
+    this.ip = "";
+    this.changes = {};
+  }
+
+  deserialize(input: any): void {
+    this.id = input.id;
+    if (input.timestamp) {
+      this.timestamp = new Date(input.timestamp);
+    }
+    this.actorUserId = input.actorUserId;
+    this.actorEmail = input.actorEmail;
+    this.action = input.action;
+    this.entityType = input.entityType;
+    this.entityId = input.entityId;
+    this.entityName = input.entityName;
+    this.ip = input.ip;
+    this.changes = input.changes ?? {};
+  }
+
+  static getQuery(params: AuditLogListParams): URLSearchParams {
+    const query = new URLSearchParams();
+    if (params.start) {
+      query.append("start", params.start.toISOString());
+    }
+    if (params.end) {
+      query.append("end", params.end.toISOString());
+    }
+    if (params.actor) {
+      query.append("actor", params.actor);
+    }
+    if (params.action) {
+      query.append("action", params.action);
+    }
+    if (params.entityType) {
+      query.append("entityType", params.entityType);
+    }
+    if (params.entityId) {
+      query.append("entityId", params.entityId);
+    }
+    if (params.limit) {
+      query.append("limit", params.limit.toString());
+    }
+    if (params.offset) {
+      query.append("offset", params.offset.toString());
+    }
+    return query;
+  }
+
+  static async list(params: AuditLogListParams): Promise<AuditLogListResult> {
+    const query = AuditLogEntry.getQuery(params);
+    const result = await Ajax.get("/audit-log/?" + query.toString());
+    const items: AuditLogEntry[] = [];
+    (result.json.items as []).forEach((item) => {
+      const e: AuditLogEntry = new AuditLogEntry();
+      e.deserialize(item);
+      items.push(e);
+    });
+    return {
+      total: result.json.total,
+      items: items,
+    };
+  }
+
+  static async export(params: AuditLogListParams): Promise<void> {
+    const query = AuditLogEntry.getQuery(params);
+    const credentials = Ajax.PERSISTER.readCredentialsFromLocalStorage();
+    const options: RequestInit = Ajax.getFetchOptions(
+      "GET",
+      credentials.accessToken,
+      null,
+    );
+    const url = Ajax.getBackendUrl() + "/audit-log/export?" + query.toString();
+    const response = await fetch(url, options);
+    if (!response.ok) {
+      return;
+    }
+    const data = await response.blob();
+    const blob = new Blob([data], { type: "text/csv" });
+    const blobUrl = window.URL.createObjectURL(blob);
+    const a = document.createElement("a");
+    a.style.display = "none";
+    a.href = blobUrl;
+    a.download = "seatsurfing-audit-log.csv";
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    window.URL.revokeObjectURL(blobUrl);
+  }
+}
@@ -24,6 +24,12 @@ export default class AuthAttempt {
     "passkey_2fa",
     "oauth",
     "confluence",
+    "client_credentials",
+    "refresh_token",
+    "magic_link",
+    "access_token",
+    "api_token",
+    "service_account",
   ];
 
   static readonly ERROR_CODES = [
@@ -53,6 +59,10 @@ export default class AuthAttempt {
     "org_mismatch",
     "internal_error",
     "confluence_jwt_invalid",
+    "no_service_account",
+    "refresh_token_reuse",
+    "network_not_allowed",
+    "second_factor_required",
   ];
 
   id: string;
@@ -15,6 +15,7 @@ export default class Booking extends Entity {
   approved: boolean;
   subject: string;
   recurringId: string;
+  createdByEmail: string;
 
   constructor() {
     super();
@@ -26,6 +27,7 @@ export default class Booking extends Entity {
     this.approved = false;
     this.subject = "";
     this.recurringId = "";
+    this.createdByEmail = "";
   }
 
   serialize(): Object {
@@ -82,6 +84,9 @@ export default class Booking extends Entity {
     if (input.recurringId) {
       this.recurringId = input.recurringId;
     }
+    if (input.createdByEmail) {
+      this.createdByEmail = input.createdByEmail;
+    }
   }
 
   getBackendUrl(): string {
@@ -96,8 +101,11 @@ export default class Booking extends Entity {
     return Ajax.saveEntity(this, this.getBackendUrl()).then(() => this);
   }
 
-  async delete(): Promise<void> {
-    return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
+  async delete(reason?: string): Promise<void> {
+    const params = reason ? "?reason=" + encodeURIComponent(reason) : "";
+    return Ajax.delete(this.getBackendUrl() + this.id + params).then(
+      () => undefined,
+    );
   }
 
   async approve(approved: boolean): Promise<void> {
@@ -130,6 +138,18 @@ export default class Booking extends Entity {
     });
   }
 
+  static async listDelegated(): Promise<Booking[]> {
+    return Ajax.get("/booking/delegated/").then((result) => {
+      const list: Booking[] = [];
+      (result.json as []).forEach((item) => {
+        const e: Booking = new Booking();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return list;
+    });
+  }
+
   static async listPendingApprovals(): Promise<Booking[]> {
     return Ajax.get("/booking/pendingapprovals/").then((result) => {
       const list: Booking[] = [];
@@ -142,6 +162,30 @@ export default class Booking extends Entity {
     });
   }
 
+  static async bulkCancel(
+    start: Date,
+    end: Date,
+    locationId: string,
+    spaceId: string,
+    userId: string,
+    reason: string,
+  ): Promise<{ count: number; failed: number }> {
+    const payload = {
+      start: DateUtil.convertToFakeUTCDate(start).toISOString(),
+      end: DateUtil.convertToFakeUTCDate(end).toISOString(),
+      locationId,
+      spaceId,
+      userId,
+      reason,
+    };
+    return Ajax.postData("/booking/cancel/", payload).then((result) => {
+      return {
+        count: result.json.count as number,
+        failed: result.json.failed as number,
+      };
+    });
+  }
+
   static async getPendingApprovalsCount(): Promise<number> {
     return Ajax.get("/booking/pendingapprovals/count").then((result) => {
       return result.json.count as number;
@@ -0,0 +1,84 @@
+import { Entity } from "./Entity";
+import Ajax from "../util/Ajax";
+import User from "./User";
+
+export default class Delegation extends Entity {
+  delegateEmail: string;
+  delegateGroupId: string;
+  delegateGroupName: string;
+  created: Date;
+  expiry: Date | null;
+  active: boolean;
+
+  constructor() {
+    super();
+    this.delegateEmail = "";
+    this.delegateGroupId = "";
+    this.delegateGroupName = "";
+    this.created = new Date();
+    this.expiry = null;
+    this.active = true;
+  }
+
+  serialize(): Object {
+    return Object.assign(super.serialize(), {
+      delegateEmail: this.delegateEmail,
+      delegateGroupId: this.delegateGroupId,
+      expiry: this.expiry ? this.expiry.toISOString() : null,
+    });
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.delegateEmail = input.delegateEmail;
+    this.delegateGroupId = input.delegateGroupId;
+    this.delegateGroupName = input.delegateGroupName;
+    this.created = new Date(input.created);
+    this.expiry = input.expiry ? new Date(input.expiry) : null;
+    this.active = input.active;
+  }
+
+  getBackendUrl(): string {
+    return "/delegation/";
+  }
+
+  async save(): Promise<Delegation> {
+    return Ajax.postData(this.getBackendUrl(), this.serialize()).then(
+      (result) => {
+        this.id = result.objectId;
+        return this;
+      },
+    );
+  }
+
+  async delete(): Promise<void> {
+    return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
+  }
+
+  static async list(): Promise<Delegation[]> {
+    return Ajax.get("/delegation/").then((result) => {
+      const list: Delegation[] = [];
+      (result.json as []).forEach((item) => {
+        const e: Delegation = new Delegation();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return list;
+    });
+  }
+
+  static async listPrincipals(): Promise<User[]> {
+    return Ajax.get("/delegation/principals").then((result) => {
+      const list: User[] = [];
+      (result.json as any[]).forEach((item) => {
+        const e: User = new User();
+        e.id = item.userId;
+        e.email = item.email;
+        e.firstname = item.firstname;
+        e.lastname = item.lastname;
+        list.push(e);
+      });
+      return list;
+    });
+  }
+}
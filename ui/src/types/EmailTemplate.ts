@@ -0,0 +1,118 @@
+import Ajax from "../util/Ajax";
+
+export class EmailTemplateDefinition {
+  name: string;
+  variables: string[];
+  conditions: string[];
+  requiredVariables: string[];
+  customLanguages: string[];
+
+  constructor() {
+    this.name = "";
+    this.variables = [];
+    this.conditions = [];
+    this.requiredVariables = [];
+    this.customLanguages = [];
+  }
+
+  deserialize(input: any): void {
+    this.name = input.name;
+    this.variables = input.variables;
+    this.conditions = input.conditions;
+    this.requiredVariables = input.requiredVariables;
+    this.customLanguages = input.customLanguages;
+  }
+}
+
+export interface EmailTemplatePreview {
+  subject: string;
+  bodyHtml: string;
+  bodyText: string;
+}
+
+export default class EmailTemplate {
+  static LANGUAGES = ["en", "de"];
+
+  name: string;
+  language: string;
+  custom: boolean;
+  updated: Date | null;
+  subject: string;
+  headline: string;
+  bodyHtml: string;
+  bodyText: string;
+
+  constructor() {
+    this.name = "";
+    this.language = "";
+    this.custom = false;
+    this.updated = null;
+    this.subject = "";
+    this.headline = "";
+    this.bodyHtml = "";
+    this.bodyText = "";
+  }
+
+  serialize(): object {
+    return {
+      subject: this.subject,
+      headline: this.headline,
+      bodyHtml: this.bodyHtml,
+      bodyText: this.bodyText,
+    };
+  }
+
+  deserialize(input: any): void {
+    this.name = input.name;
+    this.language = input.language;
+    this.custom = input.custom;
+    this.updated = input.updated ? new Date(input.updated) : null;
+    this.subject = input.subject;
+    this.headline = input.headline;
+    this.bodyHtml = input.bodyHtml;
+    this.bodyText = input.bodyText;
+  }
+
+  getBackendUrl(): string {
+    return "/email-template/" + this.name + "/" + this.language;
+  }
+
+  async save(): Promise<void> {
+    return Ajax.putData(this.getBackendUrl(), this.serialize()).then(
+      () => undefined,
+    );
+  }
+
+  async delete(): Promise<void> {
+    return Ajax.delete(this.getBackendUrl()).then(() => undefined);
+  }
+
+  async preview(): Promise<EmailTemplatePreview> {
+    return Ajax.postData(
+      this.getBackendUrl() + "/preview",
+      this.serialize(),
+    ).then((result) => result.json as EmailTemplatePreview);
+  }
+
+  static async get(name: string, language: string): Promise<EmailTemplate> {
+    return Ajax.get("/email-template/" + name + "/" + language).then(
+      (result) => {
+        let e: EmailTemplate = new EmailTemplate();
+        e.deserialize(result.json);
+        return e;
+      },
+    );
+  }
+
+  static async listDefinitions(): Promise<EmailTemplateDefinition[]> {
+    return Ajax.get("/email-template/").then((result) => {
+      let list: EmailTemplateDefinition[] = [];
+      (result.json as []).forEach((item) => {
+        let e: EmailTemplateDefinition = new EmailTemplateDefinition();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return list;
+    });
+  }
+}
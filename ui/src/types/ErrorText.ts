@@ -20,8 +20,19 @@ export const enum ResponseCode {
   GroupNameAlreadyExists = 4001,
 
   PasswordUpdateRequired = 5001,
+  PasswordPolicyViolation = 5002,
+  PasswordBreached = 5003,
+  PasswordReused = 5004,
+  NetworkNotAllowed = 5005,
+  SecondFactorRequired = 5006,
+  NetworkPolicyLockout = 5007,
 
   AuthProviderNameExists = 6001,
+
+  EmailTemplateMissingVariable = 7001,
+  EmailTemplateUnknownVariable = 7002,
+  MailHostNotAllowed = 7003,
+  MailPasswordRequired = 7004,
 }
 
 export default class ErrorText {
@@ -57,6 +68,22 @@ export default class ErrorText {
         t("errorGroupNameAlreadyExists"),
       [ResponseCode.AuthProviderNameExists]: () =>
         t("errorAuthProviderNameExists"),
+      [ResponseCode.PasswordPolicyViolation]: () =>
+        t("errorPasswordPolicyViolation"),
+      [ResponseCode.PasswordBreached]: () => t("errorPasswordBreached"),
+      [ResponseCode.PasswordReused]: () => t("errorPasswordReused"),
+      [ResponseCode.NetworkNotAllowed]: () => t("errorNetworkNotAllowed"),
+      [ResponseCode.SecondFactorRequired]: () =>
+        t("errorSecondFactorRequiredNetwork"),
+      [ResponseCode.NetworkPolicyLockout]: () =>
+        t("errorNetworkPolicyLockout"),
+      [ResponseCode.EmailTemplateMissingVariable]: () =>
+        t("errorEmailTemplateMissingVariable"),
+      [ResponseCode.EmailTemplateUnknownVariable]: () =>
+        t("errorEmailTemplateUnknownVariable"),
+      [ResponseCode.MailHostNotAllowed]: () => t("errorMailHostNotAllowed"),
+      [ResponseCode.MailPasswordRequired]: () =>
+        t("errorMailPasswordRequired"),
     };
 
     return errorMap[code as ResponseCode]?.() ?? t("errorUnknown");
@@ -0,0 +1,72 @@
+import { Entity } from "./Entity";
+import Ajax from "../util/Ajax";
+
+export interface FailedMailList {
+  total: number;
+  pending: number;
+  items: FailedMail[];
+}
+
+export default class FailedMail extends Entity {
+  recipient: string;
+  recipientName: string;
+  subject: string;
+  attempts: number;
+  lastAttempt: Date | null;
+  error: string;
+  created: Date;
+
+  constructor() {
+    super();
+    this.recipient = "";
+    this.recipientName = "";
+    this.subject = "";
+    this.attempts = 0;
+    this.lastAttempt = null;
+    this.error = "";
+    this.created = new Date();
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.recipient = input.recipient;
+    this.recipientName = input.recipientName;
+    this.subject = input.subject;
+    this.attempts = input.attempts;
+    this.lastAttempt = input.lastAttempt ? new Date(input.lastAttempt) : null;
+    this.error = input.error;
+    this.created = new Date(input.created);
+  }
+
+  getBackendUrl(): string {
+    return "/mail-queue/";
+  }
+
+  async retry(): Promise<void> {
+    return Ajax.postData(this.getBackendUrl() + this.id + "/retry").then(
+      () => undefined,
+    );
+  }
+
+  async delete(): Promise<void> {
+    return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
+  }
+
+  static async list(limit: number, offset: number): Promise<FailedMailList> {
+    return Ajax.get(
+      "/mail-queue/?limit=" + limit + "&offset=" + offset,
+    ).then((result) => {
+      let list: FailedMail[] = [];
+      (result.json.items as []).forEach((item) => {
+        let e: FailedMail = new FailedMail();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return {
+        total: result.json.total,
+        pending: result.json.pending,
+        items: list,
+      };
+    });
+  }
+}
@@ -2,6 +2,40 @@ import { Entity } from "./Entity";
 import Ajax from "../util/Ajax";
 import SpaceAttributeValue from "./SpaceAttributeValue";
 
+export class LocationAdmin extends Entity {
+  userId: string;
+  userEmail: string;
+  groupId: string;
+  groupName: string;
+
+  constructor() {
+    super();
+    this.userId = "";
+    this.userEmail = "";
+    this.groupId = "";
+    this.groupName = "";
+  }
+
+  serialize(): Object {
+    return Object.assign(super.serialize(), {
+      userEmail: this.userEmail,
+      groupId: this.groupId,
+    });
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.userId = input.userId;
+    this.userEmail = input.userEmail;
+    this.groupId = input.groupId;
+    this.groupName = input.groupName;
+  }
+
+  getBackendUrl(): string {
+    return "/location/";
+  }
+}
+
 export default class Location extends Entity {
   name: string;
   description: string;
@@ -14,6 +48,7 @@ export default class Location extends Entity {
   mapScale: number;
   mapType: string;
   allowedBookerGroupIds: string[];
+  allowedBookerAttributes: { attributeId: string; value: string }[];
   /** weekdays (0=Sunday..6=Saturday) the location can be booked on; empty array means unrestricted */
   bookableDays: number[];
 
@@ -30,6 +65,7 @@ export default class Location extends Entity {
     this.mapScale = 1.0;
     this.mapType = "";
     this.allowedBookerGroupIds = [];
+    this.allowedBookerAttributes = [];
     this.bookableDays = [];
   }
 
@@ -43,6 +79,7 @@ export default class Location extends Entity {
       mapScale: this.mapScale,
       mapType: this.mapType,
       allowedBookerGroupIds: this.allowedBookerGroupIds,
+      allowedBookerAttributes: this.allowedBookerAttributes,
       bookableDays: this.bookableDays,
     });
   }
@@ -62,6 +99,7 @@ export default class Location extends Entity {
     if (input.allowedBookerGroupIds) {
       this.allowedBookerGroupIds = input.allowedBookerGroupIds;
     }
+    this.allowedBookerAttributes = input.allowedBookerAttributes || [];
     this.bookableDays = input.bookableDays || [];
   }
 
@@ -81,6 +119,33 @@ export default class Location extends Entity {
     return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
   }
 
+  async getAdmins(): Promise<LocationAdmin[]> {
+    return Ajax.get(this.getBackendUrl() + this.id + "/admin").then(
+      (result) => {
+        let list: LocationAdmin[] = [];
+        (result.json as []).forEach((item) => {
+          let e: LocationAdmin = new LocationAdmin();
+          e.deserialize(item);
+          list.push(e);
+        });
+        return list;
+      },
+    );
+  }
+
+  async addAdmin(e: LocationAdmin): Promise<void> {
+    return Ajax.postData(
+      this.getBackendUrl() + this.id + "/admin",
+      e.serialize(),
+    ).then(() => undefined);
+  }
+
+  async removeAdmin(e: LocationAdmin): Promise<void> {
+    return Ajax.delete(this.getBackendUrl() + this.id + "/admin/" + e.id).then(
+      () => undefined,
+    );
+  }
+
   async getMap(): Promise<LocationMap> {
     return Ajax.get(this.getMapUrl()).then((result) => {
       return {
@@ -151,8 +216,12 @@ export default class Location extends Entity {
     });
   }
 
-  static async list(): Promise<Location[]> {
-    return Ajax.get("/location/").then((result) => {
+  static async list(permission?: string): Promise<Location[]> {
+    let url = "/location/";
+    if (permission) {
+      url += "?permission=" + encodeURIComponent(permission);
+    }
+    return Ajax.get(url).then((result) => {
       let list: Location[] = [];
       (result.json as []).forEach((item) => {
         let e: Location = new Location();
@@ -0,0 +1,107 @@
+import Ajax from "../util/Ajax";
+import AjaxError from "../util/AjaxError";
+
+export interface TestMailResult {
+  recipient: string;
+  success: boolean;
+  error: string;
+}
+
+export default class MailSettings {
+  static TLS_MODES = ["none", "starttls", "tls"];
+  static AUTH_METHODS = ["PLAIN", "LOGIN"];
+
+  smtpHost: string;
+  smtpPort: number;
+  smtpTlsMode: string;
+  smtpInsecureSkipVerify: boolean;
+  smtpAuth: boolean;
+  smtpAuthMethod: string;
+  smtpAuthUser: string;
+  smtpAuthPass: string;
+  smtpAuthPassSet: boolean;
+  senderAddress: string;
+  senderName: string;
+  replyTo: string;
+  updated: Date | null;
+
+  constructor() {
+    this.smtpHost = "";
+    this.smtpPort = 587;
+    this.smtpTlsMode = "starttls";
+    this.smtpInsecureSkipVerify = false;
+    this.smtpAuth = false;
+    this.smtpAuthMethod = "PLAIN";
+    this.smtpAuthUser = "";
+    this.smtpAuthPass = "";
+    this.smtpAuthPassSet = false;
+    this.senderAddress = "";
+    this.senderName = "";
+    this.replyTo = "";
+    this.updated = null;
+  }
+
+  serialize(): object {
+    return {
+      smtpHost: this.smtpHost,
+      smtpPort: this.smtpPort,
+      smtpTlsMode: this.smtpTlsMode,
+      smtpInsecureSkipVerify: this.smtpInsecureSkipVerify,
+      smtpAuth: this.smtpAuth,
+      smtpAuthMethod: this.smtpAuthMethod,
+      smtpAuthUser: this.smtpAuthUser,
+      smtpAuthPass: this.smtpAuthPass,
+      senderAddress: this.senderAddress,
+      senderName: this.senderName,
+      replyTo: this.replyTo,
+    };
+  }
+
+  deserialize(input: any): void {
+    this.smtpHost = input.smtpHost;
+    this.smtpPort = input.smtpPort;
+    this.smtpTlsMode = input.smtpTlsMode;
+    this.smtpInsecureSkipVerify = input.smtpInsecureSkipVerify;
+    this.smtpAuth = input.smtpAuth;
+    this.smtpAuthMethod = input.smtpAuthMethod;
+    this.smtpAuthUser = input.smtpAuthUser;
+    this.smtpAuthPass = "";
+    this.smtpAuthPassSet = input.smtpAuthPassSet;
+    this.senderAddress = input.senderAddress;
+    this.senderName = input.senderName;
+    this.replyTo = input.replyTo;
+    this.updated = input.updated ? new Date(input.updated) : null;
+  }
+
+  async save(): Promise<void> {
+    return Ajax.putData("/mail-settings/", this.serialize()).then(
+      () => undefined,
+    );
+  }
+
+  static async delete(): Promise<void> {
+    return Ajax.delete("/mail-settings/").then(() => undefined);
+  }
+
+  static async sendTestMail(): Promise<TestMailResult> {
+    return Ajax.postData("/mail-settings/test", {}).then(
+      (result) => result.json as TestMailResult,
+    );
+  }
+
+  // Returns null if the organization uses the global mail settings
+  static async get(): Promise<MailSettings | null> {
+    return Ajax.get("/mail-settings/")
+      .then((result) => {
+        let e: MailSettings = new MailSettings();
+        e.deserialize(result.json);
+        return e;
+      })
+      .catch((e) => {
+        if (e instanceof AjaxError && e.httpStatusCode === 404) {
+          return null;
+        }
+        throw e;
+      });
+  }
+}
@@ -49,6 +49,34 @@ export default class Organization extends Entity {
   static readonly PREF_SYS_INSTALL_ID = "_sys_install_id";
   static readonly PREF_HIDE_REPORTS = "hide_reports";
   static readonly PREF_HIDE_STATS = "hide_stats";
+  static readonly PREF_ALLOW_MAGIC_LINK_LOGIN = "allow_magic_link_login";
+  static readonly PREF_PASSWORD_MIN_LENGTH = "password_min_length";
+  static readonly PREF_PASSWORD_MIN_CHAR_CLASSES = "password_min_char_classes";
+  static readonly PREF_PASSWORD_HISTORY = "password_history";
+  static readonly PREF_PASSWORD_MAX_AGE_DAYS = "password_max_age_days";
+  static readonly PREF_PASSWORD_CHECK_BREACHED = "password_check_breached";
+  static readonly PREF_NETWORK_ALLOWLIST_USERS = "network_allowlist_users";
+  static readonly PREF_NETWORK_ALLOWLIST_SPACE_ADMINS =
+    "network_allowlist_space_admins";
+  static readonly PREF_NETWORK_ALLOWLIST_ORG_ADMINS =
+    "network_allowlist_org_admins";
+  static readonly PREF_NETWORK_ALLOWLIST_SERVICE_ACCOUNTS =
+    "network_allowlist_service_accounts";
+  static readonly PREF_TRUSTED_NETWORKS = "trusted_networks";
+  static readonly PREF_REQUIRE_2FA_UNTRUSTED_NETWORKS =
+    "require_2fa_untrusted_networks";
+  static readonly PREF_SUSPICIOUS_LOGIN_NOTIFICATIONS =
+    "suspicious_login_notifications";
+  static readonly PREF_CHAT_APPROVAL_WEBHOOK_URL = "chat_approval_webhook_url";
+  static readonly PREF_CHAT_APPROVAL_WEBHOOK_FORMAT =
+    "chat_approval_webhook_format";
+  static readonly PREF_APPROVAL_REMINDER_HOURS = "approval_reminder_hours";
+  static readonly PREF_APPROVAL_ESCALATION_HOURS = "approval_escalation_hours";
+  static readonly PREF_APPROVAL_ESCALATION_GROUP_ID =
+    "approval_escalation_group_id";
+  static readonly PREF_APPROVAL_EXPIRY_ACTION = "approval_expiry_action";
+  static readonly PREF_APPROVAL_EXPIRY_HOURS = "approval_expiry_hours";
+  static readonly APPROVAL_EXPIRY_ACTIONS = ["", "approve", "decline"];
 
   name: string;
   contactFirstname: string;
@@ -15,6 +15,7 @@ export default class RecurringBooking extends Entity {
   cadence: number;
   cycle: number;
   weekdays: number[];
+  userEmail: string;
 
   constructor() {
     super();
@@ -26,6 +27,7 @@ export default class RecurringBooking extends Entity {
     this.cadence = 0;
     this.cycle = 0;
     this.weekdays = [];
+    this.userEmail = "";
   }
 
   serialize(): Object {
@@ -43,6 +45,7 @@ export default class RecurringBooking extends Entity {
       cadence: this.cadence,
       cycle: this.cycle,
       weekdays: this.weekdays,
+      userEmail: this.userEmail,
     });
   }
 
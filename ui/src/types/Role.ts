@@ -0,0 +1,150 @@
+import { Entity } from "./Entity";
+import Ajax from "../util/Ajax";
+
+export class RoleAssignment extends Entity {
+  roleId: string;
+  userId: string;
+  userEmail: string;
+  groupId: string;
+  groupName: string;
+  locationId: string;
+  locationName: string;
+
+  constructor() {
+    super();
+    this.roleId = "";
+    this.userId = "";
+    this.userEmail = "";
+    this.groupId = "";
+    this.groupName = "";
+    this.locationId = "";
+    this.locationName = "";
+  }
+
+  serialize(): Object {
+    return Object.assign(super.serialize(), {
+      userEmail: this.userEmail,
+      groupId: this.groupId,
+      locationId: this.locationId,
+    });
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.roleId = input.roleId;
+    this.userId = input.userId;
+    this.userEmail = input.userEmail;
+    this.groupId = input.groupId;
+    this.groupName = input.groupName;
+    this.locationId = input.locationId;
+    this.locationName = input.locationName;
+  }
+
+  getBackendUrl(): string {
+    return "/role/";
+  }
+}
+
+export default class Role extends Entity {
+  static PERMISSION_MANAGE_SPACES = "manage_spaces";
+  static PERMISSION_MANAGE_BOOKINGS = "manage_bookings";
+  static PERMISSION_APPROVE_BOOKINGS = "approve_bookings";
+  static PERMISSION_VIEW_REPORTS = "view_reports";
+  static PERMISSION_MANAGE_USERS = "manage_users";
+  static PERMISSION_MANAGE_SETTINGS = "manage_settings";
+  static PERMISSION_VIEW_AUDIT_LOG = "view_audit_log";
+
+  static readonly PERMISSIONS = [
+    Role.PERMISSION_MANAGE_SPACES,
+    Role.PERMISSION_MANAGE_BOOKINGS,
+    Role.PERMISSION_APPROVE_BOOKINGS,
+    Role.PERMISSION_VIEW_REPORTS,
+    Role.PERMISSION_MANAGE_USERS,
+    Role.PERMISSION_MANAGE_SETTINGS,
+    Role.PERMISSION_VIEW_AUDIT_LOG,
+  ];
+
+  organizationId: string;
+  name: string;
+  permissions: string[];
+
+  constructor() {
+    super();
+    this.organizationId = "";
+    this.name = "";
+    this.permissions = [];
+  }
+
+  serialize(): Object {
+    return Object.assign(super.serialize(), {
+      name: this.name,
+      permissions: this.permissions,
+    });
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.organizationId = input.organizationId;
+    this.name = input.name;
+    this.permissions = input.permissions ?? [];
+  }
+
+  getBackendUrl(): string {
+    return "/role/";
+  }
+
+  async save(): Promise<Role> {
+    return Ajax.saveEntity(this, this.getBackendUrl()).then(() => this);
+  }
+
+  async delete(): Promise<void> {
+    return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
+  }
+
+  async getAssignments(): Promise<RoleAssignment[]> {
+    return Ajax.get(this.getBackendUrl() + this.id + "/assignment").then(
+      (result) => {
+        let list: RoleAssignment[] = [];
+        (result.json as []).forEach((item) => {
+          let e: RoleAssignment = new RoleAssignment();
+          e.deserialize(item);
+          list.push(e);
+        });
+        return list;
+      },
+    );
+  }
+
+  async addAssignment(e: RoleAssignment): Promise<void> {
+    return Ajax.postData(
+      this.getBackendUrl() + this.id + "/assignment",
+      e.serialize(),
+    ).then(() => undefined);
+  }
+
+  async removeAssignment(e: RoleAssignment): Promise<void> {
+    return Ajax.delete(
+      this.getBackendUrl() + this.id + "/assignment/" + e.id,
+    ).then(() => undefined);
+  }
+
+  static async get(id: string): Promise<Role> {
+    return Ajax.get("/role/" + id).then((result) => {
+      let e: Role = new Role();
+      e.deserialize(result.json);
+      return e;
+    });
+  }
+
+  static async list(): Promise<Role[]> {
+    return Ajax.get("/role/").then((result) => {
+      let list: Role[] = [];
+      (result.json as []).forEach((item) => {
+        let e: Role = new Role();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return list;
+    });
+  }
+}
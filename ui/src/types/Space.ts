@@ -21,6 +21,7 @@ export default class Space extends Entity {
   fontSize: string;
   attributes: SpaceAttributeValue[];
   approverGroupIds: string[];
+  secondApproverGroupIds: string[];
   allowedBookerGroupIds: string[];
   available: boolean;
   locationId: string;
@@ -44,6 +45,7 @@ export default class Space extends Entity {
     this.fontSize = "normal";
     this.attributes = [];
     this.approverGroupIds = [];
+    this.secondApproverGroupIds = [];
     this.allowedBookerGroupIds = [];
     this.available = false;
     this.locationId = "";
@@ -129,6 +131,7 @@ export default class Space extends Entity {
       fontSize: this.fontSize,
       attributes: this.attributes.map((a) => a.serialize()),
       approverGroupIds: this.approverGroupIds,
+      secondApproverGroupIds: this.secondApproverGroupIds,
       allowedBookerGroupIds: this.allowedBookerGroupIds,
     });
   }
@@ -172,6 +175,9 @@ export default class Space extends Entity {
     if (input.approverGroupIds) {
       this.approverGroupIds = input.approverGroupIds;
     }
+    if (input.secondApproverGroupIds) {
+      this.secondApproverGroupIds = input.secondApproverGroupIds;
+    }
     if (input.allowedBookerGroupIds) {
       this.allowedBookerGroupIds = input.allowedBookerGroupIds;
     }
@@ -47,8 +47,11 @@ export default class Stats {
     this.bookingsByWeekday = input.bookingsByWeekday ?? [0, 0, 0, 0, 0, 0, 0];
   }
 
-  static async get(): Promise<Stats> {
-    const result = await Ajax.get(`/stats/`);
+  static async get(locationId: string | null = null): Promise<Stats> {
+    const params = locationId
+      ? `?location=${encodeURIComponent(locationId)}`
+      : "";
+    const result = await Ajax.get(`/stats/${params}`);
     const e: Stats = new Stats();
     e.deserialize(result.json);
     return e;
@@ -249,19 +249,93 @@ export default class User extends Entity {
   static async revokeApiToken(userId: string): Promise<void> {
     return Ajax.delete("/user/" + userId + "/api-token").then(() => undefined);
   }
+
+  static async importCSV(
+    data: string,
+    dryRun: boolean,
+    sendInvitation: boolean,
+  ): Promise<UserImportResult> {
+    return Ajax.postData("/user/import", {
+      data: data,
+      dryRun: dryRun,
+      sendInvitation: sendInvitation,
+    }).then((result) => Object.assign(new UserImportResult(), result.json));
+  }
+
+  static async exportCSV(): Promise<void> {
+    const credentials = Ajax.PERSISTER.readCredentialsFromLocalStorage();
+    const options: RequestInit = Ajax.getFetchOptions(
+      "GET",
+      credentials.accessToken,
+      null,
+    );
+    const response = await fetch(
+      Ajax.getBackendUrl() + "/user/export",
+      options,
+    );
+    if (!response.ok) {
+      return;
+    }
+    const data = await response.blob();
+    const blob = new Blob([data], { type: "text/csv" });
+    const blobUrl = window.URL.createObjectURL(blob);
+    const a = document.createElement("a");
+    a.style.display = "none";
+    a.href = blobUrl;
+    a.download = "seatsurfing-users.csv";
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    window.URL.revokeObjectURL(blobUrl);
+  }
+}
+
+export class UserImportError {
+  line: number;
+  email: string;
+  error: string;
+
+  constructor() {
+    this.line = 0;
+    this.email = "";
+    this.error = "";
+  }
+}
+
+export class UserImportResult {
+  dryRun: boolean;
+  numValid: number;
+  numCreated: number;
+  errors: UserImportError[];
+
+  constructor() {
+    this.dryRun = false;
+    this.numValid = 0;
+    this.numCreated = 0;
+    this.errors = [];
+  }
 }
 
 export class UserSelf extends User {
   isPrimaryDomain: boolean;
+  permissions: string[];
+  locationScopedPermissions: string[];
+  isManager: boolean;
 
   constructor() {
     super();
     this.isPrimaryDomain = false;
+    this.permissions = [];
+    this.locationScopedPermissions = [];
+    this.isManager = false;
   }
 
   deserialize(input: any): void {
     super.deserialize(input);
     this.isPrimaryDomain = input.isPrimaryDomain ?? false;
+    this.permissions = input.permissions ?? [];
+    this.locationScopedPermissions = input.locationScopedPermissions ?? [];
+    this.isManager = input.isManager ?? false;
   }
 }
 
@@ -0,0 +1,140 @@
+import { Entity } from "./Entity";
+import Ajax from "../util/Ajax";
+
+export class UserAttributeValue {
+  attributeId: string;
+  value: string;
+
+  constructor(attributeId: string = "", value: string = "") {
+    this.attributeId = attributeId;
+    this.value = value;
+  }
+}
+
+export class UserAttributeStats {
+  value: string;
+  numUsers: number;
+  numBookers: number;
+  numBookings: number;
+
+  constructor() {
+    this.value = "";
+    this.numUsers = 0;
+    this.numBookers = 0;
+    this.numBookings = 0;
+  }
+}
+
+export default class UserAttribute extends Entity {
+  static readonly TYPE_INT = 1;
+  static readonly TYPE_BOOL = 2;
+  static readonly TYPE_STRING = 3;
+  static readonly TYPE_MANAGER = 10;
+
+  label: string;
+  type: number;
+  claimName: string;
+
+  constructor(
+    id: string = "",
+    label: string = "",
+    type: number = UserAttribute.TYPE_STRING,
+    claimName: string = "",
+  ) {
+    super(id);
+    this.label = label;
+    this.type = type;
+    this.claimName = claimName;
+  }
+
+  serialize(): Object {
+    return Object.assign(super.serialize(), {
+      label: this.label,
+      type: this.type,
+      claimName: this.claimName,
+    });
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.label = input.label;
+    this.type = input.type;
+    this.claimName = input.claimName;
+  }
+
+  getBackendUrl(): string {
+    return "/user-attribute/";
+  }
+
+  async save(): Promise<UserAttribute> {
+    return Ajax.saveEntity(this, this.getBackendUrl()).then(() => this);
+  }
+
+  async delete(): Promise<void> {
+    return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
+  }
+
+  async getStats(
+    locationId: string | null = null,
+    period: string | null = null,
+  ): Promise<UserAttributeStats[]> {
+    let params = new URLSearchParams();
+    if (locationId) params.set("location", locationId);
+    if (period) params.set("period", period);
+    return Ajax.get("/stats/attribute/" + this.id + "?" + params).then(
+      (result) => {
+        let list: UserAttributeStats[] = [];
+        (result.json as []).forEach((item) => {
+          list.push(Object.assign(new UserAttributeStats(), item));
+        });
+        return list;
+      },
+    );
+  }
+
+  static async get(id: string): Promise<UserAttribute> {
+    return Ajax.get("/user-attribute/" + id).then((result) => {
+      let e: UserAttribute = new UserAttribute();
+      e.deserialize(result.json);
+      return e;
+    });
+  }
+
+  static async list(): Promise<UserAttribute[]> {
+    return Ajax.get("/user-attribute/").then((result) => {
+      let list: UserAttribute[] = [];
+      (result.json as []).forEach((item) => {
+        let e: UserAttribute = new UserAttribute();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return list;
+    });
+  }
+
+  static async getValues(userId: string): Promise<UserAttributeValue[]> {
+    return Ajax.get("/user/" + userId + "/attribute").then((result) => {
+      let list: UserAttributeValue[] = [];
+      (result.json as []).forEach((item: any) => {
+        list.push(new UserAttributeValue(item.attributeId, item.value));
+      });
+      return list;
+    });
+  }
+
+  static async setValue(
+    userId: string,
+    attributeId: string,
+    value: string,
+  ): Promise<void> {
+    return Ajax.postData("/user/" + userId + "/attribute/" + attributeId, {
+      value: value,
+    }).then(() => undefined);
+  }
+
+  static async deleteValue(userId: string, attributeId: string): Promise<void> {
+    return Ajax.delete("/user/" + userId + "/attribute/" + attributeId).then(
+      () => undefined,
+    );
+  }
+}
@@ -15,6 +15,13 @@ export default class UserPreference extends Entity {
     NextWorkday: 3,
   };
 
+  static readonly PreferenceMailNotifications = {
+    Off: 0,
+    Immediate: 1,
+    DailyDigest: 2,
+    WeeklyDigest: 3,
+  };
+
   static readonly PREF_ENTER_TIME = "enter_time";
   static readonly PREF_WORKDAY_START = "workday_start";
   static readonly PREF_WORKDAY_END = "workday_end";
@@ -36,6 +43,14 @@ export default class UserPreference extends Entity {
   static readonly PREF_USE_24_HOUR_TIME = "use_24_hour_time";
   static readonly PREF_DATE_FORMAT = "date_format";
   static readonly PREF_WEEK_START_DAY = "week_start_day";
+  static readonly PREF_CHAT_WEBHOOK_URL = "chat_webhook_url";
+  static readonly PREF_CHAT_WEBHOOK_FORMAT = "chat_webhook_format";
+  static readonly PREF_CHAT_NOTIFICATIONS = "chat_notifications";
+  static readonly PREF_CHAT_REMINDER = "chat_reminder";
+  static readonly PREF_PUSH_NOTIFICATIONS = "push_notifications";
+  static readonly PREF_PUSH_REMINDER = "push_reminder";
+  static readonly PREF_PUSH_APPROVAL_REQUESTS = "push_approval_requests";
+  static readonly CHAT_FORMATS = ["slack", "teams", "mattermost"];
 
   name: string;
   value: string;
@@ -0,0 +1,123 @@
+import { Entity } from "./Entity";
+import Ajax from "../util/Ajax";
+
+export default class WebPushSubscription extends Entity {
+  static readonly SERVICE_WORKER_URL = "/ui/push-sw.js";
+
+  endpoint: string;
+  userAgent: string;
+  created: Date;
+
+  constructor() {
+    super();
+    this.endpoint = "";
+    this.userAgent = "";
+    this.created = new Date();
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.endpoint = input.endpoint;
+    this.userAgent = input.userAgent;
+    this.created = new Date(input.created);
+  }
+
+  getBackendUrl(): string {
+    return "/push-subscription/";
+  }
+
+  async delete(): Promise<void> {
+    return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
+  }
+
+  static isSupported(): boolean {
+    return (
+      typeof window !== "undefined" &&
+      "serviceWorker" in navigator &&
+      "PushManager" in window &&
+      "Notification" in window
+    );
+  }
+
+  static async list(): Promise<WebPushSubscription[]> {
+    return Ajax.get("/push-subscription/").then((result) => {
+      const list: WebPushSubscription[] = [];
+      (result.json as []).forEach((item) => {
+        const e: WebPushSubscription = new WebPushSubscription();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return list;
+    });
+  }
+
+  static async getVapidPublicKey(): Promise<string> {
+    return Ajax.get("/push-subscription/vapid-key").then(
+      (result) => result.json.publicKey,
+    );
+  }
+
+  // getBrowserSubscription returns this browser's current subscription, if
+  // push notifications have been enabled before.
+  static async getBrowserSubscription(): Promise<PushSubscription | null> {
+    const registration = await navigator.serviceWorker.getRegistration(
+      WebPushSubscription.SERVICE_WORKER_URL,
+    );
+    if (!registration) {
+      return null;
+    }
+    return registration.pushManager.getSubscription();
+  }
+
+  // subscribe asks for permission to show notifications, subscribes this
+  // browser with the push service and registers the subscription.
+  static async subscribe(): Promise<void> {
+    const permission = await Notification.requestPermission();
+    if (permission !== "granted") {
+      throw new Error("permission denied");
+    }
+    const registration = await navigator.serviceWorker.register(
+      WebPushSubscription.SERVICE_WORKER_URL,
+      { scope: "/ui/" },
+    );
+    await navigator.serviceWorker.ready;
+    const publicKey = await WebPushSubscription.getVapidPublicKey();
+    const subscription = await registration.pushManager.subscribe({
+      userVisibleOnly: true,
+      applicationServerKey: WebPushSubscription.decodeKey(publicKey),
+    });
+    const json = subscription.toJSON();
+    await Ajax.postData("/push-subscription/", {
+      endpoint: json.endpoint,
+      keys: json.keys,
+      userAgent: navigator.userAgent.substring(0, 255),
+    });
+  }
+
+  // unsubscribe removes this browser's subscription from the server and the
+  // push service.
+  static async unsubscribe(): Promise<void> {
+    const subscription = await WebPushSubscription.getBrowserSubscription();
+    if (!subscription) {
+      return;
+    }
+    const list = await WebPushSubscription.list();
+    const e = list.find((s) => s.endpoint === subscription.endpoint);
+    if (e) {
+      await e.delete();
+    }
+    await subscription.unsubscribe();
+  }
+
+  static decodeKey(s: string): Uint8Array<ArrayBuffer> {
+    const base64 = (s + "=".repeat((4 - (s.length % 4)) % 4))
+      .replace(/-/g, "+")
+      .replace(/_/g, "/");
+    const raw = window.atob(base64);
+    const result = new Uint8Array(raw.length);
+    for (let i = 0; i < raw.length; i++) {
+      result[i] = raw.charCodeAt(i);
+    }
+    return result;
+  }
+}
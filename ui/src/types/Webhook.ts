@@ -0,0 +1,180 @@
+import { Entity } from "./Entity";
+import Ajax from "../util/Ajax";
+
+export class WebhookDelivery extends Entity {
+  static STATUS_PENDING = "pending";
+  static STATUS_SUCCEEDED = "succeeded";
+  static STATUS_FAILED = "failed";
+
+  webhookId: string;
+  event: string;
+  status: string;
+  attempts: number;
+  nextAttempt: Date | null;
+  lastAttempt: Date | null;
+  responseCode: number;
+  error: string;
+  created: Date;
+  payload: string;
+
+  constructor() {
+    super();
+    this.webhookId = "";
+    this.event = "";
+    this.status = "";
+    this.attempts = 0;
+    this.nextAttempt = null;
+    this.lastAttempt = null;
+    this.responseCode = 0;
+    this.error = "";
+    this.created = new Date();
+    this.payload = "";
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.webhookId = input.webhookId;
+    this.event = input.event;
+    this.status = input.status;
+    this.attempts = input.attempts;
+    this.nextAttempt = input.nextAttempt ? new Date(input.nextAttempt) : null;
+    this.lastAttempt = input.lastAttempt ? new Date(input.lastAttempt) : null;
+    this.responseCode = input.responseCode;
+    this.error = input.error;
+    this.created = new Date(input.created);
+    this.payload = input.payload;
+  }
+
+  getBackendUrl(): string {
+    return "/webhook/" + this.webhookId + "/delivery/";
+  }
+
+  async retry(): Promise<void> {
+    return Ajax.postData(this.getBackendUrl() + this.id + "/retry").then(
+      () => undefined,
+    );
+  }
+}
+
+export default class Webhook extends Entity {
+  name: string;
+  url: string;
+  events: string[];
+  enabled: boolean;
+  consecutiveFailures: number;
+  disabledAt: Date | null;
+  created: Date;
+  // Only set after creation and secret rotation
+  secret: string;
+
+  constructor() {
+    super();
+    this.name = "";
+    this.url = "";
+    this.events = [];
+    this.enabled = true;
+    this.consecutiveFailures = 0;
+    this.disabledAt = null;
+    this.created = new Date();
+    this.secret = "";
+  }
+
+  serialize(): Object {
+    return Object.assign(super.serialize(), {
+      name: this.name,
+      url: this.url,
+      events: this.events,
+      enabled: this.enabled,
+    });
+  }
+
+  deserialize(input: any): void {
+    super.deserialize(input);
+    this.name = input.name;
+    this.url = input.url;
+    this.events = input.events ?? [];
+    this.enabled = input.enabled;
+    this.consecutiveFailures = input.consecutiveFailures;
+    this.disabledAt = input.disabledAt ? new Date(input.disabledAt) : null;
+    this.created = new Date(input.created);
+    if (input.secret) {
+      this.secret = input.secret;
+    }
+  }
+
+  getBackendUrl(): string {
+    return "/webhook/";
+  }
+
+  async save(): Promise<Webhook> {
+    return Ajax.saveEntity(this, this.getBackendUrl()).then((result) => {
+      if (result.json && result.json.secret) {
+        this.secret = result.json.secret;
+      }
+      return this;
+    });
+  }
+
+  async delete(): Promise<void> {
+    return Ajax.delete(this.getBackendUrl() + this.id).then(() => undefined);
+  }
+
+  async rotateSecret(): Promise<string> {
+    return Ajax.postData(this.getBackendUrl() + this.id + "/secret").then(
+      (result) => {
+        this.secret = result.json.secret;
+        return this.secret;
+      },
+    );
+  }
+
+  async sendTestEvent(): Promise<WebhookDelivery> {
+    return Ajax.postData(this.getBackendUrl() + this.id + "/test").then(
+      (result) => {
+        let e: WebhookDelivery = new WebhookDelivery();
+        e.deserialize(result.json);
+        return e;
+      },
+    );
+  }
+
+  async getDeliveries(): Promise<WebhookDelivery[]> {
+    return Ajax.get(this.getBackendUrl() + this.id + "/delivery/").then(
+      (result) => {
+        let list: WebhookDelivery[] = [];
+        (result.json as []).forEach((item) => {
+          let e: WebhookDelivery = new WebhookDelivery();
+          e.deserialize(item);
+          list.push(e);
+        });
+        return list;
+      },
+    );
+  }
+
+  static async getEvents(): Promise<string[]> {
+    return Ajax.get("/webhook/events").then(
+      (result) => result.json as string[],
+    );
+  }
+
+  static async get(id: string): Promise<Webhook> {
+    return Ajax.get("/webhook/" + id).then((result) => {
+      let e: Webhook = new Webhook();
+      e.deserialize(result.json);
+      return e;
+    });
+  }
+
+  static async list(): Promise<Webhook[]> {
+    return Ajax.get("/webhook/").then((result) => {
+      let list: Webhook[] = [];
+      (result.json as []).forEach((item) => {
+        let e: Webhook = new Webhook();
+        e.deserialize(item);
+        list.push(e);
+      });
+      return list;
+    });
+  }
+}
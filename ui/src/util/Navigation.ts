@@ -1,12 +1,19 @@
 import CONSTANT from "@/util/Contant";
 
-export type PreferencesTab = "security" | "style" | "booking" | "integration";
+export type PreferencesTab =
+  | "security"
+  | "style"
+  | "booking"
+  | "integration"
+  | "delegation";
 
 export default class Navigation {
   // API
   static readonly PATH_API_SETTINGS = "/setting/";
   static readonly PATH_API_USER_PREFERENCES = "/preference/";
   static readonly PATH_API_AUTH_INIT_PW_RESET = "/auth/initpwreset";
+  static readonly PATH_API_AUTH_MAGIC_LINK = "/auth/magic-link";
+  static readonly PATH_API_APPROVAL_LINK = "/approval-link/";
   static readonly PATH_API_AUTH_ORG = "/auth/org/";
   static readonly PATH_API_AUTH_SINGLE_ORG = "/auth/singleorg";
   static readonly PATH_API_SEARCH = "/search";